@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/aperturerobotics/cli"
+	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler/gobuild"
+)
+
+func buildCommands() []*cli.Command {
+	return []*cli.Command{newBuildCommand()}
+}
+
+func newBuildCommand() *cli.Command {
+	var exports cli.StringSlice
+	var tags cli.StringSlice
+	var overrideDirs cli.StringSlice
+	var output string
+	var target string
+	var workDir string
+	var dir string
+	var minify bool
+	var sourceMaps bool
+	var preemptLoops bool
+
+	return &cli.Command{
+		Name:      "build",
+		Category:  "build",
+		Usage:     "bundle a Go package into one ESM JavaScript file with declarations",
+		ArgsUsage: "package",
+		Action: func(c *cli.Context) error {
+			args := c.Args().Slice()
+			if len(args) != 1 {
+				return errors.New("goscript build requires exactly one package")
+			}
+			result, err := gobuild.NewBuilder().Build(c.Context, &gobuild.Request{
+				Dir:          dir,
+				Package:      args[0],
+				Exports:      exports.Value(),
+				Output:       output,
+				Target:       gobuild.Target(target),
+				Minify:       minify,
+				BuildTags:    tags.Value(),
+				OverrideDirs: overrideDirs.Value(),
+				WorkDir:      workDir,
+				SourceMaps:   sourceMaps,
+				PreemptLoops: preemptLoops,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = c.App.Writer.Write([]byte(result.Output + "\n" + result.Declarations + "\n"))
+			return err
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       "bundle path; the declarations are written beside it as .d.ts",
+				Destination: &output,
+			},
+			&cli.StringSliceFlag{
+				Name:        "export",
+				Usage:       "exported identifier a library bundle exposes (repeatable or comma-separated)",
+				Destination: &exports,
+			},
+			&cli.StringFlag{
+				Name:        "target",
+				Usage:       "bundle host: browser or bun",
+				Value:       string(gobuild.TargetBrowser),
+				Destination: &target,
+			},
+			&cli.BoolFlag{
+				Name:        "minify",
+				Usage:       "minify the bundle",
+				Destination: &minify,
+			},
+			&cli.StringSliceFlag{
+				Name:        "tags",
+				Usage:       "comma-separated Go build tags",
+				Destination: &tags,
+			},
+			&cli.StringSliceFlag{
+				Name:        "gs-path",
+				Aliases:     []string{"override-dir"},
+				Usage:       "additional GoScript override root containing package-path directories",
+				Destination: &overrideDirs,
+			},
+			&cli.StringFlag{
+				Name:        "workdir",
+				Usage:       "generated build workspace directory, kept after the build",
+				Destination: &workDir,
+			},
+			&cli.StringFlag{
+				Name:        "dir",
+				Usage:       "Go module working directory",
+				Destination: &dir,
+			},
+			&cli.BoolFlag{
+				Name:        "source-maps",
+				Usage:       "write an external source map beside the bundle",
+				Destination: &sourceMaps,
+			},
+			&cli.BoolFlag{
+				Name:        "preempt-loops",
+				Usage:       "let long-running loops in goroutines yield to other goroutines",
+				Destination: &preemptLoops,
+			},
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildCommandHelp(t *testing.T) {
+	var out bytes.Buffer
+	app := newApp()
+	app.Writer = &out
+
+	err := app.Run([]string{"goscript", "build", "--help"})
+	if err != nil {
+		t.Fatalf("build help failed: %v", err)
+	}
+	help := out.String()
+	for _, expected := range []string{"bundle a Go package into one ESM JavaScript file with declarations", "--export", "--target", "--minify", "--output"} {
+		if !strings.Contains(help, expected) {
+			t.Fatalf("help output missing %q:\n%s", expected, help)
+		}
+	}
+}
+
+func TestBuildCommandRejectsExportsForMainPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/cmdbuild\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n\nfunc Value() int { return 7 }\n\nfunc main() {}\n")
+
+	app := newApp()
+	err := app.Run([]string{"goscript", "build", "--dir", dir, "--export", "Value", "."})
+	if err == nil || !strings.Contains(err.Error(), "package example.test/cmdbuild is a main package") {
+		t.Fatalf("expected main package exports error, got %v", err)
+	}
+}
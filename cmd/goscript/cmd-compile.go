@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"io"
 	"slices"
 
 	"github.com/aperturerobotics/cli"
+	jsoniter "github.com/aperturerobotics/json-iterator-lite"
 	"github.com/pkg/errors"
 	"github.com/s4wave/goscript/compiler"
 	"github.com/sirupsen/logrus"
@@ -20,6 +22,8 @@ func newCompileCommand() *cli.Command {
 	var buildFlags rawStringSlice
 	var overrideDirs cli.StringSlice
 	var packageBlocklist cli.StringSlice
+	var diagnosticsFormat string
+	var resultJSON bool
 
 	return &cli.Command{
 		Name:     "compile",
@@ -29,7 +33,17 @@ func newCompileCommand() *cli.Command {
 			config.BuildFlags = buildFlags.Value()
 			config.OverrideDirs = slices.Clone(overrideDirs.Value())
 			config.PackageBlocklist = slices.Clone(packageBlocklist.Value())
-			return compilePackage(c.Context, &config, packages.Value())
+			format, err := compiler.ParseDiagnosticFormat(diagnosticsFormat)
+			if err != nil {
+				return err
+			}
+			if resultJSON {
+				if format != compiler.DiagnosticFormatText {
+					return errors.New("--result-json cannot be combined with --diagnostics-format " + string(format))
+				}
+				return compilePackageResultJSON(c.Context, &config, packages.Value(), c.App.Writer)
+			}
+			return compilePackage(c.Context, &config, packages.Value(), c.App.Writer, format)
 		},
 		Flags: []cli.Flag{
 			&cli.StringSliceFlag{
@@ -102,6 +116,41 @@ func newCompileCommand() *cli.Command {
 				Value:       false,
 				EnvVars:     []string{"GOSCRIPT_PROTOBUF_TS_BINDING"},
 			},
+			&cli.BoolFlag{
+				Name:        "source-maps",
+				Usage:       "emit .gs.ts.map source maps that point back to the Go source",
+				Destination: &config.SourceMaps,
+				Value:       false,
+				EnvVars:     []string{"GOSCRIPT_SOURCE_MAPS"},
+			},
+			&cli.BoolFlag{
+				Name:        "preempt-loops",
+				Usage:       "add cooperative yield checks to loops so goroutines cannot starve each other",
+				Destination: &config.PreemptLoops,
+				Value:       false,
+				EnvVars:     []string{"GOSCRIPT_PREEMPT_LOOPS"},
+			},
+			&cli.BoolFlag{
+				Name:        "eliminate-dead-code",
+				Usage:       "skip declarations unreachable from main, the requested packages' exported API, and init",
+				Aliases:     []string{"dce"},
+				Destination: &config.EliminateDeadCode,
+				Value:       false,
+				EnvVars:     []string{"GOSCRIPT_ELIMINATE_DEAD_CODE"},
+			},
+			&cli.StringFlag{
+				Name:        "diagnostics-format",
+				Usage:       "diagnostics output format: text, json, or sarif",
+				Destination: &diagnosticsFormat,
+				Value:       string(compiler.DiagnosticFormatText),
+				EnvVars:     []string{"GOSCRIPT_DIAGNOSTICS_FORMAT"},
+			},
+			&cli.BoolFlag{
+				Name:        "result-json",
+				Usage:       "write the compilation result as one JSON document to stdout",
+				Destination: &resultJSON,
+				EnvVars:     []string{"GOSCRIPT_RESULT_JSON"},
+			},
 		},
 	}
 }
@@ -127,7 +176,8 @@ func (s *rawStringSlice) Value() []string {
 }
 
 // compilePackage tries to compile the package.
-func compilePackage(ctx context.Context, config *compiler.Config, pkgs []string) error {
+// With a json or sarif format the diagnostics report is written to w.
+func compilePackage(ctx context.Context, config *compiler.Config, pkgs []string, w io.Writer, format compiler.DiagnosticFormat) error {
 	if len(pkgs) == 0 {
 		return errors.New("package(s) must be specified")
 	}
@@ -139,6 +189,72 @@ func compilePackage(ctx context.Context, config *compiler.Config, pkgs []string)
 	if err != nil {
 		return err
 	}
-	_, err = comp.CompilePackages(ctx, pkgs...)
+	result, err := comp.CompilePackages(ctx, pkgs...)
+	if format == "" || format == compiler.DiagnosticFormatText {
+		return err
+	}
+
+	diagnostics, ok := compileDiagnostics(result, err)
+	if !ok {
+		return err
+	}
+	if writeErr := compiler.WriteDiagnostics(w, format, diagnostics); writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		return errors.New("goscript compile failed")
+	}
+	return nil
+}
+
+// compilePackageResultJSON compiles the packages and writes the
+// CompilationResult to w as JSON, including when compilation fails with
+// diagnostics. The diagnostics error is still returned so the exit status
+// and stderr report the failure.
+func compilePackageResultJSON(ctx context.Context, config *compiler.Config, pkgs []string, w io.Writer) error {
+	if len(pkgs) == 0 {
+		return errors.New("package(s) must be specified")
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	comp, err := compiler.NewCompiler(config, logrus.NewEntry(logger), nil)
+	if err != nil {
+		return err
+	}
+	result, err := comp.CompilePackages(ctx, pkgs...)
+	diagnostics, ok := compileDiagnostics(result, err)
+	if !ok {
+		return err
+	}
+	if result == nil {
+		result = &compiler.CompilationResult{OriginalPackages: slices.Clone(pkgs)}
+	}
+	result.Diagnostics = diagnostics
+	writeErr := writeResultJSON(w, func(stream *jsoniter.Stream) {
+		writeCompilationResult(stream, result)
+	})
+	if writeErr != nil {
+		return writeErr
+	}
 	return err
 }
+
+// compileDiagnostics returns the diagnostics of a compile run.
+// It reports false when err is not a diagnostics failure.
+func compileDiagnostics(result *compiler.CompilationResult, err error) ([]compiler.Diagnostic, bool) {
+	if err == nil {
+		if result == nil {
+			return nil, true
+		}
+		return result.Diagnostics, true
+	}
+	var compileErr *compiler.CompileError
+	if !errors.As(err, &compileErr) {
+		return nil, false
+	}
+	if result != nil && len(result.Diagnostics) != 0 {
+		return result.Diagnostics, true
+	}
+	return compileErr.Diagnostics, true
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aperturerobotics/cli"
+	"github.com/s4wave/goscript/compiler"
+)
+
+func explainCommands() []*cli.Command {
+	return []*cli.Command{{
+		Name:        "explain",
+		Category:    "compile",
+		Usage:       "explain compiler decisions for a Go package",
+		Subcommands: []*cli.Command{newExplainAsyncCommand()},
+	}}
+}
+
+func newExplainAsyncCommand() *cli.Command {
+	var config compiler.Config
+	var packages cli.StringSlice
+	var buildFlags rawStringSlice
+	var overrideDirs cli.StringSlice
+
+	return &cli.Command{
+		Name:      "async",
+		Usage:     "show why functions were compiled as async",
+		ArgsUsage: "[function]",
+		Description: "With a function name, prints the shortest chain of calls from that function " +
+			"to the fact that made it async. Without one, prints every async function in the " +
+			"requested packages grouped by root cause.",
+		Action: func(c *cli.Context) error {
+			config.BuildFlags = buildFlags.Value()
+			config.OverrideDirs = slices.Clone(overrideDirs.Value())
+			pkgs := packages.Value()
+			if len(pkgs) == 0 {
+				pkgs = []string{"."}
+			}
+			return explainAsync(c.Context, c.App.Writer, &config, pkgs, c.Args().First())
+		},
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "package",
+				Usage:       "the package(s) to analyze (default: .)",
+				Aliases:     []string{"p", "packages"},
+				EnvVars:     []string{"GOSCRIPT_PACKAGES"},
+				Destination: &packages,
+			},
+			&cli.StringFlag{
+				Name:        "dir",
+				Usage:       "the working directory to use for the compiler (default: current directory)",
+				Destination: &config.Dir,
+				EnvVars:     []string{"GOSCRIPT_DIR"},
+			},
+			&cli.GenericFlag{
+				Name:    "build-flags",
+				Aliases: []string{"b", "buildflags", "build-flag", "buildflag"},
+				Usage:   "Go build flags (tags) to use during analysis",
+				Value:   &buildFlags,
+				EnvVars: []string{"GOSCRIPT_BUILD_FLAGS"},
+			},
+			&cli.StringSliceFlag{
+				Name:        "gs-path",
+				Aliases:     []string{"override-dir"},
+				Usage:       "additional GoScript override root containing package-path directories",
+				Destination: &overrideDirs,
+				EnvVars:     []string{"GOSCRIPT_GS_PATH"},
+			},
+			&cli.BoolFlag{
+				Name:        "preempt-loops",
+				Usage:       "analyze with cooperative loop preemption, as for goscript compile",
+				Destination: &config.PreemptLoops,
+				EnvVars:     []string{"GOSCRIPT_PREEMPT_LOOPS"},
+			},
+		},
+	}
+}
+
+// explainAsync prints the async explanation for one function, or the
+// package-wide report when name is empty.
+func explainAsync(ctx context.Context, w io.Writer, config *compiler.Config, pkgs []string, name string) error {
+	service := compiler.NewCompileService(config.OverrideDirs...)
+	req := service.RequestOwner().NewRequest(*config, pkgs)
+	model, _, err := service.BuildSemanticModel(ctx, req)
+	if err != nil {
+		return err
+	}
+	opts := compiler.AsyncReportOptions{DisplayRoot: req.Dir}
+
+	if name != "" {
+		explanation, err := model.ExplainAsync(name, opts)
+		if err != nil {
+			return err
+		}
+		if explanation == nil {
+			_, err := io.WriteString(w, name+" is not async\n")
+			return err
+		}
+		return writeAsyncExplanation(w, explanation)
+	}
+
+	groups := model.AsyncReport(opts)
+	if len(groups) == 0 {
+		_, err := io.WriteString(w, "no async functions\n")
+		return err
+	}
+	var b strings.Builder
+	for i, group := range groups {
+		if i != 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(asyncRootCauseTitle(group.RootCause))
+		b.WriteString(" (")
+		b.WriteString(strconv.Itoa(len(group.Functions)))
+		if len(group.Functions) == 1 {
+			b.WriteString(" function)\n")
+		} else {
+			b.WriteString(" functions)\n")
+		}
+		for _, explanation := range group.Functions {
+			b.WriteString("  ")
+			b.WriteString(explanation.Function)
+			if len(explanation.Chain) > 1 {
+				b.WriteString(" via ")
+				b.WriteString(explanation.Chain[len(explanation.Chain)-1].Function)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeAsyncExplanation(w io.Writer, explanation *compiler.AsyncExplanation) error {
+	var b strings.Builder
+	b.WriteString(explanation.Function)
+	b.WriteString(" is async\n")
+	for _, step := range explanation.Chain {
+		b.WriteString("  ")
+		b.WriteString(step.Function)
+		if pos := step.Position; pos != nil && pos.DisplayFile != "" {
+			b.WriteString(" (")
+			b.WriteString(pos.DisplayFile)
+			b.WriteString(":")
+			b.WriteString(strconv.Itoa(pos.Line))
+			b.WriteString(")")
+		}
+		if step.Reason != "" {
+			b.WriteString(": ")
+			b.WriteString(compiler.DescribeAsyncReason(step.Reason))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("root cause: ")
+	b.WriteString(asyncRootCauseTitle(explanation.RootCause))
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func asyncRootCauseTitle(rootCause string) string {
+	if rootCause == "" {
+		return "unknown"
+	}
+	return rootCause
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+
+	"github.com/aperturerobotics/cli"
+	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler/gorun"
+)
+
+func runCommands() []*cli.Command {
+	return []*cli.Command{newRunCommand()}
+}
+
+func newRunCommand() *cli.Command {
+	var tags cli.StringSlice
+	var overrideDirs cli.StringSlice
+	var outputRoot string
+	var workDir string
+	var dir string
+	var sourceMaps bool
+	var preemptLoops bool
+
+	return &cli.Command{
+		Name:      "run",
+		Category:  "run",
+		Usage:     "compile and run a Go main package through GoScript",
+		ArgsUsage: "package [-- arguments...]",
+		Action: func(c *cli.Context) error {
+			args := c.Args().Slice()
+			if len(args) == 0 {
+				return errors.New("goscript run requires a main package")
+			}
+			programArgs := args[1:]
+			if len(programArgs) != 0 && programArgs[0] == "--" {
+				programArgs = programArgs[1:]
+			}
+			result, err := gorun.NewRunner().Run(c.Context, &gorun.Request{
+				Dir:          dir,
+				Package:      args[0],
+				Args:         programArgs,
+				BuildTags:    tags.Value(),
+				OverrideDirs: overrideDirs.Value(),
+				WorkDir:      workDir,
+				OutputRoot:   outputRoot,
+				SourceMaps:   sourceMaps,
+				PreemptLoops: preemptLoops,
+				Stdin:        os.Stdin,
+				Stdout:       c.App.Writer,
+				Stderr:       c.App.ErrWriter,
+			})
+			if err != nil {
+				return err
+			}
+			if result.ExitCode != 0 {
+				return cli.Exit("", result.ExitCode)
+			}
+			return nil
+		},
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "tags",
+				Usage:       "comma-separated Go build tags",
+				Destination: &tags,
+			},
+			&cli.StringSliceFlag{
+				Name:        "gs-path",
+				Aliases:     []string{"override-dir"},
+				Usage:       "additional GoScript override root containing package-path directories",
+				Destination: &overrideDirs,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Usage:       "generated TypeScript output root",
+				Destination: &outputRoot,
+			},
+			&cli.StringFlag{
+				Name:        "workdir",
+				Usage:       "generated program workspace directory, kept after the run",
+				Destination: &workDir,
+			},
+			&cli.StringFlag{
+				Name:        "dir",
+				Usage:       "Go module working directory",
+				Destination: &dir,
+			},
+			&cli.BoolFlag{
+				Name:        "source-maps",
+				Usage:       "emit source maps so panics print Go file and line",
+				Destination: &sourceMaps,
+			},
+			&cli.BoolFlag{
+				Name:        "preempt-loops",
+				Usage:       "let long-running loops in goroutines yield to other goroutines",
+				Destination: &preemptLoops,
+			},
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCommandHelp(t *testing.T) {
+	var out bytes.Buffer
+	app := newApp()
+	app.Writer = &out
+
+	err := app.Run([]string{"goscript", "run", "--help"})
+	if err != nil {
+		t.Fatalf("run help failed: %v", err)
+	}
+	help := out.String()
+	for _, expected := range []string{"compile and run a Go main package through GoScript", "package [-- arguments...]", "--tags", "--workdir", "--source-maps"} {
+		if !strings.Contains(help, expected) {
+			t.Fatalf("help output missing %q:\n%s", expected, help)
+		}
+	}
+}
+
+func TestRunCommandRejectsLibraryPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/cmdrun\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "value.go"), "package cmdrun\n\nfunc Value() int { return 7 }\n")
+
+	app := newApp()
+	err := app.Run([]string{"goscript", "run", "--dir", dir, "."})
+	if err == nil || !strings.Contains(err.Error(), "package example.test/cmdrun is not a main package") {
+		t.Fatalf("expected non-main package error, got %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"slices"
+
+	"github.com/aperturerobotics/cli"
+	jsoniter "github.com/aperturerobotics/json-iterator-lite"
+	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler"
+)
+
+// JSON-RPC 2.0 error codes used by goscript serve.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	// rpcCompileFailed reports a compile that produced error diagnostics.
+	// The error data holds the CompilationResult.
+	rpcCompileFailed = -32000
+)
+
+func serveCommands() []*cli.Command {
+	return []*cli.Command{newServeCommand()}
+}
+
+func newServeCommand() *cli.Command {
+	var dir string
+
+	return &cli.Command{
+		Name:     "serve",
+		Category: "compile",
+		Usage:    "serve compile requests over JSON-RPC on stdin and stdout",
+		Description: "Reads newline-delimited JSON-RPC 2.0 requests from stdin and writes one response " +
+			"line per request to stdout. Loaded package graphs and override facts stay warm between " +
+			"requests and are reloaded when the files they were loaded from change. Methods: compile, " +
+			"testCompile, changes, and shutdown.",
+		Action: func(c *cli.Context) error {
+			server := &compileServer{
+				session: compiler.NewCompileSession(),
+				dir:     dir,
+			}
+			return server.serve(c.Context, c.App.Reader, c.App.Writer)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "dir",
+				Usage:       "the working directory for requests that do not set Config.Dir (default: current directory)",
+				Destination: &dir,
+				EnvVars:     []string{"GOSCRIPT_DIR"},
+			},
+		},
+	}
+}
+
+// compileServer answers JSON-RPC requests against one compile session.
+type compileServer struct {
+	session *compiler.CompileSession
+	dir     string
+}
+
+type rpcRequest struct {
+	jsonrpc string
+	// id is the raw request id, or nil for a notification.
+	id     []byte
+	method string
+	params []byte
+}
+
+type rpcResponse struct {
+	id []byte
+	// result writes the result value. It is unused when err is set.
+	result func(stream *jsoniter.Stream)
+	err    *rpcError
+}
+
+type rpcError struct {
+	code    int
+	message string
+	// data is the CompilationResult of a failed compile, or nil.
+	data *compiler.CompilationResult
+}
+
+// serveCompileParams are the params of compile and testCompile. Config uses
+// the compiler.Config field names.
+type serveCompileParams struct {
+	config   compiler.Config
+	packages []string
+}
+
+// serve handles requests from r until EOF or a shutdown request.
+func (s *compileServer) serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	stream := jsoniter.NewStream(w, 4096, 0)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) != 0 {
+			resp, shutdown := s.handle(ctx, line)
+			if resp != nil {
+				writeRPCResponse(stream, resp)
+				stream.WriteRaw("\n")
+				if stream.Error != nil {
+					return stream.Error
+				}
+				if err := stream.Flush(); err != nil {
+					return err
+				}
+			}
+			if shutdown {
+				return nil
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// handle answers one request line. It returns a nil response for
+// notifications and reports whether the server should stop.
+func (s *compileServer) handle(ctx context.Context, line []byte) (*rpcResponse, bool) {
+	req, err := parseRPCRequest(line)
+	if err != nil {
+		return &rpcResponse{err: &rpcError{code: rpcParseError, message: err.Error()}}, false
+	}
+	resp := &rpcResponse{id: req.id}
+	if req.jsonrpc != "2.0" || req.method == "" {
+		resp.err = &rpcError{code: rpcInvalidRequest, message: "expected a JSON-RPC 2.0 request with a method"}
+		return resp, false
+	}
+
+	var shutdown bool
+	switch req.method {
+	case "compile", "testCompile":
+		var result *compiler.CompilationResult
+		result, resp.err = s.compile(ctx, req.params, req.method == "testCompile")
+		resp.result = func(stream *jsoniter.Stream) {
+			writeCompilationResult(stream, result)
+		}
+	case "changes":
+		changes := s.session.Changes()
+		resp.result = func(stream *jsoniter.Stream) {
+			stream.WriteObjectStart()
+			writeStringListField(stream, "Files", changes.Files)
+			stream.WriteMore()
+			writeStringListField(stream, "Packages", changes.Packages)
+			stream.WriteObjectEnd()
+		}
+	case "shutdown":
+		resp.result = func(stream *jsoniter.Stream) {
+			stream.WriteNil()
+		}
+		shutdown = true
+	default:
+		resp.err = &rpcError{code: rpcMethodNotFound, message: "unknown method " + req.method}
+	}
+	if req.id == nil {
+		return nil, shutdown
+	}
+	return resp, shutdown
+}
+
+// parseRPCRequest reads a JSON-RPC request object. The id and params are
+// kept as raw JSON.
+func parseRPCRequest(line []byte) (rpcRequest, error) {
+	var req rpcRequest
+	iter := jsoniter.ParseBytes(line)
+	if next := iter.WhatIsNext(); next != jsoniter.ObjectValue && next != jsoniter.NilValue {
+		return req, errors.New("expected a JSON object")
+	}
+	for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
+		switch field {
+		case "jsonrpc":
+			req.jsonrpc = iter.ReadString()
+		case "id":
+			req.id = bytes.TrimSpace(iter.SkipAndReturnBytes())
+		case "method":
+			req.method = iter.ReadString()
+		case "params":
+			req.params = bytes.TrimSpace(iter.SkipAndReturnBytes())
+		default:
+			iter.Skip()
+		}
+	}
+	if iter.Error != nil && !errors.Is(iter.Error, io.EOF) {
+		return rpcRequest{}, iter.Error
+	}
+	return req, nil
+}
+
+// parseServeCompileParams reads the params of compile and testCompile.
+func parseServeCompileParams(data []byte) (serveCompileParams, error) {
+	var params serveCompileParams
+	iter := jsoniter.ParseBytes(data)
+	for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
+		switch field {
+		case "Config":
+			readServeConfig(iter, &params.config)
+		case "Packages":
+			params.packages = readStringList(iter)
+		default:
+			iter.Skip()
+		}
+	}
+	if iter.Error != nil && !errors.Is(iter.Error, io.EOF) {
+		return serveCompileParams{}, iter.Error
+	}
+	return params, nil
+}
+
+func readServeConfig(iter *jsoniter.Iterator, config *compiler.Config) {
+	for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
+		switch field {
+		case "Dir":
+			config.Dir = iter.ReadString()
+		case "OutputPath":
+			config.OutputPath = iter.ReadString()
+		case "CacheRoot":
+			config.CacheRoot = iter.ReadString()
+		case "BuildFlags":
+			config.BuildFlags = readStringList(iter)
+		case "OverrideDirs":
+			config.OverrideDirs = readStringList(iter)
+		case "PackageBlocklist":
+			config.PackageBlocklist = readStringList(iter)
+		case "AllDependencies":
+			config.AllDependencies = iter.ReadBool()
+		case "DisableEmitBuiltin":
+			config.DisableEmitBuiltin = iter.ReadBool()
+		case "ProtobufTypeScriptBinding":
+			config.ProtobufTypeScriptBinding = iter.ReadBool()
+		case "SourceMaps":
+			config.SourceMaps = iter.ReadBool()
+		case "PreemptLoops":
+			config.PreemptLoops = iter.ReadBool()
+		case "EliminateDeadCode":
+			config.EliminateDeadCode = iter.ReadBool()
+		default:
+			iter.Skip()
+		}
+	}
+}
+
+func readStringList(iter *jsoniter.Iterator) []string {
+	var values []string
+	for iter.ReadArray() {
+		values = append(values, iter.ReadString())
+	}
+	return values
+}
+
+// writeRPCResponse writes resp as one JSON-RPC 2.0 response object. A
+// response without a request id, such as a parse error, has a null id.
+func writeRPCResponse(stream *jsoniter.Stream, resp *rpcResponse) {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("jsonrpc")
+	stream.WriteString("2.0")
+	stream.WriteMore()
+	stream.WriteObjectField("id")
+	if len(resp.id) == 0 {
+		stream.WriteNil()
+	} else {
+		stream.WriteRaw(string(resp.id))
+	}
+	stream.WriteMore()
+	if resp.err == nil {
+		stream.WriteObjectField("result")
+		resp.result(stream)
+		stream.WriteObjectEnd()
+		return
+	}
+	stream.WriteObjectField("error")
+	stream.WriteObjectStart()
+	stream.WriteObjectField("code")
+	stream.WriteInt(resp.err.code)
+	stream.WriteMore()
+	stream.WriteObjectField("message")
+	stream.WriteString(resp.err.message)
+	if resp.err.data != nil {
+		stream.WriteMore()
+		stream.WriteObjectField("data")
+		writeCompilationResult(stream, resp.err.data)
+	}
+	stream.WriteObjectEnd()
+	stream.WriteObjectEnd()
+}
+
+// compile runs a compile or test-variant compile request.
+func (s *compileServer) compile(ctx context.Context, rawParams []byte, tests bool) (*compiler.CompilationResult, *rpcError) {
+	var params serveCompileParams
+	if len(rawParams) != 0 {
+		var err error
+		if params, err = parseServeCompileParams(rawParams); err != nil {
+			return nil, &rpcError{code: rpcInvalidParams, message: err.Error()}
+		}
+	}
+	if len(params.packages) == 0 {
+		return nil, &rpcError{code: rpcInvalidParams, message: "package(s) must be specified"}
+	}
+	config := params.config
+	if config.Dir == "" {
+		config.Dir = s.dir
+	}
+	if config.OutputPath == "" {
+		config.OutputPath = "./output"
+	}
+	if err := config.Validate(); err != nil {
+		return nil, &rpcError{code: rpcInvalidParams, message: err.Error()}
+	}
+
+	req := compiler.NewCompileRequestOwner().NewRequest(config, params.packages)
+	req.Tests = tests
+	result, err := s.session.Compile(ctx, req)
+	diagnostics, ok := compileDiagnostics(result, err)
+	if !ok {
+		return nil, &rpcError{code: rpcInternalError, message: err.Error()}
+	}
+	if result == nil {
+		result = &compiler.CompilationResult{OriginalPackages: slices.Clone(params.packages)}
+	}
+	result.Diagnostics = diagnostics
+	if err != nil {
+		return nil, &rpcError{code: rpcCompileFailed, message: err.Error(), data: result}
+	}
+	return result, nil
+}
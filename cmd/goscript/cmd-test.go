@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/aperturerobotics/cli"
+	jsoniter "github.com/aperturerobotics/json-iterator-lite"
 	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler"
 	"github.com/s4wave/goscript/compiler/gotest"
 )
 
@@ -36,6 +38,20 @@ func newTestCommand() *cli.Command {
 	var cpuProfile string
 	var memProfile string
 	var incrementalTypeCheck bool
+	var sourceMaps bool
+	var preemptLoops bool
+	var diagnosticsFormat string
+	var resultJSON bool
+	var jsonEvents bool
+	var cover bool
+	var coverMode string
+	var coverProfile string
+	var coverPackages cli.StringSlice
+	var bench string
+	var benchTime string
+	var benchMem bool
+	var fuzz string
+	var fuzzTime string
 
 	return &cli.Command{
 		Name:     "test",
@@ -51,13 +67,37 @@ func newTestCommand() *cli.Command {
 				Count:                count,
 				Short:                short,
 				Timeout:              timeout,
-				Verbose:              verbose,
+				Verbose:              verbose || jsonEvents,
 				WorkDir:              workDir,
 				OutputRoot:           outputRoot,
 				Parallelism:          parallelism,
 				RuntimeBackend:       testRuntimeBackend(browser),
 				RuntimeGroups:        runtimeGroups,
 				IncrementalTypeCheck: incrementalTypeCheck,
+				SourceMaps:           sourceMaps,
+				PreemptLoops:         preemptLoops,
+				Cover:                cover,
+				CoverMode:            gotest.CoverMode(coverMode),
+				CoverProfile:         coverProfile,
+				CoverPackages:        coverPackages.Value(),
+				Bench:                bench,
+				BenchTime:            benchTime,
+				BenchMem:             benchMem,
+				Fuzz:                 fuzz,
+				FuzzTime:             fuzzTime,
+			}
+			format, err := compiler.ParseDiagnosticFormat(diagnosticsFormat)
+			if err != nil {
+				return err
+			}
+			if resultJSON && format != compiler.DiagnosticFormatText {
+				return errors.New("--result-json cannot be combined with --diagnostics-format " + string(format))
+			}
+			if jsonEvents && resultJSON {
+				return errors.New("--json cannot be combined with --result-json")
+			}
+			if jsonEvents && format != compiler.DiagnosticFormatText {
+				return errors.New("--json cannot be combined with --diagnostics-format " + string(format))
 			}
 			stopProfile, err := startCPUProfile(cpuProfile)
 			if err != nil {
@@ -71,13 +111,43 @@ func newTestCommand() *cli.Command {
 					err = profileErr
 				}
 			}()
+			var events *testEventStream
+			if jsonEvents {
+				events = newTestEventStream(c.App.Writer)
+				req.RuntimeOutput = events.runtimeLine
+			}
 			result, err := gotest.NewRunner().Run(c.Context, req)
 			if err != nil {
 				return err
 			}
-			if err := printTestResult(c.Context, c.App.Writer, result); err != nil {
+			// Keep stdout parseable when a machine-readable report is requested.
+			resultWriter := c.App.Writer
+			if format != compiler.DiagnosticFormatText || resultJSON {
+				resultWriter = c.App.ErrWriter
+			}
+			if jsonEvents {
+				if err := events.finish(result, coverPackages.Value()); err != nil {
+					return err
+				}
+				if err := printWorkDir(c.App.ErrWriter, result); err != nil {
+					return err
+				}
+			} else if err := printTestResult(c.Context, resultWriter, result, coverPackages.Value()); err != nil {
 				return err
 			}
+			if resultJSON {
+				err := writeResultJSON(c.App.Writer, func(stream *jsoniter.Stream) {
+					writeTestResult(stream, result)
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if format != compiler.DiagnosticFormatText {
+				if err := compiler.WriteDiagnostics(c.App.Writer, format, result.Diagnostics); err != nil {
+					return err
+				}
+			}
 			if !result.Passed() {
 				return errors.New("goscript test failed")
 			}
@@ -159,6 +229,79 @@ func newTestCommand() *cli.Command {
 				Usage:       "reuse TypeScript build-info files in the test workdir",
 				Destination: &incrementalTypeCheck,
 			},
+			&cli.BoolFlag{
+				Name:        "source-maps",
+				Usage:       "emit source maps that point generated TypeScript back to the Go source",
+				Destination: &sourceMaps,
+			},
+			&cli.BoolFlag{
+				Name:        "preempt-loops",
+				Usage:       "let long-running loops in goroutines yield to other goroutines",
+				Destination: &preemptLoops,
+			},
+			&cli.BoolFlag{
+				Name:        "cover",
+				Usage:       "measure statement coverage of the tested packages",
+				Destination: &cover,
+			},
+			&cli.StringFlag{
+				Name:        "covermode",
+				Usage:       "coverage mode: set, count, or atomic",
+				Destination: &coverMode,
+				Value:       string(gotest.CoverModeSet),
+			},
+			&cli.StringFlag{
+				Name:        "coverprofile",
+				Usage:       "write a Go coverage profile after the tests run; implies --cover",
+				Destination: &coverProfile,
+			},
+			&cli.StringSliceFlag{
+				Name:        "coverpkg",
+				Usage:       "comma-separated package patterns to measure coverage of instead of each tested package; implies --cover",
+				Destination: &coverPackages,
+			},
+			&cli.StringFlag{
+				Name:        "bench",
+				Usage:       "run benchmarks matching the regexp after the tests pass",
+				Destination: &bench,
+			},
+			&cli.StringFlag{
+				Name:        "benchtime",
+				Usage:       "run each benchmark for this duration, or N times with the Nx form",
+				Destination: &benchTime,
+				Value:       "1s",
+			},
+			&cli.BoolFlag{
+				Name:        "benchmem",
+				Usage:       "print memory statistics for benchmarks",
+				Destination: &benchMem,
+			},
+			&cli.StringFlag{
+				Name:        "fuzz",
+				Usage:       "fuzz the one fuzz target matching the regexp after the tests and seed corpora pass",
+				Destination: &fuzz,
+			},
+			&cli.StringFlag{
+				Name:        "fuzztime",
+				Usage:       "fuzz for this duration, or N inputs with the Nx form; until a failure by default",
+				Destination: &fuzzTime,
+			},
+			&cli.StringFlag{
+				Name:        "diagnostics-format",
+				Usage:       "diagnostics output format: text, json, or sarif",
+				Destination: &diagnosticsFormat,
+				Value:       string(compiler.DiagnosticFormatText),
+			},
+			&cli.BoolFlag{
+				Name:        "result-json",
+				Usage:       "write the test result as one JSON document to stdout and the summary to stderr",
+				Destination: &resultJSON,
+			},
+			&cli.BoolFlag{
+				Name:        "json",
+				Usage:       "write go test -json events to stdout; implies -v",
+				Destination: &jsonEvents,
+			},
 			&cli.StringFlag{
 				Name:        "cpuprofile",
 				Usage:       "write a Go CPU profile for the goscript test process",
@@ -216,7 +359,7 @@ func writeMemProfile(path string) error {
 	return nil
 }
 
-func printTestResult(ctx context.Context, w io.Writer, result *gotest.Result) error {
+func printTestResult(ctx context.Context, w io.Writer, result *gotest.Result, coverPackages []string) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -229,33 +372,41 @@ func printTestResult(ctx context.Context, w io.Writer, result *gotest.Result) er
 				return err
 			}
 		}
-		switch pkg.Action {
-		case gotest.ActionPass:
-			if _, err := io.WriteString(w, "ok  \t"+pkg.PackagePath+"\t"+formatElapsed(pkg.Elapsed)+"\n"); err != nil {
-				return err
-			}
-		case gotest.ActionSkip:
-			if _, err := io.WriteString(w, "?   \t"+pkg.PackagePath+"\t[no test files]\n"); err != nil {
-				return err
-			}
-		case gotest.ActionFail:
-			line := "FAIL\t" + pkg.PackagePath
-			if pkg.Owner != "" {
-				line += "\towner=" + string(pkg.Owner)
-			}
-			if phase := failedPhase(pkg.Phases); phase != "" {
-				line += "\tphase=" + phase
-			}
-			if _, err := io.WriteString(w, line+"\n"); err != nil {
-				return err
-			}
-			if strings.TrimSpace(pkg.Error) != "" {
-				if _, err := io.WriteString(w, strings.TrimSpace(pkg.Error)+"\n"); err != nil {
-					return err
-				}
-			}
+		if _, err := io.WriteString(w, packageSummary(&pkg, coverPackages)); err != nil {
+			return err
 		}
 	}
+	return printWorkDir(w, result)
+}
+
+// packageSummary returns the lines go test prints after the output of a
+// package: its ok, FAIL, or no test files line, with the failed phase, owner,
+// and error of a package that failed before its tests ran.
+func packageSummary(pkg *gotest.PackageResult, coverPackages []string) string {
+	switch pkg.Action {
+	case gotest.ActionPass:
+		return "ok  \t" + pkg.PackagePath + "\t" + formatElapsed(pkg.Elapsed) + formatCoverage(pkg.Coverage, coverPackages) + "\n"
+	case gotest.ActionSkip:
+		return "?   \t" + pkg.PackagePath + "\t[no test files]\n"
+	case gotest.ActionFail:
+		line := "FAIL\t" + pkg.PackagePath
+		if pkg.Owner != "" {
+			line += "\towner=" + string(pkg.Owner)
+		}
+		if phase := failedPhase(pkg.Phases); phase != "" {
+			line += "\tphase=" + phase
+		}
+		line += "\n"
+		if strings.TrimSpace(pkg.Error) != "" {
+			line += strings.TrimSpace(pkg.Error) + "\n"
+		}
+		return line
+	default:
+		return ""
+	}
+}
+
+func printWorkDir(w io.Writer, result *gotest.Result) error {
 	if result.WorkDir != "" {
 		if _, err := io.WriteString(w, "goscript test workdir: "+result.WorkDir+"\n"); err != nil {
 			return err
@@ -291,6 +442,23 @@ func formatElapsed(elapsed time.Duration) string {
 	return strconv.FormatInt(seconds, 10) + "." + leftPadMillis(remainder) + "s"
 }
 
+// formatCoverage returns the coverage summary go test appends to a package
+// line, or "" when the package did not report coverage.
+func formatCoverage(coverage *gotest.Coverage, coverPackages []string) string {
+	if coverage == nil {
+		return ""
+	}
+	total, covered := coverage.Statements()
+	if total == 0 {
+		return "\tcoverage: [no statements]"
+	}
+	summary := "\tcoverage: " + strconv.FormatFloat(100*float64(covered)/float64(total), 'f', 1, 64) + "% of statements"
+	if len(coverPackages) != 0 {
+		summary += " in " + strings.Join(coverPackages, ",")
+	}
+	return summary
+}
+
 func leftPadMillis(value int64) string {
 	raw := strconv.FormatInt(value, 10)
 	for len(raw) < 3 {
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/s4wave/goscript/compiler/gotest"
 )
 
 func TestTestCommandHelp(t *testing.T) {
@@ -17,7 +19,7 @@ func TestTestCommandHelp(t *testing.T) {
 		t.Fatalf("test help failed: %v", err)
 	}
 	help := out.String()
-	for _, expected := range []string{"compile and run Go package tests through GoScript", "--tags", "--run", "--count", "--short", "--timeout", "-p", "--runtime-groups", "--browser", "--cpuprofile", "--memprofile"} {
+	for _, expected := range []string{"compile and run Go package tests through GoScript", "--tags", "--run", "--count", "--short", "--timeout", "-p", "--runtime-groups", "--browser", "--cpuprofile", "--memprofile", "--cover", "--covermode", "--coverprofile", "--coverpkg", "--bench", "--benchtime", "--benchmem", "--fuzz", "--fuzztime", "--json"} {
 		if !strings.Contains(help, expected) {
 			t.Fatalf("help output missing %q:\n%s", expected, help)
 		}
@@ -66,7 +68,7 @@ func TestTestCommandRunsPackageTest(t *testing.T) {
 
 func TestTestCommandRejectsUnsupportedFlags(t *testing.T) {
 	app := newApp()
-	err := app.Run([]string{"goscript", "test", "--cover", "."})
+	err := app.Run([]string{"goscript", "test", "--race", "."})
 	if err == nil {
 		t.Fatalf("expected unsupported flag to fail")
 	}
@@ -74,3 +76,22 @@ func TestTestCommandRejectsUnsupportedFlags(t *testing.T) {
 		t.Fatalf("unexpected unsupported flag error: %v", err)
 	}
 }
+
+func TestFormatCoverageMatchesGoTest(t *testing.T) {
+	coverage := &gotest.Coverage{Blocks: []gotest.CoverageBlock{
+		{File: "example.test/cover/cover.go", Statements: 2, Count: 1},
+		{File: "example.test/cover/cover.go", Statements: 1},
+	}}
+	if got := formatCoverage(coverage, nil); got != "\tcoverage: 66.7% of statements" {
+		t.Fatalf("unexpected coverage summary %q", got)
+	}
+	if got := formatCoverage(coverage, []string{"./..."}); got != "\tcoverage: 66.7% of statements in ./..." {
+		t.Fatalf("unexpected -coverpkg summary %q", got)
+	}
+	if got := formatCoverage(&gotest.Coverage{}, nil); got != "\tcoverage: [no statements]" {
+		t.Fatalf("unexpected empty summary %q", got)
+	}
+	if got := formatCoverage(nil, nil); got != "" {
+		t.Fatalf("expected no summary without coverage, got %q", got)
+	}
+}
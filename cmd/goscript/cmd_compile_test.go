@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/s4wave/goscript/compiler"
 )
 
 func TestCompileCommandForwardsBuildFlags(t *testing.T) {
@@ -211,6 +216,193 @@ func TestCompileCommandForwardsPackageBlocklist(t *testing.T) {
 	}
 }
 
+func TestCompileCommandForwardsSourceMaps(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "output")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/cli\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "main.go"), strings.Join([]string{
+		"package cli",
+		"func Value() int { return 1 }",
+		"",
+	}, "\n"))
+
+	app := newApp()
+	err := app.Run([]string{
+		"goscript",
+		"compile",
+		"--package",
+		".",
+		"--output",
+		outputDir,
+		"--dir",
+		dir,
+		"--source-maps",
+	})
+	if err != nil {
+		t.Fatalf("compile command failed: %v", err)
+	}
+
+	generatedPath := filepath.Join(outputDir, "@goscript", "example.test", "cli", "main.gs.ts")
+	generated, err := os.ReadFile(generatedPath)
+	if err != nil {
+		t.Fatalf("read generated file: %v", err)
+	}
+	if !strings.Contains(string(generated), "//# sourceMappingURL=main.gs.ts.map") {
+		t.Fatalf("expected sourceMappingURL comment, got:\n%s", generated)
+	}
+	sourceMap, err := os.ReadFile(generatedPath + ".map")
+	if err != nil {
+		t.Fatalf("read source map: %v", err)
+	}
+	if !strings.Contains(string(sourceMap), "main.go") {
+		t.Fatalf("expected source map to reference main.go, got:\n%s", sourceMap)
+	}
+}
+
+func TestCompileCommandWritesJSONDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "output")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/cli\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "package cli\n\nfunc Make[T ~[]int]() T {\n\treturn make(T, 1)\n}\n")
+
+	app := newApp()
+	var stdout bytes.Buffer
+	app.Writer = &stdout
+	err := app.Run([]string{
+		"goscript",
+		"compile",
+		"--package",
+		".",
+		"--output",
+		outputDir,
+		"--dir",
+		dir,
+		"--diagnostics-format=json",
+	})
+	if err == nil {
+		t.Fatal("expected compile failure")
+	}
+
+	var report struct {
+		Packages []struct {
+			Package     string `json:"package"`
+			Diagnostics []struct {
+				Severity string `json:"severity"`
+				Code     string `json:"code"`
+				Stage    string `json:"stage"`
+				Position *struct {
+					File string `json:"file"`
+					Line int    `json:"line"`
+				} `json:"position"`
+			} `json:"diagnostics"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode diagnostics report: %v\n%s", err, stdout.String())
+	}
+	if len(report.Packages) != 1 || report.Packages[0].Package != "example.test/cli" {
+		t.Fatalf("expected one example.test/cli package group, got:\n%s", stdout.String())
+	}
+	diags := report.Packages[0].Diagnostics
+	if len(diags) != 1 {
+		t.Fatalf("expected one diagnostic, got:\n%s", stdout.String())
+	}
+	diag := diags[0]
+	if diag.Severity != "error" || diag.Code != "goscript/lowering:unsupported" || diag.Stage != "lowering" {
+		t.Fatalf("unexpected diagnostic fields:\n%s", stdout.String())
+	}
+	if diag.Position == nil || diag.Position.File != "main.go" || diag.Position.Line != 4 {
+		t.Fatalf("unexpected diagnostic position:\n%s", stdout.String())
+	}
+}
+
+func TestCompileCommandWritesResultJSON(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "output")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/cli\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "package cli\n\nconst Value = 1\n")
+	writeFile(t, filepath.Join(dir, "sub", "sub.go"), "package sub\n\nconst Value = 2\n")
+
+	app := newApp()
+	var stdout bytes.Buffer
+	app.Writer = &stdout
+	if err := app.Run([]string{
+		"goscript",
+		"compile",
+		"--package",
+		".",
+		"--package",
+		"./sub",
+		"--output",
+		outputDir,
+		"--dir",
+		dir,
+		"--result-json",
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var result compiler.CompilationResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decode compilation result: %v\n%s", err, stdout.String())
+	}
+	if !slices.Equal(result.CompiledPackages, []string{"example.test/cli", "example.test/cli/sub"}) {
+		t.Fatalf("unexpected compiled packages:\n%s", stdout.String())
+	}
+	if result.Diagnostics == nil || result.CopiedPackages == nil {
+		t.Fatalf("expected list fields to encode as arrays:\n%s", stdout.String())
+	}
+}
+
+func TestCompileCommandWritesResultJSONOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/cli\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "package cli\n\nfunc Make[T ~[]int]() T {\n\treturn make(T, 1)\n}\n")
+
+	app := newApp()
+	var stdout bytes.Buffer
+	app.Writer = &stdout
+	err := app.Run([]string{
+		"goscript",
+		"compile",
+		"--package",
+		".",
+		"--output",
+		filepath.Join(dir, "output"),
+		"--dir",
+		dir,
+		"--result-json",
+	})
+	if err == nil || !strings.Contains(err.Error(), "main.go:4:") {
+		t.Fatalf("expected positioned compile error, got %v", err)
+	}
+
+	var result compiler.CompilationResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decode compilation result: %v\n%s", err, stdout.String())
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != "goscript/lowering:unsupported" {
+		t.Fatalf("unexpected diagnostics:\n%s", stdout.String())
+	}
+	if pos := result.Diagnostics[0].Position; pos == nil || pos.Line != 4 {
+		t.Fatalf("unexpected diagnostic position:\n%s", stdout.String())
+	}
+}
+
+func TestCompileCommandRejectsUnknownDiagnosticsFormat(t *testing.T) {
+	app := newApp()
+	err := app.Run([]string{
+		"goscript",
+		"compile",
+		"--package",
+		".",
+		"--diagnostics-format=xml",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown diagnostics format") {
+		t.Fatalf("expected unknown diagnostics format error, got %v", err)
+	}
+}
+
 func TestCompileCommandForwardsCompilerCacheRoot(t *testing.T) {
 	dir := t.TempDir()
 	outputDir := filepath.Join(dir, "output")
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExplainAsyncCommandPrintsChain(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/cli\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "main.go"), strings.Join([]string{
+		"package cli",
+		"func wait(ch chan int) int { return <-ch }",
+		"func Helper(ch chan int) int { return wait(ch) + 1 }",
+		"",
+	}, "\n"))
+
+	app := newApp()
+	var stdout bytes.Buffer
+	app.Writer = &stdout
+	if err := app.Run([]string{"goscript", "explain", "async", "--dir", dir, "Helper"}); err != nil {
+		t.Fatalf("explain command failed: %v", err)
+	}
+	want := strings.Join([]string{
+		"example.test/cli.Helper is async",
+		"  example.test/cli.Helper (main.go:3): calls example.test/cli.wait",
+		"  example.test/cli.wait (main.go:2): receives from a channel",
+		"root cause: channel-receive",
+		"",
+	}, "\n")
+	if stdout.String() != want {
+		t.Fatalf("explain output = %q, want %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if err := app.Run([]string{"goscript", "explain", "async", "--dir", dir}); err != nil {
+		t.Fatalf("explain report failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "channel-receive (2 functions)\n  example.test/cli.Helper via example.test/cli.wait\n") {
+		t.Fatalf("unexpected explain report:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/s4wave/goscript/compiler"
+)
+
+func TestServeCommandAnswersJSONRPCRequests(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "output")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.test/serve\n\ngo 1.25.3\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() { println(\"hi\") }\n")
+	writeFile(t, filepath.Join(dir, "broken", "broken.go"), "package broken\n\nfunc Broken() { missing() }\n")
+
+	params := `{"Config":{"OutputPath":` + jsonString(outputDir) + `},"Packages":["."]}`
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"compile","params":` + params + `}`,
+		`{"jsonrpc":"2.0","id":2,"method":"changes"}`,
+		`{"jsonrpc":"2.0","method":"changes"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"compile","params":{"Config":{"OutputPath":` + jsonString(outputDir) + `},"Packages":["./broken"]}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"nope"}`,
+		`not json`,
+		`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"changes"}`,
+		"",
+	}, "\n")
+
+	var stdout bytes.Buffer
+	app := newApp()
+	app.Reader = strings.NewReader(input)
+	app.Writer = &stdout
+	if err := app.Run([]string{"goscript", "serve", "--dir", dir}); err != nil {
+		t.Fatalf("serve command failed: %v", err)
+	}
+
+	type response struct {
+		ID     json.RawMessage
+		Result json.RawMessage
+		Error  *struct {
+			Code    int
+			Message string
+			Data    *compiler.CompilationResult
+		}
+	}
+	var responses []response
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		var resp response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response %q: %v", scanner.Text(), err)
+		}
+		responses = append(responses, resp)
+	}
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses, got %d:\n%s", len(responses), stdout.String())
+	}
+
+	var compiled compiler.CompilationResult
+	if err := json.Unmarshal(responses[0].Result, &compiled); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !slices.Equal(compiled.CompiledPackages, []string{"example.test/serve"}) {
+		t.Fatalf("unexpected compile result: %#v", compiled)
+	}
+	var changes compiler.PackageGraphChanges
+	if err := json.Unmarshal(responses[1].Result, &changes); err != nil {
+		t.Fatal(err.Error())
+	}
+	if changes.Files == nil || len(changes.Files) != 0 {
+		t.Fatalf("expected an empty changed file list, got %s", responses[1].Result)
+	}
+	failed := responses[2].Error
+	if string(responses[2].ID) != "3" || failed == nil || failed.Code != rpcCompileFailed || failed.Data == nil {
+		t.Fatalf("expected a compile failure with result data, got %#v", responses[2])
+	}
+	if len(failed.Data.Diagnostics) == 0 || failed.Data.Diagnostics[0].Severity != compiler.DiagnosticSeverityError {
+		t.Fatalf("expected error diagnostics, got %#v", failed.Data.Diagnostics)
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != rpcMethodNotFound {
+		t.Fatalf("expected method not found, got %#v", responses[3])
+	}
+	if responses[4].Error == nil || responses[4].Error.Code != rpcParseError || string(responses[4].ID) != "null" {
+		t.Fatalf("expected a parse error, got %#v", responses[4])
+	}
+	if string(responses[5].ID) != "5" || string(responses[5].Result) != "null" || responses[5].Error != nil {
+		t.Fatalf("expected a shutdown acknowledgement, got %#v", responses[5])
+	}
+}
+
+func jsonString(value string) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
@@ -27,7 +27,11 @@ func newApp() *cli.App {
 
 	app.Usage = "GoScript compiles Go to Typescript."
 	app.Commands = append(app.Commands, compileCommands()...)
+	app.Commands = append(app.Commands, runCommands()...)
+	app.Commands = append(app.Commands, buildCommands()...)
 	app.Commands = append(app.Commands, testCommands()...)
+	app.Commands = append(app.Commands, explainCommands()...)
+	app.Commands = append(app.Commands, serveCommands()...)
 
 	return app
 }
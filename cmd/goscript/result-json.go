@@ -0,0 +1,270 @@
+package main
+
+import (
+	"io"
+
+	jsoniter "github.com/aperturerobotics/json-iterator-lite"
+	"github.com/s4wave/goscript/compiler"
+	"github.com/s4wave/goscript/compiler/gotest"
+)
+
+// writeResultJSON writes one indented JSON document. Fields use the Go field
+// names of the result types, and list fields are always arrays.
+func writeResultJSON(w io.Writer, write func(stream *jsoniter.Stream)) error {
+	stream := jsoniter.NewStream(w, 4096, 2)
+	write(stream)
+	stream.WriteRaw("\n")
+	if stream.Error != nil {
+		return stream.Error
+	}
+	return stream.Flush()
+}
+
+func writeCompilationResult(stream *jsoniter.Stream, result *compiler.CompilationResult) {
+	stream.WriteObjectStart()
+	writeStringListField(stream, "CompiledPackages", result.CompiledPackages)
+	stream.WriteMore()
+	writeStringListField(stream, "CopiedPackages", result.CopiedPackages)
+	stream.WriteMore()
+	writeStringListField(stream, "OriginalPackages", result.OriginalPackages)
+	stream.WriteMore()
+	writeDiagnosticsField(stream, result.Diagnostics)
+	stream.WriteMore()
+	stream.WriteObjectField("DeadCode")
+	writeList(stream, result.DeadCode, func(pkg compiler.DeadCodePackage) {
+		stream.WriteObjectStart()
+		stream.WriteObjectField("Package")
+		stream.WriteString(pkg.Package)
+		stream.WriteMore()
+		stream.WriteObjectField("Declarations")
+		stream.WriteInt(pkg.Declarations)
+		stream.WriteMore()
+		stream.WriteObjectField("RemovedFunctions")
+		stream.WriteInt(pkg.RemovedFunctions)
+		stream.WriteMore()
+		stream.WriteObjectField("RemovedTypes")
+		stream.WriteInt(pkg.RemovedTypes)
+		stream.WriteMore()
+		stream.WriteObjectField("RemovedValues")
+		stream.WriteInt(pkg.RemovedValues)
+		stream.WriteObjectEnd()
+	})
+	stream.WriteMore()
+	stream.WriteObjectField("RuntimeHelpers")
+	writeList(stream, result.RuntimeHelpers, func(helper compiler.RuntimeHelper) {
+		stream.WriteString(string(helper))
+	})
+	stream.WriteObjectEnd()
+}
+
+func writeTestResult(stream *jsoniter.Stream, result *gotest.Result) {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("WorkDir")
+	stream.WriteString(result.WorkDir)
+	stream.WriteMore()
+	stream.WriteObjectField("OutputRoot")
+	stream.WriteString(result.OutputRoot)
+	stream.WriteMore()
+	stream.WriteObjectField("Packages")
+	writeList(stream, result.Packages, func(pkg gotest.PackageResult) {
+		writeTestPackageResult(stream, &pkg)
+	})
+	stream.WriteMore()
+	writeDiagnosticsField(stream, result.Diagnostics)
+	stream.WriteObjectEnd()
+}
+
+func writeTestPackageResult(stream *jsoniter.Stream, pkg *gotest.PackageResult) {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("PackagePath")
+	stream.WriteString(pkg.PackagePath)
+	stream.WriteMore()
+	stream.WriteObjectField("SourceDir")
+	stream.WriteString(pkg.SourceDir)
+	stream.WriteMore()
+	stream.WriteObjectField("TestPackagePath")
+	stream.WriteString(pkg.TestPackagePath)
+	stream.WriteMore()
+	writeStringListField(stream, "TestImports", pkg.TestImports)
+	stream.WriteMore()
+	writeTestListField(stream, "Tests", pkg.Tests)
+	stream.WriteMore()
+	writeTestListField(stream, "Benchmarks", pkg.Benchmarks)
+	stream.WriteMore()
+	writeTestListField(stream, "FuzzTargets", pkg.FuzzTargets)
+	stream.WriteMore()
+	stream.WriteObjectField("Examples")
+	writeList(stream, pkg.Examples, func(example gotest.Example) {
+		stream.WriteObjectStart()
+		stream.WriteObjectField("Name")
+		stream.WriteString(example.Name)
+		stream.WriteMore()
+		stream.WriteObjectField("PackagePath")
+		stream.WriteString(example.PackagePath)
+		stream.WriteMore()
+		stream.WriteObjectField("Output")
+		stream.WriteString(example.Output)
+		stream.WriteMore()
+		stream.WriteObjectField("Unordered")
+		stream.WriteBool(example.Unordered)
+		stream.WriteObjectEnd()
+	})
+	stream.WriteMore()
+	stream.WriteObjectField("TestMain")
+	if pkg.TestMain == nil {
+		stream.WriteNil()
+	} else {
+		writeTest(stream, *pkg.TestMain)
+	}
+	stream.WriteMore()
+	stream.WriteObjectField("Action")
+	stream.WriteString(string(pkg.Action))
+	stream.WriteMore()
+	stream.WriteObjectField("Phases")
+	stream.WriteObjectStart()
+	for idx, phase := range []struct {
+		name   string
+		status gotest.PhaseStatus
+	}{
+		{"Workspace", pkg.Phases.Workspace},
+		{"Compile", pkg.Phases.Compile},
+		{"Emit", pkg.Phases.Emit},
+		{"TypeCheck", pkg.Phases.TypeCheck},
+		{"Runtime", pkg.Phases.Runtime},
+	} {
+		if idx != 0 {
+			stream.WriteMore()
+		}
+		stream.WriteObjectField(phase.name)
+		stream.WriteString(string(phase.status))
+	}
+	stream.WriteObjectEnd()
+	stream.WriteMore()
+	stream.WriteObjectField("Owner")
+	stream.WriteString(string(pkg.Owner))
+	stream.WriteMore()
+	stream.WriteObjectField("Error")
+	stream.WriteString(pkg.Error)
+	stream.WriteMore()
+	stream.WriteObjectField("Output")
+	stream.WriteString(pkg.Output)
+	stream.WriteMore()
+	stream.WriteObjectField("Elapsed")
+	stream.WriteInt64(int64(pkg.Elapsed))
+	stream.WriteMore()
+	stream.WriteObjectField("Coverage")
+	if pkg.Coverage == nil {
+		stream.WriteNil()
+	} else {
+		stream.WriteObjectStart()
+		stream.WriteObjectField("Blocks")
+		writeList(stream, pkg.Coverage.Blocks, func(block gotest.CoverageBlock) {
+			stream.WriteObjectStart()
+			stream.WriteObjectField("File")
+			stream.WriteString(block.File)
+			stream.WriteMore()
+			stream.WriteObjectField("StartLine")
+			stream.WriteInt(block.StartLine)
+			stream.WriteMore()
+			stream.WriteObjectField("StartCol")
+			stream.WriteInt(block.StartCol)
+			stream.WriteMore()
+			stream.WriteObjectField("EndLine")
+			stream.WriteInt(block.EndLine)
+			stream.WriteMore()
+			stream.WriteObjectField("EndCol")
+			stream.WriteInt(block.EndCol)
+			stream.WriteMore()
+			stream.WriteObjectField("Statements")
+			stream.WriteInt(block.Statements)
+			stream.WriteMore()
+			stream.WriteObjectField("Count")
+			stream.WriteInt64(block.Count)
+			stream.WriteObjectEnd()
+		})
+		stream.WriteObjectEnd()
+	}
+	stream.WriteObjectEnd()
+}
+
+func writeTestListField(stream *jsoniter.Stream, field string, tests []gotest.Test) {
+	stream.WriteObjectField(field)
+	writeList(stream, tests, func(test gotest.Test) {
+		writeTest(stream, test)
+	})
+}
+
+func writeTest(stream *jsoniter.Stream, test gotest.Test) {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("Name")
+	stream.WriteString(test.Name)
+	stream.WriteMore()
+	stream.WriteObjectField("PackagePath")
+	stream.WriteString(test.PackagePath)
+	stream.WriteObjectEnd()
+}
+
+func writeDiagnosticsField(stream *jsoniter.Stream, diagnostics []compiler.Diagnostic) {
+	stream.WriteObjectField("Diagnostics")
+	writeList(stream, diagnostics, func(diag compiler.Diagnostic) {
+		stream.WriteObjectStart()
+		stream.WriteObjectField("Severity")
+		stream.WriteString(string(diag.Severity))
+		stream.WriteMore()
+		stream.WriteObjectField("Code")
+		stream.WriteString(diag.Code)
+		stream.WriteMore()
+		stream.WriteObjectField("Message")
+		stream.WriteString(diag.Message)
+		stream.WriteMore()
+		stream.WriteObjectField("Detail")
+		stream.WriteString(diag.Detail)
+		stream.WriteMore()
+		stream.WriteObjectField("Position")
+		if diag.Position == nil {
+			stream.WriteNil()
+		} else {
+			stream.WriteObjectStart()
+			stream.WriteObjectField("File")
+			stream.WriteString(diag.Position.File)
+			stream.WriteMore()
+			stream.WriteObjectField("DisplayFile")
+			stream.WriteString(diag.Position.DisplayFile)
+			stream.WriteMore()
+			stream.WriteObjectField("Line")
+			stream.WriteInt(diag.Position.Line)
+			stream.WriteMore()
+			stream.WriteObjectField("Column")
+			stream.WriteInt(diag.Position.Column)
+			stream.WriteObjectEnd()
+		}
+		stream.WriteMore()
+		stream.WriteObjectField("Stage")
+		stream.WriteString(string(diag.Stage))
+		stream.WriteMore()
+		stream.WriteObjectField("Package")
+		stream.WriteString(diag.Package)
+		stream.WriteObjectEnd()
+	})
+}
+
+func writeStringListField(stream *jsoniter.Stream, field string, values []string) {
+	stream.WriteObjectField(field)
+	writeList(stream, values, stream.WriteString)
+}
+
+// writeList writes values as a JSON array, which is empty when values is nil.
+func writeList[T any](stream *jsoniter.Stream, values []T, write func(T)) {
+	if len(values) == 0 {
+		stream.WriteEmptyArray()
+		return
+	}
+	stream.WriteArrayStart()
+	for idx, value := range values {
+		if idx != 0 {
+			stream.WriteMore()
+		}
+		write(value)
+	}
+	stream.WriteArrayEnd()
+}
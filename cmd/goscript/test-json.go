@@ -0,0 +1,255 @@
+package main
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/aperturerobotics/json-iterator-lite"
+	"github.com/s4wave/goscript/compiler/gotest"
+)
+
+// testEvent is one event of the go test -json stream, as cmd/test2json
+// documents it. Empty fields other than Time and Action are left out.
+type testEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed *float64
+	Output  string
+}
+
+// writeTestEvent writes event as one line of the event stream.
+func writeTestEvent(stream *jsoniter.Stream, event *testEvent) error {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("Time")
+	stream.WriteString(event.Time.Format(time.RFC3339Nano))
+	stream.WriteMore()
+	stream.WriteObjectField("Action")
+	stream.WriteString(event.Action)
+	if event.Package != "" {
+		stream.WriteMore()
+		stream.WriteObjectField("Package")
+		stream.WriteString(event.Package)
+	}
+	if event.Test != "" {
+		stream.WriteMore()
+		stream.WriteObjectField("Test")
+		stream.WriteString(event.Test)
+	}
+	if event.Elapsed != nil {
+		stream.WriteMore()
+		stream.WriteObjectField("Elapsed")
+		stream.WriteFloat64(*event.Elapsed)
+	}
+	if event.Output != "" {
+		stream.WriteMore()
+		stream.WriteObjectField("Output")
+		stream.WriteString(event.Output)
+	}
+	stream.WriteObjectEnd()
+	stream.WriteRaw("\n")
+	if stream.Error != nil {
+		return stream.Error
+	}
+	return stream.Flush()
+}
+
+// testEventConverter turns the verbose output of one package test run into
+// test events the way cmd/test2json does.
+type testEventConverter struct {
+	stream *jsoniter.Stream
+	pkg    string
+	// now is the time events are stamped with: when the line they convert
+	// was read.
+	now time.Time
+	// testName is the test that the following output lines belong to.
+	testName string
+	// report holds the pass, fail, and skip events of a test and its
+	// subtests, which are written once the report lines below them end.
+	report []testEvent
+	err    error
+}
+
+var (
+	testEventUpdates = []string{"=== RUN   ", "=== PAUSE ", "=== CONT  ", "=== NAME  ", "=== FUZZ  "}
+	testEventReports = []string{"--- PASS: ", "--- FAIL: ", "--- SKIP: ", "--- BENCH: "}
+)
+
+// testEventStream writes the go test -json event stream of a test run.
+// Runtime output lines are converted as the packages write them; the rest of
+// each package is written when the run finishes.
+type testEventStream struct {
+	mu         sync.Mutex
+	stream     *jsoniter.Stream
+	converters map[string]*testEventConverter
+	// streamed records the packages whose runtime output was converted as
+	// it was written.
+	streamed map[string]bool
+}
+
+// newTestEventStream creates a test event stream writing to w.
+func newTestEventStream(w io.Writer) *testEventStream {
+	return &testEventStream{
+		stream:     jsoniter.NewStream(w, 4096, 0),
+		converters: make(map[string]*testEventConverter),
+		streamed:   make(map[string]bool),
+	}
+}
+
+// runtimeLine converts a line of runtime output of pkg as it is read. It is
+// a gotest.Request RuntimeOutput callback.
+func (s *testEventStream) runtimeLine(pkg string, line string) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.converter(pkg, now)
+	c.now = now
+	c.line(line)
+	s.streamed[pkg] = true
+}
+
+// finish writes what is left of every package of result: the output that
+// was not streamed, the package summary, and the final pass, fail, or skip
+// event. Failures before the tests run are reported as package output naming
+// the failed phase and its owner.
+func (s *testEventStream) finish(result *gotest.Result, coverPackages []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pkg := range result.Packages {
+		c := s.converter(pkg.PackagePath, time.Now())
+		if output := strings.TrimSpace(pkg.Output); output != "" && !s.streamed[pkg.PackagePath] {
+			for line := range strings.SplitSeq(output, "\n") {
+				c.now = time.Now()
+				c.line(line)
+			}
+		}
+		c.now = time.Now()
+		c.flushReport(0)
+		for line := range strings.SplitSeq(strings.TrimSuffix(packageSummary(&pkg, coverPackages), "\n"), "\n") {
+			c.output(line)
+		}
+		elapsed := pkg.Elapsed.Round(time.Millisecond).Seconds()
+		c.write(testEvent{Action: string(pkg.Action), Elapsed: &elapsed})
+		if c.err != nil {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// converter returns the converter of pkg, writing its start event stamped
+// now when the package has none yet.
+func (s *testEventStream) converter(pkg string, now time.Time) *testEventConverter {
+	c, ok := s.converters[pkg]
+	if !ok {
+		c = &testEventConverter{stream: s.stream, pkg: pkg, now: now}
+		s.converters[pkg] = c
+		c.write(testEvent{Action: "start"})
+	}
+	return c
+}
+
+// line converts one line of test output.
+func (c *testEventConverter) line(line string) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "PASS" || trimmed == "FAIL" || strings.HasPrefix(line, "FAIL\t") {
+		c.flushReport(0)
+		c.output(line)
+		return
+	}
+
+	update := hasAnyPrefix(line, testEventUpdates)
+	rest, indent := line, 0
+	report := false
+	if !update {
+		for strings.HasPrefix(rest, "    ") {
+			rest = rest[4:]
+			indent++
+		}
+		report = hasAnyPrefix(rest, testEventReports)
+	}
+	if !update && !report {
+		// Indented output belongs to the subtest at that depth of the report.
+		if indent > 0 && indent <= len(c.report) {
+			c.testName = c.report[indent-1].Test
+		}
+		c.output(line)
+		return
+	}
+
+	if report {
+		colon := strings.IndexByte(rest, ':')
+		action := strings.ToLower(rest[4:colon])
+		name := strings.TrimSpace(rest[colon+1:])
+		event := testEvent{Action: action}
+		if idx := strings.LastIndex(name, " ("); idx >= 0 && strings.HasSuffix(name, "s)") {
+			if seconds, err := strconv.ParseFloat(name[idx+2:len(name)-2], 64); err == nil {
+				event.Elapsed = &seconds
+			}
+			name = name[:idx]
+		}
+		if len(c.report) < indent {
+			// Nested deeper than any test reported so far.
+			c.output(line)
+			return
+		}
+		c.flushReport(indent)
+		event.Test = name
+		c.testName = name
+		c.report = append(c.report, event)
+		c.output(line)
+		return
+	}
+
+	c.flushReport(0)
+	action := strings.ToLower(strings.TrimSpace(line[4:10]))
+	c.testName = strings.TrimSpace(line[len(testEventUpdates[0]):])
+	switch action {
+	case "name":
+		// The line only names the test that the following output belongs to.
+	case "pause":
+		c.output(line)
+		c.write(testEvent{Action: action, Test: c.testName})
+	default:
+		c.write(testEvent{Action: action, Test: c.testName})
+		c.output(line)
+	}
+}
+
+// flushReport writes the report events at depth or deeper, innermost first.
+func (c *testEventConverter) flushReport(depth int) {
+	c.testName = ""
+	for len(c.report) > depth {
+		event := c.report[len(c.report)-1]
+		c.report = c.report[:len(c.report)-1]
+		c.write(event)
+	}
+}
+
+// output writes line as output of the current test.
+func (c *testEventConverter) output(line string) {
+	c.write(testEvent{Action: "output", Test: c.testName, Output: line + "\n"})
+}
+
+func (c *testEventConverter) write(event testEvent) {
+	if c.err != nil {
+		return
+	}
+	event.Time = c.now
+	event.Package = c.pkg
+	c.err = writeTestEvent(c.stream, &event)
+}
+
+// hasAnyPrefix reports whether line starts with one of prefixes.
+func hasAnyPrefix(line string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
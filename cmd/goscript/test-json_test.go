@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4wave/goscript/compiler/gotest"
+)
+
+func TestWriteTestEventsMatchesTest2JSON(t *testing.T) {
+	result := &gotest.Result{Packages: []gotest.PackageResult{
+		{
+			PackagePath: "example.test/pass",
+			Action:      gotest.ActionPass,
+			Elapsed:     1500 * time.Millisecond,
+			Output: strings.Join([]string{
+				"=== RUN   TestA",
+				"=== RUN   TestA/sub",
+				"    hello",
+				"--- FAIL: TestA (0.02s)",
+				"    --- FAIL: TestA/sub (0.01s)",
+				"=== RUN   TestB",
+				"--- SKIP: TestB (0.00s)",
+				"FAIL\texample.test/pass",
+				"",
+			}, "\n"),
+		},
+		{
+			PackagePath: "example.test/typecheck",
+			Action:      gotest.ActionFail,
+			Owner:       gotest.OwnerTypeScriptEmitter,
+			Phases:      gotest.PackagePhases{TypeCheck: gotest.PhaseStatusFail},
+			Error:       "value.gs.ts(3,1): error TS2304",
+		},
+		{
+			PackagePath: "example.test/empty",
+			Action:      gotest.ActionSkip,
+		},
+	}}
+	var out bytes.Buffer
+	if err := newTestEventStream(&out).finish(result, nil); err != nil {
+		t.Fatalf("write test events: %v", err)
+	}
+
+	type event struct {
+		Action  string
+		Package string
+		Test    string
+		Elapsed *float64
+		Output  string
+	}
+	var got []string
+	for line := range strings.SplitSeq(strings.TrimSpace(out.String()), "\n") {
+		var e event
+		if err := json.Unmarshal([]byte(line), &e); err != nil || !strings.Contains(line, `"Time":"`) {
+			t.Fatalf("decode event %q: %v", line, err)
+		}
+		desc := e.Action + " " + strings.TrimPrefix(e.Package, "example.test/") + " " + e.Test
+		if e.Elapsed != nil {
+			desc += " " + time.Duration(*e.Elapsed*float64(time.Second)).String()
+		}
+		if e.Output != "" {
+			desc += " " + strings.TrimSuffix(e.Output, "\n")
+		}
+		got = append(got, desc)
+	}
+	want := []string{
+		"start pass ",
+		"run pass TestA",
+		"output pass TestA === RUN   TestA",
+		"run pass TestA/sub",
+		"output pass TestA/sub === RUN   TestA/sub",
+		"output pass TestA/sub     hello",
+		"output pass TestA --- FAIL: TestA (0.02s)",
+		"output pass TestA/sub     --- FAIL: TestA/sub (0.01s)",
+		"fail pass TestA/sub 10ms",
+		"fail pass TestA 20ms",
+		"run pass TestB",
+		"output pass TestB === RUN   TestB",
+		"output pass TestB --- SKIP: TestB (0.00s)",
+		"skip pass TestB 0s",
+		"output pass  FAIL\texample.test/pass",
+		"output pass  ok  \texample.test/pass\t1.500s",
+		"pass pass  1.5s",
+		"start typecheck ",
+		"output typecheck  FAIL\texample.test/typecheck\towner=TypeScriptEmitterOwner\tphase=typecheck",
+		"output typecheck  value.gs.ts(3,1): error TS2304",
+		"fail typecheck  0s",
+		"start empty ",
+		"output empty  ?   \texample.test/empty\t[no test files]",
+		"skip empty  0s",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTestEventStreamConvertsRuntimeLinesAsTheyArrive(t *testing.T) {
+	var out bytes.Buffer
+	stream := newTestEventStream(&out)
+	lines := []string{
+		"=== FUZZ  FuzzA",
+		"--- PASS: FuzzA (0.50s)",
+		"PASS",
+	}
+	var times []time.Time
+	for _, line := range lines {
+		before := time.Now()
+		stream.runtimeLine("example.test/fuzz", line)
+		times = append(times, before)
+		time.Sleep(2 * time.Millisecond)
+	}
+	if !strings.Contains(out.String(), `"Action":"fuzz"`) {
+		t.Fatalf("runtime lines were not converted when read:\n%s", out.String())
+	}
+
+	result := &gotest.Result{Packages: []gotest.PackageResult{{
+		PackagePath: "example.test/fuzz",
+		Action:      gotest.ActionPass,
+		Output:      strings.Join(lines, "\n"),
+	}}}
+	if err := stream.finish(result, nil); err != nil {
+		t.Fatalf("finish test events: %v", err)
+	}
+
+	type event struct {
+		Time   time.Time
+		Action string
+		Test   string
+		Output string
+	}
+	var events []event
+	for line := range strings.SplitSeq(strings.TrimSpace(out.String()), "\n") {
+		var e event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("decode event %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, strings.TrimSpace(e.Action+" "+e.Test+" "+strings.TrimSuffix(e.Output, "\n")))
+	}
+	want := []string{
+		"start",
+		"fuzz FuzzA",
+		"output FuzzA === FUZZ  FuzzA",
+		"output FuzzA --- PASS: FuzzA (0.50s)",
+		"pass FuzzA",
+		"output  PASS",
+		"output  ok  \texample.test/fuzz\t0.000s",
+		"pass",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	// Events are stamped when their line is read, not when the run ends.
+	if events[1].Time.Before(times[0]) || !events[1].Time.Before(times[1]) {
+		t.Fatalf("fuzz event time %v is not when its line was read (%v to %v)", events[1].Time, times[0], times[1])
+	}
+}
+
+func TestTestCommandRejectsJSONWithOtherReports(t *testing.T) {
+	for _, args := range [][]string{
+		{"--json", "--result-json"},
+		{"--json", "--diagnostics-format", "sarif"},
+	} {
+		app := newApp()
+		err := app.Run(append(append([]string{"goscript", "test"}, args...), "."))
+		if err == nil || !strings.Contains(err.Error(), "--json cannot be combined") {
+			t.Fatalf("expected %v to be rejected, got %v", args, err)
+		}
+	}
+}
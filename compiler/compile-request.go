@@ -3,7 +3,10 @@ package compiler
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // DependencyMode describes how much of the loaded package graph to keep.
@@ -54,6 +57,14 @@ type CompileRequest struct {
 	AllDependencies bool
 	// DisableEmitBuiltin controls whether runtime packages are emitted.
 	DisableEmitBuiltin bool
+	// SourceMaps emits source maps that point generated TypeScript back to Go.
+	SourceMaps bool
+	// PreemptLoops adds cooperative yield checks to loops in async functions.
+	PreemptLoops bool
+	// EliminateDeadCode skips declarations unreachable from the program roots.
+	EliminateDeadCode bool
+	// CoverPackages are the package paths instrumented for statement coverage.
+	CoverPackages []string
 }
 
 // CompileRequestOwner owns adapter input normalization and validation.
@@ -93,6 +104,9 @@ func (o *CompileRequestOwner) NewRequest(conf Config, patterns []string) *Compil
 		ProtobufTypeScriptBinding: conf.ProtobufTypeScriptBinding,
 		AllDependencies:           conf.AllDependencies,
 		DisableEmitBuiltin:        conf.DisableEmitBuiltin,
+		SourceMaps:                conf.SourceMaps,
+		PreemptLoops:              conf.PreemptLoops,
+		EliminateDeadCode:         conf.EliminateDeadCode,
 	}
 }
 
@@ -229,6 +243,47 @@ func (o *CompileRequestOwner) Validate(req *CompileRequest) []Diagnostic {
 	return diagnostics
 }
 
+// BuildTagsFlags returns the Go build flags that select tags. Each entry may
+// list several tags separated by commas or spaces. The tags are deduplicated
+// and sorted, so equal tag sets produce equal flags.
+func BuildTagsFlags(tags []string) []string {
+	var normalized []string
+	for _, value := range tags {
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\n'
+		}) {
+			if !slices.Contains(normalized, tag) {
+				normalized = append(normalized, tag)
+			}
+		}
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+	slices.Sort(normalized)
+	return []string{"-tags=" + strings.Join(normalized, ",")}
+}
+
+// NormalizeOverrideDirs resolves dirs to absolute paths, dropping blank and
+// repeated entries.
+func NormalizeOverrideDirs(dirs []string) ([]string, error) {
+	var normalized []string
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, errors.Wrap(err, "resolve override directory")
+		}
+		if !slices.Contains(normalized, abs) {
+			normalized = append(normalized, abs)
+		}
+	}
+	return normalized, nil
+}
+
 func normalizePatterns(patterns []string) []string {
 	if len(patterns) == 0 {
 		return nil
@@ -0,0 +1,147 @@
+package compiler
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CompileSession serves repeated compile requests in one process. It keeps
+// one CompileService per override root set, so override facts and package
+// graphs stay warm between requests. Warm graphs are reloaded when the files
+// they were loaded from change, and a service is rebuilt when a file under
+// one of its override roots changes.
+type CompileSession struct {
+	mtx      sync.Mutex
+	services map[string]*compileSessionService
+}
+
+type compileSessionService struct {
+	service *CompileService
+	// created is when the override roots were stamped.
+	created time.Time
+	// overrides maps each file under the override roots to its stamp.
+	overrides map[string]packageGraphFileStamp
+}
+
+// NewCompileSession creates an empty compile session.
+func NewCompileSession() *CompileSession {
+	return &CompileSession{services: make(map[string]*compileSessionService)}
+}
+
+// Compile runs one request through the warm service for its override roots.
+// Requests are serialized.
+func (s *CompileSession) Compile(ctx context.Context, req *CompileRequest) (*CompilationResult, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var overrideDirs []string
+	if req != nil {
+		overrideDirs = req.OverrideDirs
+	}
+	return s.service(overrideDirs).Compile(ctx, req)
+}
+
+// Changes reports the files changed since the session's warm package graphs
+// were loaded and the packages they affect. It does not reload anything; the
+// next Compile does.
+func (s *CompileSession) Changes() *PackageGraphChanges {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	files := make(map[string]bool)
+	packages := make(map[string]bool)
+	for _, entry := range s.services {
+		changes := entry.service.PackageGraphOwner().Changes()
+		for _, file := range changes.Files {
+			files[file] = true
+		}
+		for _, pkgPath := range changes.Packages {
+			packages[pkgPath] = true
+		}
+		for _, file := range entry.changedOverrideFiles() {
+			files[file] = true
+		}
+	}
+	return &PackageGraphChanges{
+		Files:    sortedKeys(files),
+		Packages: sortedKeys(packages),
+	}
+}
+
+// service returns the warm service for overrideDirs, replacing it when an
+// override file changed since it was created.
+func (s *CompileSession) service(overrideDirs []string) *CompileService {
+	key := strings.Join(overrideDirs, "\x00")
+	if entry := s.services[key]; entry != nil && len(entry.changedOverrideFiles()) == 0 {
+		return entry.service
+	}
+	created := time.Now()
+	stamps := overrideDirStamps(overrideDirs)
+	for file, stamp := range stamps {
+		if stamp.modTime.Before(created) {
+			stamp.hash = hashPackageGraphFile(file)
+			stamps[file] = stamp
+		}
+	}
+	service := NewCompileService(overrideDirs...)
+	service.PackageGraphOwner().KeepWarm()
+	s.services[key] = &compileSessionService{service: service, created: created, overrides: stamps}
+	return service
+}
+
+// overrideDirStamps stamps every file under the override roots by size and
+// modification time without hashing it.
+func overrideDirStamps(overrideDirs []string) map[string]packageGraphFileStamp {
+	stamps := make(map[string]packageGraphFileStamp)
+	for _, dir := range overrideDirs {
+		_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			stamps[path] = packageGraphFileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+	}
+	return stamps
+}
+
+// changedOverrideFiles returns the override files added, removed, or
+// modified since the service was created. Like package graph files, a file is
+// rehashed only when its size or mtime changed or its mtime is not before the
+// creation time, and stamps whose hash did not change are refreshed in place.
+func (e *compileSessionService) changedOverrideFiles() []string {
+	current := overrideDirStamps(e.service.overrideOwner.overrideDirs)
+	var changed []string
+	for file, stamp := range e.overrides {
+		now, ok := current[file]
+		if !ok {
+			changed = append(changed, file)
+			continue
+		}
+		if now.size == stamp.size && now.modTime.Equal(stamp.modTime) && now.modTime.Before(e.created) {
+			continue
+		}
+		if stamp.hash != "" && hashPackageGraphFile(file) == stamp.hash {
+			now.hash = stamp.hash
+			e.overrides[file] = now
+			continue
+		}
+		changed = append(changed, file)
+	}
+	for file := range current {
+		if _, ok := e.overrides[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,127 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPackageGraphOwnerKeepWarmReusesGraphUntilFilesChange(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":     "module example.test/warm\n\ngo 1.25.3\n",
+		"main.go":    "package main\n\nimport \"example.test/warm/lib\"\n\nfunc main() { println(lib.Value()) }\n",
+		"lib/lib.go": "package lib\n\nfunc Value() int { return 1 }\n",
+		"other/o.go": "package other\n\nfunc Other() int { return 2 }\n",
+	})
+	req := &CompileRequest{
+		Patterns:            []string{".", "./other"},
+		Dir:                 moduleDir,
+		OutputPath:          filepath.Join(t.TempDir(), "out"),
+		DependencyMode:      DependencyModeAll,
+		RuntimeEmissionMode: RuntimeEmissionModeEmit,
+	}
+	owner := NewPackageGraphOwner()
+	owner.KeepWarm()
+	ctx := context.Background()
+
+	first, diagnostics := owner.Load(ctx, req)
+	if diagnosticsHaveErrors(diagnostics) {
+		t.Fatalf("load failed: %#v", diagnostics)
+	}
+	second, _ := owner.Load(ctx, req)
+	if first != second {
+		t.Fatal("expected the warm graph to be reused")
+	}
+	if identity, _ := owner.LoadIdentity(ctx, req); identity != first {
+		t.Fatal("expected the warm full graph to answer identity loads")
+	}
+	if changes := owner.Changes(); len(changes.Files) != 0 || len(changes.Packages) != 0 {
+		t.Fatalf("expected no changes, got %#v", changes)
+	}
+
+	libFile := filepath.Join(moduleDir, "lib", "lib.go")
+	touched := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(libFile, touched, touched); err != nil {
+		t.Fatal(err.Error())
+	}
+	if changes := owner.Changes(); len(changes.Files) != 0 {
+		t.Fatalf("expected a touched file with unchanged content to keep the graph, got %#v", changes)
+	}
+	if touchedGraph, _ := owner.Load(ctx, req); touchedGraph != first {
+		t.Fatal("expected the warm graph to be reused after a touch")
+	}
+
+	if err := os.WriteFile(libFile, []byte("package lib\n\nfunc Value() int { return 3 }\n"), 0o644); err != nil {
+		t.Fatal(err.Error())
+	}
+	addedFile := filepath.Join(moduleDir, "lib", "extra.go")
+	if err := os.WriteFile(addedFile, []byte("package lib\n"), 0o644); err != nil {
+		t.Fatal(err.Error())
+	}
+	changes := owner.Changes()
+	if !slices.Equal(changes.Files, []string{addedFile, libFile}) {
+		t.Fatalf("unexpected changed files: %#v", changes.Files)
+	}
+	if !slices.Equal(changes.Packages, []string{"example.test/warm", "example.test/warm/lib"}) {
+		t.Fatalf("unexpected affected packages: %#v", changes.Packages)
+	}
+
+	reloaded, _ := owner.Load(ctx, req)
+	if reloaded == first {
+		t.Fatal("expected a changed source file to reload the graph")
+	}
+	if files := reloaded.NodesByPackagePath["example.test/warm/lib"].GoFiles; len(files) != 2 {
+		t.Fatalf("expected the added file in the reloaded graph, got %#v", files)
+	}
+	if changes := owner.Changes(); len(changes.Files) != 0 {
+		t.Fatalf("expected no changes after reload, got %#v", changes)
+	}
+}
+
+func TestCompileSessionRecompilesChangedSources(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":  "module example.test/session\n\ngo 1.25.3\n",
+		"main.go": "package main\n\nfunc main() { println(\"first\") }\n",
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	req := NewCompileRequestOwner().NewRequest(Config{Dir: moduleDir, OutputPath: outputDir}, []string{"."})
+	session := NewCompileSession()
+	ctx := context.Background()
+	generatedPath := filepath.Join(outputDir, "@goscript", "example.test", "session", "main.gs.ts")
+	readGenerated := func() string {
+		t.Helper()
+		content, err := os.ReadFile(generatedPath)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return string(content)
+	}
+
+	if _, err := session.Compile(ctx, req); err != nil {
+		t.Fatal(err.Error())
+	}
+	first := readGenerated()
+	if _, err := session.Compile(ctx, req); err != nil {
+		t.Fatal(err.Error())
+	}
+	if second := readGenerated(); second != first {
+		t.Fatalf("warm recompile changed the output:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	mainFile := filepath.Join(moduleDir, "main.go")
+	if err := os.WriteFile(mainFile, []byte("package main\n\nfunc main() { println(\"second\") }\n"), 0o644); err != nil {
+		t.Fatal(err.Error())
+	}
+	if changes := session.Changes(); !slices.Equal(changes.Files, []string{mainFile}) || !slices.Equal(changes.Packages, []string{"example.test/session"}) {
+		t.Fatalf("unexpected changes: %#v", changes)
+	}
+	if _, err := session.Compile(ctx, req); err != nil {
+		t.Fatal(err.Error())
+	}
+	if generated := readGenerated(); generated == first {
+		t.Fatalf("expected the changed source in the output:\n%s", generated)
+	}
+}
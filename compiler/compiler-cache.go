@@ -21,7 +21,7 @@ import (
 	jsoniter "github.com/aperturerobotics/json-iterator-lite"
 )
 
-const compilerCacheSchema = "goscript-package-artifact-v1"
+const compilerCacheSchema = "goscript-package-artifact-v2"
 
 type compilerCacheEntryKind string
 
@@ -46,6 +46,7 @@ type compilerCacheManifest struct {
 	packagePath      string
 	compiledPackages []string
 	copiedPackages   []string
+	runtimeHelpers   []string
 	files            []compilerCacheManifestFile
 }
 
@@ -62,8 +63,11 @@ func NewCompilerCacheOwner() *CompilerCacheOwner {
 	return &CompilerCacheOwner{}
 }
 
+// Enabled reports whether req reads and writes the package artifact cache.
+// Dead-code elimination disables the cache because a package's output then
+// depends on the packages that import it.
 func (o *CompilerCacheOwner) Enabled(req *CompileRequest) bool {
-	return req != nil && strings.TrimSpace(req.CacheRoot) != ""
+	return req != nil && strings.TrimSpace(req.CacheRoot) != "" && !req.EliminateDeadCode
 }
 
 func (o *CompilerCacheOwner) Entries(
@@ -121,6 +125,11 @@ func (o *CompilerCacheOwner) Replay(
 		}
 		result.CompiledPackages = append(result.CompiledPackages, manifest.compiledPackages...)
 		result.CopiedPackages = append(result.CopiedPackages, manifest.copiedPackages...)
+		helpers := make([]RuntimeHelper, 0, len(manifest.runtimeHelpers))
+		for _, helper := range manifest.runtimeHelpers {
+			helpers = append(helpers, RuntimeHelper(helper))
+		}
+		result.addRuntimeHelpers(helpers)
 	}
 	return result, true
 }
@@ -148,6 +157,9 @@ func (o *CompilerCacheOwner) StoreGenerated(
 			packagePath:      pkg.pkgPath,
 			compiledPackages: []string{pkg.pkgPath},
 		}
+		for _, helper := range pkg.sortedRuntimeHelpers() {
+			manifest.runtimeHelpers = append(manifest.runtimeHelpers, string(helper))
+		}
 		for filePath, contents := range files {
 			if !strings.HasPrefix(filePath, prefix) {
 				continue
@@ -421,6 +433,9 @@ func writeRequestIdentity(b *strings.Builder, req *CompileRequest) {
 	if req.ProtobufTypeScriptBinding {
 		writeKeyField(b, "protobuf-output", cleanAbs(req.OutputPath))
 	}
+	if req.SourceMaps {
+		writeKeyField(b, "source-map-output", cleanAbs(req.OutputPath))
+	}
 	for _, flag := range goScriptBuildFlags(req.BuildFlags) {
 		writeKeyField(b, "build-flag", flag)
 	}
@@ -434,6 +449,11 @@ func writeRequestIdentity(b *strings.Builder, req *CompileRequest) {
 	writeKeyField(b, "runtime-mode", string(req.RuntimeEmissionMode))
 	writeKeyField(b, "protobuf-ts-binding", strconv.FormatBool(req.ProtobufTypeScriptBinding))
 	writeKeyField(b, "tests", strconv.FormatBool(req.Tests))
+	writeKeyField(b, "source-maps", strconv.FormatBool(req.SourceMaps))
+	writeKeyField(b, "preempt-loops", strconv.FormatBool(req.PreemptLoops))
+	for _, path := range req.CoverPackages {
+		writeKeyField(b, "cover-package", path)
+	}
 	for _, key := range goLoaderEnvKeys() {
 		writeKeyField(b, "env-"+key, os.Getenv(key))
 	}
@@ -638,6 +658,9 @@ func generatedArtifactKind(filePath string) string {
 	if strings.HasSuffix(filePath, "/index.ts") {
 		return "package-index"
 	}
+	if strings.HasSuffix(filePath, ".map") {
+		return "source-map"
+	}
 	return "generated"
 }
 
@@ -724,6 +747,8 @@ func formatCompilerCacheManifest(manifest compilerCacheManifest) []byte {
 	stream.WriteMore()
 	writeStringArray(stream, "copiedPackages", manifest.copiedPackages)
 	stream.WriteMore()
+	writeStringArray(stream, "runtimeHelpers", manifest.runtimeHelpers)
+	stream.WriteMore()
 	stream.WriteObjectField("files")
 	stream.WriteArrayStart()
 	for idx, file := range manifest.files {
@@ -784,6 +809,8 @@ func parseCompilerCacheManifest(data []byte) compilerCacheManifest {
 			manifest.compiledPackages = readStringArray(iter)
 		case "copiedPackages":
 			manifest.copiedPackages = readStringArray(iter)
+		case "runtimeHelpers":
+			manifest.runtimeHelpers = readStringArray(iter)
 		case "files":
 			for iter.ReadArray() {
 				manifest.files = append(manifest.files, readManifestFile(iter))
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -46,7 +47,7 @@ func TestCompilePackagesCacheDisabledCreatesNoCacheRoot(t *testing.T) {
 func TestCompilePackagesCacheReplaysOutput(t *testing.T) {
 	moduleDir := writePackageGraphFixture(t, map[string]string{
 		"go.mod":  "module example.test/cachereplay\n\ngo 1.25.3\n",
-		"main.go": "package cachereplay\nconst Value = 1\n",
+		"main.go": "package cachereplay\nconst Value = 1\nfunc Grow(xs []int) []int { return append(xs, Value) }\n",
 	})
 	cacheRoot := filepath.Join(t.TempDir(), "cache")
 	firstOut := filepath.Join(t.TempDir(), "first")
@@ -54,6 +55,9 @@ func TestCompilePackagesCacheReplaysOutput(t *testing.T) {
 	if len(result.CompiledPackages) != 1 || result.CompiledPackages[0] != "example.test/cachereplay" {
 		t.Fatalf("compiled packages = %#v", result.CompiledPackages)
 	}
+	if !slices.Contains(result.RuntimeHelpers, RuntimeHelperAppend) {
+		t.Fatalf("runtime helpers = %v, want the append helper", result.RuntimeHelpers)
+	}
 	manifest := firstCacheManifestPath(t, cacheRoot)
 	if manifest == "" {
 		t.Fatal("cache manifest not written")
@@ -65,6 +69,9 @@ func TestCompilePackagesCacheReplaysOutput(t *testing.T) {
 	if len(second.CompiledPackages) != 1 || second.CompiledPackages[0] != "example.test/cachereplay" {
 		t.Fatalf("cached compiled packages = %#v", second.CompiledPackages)
 	}
+	if !slices.Equal(second.RuntimeHelpers, result.RuntimeHelpers) {
+		t.Fatalf("cached runtime helpers = %v, want %v", second.RuntimeHelpers, result.RuntimeHelpers)
+	}
 
 	first := readOutputFile(t, firstOut, "example.test/cachereplay", "main.gs.ts")
 	replayed := readOutputFile(t, secondOut, "example.test/cachereplay", "main.gs.ts")
@@ -37,5 +37,18 @@ func (c *Compiler) CompilePackages(ctx context.Context, patterns ...string) (*Co
 		c.le.Debugf("goscript v2 compile request: %v", patterns)
 	}
 	request := c.service.RequestOwner().NewRequest(c.config, patterns)
-	return c.service.Compile(ctx, request)
+	result, err := c.service.Compile(ctx, request)
+	if err == nil && result != nil && c.le != nil {
+		for _, pkg := range result.DeadCode {
+			if pkg.Removed() == 0 {
+				continue
+			}
+			c.le.Infof(
+				"eliminated %d of %d declarations from %s (%d functions, %d types, %d values)",
+				pkg.Removed(), pkg.Declarations, pkg.Package,
+				pkg.RemovedFunctions, pkg.RemovedTypes, pkg.RemovedValues,
+			)
+		}
+	}
+	return result, err
 }
@@ -28,6 +28,15 @@ type Config struct {
 	DisableEmitBuiltin bool
 	// ProtobufTypeScriptBinding binds .pb.go files to sibling .pb.ts files.
 	ProtobufTypeScriptBinding bool
+	// SourceMaps emits a v3 source map beside every generated TypeScript file.
+	SourceMaps bool
+	// PreemptLoops adds cooperative yield checks to loops in async functions
+	// and colors goroutine entry points that contain loops async.
+	PreemptLoops bool
+	// EliminateDeadCode skips package-level declarations that are unreachable
+	// from main, the exported API of requested packages, and init side
+	// effects. It disables the compiler cache.
+	EliminateDeadCode bool
 }
 
 // Validate checks the config and initializes owned defaults.
@@ -0,0 +1,485 @@
+package compiler
+
+import (
+	"bytes"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Statement coverage instrumentation.
+//
+// Instrumented packages are split into basic blocks the way cmd/cover splits
+// them, so a GoScript profile lines up with one from go test -coverprofile.
+// Every generated module registers the blocks of its Go file when it loads,
+// and lowering puts a counter call before the first statement of each block,
+// or into the empty statement list that forms a block on its own. Blocks are
+// named by the profile file name, the package path joined with the file's
+// base name, so a method lowered into another file's module still counts
+// against its own file.
+
+// coverBlock is one basic block recorded in a coverage profile.
+type coverBlock struct {
+	startLine int
+	startCol  int
+	endLine   int
+	endCol    int
+	stmts     int
+}
+
+// fileCoverage holds the coverage blocks of one Go file.
+type fileCoverage struct {
+	// name is the file name recorded in the profile.
+	name   string
+	blocks []coverBlock
+}
+
+// coverCounter locates the counter of one coverage block.
+type coverCounter struct {
+	file  string
+	block int
+}
+
+// packageCoverage holds the coverage blocks of an instrumented package and the
+// syntax nodes that start them: a statement, or an empty statement list's
+// block, case clause, or select clause.
+type packageCoverage struct {
+	files    map[string]*fileCoverage
+	counters map[ast.Node]coverCounter
+}
+
+// newPackageCoverage splits the non-test Go files of semPkg into coverage
+// blocks, reading block text from the source the loader parsed. A file whose
+// parsed source is unavailable is left uninstrumented.
+func newPackageCoverage(semPkg *semanticPackage, sources map[string][]byte) *packageCoverage {
+	cov := &packageCoverage{
+		files:    make(map[string]*fileCoverage),
+		counters: make(map[ast.Node]coverCounter),
+	}
+	for idx, file := range semPkg.source.Syntax {
+		sourcePath := sourceFilePath(semPkg, idx, file)
+		if strings.HasSuffix(sourcePath, "_test.go") {
+			continue
+		}
+		tokFile := semPkg.source.Fset.File(file.Package)
+		if tokFile == nil {
+			continue
+		}
+		src := sources[tokFile.Name()]
+		if len(src) != tokFile.Size() {
+			continue
+		}
+		fileCov := &fileCoverage{name: semPkg.pkgPath + "/" + filepath.Base(sourcePath)}
+		ast.Walk(&coverWalker{
+			fset:    semPkg.source.Fset,
+			tokFile: tokFile,
+			src:     src,
+			cov:     cov,
+			file:    fileCov,
+			seen:    make(map[[4]int]bool),
+		}, file)
+		cov.files[sourcePath] = fileCov
+	}
+	return cov
+}
+
+// registration returns the module statement registering the blocks of the Go
+// file at sourcePath, or "" when the file has none.
+func (c *packageCoverage) registration(runtimeOwner *RuntimeContractOwner, sourcePath string) string {
+	if c == nil || c.files[sourcePath] == nil || len(c.files[sourcePath].blocks) == 0 {
+		return ""
+	}
+	file := c.files[sourcePath]
+	fields := make([]string, 0, len(file.blocks)*5)
+	for _, block := range file.blocks {
+		fields = append(fields,
+			strconv.Itoa(block.startLine),
+			strconv.Itoa(block.startCol),
+			strconv.Itoa(block.endLine),
+			strconv.Itoa(block.endCol),
+			strconv.Itoa(block.stmts),
+		)
+	}
+	return runtimeOwner.QualifiedHelper(RuntimeHelperCoverBlocks) + "(" + strconv.Quote(file.name) + ", [" + strings.Join(fields, ", ") + "])"
+}
+
+// counterStmt returns the counter call for the block that starts at node.
+func (c *packageCoverage) counterStmt(runtimeOwner *RuntimeContractOwner, node ast.Node) (loweredStmt, bool) {
+	if c == nil {
+		return loweredStmt{}, false
+	}
+	counter, ok := c.counters[node]
+	if !ok {
+		return loweredStmt{}, false
+	}
+	return loweredStmt{
+		text: runtimeOwner.QualifiedHelper(RuntimeHelperCoverHit) + "(" + strconv.Quote(counter.file) + ", " + strconv.Itoa(counter.block) + ")",
+	}, true
+}
+
+type coverWalker struct {
+	fset    *token.FileSet
+	tokFile *token.File
+	src     []byte
+	cov     *packageCoverage
+	file    *fileCoverage
+	// seen holds the positions of the blocks added so far, so two blocks
+	// never share one.
+	seen map[[4]int]bool
+}
+
+// coverRange is a stretch of source that holds code.
+type coverRange struct {
+	pos token.Pos
+	end token.Pos
+}
+
+func (w *coverWalker) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.BlockStmt:
+		// The body of a switch or select is a list of clauses, each its own
+		// statement list.
+		if len(n.List) != 0 {
+			switch n.List[0].(type) {
+			case *ast.CaseClause:
+				for _, stmt := range n.List {
+					clause := stmt.(*ast.CaseClause)
+					w.addBlocks(clause, clause.Colon+1, clause.Colon+1, clause.End(), clause.Body, false)
+				}
+				return w
+			case *ast.CommClause:
+				for _, stmt := range n.List {
+					clause := stmt.(*ast.CommClause)
+					w.addBlocks(clause, clause.Colon+1, clause.Colon+1, clause.End(), clause.Body, false)
+				}
+				return w
+			}
+		}
+		w.addBlocks(n, n.Lbrace, n.Lbrace+1, n.Rbrace+1, n.List, true)
+	case *ast.IfStmt:
+		if n.Init != nil {
+			ast.Walk(w, n.Init)
+		}
+		ast.Walk(w, n.Cond)
+		ast.Walk(w, n.Body)
+		if n.Else == nil {
+			return nil
+		}
+		// An else branch is a block starting after the else keyword, so an
+		// else if condition is covered by a block of its own.
+		pos := w.findElse(n.Body.End())
+		if !pos.IsValid() {
+			return nil
+		}
+		switch els := n.Else.(type) {
+		case *ast.IfStmt:
+			w.addBlocks(els, pos, pos+1, els.End()+1, []ast.Stmt{els}, true)
+			ast.Walk(w, els)
+		case *ast.BlockStmt:
+			w.addBlocks(els, pos, pos+1, els.Rbrace+1, els.List, true)
+			for _, stmt := range els.List {
+				ast.Walk(w, stmt)
+			}
+		}
+		return nil
+	case *ast.SelectStmt:
+		if n.Body == nil || len(n.Body.List) == 0 {
+			return nil
+		}
+	case *ast.SwitchStmt:
+		if n.Body == nil || len(n.Body.List) == 0 {
+			if n.Init != nil {
+				ast.Walk(w, n.Init)
+			}
+			if n.Tag != nil {
+				ast.Walk(w, n.Tag)
+			}
+			return nil
+		}
+	case *ast.TypeSwitchStmt:
+		if n.Body == nil || len(n.Body.List) == 0 {
+			if n.Init != nil {
+				ast.Walk(w, n.Init)
+			}
+			ast.Walk(w, n.Assign)
+			return nil
+		}
+	case *ast.FuncDecl:
+		// Functions named _ and functions without bodies never run.
+		if n.Name.Name == "_" || n.Body == nil {
+			return nil
+		}
+	}
+	return w
+}
+
+// addBlocks splits the statement list of container into basic blocks. The
+// first block spans from pos, with its code starting no earlier than
+// codePos; the last ends at blockEnd when extendToClosingBrace is set and no
+// statement broke the list up.
+func (w *coverWalker) addBlocks(container ast.Node, pos, codePos, blockEnd token.Pos, list []ast.Stmt, extendToClosingBrace bool) {
+	if len(list) == 0 {
+		r := w.codeRanges(codePos, blockEnd)[0]
+		w.addBlock(container, r.pos, r.end, 0)
+		return
+	}
+	list = slices.Clone(list)
+	// labels maps the label-only statements split off labeled statements
+	// back to the labeled statement that lowering sees.
+	labels := make(map[ast.Stmt]ast.Stmt)
+	for {
+		last := 0
+		end := blockEnd
+		for ; last < len(list); last++ {
+			stmt := list[last]
+			end = coverStatementBoundary(stmt)
+			if !coverEndsBlock(stmt) {
+				continue
+			}
+			// A goto may branch to a label, so the statement after a label
+			// starts a new block. A labeled loop or switch already ends one.
+			if label, ok := stmt.(*ast.LabeledStmt); ok && !coverIsControl(label.Stmt) {
+				split := &ast.LabeledStmt{
+					Label: label.Label,
+					Colon: label.Colon,
+					Stmt:  &ast.EmptyStmt{Semicolon: label.Stmt.Pos(), Implicit: true},
+				}
+				labels[split] = label
+				end = label.Pos()
+				list[last] = split
+				list = slices.Insert(list, last+1, label.Stmt)
+			}
+			last++
+			extendToClosingBrace = false
+			break
+		}
+		if extendToClosingBrace {
+			end = blockEnd
+		}
+		if pos != end {
+			// Blank lines and comments split a block into several ranges,
+			// each counted by the statement it starts with.
+			ranges := w.codeRanges(pos, end)
+			stmts := list[:last]
+			for idx, r := range ranges {
+				var node ast.Stmt
+				if idx == 0 {
+					node = stmts[0]
+				} else if at := slices.IndexFunc(stmts, func(stmt ast.Stmt) bool { return stmt.Pos() == r.pos }); at >= 0 {
+					node = stmts[at]
+				} else {
+					// A range starting inside a statement belongs to the
+					// range before it.
+					w.extendBlock(r.end)
+					continue
+				}
+				if label, ok := labels[node]; ok {
+					node = label
+				}
+				w.addBlock(node, r.pos, r.end, last)
+			}
+		}
+		list = list[last:]
+		if len(list) == 0 {
+			return
+		}
+		pos = list[0].Pos()
+	}
+}
+
+// codeRanges splits the source between start and end into the ranges that
+// hold code, leaving out braces and runs of blank or comment lines. A span
+// without code yields one empty range at start.
+func (w *coverWalker) codeRanges(start, end token.Pos) []coverRange {
+	startOffset := w.tokFile.Offset(start)
+	src := w.src[startOffset:w.tokFile.Offset(end)]
+	scanFile := token.NewFileSet().AddFile("", -1, len(src))
+	var s scanner.Scanner
+	s.Init(scanFile, src, nil, 0)
+
+	lineStart := func(line int) token.Pos {
+		return w.tokFile.Pos(startOffset + scanFile.Offset(scanFile.LineStart(line)))
+	}
+	var ranges []coverRange
+	var codeStart token.Pos
+	prevEndLine := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.LBRACE || tok == token.RBRACE || (tok == token.SEMICOLON && lit == "\n") {
+			continue
+		}
+		startLine := scanFile.PositionFor(pos, false).Line
+		endLine := startLine
+		if tok == token.STRING {
+			endLine = scanFile.PositionFor(pos+token.Pos(len(lit)), false).Line
+		}
+		switch {
+		case prevEndLine == 0:
+			codeStart = w.tokFile.Pos(startOffset + scanFile.Offset(pos))
+		case startLine > prevEndLine+1:
+			ranges = append(ranges, coverRange{pos: codeStart, end: lineStart(prevEndLine + 1)})
+			codeStart = w.tokFile.Pos(startOffset + scanFile.Offset(pos))
+		}
+		prevEndLine = max(prevEndLine, endLine)
+	}
+	switch {
+	case prevEndLine == 0:
+		return []coverRange{{pos: start, end: start}}
+	case prevEndLine < scanFile.LineCount():
+		ranges = append(ranges, coverRange{pos: codeStart, end: lineStart(prevEndLine + 1)})
+	default:
+		ranges = append(ranges, coverRange{pos: codeStart, end: end})
+	}
+	return ranges
+}
+
+// findElse returns the position just past the else keyword following pos,
+// skipping comments.
+func (w *coverWalker) findElse(pos token.Pos) token.Pos {
+	src := w.src
+	for idx := w.tokFile.Offset(pos); idx < len(src); idx++ {
+		switch {
+		case bytes.HasPrefix(src[idx:], []byte("else")):
+			return w.tokFile.Pos(idx + len("else"))
+		case bytes.HasPrefix(src[idx:], []byte("//")):
+			end := bytes.IndexByte(src[idx:], '\n')
+			if end < 0 {
+				return token.NoPos
+			}
+			idx += end
+		case bytes.HasPrefix(src[idx:], []byte("/*")):
+			end := bytes.Index(src[idx+2:], []byte("*/"))
+			if end < 0 {
+				return token.NoPos
+			}
+			idx += end + 3
+		}
+	}
+	return token.NoPos
+}
+
+func (w *coverWalker) addBlock(node ast.Node, start token.Pos, end token.Pos, stmts int) {
+	startPos := w.fset.PositionFor(start, false)
+	endPos := w.fset.PositionFor(end, false)
+	key := [4]int{startPos.Line, startPos.Column, endPos.Line, endPos.Column}
+	for w.seen[key] {
+		key[3]++
+	}
+	w.seen[key] = true
+	w.cov.counters[node] = coverCounter{file: w.file.name, block: len(w.file.blocks)}
+	w.file.blocks = append(w.file.blocks, coverBlock{
+		startLine: key[0],
+		startCol:  key[1],
+		endLine:   key[2],
+		endCol:    key[3],
+		stmts:     stmts,
+	})
+}
+
+// extendBlock moves the end of the last block added to end.
+func (w *coverWalker) extendBlock(end token.Pos) {
+	endPos := w.fset.PositionFor(end, false)
+	block := &w.file.blocks[len(w.file.blocks)-1]
+	block.endLine = endPos.Line
+	block.endCol = endPos.Column
+}
+
+// coverEndsBlock reports whether control may leave the block after stmt, or
+// enter the statement after it from elsewhere.
+func coverEndsBlock(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt, *ast.BranchStmt, *ast.ForStmt, *ast.IfStmt, *ast.LabeledStmt,
+		*ast.RangeStmt, *ast.SwitchStmt, *ast.SelectStmt, *ast.TypeSwitchStmt:
+		return true
+	case *ast.ExprStmt:
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "panic" && len(call.Args) == 1 {
+				return true
+			}
+		}
+	}
+	found, _ := coverFuncLiteral(stmt)
+	return found
+}
+
+// coverStatementBoundary returns where the block ending at stmt ends: the
+// opening brace of a control statement's body, or the first function literal,
+// whose body gets blocks of its own.
+func coverStatementBoundary(stmt ast.Stmt) token.Pos {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		return s.Lbrace
+	case *ast.IfStmt:
+		if found, pos := coverFuncLiteral(s.Init, s.Cond); found {
+			return pos
+		}
+		return s.Body.Lbrace
+	case *ast.ForStmt:
+		if found, pos := coverFuncLiteral(s.Init, s.Cond, s.Post); found {
+			return pos
+		}
+		return s.Body.Lbrace
+	case *ast.LabeledStmt:
+		return coverStatementBoundary(s.Stmt)
+	case *ast.RangeStmt:
+		if found, pos := coverFuncLiteral(s.X); found {
+			return pos
+		}
+		return s.Body.Lbrace
+	case *ast.SwitchStmt:
+		if found, pos := coverFuncLiteral(s.Init, s.Tag); found {
+			return pos
+		}
+		return s.Body.Lbrace
+	case *ast.SelectStmt:
+		return s.Body.Lbrace
+	case *ast.TypeSwitchStmt:
+		if found, pos := coverFuncLiteral(s.Init); found {
+			return pos
+		}
+		return s.Body.Lbrace
+	}
+	if found, pos := coverFuncLiteral(stmt); found {
+		return pos
+	}
+	return stmt.End()
+}
+
+func coverIsControl(stmt ast.Stmt) bool {
+	switch stmt.(type) {
+	case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.SelectStmt, *ast.TypeSwitchStmt:
+		return true
+	}
+	return false
+}
+
+// coverFuncLiteral returns the body of the first function literal in nodes.
+func coverFuncLiteral(nodes ...ast.Node) (bool, token.Pos) {
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		var pos token.Pos
+		ast.Inspect(node, func(n ast.Node) bool {
+			if pos.IsValid() {
+				return false
+			}
+			if lit, ok := n.(*ast.FuncLit); ok {
+				pos = lit.Body.Lbrace
+				return false
+			}
+			return true
+		})
+		if pos.IsValid() {
+			return true, pos
+		}
+	}
+	return false, token.NoPos
+}
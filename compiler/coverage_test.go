@@ -0,0 +1,88 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompileInstrumentsCoveredPackages(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod": "module example.test/cover\n\ngo 1.25.3\n",
+		"cover.go": strings.Join([]string{
+			"package cover",
+			"",
+			"func Sign(x int) int {",
+			"\tif x > 0 {",
+			"\t\treturn 1",
+			"\t} else if x < 0 {",
+			"\t\treturn -1",
+			"\t}",
+			"\tswitch {",
+			"\tcase x == 0:",
+			"\t}",
+			"\treturn 0",
+			"}",
+			"",
+		}, "\n"),
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	_, err := NewCompileService().Compile(context.Background(), &CompileRequest{
+		Patterns:            []string{"."},
+		Dir:                 moduleDir,
+		OutputPath:          outputDir,
+		DependencyMode:      DependencyModeRequested,
+		RuntimeEmissionMode: RuntimeEmissionModeReference,
+		CoverPackages:       []string{"example.test/cover"},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	generated, err := os.ReadFile(filepath.Join(outputDir, "@goscript", "example.test", "cover", "cover.gs.ts"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	// The blocks match go tool cover: the function entry, both else branches,
+	// the switch, the empty case clause, and the final return.
+	blocks := `$.coverBlocks("example.test/cover/cover.go", [4, 2, 4, 11, 1, 9, 2, 9, 9, 1, 12, 2, 12, 10, 1, 5, 3, 6, 1, 1, 6, 9, 6, 18, 1, 7, 3, 8, 1, 1, 10, 14, 10, 14, 0])`
+	if !strings.Contains(string(generated), blocks) {
+		t.Fatalf("missing coverage block registration:\n%s", generated)
+	}
+	for id := range 7 {
+		hit := `$.coverHit("example.test/cover/cover.go", ` + string(rune('0'+id)) + `)`
+		if strings.Count(string(generated), hit) != 1 {
+			t.Fatalf("expected one counter %s:\n%s", hit, generated)
+		}
+	}
+}
+
+func TestPackageCoverageUsesParsedSource(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":   "module example.test/cover\n\ngo 1.25.3\n",
+		"cover.go": "package cover\n\nfunc One() int {\n\treturn 1\n}\n",
+	})
+	ctx := context.Background()
+	graph, diagnostics := NewPackageGraphOwner().Load(ctx, &CompileRequest{
+		Patterns:       []string{"."},
+		Dir:            moduleDir,
+		DependencyMode: DependencyModeRequested,
+	})
+	if diagnosticsHaveErrors(diagnostics) {
+		t.Fatalf("load failed: %#v", diagnostics)
+	}
+	if err := os.Remove(filepath.Join(moduleDir, "cover.go")); err != nil {
+		t.Fatal(err.Error())
+	}
+	model, diagnostics := NewSemanticModelOwner().Build(ctx, graph)
+	if diagnosticsHaveErrors(diagnostics) {
+		t.Fatalf("semantic model failed: %#v", diagnostics)
+	}
+	cov := newPackageCoverage(model.packages["example.test/cover"], model.sources)
+	file := cov.files[filepath.Join(moduleDir, "cover.go")]
+	if file == nil || len(file.blocks) != 1 {
+		t.Fatalf("expected the parsed source to be instrumented after removal, got %#v", cov.files)
+	}
+}
@@ -0,0 +1,355 @@
+package compiler
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	jsoniter "github.com/aperturerobotics/json-iterator-lite"
+	"github.com/pkg/errors"
+)
+
+// DiagnosticFormat selects how diagnostics are rendered for a consumer.
+type DiagnosticFormat string
+
+const (
+	// DiagnosticFormatText renders one human-readable diagnostic per line.
+	DiagnosticFormatText DiagnosticFormat = "text"
+	// DiagnosticFormatJSON renders diagnostics grouped by package as JSON.
+	DiagnosticFormatJSON DiagnosticFormat = "json"
+	// DiagnosticFormatSARIF renders diagnostics as a SARIF 2.1.0 log.
+	DiagnosticFormatSARIF DiagnosticFormat = "sarif"
+)
+
+// ParseDiagnosticFormat validates a diagnostics format name.
+// The empty string selects DiagnosticFormatText.
+func ParseDiagnosticFormat(value string) (DiagnosticFormat, error) {
+	switch format := DiagnosticFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return DiagnosticFormatText, nil
+	case DiagnosticFormatText, DiagnosticFormatJSON, DiagnosticFormatSARIF:
+		return format, nil
+	default:
+		return "", errors.Errorf("unknown diagnostics format %q (want text, json, or sarif)", value)
+	}
+}
+
+// WriteDiagnostics renders diagnostics to w in the requested format.
+func WriteDiagnostics(w io.Writer, format DiagnosticFormat, diagnostics []Diagnostic) error {
+	switch format {
+	case "", DiagnosticFormatText:
+		for _, diag := range diagnostics {
+			if _, err := io.WriteString(w, FormatDiagnostic(diag)+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	case DiagnosticFormatJSON:
+		return writeDiagnosticJSON(w, func(stream *jsoniter.Stream) {
+			writeDiagnosticReport(stream, diagnostics)
+		})
+	case DiagnosticFormatSARIF:
+		return writeDiagnosticJSON(w, func(stream *jsoniter.Stream) {
+			writeSARIFLog(stream, diagnostics)
+		})
+	default:
+		return errors.Errorf("unknown diagnostics format %q", string(format))
+	}
+}
+
+func writeDiagnosticJSON(w io.Writer, write func(stream *jsoniter.Stream)) error {
+	stream := jsoniter.NewStream(w, 4096, 2)
+	write(stream)
+	stream.WriteRaw("\n")
+	if stream.Error != nil {
+		return stream.Error
+	}
+	return stream.Flush()
+}
+
+// writeDiagnosticReport writes the JSON diagnostics document: a packages list
+// that groups the diagnostics of each Go package. Diagnostics that do not
+// belong to a package are grouped with an empty path.
+func writeDiagnosticReport(stream *jsoniter.Stream, diagnostics []Diagnostic) {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("packages")
+	groups := groupDiagnosticsByPackage(diagnostics)
+	if len(groups) == 0 {
+		stream.WriteEmptyArray()
+	} else {
+		stream.WriteArrayStart()
+		for idx, group := range groups {
+			if idx != 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectStart()
+			stream.WriteObjectField("package")
+			stream.WriteString(group.pkgPath)
+			stream.WriteMore()
+			stream.WriteObjectField("diagnostics")
+			stream.WriteArrayStart()
+			for diagIdx, diag := range group.diagnostics {
+				if diagIdx != 0 {
+					stream.WriteMore()
+				}
+				writeDiagnosticReportEntry(stream, diag)
+			}
+			stream.WriteArrayEnd()
+			stream.WriteObjectEnd()
+		}
+		stream.WriteArrayEnd()
+	}
+	stream.WriteObjectEnd()
+}
+
+func writeDiagnosticReportEntry(stream *jsoniter.Stream, diag Diagnostic) {
+	stream.WriteObjectStart()
+	stream.WriteObjectField("severity")
+	stream.WriteString(string(diag.Severity))
+	writeOptionalStringField(stream, "code", diag.Code)
+	writeOptionalStringField(stream, "stage", string(diag.Stage))
+	stream.WriteMore()
+	stream.WriteObjectField("message")
+	stream.WriteString(diag.Message)
+	writeOptionalStringField(stream, "detail", diag.Detail)
+	if file := diagnosticReportFile(diag.Position); file != "" {
+		stream.WriteMore()
+		stream.WriteObjectField("position")
+		stream.WriteObjectStart()
+		stream.WriteObjectField("file")
+		stream.WriteString(file)
+		stream.WriteMore()
+		stream.WriteObjectField("line")
+		stream.WriteInt(diag.Position.Line)
+		if diag.Position.Column != 0 {
+			stream.WriteMore()
+			stream.WriteObjectField("column")
+			stream.WriteInt(diag.Position.Column)
+		}
+		stream.WriteObjectEnd()
+	}
+	stream.WriteObjectEnd()
+}
+
+// writeOptionalStringField writes a field that follows another field and is
+// left out when value is empty.
+func writeOptionalStringField(stream *jsoniter.Stream, field string, value string) {
+	if value == "" {
+		return
+	}
+	stream.WriteMore()
+	stream.WriteObjectField(field)
+	stream.WriteString(value)
+}
+
+type diagnosticPackageGroup struct {
+	pkgPath     string
+	diagnostics []Diagnostic
+}
+
+// groupDiagnosticsByPackage groups diagnostics in first-seen package order.
+func groupDiagnosticsByPackage(diagnostics []Diagnostic) []diagnosticPackageGroup {
+	var groups []diagnosticPackageGroup
+	index := make(map[string]int)
+	for _, diag := range diagnostics {
+		idx, ok := index[diag.Package]
+		if !ok {
+			idx = len(groups)
+			index[diag.Package] = idx
+			groups = append(groups, diagnosticPackageGroup{pkgPath: diag.Package})
+		}
+		groups[idx].diagnostics = append(groups[idx].diagnostics, diag)
+	}
+	return groups
+}
+
+func diagnosticReportFile(pos *DiagnosticPosition) string {
+	if pos == nil || pos.Line <= 0 {
+		return ""
+	}
+	file := strings.TrimSpace(pos.DisplayFile)
+	if file == "" {
+		file = strings.TrimSpace(pos.File)
+	}
+	return filepath.ToSlash(file)
+}
+
+func diagnosticMessageText(diag Diagnostic) string {
+	if diag.Detail == "" {
+		return diag.Message
+	}
+	return diag.Message + " (" + diag.Detail + ")"
+}
+
+// writeSARIFLog writes diagnostics as the subset of the SARIF 2.1.0 log
+// format goscript emits: one run whose driver lists each diagnostic code as a
+// rule.
+func writeSARIFLog(stream *jsoniter.Stream, diagnostics []Diagnostic) {
+	var ordered []Diagnostic
+	var rules []string
+	seenRules := make(map[string]bool)
+	for _, group := range groupDiagnosticsByPackage(diagnostics) {
+		for _, diag := range group.diagnostics {
+			if diag.Code != "" && !seenRules[diag.Code] {
+				seenRules[diag.Code] = true
+				rules = append(rules, diag.Code)
+			}
+			ordered = append(ordered, diag)
+		}
+	}
+
+	stream.WriteObjectStart()
+	stream.WriteObjectField("$schema")
+	stream.WriteString("https://json.schemastore.org/sarif-2.1.0.json")
+	stream.WriteMore()
+	stream.WriteObjectField("version")
+	stream.WriteString("2.1.0")
+	stream.WriteMore()
+	stream.WriteObjectField("runs")
+	stream.WriteArrayStart()
+	stream.WriteObjectStart()
+	stream.WriteObjectField("tool")
+	stream.WriteObjectStart()
+	stream.WriteObjectField("driver")
+	stream.WriteObjectStart()
+	stream.WriteObjectField("name")
+	stream.WriteString("goscript")
+	stream.WriteMore()
+	stream.WriteObjectField("informationUri")
+	stream.WriteString("https://github.com/s4wave/goscript")
+	if len(rules) != 0 {
+		stream.WriteMore()
+		stream.WriteObjectField("rules")
+		stream.WriteArrayStart()
+		for idx, rule := range rules {
+			if idx != 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectStart()
+			stream.WriteObjectField("id")
+			stream.WriteString(rule)
+			stream.WriteObjectEnd()
+		}
+		stream.WriteArrayEnd()
+	}
+	stream.WriteObjectEnd()
+	stream.WriteObjectEnd()
+	stream.WriteMore()
+	stream.WriteObjectField("results")
+	if len(ordered) == 0 {
+		stream.WriteEmptyArray()
+	} else {
+		stream.WriteArrayStart()
+		for idx, diag := range ordered {
+			if idx != 0 {
+				stream.WriteMore()
+			}
+			writeSARIFResult(stream, diag)
+		}
+		stream.WriteArrayEnd()
+	}
+	stream.WriteObjectEnd()
+	stream.WriteArrayEnd()
+	stream.WriteObjectEnd()
+}
+
+func writeSARIFResult(stream *jsoniter.Stream, diag Diagnostic) {
+	stream.WriteObjectStart()
+	if diag.Code != "" {
+		stream.WriteObjectField("ruleId")
+		stream.WriteString(diag.Code)
+		stream.WriteMore()
+	}
+	stream.WriteObjectField("level")
+	stream.WriteString(sarifLevel(diag.Severity))
+	stream.WriteMore()
+	stream.WriteObjectField("message")
+	stream.WriteObjectStart()
+	stream.WriteObjectField("text")
+	stream.WriteString(diagnosticMessageText(diag))
+	stream.WriteObjectEnd()
+
+	file := diagnosticReportFile(diag.Position)
+	if file != "" || diag.Package != "" {
+		stream.WriteMore()
+		stream.WriteObjectField("locations")
+		stream.WriteArrayStart()
+		stream.WriteObjectStart()
+		if file != "" {
+			stream.WriteObjectField("physicalLocation")
+			stream.WriteObjectStart()
+			stream.WriteObjectField("artifactLocation")
+			stream.WriteObjectStart()
+			stream.WriteObjectField("uri")
+			stream.WriteString(sarifURI(file))
+			stream.WriteObjectEnd()
+			stream.WriteMore()
+			stream.WriteObjectField("region")
+			stream.WriteObjectStart()
+			stream.WriteObjectField("startLine")
+			stream.WriteInt(diag.Position.Line)
+			if diag.Position.Column != 0 {
+				stream.WriteMore()
+				stream.WriteObjectField("startColumn")
+				stream.WriteInt(diag.Position.Column)
+			}
+			stream.WriteObjectEnd()
+			stream.WriteObjectEnd()
+		}
+		if diag.Package != "" {
+			if file != "" {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField("logicalLocations")
+			stream.WriteArrayStart()
+			stream.WriteObjectStart()
+			stream.WriteObjectField("fullyQualifiedName")
+			stream.WriteString(diag.Package)
+			stream.WriteMore()
+			stream.WriteObjectField("kind")
+			stream.WriteString("namespace")
+			stream.WriteObjectEnd()
+			stream.WriteArrayEnd()
+		}
+		stream.WriteObjectEnd()
+		stream.WriteArrayEnd()
+	}
+
+	if diag.Package != "" || diag.Stage != "" {
+		stream.WriteMore()
+		stream.WriteObjectField("properties")
+		stream.WriteObjectStart()
+		if diag.Package != "" {
+			stream.WriteObjectField("package")
+			stream.WriteString(diag.Package)
+			if diag.Stage != "" {
+				stream.WriteMore()
+			}
+		}
+		if diag.Stage != "" {
+			stream.WriteObjectField("stage")
+			stream.WriteString(string(diag.Stage))
+		}
+		stream.WriteObjectEnd()
+	}
+	stream.WriteObjectEnd()
+}
+
+func sarifLevel(severity DiagnosticSeverity) string {
+	if severity == DiagnosticSeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// sarifURI keeps relative paths relative so code hosts can resolve them
+// against the checkout root, and turns absolute paths into file URIs.
+func sarifURI(file string) string {
+	if strings.HasPrefix(file, "/") {
+		return "file://" + file
+	}
+	if filepath.IsAbs(filepath.FromSlash(file)) {
+		return "file:///" + file
+	}
+	return file
+}
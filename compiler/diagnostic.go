@@ -16,6 +16,24 @@ const (
 	DiagnosticSeverityWarning DiagnosticSeverity = "warning"
 )
 
+// DiagnosticStage names the compiler pipeline stage that produced a diagnostic.
+type DiagnosticStage string
+
+const (
+	// DiagnosticStageRequest marks compile request validation.
+	DiagnosticStageRequest DiagnosticStage = "request"
+	// DiagnosticStagePackageGraph marks package loading and graph construction.
+	DiagnosticStagePackageGraph DiagnosticStage = "package-graph"
+	// DiagnosticStageSemanticModel marks semantic fact collection.
+	DiagnosticStageSemanticModel DiagnosticStage = "semantic-model"
+	// DiagnosticStageLowering marks Go-to-IR lowering.
+	DiagnosticStageLowering DiagnosticStage = "lowering"
+	// DiagnosticStageEmit marks TypeScript rendering and output writes.
+	DiagnosticStageEmit DiagnosticStage = "emit"
+	// DiagnosticStageOverrideRegistry marks override facts, parity checks, and copies.
+	DiagnosticStageOverrideRegistry DiagnosticStage = "override-registry"
+)
+
 // Diagnostic is a structured compiler message surfaced by every adapter.
 type Diagnostic struct {
 	// Severity is the diagnostic severity.
@@ -28,6 +46,10 @@ type Diagnostic struct {
 	Detail string
 	// Position is the optional source point that caused the diagnostic.
 	Position *DiagnosticPosition
+	// Stage is the pipeline stage that produced the diagnostic, if known.
+	Stage DiagnosticStage
+	// Package is the Go import path the diagnostic belongs to, if known.
+	Package string
 }
 
 // DiagnosticPosition identifies the source point that caused a diagnostic.
@@ -152,6 +174,26 @@ func diagnosticDisplayFile(file string, displayRoot string) string {
 	return filepath.ToSlash(file)
 }
 
+// withDiagnosticStage fills the stage of diagnostics that do not name one yet.
+func withDiagnosticStage(diagnostics []Diagnostic, stage DiagnosticStage) []Diagnostic {
+	for i := range diagnostics {
+		if diagnostics[i].Stage == "" {
+			diagnostics[i].Stage = stage
+		}
+	}
+	return diagnostics
+}
+
+// withDiagnosticPackage fills the package of diagnostics that do not name one yet.
+func withDiagnosticPackage(diagnostics []Diagnostic, pkgPath string) []Diagnostic {
+	for i := range diagnostics {
+		if diagnostics[i].Package == "" {
+			diagnostics[i].Package = pkgPath
+		}
+	}
+	return diagnostics
+}
+
 func diagnosticsHaveErrors(diagnostics []Diagnostic) bool {
 	for _, diag := range diagnostics {
 		if diag.Severity == DiagnosticSeverityError {
@@ -1,7 +1,9 @@
 package compiler
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"path/filepath"
 	"strings"
@@ -100,7 +102,144 @@ func TestLoweringUnsupportedDiagnosticIncludesSourcePosition(t *testing.T) {
 	if diag.Position.Line != 4 {
 		t.Fatalf("Line = %d, want 4", diag.Position.Line)
 	}
+	if diag.Stage != DiagnosticStageLowering {
+		t.Fatalf("Stage = %q, want %q", diag.Stage, DiagnosticStageLowering)
+	}
+	if diag.Package != "example.test/loweringdiag" {
+		t.Fatalf("Package = %q, want example.test/loweringdiag", diag.Package)
+	}
 	if got := FormatDiagnostics(compileErr.Diagnostics); !strings.HasPrefix(got, "main.go:4:") {
 		t.Fatalf("formatted diagnostic = %q, want main.go:4 prefix", got)
 	}
 }
+
+func TestWriteDiagnosticsGroupsJSONByPackage(t *testing.T) {
+	diagnostics := []Diagnostic{
+		{Severity: DiagnosticSeverityError, Code: "goscript/lowering:unsupported", Message: "first", Stage: DiagnosticStageLowering, Package: "example.test/a"},
+		{Severity: DiagnosticSeverityError, Code: "goscript/request:invalid", Message: "request", Stage: DiagnosticStageRequest},
+		{Severity: DiagnosticSeverityWarning, Code: "goscript/lowering:unsupported", Message: "second", Stage: DiagnosticStageLowering, Package: "example.test/a"},
+	}
+	var buf bytes.Buffer
+	if err := WriteDiagnostics(&buf, DiagnosticFormatJSON, diagnostics); err != nil {
+		t.Fatal(err.Error())
+	}
+	var report struct {
+		Packages []struct {
+			Package     string `json:"package"`
+			Diagnostics []struct {
+				Stage DiagnosticStage `json:"stage"`
+			} `json:"diagnostics"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v\n%s", err, buf.String())
+	}
+	if len(report.Packages) != 2 {
+		t.Fatalf("Packages = %#v, want two groups", report.Packages)
+	}
+	if report.Packages[0].Package != "example.test/a" || len(report.Packages[0].Diagnostics) != 2 {
+		t.Fatalf("first group = %#v, want both example.test/a diagnostics", report.Packages[0])
+	}
+	if report.Packages[1].Package != "" || report.Packages[1].Diagnostics[0].Stage != DiagnosticStageRequest {
+		t.Fatalf("second group = %#v, want package-less request diagnostic", report.Packages[1])
+	}
+}
+
+func TestWriteDiagnosticsRendersSARIF(t *testing.T) {
+	diagnostics := []Diagnostic{{
+		Severity: DiagnosticSeverityWarning,
+		Code:     "goscript/test",
+		Message:  "failed",
+		Detail:   "bad input",
+		Stage:    DiagnosticStageEmit,
+		Package:  "example.test/pkg",
+		Position: &DiagnosticPosition{
+			File:        filepath.Join("internal", "raw.go"),
+			DisplayFile: filepath.Join("pkg", "main.go"),
+			Line:        12,
+			Column:      3,
+		},
+	}}
+	var buf bytes.Buffer
+	if err := WriteDiagnostics(&buf, DiagnosticFormatSARIF, diagnostics); err != nil {
+		t.Fatal(err.Error())
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+					LogicalLocations []struct {
+						FullyQualifiedName string `json:"fullyQualifiedName"`
+					} `json:"logicalLocations"`
+				} `json:"locations"`
+				Properties *struct {
+					Stage DiagnosticStage `json:"stage"`
+				} `json:"properties"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("decode SARIF: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF envelope:\n%s", buf.String())
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "goscript/test" {
+		t.Fatalf("unexpected SARIF rules:\n%s", buf.String())
+	}
+	if len(run.Results) != 1 {
+		t.Fatalf("unexpected SARIF results:\n%s", buf.String())
+	}
+	result := run.Results[0]
+	if result.Level != "warning" || result.Message.Text != "failed (bad input)" {
+		t.Fatalf("unexpected SARIF result:\n%s", buf.String())
+	}
+	loc := result.Locations[0]
+	if loc.PhysicalLocation.ArtifactLocation.URI != "pkg/main.go" || loc.PhysicalLocation.Region.StartLine != 12 {
+		t.Fatalf("unexpected SARIF location:\n%s", buf.String())
+	}
+	if loc.LogicalLocations[0].FullyQualifiedName != "example.test/pkg" {
+		t.Fatalf("missing package logical location:\n%s", buf.String())
+	}
+	if result.Properties == nil || result.Properties.Stage != DiagnosticStageEmit {
+		t.Fatalf("missing stage property:\n%s", buf.String())
+	}
+}
+
+func TestParseDiagnosticFormat(t *testing.T) {
+	for value, want := range map[string]DiagnosticFormat{
+		"":      DiagnosticFormatText,
+		"text":  DiagnosticFormatText,
+		"JSON":  DiagnosticFormatJSON,
+		"sarif": DiagnosticFormatSARIF,
+	} {
+		got, err := ParseDiagnosticFormat(value)
+		if err != nil || got != want {
+			t.Fatalf("ParseDiagnosticFormat(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseDiagnosticFormat("xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
@@ -0,0 +1,418 @@
+// Package gobuild owns bundling GoScript packages into shippable JavaScript.
+package gobuild
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler"
+	"github.com/s4wave/goscript/compiler/tsworkspace"
+)
+
+const (
+	buildEntryFile        = "goscript-build-entry.ts"
+	builtinFacadeFile     = "goscript-builtin.ts"
+	declarationsConfig    = "tsconfig.declarations.json"
+	declarationsOutputDir = "types"
+	outputDirName         = "output"
+)
+
+// Builder owns GoScript bundle builds: compilation, the bundle entry, and the
+// Bun and TypeScript tool runs that produce the artifacts.
+type Builder struct{}
+
+// NewBuilder creates a bundle builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Build compiles the requested package with its dependencies and bundles it
+// into one ESM file. A main package bundle runs main when loaded; a library
+// bundle exports the requested identifiers and gets a .d.ts declaring them.
+// The bundle's builtin runtime import resolves to a facade that re-exports
+// only the runtime exports the compiled tree references.
+func (b *Builder) Build(ctx context.Context, req *Request) (*Result, error) {
+	norm, err := req.normalize()
+	if err != nil {
+		return nil, err
+	}
+	service := compiler.NewCompileService(norm.OverrideDirs...)
+
+	pkg, diagnostics, err := service.PackageGraphOwner().LoadPackage(ctx, &compiler.CompileRequest{
+		Patterns:       []string{norm.Package},
+		Dir:            norm.Dir,
+		BuildFlags:     append([]string(nil), norm.BuildFlags...),
+		OverrideDirs:   append([]string(nil), norm.OverrideDirs...),
+		DependencyMode: compiler.DependencyModeRequested,
+	})
+	result := &Result{Diagnostics: diagnostics}
+	if err != nil {
+		return result, err
+	}
+	result.PackagePath = pkg.PkgPath
+	isMain := pkg.Name == "main"
+	if isMain && len(norm.Exports) != 0 {
+		return result, errors.Errorf("package %s is a main package; exports apply to library packages", pkg.PkgPath)
+	}
+	if !isMain && len(norm.Exports) == 0 {
+		return result, errors.Errorf("library package %s needs at least one export", pkg.PkgPath)
+	}
+	if norm.Output == "" {
+		norm.Output = filepath.Join(norm.Dir, path.Base(pkg.PkgPath)+".js")
+	}
+	result.Output = norm.Output
+	result.Declarations = declarationsPath(norm.Output)
+
+	if norm.WorkDir == "" {
+		norm.WorkDir, err = tsworkspace.NewTempWorkDir(norm.Dir, "goscript-build-")
+		if err != nil {
+			return result, err
+		}
+		defer func() { _ = os.RemoveAll(norm.WorkDir) }()
+	}
+	outputRoot := filepath.Join(norm.WorkDir, outputDirName)
+
+	compileResult, err := service.Compile(ctx, &compiler.CompileRequest{
+		Patterns:            []string{pkg.PkgPath},
+		Dir:                 norm.Dir,
+		OutputPath:          outputRoot,
+		BuildFlags:          append([]string(nil), norm.BuildFlags...),
+		OverrideDirs:        append([]string(nil), norm.OverrideDirs...),
+		DependencyMode:      compiler.DependencyModeAll,
+		RuntimeEmissionMode: compiler.RuntimeEmissionModeEmit,
+		AllDependencies:     true,
+		SourceMaps:          norm.SourceMaps,
+		PreemptLoops:        norm.PreemptLoops,
+	})
+	if compileResult != nil {
+		result.Diagnostics = append(result.Diagnostics, compileResult.Diagnostics...)
+		result.OverridePackages = append(result.OverridePackages, compileResult.CopiedPackages...)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	packageDir := filepath.Join(outputRoot, "@goscript", filepath.FromSlash(pkg.PkgPath))
+	helpers := append([]compiler.RuntimeHelper(nil), compileResult.RuntimeHelpers...)
+	var entry string
+	if isMain {
+		entry, err = renderMainEntry(service.RuntimeContractOwner(), pkg.PkgPath, packageDir)
+		helpers = append(helpers, compiler.RuntimeHelperMainGoroutineStarted, compiler.RuntimeHelperStartMainGoroutine)
+	} else {
+		entry, err = renderLibraryEntry(pkg.PkgPath, packageDir, norm.Exports)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	workspace := tsworkspace.NewOwner(norm.WorkDir, norm.Dir)
+	sources, err := overrideSources(outputRoot, compileResult)
+	if err != nil {
+		return result, err
+	}
+	usage, err := service.RuntimeContractOwner().RuntimeUsage(helpers, sources)
+	if err != nil {
+		return result, err
+	}
+	result.RuntimeExports = usage.Exports
+	for _, helper := range usage.Helpers {
+		result.RuntimeHelpers = append(result.RuntimeHelpers, helper.Export)
+	}
+
+	for _, file := range []struct {
+		name string
+		data string
+	}{
+		{"package.json", "{\"type\":\"module\"}\n"},
+		{buildEntryFile, entry},
+		{builtinFacadeFile, renderBuiltinFacade(usage.Exports)},
+		{"tsconfig.json", renderBundleTypeScriptProject()},
+	} {
+		if phase := workspace.WriteFile(tsworkspace.PhaseWorkspace, file.name, file.data); phase.Failed() {
+			return result, errors.New(phase.Error)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(norm.Output), 0o755); err != nil {
+		return result, errors.Wrap(err, "create build output directory")
+	}
+
+	args := []string{"build", buildEntryFile, "--outfile", norm.Output, "--target", string(norm.Target), "--format", "esm"}
+	if norm.Minify {
+		args = append(args, "--minify")
+	}
+	if norm.SourceMaps {
+		args = append(args, "--sourcemap=external")
+	}
+	if bundle := workspace.RunTool(ctx, tsworkspace.PhaseRuntime, norm.WorkDir, "bun", args...); bundle.Failed() {
+		return result, errors.Errorf("bundle %s: %s\n%s", pkg.PkgPath, bundle.Error, strings.TrimSpace(bundle.Output))
+	}
+
+	if isMain {
+		err = os.WriteFile(result.Declarations, []byte("export {}\n"), 0o644)
+		return result, errors.Wrap(err, "write bundle declarations")
+	}
+	return result, b.writeDeclarations(ctx, workspace, norm, result.Declarations)
+}
+
+// renderMainEntry imports the generated file that declares main and starts
+// main unless that file already did. Generated main files start main only
+// when the host reports them as its entry script, which a browser never does
+// for a bundled module.
+func renderMainEntry(contract *compiler.RuntimeContractOwner, pkgPath string, packageDir string) (string, error) {
+	marker := contract.QualifiedHelper(compiler.RuntimeHelperIsMainScript) + "(import.meta)"
+	entries, err := os.ReadDir(packageDir)
+	if err != nil {
+		return "", errors.Wrap(err, "read generated main package")
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".gs.ts") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(packageDir, entry.Name()))
+		if err != nil {
+			return "", errors.Wrap(err, "read generated main package file")
+		}
+		if !strings.Contains(string(data), marker) {
+			continue
+		}
+		module := "@goscript/" + pkgPath + "/" + strings.TrimSuffix(entry.Name(), ".ts") + ".js"
+		builtin := contract.BuiltinImport()
+		var b strings.Builder
+		b.WriteString("import * as " + builtin.Alias + " from " + strconv.Quote(builtin.Source) + "\n")
+		b.WriteString("import { main } from " + strconv.Quote(module) + "\n\n")
+		b.WriteString("if (!" + contract.QualifiedHelper(compiler.RuntimeHelperMainGoroutineStarted) + "()) {\n")
+		b.WriteString("\t" + contract.QualifiedHelper(compiler.RuntimeHelperStartMainGoroutine) + "()\n")
+		b.WriteString("\tawait main()\n")
+		b.WriteString("}\n")
+		return b.String(), nil
+	}
+	return "", errors.Errorf("function main is undeclared in the main package %s", pkgPath)
+}
+
+var indexExportPattern = regexp.MustCompile(`export (type )?\{([^}]*)\} from`)
+
+// renderLibraryEntry re-exports the requested identifiers from the package's
+// generated index. Go types without a runtime value, such as interfaces, are
+// re-exported as types.
+func renderLibraryEntry(pkgPath string, packageDir string, exports []string) (string, error) {
+	index, err := os.ReadFile(filepath.Join(packageDir, "index.ts"))
+	if err != nil {
+		return "", errors.Wrap(err, "read generated package index")
+	}
+	values := make(map[string]bool)
+	types := make(map[string]bool)
+	for _, match := range indexExportPattern.FindAllStringSubmatch(string(index), -1) {
+		for name := range strings.SplitSeq(match[2], ",") {
+			name = strings.TrimSpace(name)
+			if match[1] != "" {
+				types[name] = true
+			} else {
+				values[name] = true
+			}
+		}
+	}
+	var valueExports, typeExports []string
+	for _, name := range exports {
+		switch {
+		case values[name]:
+			valueExports = append(valueExports, name)
+		case types[name]:
+			typeExports = append(typeExports, name)
+		default:
+			return "", errors.Errorf("package %s does not export %s", pkgPath, name)
+		}
+	}
+	module := strconv.Quote("@goscript/" + pkgPath + "/index.js")
+	var b strings.Builder
+	if len(typeExports) != 0 {
+		b.WriteString("export type { " + strings.Join(typeExports, ", ") + " } from " + module + "\n")
+	}
+	if len(valueExports) != 0 {
+		b.WriteString("export { " + strings.Join(valueExports, ", ") + " } from " + module + "\n")
+	}
+	return b.String(), nil
+}
+
+// overrideSources returns the handwritten override modules copied for a
+// build, leaving out the runtime itself and test files. Generated modules are
+// not scanned: lowering records the runtime helpers they use.
+func overrideSources(outputRoot string, compileResult *compiler.CompilationResult) ([]string, error) {
+	root := filepath.Join(outputRoot, "@goscript")
+	packageDirs := make(map[string]bool)
+	for _, pkgPath := range slices.Concat(compileResult.CompiledPackages, compileResult.CopiedPackages) {
+		packageDirs[filepath.Join(root, filepath.FromSlash(pkgPath))] = true
+	}
+	var sources []string
+	for _, pkgPath := range compileResult.CopiedPackages {
+		if pkgPath == "builtin" {
+			continue
+		}
+		pkgDir := filepath.Join(root, filepath.FromSlash(pkgPath))
+		err := filepath.WalkDir(pkgDir, func(file string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if file != pkgDir && packageDirs[file] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(file) != ".ts" || strings.HasSuffix(file, ".test.ts") {
+				return nil
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, string(data))
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "scan override modules for runtime usage")
+		}
+	}
+	return sources, nil
+}
+
+// renderBuiltinFacade re-exports exports from the builtin runtime. The bundle
+// project resolves the builtin import to this facade, so the bundler drops
+// every runtime export the program does not reference.
+func renderBuiltinFacade(exports []string) string {
+	if len(exports) == 0 {
+		return "export {}\n"
+	}
+	var b strings.Builder
+	b.WriteString("export {\n")
+	for _, name := range exports {
+		b.WriteString("\t" + name + ",\n")
+	}
+	b.WriteString("} from " + strconv.Quote("./"+outputDirName+"/@goscript/builtin/index.ts") + "\n")
+	return b.String()
+}
+
+func renderBundleTypeScriptProject() string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	b.WriteString("  \"compilerOptions\": {\n")
+	b.WriteString("    \"target\": \"ES2022\",\n")
+	b.WriteString("    \"module\": \"ESNext\",\n")
+	b.WriteString("    \"moduleResolution\": \"Bundler\",\n")
+	b.WriteString("    \"allowImportingTsExtensions\": true,\n")
+	b.WriteString("    \"noEmit\": true,\n")
+	b.WriteString("    \"paths\": {\n")
+	b.WriteString("      \"@goscript/builtin/index.js\": [" + strconv.Quote("./"+builtinFacadeFile) + "],\n")
+	b.WriteString("      \"@goscript/*\": [" + strconv.Quote("./"+outputDirName+"/@goscript/*") + "]\n")
+	b.WriteString("    }\n")
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// writeDeclarations emits declarations for the bundle entry with tsgo,
+// rewrites their @goscript imports to relative paths, and writes them next
+// to the bundle: <name>.d.ts re-exports the entry declarations from the
+// <name>.types directory.
+func (b *Builder) writeDeclarations(ctx context.Context, workspace *tsworkspace.Owner, req *normalizedRequest, declarations string) error {
+	nodeTypesAvailable := tsworkspace.NodeTypesPresent(req.WorkDir, req.Dir)
+	if phase := workspace.EnsureNodeAmbientTypes(); phase.Failed() {
+		return errors.New(phase.Error)
+	}
+	if phase := workspace.WriteFile(tsworkspace.PhaseWorkspace, declarationsConfig, renderDeclarationsProject(nodeTypesAvailable)); phase.Failed() {
+		return errors.New(phase.Error)
+	}
+	if emit := workspace.RunTool(ctx, tsworkspace.PhaseTypeCheck, req.WorkDir, "tsgo", "--project", declarationsConfig); emit.Failed() {
+		return errors.Errorf("emit declarations: %s\n%s", emit.Error, strings.TrimSpace(emit.Output))
+	}
+
+	typesDir := strings.TrimSuffix(declarations, ".d.ts") + ".types"
+	if err := os.RemoveAll(typesDir); err != nil {
+		return errors.Wrap(err, "clear bundle declarations")
+	}
+	emitted := filepath.Join(req.WorkDir, declarationsOutputDir)
+	goscriptRoot := filepath.Join(typesDir, outputDirName, "@goscript")
+	err := filepath.WalkDir(emitted, func(file string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(emitted, file)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(typesDir, rel)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		data = []byte(rewriteDeclarationImports(string(data), filepath.Dir(dest), goscriptRoot))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(dest, data, 0o644)
+	})
+	if err != nil {
+		return errors.Wrap(err, "write bundle declarations")
+	}
+	entry := "./" + filepath.Base(typesDir) + "/" + strings.TrimSuffix(buildEntryFile, ".ts") + ".js"
+	return errors.Wrap(os.WriteFile(declarations, []byte("export * from "+strconv.Quote(entry)+"\n"), 0o644), "write bundle declarations")
+}
+
+var declarationImportPattern = regexp.MustCompile(`(["'])@goscript/([^"']+)(["'])`)
+
+// rewriteDeclarationImports points @goscript/... module specifiers in a
+// declaration file written to dir at the copied declaration tree under
+// goscriptRoot, so consumers need no path aliases.
+func rewriteDeclarationImports(data string, dir string, goscriptRoot string) string {
+	return declarationImportPattern.ReplaceAllStringFunc(data, func(match string) string {
+		parts := declarationImportPattern.FindStringSubmatch(match)
+		rel, err := filepath.Rel(dir, filepath.Join(goscriptRoot, filepath.FromSlash(parts[2])))
+		if err != nil {
+			return match
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, ".") {
+			rel = "./" + rel
+		}
+		return parts[1] + rel + parts[3]
+	})
+}
+
+func renderDeclarationsProject(nodeTypesAvailable bool) string {
+	types := "[]"
+	if nodeTypesAvailable {
+		types = "[\"node\"]"
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	b.WriteString("  \"compilerOptions\": {\n")
+	b.WriteString("    \"target\": \"ES2022\",\n")
+	b.WriteString("    \"module\": \"ESNext\",\n")
+	b.WriteString("    \"moduleResolution\": \"Bundler\",\n")
+	b.WriteString("    \"lib\": [\"ESNext\", \"DOM\"],\n")
+	b.WriteString("    \"strict\": true,\n")
+	b.WriteString("    \"skipLibCheck\": true,\n")
+	b.WriteString("    \"allowImportingTsExtensions\": true,\n")
+	b.WriteString("    \"declaration\": true,\n")
+	b.WriteString("    \"emitDeclarationOnly\": true,\n")
+	b.WriteString("    \"rootDir\": \".\",\n")
+	b.WriteString("    \"outDir\": " + strconv.Quote(declarationsOutputDir) + ",\n")
+	b.WriteString("    \"types\": " + types + ",\n")
+	b.WriteString("    \"paths\": {\n")
+	b.WriteString("      \"@goscript/*\": [" + strconv.Quote("./"+outputDirName+"/@goscript/*") + "]\n")
+	b.WriteString("    }\n")
+	b.WriteString("  },\n")
+	b.WriteString("  \"include\": [" + strconv.Quote(buildEntryFile) + ", " + strconv.Quote(tsworkspace.NodeAmbientTypesFile) + "]\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func declarationsPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".d.ts"
+}
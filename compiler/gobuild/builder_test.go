@@ -0,0 +1,130 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s4wave/goscript/compiler/tsworkspace"
+)
+
+func TestBuilderRequiresExportsForLibraryPackage(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod":   "module example.test/gobuild\n\ngo 1.25.3\n",
+		"value.go": "package gobuild\n\nfunc Value() int { return 7 }\n",
+	})
+
+	_, err := NewBuilder().Build(context.Background(), &Request{Dir: moduleDir, Package: "."})
+	if err == nil || !strings.Contains(err.Error(), "library package example.test/gobuild needs at least one export") {
+		t.Fatalf("expected missing exports error, got %v", err)
+	}
+}
+
+func TestRequestRejectsUnexportedExport(t *testing.T) {
+	_, err := (&Request{Package: ".", Exports: []string{"Value,helper"}}).normalize()
+	if err == nil || !strings.Contains(err.Error(), `export "helper" is not an exported Go identifier`) {
+		t.Fatalf("expected unexported export error, got %v", err)
+	}
+}
+
+func TestRenderLibraryEntrySplitsTypeAndValueExports(t *testing.T) {
+	dir := writeFixture(t, map[string]string{
+		"index.ts": strings.Join([]string{
+			`export type { Reader } from "./reader.gs.ts"`,
+			`export { Buffer, NewBuffer } from "./buffer.gs.ts"`,
+			"",
+		}, "\n"),
+	})
+
+	entry, err := renderLibraryEntry("example.test/lib", dir, []string{"NewBuffer", "Reader"})
+	if err != nil {
+		t.Fatalf("renderLibraryEntry: %v", err)
+	}
+	want := strings.Join([]string{
+		`export type { Reader } from "@goscript/example.test/lib/index.js"`,
+		`export { NewBuffer } from "@goscript/example.test/lib/index.js"`,
+		"",
+	}, "\n")
+	if entry != want {
+		t.Fatalf("unexpected entry:\n%s", entry)
+	}
+
+	_, err = renderLibraryEntry("example.test/lib", dir, []string{"Missing"})
+	if err == nil || !strings.Contains(err.Error(), "package example.test/lib does not export Missing") {
+		t.Fatalf("expected missing export error, got %v", err)
+	}
+}
+
+func TestRewriteDeclarationImportsUsesRelativePaths(t *testing.T) {
+	root := filepath.Join("/out", "lib.types")
+	goscriptRoot := filepath.Join(root, "output", "@goscript")
+
+	got := rewriteDeclarationImports(
+		`import * as $ from "@goscript/builtin/index.js";`+"\n"+`export { Value } from '@goscript/example.test/lib/index.js';`,
+		filepath.Join(goscriptRoot, "example.test", "lib"),
+		goscriptRoot,
+	)
+	want := `import * as $ from "../../builtin/index.js";` + "\n" + `export { Value } from './index.js';`
+	if got != want {
+		t.Fatalf("unexpected rewrite:\n%s", got)
+	}
+}
+
+func TestBuilderBundlesMainPackage(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod":  "module example.test/gobuild\n\ngo 1.25.3\n",
+		"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"bundled\")\n}\n",
+	})
+	workspace := requireBun(t, moduleDir)
+
+	result, err := NewBuilder().Build(context.Background(), &Request{
+		Dir:     moduleDir,
+		Package: ".",
+		Target:  TargetBun,
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if result.Output != filepath.Join(moduleDir, "gobuild.js") {
+		t.Fatalf("unexpected bundle path %q", result.Output)
+	}
+	if len(result.RuntimeHelpers) == 0 || len(result.RuntimeExports) < len(result.RuntimeHelpers) {
+		t.Fatalf("expected runtime usage, got helpers %v exports %v", result.RuntimeHelpers, result.RuntimeExports)
+	}
+
+	run := workspace.RunTool(context.Background(), tsworkspace.PhaseRuntime, moduleDir, "bun", result.Output)
+	if run.Failed() {
+		t.Fatalf("run bundle: %s\n%s", run.Error, run.Output)
+	}
+	if got := strings.TrimSpace(run.Output); got != "bundled" {
+		t.Fatalf("unexpected bundle output %q", got)
+	}
+}
+
+func requireBun(t *testing.T, dir string) *tsworkspace.Owner {
+	t.Helper()
+
+	workspace := tsworkspace.NewOwner(dir, dir)
+	if _, err := workspace.FindTool("bun"); err != nil {
+		t.Skip(err.Error())
+	}
+	return workspace
+}
+
+func writeFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	return dir
+}
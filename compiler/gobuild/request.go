@@ -0,0 +1,143 @@
+package gobuild
+
+import (
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler"
+)
+
+// Target selects the JavaScript host a bundle is built for.
+type Target string
+
+const (
+	// TargetBrowser bundles for browsers.
+	TargetBrowser Target = "browser"
+	// TargetBun bundles for Bun.
+	TargetBun Target = "bun"
+)
+
+// Request describes one GoScript bundle build.
+type Request struct {
+	// Dir is the working directory for package loading.
+	Dir string
+	// Package is the pattern naming the package to build.
+	Package string
+	// Exports are the exported Go identifiers a library bundle exposes. A
+	// main package bundle runs main and takes no exports.
+	Exports []string
+	// Output is the bundle path. Empty writes <package base name>.js in Dir.
+	Output string
+	// Target selects the bundle host. Empty builds for browsers.
+	Target Target
+	// Minify minifies the bundle.
+	Minify bool
+	// BuildTags are normalized into a Go -tags build flag.
+	BuildTags []string
+	// OverrideDirs are additional GoScript override roots.
+	OverrideDirs []string
+	// WorkDir stores the generated workspace. Empty uses a temporary
+	// directory that is removed after the build.
+	WorkDir string
+	// SourceMaps writes an external source map beside the bundle.
+	SourceMaps bool
+	// PreemptLoops adds cooperative yield checks to loops in async functions.
+	PreemptLoops bool
+}
+
+type normalizedRequest struct {
+	Dir          string
+	Package      string
+	Exports      []string
+	Output       string
+	Target       Target
+	Minify       bool
+	BuildFlags   []string
+	OverrideDirs []string
+	WorkDir      string
+	SourceMaps   bool
+	PreemptLoops bool
+}
+
+func (r *Request) normalize() (*normalizedRequest, error) {
+	if r == nil {
+		return nil, errors.New("goscript build request cannot be nil")
+	}
+
+	dir := strings.TrimSpace(r.Dir)
+	if dir == "" {
+		dir = "."
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve build working directory")
+	}
+
+	pkg := strings.TrimSpace(r.Package)
+	if pkg == "" {
+		return nil, errors.New("a package to build is required")
+	}
+
+	var exports []string
+	for _, value := range r.Exports {
+		for name := range strings.SplitSeq(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || slices.Contains(exports, name) {
+				continue
+			}
+			if !token.IsIdentifier(name) || !token.IsExported(name) {
+				return nil, errors.Errorf("export %q is not an exported Go identifier", name)
+			}
+			exports = append(exports, name)
+		}
+	}
+
+	output := strings.TrimSpace(r.Output)
+	if output != "" {
+		output, err = filepath.Abs(output)
+		if err != nil {
+			return nil, errors.Wrap(err, "resolve build output")
+		}
+	}
+
+	target := r.Target
+	if target == "" {
+		target = TargetBrowser
+	}
+	switch target {
+	case TargetBrowser, TargetBun:
+	default:
+		return nil, errors.Errorf("unsupported build target %q", target)
+	}
+
+	buildFlags := compiler.BuildTagsFlags(r.BuildTags)
+	overrideDirs, err := compiler.NormalizeOverrideDirs(r.OverrideDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir := strings.TrimSpace(r.WorkDir)
+	if workDir != "" {
+		workDir, err = filepath.Abs(workDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "resolve build work directory")
+		}
+	}
+
+	return &normalizedRequest{
+		Dir:          absDir,
+		Package:      pkg,
+		Exports:      exports,
+		Output:       output,
+		Target:       target,
+		Minify:       r.Minify,
+		BuildFlags:   buildFlags,
+		OverrideDirs: overrideDirs,
+		WorkDir:      workDir,
+		SourceMaps:   r.SourceMaps,
+		PreemptLoops: r.PreemptLoops,
+	}, nil
+}
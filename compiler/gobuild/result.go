@@ -0,0 +1,22 @@
+package gobuild
+
+import "github.com/s4wave/goscript/compiler"
+
+// Result describes one GoScript bundle build.
+type Result struct {
+	// PackagePath is the package that was built.
+	PackagePath string
+	// Output is the bundled ESM JavaScript file.
+	Output string
+	// Declarations is the .d.ts file for the bundle's public API.
+	Declarations string
+	// RuntimeHelpers are the exports of compiler-visible runtime helpers the
+	// bundle includes.
+	RuntimeHelpers []string
+	// RuntimeExports are all builtin runtime exports the bundle includes.
+	RuntimeExports []string
+	// OverridePackages are the handwritten override packages in the bundle.
+	OverridePackages []string
+	// Diagnostics are compiler diagnostics surfaced during the build.
+	Diagnostics []compiler.Diagnostic
+}
@@ -0,0 +1,115 @@
+package gorun
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler"
+)
+
+// Request describes one GoScript program run.
+type Request struct {
+	// Dir is the working directory for package loading and for the program.
+	Dir string
+	// Package is the pattern naming the main package to run.
+	Package string
+	// Args are the program arguments after os.Args[0].
+	Args []string
+	// BuildTags are normalized into a Go -tags build flag.
+	BuildTags []string
+	// OverrideDirs are additional GoScript override roots.
+	OverrideDirs []string
+	// WorkDir stores the generated workspace. Empty uses a temporary
+	// directory that is removed after the run.
+	WorkDir string
+	// OutputRoot stores generated TypeScript packages.
+	OutputRoot string
+	// SourceMaps emits source maps so panics print Go frames.
+	SourceMaps bool
+	// PreemptLoops adds cooperative yield checks to loops in async functions.
+	PreemptLoops bool
+	// Stdin is the program's standard input. Nil reads from the null device.
+	Stdin io.Reader
+	// Stdout receives the program's standard output.
+	Stdout io.Writer
+	// Stderr receives the program's standard error.
+	Stderr io.Writer
+	// Env holds KEY=value entries added to the inherited environment.
+	Env []string
+}
+
+type normalizedRequest struct {
+	Dir          string
+	Package      string
+	Args         []string
+	BuildFlags   []string
+	OverrideDirs []string
+	WorkDir      string
+	OutputRoot   string
+	SourceMaps   bool
+	PreemptLoops bool
+	Stdin        io.Reader
+	Stdout       io.Writer
+	Stderr       io.Writer
+	Env          []string
+}
+
+func (r *Request) normalize() (*normalizedRequest, error) {
+	if r == nil {
+		return nil, errors.New("goscript run request cannot be nil")
+	}
+
+	dir := strings.TrimSpace(r.Dir)
+	if dir == "" {
+		dir = "."
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve run working directory")
+	}
+
+	pkg := strings.TrimSpace(r.Package)
+	if pkg == "" {
+		return nil, errors.New("a main package is required")
+	}
+
+	buildFlags := compiler.BuildTagsFlags(r.BuildTags)
+	overrideDirs, err := compiler.NormalizeOverrideDirs(r.OverrideDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir := strings.TrimSpace(r.WorkDir)
+	if workDir != "" {
+		workDir, err = filepath.Abs(workDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "resolve run work directory")
+		}
+	}
+
+	outputRoot := strings.TrimSpace(r.OutputRoot)
+	if outputRoot != "" {
+		outputRoot, err = filepath.Abs(outputRoot)
+		if err != nil {
+			return nil, errors.Wrap(err, "resolve run output root")
+		}
+	}
+
+	return &normalizedRequest{
+		Dir:          absDir,
+		Package:      pkg,
+		Args:         append([]string(nil), r.Args...),
+		BuildFlags:   buildFlags,
+		OverrideDirs: overrideDirs,
+		WorkDir:      workDir,
+		OutputRoot:   outputRoot,
+		SourceMaps:   r.SourceMaps,
+		PreemptLoops: r.PreemptLoops,
+		Stdin:        r.Stdin,
+		Stdout:       r.Stdout,
+		Stderr:       r.Stderr,
+		Env:          append([]string(nil), r.Env...),
+	}, nil
+}
@@ -0,0 +1,20 @@
+package gorun
+
+import "github.com/s4wave/goscript/compiler"
+
+// Result describes one GoScript program run.
+type Result struct {
+	// PackagePath is the main package that ran.
+	PackagePath string
+	// WorkDir is the generated workspace.
+	WorkDir string
+	// OutputRoot is the generated TypeScript package root.
+	OutputRoot string
+	// Entry is the generated file that declares main.
+	Entry string
+	// ExitCode is the program's exit status: the os.Exit code, 2 after an
+	// unrecovered panic or deadlock, and 0 when main returns.
+	ExitCode int
+	// Diagnostics are compiler diagnostics surfaced during the run.
+	Diagnostics []compiler.Diagnostic
+}
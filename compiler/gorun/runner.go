@@ -0,0 +1,150 @@
+// Package gorun owns compiling and running GoScript main packages.
+package gorun
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler"
+	"github.com/s4wave/goscript/compiler/tsworkspace"
+)
+
+// argv0Env names the environment variable the runtime reads os.Args[0] from.
+const argv0Env = "GOSCRIPT_ARGV0"
+
+// Runner owns GoScript program compilation and execution in Bun.
+type Runner struct{}
+
+// NewRunner creates a program runner.
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Run compiles the requested main package with its dependencies into a
+// workspace and runs it in Bun attached to the request's stdio. A program
+// that ran returns a nil error whatever its exit code; errors report that
+// the program could not be built or started.
+func (r *Runner) Run(ctx context.Context, req *Request) (*Result, error) {
+	norm, err := req.normalize()
+	if err != nil {
+		return nil, err
+	}
+	service := compiler.NewCompileService(norm.OverrideDirs...)
+
+	pkg, entryFile, diagnostics, err := loadMainPackage(ctx, service, norm)
+	result := &Result{Diagnostics: diagnostics, ExitCode: -1}
+	if err != nil {
+		return result, err
+	}
+	result.PackagePath = pkg.PkgPath
+
+	if norm.WorkDir == "" {
+		norm.WorkDir, err = tsworkspace.NewTempWorkDir(norm.Dir, "goscript-run-")
+		if err != nil {
+			return result, err
+		}
+		defer func() { _ = os.RemoveAll(norm.WorkDir) }()
+	}
+	if norm.OutputRoot == "" {
+		norm.OutputRoot = filepath.Join(norm.WorkDir, "output")
+	}
+	result.WorkDir = norm.WorkDir
+	result.OutputRoot = norm.OutputRoot
+	result.Entry = filepath.Join(norm.OutputRoot, "@goscript", filepath.FromSlash(pkg.PkgPath), strings.TrimSuffix(filepath.Base(entryFile), ".go")+".gs.ts")
+
+	compileReq := &compiler.CompileRequest{
+		Patterns:            []string{pkg.PkgPath},
+		Dir:                 norm.Dir,
+		OutputPath:          norm.OutputRoot,
+		BuildFlags:          append([]string(nil), norm.BuildFlags...),
+		OverrideDirs:        append([]string(nil), norm.OverrideDirs...),
+		DependencyMode:      compiler.DependencyModeAll,
+		RuntimeEmissionMode: compiler.RuntimeEmissionModeEmit,
+		AllDependencies:     true,
+		SourceMaps:          norm.SourceMaps,
+		PreemptLoops:        norm.PreemptLoops,
+	}
+	compileResult, err := service.Compile(ctx, compileReq)
+	if compileResult != nil {
+		result.Diagnostics = append(result.Diagnostics, compileResult.Diagnostics...)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	workspace := tsworkspace.NewOwner(norm.WorkDir, norm.Dir)
+	if phase := workspace.EnsurePackageJSON(); phase.Failed() {
+		return result, errors.New(phase.Error)
+	}
+	if phase := workspace.WriteModuleShims([]string{norm.OutputRoot}); phase.Failed() {
+		return result, errors.New(phase.Error)
+	}
+
+	stdio := tsworkspace.ToolIO{
+		Stdin:  norm.Stdin,
+		Stdout: norm.Stdout,
+		Stderr: norm.Stderr,
+		Env:    append([]string{argv0Env + "=" + path.Base(pkg.PkgPath)}, norm.Env...),
+	}
+	run := workspace.RunToolIO(ctx, tsworkspace.PhaseRuntime, norm.Dir, stdio, "bun", append([]string{result.Entry}, norm.Args...)...)
+	result.ExitCode = run.ExitCode
+	if run.ExitCode < 0 {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		return result, errors.New("run " + pkg.PkgPath + ": " + run.Error)
+	}
+	return result, nil
+}
+
+// loadMainPackage resolves the requested pattern to one main package and the
+// Go file that declares func main.
+func loadMainPackage(ctx context.Context, service *compiler.CompileService, req *normalizedRequest) (*compiler.PackageGraphNode, string, []compiler.Diagnostic, error) {
+	pkg, diagnostics, err := service.PackageGraphOwner().LoadPackage(ctx, &compiler.CompileRequest{
+		Patterns:       []string{req.Package},
+		Dir:            req.Dir,
+		BuildFlags:     append([]string(nil), req.BuildFlags...),
+		OverrideDirs:   append([]string(nil), req.OverrideDirs...),
+		DependencyMode: compiler.DependencyModeRequested,
+	})
+	if err != nil {
+		return nil, "", diagnostics, err
+	}
+	if pkg.Name != "main" {
+		return nil, "", diagnostics, errors.Errorf("package %s is not a main package", pkg.PkgPath)
+	}
+	entryFile, err := mainFuncFile(pkg.CompiledGoFiles)
+	if err != nil {
+		return nil, "", diagnostics, err
+	}
+	if entryFile == "" {
+		return nil, "", diagnostics, errors.Errorf("function main is undeclared in the main package %s", pkg.PkgPath)
+	}
+	return pkg, entryFile, diagnostics, nil
+}
+
+// mainFuncFile returns the file among files that declares func main, or ""
+// when none does.
+func mainFuncFile(files []string) (string, error) {
+	fset := token.NewFileSet()
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", errors.Wrap(err, "parse main package file")
+		}
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && fn.Name.Name == "main" {
+				return file, nil
+			}
+		}
+	}
+	return "", nil
+}
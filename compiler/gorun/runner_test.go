@@ -0,0 +1,137 @@
+package gorun
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s4wave/goscript/compiler/tsworkspace"
+)
+
+func TestRunnerRequiresMainFunc(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod":          "module example.test/gorun\n\ngo 1.25.3\n",
+		"cmd/tool/doc.go": "package main\n\nfunc helper() {}\n",
+	})
+
+	_, err := NewRunner().Run(context.Background(), &Request{Dir: moduleDir, Package: "./cmd/tool"})
+	if err == nil || !strings.Contains(err.Error(), "function main is undeclared in the main package example.test/gorun/cmd/tool") {
+		t.Fatalf("expected missing main error, got %v", err)
+	}
+}
+
+func TestMainFuncFileFindsMainDeclaration(t *testing.T) {
+	dir := writeFixture(t, map[string]string{
+		"flags.go": "package main\n\nfunc (c *config) main() {}\n\ntype config struct{}\n",
+		"main.go":  "package main\n\nfunc main() {}\n",
+	})
+
+	got, err := mainFuncFile([]string{filepath.Join(dir, "flags.go"), filepath.Join(dir, "main.go")})
+	if err != nil {
+		t.Fatalf("mainFuncFile: %v", err)
+	}
+	if got != filepath.Join(dir, "main.go") {
+		t.Fatalf("mainFuncFile() = %q, want main.go", got)
+	}
+}
+
+func TestRunnerRunsMainPackage(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod": "module example.test/gorun\n\ngo 1.25.3\n",
+		"cmd/tool/main.go": strings.Join([]string{
+			"package main",
+			"",
+			"import (",
+			"\t\"bufio\"",
+			"\t\"fmt\"",
+			"\t\"os\"",
+			")",
+			"",
+			"func main() {",
+			"\tline, _ := bufio.NewReader(os.Stdin).ReadString('\\n')",
+			"\tfmt.Println(os.Args[0], os.Args[1:], line)",
+			"\tfmt.Fprintln(os.Stderr, os.Getenv(\"GORUN_TEST\"))",
+			"\tos.Exit(3)",
+			"}",
+			"",
+		}, "\n"),
+	})
+	requireBun(t, moduleDir)
+
+	var stdout, stderr bytes.Buffer
+	result, err := NewRunner().Run(context.Background(), &Request{
+		Dir:     moduleDir,
+		Package: "./cmd/tool",
+		Args:    []string{"-v", "input.txt"},
+		Stdin:   strings.NewReader("hello\n"),
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+		Env:     []string{"GORUN_TEST=from-env"},
+	})
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	if result.ExitCode != 3 {
+		t.Fatalf("expected os.Exit code 3, got %d\n%s", result.ExitCode, stderr.String())
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "tool [-v input.txt] hello" {
+		t.Fatalf("unexpected stdout %q", got)
+	}
+	if got := strings.TrimSpace(stderr.String()); got != "from-env" {
+		t.Fatalf("unexpected stderr %q", got)
+	}
+	if _, err := os.Stat(result.WorkDir); !os.IsNotExist(err) {
+		t.Fatalf("expected temporary workdir to be removed, got %v", err)
+	}
+}
+
+func TestRunnerExitsTwoOnPanic(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod":  "module example.test/gorun\n\ngo 1.25.3\n",
+		"main.go": "package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n",
+	})
+	requireBun(t, moduleDir)
+
+	var stderr bytes.Buffer
+	result, err := NewRunner().Run(context.Background(), &Request{
+		Dir:     moduleDir,
+		Package: ".",
+		Stderr:  &stderr,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.ExitCode != 2 {
+		t.Fatalf("expected exit code 2 after a panic, got %d\n%s", result.ExitCode, stderr.String())
+	}
+	if !strings.HasPrefix(stderr.String(), "panic: boom\n\ngoroutine 1 [running]:\n") {
+		t.Fatalf("expected Go panic output, got:\n%s", stderr.String())
+	}
+}
+
+func requireBun(t *testing.T, dir string) {
+	t.Helper()
+
+	if _, err := tsworkspace.NewOwner(dir, dir).FindTool("bun"); err != nil {
+		t.Skip(err.Error())
+	}
+}
+
+func writeFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	return dir
+}
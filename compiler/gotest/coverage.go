@@ -0,0 +1,223 @@
+package gotest
+
+import (
+	"cmp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const coverageRecordPrefix = "__GOSCRIPT_COVERAGE__"
+
+// CoverMode selects how coverage profiles count block executions.
+type CoverMode string
+
+const (
+	// CoverModeSet records whether each block ran.
+	CoverModeSet CoverMode = "set"
+	// CoverModeCount records how many times each block ran.
+	CoverModeCount CoverMode = "count"
+	// CoverModeAtomic counts like CoverModeCount. JavaScript runs goroutines
+	// on one thread, so counts never race.
+	CoverModeAtomic CoverMode = "atomic"
+)
+
+// CoverageBlock is one basic block of a coverage profile.
+type CoverageBlock struct {
+	// File is the profile file name: the package path and the file's base name.
+	File string
+	// StartLine and StartCol locate the first character of the block.
+	StartLine int
+	StartCol  int
+	// EndLine and EndCol locate the position just past the block.
+	EndLine int
+	EndCol  int
+	// Statements is the number of statements in the block.
+	Statements int
+	// Count is how many times the block ran.
+	Count int64
+}
+
+// Coverage is the statement coverage measured while one package's tests ran.
+type Coverage struct {
+	// Blocks are the blocks of the covered packages, in profile order.
+	Blocks []CoverageBlock
+}
+
+// Statements returns the number of statements and how many of them ran.
+func (c *Coverage) Statements() (total int, covered int) {
+	if c == nil {
+		return 0, 0
+	}
+	for _, block := range c.Blocks {
+		total += block.Statements
+		if block.Count != 0 {
+			covered += block.Statements
+		}
+	}
+	return total, covered
+}
+
+// parseCoverageRecords removes the coverage records the generated runner
+// printed from output and returns the coverage of packagePath.
+func parseCoverageRecords(output string, packagePath string) (string, *Coverage, error) {
+	if !strings.Contains(output, coverageRecordPrefix) {
+		return output, nil, nil
+	}
+	var kept []string
+	var coverage *Coverage
+	for line := range strings.SplitSeq(output, "\n") {
+		record, ok := strings.CutPrefix(strings.TrimSuffix(line, "\r"), coverageRecordPrefix)
+		if !ok {
+			kept = append(kept, line)
+			continue
+		}
+		recordPath, profile, ok := strings.Cut(record, "\t")
+		if !ok {
+			return output, nil, errors.New("malformed coverage record")
+		}
+		recordPath, pathErr := url.PathUnescape(recordPath)
+		profile, profileErr := url.PathUnescape(profile)
+		if pathErr != nil || profileErr != nil {
+			return output, nil, errors.New("malformed coverage record")
+		}
+		if recordPath != packagePath {
+			continue
+		}
+		blocks, err := parseCoverageBlocks(profile)
+		if err != nil {
+			return output, nil, err
+		}
+		coverage = &Coverage{Blocks: blocks}
+	}
+	return strings.Join(kept, "\n"), coverage, nil
+}
+
+// parseCoverageBlocks parses coverprofile block lines without a mode line.
+func parseCoverageBlocks(profile string) ([]CoverageBlock, error) {
+	var blocks []CoverageBlock
+	for line := range strings.SplitSeq(profile, "\n") {
+		if line == "" {
+			continue
+		}
+		block, ok := parseCoverageBlock(line)
+		if !ok {
+			return nil, errors.Errorf("malformed coverage profile line %q", line)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// parseCoverageBlock parses one "file:start.col,end.col statements count"
+// profile line.
+func parseCoverageBlock(line string) (CoverageBlock, bool) {
+	colon := strings.LastIndex(line, ":")
+	if colon < 0 {
+		return CoverageBlock{}, false
+	}
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) != 3 {
+		return CoverageBlock{}, false
+	}
+	start, end, ok := strings.Cut(fields[0], ",")
+	if !ok {
+		return CoverageBlock{}, false
+	}
+	block := CoverageBlock{File: line[:colon]}
+	var err error
+	parsePos := func(pos string, line *int, col *int) bool {
+		lineField, colField, ok := strings.Cut(pos, ".")
+		if !ok {
+			return false
+		}
+		if *line, err = strconv.Atoi(lineField); err != nil {
+			return false
+		}
+		*col, err = strconv.Atoi(colField)
+		return err == nil
+	}
+	if !parsePos(start, &block.StartLine, &block.StartCol) || !parsePos(end, &block.EndLine, &block.EndCol) {
+		return CoverageBlock{}, false
+	}
+	if block.Statements, err = strconv.Atoi(fields[1]); err != nil {
+		return CoverageBlock{}, false
+	}
+	if block.Count, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+		return CoverageBlock{}, false
+	}
+	return block, true
+}
+
+// writeCoverProfile merges the coverage of every package in result into one
+// Go coverprofile at path. A block covered by the tests of several packages
+// keeps the highest count in set mode and sums the counts otherwise.
+func writeCoverProfile(path string, mode CoverMode, result *Result) error {
+	type blockKey struct {
+		file                                 string
+		startLine, startCol, endLine, endCol int
+	}
+	merged := make(map[blockKey]CoverageBlock)
+	for _, pkg := range result.Packages {
+		if pkg.Coverage == nil {
+			continue
+		}
+		for _, block := range pkg.Coverage.Blocks {
+			if mode == CoverModeSet {
+				block.Count = min(block.Count, 1)
+			}
+			key := blockKey{block.File, block.StartLine, block.StartCol, block.EndLine, block.EndCol}
+			if prev, ok := merged[key]; ok {
+				if mode == CoverModeSet {
+					block.Count = max(block.Count, prev.Count)
+				} else {
+					block.Count += prev.Count
+				}
+			}
+			merged[key] = block
+		}
+	}
+	blocks := make([]CoverageBlock, 0, len(merged))
+	for _, block := range merged {
+		blocks = append(blocks, block)
+	}
+	slices.SortFunc(blocks, func(a, b CoverageBlock) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.StartLine, b.StartLine),
+			cmp.Compare(a.StartCol, b.StartCol),
+			cmp.Compare(a.EndLine, b.EndLine),
+			cmp.Compare(a.EndCol, b.EndCol),
+		)
+	})
+
+	var b strings.Builder
+	b.WriteString("mode: ")
+	b.WriteString(string(mode))
+	b.WriteString("\n")
+	for _, block := range blocks {
+		b.WriteString(block.File)
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(block.StartLine))
+		b.WriteString(".")
+		b.WriteString(strconv.Itoa(block.StartCol))
+		b.WriteString(",")
+		b.WriteString(strconv.Itoa(block.EndLine))
+		b.WriteString(".")
+		b.WriteString(strconv.Itoa(block.EndCol))
+		b.WriteString(" ")
+		b.WriteString(strconv.Itoa(block.Statements))
+		b.WriteString(" ")
+		b.WriteString(strconv.FormatInt(block.Count, 10))
+		b.WriteString("\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "create coverage profile directory")
+	}
+	return errors.Wrap(os.WriteFile(path, []byte(b.String()), 0o644), "write coverage profile")
+}
@@ -0,0 +1,175 @@
+package gotest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4wave/goscript/compiler/tsworkspace"
+)
+
+func TestNormalizeCoverFlags(t *testing.T) {
+	norm, err := (&Request{Patterns: []string{"."}, CoverProfile: "cover.out"}).normalize()
+	if err != nil {
+		t.Fatalf("normalize cover profile: %v", err)
+	}
+	if !norm.Cover || norm.CoverMode != CoverModeSet || !filepath.IsAbs(norm.CoverProfile) {
+		t.Fatalf("expected cover profile to enable set-mode coverage: %#v", norm)
+	}
+	if got := norm.coverPackages("example.test/pkg"); len(got) != 1 || got[0] != "example.test/pkg" {
+		t.Fatalf("expected coverage of the tested package, got %v", got)
+	}
+
+	norm, err = (&Request{Patterns: []string{"."}, CoverPackages: []string{"./..."}}).normalize()
+	if err != nil {
+		t.Fatalf("normalize coverpkg: %v", err)
+	}
+	norm.CoverPackagePaths = []string{"example.test/a", "example.test/b"}
+	if got := norm.coverPackages("example.test/pkg"); strings.Join(got, ",") != "example.test/a,example.test/b" {
+		t.Fatalf("expected -coverpkg packages, got %v", got)
+	}
+
+	if _, err := (&Request{Patterns: []string{"."}, CoverMode: "sample"}).normalize(); err == nil ||
+		!strings.Contains(err.Error(), `unsupported cover mode "sample"`) {
+		t.Fatalf("expected unsupported cover mode error, got %v", err)
+	}
+}
+
+func TestRenderRunnersReportCoverage(t *testing.T) {
+	req := &normalizedRequest{Cover: true}
+	pkg := PackageResult{
+		PackagePath: "example.test/pkg",
+		Tests: []Test{{
+			Name:        "TestCover",
+			PackagePath: "example.test/pkg",
+		}},
+	}
+	want := `"__GOSCRIPT_COVERAGE__" + encodeURIComponent("example.test/pkg") + "\t" + encodeURIComponent(coverProfile(["example.test/pkg"])))`
+	runner := renderRunner(pkg, req)
+	if !strings.Contains(runner, "import { coverProfile } from \"@goscript/builtin/index.js\"\n") ||
+		!strings.Contains(runner, "console.log("+want) {
+		t.Fatalf("expected runner to print the package coverage: %s", runner)
+	}
+	browser := renderBrowserRunner(pkg, req)
+	if !strings.Contains(browser, "__goscriptOriginalLog("+want) {
+		t.Fatalf("expected browser runner to print the package coverage: %s", browser)
+	}
+	if strings.Contains(renderRunner(pkg, &normalizedRequest{}), "coverProfile") {
+		t.Fatal("expected no coverage without -cover")
+	}
+}
+
+func TestParseCoverageRecordsStripsRecords(t *testing.T) {
+	output := strings.Join([]string{
+		"=== RUN   TestSign",
+		"__GOSCRIPT_COVERAGE__example.test%2Fcover\texample.test%2Fcover%2Fcover.go%3A4.2%2C4.11%201%203%0Aexample.test%2Fcover%2Fcover.go%3A5.3%2C6.1%201%200",
+		"--- PASS: TestSign (0.00s)",
+	}, "\n")
+
+	rest, coverage, err := parseCoverageRecords(output, "example.test/cover")
+	if err != nil {
+		t.Fatalf("parse coverage records: %v", err)
+	}
+	if rest != "=== RUN   TestSign\n--- PASS: TestSign (0.00s)" {
+		t.Fatalf("expected coverage record to be stripped, got %q", rest)
+	}
+	want := []CoverageBlock{
+		{File: "example.test/cover/cover.go", StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 11, Statements: 1, Count: 3},
+		{File: "example.test/cover/cover.go", StartLine: 5, StartCol: 3, EndLine: 6, EndCol: 1, Statements: 1},
+	}
+	if coverage == nil || len(coverage.Blocks) != len(want) || coverage.Blocks[0] != want[0] || coverage.Blocks[1] != want[1] {
+		t.Fatalf("unexpected coverage: %#v", coverage)
+	}
+	if total, covered := coverage.Statements(); total != 2 || covered != 1 {
+		t.Fatalf("statements = %d/%d, want 1/2", covered, total)
+	}
+}
+
+func TestWriteCoverProfileMergesPackages(t *testing.T) {
+	block := CoverageBlock{File: "example.test/a/a.go", StartLine: 3, StartCol: 2, EndLine: 3, EndCol: 9, Statements: 1}
+	early := CoverageBlock{File: "example.test/a/a.go", StartLine: 1, StartCol: 14, EndLine: 2, EndCol: 2, Statements: 2, Count: 4}
+	hit := block
+	hit.Count = 2
+	result := &Result{Packages: []PackageResult{
+		{PackagePath: "example.test/a", Coverage: &Coverage{Blocks: []CoverageBlock{block, early}}},
+		{PackagePath: "example.test/b", Coverage: &Coverage{Blocks: []CoverageBlock{hit}}},
+		{PackagePath: "example.test/c"},
+	}}
+
+	for mode, want := range map[CoverMode]string{
+		CoverModeSet:   "mode: set\nexample.test/a/a.go:1.14,2.2 2 1\nexample.test/a/a.go:3.2,3.9 1 1\n",
+		CoverModeCount: "mode: count\nexample.test/a/a.go:1.14,2.2 2 4\nexample.test/a/a.go:3.2,3.9 1 2\n",
+	} {
+		path := filepath.Join(t.TempDir(), "cover.out")
+		if err := writeCoverProfile(path, mode, result); err != nil {
+			t.Fatalf("write %s profile: %v", mode, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if string(data) != want {
+			t.Fatalf("unexpected %s profile:\n%s", mode, data)
+		}
+	}
+}
+
+func TestRunnerWritesCoverProfile(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod": "module example.test/cover\n\ngo 1.25.3\n",
+		"cover.go": strings.Join([]string{
+			"package cover",
+			"",
+			"func Sign(x int) int {",
+			"\tif x > 0 {",
+			"\t\treturn 1",
+			"\t}",
+			"\treturn 0",
+			"}",
+			"",
+		}, "\n"),
+		"cover_test.go": strings.Join([]string{
+			"package cover",
+			"",
+			"import \"testing\"",
+			"",
+			"func TestSign(t *testing.T) {",
+			"\tif Sign(1) != 1 {",
+			"\t\tt.Fatal(\"unexpected sign\")",
+			"\t}",
+			"}",
+			"",
+		}, "\n"),
+	})
+	if _, err := tsworkspace.NewOwner(moduleDir, moduleDir).FindTool("bun"); err != nil {
+		t.Skip(err.Error())
+	}
+	profile := filepath.Join(t.TempDir(), "cover.out")
+
+	result, err := NewRunner().Run(context.Background(), &Request{
+		Dir:          moduleDir,
+		Patterns:     []string{"."},
+		Timeout:      30 * time.Second,
+		CoverProfile: profile,
+	})
+	if err != nil {
+		t.Fatalf("run package test: %v", err)
+	}
+	if !result.Passed() || len(result.Packages) != 1 {
+		t.Fatalf("expected package test to pass: %#v", result.Packages)
+	}
+	if total, covered := result.Packages[0].Coverage.Statements(); total != 3 || covered != 2 {
+		t.Fatalf("statements = %d/%d, want 2/3", covered, total)
+	}
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := "mode: set\nexample.test/cover/cover.go:4.2,4.11 1 1\nexample.test/cover/cover.go:5.3,6.1 1 1\nexample.test/cover/cover.go:7.2,7.10 1 0\n"
+	if string(data) != want {
+		t.Fatalf("unexpected coverage profile:\n%s", data)
+	}
+}
@@ -54,6 +54,17 @@ type PackageResult struct {
 	TestImports []string
 	// Tests are the selected tests for this package.
 	Tests []Test
+	// Benchmarks are the benchmarks selected by -bench for this package.
+	Benchmarks []Test
+	// FuzzTargets are the fuzz targets selected by -run, whose seed corpora
+	// run after the tests, and by -fuzz.
+	FuzzTargets []Test
+	// Examples are the examples selected by -run, which run after the tests
+	// and seed corpora.
+	Examples []Example
+	// TestMain is the package's TestMain function, which runs the selected
+	// tests through m.Run, or nil.
+	TestMain *Test
 	// Action is the package result.
 	Action Action
 	// Phases records structured status for each runner phase.
@@ -66,4 +77,7 @@ type PackageResult struct {
 	Output string
 	// Elapsed is the package runtime.
 	Elapsed time.Duration
+	// Coverage is the statement coverage the package tests measured, or nil
+	// when coverage was off or the tests did not report it.
+	Coverage *Coverage
 }
@@ -3,11 +3,12 @@ package gotest
 import (
 	"path/filepath"
 	"runtime"
-	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/s4wave/goscript/compiler"
 )
 
 // Request describes one GoScript package-test run.
@@ -44,6 +45,41 @@ type Request struct {
 	RuntimeGroups bool
 	// IncrementalTypeCheck reuses TypeScript build-info files inside WorkDir.
 	IncrementalTypeCheck bool
+	// SourceMaps emits source maps that point generated TypeScript back to Go.
+	SourceMaps bool
+	// PreemptLoops adds cooperative yield checks to loops in async functions.
+	PreemptLoops bool
+	// Cover measures the statement coverage of the tested packages.
+	Cover bool
+	// CoverMode selects how coverage counts block executions. It defaults to
+	// CoverModeSet.
+	CoverMode CoverMode
+	// CoverProfile writes the merged Go coverage profile of the run to this
+	// path. It implies Cover.
+	CoverProfile string
+	// CoverPackages are package patterns to measure coverage of in every
+	// tested package instead of the package itself. They imply Cover.
+	CoverPackages []string
+	// Bench is the optional Go benchmark name regexp. Benchmarks only run
+	// when it is set.
+	Bench string
+	// BenchTime is the -benchtime target, a duration such as "1s" or an
+	// iteration count such as "100x". It defaults to one second.
+	BenchTime string
+	// BenchMem prints memory statistics for every benchmark.
+	BenchMem bool
+	// Fuzz is the optional Go fuzz target regexp. The one fuzz target it
+	// matches is fuzzed after the tests, benchmarks, and seed corpora pass.
+	// It requires a single package.
+	Fuzz string
+	// FuzzTime is the -fuzztime budget, a duration such as "30s" or an input
+	// count such as "1000x". Fuzzing runs until a failure or Timeout when it
+	// is empty.
+	FuzzTime string
+	// RuntimeOutput receives each line a package's Bun runtime writes, as it
+	// is written. Packages run concurrently, so it must be safe to call from
+	// several goroutines. PackageResult.Output still holds the whole output.
+	RuntimeOutput func(packagePath string, line string)
 }
 
 type normalizedRequest struct {
@@ -63,6 +99,26 @@ type normalizedRequest struct {
 	RuntimeBackend       RuntimeBackend
 	RuntimeGroups        bool
 	IncrementalTypeCheck bool
+	SourceMaps           bool
+	PreemptLoops         bool
+	Cover                bool
+	CoverMode            CoverMode
+	CoverProfile         string
+	CoverPatterns        []string
+	Bench                string
+	BenchTime            time.Duration
+	BenchIters           int
+	BenchMem             bool
+	Fuzz                 string
+	FuzzTime             time.Duration
+	FuzzIters            int
+	RuntimeOutput        func(packagePath string, line string)
+
+	// CoverPackagePaths are the packages the CoverPatterns resolved to.
+	CoverPackagePaths []string
+	// Deadline is when generated runners stop a hung test and print a
+	// goroutine dump, shortly before Timeout kills the runtime process.
+	Deadline time.Time
 }
 
 // RuntimeBackend selects the JavaScript host used for package runtime tests.
@@ -114,12 +170,8 @@ func (r *Request) normalize() (*normalizedRequest, error) {
 		return nil, errors.New("test count must be positive")
 	}
 
-	buildTags := normalizeBuildTags(r.BuildTags)
-	var buildFlags []string
-	if len(buildTags) != 0 {
-		buildFlags = append(buildFlags, "-tags="+strings.Join(buildTags, ","))
-	}
-	overrideDirs, err := normalizeOverrideDirs(r.OverrideDirs)
+	buildFlags := compiler.BuildTagsFlags(r.BuildTags)
+	overrideDirs, err := compiler.NormalizeOverrideDirs(r.OverrideDirs)
 	if err != nil {
 		return nil, err
 	}
@@ -160,6 +212,32 @@ func (r *Request) normalize() (*normalizedRequest, error) {
 		return nil, errors.Errorf("unsupported runtime backend %q", runtimeBackend)
 	}
 
+	coverMode := r.CoverMode
+	if coverMode == "" {
+		coverMode = CoverModeSet
+	}
+	switch coverMode {
+	case CoverModeSet, CoverModeCount, CoverModeAtomic:
+	default:
+		return nil, errors.Errorf("unsupported cover mode %q", coverMode)
+	}
+	coverProfile := strings.TrimSpace(r.CoverProfile)
+	if coverProfile != "" {
+		coverProfile, err = filepath.Abs(coverProfile)
+		if err != nil {
+			return nil, errors.Wrap(err, "resolve coverage profile path")
+		}
+	}
+	coverPatterns := normalizePatterns(r.CoverPackages)
+	benchTime, benchIters, err := parseTimeOrCount("benchtime", r.BenchTime, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	fuzzTime, fuzzIters, err := parseTimeOrCount("fuzztime", r.FuzzTime, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	return &normalizedRequest{
 		Dir:                  absDir,
 		Patterns:             patterns,
@@ -177,31 +255,54 @@ func (r *Request) normalize() (*normalizedRequest, error) {
 		RuntimeBackend:       runtimeBackend,
 		RuntimeGroups:        r.RuntimeGroups,
 		IncrementalTypeCheck: r.IncrementalTypeCheck,
+		SourceMaps:           r.SourceMaps,
+		PreemptLoops:         r.PreemptLoops,
+		Cover:                r.Cover || coverProfile != "" || len(coverPatterns) != 0,
+		CoverMode:            coverMode,
+		CoverProfile:         coverProfile,
+		CoverPatterns:        coverPatterns,
+		Bench:                strings.TrimSpace(r.Bench),
+		BenchTime:            benchTime,
+		BenchIters:           benchIters,
+		BenchMem:             r.BenchMem,
+		Fuzz:                 strings.TrimSpace(r.Fuzz),
+		FuzzTime:             fuzzTime,
+		FuzzIters:            fuzzIters,
+		RuntimeOutput:        r.RuntimeOutput,
 	}, nil
 }
 
-func normalizeOverrideDirs(dirs []string) ([]string, error) {
-	if len(dirs) == 0 {
-		return nil, nil
+// coverPackages returns the packages to instrument when compiling
+// packagePath or its tests.
+func (r *normalizedRequest) coverPackages(packagePath string) []string {
+	if !r.Cover {
+		return nil
 	}
-	var normalized []string
-	seen := make(map[string]bool)
-	for _, dir := range dirs {
-		dir = strings.TrimSpace(dir)
-		if dir == "" {
-			continue
-		}
-		abs, err := filepath.Abs(dir)
-		if err != nil {
-			return nil, errors.Wrap(err, "resolve override directory")
-		}
-		if seen[abs] {
-			continue
+	if len(r.CoverPatterns) != 0 {
+		return append([]string(nil), r.CoverPackagePaths...)
+	}
+	return []string{packagePath}
+}
+
+// parseTimeOrCount parses a -benchtime or -fuzztime value into a duration
+// or, for the Nx form, an iteration count. An empty value is def.
+func parseTimeOrCount(flag string, value string, def time.Duration) (time.Duration, int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return def, 0, nil
+	}
+	if count, ok := strings.CutSuffix(value, "x"); ok {
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return 0, 0, errors.Errorf("invalid %s %q", flag, value)
 		}
-		seen[abs] = true
-		normalized = append(normalized, abs)
+		return 0, n, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, 0, errors.Errorf("invalid %s %q", flag, value)
 	}
-	return normalized, nil
+	return d, 0, nil
 }
 
 func normalizePatterns(patterns []string) []string {
@@ -217,22 +318,3 @@ func normalizePatterns(patterns []string) []string {
 	}
 	return normalized
 }
-
-func normalizeBuildTags(tags []string) []string {
-	seen := make(map[string]bool)
-	var normalized []string
-	for _, value := range tags {
-		for _, tag := range strings.FieldsFunc(value, func(r rune) bool {
-			return r == ',' || r == ' ' || r == '\t' || r == '\n'
-		}) {
-			tag = strings.TrimSpace(tag)
-			if tag == "" || seen[tag] {
-				continue
-			}
-			seen[tag] = true
-			normalized = append(normalized, tag)
-		}
-	}
-	slices.Sort(normalized)
-	return normalized
-}
@@ -3,7 +3,6 @@ package gotest
 import (
 	"context"
 	"net/url"
-	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
@@ -47,18 +46,15 @@ func (r *Runner) Run(ctx context.Context, req *Request) (*Result, error) {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, norm.Timeout)
 		defer cancel()
+		norm.Deadline = time.Now().Add(norm.Timeout - min(norm.Timeout/10, runnerTimeoutGrace))
 	}
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 	if norm.WorkDir == "" {
-		workRoot := filepath.Join(norm.Dir, ".tmp")
-		if err := os.MkdirAll(workRoot, 0o755); err != nil {
-			return nil, errors.Wrap(err, "create goscript test work root")
-		}
-		norm.WorkDir, err = os.MkdirTemp(workRoot, "goscript-test-")
+		norm.WorkDir, err = tsworkspace.NewTempWorkDir(norm.Dir, "goscript-test-")
 		if err != nil {
-			return nil, errors.Wrap(err, "create goscript test work directory")
+			return nil, err
 		}
 	}
 	if norm.OutputRoot == "" {
@@ -78,6 +74,33 @@ func (r *Runner) Run(ctx context.Context, req *Request) (*Result, error) {
 			Code:     "goscript/gotest:run-pattern",
 			Message:  "invalid -run pattern",
 			Detail:   err.Error(),
+			Stage:    compiler.DiagnosticStageRequest,
+		}
+		result.Diagnostics = append(result.Diagnostics, diag)
+		markAllFailures(result, OwnerPackageGraph, compiler.NewCompileError([]compiler.Diagnostic{diag}).Error())
+		return result, nil
+	}
+	benchPattern, err := compileBenchPattern(norm.Bench)
+	if err != nil {
+		diag := compiler.Diagnostic{
+			Severity: compiler.DiagnosticSeverityError,
+			Code:     "goscript/gotest:bench-pattern",
+			Message:  "invalid -bench pattern",
+			Detail:   err.Error(),
+			Stage:    compiler.DiagnosticStageRequest,
+		}
+		result.Diagnostics = append(result.Diagnostics, diag)
+		markAllFailures(result, OwnerPackageGraph, compiler.NewCompileError([]compiler.Diagnostic{diag}).Error())
+		return result, nil
+	}
+	fuzzPattern, err := compileRunPattern(norm.Fuzz)
+	if err != nil {
+		diag := compiler.Diagnostic{
+			Severity: compiler.DiagnosticSeverityError,
+			Code:     "goscript/gotest:fuzz-pattern",
+			Message:  "invalid -fuzz pattern",
+			Detail:   err.Error(),
+			Stage:    compiler.DiagnosticStageRequest,
 		}
 		result.Diagnostics = append(result.Diagnostics, diag)
 		markAllFailures(result, OwnerPackageGraph, compiler.NewCompileError([]compiler.Diagnostic{diag}).Error())
@@ -96,11 +119,39 @@ func (r *Runner) Run(ctx context.Context, req *Request) (*Result, error) {
 	}
 	testGraph, loadDiagnostics := r.service.PackageGraphOwner().LoadTestGraph(ctx, testGraphReq)
 	result.Diagnostics = append(result.Diagnostics, loadDiagnostics...)
-	result.Packages = packageResults(testGraph, runPattern)
+	result.Packages = packageResults(testGraph, runPattern, benchPattern, fuzzPattern)
 	if diagnosticsHaveErrors(loadDiagnostics) && len(result.Packages) == 0 {
 		markAllFailures(result, OwnerPackageGraph, diagnosticsSummary(loadDiagnostics))
 		return result, nil
 	}
+	if fuzzPattern != nil && len(result.Packages) > 1 {
+		diag := compiler.Diagnostic{
+			Severity: compiler.DiagnosticSeverityError,
+			Code:     "goscript/gotest:fuzz-packages",
+			Message:  "cannot use -fuzz flag with multiple packages",
+			Stage:    compiler.DiagnosticStageRequest,
+		}
+		result.Diagnostics = append(result.Diagnostics, diag)
+		markAllFailures(result, OwnerPackageGraph, compiler.NewCompileError([]compiler.Diagnostic{diag}).Error())
+		return result, nil
+	}
+	if len(norm.CoverPatterns) != 0 {
+		coverGraph, coverDiagnostics := r.service.PackageGraphOwner().Load(ctx, &compiler.CompileRequest{
+			Patterns:            append([]string(nil), norm.CoverPatterns...),
+			Dir:                 norm.Dir,
+			OutputPath:          norm.OutputRoot,
+			BuildFlags:          append([]string(nil), norm.BuildFlags...),
+			OverrideDirs:        append([]string(nil), norm.OverrideDirs...),
+			DependencyMode:      compiler.DependencyModeRequested,
+			RuntimeEmissionMode: compiler.RuntimeEmissionModeEmit,
+		})
+		result.Diagnostics = append(result.Diagnostics, coverDiagnostics...)
+		if diagnosticsHaveErrors(coverDiagnostics) || coverGraph == nil {
+			markAllFailures(result, OwnerPackageGraph, "resolve -coverpkg: "+diagnosticsSummary(coverDiagnostics))
+			return result, nil
+		}
+		norm.CoverPackagePaths = coverGraph.RequestedPackagePaths
+	}
 
 	outputRoots := r.compilePackageOutputs(ctx, norm, result)
 
@@ -126,6 +177,11 @@ func (r *Runner) Run(ctx context.Context, req *Request) (*Result, error) {
 		return result, nil
 	}
 	r.runPackageTools(ctx, norm, workspace, result, outputRoots, nodeTypesAvailable)
+	if norm.CoverProfile != "" {
+		if err := writeCoverProfile(norm.CoverProfile, norm.CoverMode, result); err != nil {
+			return result, err
+		}
+	}
 	return result, nil
 }
 
@@ -146,7 +202,7 @@ func (r *Runner) runPackageTools(
 		return
 	}
 	if len(indexes) == 1 {
-		r.runPackageTypeCheckAndRuntime(ctx, req, workspace, result, outputRoots, indexes[0])
+		r.runPackageTypeCheckAndRuntime(ctx, req, workspace, result, outputRoots, make(chan struct{}, 1), indexes[0])
 		return
 	}
 	typecheck := workspace.RunTool(ctx, tsworkspace.PhaseTypeCheck, req.WorkDir, "tsgo", "--project", "tsconfig.json")
@@ -218,8 +274,8 @@ func (r *Runner) runPackageTypeChecksAndRuntimes(
 	outputRoots []string,
 	indexes []int,
 ) {
-	parallelism := max(req.Parallelism, 1)
-	sem := make(chan struct{}, parallelism)
+	sem := make(chan struct{}, max(req.Parallelism, 1))
+	runtimeSem := make(chan struct{}, runtimeParallelism(req))
 	var wg sync.WaitGroup
 	for _, idx := range packageExecutionIndexes(result, indexes) {
 		wg.Go(func() {
@@ -232,23 +288,35 @@ func (r *Runner) runPackageTypeChecksAndRuntimes(
 				result.Packages[idx].Error = ctx.Err().Error()
 				return
 			}
-			r.runPackageTypeCheckAndRuntime(ctx, req, workspace, result, outputRoots, idx)
+			r.runPackageTypeCheckAndRuntime(ctx, req, workspace, result, outputRoots, runtimeSem, idx)
 		})
 	}
 	wg.Wait()
 }
 
+// runPackageTypeCheckAndRuntime type checks a package and runs it once
+// runtimeSem admits it, so only runtimes are held to runtimeParallelism.
 func (r *Runner) runPackageTypeCheckAndRuntime(
 	ctx context.Context,
 	req *normalizedRequest,
 	workspace *tsworkspace.Owner,
 	result *Result,
 	outputRoots []string,
+	runtimeSem chan struct{},
 	idx int,
 ) {
 	if !r.runPackageTypeCheck(ctx, req, workspace, result, idx) {
 		return
 	}
+	select {
+	case runtimeSem <- struct{}{}:
+		defer func() { <-runtimeSem }()
+	case <-ctx.Done():
+		result.Packages[idx].Owner = OwnerTestRunner
+		result.Packages[idx].Phases.Runtime = PhaseStatusFail
+		result.Packages[idx].Error = ctx.Err().Error()
+		return
+	}
 	r.runPackageRuntime(ctx, req, workspace, result, outputRootAt(outputRoots, idx), idx)
 }
 
@@ -282,7 +350,12 @@ func (r *Runner) runPackageRuntimes(
 		r.runPackageRuntimesIndividually(ctx, req, workspace, result, outputRoots, indexes)
 		return
 	}
+	// Coverage counts package initialization, which a shared process would
+	// only run once for all of its packages. A TestMain may end the process
+	// with os.Exit, so its package needs a process of its own.
 	if len(indexes) > 1 &&
+		!req.Cover &&
+		!slices.ContainsFunc(indexes, func(idx int) bool { return result.Packages[idx].TestMain != nil }) &&
 		(req.RuntimeGroups || req.Parallelism == 1) &&
 		r.runCombinedPackageRuntimes(ctx, req, workspace, result, indexes) {
 		return
@@ -294,82 +367,7 @@ func materializeRuntimeModuleShims(req *normalizedRequest, outputRoots []string)
 	if req.RuntimeBackend != RuntimeBackendBun {
 		return tsworkspace.Result{Phase: tsworkspace.PhaseWorkspace}
 	}
-	seen := make(map[string]bool)
-	shimRoots := runtimeModuleShimRoots(req, outputRoots)
-	for _, outputRoot := range outputRoots {
-		if outputRoot == "" {
-			continue
-		}
-		root := filepath.Join(outputRoot, "@goscript")
-		if _, err := os.Stat(root); err != nil {
-			if os.IsNotExist(err) {
-				continue
-			}
-			return tsworkspace.Result{Phase: tsworkspace.PhaseWorkspace, Error: errors.Wrap(err, "stat GoScript runtime output root").Error()}
-		}
-		err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			if entry.IsDir() || filepath.Ext(path) != ".ts" {
-				return nil
-			}
-			rel, err := filepath.Rel(root, path)
-			if err != nil {
-				return err
-			}
-			shimRel := filepath.Join("node_modules", "@goscript", strings.TrimSuffix(rel, ".ts")+".js")
-			for _, shimRoot := range shimRoots {
-				shimPath := filepath.Join(shimRoot, shimRel)
-				seenKey := shimPath
-				if seen[seenKey] {
-					continue
-				}
-				seen[seenKey] = true
-				shimDir := filepath.Dir(shimPath)
-				importPath, err := filepath.Rel(shimDir, path)
-				if err != nil {
-					return err
-				}
-				importPath = filepath.ToSlash(importPath)
-				if !strings.HasPrefix(importPath, ".") {
-					importPath = "./" + importPath
-				}
-				if err := writeRuntimeModuleShim(shimPath, "export * from "+strconv.Quote(importPath)+"\n"); err != nil {
-					return err
-				}
-			}
-			return nil
-		})
-		if err != nil {
-			return tsworkspace.Result{Phase: tsworkspace.PhaseWorkspace, Error: errors.Wrap(err, "materialize GoScript runtime module shims").Error()}
-		}
-	}
-	return tsworkspace.Result{Phase: tsworkspace.PhaseWorkspace}
-}
-
-func runtimeModuleShimRoots(req *normalizedRequest, outputRoots []string) []string {
-	seen := make(map[string]bool)
-	var roots []string
-	for _, root := range append([]string{req.WorkDir}, outputRoots...) {
-		if root == "" {
-			continue
-		}
-		clean := filepath.Clean(root)
-		if seen[clean] {
-			continue
-		}
-		seen[clean] = true
-		roots = append(roots, clean)
-	}
-	return roots
-}
-
-func writeRuntimeModuleShim(path string, data string) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
-	}
-	return os.WriteFile(path, []byte(data), 0o644)
+	return tsworkspace.NewOwner(req.WorkDir, req.Dir).WriteModuleShims(outputRoots)
 }
 
 func markRuntimeFailures(result *Result, indexes []int, owner Owner, message string) {
@@ -392,8 +390,7 @@ func (r *Runner) runPackageRuntimesIndividually(
 	outputRoots []string,
 	indexes []int,
 ) {
-	parallelism := max(req.Parallelism, 1)
-	sem := make(chan struct{}, parallelism)
+	sem := make(chan struct{}, runtimeParallelism(req))
 	var wg sync.WaitGroup
 	for _, idx := range packageExecutionIndexes(result, indexes) {
 		wg.Go(func() {
@@ -420,7 +417,7 @@ func (r *Runner) runCombinedPackageRuntimes(
 	indexes []int,
 ) bool {
 	ordered := packageExecutionIndexes(result, indexes)
-	chunks := packageRuntimeChunks(ordered, max(req.Parallelism, 1))
+	chunks := packageRuntimeChunks(ordered, runtimeParallelism(req))
 	if len(chunks) == 0 {
 		return true
 	}
@@ -446,6 +443,16 @@ func (r *Runner) runCombinedPackageRuntimes(
 	return ok
 }
 
+// runtimeParallelism limits how many package runtimes run at once. Like go
+// test, benchmarks and fuzzing run one package at a time so they do not skew
+// each other.
+func runtimeParallelism(req *normalizedRequest) int {
+	if req.Bench != "" || req.Fuzz != "" {
+		return 1
+	}
+	return max(req.Parallelism, 1)
+}
+
 func packageRuntimeChunks(indexes []int, parallelism int) [][]int {
 	if len(indexes) == 0 {
 		return nil
@@ -488,6 +495,15 @@ func (r *Runner) runCombinedPackageRuntime(
 	}
 	for _, idx := range indexes {
 		record, ok := byPath[result.Packages[idx].PackagePath]
+		if !ok && !req.Deadline.IsZero() && time.Now().After(req.Deadline) {
+			// An earlier package timed out and the runner exited; rerunning
+			// the rest individually would only hit the same deadline.
+			result.Packages[idx].Action = ActionFail
+			result.Packages[idx].Owner = OwnerTestRunner
+			result.Packages[idx].Phases.Runtime = PhaseStatusFail
+			result.Packages[idx].Error = "package tests did not run before the " + req.Timeout.String() + " timeout"
+			continue
+		}
 		if !ok {
 			return false
 		}
@@ -521,7 +537,18 @@ func (r *Runner) runPackageRuntime(
 		r.runPackageBrowserRuntime(ctx, req, workspace, result, outputRoot, idx)
 		return
 	}
-	runtime := workspace.RunTool(ctx, tsworkspace.PhaseRuntime, req.WorkDir, "bun", packageRunnerFile(idx))
+	var runtime tsworkspace.Result
+	if req.RuntimeOutput != nil {
+		packagePath := result.Packages[idx].PackagePath
+		runtime = workspace.RunToolLines(ctx, tsworkspace.PhaseRuntime, req.WorkDir, func(line string) {
+			if !strings.HasPrefix(line, coverageRecordPrefix) {
+				req.RuntimeOutput(packagePath, line)
+			}
+		}, "bun", packageRunnerFile(idx))
+	} else {
+		runtime = workspace.RunTool(ctx, tsworkspace.PhaseRuntime, req.WorkDir, "bun", packageRunnerFile(idx))
+	}
+	r.takePackageCoverage(req, &runtime, &result.Packages[idx])
 	result.Packages[idx].Elapsed = runtime.Elapsed
 	result.Packages[idx].Output = strings.TrimSpace(runtime.Output)
 	if runtime.Failed() {
@@ -535,6 +562,21 @@ func (r *Runner) runPackageRuntime(
 	result.Packages[idx].Action = ActionPass
 }
 
+// takePackageCoverage moves the coverage record of pkg out of the runtime
+// output into the package result.
+func (r *Runner) takePackageCoverage(req *normalizedRequest, runtime *tsworkspace.Result, pkg *PackageResult) {
+	if !req.Cover {
+		return
+	}
+	output, coverage, err := parseCoverageRecords(runtime.Output, pkg.PackagePath)
+	if err != nil {
+		runtime.Output += "\n" + err.Error()
+		return
+	}
+	runtime.Output = output
+	pkg.Coverage = coverage
+}
+
 func outputRootAt(outputRoots []string, idx int) string {
 	if idx < 0 || idx >= len(outputRoots) {
 		return ""
@@ -570,6 +612,7 @@ func (r *Runner) runPackageBrowserRuntime(
 		return
 	}
 	runtime := workspace.RunTool(ctx, tsworkspace.PhaseRuntime, req.WorkDir, "vitest", "run", "--config", configFile, "--reporter", "verbose")
+	r.takePackageCoverage(req, &runtime, &result.Packages[idx])
 	result.Packages[idx].Elapsed = runtime.Elapsed
 	result.Packages[idx].Output = strings.TrimSpace(runtime.Output)
 	records, ok := parseCombinedRuntimeRecords(runtime.Output)
@@ -636,6 +679,9 @@ func (r *Runner) compileTestImports(
 			RuntimeEmissionMode: compiler.RuntimeEmissionModeEmit,
 			Tests:               false,
 			AllDependencies:     true,
+			SourceMaps:          req.SourceMaps,
+			PreemptLoops:        req.PreemptLoops,
+			CoverPackages:       req.coverPackages(pkg.PackagePath),
 		}
 		compileResult, compileErr := r.service.Compile(ctx, compileReq)
 		if compileResult != nil {
@@ -678,6 +724,9 @@ func (r *Runner) compilePackageBatch(ctx context.Context, req *normalizedRequest
 		RuntimeEmissionMode: compiler.RuntimeEmissionModeEmit,
 		Tests:               true,
 		AllDependencies:     true,
+		SourceMaps:          req.SourceMaps,
+		PreemptLoops:        req.PreemptLoops,
+		CoverPackages:       batchCoverPackages(req, packagePaths),
 	}
 	testCompileResult, testCompileErr := r.service.Compile(ctx, testCompileReq)
 	if testCompileErr != nil {
@@ -696,6 +745,15 @@ func (r *Runner) compilePackageBatch(ctx context.Context, req *normalizedRequest
 	return true
 }
 
+// batchCoverPackages returns the packages to instrument when the tests of
+// packagePaths compile together.
+func batchCoverPackages(req *normalizedRequest, packagePaths []string) []string {
+	if !req.Cover || len(req.CoverPatterns) != 0 {
+		return req.coverPackages("")
+	}
+	return append([]string(nil), packagePaths...)
+}
+
 func (r *Runner) compilePackageOutputsIndividually(ctx context.Context, req *normalizedRequest, result *Result) []string {
 	outputRoots := make([]string, len(result.Packages))
 	for idx := range result.Packages {
@@ -714,6 +772,9 @@ func (r *Runner) compilePackageOutputsIndividually(ctx context.Context, req *nor
 			RuntimeEmissionMode: compiler.RuntimeEmissionModeEmit,
 			Tests:               false,
 			AllDependencies:     true,
+			SourceMaps:          req.SourceMaps,
+			PreemptLoops:        req.PreemptLoops,
+			CoverPackages:       req.coverPackages(result.Packages[idx].PackagePath),
 		}
 		if compileResult, compileErr := r.service.Compile(ctx, compileReq); compileErr != nil {
 			result.Packages[idx].Action = ActionFail
@@ -741,6 +802,9 @@ func (r *Runner) compilePackageOutputsIndividually(ctx context.Context, req *nor
 			RuntimeEmissionMode: compiler.RuntimeEmissionModeEmit,
 			Tests:               true,
 			AllDependencies:     true,
+			SourceMaps:          req.SourceMaps,
+			PreemptLoops:        req.PreemptLoops,
+			CoverPackages:       req.coverPackages(result.Packages[idx].PackagePath),
 		}
 		if compileResult, compileErr := r.service.Compile(ctx, testCompileReq); compileErr != nil {
 			result.Packages[idx].Action = ActionFail
@@ -759,15 +823,80 @@ func (r *Runner) compilePackageOutputsIndividually(ctx context.Context, req *nor
 	return outputRoots
 }
 
+// compileRunPattern compiles the first slash-separated element of a -run or
+// -fuzz pattern, which selects top-level tests. It returns nil when pattern
+// is empty. The generated runner matches the second element of -run against
+// fuzz corpus entry names.
 func compileRunPattern(pattern string) (*regexp.Regexp, error) {
 	pattern = strings.TrimSpace(pattern)
 	if pattern == "" {
 		return nil, nil
 	}
-	return regexp.Compile(pattern)
+	elems := splitTestPattern(pattern)
+	for idx, elem := range elems {
+		if _, err := regexp.Compile(elem); err != nil {
+			if len(elems) == 1 {
+				return nil, err
+			}
+			return nil, errors.Wrapf(err, "element %d of %q", idx, pattern)
+		}
+	}
+	return regexp.MustCompile(elems[0]), nil
+}
+
+// compileBenchPattern compiles the first slash-separated element of a -bench
+// pattern, which selects the top-level benchmarks. It returns nil when
+// benchmarks are off. The generated runner matches the other elements
+// against sub-benchmark names.
+func compileBenchPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	elems := splitTestPattern(pattern)
+	for idx, elem := range elems {
+		if _, err := regexp.Compile(elem); err != nil {
+			return nil, errors.Wrapf(err, "element %d of %q", idx, pattern)
+		}
+	}
+	return regexp.MustCompile(elems[0]), nil
+}
+
+// splitTestPattern splits a -run or -bench pattern at the slashes outside of
+// brackets and parentheses, like the testing package.
+func splitTestPattern(pattern string) []string {
+	var elems []string
+	brackets, parens := 0, 0
+	for i := 0; i < len(pattern); {
+		switch pattern[i] {
+		case '[':
+			brackets++
+		case ']':
+			// An unmatched ']' is legal.
+			brackets = max(brackets-1, 0)
+		case '(':
+			if brackets == 0 {
+				parens++
+			}
+		case ')':
+			if brackets == 0 {
+				parens--
+			}
+		case '\\':
+			i++
+		case '/':
+			if brackets == 0 && parens == 0 {
+				elems = append(elems, pattern[:i])
+				pattern = pattern[i+1:]
+				i = 0
+				continue
+			}
+		}
+		i++
+	}
+	return append(elems, pattern)
 }
 
-func packageResults(testGraph *compiler.PackageTestGraph, runPattern *regexp.Regexp) []PackageResult {
+func packageResults(testGraph *compiler.PackageTestGraph, runPattern, benchPattern, fuzzPattern *regexp.Regexp) []PackageResult {
 	if testGraph == nil {
 		return nil
 	}
@@ -777,23 +906,41 @@ func packageResults(testGraph *compiler.PackageTestGraph, runPattern *regexp.Reg
 		result.SourceDir = packageSourceDir(pkg)
 		result.Tests = append(result.Tests, packageVariantTests(pkg.SamePackageTests, runPattern)...)
 		result.Tests = append(result.Tests, packageVariantTests(pkg.ExternalPackageTests, runPattern)...)
+		if benchPattern != nil {
+			result.Benchmarks = append(result.Benchmarks, packageVariantBenchmarks(pkg.SamePackageTests, benchPattern)...)
+			result.Benchmarks = append(result.Benchmarks, packageVariantBenchmarks(pkg.ExternalPackageTests, benchPattern)...)
+		}
+		result.FuzzTargets = append(result.FuzzTargets, packageVariantFuzzTargets(pkg.SamePackageTests, runPattern, fuzzPattern)...)
+		result.FuzzTargets = append(result.FuzzTargets, packageVariantFuzzTargets(pkg.ExternalPackageTests, runPattern, fuzzPattern)...)
+		result.Examples = append(result.Examples, packageVariantExamples(pkg.SamePackageTests, runPattern)...)
+		result.Examples = append(result.Examples, packageVariantExamples(pkg.ExternalPackageTests, runPattern)...)
 		result.TestImports = packageTestImports(pkg)
-		slices.SortFunc(result.Tests, func(a, b Test) int {
-			if a.Name == b.Name {
-				return strings.Compare(a.PackagePath, b.PackagePath)
-			}
-			return strings.Compare(a.Name, b.Name)
-		})
-		if len(result.Tests) != 0 {
-			result.TestPackagePath = result.Tests[0].PackagePath
+		slices.SortFunc(result.Tests, compareTests)
+		slices.SortFunc(result.Benchmarks, compareTests)
+		slices.SortFunc(result.FuzzTargets, compareTests)
+		slices.SortFunc(result.Examples, compareExamples)
+		testMains := slices.Concat(packageVariantTestMain(pkg.SamePackageTests), packageVariantTestMain(pkg.ExternalPackageTests))
+		if len(testMains) != 0 {
+			result.TestMain = &testMains[0]
+		}
+		if runnable := packageRunnerFunctions(result); len(runnable) != 0 {
+			result.TestPackagePath = runnable[0].PackagePath
 			result.Action = ActionFail
 			result.Phases = PackagePhases{}
 		}
-		if diagnosticsHaveErrors(pkg.Diagnostics) {
+		switch {
+		case diagnosticsHaveErrors(pkg.Diagnostics):
 			result.Action = ActionFail
 			result.Owner = OwnerPackageGraph
 			result.Error = diagnosticsSummary(pkg.Diagnostics)
 			result.Phases = failurePhases(OwnerPackageGraph)
+		case len(testMains) > 1:
+			// Both test variants link into one test binary, which has a
+			// single TestMain.
+			result.Action = ActionFail
+			result.Owner = OwnerPackageGraph
+			result.Error = "multiple definitions of TestMain"
+			result.Phases = failurePhases(OwnerPackageGraph)
 		}
 		results = append(results, result)
 	}
@@ -869,9 +1016,77 @@ func packageVariantTests(variant *compiler.PackageTestGraphVariant, runPattern *
 	if variant == nil {
 		return nil
 	}
-	tests := make([]Test, 0, len(variant.Tests))
-	for _, test := range variant.Tests {
-		if runPattern != nil && !runPattern.MatchString(test.Name) {
+	return selectTests(variant.Tests, runPattern)
+}
+
+func packageVariantBenchmarks(variant *compiler.PackageTestGraphVariant, benchPattern *regexp.Regexp) []Test {
+	if variant == nil {
+		return nil
+	}
+	return selectTests(variant.Benchmarks, benchPattern)
+}
+
+// packageVariantFuzzTargets returns the fuzz targets whose seed corpora -run
+// selects, along with the one -fuzz selects for fuzzing.
+func packageVariantFuzzTargets(variant *compiler.PackageTestGraphVariant, runPattern, fuzzPattern *regexp.Regexp) []Test {
+	if variant == nil {
+		return nil
+	}
+	if fuzzPattern == nil {
+		return selectTests(variant.FuzzTargets, runPattern)
+	}
+	targets := make([]compiler.PackageTestFunction, 0, len(variant.FuzzTargets))
+	for _, target := range variant.FuzzTargets {
+		if runPattern == nil || runPattern.MatchString(target.Name) || fuzzPattern.MatchString(target.Name) {
+			targets = append(targets, target)
+		}
+	}
+	return selectTests(targets, nil)
+}
+
+// packageVariantExamples returns the examples -run selects.
+func packageVariantExamples(variant *compiler.PackageTestGraphVariant, runPattern *regexp.Regexp) []Example {
+	if variant == nil {
+		return nil
+	}
+	examples := make([]Example, 0, len(variant.Examples))
+	for _, example := range variant.Examples {
+		if runPattern != nil && !runPattern.MatchString(example.Name) {
+			continue
+		}
+		examples = append(examples, Example{
+			Name:        example.Name,
+			PackagePath: example.PackagePath,
+			Output:      example.Output,
+			Unordered:   example.Unordered,
+		})
+	}
+	return examples
+}
+
+func packageVariantTestMain(variant *compiler.PackageTestGraphVariant) []Test {
+	if variant == nil || variant.TestMain == nil {
+		return nil
+	}
+	return []Test{{Name: variant.TestMain.Name, PackagePath: variant.TestMain.PackagePath}}
+}
+
+// packageRunnerFunctions returns every function the runner of result calls.
+func packageRunnerFunctions(result PackageResult) []Test {
+	functions := slices.Concat(result.Tests, result.Benchmarks, result.FuzzTargets)
+	for _, example := range result.Examples {
+		functions = append(functions, Test{Name: example.Name, PackagePath: example.PackagePath})
+	}
+	if result.TestMain != nil {
+		functions = append(functions, *result.TestMain)
+	}
+	return functions
+}
+
+func selectTests(functions []compiler.PackageTestFunction, pattern *regexp.Regexp) []Test {
+	tests := make([]Test, 0, len(functions))
+	for _, test := range functions {
+		if pattern != nil && !pattern.MatchString(test.Name) {
 			continue
 		}
 		tests = append(tests, Test{
@@ -882,6 +1097,17 @@ func packageVariantTests(variant *compiler.PackageTestGraphVariant, runPattern *
 	return tests
 }
 
+func compareTests(a, b Test) int {
+	if a.Name == b.Name {
+		return strings.Compare(a.PackagePath, b.PackagePath)
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+func compareExamples(a, b Example) int {
+	return compareTests(Test{Name: a.Name, PackagePath: a.PackagePath}, Test{Name: b.Name, PackagePath: b.PackagePath})
+}
+
 func shouldCompilePackage(result PackageResult) bool {
 	return result.Action != ActionSkip && result.Owner == "" && result.Error == ""
 }
@@ -1014,7 +1240,8 @@ func renderPackageRunner(result PackageResult, req *normalizedRequest) string {
 func renderRunner(result PackageResult, req *normalizedRequest) string {
 	var b strings.Builder
 	b.WriteString("import { runTests } from \"@goscript/testing/index.js\"\n")
-	imports := runnerImports(result.Tests)
+	writeCoverageImport(&b, req)
+	imports := runnerImports(packageRunnerFunctions(result))
 	for idx, packagePath := range imports {
 		b.WriteString("import * as pkg")
 		b.WriteString(strconv.Itoa(idx))
@@ -1054,7 +1281,26 @@ func renderRunner(result PackageResult, req *normalizedRequest) string {
 	} else {
 		b.WriteString("false")
 	}
+	writeRunTimeoutOptions(&b, req)
+	alias := func(packagePath string) string {
+		return "pkg" + strconv.Itoa(slices.Index(imports, packagePath))
+	}
+	writeBenchOptions(&b, req, renderCases("", result.Benchmarks, "b", alias))
+	writeFuzzOptions(&b, req, renderCases("", result.FuzzTargets, "f", alias))
+	writeExampleOptions(&b, renderExamples("", result.Examples, alias))
+	writeTestMainOption(&b, result.TestMain, alias)
+	if result.TestMain != nil && req.Cover {
+		// os.Exit(m.Run()) ends the process before runTests returns, so the
+		// profile is written when m.Run finishes.
+		b.WriteString(", after: () => {\n")
+		writeCoverageRecord(&b, "\t", "console.log", result.PackagePath, req)
+		b.WriteString("}")
+	}
 	b.WriteString(" })\n")
+	if result.TestMain == nil {
+		writeCoverageRecord(&b, "", "console.log", result.PackagePath, req)
+	}
+	b.WriteString("if (result.timedOut && typeof process !== \"undefined\" && process.exit) {\n\tprocess.exit(2)\n}\n")
 	b.WriteString("if (!result.ok) {\n\tthrow new Error(\"goscript test failed\")\n}\n")
 	b.WriteString("if (typeof process !== \"undefined\" && process.exit) {\n\tprocess.exit(0)\n}\n")
 	return b.String()
@@ -1064,7 +1310,8 @@ func renderBrowserRunner(result PackageResult, req *normalizedRequest) string {
 	var b strings.Builder
 	b.WriteString("import { test } from \"vitest\"\n")
 	b.WriteString("import { runTests } from \"@goscript/testing/index.js\"\n")
-	imports := runnerImports(result.Tests)
+	writeCoverageImport(&b, req)
+	imports := runnerImports(packageRunnerFunctions(result))
 	for idx, packagePath := range imports {
 		b.WriteString("import * as pkg")
 		b.WriteString(strconv.Itoa(idx))
@@ -1117,6 +1364,20 @@ func renderBrowserRunner(result PackageResult, req *normalizedRequest) string {
 	} else {
 		b.WriteString("false")
 	}
+	alias := func(packagePath string) string {
+		return "pkg" + strconv.Itoa(slices.Index(imports, packagePath))
+	}
+	writeBenchOptions(&b, req, renderCases("\t\t", result.Benchmarks, "b", alias))
+	writeFuzzOptions(&b, req, renderCases("\t\t", result.FuzzTargets, "f", alias))
+	writeExampleOptions(&b, renderExamples("\t\t", result.Examples, alias))
+	writeTestMainOption(&b, result.TestMain, alias)
+	if result.TestMain != nil && req.Cover {
+		// os.Exit(m.Run()) unwinds out of runTests, so the profile is
+		// written when m.Run finishes, as in the Bun runner.
+		b.WriteString(", after: () => {\n")
+		writeCoverageRecord(&b, "\t\t\t", "__goscriptOriginalLog", result.PackagePath, req)
+		b.WriteString("\t\t}")
+	}
 	b.WriteString(" })\n")
 	b.WriteString("\t\t__goscriptOK = result.ok\n")
 	b.WriteString("\t\tif (!result.ok) {\n")
@@ -1148,6 +1409,9 @@ func renderBrowserRunner(result PackageResult, req *normalizedRequest) string {
 	b.WriteString("\t\t}\n")
 	b.WriteString("\t} finally {\n")
 	b.WriteString("\t\tconsole.log = __goscriptOriginalLog\n")
+	if result.TestMain == nil {
+		writeCoverageRecord(&b, "\t\t", "__goscriptOriginalLog", result.PackagePath, req)
+	}
 	b.WriteString("\t\t__goscriptOriginalLog(__goscriptRuntimeRecord(")
 	b.WriteString(strconv.Quote(result.PackagePath))
 	b.WriteString(", __goscriptOK, Date.now() - __goscriptStartedAt, __goscriptLogs.join('\\n')))\n")
@@ -1257,13 +1521,14 @@ func renderCombinedRunner(result *Result, indexes []int, req *normalizedRequest)
 	b.WriteString("\n")
 	b.WriteString("const __goscriptOriginalLog = console.log\n")
 	writeRuntimeRecordFunction(&b, "")
-	b.WriteString("async function __goscriptRunPackage(packagePath, packageDir, tests) {\n")
+	b.WriteString("async function __goscriptRunPackage(packagePath, packageDir, tests, benchmarks, fuzzTargets, examples) {\n")
 	b.WriteString("\tif (packageDir && typeof process !== \"undefined\" && process.chdir) {\n")
 	b.WriteString("\t\tprocess.chdir(packageDir)\n")
 	b.WriteString("\t}\n")
 	b.WriteString("\tconst logs = []\n")
 	b.WriteString("\tconst startedAt = Date.now()\n")
 	b.WriteString("\tlet ok = false\n")
+	b.WriteString("\tlet timedOut = false\n")
 	b.WriteString("\tconsole.log = (...args) => logs.push(args.map((arg) => String(arg)).join(' '))\n")
 	b.WriteString("\ttry {\n")
 	b.WriteString("\t\tconst result = await runTests(packagePath, tests, { verbose: ")
@@ -1280,8 +1545,13 @@ func renderCombinedRunner(result *Result, indexes []int, req *normalizedRequest)
 	} else {
 		b.WriteString("false")
 	}
+	writeRunTimeoutOptions(&b, req)
+	writeBenchOptions(&b, req, "benchmarks")
+	writeFuzzOptions(&b, req, "fuzzTargets")
+	writeExampleOptions(&b, "examples")
 	b.WriteString(" })\n")
 	b.WriteString("\t\tok = result.ok\n")
+	b.WriteString("\t\ttimedOut = result.timedOut === true\n")
 	b.WriteString("\t} catch (err) {\n")
 	b.WriteString("\t\tok = false\n")
 	b.WriteString("\t\tlogs.push(err && err.stack ? String(err.stack) : String(err))\n")
@@ -1289,6 +1559,9 @@ func renderCombinedRunner(result *Result, indexes []int, req *normalizedRequest)
 	b.WriteString("\t\tconsole.log = __goscriptOriginalLog\n")
 	b.WriteString("\t}\n")
 	b.WriteString("\t__goscriptOriginalLog(__goscriptRuntimeRecord(packagePath, ok, Date.now() - startedAt, logs.join('\\n')))\n")
+	b.WriteString("\tif (timedOut && typeof process !== \"undefined\" && process.exit) {\n")
+	b.WriteString("\t\tprocess.exit(2)\n")
+	b.WriteString("\t}\n")
 	b.WriteString("}\n\n")
 	for _, idx := range indexes {
 		pkg := result.Packages[idx]
@@ -1310,12 +1583,185 @@ func renderCombinedRunner(result *Result, indexes []int, req *normalizedRequest)
 			}
 			b.WriteString("\n")
 		}
-		b.WriteString("])\n")
+		b.WriteString("], ")
+		alias := func(packagePath string) string {
+			return aliases[packagePath]
+		}
+		b.WriteString(renderCases("", pkg.Benchmarks, "b", alias))
+		b.WriteString(", ")
+		b.WriteString(renderCases("", pkg.FuzzTargets, "f", alias))
+		b.WriteString(", ")
+		b.WriteString(renderExamples("", pkg.Examples, alias))
+		b.WriteString(")\n")
 	}
 	b.WriteString("if (typeof process !== \"undefined\" && process.exit) {\n\tprocess.exit(0)\n}\n")
 	return b.String()
 }
 
+// runnerTimeoutGrace is how long before the -timeout deadline generated
+// runners stop a hung test, so the goroutine dump is written before the
+// runtime process is killed.
+const runnerTimeoutGrace = time.Second
+
+// writeRunTimeoutOptions adds the -timeout deadline to a runTests options
+// object.
+func writeRunTimeoutOptions(b *strings.Builder, req *normalizedRequest) {
+	if req.Deadline.IsZero() {
+		return
+	}
+	b.WriteString(", timeout: ")
+	b.WriteString(strconv.Quote(req.Timeout.String()))
+	b.WriteString(", deadline: ")
+	b.WriteString(strconv.FormatInt(req.Deadline.UnixMilli(), 10))
+}
+
+// writeBenchOptions adds the -bench settings to a runTests options object.
+// benchmarks is the JavaScript expression of the benchmark list.
+func writeBenchOptions(b *strings.Builder, req *normalizedRequest, benchmarks string) {
+	if req.Bench == "" {
+		return
+	}
+	b.WriteString(", benchmarks: ")
+	b.WriteString(benchmarks)
+	b.WriteString(", bench: [")
+	for idx, elem := range splitTestPattern(req.Bench) {
+		if idx != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(elem))
+	}
+	b.WriteString("]")
+	if req.BenchIters != 0 {
+		b.WriteString(", benchIters: ")
+		b.WriteString(strconv.Itoa(req.BenchIters))
+	} else {
+		b.WriteString(", benchTime: ")
+		b.WriteString(strconv.FormatFloat(float64(req.BenchTime)/float64(time.Millisecond), 'f', -1, 64))
+	}
+	if req.BenchMem {
+		b.WriteString(", benchmem: true")
+	}
+}
+
+// writeFuzzOptions adds the fuzz targets and the -run levels that select
+// their corpus entries to a runTests options object, along with the -fuzz
+// settings. fuzzTargets is the JavaScript expression of the fuzz target list.
+func writeFuzzOptions(b *strings.Builder, req *normalizedRequest, fuzzTargets string) {
+	if fuzzTargets == "[]" {
+		return
+	}
+	b.WriteString(", fuzzTargets: ")
+	b.WriteString(fuzzTargets)
+	if elems := splitTestPattern(req.Run); req.Run != "" && len(elems) > 1 {
+		b.WriteString(", run: [")
+		for idx, elem := range elems {
+			if idx != 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.Quote(elem))
+		}
+		b.WriteString("]")
+	}
+	if req.Fuzz == "" {
+		return
+	}
+	b.WriteString(", fuzz: ")
+	b.WriteString(strconv.Quote(splitTestPattern(req.Fuzz)[0]))
+	if req.FuzzIters != 0 {
+		b.WriteString(", fuzzIters: ")
+		b.WriteString(strconv.Itoa(req.FuzzIters))
+	} else if req.FuzzTime != 0 {
+		b.WriteString(", fuzzTime: ")
+		b.WriteString(strconv.FormatFloat(float64(req.FuzzTime)/float64(time.Millisecond), 'f', -1, 64))
+	}
+}
+
+// writeExampleOptions adds the examples to a runTests options object.
+// examples is the JavaScript expression of the example list.
+func writeExampleOptions(b *strings.Builder, examples string) {
+	if examples == "[]" {
+		return
+	}
+	b.WriteString(", examples: ")
+	b.WriteString(examples)
+}
+
+// writeTestMainOption adds the TestMain of a package to a runTests options
+// object, which then runs the tests from m.Run.
+func writeTestMainOption(b *strings.Builder, testMain *Test, alias func(packagePath string) string) {
+	if testMain == nil {
+		return
+	}
+	b.WriteString(", testMain: async (m) => await ")
+	b.WriteString(alias(testMain.PackagePath))
+	b.WriteString(".")
+	b.WriteString(testMain.Name)
+	b.WriteString("(m)")
+}
+
+// renderExamples renders the example list of a runTests call.
+func renderExamples(indent string, examples []Example, alias func(packagePath string) string) string {
+	if len(examples) == 0 {
+		return "[]"
+	}
+	var b strings.Builder
+	b.WriteString("[\n")
+	for idx, example := range examples {
+		b.WriteString(indent)
+		b.WriteString("\t{ name: ")
+		b.WriteString(strconv.Quote(example.Name))
+		b.WriteString(", fn: async () => await ")
+		b.WriteString(alias(example.PackagePath))
+		b.WriteString(".")
+		b.WriteString(example.Name)
+		b.WriteString("(), output: ")
+		b.WriteString(strconv.Quote(example.Output))
+		if example.Unordered {
+			b.WriteString(", unordered: true")
+		}
+		b.WriteString(" }")
+		if idx != len(examples)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent)
+	b.WriteString("]")
+	return b.String()
+}
+
+// renderCases renders the benchmark or fuzz target list of a runTests call.
+// param names the *testing.B or *testing.F argument, and alias returns the
+// import alias of a case's package.
+func renderCases(indent string, cases []Test, param string, alias func(packagePath string) string) string {
+	if len(cases) == 0 {
+		return "[]"
+	}
+	var b strings.Builder
+	b.WriteString("[\n")
+	for idx, test := range cases {
+		b.WriteString(indent)
+		b.WriteString("\t{ name: ")
+		b.WriteString(strconv.Quote(test.Name))
+		b.WriteString(", fn: async (")
+		b.WriteString(param)
+		b.WriteString(") => await ")
+		b.WriteString(alias(test.PackagePath))
+		b.WriteString(".")
+		b.WriteString(test.Name)
+		b.WriteString("(")
+		b.WriteString(param)
+		b.WriteString(") }")
+		if idx != len(cases)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent)
+	b.WriteString("]")
+	return b.String()
+}
+
 func writeRuntimeRecordFunction(b *strings.Builder, indent string) {
 	b.WriteString(indent)
 	b.WriteString("function __goscriptRuntimeRecord(packagePath: string, ok: boolean, elapsedMs: number, output: string): string {\n")
@@ -1325,6 +1771,37 @@ func writeRuntimeRecordFunction(b *strings.Builder, indent string) {
 	b.WriteString("}\n")
 }
 
+// writeCoverageImport imports the coverage profile helper when the run
+// measures coverage.
+func writeCoverageImport(b *strings.Builder, req *normalizedRequest) {
+	if !req.Cover {
+		return
+	}
+	b.WriteString("import { coverProfile } from \"@goscript/builtin/index.js\"\n")
+}
+
+// writeCoverageRecord prints the coverage profile of the packages measured
+// for packagePath's tests through log.
+func writeCoverageRecord(b *strings.Builder, indent string, log string, packagePath string, req *normalizedRequest) {
+	if !req.Cover {
+		return
+	}
+	covered := req.coverPackages(packagePath)
+	quoted := make([]string, 0, len(covered))
+	for _, path := range covered {
+		quoted = append(quoted, strconv.Quote(path))
+	}
+	b.WriteString(indent)
+	b.WriteString(log)
+	b.WriteString("(")
+	b.WriteString(strconv.Quote(coverageRecordPrefix))
+	b.WriteString(" + encodeURIComponent(")
+	b.WriteString(strconv.Quote(packagePath))
+	b.WriteString(") + \"\\t\" + encodeURIComponent(coverProfile([")
+	b.WriteString(strings.Join(quoted, ", "))
+	b.WriteString("])))\n")
+}
+
 func writeProcessChdir(b *strings.Builder, dir string) {
 	if dir == "" {
 		return
@@ -1343,7 +1820,7 @@ func runnerImportAliases(result *Result, indexes []int) map[string]string {
 		if result == nil || idx < 0 || idx >= len(result.Packages) {
 			continue
 		}
-		for _, packagePath := range runnerImports(result.Packages[idx].Tests) {
+		for _, packagePath := range runnerImports(packageRunnerFunctions(result.Packages[idx])) {
 			if seen[packagePath] {
 				continue
 			}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"slices"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/s4wave/goscript/compiler"
+	"github.com/s4wave/goscript/compiler/tsworkspace"
 )
 
 func TestDiagnosticsSummaryUsesCompilerFormatter(t *testing.T) {
@@ -487,6 +489,102 @@ func TestRunnerRejectsInvalidRunPattern(t *testing.T) {
 	}
 }
 
+func TestRunnerRejectsInvalidBenchPattern(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod":   "module example.test/badbench\n\ngo 1.25.3\n",
+		"value.go": "package badbench\n",
+	})
+
+	result, err := NewRunner().Run(context.Background(), &Request{
+		Dir:      moduleDir,
+		Patterns: []string{"."},
+		Bench:    "BenchmarkX/[",
+	})
+	if err != nil {
+		t.Fatalf("run package test: %v", err)
+	}
+	if result.Passed() {
+		t.Fatalf("expected invalid bench pattern to fail")
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != "goscript/gotest:bench-pattern" {
+		t.Fatalf("expected structured bench-pattern diagnostic: %#v", result.Diagnostics)
+	}
+}
+
+func TestRunnerRejectsFuzzingMultiplePackages(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod":      "module example.test/fuzzpkgs\n\ngo 1.25.3\n",
+		"a/a.go":      "package a\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n",
+		"b/b.go":      "package b\n",
+		"b/b_test.go": "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\n",
+	})
+
+	result, err := NewRunner().Run(context.Background(), &Request{
+		Dir:      moduleDir,
+		Patterns: []string{"./..."},
+		Fuzz:     "Fuzz",
+	})
+	if err != nil {
+		t.Fatalf("run package test: %v", err)
+	}
+	if result.Passed() {
+		t.Fatalf("expected fuzzing multiple packages to fail")
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != "goscript/gotest:fuzz-packages" {
+		t.Fatalf("expected structured fuzz-packages diagnostic: %#v", result.Diagnostics)
+	}
+}
+
+func TestRunnerRunsBenchmarks(t *testing.T) {
+	moduleDir := writeFixture(t, map[string]string{
+		"go.mod": "module example.test/bench\n\ngo 1.25.3\n",
+		"value_test.go": strings.Join([]string{
+			"package bench",
+			"",
+			"import \"testing\"",
+			"",
+			"func BenchmarkSum(b *testing.B) {",
+			"\tsum := 0",
+			"\tfor i := 0; i < b.N; i++ {",
+			"\t\tsum += i",
+			"\t}",
+			"\tb.ReportMetric(float64(sum), \"sum\")",
+			"}",
+			"",
+			"func BenchmarkLoop(b *testing.B) {",
+			"\tfor b.Loop() {",
+			"\t}",
+			"}",
+			"",
+		}, "\n"),
+	})
+	if _, err := tsworkspace.NewOwner(moduleDir, moduleDir).FindTool("bun"); err != nil {
+		t.Skip(err.Error())
+	}
+
+	result, err := NewRunner().Run(context.Background(), &Request{
+		Dir:       moduleDir,
+		Patterns:  []string{"."},
+		Bench:     "Sum",
+		BenchTime: "4x",
+		Timeout:   30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("run package benchmarks: %v", err)
+	}
+	if !result.Passed() || len(result.Packages) != 1 {
+		t.Fatalf("expected benchmark package to pass: %#v", result.Packages)
+	}
+	output := result.Packages[0].Output
+	if !strings.Contains(output, "pkg: example.test/bench\n") || !regexp.MustCompile(`(?m)^BenchmarkSum\s+4\t.*\t\s+6\.000 sum$`).MatchString(output) {
+		t.Fatalf("expected go test -bench output: %s", output)
+	}
+	if strings.Contains(output, "BenchmarkLoop") || !strings.HasSuffix(output, "PASS") {
+		t.Fatalf("expected only the selected benchmark to run: %s", output)
+	}
+}
+
 func TestRunnerFailsPackagePatternsWhenGraphHasOnlyDiagnostics(t *testing.T) {
 	moduleDir := writeFixture(t, map[string]string{
 		"go.mod":   "module example.test/missing\n\ngo 1.25.3\n",
@@ -1447,6 +1545,199 @@ func TestRenderRunnerChangesToPackageSourceDir(t *testing.T) {
 	}
 }
 
+func TestRenderRunnersPassTimeoutDeadline(t *testing.T) {
+	req := &normalizedRequest{
+		Timeout:  2 * time.Second,
+		Deadline: time.UnixMilli(1700000000000),
+	}
+	pkg := PackageResult{
+		PackagePath: "example.test/pkg",
+		Tests: []Test{{
+			Name:        "TestHang",
+			PackagePath: "example.test/pkg",
+		}},
+	}
+	runner := renderPackageRunner(pkg, req)
+	if !strings.Contains(runner, `, timeout: "2s", deadline: 1700000000000 })`) {
+		t.Fatalf("expected runner to pass the timeout deadline: %s", runner)
+	}
+	if !strings.Contains(runner, "if (result.timedOut && typeof process !== \"undefined\" && process.exit) {\n\tprocess.exit(2)\n}") {
+		t.Fatalf("expected runner to exit after a timed out test: %s", runner)
+	}
+	combined := renderCombinedRunner(&Result{Packages: []PackageResult{pkg}}, []int{0}, req)
+	if !strings.Contains(combined, `, timeout: "2s", deadline: 1700000000000, fuzzTargets: fuzzTargets, examples: examples })`) {
+		t.Fatalf("expected combined runner to pass the timeout deadline: %s", combined)
+	}
+	if strings.Contains(renderPackageRunner(pkg, &normalizedRequest{}), "deadline:") {
+		t.Fatal("expected no deadline without a timeout")
+	}
+}
+
+func TestRenderRunnersPassBenchmarks(t *testing.T) {
+	req, err := (&Request{Patterns: []string{"."}, Bench: "Sum/(a/b)", BenchTime: "1500ms", BenchMem: true}).normalize()
+	if err != nil {
+		t.Fatalf("normalize bench flags: %v", err)
+	}
+	pkg := PackageResult{
+		PackagePath: "example.test/pkg",
+		Benchmarks: []Test{{
+			Name:        "BenchmarkSum",
+			PackagePath: "example.test/pkg_test",
+		}},
+	}
+	options := `, benchmarks: [
+	{ name: "BenchmarkSum", fn: async (b) => await pkg0.BenchmarkSum(b) }
+], bench: ["Sum", "(a/b)"], benchTime: 1500, benchmem: true })`
+	runner := renderRunner(pkg, req)
+	if !strings.Contains(runner, `import * as pkg0 from "@goscript/example.test/pkg_test/index.js"`) || !strings.Contains(runner, options) {
+		t.Fatalf("expected runner to pass the benchmarks: %s", runner)
+	}
+	combined := renderCombinedRunner(&Result{Packages: []PackageResult{pkg}}, []int{0}, req)
+	if !strings.Contains(combined, `, benchmarks: benchmarks, bench: ["Sum", "(a/b)"], benchTime: 1500, benchmem: true, fuzzTargets: fuzzTargets, examples: examples })`) ||
+		!strings.Contains(combined, "], [\n\t{ name: \"BenchmarkSum\", fn: async (b) => await pkg0.BenchmarkSum(b) }\n], [], [])\n") {
+		t.Fatalf("expected combined runner to pass the benchmarks: %s", combined)
+	}
+	if strings.Contains(renderRunner(pkg, &normalizedRequest{}), "benchmarks:") {
+		t.Fatal("expected no benchmarks without -bench")
+	}
+	if got := runtimeParallelism(&normalizedRequest{Bench: ".", Parallelism: 4}); got != 1 {
+		t.Fatalf("benchmark runtime parallelism = %d, want 1", got)
+	}
+}
+
+func TestRenderRunnersPassFuzzTargets(t *testing.T) {
+	req, err := (&Request{Patterns: []string{"."}, Run: "FuzzAdd/seed#1", Fuzz: "Add", FuzzTime: "200x"}).normalize()
+	if err != nil {
+		t.Fatalf("normalize fuzz flags: %v", err)
+	}
+	pkg := PackageResult{
+		PackagePath: "example.test/pkg",
+		FuzzTargets: []Test{{
+			Name:        "FuzzAdd",
+			PackagePath: "example.test/pkg",
+		}},
+	}
+	options := `, fuzzTargets: [
+	{ name: "FuzzAdd", fn: async (f) => await pkg0.FuzzAdd(f) }
+], run: ["FuzzAdd", "seed#1"], fuzz: "Add", fuzzIters: 200 })`
+	if runner := renderRunner(pkg, req); !strings.Contains(runner, options) {
+		t.Fatalf("expected runner to pass the fuzz targets: %s", runner)
+	}
+	combined := renderCombinedRunner(&Result{Packages: []PackageResult{pkg}}, []int{0}, req)
+	if !strings.Contains(combined, `, fuzzTargets: fuzzTargets, run: ["FuzzAdd", "seed#1"], fuzz: "Add", fuzzIters: 200, examples: examples })`) ||
+		!strings.Contains(combined, "], [], [\n\t{ name: \"FuzzAdd\", fn: async (f) => await pkg0.FuzzAdd(f) }\n], [])\n") {
+		t.Fatalf("expected combined runner to pass the fuzz targets: %s", combined)
+	}
+	req, err = (&Request{Patterns: []string{"."}, Fuzz: "Add/x", FuzzTime: "1500ms"}).normalize()
+	if err != nil {
+		t.Fatalf("normalize fuzztime: %v", err)
+	}
+	if runner := renderRunner(pkg, req); !strings.Contains(runner, `], fuzz: "Add", fuzzTime: 1500 })`) {
+		t.Fatalf("expected runner to pass the fuzz time: %s", runner)
+	}
+	if strings.Contains(renderRunner(PackageResult{PackagePath: "example.test/pkg"}, req), "fuzzTargets:") {
+		t.Fatal("expected no fuzz options without fuzz targets")
+	}
+	if got := runtimeParallelism(&normalizedRequest{Fuzz: ".", Parallelism: 4}); got != 1 {
+		t.Fatalf("fuzz runtime parallelism = %d, want 1", got)
+	}
+}
+
+func TestRenderRunnersPassExamplesAndTestMain(t *testing.T) {
+	req, err := (&Request{Patterns: []string{"."}, Cover: true}).normalize()
+	if err != nil {
+		t.Fatalf("normalize cover: %v", err)
+	}
+	pkg := PackageResult{
+		PackagePath: "example.test/pkg",
+		Examples: []Example{
+			{Name: "ExampleAdd", PackagePath: "example.test/pkg", Output: "3\n"},
+			{Name: "Example_set", PackagePath: "example.test/pkg_test", Output: "a\n\"b\"\n", Unordered: true},
+		},
+		TestMain: &Test{Name: "TestMain", PackagePath: "example.test/pkg_test"},
+	}
+	runner := renderRunner(pkg, req)
+	examples := `, examples: [
+	{ name: "ExampleAdd", fn: async () => await pkg0.ExampleAdd(), output: "3\n" },
+	{ name: "Example_set", fn: async () => await pkg1.Example_set(), output: "a\n\"b\"\n", unordered: true }
+], testMain: async (m) => await pkg1.TestMain(m), after: () => {
+	console.log(`
+	if !strings.Contains(runner, examples) {
+		t.Fatalf("expected runner to pass the examples and TestMain: %s", runner)
+	}
+	if strings.Count(runner, "coverProfile(") != 1 {
+		t.Fatalf("expected the coverage record to move into after: %s", runner)
+	}
+	browser := renderBrowserRunner(pkg, req)
+	if !strings.Contains(browser, "testMain: async (m) => await pkg1.TestMain(m), after: () => {\n\t\t\t__goscriptOriginalLog(") ||
+		strings.Count(browser, "coverProfile(") != 1 {
+		t.Fatalf("expected the browser coverage record to move into after: %s", browser)
+	}
+	combined := renderCombinedRunner(&Result{Packages: []PackageResult{pkg}}, []int{0}, req)
+	if !strings.Contains(combined, ", examples: examples })") ||
+		!strings.Contains(combined, "], [], [], [\n\t{ name: \"ExampleAdd\"") {
+		t.Fatalf("expected combined runner to pass the examples: %s", combined)
+	}
+	if strings.Contains(renderRunner(PackageResult{PackagePath: "example.test/pkg"}, req), "examples:") {
+		t.Fatal("expected no example options without examples")
+	}
+}
+
+func TestNormalizeFuzzTime(t *testing.T) {
+	for value, want := range map[string]normalizedRequest{
+		"":     {},
+		"30s":  {FuzzTime: 30 * time.Second},
+		"500x": {FuzzIters: 500},
+	} {
+		norm, err := (&Request{Patterns: []string{"."}, FuzzTime: value}).normalize()
+		if err != nil {
+			t.Fatalf("normalize fuzztime %q: %v", value, err)
+		}
+		if norm.FuzzTime != want.FuzzTime || norm.FuzzIters != want.FuzzIters {
+			t.Fatalf("fuzztime %q = %v/%dx, want %v/%dx", value, norm.FuzzTime, norm.FuzzIters, want.FuzzTime, want.FuzzIters)
+		}
+	}
+	if _, err := (&Request{Patterns: []string{"."}, FuzzTime: "soon"}).normalize(); err == nil ||
+		!strings.Contains(err.Error(), "invalid fuzztime") {
+		t.Fatalf("expected invalid fuzztime to fail, got %v", err)
+	}
+}
+
+func TestNormalizeBenchTime(t *testing.T) {
+	for value, want := range map[string]normalizedRequest{
+		"":      {BenchTime: time.Second},
+		"250ms": {BenchTime: 250 * time.Millisecond},
+		"100x":  {BenchIters: 100},
+	} {
+		norm, err := (&Request{Patterns: []string{"."}, BenchTime: value}).normalize()
+		if err != nil {
+			t.Fatalf("normalize benchtime %q: %v", value, err)
+		}
+		if norm.BenchTime != want.BenchTime || norm.BenchIters != want.BenchIters {
+			t.Fatalf("benchtime %q = %v/%dx, want %v/%dx", value, norm.BenchTime, norm.BenchIters, want.BenchTime, want.BenchIters)
+		}
+	}
+	for _, value := range []string{"0x", "-1s", "fast"} {
+		if _, err := (&Request{Patterns: []string{"."}, BenchTime: value}).normalize(); err == nil ||
+			!strings.Contains(err.Error(), "invalid benchtime") {
+			t.Fatalf("expected invalid benchtime %q to fail, got %v", value, err)
+		}
+	}
+}
+
+func TestSplitTestPatternKeepsGroupedSlashes(t *testing.T) {
+	for pattern, want := range map[string][]string{
+		"Sum":             {"Sum"},
+		"Sum/":            {"Sum", ""},
+		"Sum/size=[0-9/]": {"Sum", "size=[0-9/]"},
+		`Sum/(a/b)\/c/d`:  {"Sum", `(a/b)\/c`, "d"},
+	} {
+		if got := splitTestPattern(pattern); !slices.Equal(got, want) {
+			t.Fatalf("splitTestPattern(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
 func TestRenderBrowserRunnerAvoidsProcessAPIs(t *testing.T) {
 	req := &normalizedRequest{
 		RuntimeBackend: RuntimeBackendBrowser,
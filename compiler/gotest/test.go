@@ -1,9 +1,22 @@
 package gotest
 
-// Test describes one discovered Go test function.
+// Test describes one discovered Go test or benchmark function.
 type Test struct {
-	// Name is the Go TestXxx function name.
+	// Name is the Go TestXxx, BenchmarkXxx, FuzzXxx, or TestMain function
+	// name.
 	Name string
 	// PackagePath is the package variant that exports the function.
 	PackagePath string
 }
+
+// Example describes one discovered Go example function that go test runs.
+type Example struct {
+	// Name is the Go ExampleXxx function name.
+	Name string
+	// PackagePath is the package variant that exports the function.
+	PackagePath string
+	// Output is the text of the example's output comment.
+	Output string
+	// Unordered reports that the output lines may be printed in any order.
+	Unordered bool
+}
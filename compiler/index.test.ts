@@ -2,7 +2,7 @@ import { mkdtemp, mkdir, readFile, readdir, writeFile } from 'node:fs/promises'
 import { tmpdir } from 'node:os'
 import { join } from 'node:path'
 import { describe, it, expect } from 'vitest'
-import { compile } from './index'
+import { compile, type CompilationResult, type GoScriptError } from './index'
 
 describe('GoScript Compiler API', () => {
   it('compiles a simple package through the CLI adapter', async () => {
@@ -43,13 +43,61 @@ describe('GoScript Compiler API', () => {
       '',
     ].join('\n'))
 
-    await expect(compile({
+    const err = await compile({
       pkg: '.',
       output,
       dir,
-    })).rejects.toMatchObject({
+    }).then(
+      () => undefined,
+      (err: GoScriptError<CompilationResult>) => err,
+    )
+    expect(err).toMatchObject({
       stderr: expect.stringContaining('main.go:4:'),
     })
+    expect(err?.result?.Diagnostics).toMatchObject([{
+      Severity: 'error',
+      Code: 'goscript/lowering:unsupported',
+      Stage: 'lowering',
+      Position: { DisplayFile: 'main.go', Line: 4 },
+    }])
+  }, 30000)
+
+  it('compiles multiple packages with build flags and returns the result', async () => {
+    const dir = await mkdtemp(join(tmpdir(), 'goscript-api-result-'))
+    const output = join(dir, 'output')
+    await mkdir(join(dir, 'sub'), { recursive: true })
+    await writeFile(join(dir, 'go.mod'), 'module example.test/apiresult\n\ngo 1.25.3\n')
+    await writeFile(join(dir, 'default.go'), [
+      '//go:build !customtag',
+      '',
+      'package apiresult',
+      'const Selected = "default"',
+      '',
+    ].join('\n'))
+    await writeFile(join(dir, 'custom.go'), [
+      '//go:build customtag',
+      '',
+      'package apiresult',
+      'const Selected = "custom"',
+      '',
+    ].join('\n'))
+    await writeFile(join(dir, 'sub', 'sub.go'), [
+      'package sub',
+      'const Value = 2',
+      '',
+    ].join('\n'))
+
+    const result = await compile({
+      pkg: ['.', './sub'],
+      output,
+      dir,
+      buildFlags: ['-tags=customtag'],
+    })
+
+    expect(result.CompiledPackages).toEqual(['example.test/apiresult', 'example.test/apiresult/sub'])
+    expect(result.Diagnostics).toEqual([])
+    const generated = await readFile(join(output, '@goscript', 'example.test', 'apiresult', 'custom.gs.ts'), 'utf8')
+    expect(generated).toContain('"custom"')
   }, 30000)
 
   it('forwards the package blocklist to closure compiles', async () => {
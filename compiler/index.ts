@@ -9,12 +9,15 @@ const __filename = fileURLToPath(import.meta.url)
 const __dirname = dirname(__filename)
 const projectRoot = dirname(__dirname)
 
+// Test runs can print a lot of package output before the JSON result.
+const maxOutputBuffer = 256 * 1024 * 1024
+
 /**
  * Configuration options for the GoScript compiler.
  */
 export interface CompileConfig {
-  /** The Go package path or pattern to compile. */
-  pkg: string
+  /** The Go package path(s) or pattern(s) to compile. */
+  pkg: string | string[]
   /** The output directory for the generated TypeScript files. Defaults to './output'. */
   output?: string
   /** The working directory for the compiler. Defaults to the current working directory. */
@@ -25,29 +28,104 @@ export interface CompileConfig {
   allDependencies?: boolean
   /** Go import paths to reject from the compiled package graph. */
   packageBlocklist?: string[] | string
+  /** Go build flags, such as `-tags=goscript`. Each entry is one flag. */
+  buildFlags?: string[]
+  /** Additional GoScript override roots containing package-path directories. */
+  gsPath?: string[] | string
+  /** Bind .pb.go files to sibling .pb.ts files instead of emitting .pb.gs.ts. */
+  protobufTsBinding?: boolean
+  /** Skip copying handwritten `gs/` runtime packages. */
+  disableEmitBuiltin?: boolean
+  /** Emit .gs.ts.map source maps that point back to the Go source. */
+  sourceMaps?: boolean
+  /** Add cooperative yield checks to loops in goroutines. */
+  preemptLoops?: boolean
+  /** Skip declarations unreachable from main or the exported API. */
+  eliminateDeadCode?: boolean
   /** The path to the goscript executable. Defaults to `go run ./cmd/goscript`. */
   goscriptPath?: string
 }
 
+/** DiagnosticSeverity mirrors compiler.DiagnosticSeverity. */
+export type DiagnosticSeverity = 'error' | 'warning'
+
+/** DiagnosticStage mirrors compiler.DiagnosticStage. It is empty when unknown. */
+export type DiagnosticStage =
+  | ''
+  | 'request'
+  | 'package-graph'
+  | 'semantic-model'
+  | 'lowering'
+  | 'emit'
+  | 'override-registry'
+
+/** DiagnosticPosition mirrors compiler.DiagnosticPosition. */
+export interface DiagnosticPosition {
+  File: string
+  DisplayFile: string
+  Line: number
+  Column: number
+}
+
+/** Diagnostic mirrors compiler.Diagnostic. */
+export interface Diagnostic {
+  Severity: DiagnosticSeverity
+  Code: string
+  Message: string
+  Detail: string
+  Position: DiagnosticPosition | null
+  Stage: DiagnosticStage
+  Package: string
+}
+
+/** DeadCodePackage mirrors compiler.DeadCodePackage. */
+export interface DeadCodePackage {
+  Package: string
+  Declarations: number
+  RemovedFunctions: number
+  RemovedTypes: number
+  RemovedValues: number
+}
+
+/** CompilationResult mirrors compiler.CompilationResult. */
+export interface CompilationResult {
+  CompiledPackages: string[]
+  CopiedPackages: string[]
+  OriginalPackages: string[]
+  Diagnostics: Diagnostic[]
+  DeadCode: DeadCodePackage[]
+}
+
 /**
- * Compiles a Go package to TypeScript using the goscript compiler.
+ * GoScriptError is thrown when the goscript command fails. It carries the
+ * command output and, when the command wrote one, the structured result.
  */
-export async function compile(config: CompileConfig): Promise<void> {
-  if (!config.pkg) {
+export interface GoScriptError<T> extends Error {
+  stdout: string
+  stderr: string
+  code?: number
+  result?: T
+}
+
+/**
+ * Compiles Go packages to TypeScript using the goscript compiler.
+ *
+ * On failure the thrown error is a GoScriptError whose result holds the
+ * compiler diagnostics.
+ */
+export async function compile(config: CompileConfig): Promise<CompilationResult> {
+  const packages = normalizeList(config.pkg)
+  if (packages.length === 0) {
     throw new Error('Package path (pkg) must be specified.')
   }
 
   const cwd = config.dir ? path.resolve(config.dir) : process.cwd()
   const output = config.output ? path.resolve(config.output) : './output'
-  const args = [
-    'compile',
-    '--package',
-    config.pkg,
-    '--output',
-    output,
-    '--dir',
-    cwd,
-  ]
+  const args = ['compile']
+  for (const pkg of packages) {
+    args.push('--package', pkg)
+  }
+  args.push('--output', output, '--dir', cwd, '--result-json')
   if (config.allDependencies) {
     args.push('--all-dependencies')
   }
@@ -58,17 +136,305 @@ export async function compile(config: CompileConfig): Promise<void> {
   if (packageBlocklist) {
     args.push('--package-blocklist', packageBlocklist)
   }
+  for (const flag of config.buildFlags ?? []) {
+    args.push('--build-flags=' + flag)
+  }
+  for (const dir of normalizeList(config.gsPath)) {
+    args.push('--gs-path=' + path.resolve(dir))
+  }
+  if (config.protobufTsBinding) {
+    args.push('--protobuf-ts-binding')
+  }
+  if (config.disableEmitBuiltin) {
+    args.push('--disable-emit-builtin')
+  }
+  if (config.sourceMaps) {
+    args.push('--source-maps')
+  }
+  if (config.preemptLoops) {
+    args.push('--preempt-loops')
+  }
+  if (config.eliminateDeadCode) {
+    args.push('--eliminate-dead-code')
+  }
+
+  const { result, error } = await runGoScript<CompilationResult>(config.goscriptPath, args)
+  if (error) {
+    throw error
+  }
+  if (!result) {
+    throw new Error('goscript compile did not write a result')
+  }
+  return result
+}
+
+/**
+ * Configuration options for running Go package tests through GoScript.
+ */
+export interface TestConfig {
+  /** The Go package pattern(s) to test. Defaults to '.'. */
+  pkg?: string | string[]
+  /** The Go module working directory. Defaults to the current working directory. */
+  dir?: string
+  /** Go build tags. */
+  tags?: string[]
+  /** Additional GoScript override roots containing package-path directories. */
+  gsPath?: string[] | string
+  /** Run only tests matching the regexp. */
+  run?: string
+  /** Run each selected test this many times. */
+  count?: number
+  /** Report true from testing.Short. */
+  short?: boolean
+  /** Maximum time for the package-test run as a Go duration, such as '1m'. */
+  timeout?: string
+  /** Emit verbose test output. */
+  verbose?: boolean
+  /** Generated TypeScript output root. */
+  output?: string
+  /** Generated test workspace directory. */
+  workDir?: string
+  /** Maximum package typecheck/runtime commands to run concurrently. */
+  parallelism?: number
+  /** Run package runtimes in a Chromium browser instead of Bun. */
+  browser?: boolean
+  /** Emit source maps that point generated TypeScript back to the Go source. */
+  sourceMaps?: boolean
+  /** Let long-running loops in goroutines yield to other goroutines. */
+  preemptLoops?: boolean
+  /** Measure statement coverage of the tested packages. */
+  cover?: boolean
+  /** Coverage mode: 'set', 'count', or 'atomic'. Defaults to 'set'. */
+  covermode?: TestCoverMode
+  /** Write a Go coverage profile to this path. Implies cover. */
+  coverprofile?: string
+  /** Package patterns to measure coverage of instead of each tested package. Implies cover. */
+  coverpkg?: string[] | string
+  /** Run benchmarks matching the regexp after the tests pass. */
+  bench?: string
+  /** Run each benchmark for this Go duration, or N times with the Nx form. Defaults to '1s'. */
+  benchtime?: string
+  /** Print memory statistics for benchmarks. */
+  benchmem?: boolean
+  /** Fuzz the one fuzz target matching the regexp after the tests and seed corpora pass. */
+  fuzz?: string
+  /** Fuzz for this Go duration, or N inputs with the Nx form. Runs until a failure by default. */
+  fuzztime?: string
+  /** The path to the goscript executable. Defaults to `go run ./cmd/goscript`. */
+  goscriptPath?: string
+}
+
+/** TestCoverMode mirrors gotest.CoverMode. */
+export type TestCoverMode = 'set' | 'count' | 'atomic'
+
+/** TestAction mirrors gotest.Action. */
+export type TestAction = 'pass' | 'fail' | 'skip'
+
+/** TestPhaseStatus mirrors gotest.PhaseStatus. */
+export type TestPhaseStatus = 'pending' | 'pass' | 'fail' | 'skip'
+
+/** TestPackagePhases mirrors gotest.PackagePhases. */
+export interface TestPackagePhases {
+  Workspace: TestPhaseStatus
+  Compile: TestPhaseStatus
+  Emit: TestPhaseStatus
+  TypeCheck: TestPhaseStatus
+  Runtime: TestPhaseStatus
+}
+
+/** GoTest mirrors gotest.Test. */
+export interface GoTest {
+  Name: string
+  PackagePath: string
+}
+
+/** PackageTestExample mirrors gotest.Example. */
+export interface PackageTestExample {
+  Name: string
+  PackagePath: string
+  /** Output is the text of the example's output comment. */
+  Output: string
+  /** Unordered reports that the output lines may be printed in any order. */
+  Unordered: boolean
+}
+
+/** TestCoverageBlock mirrors gotest.CoverageBlock. */
+export interface TestCoverageBlock {
+  /** File is the profile file name: the package path and the file's base name. */
+  File: string
+  StartLine: number
+  StartCol: number
+  EndLine: number
+  EndCol: number
+  Statements: number
+  Count: number
+}
+
+/** TestCoverage mirrors gotest.Coverage. */
+export interface TestCoverage {
+  Blocks: TestCoverageBlock[]
+}
+
+/** TestPackageResult mirrors gotest.PackageResult. */
+export interface TestPackageResult {
+  PackagePath: string
+  SourceDir: string
+  TestPackagePath: string
+  TestImports: string[]
+  Tests: GoTest[]
+  /** Benchmarks are the benchmarks selected by bench. */
+  Benchmarks: GoTest[]
+  /** FuzzTargets are the fuzz targets selected by run, whose seed corpora run, and by fuzz. */
+  FuzzTargets: GoTest[]
+  Examples: PackageTestExample[]
+  /** TestMain is the package's TestMain function, or null. */
+  TestMain: GoTest | null
+  Action: TestAction
+  Phases: TestPackagePhases
+  /** Owner is the failure owner classification. It is empty on success. */
+  Owner: string
+  Error: string
+  Output: string
+  /** Elapsed is the package runtime in nanoseconds. */
+  Elapsed: number
+  /** Coverage is the measured statement coverage, or null when coverage was off. */
+  Coverage: TestCoverage | null
+}
+
+/** TestResult mirrors gotest.Result. */
+export interface TestResult {
+  WorkDir: string
+  OutputRoot: string
+  Packages: TestPackageResult[]
+  Diagnostics: Diagnostic[]
+}
+
+/**
+ * Compiles and runs Go package tests through GoScript.
+ *
+ * Test failures resolve with the result; check each package Action. The
+ * promise rejects only when the run did not produce a result.
+ */
+export async function test(config: TestConfig = {}): Promise<TestResult> {
+  const cwd = config.dir ? path.resolve(config.dir) : process.cwd()
+  const args = ['test', '--dir', cwd, '--result-json']
+  const tags = normalizeList(config.tags)
+  if (tags.length !== 0) {
+    args.push('--tags', tags.join(','))
+  }
+  for (const dir of normalizeList(config.gsPath)) {
+    args.push('--gs-path=' + path.resolve(dir))
+  }
+  if (config.run) {
+    args.push('--run', config.run)
+  }
+  if (config.count !== undefined) {
+    args.push('--count', String(config.count))
+  }
+  if (config.short) {
+    args.push('--short')
+  }
+  if (config.timeout) {
+    args.push('--timeout', config.timeout)
+  }
+  if (config.verbose) {
+    args.push('-v')
+  }
+  if (config.output) {
+    args.push('--output', path.resolve(config.output))
+  }
+  if (config.workDir) {
+    args.push('--workdir', path.resolve(config.workDir))
+  }
+  if (config.parallelism !== undefined) {
+    args.push('--p', String(config.parallelism))
+  }
+  if (config.browser) {
+    args.push('--browser')
+  }
+  if (config.sourceMaps) {
+    args.push('--source-maps')
+  }
+  if (config.preemptLoops) {
+    args.push('--preempt-loops')
+  }
+  if (config.cover) {
+    args.push('--cover')
+  }
+  if (config.covermode) {
+    args.push('--covermode', config.covermode)
+  }
+  if (config.coverprofile) {
+    args.push('--coverprofile', path.resolve(config.coverprofile))
+  }
+  const coverPackages = normalizeList(config.coverpkg)
+  if (coverPackages.length !== 0) {
+    args.push('--coverpkg', coverPackages.join(','))
+  }
+  if (config.bench) {
+    args.push('--bench', config.bench)
+  }
+  if (config.benchtime) {
+    args.push('--benchtime', config.benchtime)
+  }
+  if (config.benchmem) {
+    args.push('--benchmem')
+  }
+  if (config.fuzz) {
+    args.push('--fuzz', config.fuzz)
+  }
+  if (config.fuzztime) {
+    args.push('--fuzztime', config.fuzztime)
+  }
+  const packages = normalizeList(config.pkg)
+  args.push(...(packages.length !== 0 ? packages : ['.']))
+
+  const { result, error } = await runGoScript<TestResult>(config.goscriptPath, args)
+  if (result) {
+    return result
+  }
+  throw error ?? new Error('goscript test did not write a result')
+}
 
-  if (config.goscriptPath) {
-    await execFileAsync(config.goscriptPath, args)
-    return
+// runGoScript runs a goscript command that writes one JSON result document to
+// stdout. A failed command still yields the result when it wrote one.
+async function runGoScript<T>(
+  goscriptPath: string | undefined,
+  args: string[],
+): Promise<{ result?: T; error?: GoScriptError<T> }> {
+  const file = goscriptPath ?? 'go'
+  const fileArgs = goscriptPath ? args : ['run', path.join(projectRoot, 'cmd/goscript'), ...args]
+  try {
+    const { stdout } = await execFileAsync(file, fileArgs, { maxBuffer: maxOutputBuffer })
+    return { result: parseResult<T>(stdout) }
+  } catch (err) {
+    const error = err as GoScriptError<T>
+    const result = parseResult<T>(error.stdout)
+    if (result) {
+      error.result = result
+    }
+    return { result, error }
   }
+}
 
-  await execFileAsync('go', [
-    'run',
-    path.join(projectRoot, 'cmd/goscript'),
-    ...args,
-  ])
+function parseResult<T>(stdout: string | undefined): T | undefined {
+  const text = stdout?.trim()
+  if (!text) {
+    return undefined
+  }
+  try {
+    return JSON.parse(text) as T
+  } catch {
+    return undefined
+  }
+}
+
+function normalizeList(value: string[] | string | undefined): string[] {
+  if (!value) {
+    return []
+  }
+  const values = Array.isArray(value) ? value : [value]
+  return values.map((item) => item.trim()).filter(Boolean)
 }
 
 function normalizePackageBlocklist(value: string[] | string | undefined): string {
@@ -86,4 +452,5 @@ function normalizePackageBlocklist(value: string[] | string | undefined): string
  */
 export default {
   compile,
+  test,
 }
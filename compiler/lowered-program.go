@@ -1,15 +1,34 @@
 package compiler
 
+import (
+	"maps"
+	"slices"
+)
+
 // LoweredProgram is the compiler-owned IR consumed by TypeScript emission.
 type LoweredProgram struct {
 	packages     []*loweredPackage
 	trimTypeInfo bool
+	// sourceMaps makes the emitter write a v3 source map beside every file.
+	sourceMaps bool
+	// outputPath is the output root used to relativize source map sources.
+	outputPath string
+	// sources maps each parsed Go file to the content embedded as source map
+	// sourcesContent.
+	sources map[string][]byte
 }
 
 type loweredPackage struct {
 	pkgPath string
 	name    string
 	files   []*loweredFile
+	// runtimeHelpers records the runtime helpers lowering and emission used.
+	runtimeHelpers map[RuntimeHelper]bool
+}
+
+// sortedRuntimeHelpers returns the recorded runtime helpers in order.
+func (p *loweredPackage) sortedRuntimeHelpers() []RuntimeHelper {
+	return slices.Sorted(maps.Keys(p.runtimeHelpers))
 }
 
 type loweredFile struct {
@@ -57,6 +76,7 @@ type loweredDecl struct {
 	packageInitCall string
 	function        *loweredFunction
 	structType      *loweredStruct
+	source          sourcePosition
 }
 
 type loweredStruct struct {
@@ -66,8 +86,11 @@ type loweredStruct struct {
 	name                 string
 	typeName             string
 	cloneMethod          string
-	fields               []loweredStructField
-	methods              []loweredFunction
+	// typeArgs reports a generic struct whose instances carry the
+	// type-argument dictionary of their instantiation.
+	typeArgs bool
+	fields   []loweredStructField
+	methods  []loweredFunction
 }
 
 type loweredStructField struct {
@@ -93,6 +116,7 @@ type loweredFunction struct {
 	init                    bool
 	async                   bool
 	sourcePath              string
+	source                  sourcePosition
 	name                    string
 	typeParams              []string
 	runtimeName             string
@@ -113,6 +137,9 @@ type loweredFunction struct {
 	// empty for a void function. Defer wrappers keep it for every deferred
 	// function so TypeScript sees a total return path after the catch.
 	recoverReturn string
+	// tracebackName is the Go traceback name recorded in source maps, such
+	// as main.main or example.com/pkg.(*T).Method.
+	tracebackName string
 }
 
 type loweredParam struct {
@@ -137,6 +164,8 @@ type loweredStmt struct {
 	switchStmt *loweredSwitch
 	selectStmt *loweredSelect
 	typeSwitch *loweredTypeSwitch
+	// source is the Go position recorded for source maps, if any.
+	source sourcePosition
 }
 
 type loweredRangeFunc struct {
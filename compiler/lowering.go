@@ -38,6 +38,12 @@ type LoweringOptions struct {
 	ProtobufTypeScriptBinding bool
 	// TrimTypeInfo drops metadata used only by reflect from named type registration payloads.
 	TrimTypeInfo bool
+	// SourceMaps records Go source positions on lowered statements for source map emission.
+	SourceMaps bool
+	// PreemptLoops adds cooperative preemption checks to loop bodies in async functions.
+	PreemptLoops bool
+	// CoverPackages are the package paths instrumented for statement coverage.
+	CoverPackages []string
 }
 
 // NewLoweringOwner creates the lowering owner.
@@ -76,7 +82,12 @@ func (o *LoweringOwner) Build(ctx context.Context, model *SemanticModel, opts ..
 		options = opts[0]
 	}
 
-	program := &LoweredProgram{trimTypeInfo: options.TrimTypeInfo}
+	program := &LoweredProgram{
+		trimTypeInfo: options.TrimTypeInfo,
+		sourceMaps:   options.SourceMaps,
+		outputPath:   options.OutputPath,
+		sources:      model.sources,
+	}
 	lazyPackageVars := make(map[string]map[types.Object]bool, len(model.packages))
 	asyncLazyFunctionCache := make(map[*types.Func]bool)
 	asyncLazyFunctionVisiting := make(map[*types.Func]bool)
@@ -92,10 +103,17 @@ func (o *LoweringOwner) Build(ctx context.Context, model *SemanticModel, opts ..
 	var diagnostics []Diagnostic
 	for _, semPkg := range semPkgs {
 		if semPkg.source == nil {
-			diagnostics = append(diagnostics, loweringUnsupported("package", semPkg.pkgPath, "missing semantic source package"))
+			diag := loweringUnsupported("package", semPkg.pkgPath, "missing semantic source package")
+			diag.Package = semPkg.pkgPath
+			diagnostics = append(diagnostics, diag)
 			continue
 		}
-		loweredPkg, pkgDiagnostics := o.lowerPackage(
+		used := make(map[RuntimeHelper]bool)
+		pkgOwner := &LoweringOwner{
+			runtimeOwner:  o.runtimeOwner.recordingUsage(used),
+			overrideOwner: o.overrideOwner,
+		}
+		loweredPkg, pkgDiagnostics := pkgOwner.lowerPackage(
 			model,
 			semPkg,
 			lazyPackageVars,
@@ -104,8 +122,9 @@ func (o *LoweringOwner) Build(ctx context.Context, model *SemanticModel, opts ..
 			runtimeMethodSets,
 			options,
 		)
-		diagnostics = append(diagnostics, pkgDiagnostics...)
+		diagnostics = append(diagnostics, withDiagnosticPackage(pkgDiagnostics, semPkg.pkgPath)...)
 		if loweredPkg != nil {
+			loweredPkg.runtimeHelpers = used
 			program.packages = append(program.packages, loweredPkg)
 		}
 	}
@@ -135,9 +154,14 @@ func (o *LoweringOwner) lowerPackage(
 		outputNames[sourcePath] = binding.outputName
 	}
 	lazyPackageVars := o.packageLazyVars(semPkg, lazyPackageVarsByPkg, declFiles)
+	var coverage *packageCoverage
+	if slices.Contains(options.CoverPackages, semPkg.pkgPath) {
+		coverage = newPackageCoverage(semPkg, model.sources)
+	}
 	diagnostics := append([]Diagnostic(nil), bindingDiagnostics...)
 	for idx, file := range semPkg.source.Syntax {
 		sourcePath := sourceFilePath(semPkg, idx, file)
+		file = model.reachableFile(file)
 		if options.ProtobufTypeScriptBinding && protobufSRPCHasGoScriptReplacement(sourcePath) {
 			stub, stubDiagnostics := lowerProtobufSRPCTypeScriptBindingStub(semPkg, sourcePath, options)
 			diagnostics = append(diagnostics, stubDiagnostics...)
@@ -163,9 +187,12 @@ func (o *LoweringOwner) lowerPackage(
 				protobufAdapter,
 				options.TrimTypeInfo,
 				options.DisplayRoot,
+				options.SourceMaps,
+				options.PreemptLoops,
+				nil,
 			)
 			diagnostics = append(diagnostics, fileDiagnostics...)
-			rewriteProtobufTypeScriptBindingFile(loweredFile, binding)
+			rewriteProtobufTypeScriptBindingFile(loweredFile, binding, o.runtimeOwner)
 			if loweredFile != nil {
 				loweredPkg.files = append(loweredPkg.files, loweredFile)
 			}
@@ -186,6 +213,9 @@ func (o *LoweringOwner) lowerPackage(
 			false,
 			options.TrimTypeInfo,
 			options.DisplayRoot,
+			options.SourceMaps,
+			options.PreemptLoops,
+			coverage,
 		)
 		diagnostics = append(diagnostics, fileDiagnostics...)
 		if loweredFile != nil {
@@ -225,6 +255,9 @@ func (o *LoweringOwner) lowerFile(
 	protobufTypeScriptAdapter bool,
 	trimTypeInfo bool,
 	displayRoot string,
+	sourceMaps bool,
+	preemptLoops bool,
+	coverage *packageCoverage,
 ) (*loweredFile, []Diagnostic) {
 	associatedMethods := o.methodDeclsForFileTypes(semPkg, file)
 	relevantImportFiles := map[string]bool{sourcePath: true}
@@ -354,6 +387,9 @@ func (o *LoweringOwner) lowerFile(
 		protobufTSAdapter:         protobufTypeScriptAdapter,
 		trimTypeInfo:              trimTypeInfo,
 		displayRoot:               displayRoot,
+		sourceMaps:                sourceMaps,
+		preemptLoops:              preemptLoops,
+		coverage:                  coverage,
 	}
 	var diagnostics []Diagnostic
 	var packageInitCalls []string
@@ -384,6 +420,9 @@ func (o *LoweringOwner) lowerFile(
 	lowerDecl := func(decl ast.Decl) {
 		loweredDecls, declDiagnostics := o.lowerDecl(ctx, decl)
 		diagnostics = append(diagnostics, declDiagnostics...)
+		if ctx.sourceMaps {
+			markLoweredDeclSource(ctx, loweredDecls, decl)
+		}
 		appendDecls(loweredDecls)
 	}
 	for _, decl := range file.Decls {
@@ -396,6 +435,10 @@ func (o *LoweringOwner) lowerFile(
 			lowerDecl(decl)
 		}
 	}
+	if registration := coverage.registration(o.runtimeOwner, sourcePath); registration != "" {
+		loweredFile.decls = append([]loweredDecl{{code: registration}}, loweredFile.decls...)
+		loweredFile.sideEffect = true
+	}
 	for _, call := range packageInitCalls {
 		loweredFile.decls = append(loweredFile.decls, loweredDecl{code: "await " + call})
 	}
@@ -1148,14 +1191,15 @@ type lowerFileContext struct {
 	asyncFunction             bool
 	functionTypeDepth         int
 	deferState                *loweredDeferState
+	typeArgsUsed              *bool
 	rangeBranch               *loweredRangeBranch
 	rangeBreak                bool
 	rangeContinue             bool
 	gotoLabels                map[string]bool
 	forwardGotos              map[string]bool
-	gotoStateLabels           map[string]bool
-	gotoStateVar              string
-	gotoStateLoop             string
+	gotoStates                map[string]loweredGotoTarget
+	gotoBranch                *loweredGotoBranch
+	gotoFallback              bool
 	functionScopedDecls       bool
 	loopLabel                 string
 	switchBreak               bool
@@ -1163,6 +1207,11 @@ type lowerFileContext struct {
 	protobufTSAdapter         bool
 	trimTypeInfo              bool
 	displayRoot               string
+	sourceMaps                bool
+	preemptLoops              bool
+	// coverage is the statement coverage instrumentation of the package, or
+	// nil when it is not instrumented.
+	coverage *packageCoverage
 }
 
 func (ctx lowerFileContext) diagnosticPosition(pos token.Pos) *DiagnosticPosition {
@@ -1172,6 +1221,49 @@ func (ctx lowerFileContext) diagnosticPosition(pos token.Pos) *DiagnosticPositio
 	return diagnosticPositionFromSource(sourcePos(ctx.semPkg.source, pos), ctx.displayRoot)
 }
 
+// sourceMapPosition returns the Go position recorded for source maps, or the
+// zero position when the request did not ask for source maps.
+func (ctx lowerFileContext) sourceMapPosition(pos token.Pos) sourcePosition {
+	if !ctx.sourceMaps || ctx.semPkg == nil {
+		return sourcePosition{}
+	}
+	return sourcePos(ctx.semPkg.source, pos)
+}
+
+// sourceMapFuncName returns the Go traceback name of decl recorded in source
+// maps, or "" when the request did not ask for source maps.
+func (ctx lowerFileContext) sourceMapFuncName(decl *ast.FuncDecl) string {
+	if !ctx.sourceMaps || ctx.semPkg == nil {
+		return ""
+	}
+	return tracebackFuncName(ctx.semPkg, decl)
+}
+
+// markLoweredStmtSource records pos on lowered statements that do not already
+// carry a more specific position from a nested Go statement.
+func markLoweredStmtSource(stmts []loweredStmt, pos sourcePosition) {
+	if pos.line <= 0 {
+		return
+	}
+	for idx := range stmts {
+		if stmts[idx].source.line <= 0 {
+			stmts[idx].source = pos
+		}
+	}
+}
+
+func markLoweredDeclSource(ctx lowerFileContext, decls []loweredDecl, decl ast.Decl) {
+	pos := ctx.sourceMapPosition(decl.Pos())
+	if pos.line <= 0 {
+		return
+	}
+	for idx := range decls {
+		if decls[idx].source.line <= 0 {
+			decls[idx].source = pos
+		}
+	}
+}
+
 func loweringUnsupportedAt(ctx lowerFileContext, node ast.Node, kind string, subject string, detail string) Diagnostic {
 	diag := loweringUnsupported(kind, subject, detail)
 	if node != nil {
@@ -1323,7 +1415,7 @@ func (o *LoweringOwner) lowerGenDecl(ctx lowerFileContext, decl *ast.GenDecl) ([
 				}
 				value := o.lowerDeclarationZeroValueExpr(ctx, obj.Type())
 				if constObj, ok := obj.(*types.Const); ok {
-					if constValue, ok := lowerConstantValueForType(constObj.Val(), constObj.Type()); ok {
+					if constValue, ok := o.lowerConstantValueForType(constObj.Val(), constObj.Type()); ok {
 						value = constValue
 					}
 				} else if idx < len(typed.Values) {
@@ -2555,7 +2647,7 @@ func (o *LoweringOwner) lowerTupleValueSpec(
 	return decls, diagnostics
 }
 
-func lowerConstantValue(value constant.Value) (string, bool) {
+func (o *LoweringOwner) lowerConstantValue(value constant.Value) (string, bool) {
 	if value == nil {
 		return "", false
 	}
@@ -2563,7 +2655,7 @@ func lowerConstantValue(value constant.Value) (string, bool) {
 	case constant.Bool:
 		return strconv.FormatBool(constant.BoolVal(value)), true
 	case constant.String:
-		return lowerGoStringLiteral(constant.StringVal(value)), true
+		return o.lowerGoStringLiteral(constant.StringVal(value)), true
 	case constant.Int:
 		if intValue, ok := constant.Int64Val(value); ok {
 			return strconv.FormatInt(intValue, 10), true
@@ -2577,7 +2669,7 @@ func lowerConstantValue(value constant.Value) (string, bool) {
 	case constant.Complex:
 		real := constant.Real(value).ExactString()
 		imag := constant.Imag(value).ExactString()
-		return "$.complex(" + real + ", " + imag + ")", true
+		return o.runtimeOwner.QualifiedHelper(RuntimeHelperComplex) + "(" + real + ", " + imag + ")", true
 	default:
 		return "", false
 	}
@@ -2594,11 +2686,11 @@ func lowerLargeIntegerConstantValue(value constant.Value) (string, bool) {
 // its declared type is bigint-backed (int64/uint64 and named types over them),
 // so that const declarations and inlined const references carry the same
 // representation as the values they participate in arithmetic with.
-func lowerConstantValueForType(value constant.Value, typ types.Type) (string, bool) {
+func (o *LoweringOwner) lowerConstantValueForType(value constant.Value, typ types.Type) (string, bool) {
 	if value != nil && value.Kind() == constant.Int && isBigIntBackedType(typ) {
 		return value.ExactString() + "n", true
 	}
-	return lowerConstantValue(value)
+	return o.lowerConstantValue(value)
 }
 
 func lowerWideIntegerConstantValue(value constant.Value) (string, bool) {
@@ -2711,8 +2803,7 @@ func (o *LoweringOwner) lowerGoEmbedFSValue(ctx lowerFileContext, diagPos token.
 	for _, path := range paths {
 		entries = append(entries, "["+strconv.Quote(path)+", "+byteSliceLiteral(filesByPath[path])+"]")
 	}
-	builtinAlias := o.runtimeOwner.BuiltinImport().Alias
-	return builtinAlias + ".markAsStructValue(new " + embedAlias + ".FS(new globalThis.Map<string, Uint8Array>([" + strings.Join(entries, ", ") + "])))", nil
+	return o.runtimeOwner.QualifiedHelper(RuntimeHelperMarkAsStructValue) + "(new " + embedAlias + ".FS(new globalThis.Map<string, Uint8Array>([" + strings.Join(entries, ", ") + "])))", nil
 }
 
 type goEmbedFile struct {
@@ -2875,11 +2966,11 @@ func byteSliceLiteral(data []byte) string {
 	return "new Uint8Array([" + strings.Join(values, ", ") + "])"
 }
 
-func lowerGoStringLiteral(value string) string {
+func (o *LoweringOwner) lowerGoStringLiteral(value string) string {
 	if utf8.ValidString(value) {
 		return strconv.Quote(value)
 	}
-	return "$.bytesToString(" + byteSliceLiteral([]byte(value)) + ")"
+	return o.runtimeOwner.QualifiedHelper(RuntimeHelperBytesToString) + "(" + byteSliceLiteral([]byte(value)) + ")"
 }
 
 func (o *LoweringOwner) lowerTypeSpec(ctx lowerFileContext, spec *ast.TypeSpec) (loweredDecl, []Diagnostic) {
@@ -3135,6 +3226,12 @@ func (o *LoweringOwner) lowerStructType(ctx lowerFileContext, semType *semanticT
 		cloneMethod:          "clone",
 		fields:               make([]loweredStructField, 0, len(semType.fields)),
 	}
+	fieldCtx := ctx
+	if typeParams := semType.named.TypeParams(); typeParams.Len() != 0 {
+		// Field zero values read the constructor's __typeArgs parameter.
+		lowered.typeArgs = true
+		fieldCtx = ctx.withTypeParamList(typeParams)
+	}
 	for idx, field := range semType.fields {
 		structValue := isStructValueType(field.typ)
 		if named := namedStructType(field.typ); named != nil && crossPackageUnexportedNamedType(ctx, named) {
@@ -3149,7 +3246,7 @@ func (o *LoweringOwner) lowerStructType(ctx lowerFileContext, semType *semanticT
 			name:        fieldName,
 			runtimeName: runtimeName,
 			typ:         o.tsStructFieldTypeFor(ctx, field.typ),
-			zero:        o.lowerZeroValueExprFor(ctx, field.typ),
+			zero:        o.lowerZeroValueExprFor(fieldCtx, field.typ),
 			runtimeType: o.runtimeTypeInfoExpr(field.typ),
 			doc:         field.doc,
 			tag:         field.tag,
@@ -3501,7 +3598,12 @@ func (o *LoweringOwner) lowerNamedReceiverMethodDecl(
 		return nil, nil
 	}
 	async := o.functionAsync(ctx, fnObj)
-	functionCtx := ctx.withSignature(signature)
+	typeArgsUsed := false
+	functionCtx := ctx.withSignature(signature).withTypeArgsUse(&typeArgsUsed)
+	recvTypeParams := signature.RecvTypeParams()
+	if recvTypeParams.Len() != 0 {
+		functionCtx = functionCtx.withTypeParamList(recvTypeParams)
+	}
 	resultCtx := functionCtx.withAsyncFunction(async)
 	result := o.tsSignatureResultFor(resultCtx, signature)
 	receiverName := "recv"
@@ -3514,6 +3616,8 @@ func (o *LoweringOwner) lowerNamedReceiverMethodDecl(
 		indexExported: ctx.topLevel && (ast.IsExported(receiver.Obj().Name()) || ast.IsExported(decl.Name.Name)),
 		async:         async,
 		sourcePath:    sourcePos(ctx.semPkg.source, decl.Pos()).file,
+		source:        ctx.sourceMapPosition(decl.Pos()),
+		tracebackName: ctx.sourceMapFuncName(decl),
 		name:          methodFunctionName(receiver, decl.Name.Name),
 		result:        asyncResultType(result, async),
 		deferState:    deferState,
@@ -3521,7 +3625,17 @@ func (o *LoweringOwner) lowerNamedReceiverMethodDecl(
 			name: receiverName,
 			typ:  o.tsReceiverTypeFor(ctx, signature.Recv().Type()),
 		}},
-		namedResults: o.lowerNamedResults(ctx, signature),
+		namedResults: o.lowerNamedResults(functionCtx, signature),
+	}
+	// Methods of a generic type take its dictionary after the receiver.
+	typeArgsBinding := ""
+	if recvTypeParams.Len() != 0 {
+		param := loweredParam{name: "__typeArgs", typ: "$.GenericTypeArgs | undefined"}
+		if receiverRenamesTypeParams(recvTypeParams, receiver) {
+			param.name = "__receiverTypeArgs"
+			typeArgsBinding = receiverTypeArgsBinding(recvTypeParams, receiver, param.name)
+		}
+		lowered.params = append(lowered.params, param)
 	}
 	if len(decl.Recv.List) != 0 && len(decl.Recv.List[0].Names) != 0 {
 		receiverObj := ctx.semPkg.source.TypesInfo.Defs[decl.Recv.List[0].Names[0]]
@@ -3536,12 +3650,15 @@ func (o *LoweringOwner) lowerNamedReceiverMethodDecl(
 	}
 	for idx := range signature.Params().Len() {
 		param := signature.Params().At(idx)
-		lowered.params, lowered.paramBindings = o.appendLoweredParam(ctx, lowered.params, lowered.paramBindings, param, idx, decl.Body == nil || async)
+		lowered.params, lowered.paramBindings = o.appendLoweredParam(functionCtx, lowered.params, lowered.paramBindings, param, idx, decl.Body == nil || async)
 	}
 	if decl.Body != nil {
-		bodyCtx := ctx.withSignature(signature).withAsyncFunction(async).withDeferState(deferState)
-		body, diagnostics := o.lowerBlock(bodyCtx, decl.Body)
+		bodyCtx := functionCtx.withAsyncFunction(async).withDeferState(deferState)
+		body, diagnostics := o.lowerFunctionBody(bodyCtx, decl.Body)
 		lowered.body = body
+		if typeArgsBinding != "" && typeArgsUsed {
+			lowered.paramBindings = append([]loweredStmt{{text: typeArgsBinding}}, lowered.paramBindings...)
+		}
 		if deferState.used {
 			lowered.recoverReturn = o.recoverReturnStmt(bodyCtx, signature)
 			if funcBodyUsesRecover(bodyCtx, decl.Body) {
@@ -3550,7 +3667,7 @@ func (o *LoweringOwner) lowerNamedReceiverMethodDecl(
 		}
 		if deferState.async && !lowered.async {
 			lowered.async = true
-			lowered.result = asyncResultType(o.tsSignatureResultFor(ctx.withAsyncFunction(true), signature), true)
+			lowered.result = asyncResultType(o.tsSignatureResultFor(functionCtx.withAsyncFunction(true), signature), true)
 		}
 		return lowered, diagnostics
 	}
@@ -3573,7 +3690,14 @@ func (o *LoweringOwner) lowerFuncDecl(ctx lowerFileContext, decl *ast.FuncDecl)
 	if decl.Name.Name == "main" {
 		async = true
 	}
-	functionCtx := ctx.withSignature(signature)
+	typeArgsUsed := false
+	functionCtx := ctx.withSignature(signature).withTypeArgsUse(&typeArgsUsed)
+	typeArgsBinding := ""
+	if recvTypeParams := signature.RecvTypeParams(); recvTypeParams.Len() != 0 && receiverStructType(signature.Recv().Type()) != nil {
+		functionCtx = functionCtx.withTypeParamList(recvTypeParams)
+		_, pointerRecv := types.Unalias(signature.Recv().Type()).(*types.Pointer)
+		typeArgsBinding = receiverTypeArgsBinding(recvTypeParams, receiverStructType(signature.Recv().Type()), o.structReceiverTypeArgsSource(pointerRecv))
+	}
 	resultCtx := functionCtx.withAsyncFunction(async)
 	result := o.tsSignatureResultFor(resultCtx, signature)
 	deferState := &loweredDeferState{}
@@ -3596,6 +3720,8 @@ func (o *LoweringOwner) lowerFuncDecl(ctx lowerFileContext, decl *ast.FuncDecl)
 		init:          initFunc,
 		async:         async,
 		sourcePath:    sourcePos(ctx.semPkg.source, decl.Pos()).file,
+		source:        ctx.sourceMapPosition(decl.Pos()),
+		tracebackName: ctx.sourceMapFuncName(decl),
 		name:          name,
 		runtimeName:   runtimeName,
 		result:        asyncResultType(result, async),
@@ -3636,8 +3762,11 @@ func (o *LoweringOwner) lowerFuncDecl(ctx lowerFileContext, decl *ast.FuncDecl)
 	}
 	if decl.Body != nil {
 		bodyCtx := functionCtx.withAsyncFunction(async).withDeferState(deferState)
-		body, diagnostics := o.lowerBlock(bodyCtx, decl.Body)
+		body, diagnostics := o.lowerFunctionBody(bodyCtx, decl.Body)
 		lowered.body = body
+		if typeArgsBinding != "" && typeArgsUsed {
+			lowered.paramBindings = append([]loweredStmt{{text: typeArgsBinding}}, lowered.paramBindings...)
+		}
 		if deferState.used {
 			lowered.recoverReturn = o.recoverReturnStmt(bodyCtx, signature)
 			if funcBodyUsesRecover(bodyCtx, decl.Body) {
@@ -3820,6 +3949,21 @@ func (ctx lowerFileContext) withSignature(signature *types.Signature) lowerFileC
 	return ctx
 }
 
+// withTypeParamList puts typeParams in scope without a signature, as for the
+// fields and methods of a generic struct type.
+func (ctx lowerFileContext) withTypeParamList(typeParams *types.TypeParamList) lowerFileContext {
+	if typeParams == nil || typeParams.Len() == 0 {
+		return ctx
+	}
+	next := make(map[string]bool, len(ctx.typeParams)+typeParams.Len())
+	maps.Copy(next, ctx.typeParams)
+	for typeParam := range typeParams.TypeParams() {
+		next[typeParam.Obj().Name()] = true
+	}
+	ctx.typeParams = next
+	return ctx
+}
+
 func (ctx lowerFileContext) withAsyncFunction(async bool) lowerFileContext {
 	ctx.asyncFunction = async
 	return ctx
@@ -3835,6 +3979,22 @@ func (ctx lowerFileContext) withDeferState(deferState *loweredDeferState) lowerF
 	return ctx
 }
 
+// withTypeArgsUse records in used whether the lowered code reads the
+// __typeArgs dictionary.
+func (ctx lowerFileContext) withTypeArgsUse(used *bool) lowerFileContext {
+	ctx.typeArgsUsed = used
+	return ctx
+}
+
+// typeArgsRef returns the __typeArgs dictionary of the enclosing generic
+// function, method or constructor and records the use.
+func (ctx lowerFileContext) typeArgsRef() string {
+	if ctx.typeArgsUsed != nil {
+		*ctx.typeArgsUsed = true
+	}
+	return "__typeArgs"
+}
+
 func (ctx lowerFileContext) withLocalScope() lowerFileContext {
 	ctx.topLevel = false
 	ctx.functionScopedDecls = false
@@ -3911,9 +4071,34 @@ func (ctx lowerFileContext) withForwardGotos(labels map[string]bool) lowerFileCo
 }
 
 func (ctx lowerFileContext) withGotoState(labels map[string]bool, stateVar string, loopLabel string) lowerFileContext {
-	ctx.gotoStateLabels = labels
-	ctx.gotoStateVar = stateVar
-	ctx.gotoStateLoop = loopLabel
+	states := make(map[string]loweredGotoTarget, len(ctx.gotoStates)+len(labels))
+	for label, target := range ctx.gotoStates {
+		states[label] = target
+	}
+	for label := range labels {
+		states[label] = loweredGotoTarget{stateVar: stateVar, loop: loopLabel}
+	}
+	ctx.gotoStates = states
+	return ctx
+}
+
+// withoutGotoState hides enclosing goto state machines from bodies lowered
+// into callbacks, where a labeled continue cannot reach the dispatch loop.
+func (ctx lowerFileContext) withoutGotoState() lowerFileContext {
+	ctx.gotoStates = nil
+	ctx.gotoBranch = nil
+	return ctx
+}
+
+func (ctx lowerFileContext) withGotoBranch(branch *loweredGotoBranch) lowerFileContext {
+	ctx.gotoBranch = branch
+	return ctx
+}
+
+// withGotoFallback lowers every statement list that owns goto labels as a
+// state machine. It is used when the structured goto shapes do not apply.
+func (ctx lowerFileContext) withGotoFallback(fallback bool) lowerFileContext {
+	ctx.gotoFallback = fallback
 	return ctx
 }
 
@@ -3924,11 +4109,13 @@ func (ctx lowerFileContext) withLoopLabel(label string) lowerFileContext {
 
 func (ctx lowerFileContext) withoutLoopLabel() lowerFileContext {
 	ctx.loopLabel = ""
+	ctx.gotoBranch = nil
 	return ctx
 }
 
 func (ctx lowerFileContext) withSwitchBreak() lowerFileContext {
 	ctx.switchBreak = true
+	ctx.gotoBranch = nil
 	return ctx
 }
 
@@ -3936,14 +4123,47 @@ func (o *LoweringOwner) lowerBlock(ctx lowerFileContext, block *ast.BlockStmt) (
 	if block == nil {
 		return nil, nil
 	}
+	if len(block.List) == 0 {
+		if counter, ok := ctx.coverage.counterStmt(o.runtimeOwner, block); ok {
+			return []loweredStmt{counter}, nil
+		}
+	}
 	return o.lowerStmtListAfter(ctx.withLocalScope(), block.List, sourceLine(ctx, block.Lbrace))
 }
 
+// appendCoverCounter appends the coverage counter of the block that starts
+// at node, if one does.
+func (o *LoweringOwner) appendCoverCounter(ctx lowerFileContext, node ast.Node, out []loweredStmt) []loweredStmt {
+	if counter, ok := ctx.coverage.counterStmt(o.runtimeOwner, node); ok {
+		return append(out, counter)
+	}
+	return out
+}
+
+// lowerFunctionBody lowers a function body. When a goto does not fit the
+// structured loop and block shapes, every labeled statement list of the body
+// is lowered as a goto state machine instead.
+func (o *LoweringOwner) lowerFunctionBody(ctx lowerFileContext, body *ast.BlockStmt) ([]loweredStmt, []Diagnostic) {
+	ctx = ctx.withoutGotoState().withGotoFallback(!gotosFitStructuredShapes(body.List, gotoTargets{}))
+	return o.lowerBlock(ctx, body)
+}
+
 func (o *LoweringOwner) lowerStmt(ctx lowerFileContext, stmt ast.Stmt) ([]loweredStmt, []Diagnostic) {
 	return o.lowerStmtInto(ctx, stmt, nil)
 }
 
 func (o *LoweringOwner) lowerStmtInto(ctx lowerFileContext, stmt ast.Stmt, out []loweredStmt) ([]loweredStmt, []Diagnostic) {
+	start := len(out)
+	out = o.appendCoverCounter(ctx, stmt, out)
+	if !ctx.sourceMaps {
+		return o.lowerStmtKindInto(ctx, stmt, out)
+	}
+	out, diagnostics := o.lowerStmtKindInto(ctx, stmt, out)
+	markLoweredStmtSource(out[start:], ctx.sourceMapPosition(stmt.Pos()))
+	return out, diagnostics
+}
+
+func (o *LoweringOwner) lowerStmtKindInto(ctx lowerFileContext, stmt ast.Stmt, out []loweredStmt) ([]loweredStmt, []Diagnostic) {
 	switch typed := stmt.(type) {
 	case *ast.DeclStmt:
 		decls, diagnostics := o.lowerDecl(ctx, typed.Decl)
@@ -3954,8 +4174,8 @@ func (o *LoweringOwner) lowerStmtInto(ctx lowerFileContext, stmt ast.Stmt, out [
 				continue
 			}
 			if decl.structType != nil {
-				var b strings.Builder
-				renderStruct(&b, decl.structType, o.runtimeOwner, ctx.trimTypeInfo)
+				b := tsBuilder{runtime: o.runtimeOwner}
+				renderStruct(&b, decl.structType, ctx.trimTypeInfo)
 				stmts = append(stmts, loweredStmt{text: strings.TrimRight(b.String(), "\n")})
 			}
 		}
@@ -4089,10 +4309,10 @@ func (o *LoweringOwner) lowerStmtInto(ctx lowerFileContext, stmt ast.Stmt, out [
 				return append(out, loweredStmt{text: typed.Tok.String() + " " + safeIdentifier(typed.Label.Name)}), nil
 			case token.GOTO:
 				label := safeIdentifier(typed.Label.Name)
-				if ctx.gotoStateLabels[label] {
+				if target, ok := ctx.gotoStates[label]; ok {
 					return append(out,
-						loweredStmt{text: ctx.gotoStateVar + " = " + strconv.Quote(label)},
-						loweredStmt{text: "continue " + ctx.gotoStateLoop},
+						loweredStmt{text: target.stateVar + " = " + strconv.Quote(label)},
+						loweredStmt{text: "continue " + target.loop},
 					), nil
 				}
 				if ctx.forwardGotos[label] {
@@ -4120,6 +4340,9 @@ func (o *LoweringOwner) lowerStmtInto(ctx lowerFileContext, stmt ast.Stmt, out [
 			if typed.Tok == token.CONTINUE && ctx.rangeBranch != nil && ctx.rangeContinue {
 				return append(out, loweredStmt{text: "return true"}), nil
 			}
+			if ctx.gotoBranch != nil {
+				return append(out, ctx.gotoBranch.exit(typed.Tok)...), nil
+			}
 			return append(out, loweredStmt{text: typed.Tok.String()}), nil
 		case token.FALLTHROUGH:
 			return append(out, loweredStmt{text: "fallthrough"}), nil
@@ -4168,6 +4391,17 @@ func (o *LoweringOwner) lowerStmtList(ctx lowerFileContext, stmts []ast.Stmt) ([
 	return o.lowerStmtListAfter(ctx, stmts, 0)
 }
 
+// lowerClauseBody lowers the statements of a case or select clause. An empty
+// clause still counts its coverage block.
+func (o *LoweringOwner) lowerClauseBody(ctx lowerFileContext, clause ast.Node, stmts []ast.Stmt) ([]loweredStmt, []Diagnostic) {
+	if len(stmts) == 0 {
+		if counter, ok := ctx.coverage.counterStmt(o.runtimeOwner, clause); ok {
+			return []loweredStmt{counter}, nil
+		}
+	}
+	return o.lowerStmtList(ctx, stmts)
+}
+
 func (o *LoweringOwner) lowerStmtListAfter(
 	ctx lowerFileContext,
 	stmts []ast.Stmt,
@@ -4179,7 +4413,7 @@ func (o *LoweringOwner) lowerStmtListAfter(
 	var gotoSpans map[string]int
 	var gotoLabels map[string]bool
 	var forwardSpans map[string]forwardGotoLabelSpan
-	if hasGoto {
+	if hasGoto && !ctx.gotoFallback {
 		gotoSpans = backwardGotoLabelSpans(stmts)
 		gotoLabels = make(map[string]bool, len(gotoSpans))
 		for label := range gotoSpans {
@@ -4195,8 +4429,19 @@ func (o *LoweringOwner) lowerStmtListAfter(
 		stmt := stmts[idx]
 		startLine := sourceLine(ctx, stmt.Pos())
 		leading := leadingStmtLines(ctx, prevEndLine, startLine)
-		if hasGoto {
-			if cluster, ok := gotoStateClusterAt(stmts, idx); ok {
+		if hasGoto && ctx.gotoFallback {
+			if cluster, ok := gotoStateClusterAt(stmts, idx, false); ok {
+				clusterLowered, clusterDiagnostics := o.lowerGotoStateCluster(ctx, stmts, cluster, leading)
+				diagnostics = append(diagnostics, clusterDiagnostics...)
+				lowered = append(lowered, clusterLowered...)
+				if endLine := sourceLine(ctx, stmts[cluster.endIdx].End()); endLine != 0 {
+					prevEndLine = endLine
+				}
+				idx = cluster.endIdx
+				continue
+			}
+		} else if hasGoto {
+			if cluster, ok := gotoStateClusterAt(stmts, idx, true); ok {
 				clusterLowered, clusterDiagnostics := o.lowerGotoStateCluster(ctx, stmts, cluster, leading)
 				diagnostics = append(diagnostics, clusterDiagnostics...)
 				lowered = append(lowered, clusterLowered...)
@@ -4365,7 +4610,66 @@ type gotoStateLabel struct {
 	idx  int
 }
 
-func gotoStateClusterAt(stmts []ast.Stmt, idx int) (gotoStateCluster, bool) {
+// loweredGotoTarget is the dispatch loop and state variable a goto to a state
+// machine label assigns and continues.
+type loweredGotoTarget struct {
+	stateVar string
+	loop     string
+}
+
+// loweredGotoBranch reroutes unlabeled break and continue statements inside a
+// goto state machine to the loop or switch around it. The dispatch loop would
+// otherwise capture them, so they leave it with a sentinel state that the code
+// after the loop replays.
+type loweredGotoBranch struct {
+	stateVar     string
+	loop         string
+	parent       *loweredGotoBranch
+	breakUsed    bool
+	continueUsed bool
+}
+
+func (branch *loweredGotoBranch) exit(tok token.Token) []loweredStmt {
+	state := "__break"
+	if tok == token.CONTINUE {
+		state = "__continue"
+		branch.continueUsed = true
+	} else {
+		branch.breakUsed = true
+	}
+	return []loweredStmt{
+		{text: branch.stateVar + " = " + strconv.Quote(state)},
+		{text: "break " + branch.loop},
+	}
+}
+
+// replay re-issues the break or continue that left the dispatch loop.
+func (branch *loweredGotoBranch) replay() []loweredStmt {
+	var out []loweredStmt
+	for _, tok := range []token.Token{token.BREAK, token.CONTINUE} {
+		used, state := branch.breakUsed, "__break"
+		if tok == token.CONTINUE {
+			used, state = branch.continueUsed, "__continue"
+		}
+		if !used {
+			continue
+		}
+		action := []loweredStmt{{text: tok.String()}}
+		if branch.parent != nil {
+			action = branch.parent.exit(tok)
+		}
+		out = append(out, loweredStmt{
+			text:     "if (" + branch.stateVar + " === " + strconv.Quote(state) + ")",
+			children: action,
+		})
+	}
+	return out
+}
+
+// gotoStateClusterAt finds the run of statements starting at idx that gotos to
+// labels of this list span. Structured lowering only takes clusters with a
+// backward jump; the fallback takes any of them.
+func gotoStateClusterAt(stmts []ast.Stmt, idx int, requireBackward bool) (gotoStateCluster, bool) {
 	labelIndexes := make(map[string]int)
 	for stmtIdx, stmt := range stmts {
 		labeled, ok := stmt.(*ast.LabeledStmt)
@@ -4410,7 +4714,10 @@ func gotoStateClusterAt(stmts []ast.Stmt, idx int) (gotoStateCluster, bool) {
 			return true
 		})
 	}
-	if !hasBackward || startIdx != idx || endIdx < firstLabelIdx || firstLabelIdx == len(stmts) {
+	if !requireBackward && firstLabelIdx < startIdx {
+		startIdx = firstLabelIdx
+	}
+	if (requireBackward && !hasBackward) || startIdx != idx || endIdx < firstLabelIdx || firstLabelIdx == len(stmts) {
 		return gotoStateCluster{}, false
 	}
 
@@ -4445,7 +4752,8 @@ func (o *LoweringOwner) lowerGotoStateCluster(
 	for _, label := range cluster.labels {
 		labels[label.name] = true
 	}
-	stateCtx := ctx.withGotoState(labels, stateVar, loopLabel).withFunctionScopedDecls()
+	branch := &loweredGotoBranch{stateVar: stateVar, loop: loopLabel, parent: ctx.gotoBranch}
+	stateCtx := ctx.withGotoState(labels, stateVar, loopLabel).withGotoBranch(branch).withFunctionScopedDecls()
 
 	var diagnostics []Diagnostic
 	initialState := cluster.labels[0].name
@@ -4472,11 +4780,22 @@ func (o *LoweringOwner) lowerGotoStateCluster(
 			nextState = cluster.labels[idx+1].name
 		}
 		labeled, _ := stmts[label.idx].(*ast.LabeledStmt)
-		segment := make([]ast.Stmt, 0, nextIdx-label.idx)
-		segment = append(segment, labeled.Stmt)
-		segment = append(segment, stmts[label.idx+1:nextIdx]...)
-		body, bodyDiagnostics := o.lowerStmtListAfter(stateCtx, segment, sourceLine(ctx, labeled.Label.End()))
-		diagnostics = append(diagnostics, bodyDiagnostics...)
+		var body []loweredStmt
+		if labelUsedByBranch(labeled) {
+			// break/continue to this label need it on the lowered statement.
+			first, firstDiagnostics := o.lowerStmt(stateCtx, labeled)
+			rest, restDiagnostics := o.lowerStmtListAfter(stateCtx, stmts[label.idx+1:nextIdx], sourceLine(ctx, labeled.End()))
+			diagnostics = append(diagnostics, firstDiagnostics...)
+			diagnostics = append(diagnostics, restDiagnostics...)
+			body = append(first, rest...)
+		} else {
+			segment := make([]ast.Stmt, 0, nextIdx-label.idx)
+			segment = append(segment, labeled.Stmt)
+			segment = append(segment, stmts[label.idx+1:nextIdx]...)
+			segmentBody, bodyDiagnostics := o.lowerStmtListAfter(stateCtx, segment, sourceLine(ctx, labeled.Label.End()))
+			diagnostics = append(diagnostics, bodyDiagnostics...)
+			body = segmentBody
+		}
 		if nextState != "" {
 			body = append(body,
 				loweredStmt{text: stateVar + " = " + strconv.Quote(nextState)},
@@ -4503,7 +4822,219 @@ func (o *LoweringOwner) lowerGotoStateCluster(
 	if len(leading) != 0 {
 		init.leading = append(leading, init.leading...)
 	}
-	return []loweredStmt{init, dispatch}, diagnostics
+	return append([]loweredStmt{init, dispatch}, branch.replay()...), diagnostics
+}
+
+// labelUsedByBranch reports whether a break or continue names the label.
+func labelUsedByBranch(labeled *ast.LabeledStmt) bool {
+	used := false
+	ast.Inspect(labeled.Stmt, func(node ast.Node) bool {
+		if _, ok := node.(*ast.FuncLit); ok {
+			return false
+		}
+		branch, ok := node.(*ast.BranchStmt)
+		if ok && branch.Label != nil && branch.Label.Name == labeled.Label.Name &&
+			(branch.Tok == token.BREAK || branch.Tok == token.CONTINUE) {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
+
+// gotoTargets are the labels a goto can reach from a statement under the
+// structured goto shapes: loop and block labels, and the labels of
+// enclosing state machines, which select clauses cannot reach.
+type gotoTargets struct {
+	labels map[string]bool
+	states map[string]bool
+}
+
+func (targets gotoTargets) has(label string) bool {
+	return targets.labels[label] || targets.states[label]
+}
+
+func (targets gotoTargets) withLabels(labels map[string]bool) gotoTargets {
+	targets.labels = mergeGotoLabels(targets.labels, labels)
+	return targets
+}
+
+func (targets gotoTargets) withStates(labels map[string]bool) gotoTargets {
+	targets.states = mergeGotoLabels(targets.states, labels)
+	return targets
+}
+
+func (targets gotoTargets) withoutStates() gotoTargets {
+	targets.states = nil
+	return targets
+}
+
+func mergeGotoLabels(labels map[string]bool, more map[string]bool) map[string]bool {
+	merged := make(map[string]bool, len(labels)+len(more))
+	for label := range labels {
+		merged[label] = true
+	}
+	for label := range more {
+		merged[label] = true
+	}
+	return merged
+}
+
+// gotosFitStructuredShapes reports whether lowerStmtListAfter places every
+// goto in stmts with the backward loop, forward block and backward state
+// machine shapes. It makes the same choices the lowering does, so a function
+// body can pick the state machine fallback before it is lowered.
+func gotosFitStructuredShapes(stmts []ast.Stmt, targets gotoTargets) bool {
+	if !stmtListHasGoto(stmts) {
+		return true
+	}
+	gotoSpans := backwardGotoLabelSpans(stmts)
+	gotoLabels := make(map[string]bool, len(gotoSpans))
+	for label := range gotoSpans {
+		gotoLabels[label] = true
+	}
+	forwardSpans := forwardGotoLabelSpans(stmts, gotoSpans)
+	for label, span := range forwardSpans {
+		span.label = label
+		forwardSpans[label] = span
+	}
+	for idx := 0; idx < len(stmts); idx++ {
+		if cluster, ok := gotoStateClusterAt(stmts, idx, true); ok {
+			if !gotoStateClusterFits(stmts, cluster, targets) {
+				return false
+			}
+			idx = cluster.endIdx
+			continue
+		}
+		if span, ok := leadingGotoBackwardLoopSpan(stmts, idx, gotoSpans); ok {
+			if !backwardGotoLoopFits(stmts, span.labelIdx, span.endIdx, targets.withLabels(gotoLabels)) {
+				return false
+			}
+			idx = span.endIdx
+			continue
+		}
+		if group, ok := forwardGotoLabelGroupAt(idx, forwardSpans); ok {
+			forwardTargets := targets.withLabels(group.forwardLabels)
+			if !gotosFitStructuredShapes(stmts[group.start:group.spans[0].labelIdx], forwardTargets) {
+				return false
+			}
+			for spanIdx := 1; spanIdx < len(group.spans); spanIdx++ {
+				prev := group.spans[spanIdx-1]
+				next := group.spans[spanIdx]
+				labeled, ok := stmts[prev.labelIdx].(*ast.LabeledStmt)
+				if !ok {
+					continue
+				}
+				segment := make([]ast.Stmt, 0, next.labelIdx-prev.labelIdx)
+				segment = append(segment, labeled.Stmt)
+				segment = append(segment, stmts[prev.labelIdx+1:next.labelIdx]...)
+				if !gotosFitStructuredShapes(segment, forwardTargets) {
+					return false
+				}
+			}
+			if labeled, ok := stmts[group.labelIdx].(*ast.LabeledStmt); ok && !gotoStmtFitsStructuredShapes(labeled.Stmt, targets) {
+				return false
+			}
+			idx = group.labelIdx
+			continue
+		}
+		if labeled, ok := stmts[idx].(*ast.LabeledStmt); ok {
+			if endIdx, ok := gotoSpans[safeIdentifier(labeled.Label.Name)]; ok {
+				if !backwardGotoLoopFits(stmts, idx, endIdx, targets.withLabels(gotoLabels)) {
+					return false
+				}
+				idx = endIdx
+				continue
+			}
+		}
+		if !gotoStmtFitsStructuredShapes(stmts[idx], targets) {
+			return false
+		}
+	}
+	return true
+}
+
+func gotoStateClusterFits(stmts []ast.Stmt, cluster gotoStateCluster, targets gotoTargets) bool {
+	labels := make(map[string]bool, len(cluster.labels))
+	for _, label := range cluster.labels {
+		labels[label.name] = true
+	}
+	targets = targets.withStates(labels)
+	if cluster.startIdx < cluster.firstLabelIdx && !gotosFitStructuredShapes(stmts[cluster.startIdx:cluster.firstLabelIdx], targets) {
+		return false
+	}
+	for idx, label := range cluster.labels {
+		nextIdx := cluster.endIdx + 1
+		if idx+1 < len(cluster.labels) {
+			nextIdx = cluster.labels[idx+1].idx
+		}
+		labeled, _ := stmts[label.idx].(*ast.LabeledStmt)
+		if labelUsedByBranch(labeled) {
+			if !gotoStmtFitsStructuredShapes(labeled, targets) || !gotosFitStructuredShapes(stmts[label.idx+1:nextIdx], targets) {
+				return false
+			}
+			continue
+		}
+		segment := make([]ast.Stmt, 0, nextIdx-label.idx)
+		segment = append(segment, labeled.Stmt)
+		segment = append(segment, stmts[label.idx+1:nextIdx]...)
+		if !gotosFitStructuredShapes(segment, targets) {
+			return false
+		}
+	}
+	return true
+}
+
+func backwardGotoLoopFits(stmts []ast.Stmt, labelIdx int, endIdx int, targets gotoTargets) bool {
+	labeled, ok := stmts[labelIdx].(*ast.LabeledStmt)
+	if !ok {
+		return true
+	}
+	return gotoStmtFitsStructuredShapes(labeled.Stmt, targets) && gotosFitStructuredShapes(stmts[labelIdx+1:endIdx+1], targets)
+}
+
+// gotoStmtFitsStructuredShapes checks the statement lists nested in stmt.
+// Function literals are left to their own bodies.
+func gotoStmtFitsStructuredShapes(stmt ast.Stmt, targets gotoTargets) bool {
+	switch typed := stmt.(type) {
+	case *ast.BranchStmt:
+		return typed.Tok != token.GOTO || typed.Label == nil || targets.has(safeIdentifier(typed.Label.Name))
+	case *ast.LabeledStmt:
+		return gotoStmtFitsStructuredShapes(typed.Stmt, targets)
+	case *ast.BlockStmt:
+		return gotosFitStructuredShapes(typed.List, targets)
+	case *ast.IfStmt:
+		return gotosFitStructuredShapes(typed.Body.List, targets) &&
+			(typed.Else == nil || gotoStmtFitsStructuredShapes(typed.Else, targets))
+	case *ast.ForStmt:
+		return gotosFitStructuredShapes(typed.Body.List, targets)
+	case *ast.RangeStmt:
+		return gotosFitStructuredShapes(typed.Body.List, targets)
+	case *ast.SwitchStmt:
+		return gotoClausesFitStructuredShapes(typed.Body, targets)
+	case *ast.TypeSwitchStmt:
+		return gotoClausesFitStructuredShapes(typed.Body, targets)
+	case *ast.SelectStmt:
+		return gotoClausesFitStructuredShapes(typed.Body, targets.withoutStates())
+	default:
+		return true
+	}
+}
+
+func gotoClausesFitStructuredShapes(body *ast.BlockStmt, targets gotoTargets) bool {
+	for _, clause := range body.List {
+		var stmts []ast.Stmt
+		switch typed := clause.(type) {
+		case *ast.CaseClause:
+			stmts = typed.Body
+		case *ast.CommClause:
+			stmts = typed.Body
+		}
+		if !gotosFitStructuredShapes(stmts, targets) {
+			return false
+		}
+	}
+	return true
 }
 
 func (o *LoweringOwner) lowerShortDeclStatementContext(
@@ -4859,7 +5390,71 @@ func (o *LoweringOwner) lowerGoStmt(ctx lowerFileContext, stmt *ast.GoStmt) (str
 	goCtx := ctx
 	goCtx.deferState = nil
 	call, diagnostics := o.lowerCallExpr(goCtx, stmt.Call)
-	return "queueMicrotask(async () => { " + call + " })", diagnostics
+	createdBy, createdAt := goStmtCreationSite(ctx, stmt)
+	return o.runtimeOwner.QualifiedHelper(RuntimeHelperGo) + "(async () => { " + call + " }, " +
+		strconv.Quote(createdBy) + ", " + strconv.Quote(createdAt) + ")", diagnostics
+}
+
+// goStmtCreationSite returns the function containing a go statement, named
+// as Go tracebacks name it, and the statement's file:line. The file is keyed
+// by package path rather than by absolute path so output is reproducible.
+func goStmtCreationSite(ctx lowerFileContext, stmt *ast.GoStmt) (string, string) {
+	if ctx.semPkg == nil {
+		return "", ""
+	}
+	pkgPath := ctx.semPkg.pkgPath
+	createdBy := pkgPath + ".init"
+	if ctx.semPkg.name == "main" {
+		createdBy = "main.init"
+	}
+	if ctx.file != nil {
+		for _, decl := range ctx.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || stmt.Pos() < fn.Pos() || stmt.Pos() >= fn.End() {
+				continue
+			}
+			createdBy = tracebackFuncName(ctx.semPkg, fn)
+			break
+		}
+	}
+	pos := sourcePos(ctx.semPkg.source, stmt.Pos())
+	if pos.file == "" {
+		return createdBy, ""
+	}
+	dir := strings.TrimSuffix(pkgPath, "_test")
+	return createdBy, dir + "/" + filepath.Base(pos.file) + ":" + strconv.Itoa(pos.line)
+}
+
+// tracebackFuncName names fn the way Go tracebacks do, such as main.main,
+// example.com/pkg.Run, or example.com/pkg.(*Server).Serve.
+func tracebackFuncName(pkg *semanticPackage, fn *ast.FuncDecl) string {
+	qualifier := pkg.pkgPath
+	if pkg.name == "main" {
+		qualifier = "main"
+	}
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return qualifier + "." + fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	pointer := false
+	if star, ok := recv.(*ast.StarExpr); ok {
+		pointer = true
+		recv = star.X
+	}
+	switch typed := recv.(type) {
+	case *ast.IndexExpr:
+		recv = typed.X
+	case *ast.IndexListExpr:
+		recv = typed.X
+	}
+	name := "?"
+	if ident, ok := recv.(*ast.Ident); ok {
+		name = ident.Name
+	}
+	if pointer {
+		name = "(*" + name + ")"
+	}
+	return qualifier + "." + name + "." + fn.Name.Name
 }
 
 func (o *LoweringOwner) lowerDeferStmt(ctx lowerFileContext, stmt *ast.DeferStmt) (string, []Diagnostic) {
@@ -4903,7 +5498,7 @@ func (o *LoweringOwner) lowerDeferStmt(ctx lowerFileContext, stmt *ast.DeferStmt
 }
 
 func (o *LoweringOwner) lowerAssignStmt(ctx lowerFileContext, stmt *ast.AssignStmt) ([]loweredStmt, []Diagnostic) {
-	if len(stmt.Rhs) == 1 && isChannelReceiveExpr(stmt.Rhs[0]) {
+	if len(stmt.Rhs) == 1 && isChannelReceiveExpr(stmt.Rhs[0]) && (stmt.Tok == token.ASSIGN || stmt.Tok == token.DEFINE) {
 		return o.lowerChannelReceiveAssignStmt(ctx, stmt)
 	}
 	if len(stmt.Lhs) == 1 && len(stmt.Rhs) == 1 {
@@ -6273,6 +6868,7 @@ func (o *LoweringOwner) lowerForStmt(ctx lowerFileContext, stmt *ast.ForStmt) (l
 		}
 		body, bodyDiagnostics := o.lowerBlock(bodyCtx, stmt.Body)
 		diagnostics = append(diagnostics, bodyDiagnostics...)
+		body = o.withLoopPreemptCheck(ctx, body)
 		text := "while (" + cond + ")"
 		if loopLabel != "" {
 			text = loopLabel + ": " + text
@@ -6305,6 +6901,7 @@ func (o *LoweringOwner) lowerForStmt(ctx lowerFileContext, stmt *ast.ForStmt) (l
 	}
 	body, bodyDiagnostics := o.lowerBlock(bodyCtx, stmt.Body)
 	diagnostics = append(diagnostics, bodyDiagnostics...)
+	body = o.withLoopPreemptCheck(ctx, body)
 	text := "for (" + init + "; " + cond + "; " + post + ")"
 	if loopLabel != "" {
 		text = loopLabel + ": " + text
@@ -6320,6 +6917,22 @@ func (o *LoweringOwner) lowerForStmt(ctx lowerFileContext, stmt *ast.ForStmt) (l
 	return forStmt, diagnostics
 }
 
+// withLoopPreemptCheck prepends the cooperative preemption check to a loop
+// body. The check only reads a counter until the runtime's time slice runs
+// out, so it is emitted only for async functions under --preempt-loops.
+func (o *LoweringOwner) withLoopPreemptCheck(ctx lowerFileContext, body []loweredStmt) []loweredStmt {
+	if !ctx.preemptLoops || !ctx.asyncFunction {
+		return body
+	}
+	check := loweredStmt{
+		text: "if (" + o.runtimeOwner.QualifiedHelper(RuntimeHelperPreemptDue) + "())",
+		children: []loweredStmt{{
+			text: "await " + o.runtimeOwner.QualifiedHelper(RuntimeHelperPreempt) + "()",
+		}},
+	}
+	return append([]loweredStmt{check}, body...)
+}
+
 func (o *LoweringOwner) lowerForInitStmt(ctx lowerFileContext, stmt ast.Stmt) (string, []Diagnostic) {
 	assign, ok := stmt.(*ast.AssignStmt)
 	if !ok {
@@ -6466,7 +7079,7 @@ func (o *LoweringOwner) lowerRangeStmt(ctx lowerFileContext, stmt *ast.RangeStmt
 		return loweredStmt{
 			hasBlock: true,
 			text:     "for (let " + keyName + " = 0; " + keyName + " < " + rangeValue + "; " + keyName + "++)",
-			children: body,
+			children: o.withLoopPreemptCheck(ctx, body),
 		}, diagnostics
 	}
 	if isMapType(rangeType) {
@@ -6490,7 +7103,7 @@ func (o *LoweringOwner) lowerRangeStmt(ctx lowerFileContext, stmt *ast.RangeStmt
 		return loweredStmt{
 			hasBlock: true,
 			text:     "for (" + binding + " [" + key + ", " + value + "] of " + rangeTarget + "?.entries() ?? [])",
-			children: children,
+			children: o.withLoopPreemptCheck(ctx, children),
 		}, diagnostics
 	}
 	if isStringType(rangeType) {
@@ -6512,7 +7125,7 @@ func (o *LoweringOwner) lowerRangeStmt(ctx lowerFileContext, stmt *ast.RangeStmt
 		return loweredStmt{
 			hasBlock: true,
 			text:     "for (" + binding + " [" + key + ", " + value + "] of " + o.runtimeOwner.QualifiedHelper(RuntimeHelperRangeString) + "(" + rangeValue + "))",
-			children: body,
+			children: o.withLoopPreemptCheck(ctx, body),
 		}, diagnostics
 	}
 	if isFunctionType(rangeType) {
@@ -6548,7 +7161,7 @@ func (o *LoweringOwner) lowerRangeStmt(ctx lowerFileContext, stmt *ast.RangeStmt
 	return loweredStmt{
 		hasBlock: true,
 		text:     "for (let " + rangeTarget + " = " + rangeTargetValue + ", " + indexName + " = 0; " + indexName + " < " + o.runtimeOwner.QualifiedHelper(RuntimeHelperLen) + "(" + rangeTarget + "); " + indexName + "++)",
-		children: children,
+		children: o.withLoopPreemptCheck(ctx, children),
 	}, diagnostics
 }
 
@@ -6686,7 +7299,7 @@ func (o *LoweringOwner) lowerSelectStmt(ctx lowerFileContext, stmt *ast.SelectSt
 		}
 		switch comm := clause.Comm.(type) {
 		case nil:
-			body, bodyDiagnostics := o.lowerStmtList(ctx.withLocalScope().withoutRangeBreak(), clause.Body)
+			body, bodyDiagnostics := o.lowerClauseBody(ctx.withLocalScope().withoutRangeBreak().withoutGotoState(), clause, clause.Body)
 			diagnostics = append(diagnostics, bodyDiagnostics...)
 			lowered.hasDefault = true
 			lowered.cases = append(lowered.cases, loweredSelectCase{
@@ -6697,7 +7310,7 @@ func (o *LoweringOwner) lowerSelectStmt(ctx lowerFileContext, stmt *ast.SelectSt
 		case *ast.SendStmt:
 			channel, channelDiagnostics := o.lowerExpr(ctx, comm.Chan)
 			value, valueDiagnostics := o.lowerExpr(ctx, comm.Value)
-			body, bodyDiagnostics := o.lowerStmtList(ctx.withLocalScope().withoutRangeBreak(), clause.Body)
+			body, bodyDiagnostics := o.lowerClauseBody(ctx.withLocalScope().withoutRangeBreak().withoutGotoState(), clause, clause.Body)
 			diagnostics = append(diagnostics, channelDiagnostics...)
 			diagnostics = append(diagnostics, valueDiagnostics...)
 			diagnostics = append(diagnostics, bodyDiagnostics...)
@@ -6711,7 +7324,7 @@ func (o *LoweringOwner) lowerSelectStmt(ctx lowerFileContext, stmt *ast.SelectSt
 			caseID++
 		case *ast.ExprStmt:
 			channel, prelude, receiveDiagnostics := o.lowerSelectReceiveComm(ctx, nil, comm.X, lowered.result)
-			body, bodyDiagnostics := o.lowerStmtList(ctx.withLocalScope().withoutRangeBreak(), clause.Body)
+			body, bodyDiagnostics := o.lowerClauseBody(ctx.withLocalScope().withoutRangeBreak().withoutGotoState(), clause, clause.Body)
 			diagnostics = append(diagnostics, receiveDiagnostics...)
 			diagnostics = append(diagnostics, bodyDiagnostics...)
 			lowered.cases = append(lowered.cases, loweredSelectCase{
@@ -6723,7 +7336,7 @@ func (o *LoweringOwner) lowerSelectStmt(ctx lowerFileContext, stmt *ast.SelectSt
 			caseID++
 		case *ast.AssignStmt:
 			channel, prelude, receiveDiagnostics := o.lowerSelectReceiveComm(ctx, comm, nil, lowered.result)
-			body, bodyDiagnostics := o.lowerStmtList(ctx.withLocalScope().withoutRangeBreak(), clause.Body)
+			body, bodyDiagnostics := o.lowerClauseBody(ctx.withLocalScope().withoutRangeBreak().withoutGotoState(), clause, clause.Body)
 			diagnostics = append(diagnostics, receiveDiagnostics...)
 			diagnostics = append(diagnostics, bodyDiagnostics...)
 			lowered.cases = append(lowered.cases, loweredSelectCase{
@@ -6900,14 +7513,19 @@ func (o *LoweringOwner) lowerSwitchStmt(ctx lowerFileContext, stmt *ast.SwitchSt
 			continue
 		}
 		bodyStmts := clause.Body
-		fallsThrough := false
+		var fallthroughStmt *ast.BranchStmt
 		if len(bodyStmts) != 0 {
 			if branch, ok := bodyStmts[len(bodyStmts)-1].(*ast.BranchStmt); ok && branch.Tok == token.FALLTHROUGH {
 				bodyStmts = bodyStmts[:len(bodyStmts)-1]
-				fallsThrough = true
+				fallthroughStmt = branch
 			}
 		}
-		body, bodyDiagnostics := o.lowerStmtList(ctx.withLocalScope().withoutRangeBreak().withSwitchBreak(), bodyStmts)
+		fallsThrough := fallthroughStmt != nil
+		body, bodyDiagnostics := o.lowerClauseBody(ctx.withLocalScope().withoutRangeBreak().withSwitchBreak(), clause, bodyStmts)
+		if fallsThrough {
+			// A fallthrough that starts a coverage block still counts it.
+			body = o.appendCoverCounter(ctx, fallthroughStmt, body)
+		}
 		diagnostics = append(diagnostics, bodyDiagnostics...)
 		values := make([]string, 0, len(clause.List))
 		for _, expr := range clause.List {
@@ -6924,7 +7542,7 @@ func (o *LoweringOwner) lowerSwitchStmt(ctx lowerFileContext, stmt *ast.SwitchSt
 				// value still matches; a plain number case (5) would fail
 				// 5n === 5 and fall through to default.
 				if tv, ok := ctx.semPkg.source.TypesInfo.Types[unwrapParenExpr(expr)]; ok {
-					if bigLit, ok := lowerConstantValueForType(tv.Value, tagType); ok {
+					if bigLit, ok := o.lowerConstantValueForType(tv.Value, tagType); ok {
 						lowered = bigLit
 					}
 				}
@@ -7016,7 +7634,7 @@ func (o *LoweringOwner) lowerTypeSwitchStmt(ctx lowerFileContext, stmt *ast.Type
 			diagnostics = append(diagnostics, loweringUnsupportedAt(ctx, clauseStmt, "statement", ctx.semPkg.pkgPath, "unsupported type switch clause"))
 			continue
 		}
-		body, bodyDiagnostics := o.lowerStmtList(ctx.withoutRangeBreak().withSwitchBreak(), clause.Body)
+		body, bodyDiagnostics := o.lowerClauseBody(ctx.withoutRangeBreak().withSwitchBreak(), clause, clause.Body)
 		diagnostics = append(diagnostics, bodyDiagnostics...)
 		if len(clause.List) == 0 {
 			switchIR.defaultBody = body
@@ -7027,6 +7645,17 @@ func (o *LoweringOwner) lowerTypeSwitchStmt(ctx lowerFileContext, stmt *ast.Type
 		tsTypes := make([]string, 0, len(clause.List))
 		for _, expr := range clause.List {
 			typ := ctx.semPkg.source.TypesInfo.TypeOf(expr)
+			if isUntypedNilType(typ) {
+				// case nil matches only a nil interface, never a typed nil.
+				// Its variable keeps the type of the switch guard.
+				types = append(types, "null")
+				if obj := ctx.semPkg.source.TypesInfo.Implicits[clause]; obj != nil {
+					tsTypes = append(tsTypes, o.tsTypeFor(ctx, obj.Type()))
+				} else {
+					tsTypes = append(tsTypes, "null")
+				}
+				continue
+			}
 			types = append(types, o.runtimeTypeInfoExpr(typ))
 			if typ == nil {
 				tsTypes = append(tsTypes, "any")
@@ -7339,6 +7968,13 @@ func (o *LoweringOwner) lowerInterfaceEqualityExpr(ctx lowerFileContext, expr *a
 	if !isInterfaceType(leftType) && !isInterfaceType(rightType) {
 		return "", false
 	}
+	// A pointer compared with an interface is converted first, so a nil
+	// pointer compares equal to an interface holding that typed nil.
+	if isInterfaceType(leftType) && isPointerType(rightType) {
+		right = o.lowerValueForTargetTypes(ctx, leftType, rightType, right, false)
+	} else if isInterfaceType(rightType) && isPointerType(leftType) {
+		left = o.lowerValueForTargetTypes(ctx, rightType, leftType, left, false)
+	}
 	value := o.runtimeOwner.QualifiedHelper(RuntimeHelperComparableEqual) + "(" + left + ", " + right + ")"
 	if expr.Op == token.NEQ {
 		value = "!" + value
@@ -7402,19 +8038,19 @@ func binaryOperandUsesTypeParam(ctx lowerFileContext, expr ast.Expr) bool {
 
 func (o *LoweringOwner) lowerExpr(ctx lowerFileContext, expr ast.Expr) (string, []Diagnostic) {
 	if value := ctx.semPkg.source.TypesInfo.Types[unwrapParenExpr(expr)].Value; value != nil && value.Kind() == constant.Complex {
-		if constantValue, ok := lowerConstantValue(value); ok {
+		if constantValue, ok := o.lowerConstantValue(value); ok {
 			return constantValue, nil
 		}
 	}
 	switch typed := expr.(type) {
 	case *ast.BasicLit:
-		return lowerBasicLit(typed), nil
+		return o.lowerBasicLit(typed), nil
 	case *ast.Ident:
 		return o.lowerIdent(ctx, typed, false), nil
 	case *ast.BinaryExpr:
 		if value := ctx.semPkg.source.TypesInfo.Types[typed].Value; value != nil {
 			if (typed.Op == token.SHL || typed.Op == token.SHR) && (value.Kind() == constant.Int || value.Kind() == constant.Float) {
-				if constantValue, ok := lowerConstantValue(value); ok {
+				if constantValue, ok := o.lowerConstantValue(value); ok {
 					return constantValue, nil
 				}
 			}
@@ -7556,7 +8192,7 @@ func (o *LoweringOwner) lowerExpr(ctx lowerFileContext, expr ast.Expr) (string,
 	}
 }
 
-func lowerBasicLit(lit *ast.BasicLit) string {
+func (o *LoweringOwner) lowerBasicLit(lit *ast.BasicLit) string {
 	if lit.Kind == token.CHAR {
 		value, err := strconv.Unquote(lit.Value)
 		if err != nil || value == "" {
@@ -7569,7 +8205,7 @@ func lowerBasicLit(lit *ast.BasicLit) string {
 		if err != nil {
 			return strconv.Quote(lit.Value)
 		}
-		return lowerGoStringLiteral(value)
+		return o.lowerGoStringLiteral(value)
 	}
 	if lit.Kind == token.INT && isLegacyOctalLiteral(lit.Value) {
 		digits := strings.TrimLeft(strings.ReplaceAll(lit.Value, "_", ""), "0")
@@ -7646,7 +8282,8 @@ func (o *LoweringOwner) lowerFuncLitArrowWithAsyncCalls(
 	deferState := &loweredDeferState{}
 	bodyCtx := ctx.withSignature(signature).withAsyncFunction(false).withDeferState(deferState).withoutRangeBranch()
 	asyncCompatibleParams := funcLiteralNeedsAsyncFunctionParamCalls(signature)
-	if allowAsyncCalls && (asyncCompatibleParams || funcLiteralUsesAwaitableCall(ctx, lit)) {
+	if allowAsyncCalls && (asyncCompatibleParams || funcLiteralUsesAwaitableCall(ctx, lit) ||
+		ctx.preemptLoops && ctx.model.preemptLoopLiteral(lit)) {
 		bodyCtx = bodyCtx.withAsyncFunction(true)
 	}
 	var params []loweredParam
@@ -7657,7 +8294,7 @@ func (o *LoweringOwner) lowerFuncLitArrowWithAsyncCalls(
 			params, paramBindings = o.appendLoweredParam(ctx, params, paramBindings, param, idx, asyncCompatibleParams)
 		}
 	}
-	body, diagnostics := o.lowerBlock(bodyCtx, lit.Body)
+	body, diagnostics := o.lowerFunctionBody(bodyCtx, lit.Body)
 	litFn := &loweredFunction{body: body, deferState: deferState}
 	if deferState.used {
 		litFn.recoverReturn = o.recoverReturnStmt(bodyCtx, signature)
@@ -7665,7 +8302,7 @@ func (o *LoweringOwner) lowerFuncLitArrowWithAsyncCalls(
 			deferState.recover = true
 		}
 	}
-	var rendered strings.Builder
+	rendered := tsBuilder{runtime: o.runtimeOwner}
 	renderStmts(&rendered, paramBindings, 1)
 	renderNamedResults(&rendered, o.lowerNamedResults(ctx, signature), 1)
 	renderBodyWithDefer(&rendered, litFn, 1)
@@ -7772,7 +8409,7 @@ func (o *LoweringOwner) lowerIdent(ctx lowerFileContext, ident *ast.Ident, raw b
 		return alias
 	}
 	if constObj, ok := obj.(*types.Const); ok && !raw {
-		if constValue, ok := lowerConstantValueForType(constObj.Val(), constObj.Type()); ok {
+		if constValue, ok := o.lowerConstantValueForType(constObj.Val(), constObj.Type()); ok {
 			return constValue
 		}
 	}
@@ -8004,7 +8641,7 @@ func (o *LoweringOwner) lowerCallExpr(ctx lowerFileContext, expr *ast.CallExpr)
 			}
 		}
 		if signature := genericFunctionSignature(ctx, fun); signature != nil {
-			args = append([]string{o.inferredGenericTypeArgsExpr(ctx, signature, expr.Args)}, args...)
+			args = append([]string{o.inferredGenericTypeArgsExpr(ctx, signature, fun, expr.Args)}, args...)
 		}
 		callee := o.lowerCallableExpr(ctx, fun, o.lowerIdent(ctx, fun, false))
 		call := callee + "(" + strings.Join(args, ", ") + ")"
@@ -8018,7 +8655,7 @@ func (o *LoweringOwner) lowerCallExpr(ctx lowerFileContext, expr *ast.CallExpr)
 					call := receiverExpr + "." + fun.Sel.Name + "(" + strings.Join(args, ", ") + ")"
 					return o.awaitCallIfNeeded(ctx, fun, call), diagnostics
 				}
-				methodArgs := append([]string{"__typeArgs", strconv.Quote(typeParam.Obj().Name()), strconv.Quote(fun.Sel.Name), receiverExpr}, args...)
+				methodArgs := append([]string{ctx.typeArgsRef(), strconv.Quote(typeParam.Obj().Name()), strconv.Quote(fun.Sel.Name), receiverExpr}, args...)
 				call := o.runtimeOwner.QualifiedHelper(RuntimeHelperCallGenericMethod) + "(" + strings.Join(methodArgs, ", ") + ")"
 				return o.awaitCallIfNeeded(ctx, fun, call), diagnostics
 			}
@@ -8039,7 +8676,7 @@ func (o *LoweringOwner) lowerCallExpr(ctx lowerFileContext, expr *ast.CallExpr)
 		}
 		selector, selectorDiagnostics := o.lowerSelectorExpr(ctx, fun)
 		if signature := genericFunctionSignature(ctx, fun); signature != nil && !o.callUsesOverridePackage(ctx, fun) {
-			args = append([]string{o.inferredGenericTypeArgsExpr(ctx, signature, expr.Args)}, args...)
+			args = append([]string{o.inferredGenericTypeArgsExpr(ctx, signature, fun, expr.Args)}, args...)
 		}
 		call := o.lowerCallableExpr(ctx, fun, selector) + "(" + strings.Join(args, ", ") + ")"
 		if unsafePackageFunction(ctx, fun, "Slice") {
@@ -8488,7 +9125,9 @@ func (o *LoweringOwner) lowerMakeExpr(ctx lowerFileContext, expr *ast.CallExpr)
 			}
 			args = append(args, strconv.Quote(hint))
 		}
-		if namedStructType(typed.Elem()) != nil && isStructValueType(typed.Elem()) {
+		_, typeParamElem := types.Unalias(typed.Elem()).(*types.TypeParam)
+		if (namedStructType(typed.Elem()) != nil && isStructValueType(typed.Elem())) ||
+			(typeParamElem && typeParamInScope(ctx, types.Unalias(typed.Elem()).(*types.TypeParam))) {
 			if capacity == "" {
 				args = append(args, "undefined")
 			}
@@ -8525,7 +9164,7 @@ func (o *LoweringOwner) lowerNewExpr(ctx lowerFileContext, expr *ast.CallExpr) (
 	}
 	typ := typeFromExpr(ctx, expr.Args[0])
 	if named := namedStructType(typ); named != nil {
-		return "new " + o.namedTypeExpr(ctx, named) + "()", nil
+		return o.newNamedStructExpr(ctx, named, ""), nil
 	}
 	return o.runtimeOwner.QualifiedHelper(RuntimeHelperVarRef) +
 		"<" + o.tsTypeFor(ctx, typ) + ">(" + o.lowerDeclarationZeroValueExpr(ctx, typ) + ")", nil
@@ -8566,6 +9205,11 @@ func (o *LoweringOwner) lowerConversionExpr(
 	if isNilExpr(expr.Args[0]) && isPointerType(targetType) {
 		return "null", diagnostics
 	}
+	if _, ok := types.Unalias(targetType).(*types.TypeParam); ok && isPointerType(sourceType) {
+		// Type parameter values keep their concrete representation; the
+		// dictionary supplies the methods.
+		return value, diagnostics
+	}
 	if isInterfaceType(targetType) {
 		return o.lowerValueForTarget(ctx, expr.Args[0], targetType, value), diagnostics
 	}
@@ -8633,7 +9277,7 @@ func (o *LoweringOwner) lowerConversionExpr(
 		}
 	}
 	if conversion, ok := o.lowerNamedStructConversion(ctx, expr.Args[0], targetType, sourceType, value); ok {
-		return renderNamedStructConversion(conversion), diagnostics
+		return renderNamedStructConversion(o.runtimeOwner, conversion), diagnostics
 	}
 	if isNumericType(targetType) {
 		if constantValue, ok := o.lowerNumericConstantExprForTarget(ctx, expr.Args[0], targetType); ok {
@@ -8963,7 +9607,11 @@ func (o *LoweringOwner) lowerNamedReceiverMethodCall(
 	}
 	receiverExpr, receiverDiagnostics := o.lowerNamedReceiverForMethod(ctx, selector.X, selection)
 	diagnostics = append(diagnostics, receiverDiagnostics...)
-	allArgs := append([]string{receiverExpr}, args...)
+	allArgs := []string{receiverExpr}
+	if typeArgs := o.methodTypeArgsExpr(ctx, receiver); typeArgs != "" {
+		allArgs = append(allArgs, typeArgs)
+	}
+	allArgs = append(allArgs, args...)
 	call := o.methodFunctionExpr(ctx, receiver, selection.Obj(), selector.Sel.Name) + "(" + strings.Join(allArgs, ", ") + ")"
 	return o.awaitCallIfNeeded(ctx, selector, call), diagnostics
 }
@@ -9159,13 +9807,22 @@ func (o *LoweringOwner) lowerSelectorExpr(ctx lowerFileContext, expr *ast.Select
 			if namedNonInterfaceNonStructType(namedReceiver) {
 				receiverExpr, diagnostics := o.lowerNamedReceiverForMethod(ctx, expr.X, selection)
 				methodExpr := o.methodFunctionExpr(ctx, namedReceiver, selection.Obj(), expr.Sel.Name)
-				return o.lowerMethodValueClosure(ctx, selection, receiverExpr, methodExpr, true), diagnostics
+				if typeArgs := o.methodTypeArgsExpr(ctx, namedReceiver); typeArgs != "" {
+					return o.lowerMethodValueClosure(ctx, selection, receiverExpr, methodExpr, "__receiver", typeArgs), diagnostics
+				}
+				return o.lowerMethodValueClosure(ctx, selection, receiverExpr, methodExpr, "__receiver"), diagnostics
+			}
+			if typeParam := receiverTypeParam(selection.Recv()); typeParam != nil && typeParamInScope(ctx, typeParam) {
+				receiver, diagnostics := o.lowerExpr(ctx, expr.X)
+				methodExpr := o.runtimeOwner.QualifiedHelper(RuntimeHelperCallGenericMethod)
+				return o.lowerMethodValueClosure(ctx, selection, receiver, methodExpr,
+					ctx.typeArgsRef(), strconv.Quote(typeParam.Obj().Name()), strconv.Quote(expr.Sel.Name), "__receiver"), diagnostics
 			}
 			receiver, diagnostics := o.lowerMethodReceiverExpr(ctx, expr.X, selection)
-			return o.lowerMethodValueClosure(ctx, selection, receiver, "__receiver."+expr.Sel.Name, false), diagnostics
+			return o.lowerMethodValueClosure(ctx, selection, receiver, "__receiver."+expr.Sel.Name), diagnostics
 		case types.MethodExpr:
 			if receiver := receiverNamedType(selection.Recv()); namedNonInterfaceNonStructType(receiver) {
-				return o.methodFunctionExpr(ctx, receiver, selection.Obj(), expr.Sel.Name), nil
+				return o.boundMethodFunctionExpr(ctx, receiver, selection.Obj(), expr.Sel.Name), nil
 			}
 			return o.lowerMethodExpressionClosure(ctx, selection), nil
 		case types.FieldVal:
@@ -9420,7 +10077,7 @@ func (o *LoweringOwner) lowerMethodValueClosure(
 	selection *types.Selection,
 	receiver string,
 	callee string,
-	includeReceiver bool,
+	leadingArgs ...string,
 ) string {
 	signature, _ := selection.Type().(*types.Signature)
 	var params []string
@@ -9435,9 +10092,7 @@ func (o *LoweringOwner) lowerMethodValueClosure(
 			args = append(args, name)
 		}
 	}
-	if includeReceiver {
-		args = append([]string{"__receiver"}, args...)
-	}
+	args = append(leadingArgs, args...)
 	closure := "((__receiver) => (" + strings.Join(params, ", ") + ") => " + callee + "(" + strings.Join(args, ", ") + "))(" + receiver + ")"
 	if signature == nil {
 		return closure
@@ -9464,6 +10119,11 @@ func (o *LoweringOwner) lowerMethodExpressionClosure(ctx lowerFileContext, selec
 	call := o.runtimeOwner.QualifiedHelper(RuntimeHelperPointerValue) +
 		"<" + o.namedTypeExpr(ctx, receiver) + ">(" + receiverName + ")." +
 		method.Name() + "(" + strings.Join(args, ", ") + ")"
+	if typeParam := receiverTypeParam(selection.Recv()); typeParam != nil && typeParamInScope(ctx, typeParam) {
+		call = o.runtimeOwner.QualifiedHelper(RuntimeHelperCallGenericMethod) + "(" + strings.Join(append([]string{
+			ctx.typeArgsRef(), strconv.Quote(typeParam.Obj().Name()), strconv.Quote(method.Name()), receiverName,
+		}, args...), ", ") + ")"
+	}
 	async := o.functionAsync(ctx, method)
 	prefix := ""
 	if async {
@@ -10459,11 +11119,12 @@ func (o *LoweringOwner) lowerCompositeLit(
 	lit *ast.CompositeLit,
 	markStruct bool,
 ) (string, []Diagnostic) {
-	if len(lit.Elts) == 0 {
-		if typeParam, ok := types.Unalias(ctx.semPkg.source.TypesInfo.TypeOf(lit)).(*types.TypeParam); ok {
-			if typeParamInScope(ctx, typeParam) {
-				return o.lowerDeclarationZeroValueExpr(ctx, typeParam), nil
-			}
+	if typeParam, ok := types.Unalias(ctx.semPkg.source.TypesInfo.TypeOf(lit)).(*types.TypeParam); ok && typeParamInScope(ctx, typeParam) {
+		if value, diagnostics, ok := o.lowerTypeParamCompositeLit(ctx, lit, typeParam); ok {
+			return value, diagnostics
+		}
+		if len(lit.Elts) == 0 {
+			return o.lowerDeclarationZeroValueExpr(ctx, typeParam), nil
 		}
 	}
 	named := namedStructType(ctx.semPkg.source.TypesInfo.TypeOf(lit))
@@ -10494,6 +11155,75 @@ func (o *LoweringOwner) lowerCompositeLit(
 	return "undefined", []Diagnostic{loweringUnsupportedAt(ctx, lit, "expression", ctx.semPkg.pkgPath, detail)}
 }
 
+// lowerTypeParamCompositeLit lowers T{...} through the core type of T's
+// constraint. Arrays, slices and maps share one representation across
+// instantiations. Struct fields are assigned onto the dictionary's zero value,
+// so named struct instantiations keep their class.
+func (o *LoweringOwner) lowerTypeParamCompositeLit(
+	ctx lowerFileContext,
+	lit *ast.CompositeLit,
+	typeParam *types.TypeParam,
+) (string, []Diagnostic, bool) {
+	switch core := typeParamCoreType(typeParam).(type) {
+	case *types.Array:
+		value, diagnostics := o.lowerArrayCompositeLit(ctx, lit, core)
+		return value, diagnostics, true
+	case *types.Slice:
+		value, diagnostics := o.lowerSliceCompositeLit(ctx, lit, core)
+		return value, diagnostics, true
+	case *types.Map:
+		value, diagnostics := o.lowerMapCompositeLit(ctx, lit, core)
+		return value, diagnostics, true
+	case *types.Struct:
+		if len(lit.Elts) == 0 {
+			return o.lowerDeclarationZeroValueExpr(ctx, typeParam), nil, true
+		}
+		fields, diagnostics := o.lowerAnonymousStructCompositeLit(ctx, lit, core)
+		return "Object.assign(" + o.lowerDeclarationZeroValueExpr(ctx, typeParam) + ", " + fields + ")", diagnostics, true
+	default:
+		return "", nil, false
+	}
+}
+
+// typeParamCoreType returns the underlying type shared by every type in the
+// type set of typeParam's constraint, or nil when there is none.
+func typeParamCoreType(typeParam *types.TypeParam) types.Type {
+	iface, _ := typeParam.Constraint().Underlying().(*types.Interface)
+	if iface == nil {
+		return nil
+	}
+	return interfaceCoreType(iface)
+}
+
+func interfaceCoreType(iface *types.Interface) types.Type {
+	var core types.Type
+	for embedded := range iface.EmbeddedTypes() {
+		var terms []types.Type
+		switch typed := embedded.(type) {
+		case *types.Union:
+			for idx := range typed.Len() {
+				terms = append(terms, typed.Term(idx).Type().Underlying())
+			}
+		default:
+			if nested, ok := embedded.Underlying().(*types.Interface); ok {
+				if nestedCore := interfaceCoreType(nested); nestedCore != nil {
+					terms = append(terms, nestedCore)
+				}
+				break
+			}
+			terms = append(terms, embedded.Underlying())
+		}
+		for _, term := range terms {
+			if core == nil {
+				core = term
+			} else if !types.Identical(core, term) {
+				return nil
+			}
+		}
+	}
+	return core
+}
+
 func (o *LoweringOwner) lowerStructCompositeLit(
 	ctx lowerFileContext,
 	lit *ast.CompositeLit,
@@ -10540,10 +11270,11 @@ func (o *LoweringOwner) lowerStructCompositeLit(
 		fields = append(fields, fieldName+": "+value)
 	}
 
-	expr := "new " + o.namedTypeExpr(ctx, named) + "()"
+	init := ""
 	if len(fields) != 0 {
-		expr = "new " + o.namedTypeExpr(ctx, named) + "({" + strings.Join(fields, ", ") + "})"
+		init = "{" + strings.Join(fields, ", ") + "}"
 	}
+	expr := o.newNamedStructExpr(ctx, named, init)
 	if markStruct {
 		expr = o.runtimeOwner.QualifiedHelper(RuntimeHelperMarkAsStructValue) + "(" + expr + ")"
 	}
@@ -10830,7 +11561,7 @@ func (o *LoweringOwner) lowerValueForTarget(
 	return o.lowerValueForTargetTypes(ctx, targetType, sourceType, value, shouldCloneStructValue(expr))
 }
 
-func lowerRealNumericConstantExpr(ctx lowerFileContext, expr ast.Expr) (string, bool) {
+func (o *LoweringOwner) lowerRealNumericConstantExpr(ctx lowerFileContext, expr ast.Expr) (string, bool) {
 	if ctx.semPkg == nil || ctx.semPkg.source == nil {
 		return "", false
 	}
@@ -10846,7 +11577,7 @@ func lowerRealNumericConstantExpr(ctx lowerFileContext, expr ast.Expr) (string,
 		if constant.BitLen(tv.Value) <= 53 {
 			return "", false
 		}
-		return lowerConstantValue(tv.Value)
+		return o.lowerConstantValue(tv.Value)
 	default:
 		return "", false
 	}
@@ -10874,7 +11605,7 @@ func (o *LoweringOwner) lowerNumericConstantExprForTarget(ctx lowerFileContext,
 			}
 		}
 	}
-	return lowerRealNumericConstantExpr(ctx, expr)
+	return o.lowerRealNumericConstantExpr(ctx, expr)
 }
 
 // numericConversionKeepsLiteral reports whether a conversion to a named
@@ -10939,6 +11670,14 @@ func (o *LoweringOwner) lowerValueForTargetTypes(
 	if isFunctionType(targetType) && isUntypedNilType(sourceType) {
 		return "(" + value + " as " + o.tsTypeFor(ctx, targetType) + ")"
 	}
+	if typeParam, ok := types.Unalias(sourceType).(*types.TypeParam); ok && typeParamInScope(ctx, typeParam) {
+		if _, targetTypeParam := types.Unalias(targetType).(*types.TypeParam); !targetTypeParam && isInterfaceType(targetType) {
+			// The dynamic type of a type-parameter value is only known from
+			// the instantiation's dictionary.
+			return o.runtimeOwner.QualifiedHelper(RuntimeHelperGenericInterfaceValue) +
+				"<" + o.tsTypeFor(ctx, targetType) + ">(" + ctx.typeArgsRef() + ", " + strconv.Quote(typeParam.Obj().Name()) + ", " + value + ")"
+		}
+	}
 	if isBuiltinErrorType(targetType) {
 		if wrapper := o.lowerPrimitiveErrorWrapper(ctx, sourceType, value); wrapper != "" {
 			return wrapper
@@ -11086,7 +11825,7 @@ func (o *LoweringOwner) lowerPrimitiveErrorWrapper(ctx lowerFileContext, sourceT
 	}
 	return o.runtimeOwner.QualifiedHelper(RuntimeHelperNamedValueInterfaceValue) +
 		"<$.GoError>(" + value + ", " + strconv.Quote(goRuntimeTypeString(sourceType)) +
-		", {\"Error\": " + o.methodFunctionExpr(ctx, named, fn, "Error") + "}, " +
+		", {\"Error\": " + o.boundMethodFunctionExpr(ctx, named, fn, "Error") + "}, " +
 		o.runtimeTypeInfoExpr(sourceType) + ")"
 }
 
@@ -11107,7 +11846,11 @@ func (o *LoweringOwner) lowerZeroValueExprFor(ctx lowerFileContext, typ types.Ty
 		if crossPackageUnexportedNamedType(ctx, named) {
 			return "undefined as any"
 		}
-		return o.runtimeOwner.QualifiedHelper(RuntimeHelperMarkAsStructValue) + "(new " + o.namedTypeExpr(ctx, named) + "())"
+		return o.runtimeOwner.QualifiedHelper(RuntimeHelperMarkAsStructValue) + "(" + o.newNamedStructExpr(ctx, named, "") + ")"
+	}
+	if typeParam, ok := types.Unalias(typ).(*types.TypeParam); ok && typeParamInScope(ctx, typeParam) {
+		return o.runtimeOwner.QualifiedHelper(RuntimeHelperGenericZero) +
+			"(" + ctx.typeArgsRef() + ", " + strconv.Quote(typeParam.Obj().Name()) + ", " + zeroValueExpr(typ) + ")"
 	}
 	switch typed := types.Unalias(typ).Underlying().(type) {
 	case *types.Basic:
@@ -11146,6 +11889,38 @@ func (o *LoweringOwner) lowerZeroValueExprFor(ctx lowerFileContext, typ types.Ty
 	}
 }
 
+// newNamedStructExpr constructs named from the init object literal. Instances
+// of generic structs also receive the type-argument dictionary of named.
+func (o *LoweringOwner) newNamedStructExpr(ctx lowerFileContext, named *types.Named, init string) string {
+	typeArgs := o.namedTypeArgsExpr(ctx, named)
+	if typeArgs == "" {
+		return "new " + o.namedTypeExpr(ctx, named) + "(" + init + ")"
+	}
+	if init == "" {
+		init = "undefined"
+	}
+	return "new " + o.namedTypeExpr(ctx, named) + "(" + init + ", " + typeArgs + ")"
+}
+
+// namedTypeArgsExpr returns the dictionary of an instantiated generic type,
+// passed to a struct's constructor or to the methods of a non-struct type.
+// Handwritten override types do not take one.
+func (o *LoweringOwner) namedTypeArgsExpr(ctx lowerFileContext, named *types.Named) string {
+	if o.receiverUsesOverridePackage(named) {
+		return ""
+	}
+	typeArgs := named.TypeArgs()
+	typeParams := named.Origin().TypeParams()
+	if typeArgs == nil || typeArgs.Len() == 0 || typeArgs.Len() != typeParams.Len() {
+		return ""
+	}
+	entries := make([]string, 0, typeArgs.Len())
+	for idx := range typeArgs.Len() {
+		entries = append(entries, typeParams.At(idx).Obj().Name()+": "+o.genericTypeDescriptorExpr(ctx, typeArgs.At(idx)))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
 func (o *LoweringOwner) lowerDeclarationZeroValueExpr(ctx lowerFileContext, typ types.Type) string {
 	if isFunctionType(typ) {
 		return "null as " + o.tsFunctionZeroValueTypeFor(ctx, typ)
@@ -11162,7 +11937,7 @@ func (o *LoweringOwner) lowerDeclarationZeroValueExpr(ctx lowerFileContext, typ
 		return zeroValueExpr(typ)
 	}
 	return o.runtimeOwner.QualifiedHelper(RuntimeHelperGenericZero) +
-		"(__typeArgs, " + strconv.Quote(typeParam.Obj().Name()) + ", " + zeroValueExpr(typ) + ")"
+		"(" + ctx.typeArgsRef() + ", " + strconv.Quote(typeParam.Obj().Name()) + ", " + zeroValueExpr(typ) + ")"
 }
 
 func (o *LoweringOwner) tsFunctionZeroValueTypeFor(ctx lowerFileContext, typ types.Type) string {
@@ -11190,7 +11965,7 @@ func (o *LoweringOwner) runtimeTypeAssertInfoExpr(ctx lowerFileContext, typ type
 func (o *LoweringOwner) runtimeTypeAssertInfoExprWithSeen(ctx lowerFileContext, typ types.Type, seen map[types.Type]bool) string {
 	typeParam, ok := types.Unalias(typ).(*types.TypeParam)
 	if ok && typeParamInScope(ctx, typeParam) {
-		return "__typeArgs?.[" + strconv.Quote(typeParam.Obj().Name()) + "]?.type ?? " +
+		return ctx.typeArgsRef() + "?.[" + strconv.Quote(typeParam.Obj().Name()) + "]?.type ?? " +
 			o.runtimeTypeInfoExpr(typ)
 	}
 
@@ -11954,6 +12729,52 @@ func pointerToNamedStructType(typ types.Type) *types.Named {
 	return namedStructType(pointer.Elem())
 }
 
+// receiverStructType returns the named struct type of a T or *T receiver.
+func receiverStructType(typ types.Type) *types.Named {
+	if named := namedStructType(typ); named != nil {
+		return named
+	}
+	return pointerToNamedStructType(typ)
+}
+
+// structReceiverTypeArgsSource reads the dictionary a generic struct instance
+// was created with. Pointer receivers may be bound to a VarRef holding the
+// instance, so they read the dictionary through it.
+func (o *LoweringOwner) structReceiverTypeArgsSource(pointerRecv bool) string {
+	if pointerRecv {
+		return "(" + o.runtimeOwner.QualifiedHelper(RuntimeHelperIsVarRef) + "(this) ? (this as any).value : this)?.__typeArgs"
+	}
+	return "this.__typeArgs"
+}
+
+// receiverTypeArgsBinding binds __typeArgs in a method of a generic type to
+// the dictionary read from source. The dictionary is keyed by the type
+// declaration's parameter names, so receivers that rename them get a
+// remapped copy.
+func receiverTypeArgsBinding(recvTypeParams *types.TypeParamList, receiver *types.Named, source string) string {
+	if !receiverRenamesTypeParams(recvTypeParams, receiver) {
+		return "const __typeArgs = " + source
+	}
+	declTypeParams := receiver.Origin().TypeParams()
+	entries := make([]string, 0, recvTypeParams.Len())
+	for idx := range recvTypeParams.Len() {
+		entries = append(entries, recvTypeParams.At(idx).Obj().Name()+": "+source+"?.["+strconv.Quote(declTypeParams.At(idx).Obj().Name())+"]")
+	}
+	return "const __typeArgs = { " + strings.Join(entries, ", ") + " } as $.GenericTypeArgs"
+}
+
+// receiverRenamesTypeParams reports whether a method's receiver names the
+// type parameters differently from the type declaration.
+func receiverRenamesTypeParams(recvTypeParams *types.TypeParamList, receiver *types.Named) bool {
+	declTypeParams := receiver.Origin().TypeParams()
+	for idx := range recvTypeParams.Len() {
+		if idx < declTypeParams.Len() && recvTypeParams.At(idx).Obj().Name() != declTypeParams.At(idx).Obj().Name() {
+			return true
+		}
+	}
+	return false
+}
+
 func namedNonStructType(typ types.Type) *types.Named {
 	named, _ := types.Unalias(typ).(*types.Named)
 	if named == nil {
@@ -12707,6 +13528,9 @@ func (o *LoweringOwner) genericTypeArgsExpr(ctx lowerFileContext, callee ast.Exp
 	if signature == nil {
 		return "undefined"
 	}
+	if typeArgs, ok := o.instanceGenericTypeArgsExpr(ctx, signature, callee); ok {
+		return typeArgs
+	}
 	typeParams := signature.TypeParams()
 	entries := make([]string, 0, typeParams.Len())
 	for idx := range typeParams.Len() {
@@ -12728,12 +13552,16 @@ func (o *LoweringOwner) genericTypeArgsExpr(ctx lowerFileContext, callee ast.Exp
 func (o *LoweringOwner) inferredGenericTypeArgsExpr(
 	ctx lowerFileContext,
 	signature *types.Signature,
+	callee ast.Expr,
 	args []ast.Expr,
 ) string {
 	typeParams := signature.TypeParams()
 	if typeParams == nil || typeParams.Len() == 0 {
 		return "undefined"
 	}
+	if typeArgs, ok := o.instanceGenericTypeArgsExpr(ctx, signature, callee); ok {
+		return typeArgs
+	}
 	inferred := make(map[*types.TypeParam]types.Type)
 	params := signature.Params()
 	if params != nil {
@@ -12758,6 +13586,36 @@ func (o *LoweringOwner) inferredGenericTypeArgsExpr(
 	return "{" + strings.Join(entries, ", ") + "}"
 }
 
+// instanceGenericTypeArgsExpr renders the type-argument dictionary recorded by
+// the type checker for the instantiation at callee. It covers explicit,
+// partially explicit and constraint-inferred type arguments alike.
+func (o *LoweringOwner) instanceGenericTypeArgsExpr(
+	ctx lowerFileContext,
+	signature *types.Signature,
+	callee ast.Expr,
+) (string, bool) {
+	var ident *ast.Ident
+	switch typed := ast.Unparen(callee).(type) {
+	case *ast.Ident:
+		ident = typed
+	case *ast.SelectorExpr:
+		ident = typed.Sel
+	}
+	if ident == nil || ctx.semPkg == nil || ctx.semPkg.source == nil {
+		return "", false
+	}
+	instance, ok := ctx.semPkg.source.TypesInfo.Instances[ident]
+	typeParams := signature.TypeParams()
+	if !ok || instance.TypeArgs == nil || instance.TypeArgs.Len() != typeParams.Len() {
+		return "", false
+	}
+	entries := make([]string, 0, typeParams.Len())
+	for idx := range typeParams.Len() {
+		entries = append(entries, typeParams.At(idx).Obj().Name()+": "+o.genericTypeDescriptorExpr(ctx, instance.TypeArgs.At(idx)))
+	}
+	return "{" + strings.Join(entries, ", ") + "}", true
+}
+
 func (o *LoweringOwner) inferGenericTypeArg(
 	inferred map[*types.TypeParam]types.Type,
 	paramType types.Type,
@@ -12816,12 +13674,17 @@ func namedOriginsEqual(a, b *types.Named) bool {
 
 func (o *LoweringOwner) genericTypeDescriptorExpr(ctx lowerFileContext, typ types.Type) string {
 	if typeParam, ok := types.Unalias(typ).(*types.TypeParam); ok && typeParamInScope(ctx, typeParam) {
-		return "__typeArgs?.[" + strconv.Quote(typeParam.Obj().Name()) + "] ?? { type: " +
-			o.runtimeTypeInfoExpr(typ) + ", zero: () => " + o.lowerZeroValueExprFor(ctx, typ) + " }"
+		return ctx.typeArgsRef() + "?.[" + strconv.Quote(typeParam.Obj().Name()) + "] ?? { type: " +
+			o.runtimeTypeInfoExpr(typ) + ", zero: () => " + zeroValueExpr(typ) + " }"
+	}
+	zero := o.lowerZeroValueExprFor(ctx, typ)
+	if strings.HasPrefix(zero, "{") {
+		// An object literal arrow body needs parentheses.
+		zero = "(" + zero + ")"
 	}
 	parts := []string{
 		"type: " + o.runtimeTypeInfoExpr(typ),
-		"zero: () => " + o.lowerZeroValueExprFor(ctx, typ),
+		"zero: () => " + zero,
 	}
 	if methods := o.genericMethodDescriptors(ctx, typ); methods != "" {
 		parts = append(parts, "methods: "+methods)
@@ -12862,7 +13725,11 @@ func (o *LoweringOwner) genericMethodDescriptorsForType(
 			continue
 		}
 		if namedStructType(named) != nil || isInterfaceType(named) {
-			methods = append(methods, method.Name()+": (receiver: any, ...args: any[]) => receiver."+method.Name()+"(...args)")
+			receiver := "receiver"
+			if isPointerType(methodSetType) {
+				receiver = o.runtimeOwner.QualifiedHelper(RuntimeHelperPointerValue) + "<any>(receiver)"
+			}
+			methods = append(methods, method.Name()+": (receiver: any, ...args: any[]) => "+receiver+"."+method.Name()+"(...args)")
 			continue
 		}
 		receiver := "receiver"
@@ -12878,6 +13745,9 @@ func (o *LoweringOwner) genericMethodDescriptorsForType(
 				}
 			}
 		}
+		if typeArgs := o.methodTypeArgsExpr(ctx, named); typeArgs != "" {
+			receiver += ", " + typeArgs
+		}
 		methods = append(methods, method.Name()+": (receiver: any, ...args: any[]) => "+
 			"("+o.methodFunctionExpr(ctx, named.Origin(), method, method.Name())+" as any)("+receiver+", ...args)")
 	}
@@ -12909,6 +13779,11 @@ func (o *LoweringOwner) genericMethodSignatureDescriptors(typ types.Type) string
 func genericMethodSetDescriptorTarget(typ types.Type) (*types.Named, types.Type) {
 	named, _ := types.Unalias(typ).(*types.Named)
 	if named == nil {
+		if pointer, ok := types.Unalias(typ).(*types.Pointer); ok {
+			if elem := namedStructType(pointer.Elem()); elem != nil {
+				return elem, typ
+			}
+		}
 		return namedNonStructMethodSetType(typ)
 	}
 	return named, named
@@ -12966,6 +13841,33 @@ func (o *LoweringOwner) methodFunctionExpr(
 	return name
 }
 
+// methodTypeArgsExpr returns the dictionary passed after the receiver to a
+// method of a generic non-struct type such as List[T any] []T, or "" when
+// the method of receiver takes none.
+func (o *LoweringOwner) methodTypeArgsExpr(ctx lowerFileContext, receiver *types.Named) string {
+	if receiver == nil || namedStructType(receiver) != nil {
+		return ""
+	}
+	return o.namedTypeArgsExpr(ctx, receiver)
+}
+
+// boundMethodFunctionExpr is methodFunctionExpr for callers that invoke the
+// method function with the receiver and arguments only. Methods of generic
+// types get a closure that passes the receiver's dictionary.
+func (o *LoweringOwner) boundMethodFunctionExpr(
+	ctx lowerFileContext,
+	receiver *types.Named,
+	obj types.Object,
+	method string,
+) string {
+	fn := o.methodFunctionExpr(ctx, receiver, obj, method)
+	typeArgs := o.methodTypeArgsExpr(ctx, receiver)
+	if typeArgs == "" {
+		return fn
+	}
+	return "((receiver: any, ...args: any[]) => (" + fn + " as any)(receiver, " + typeArgs + ", ...args))"
+}
+
 func (o *LoweringOwner) namedTypeExpr(ctx lowerFileContext, named *types.Named) string {
 	if named == nil || named.Obj() == nil {
 		return "unknown"
@@ -94,6 +94,9 @@ func BenchmarkLoweringFile(b *testing.B) {
 			false,
 			false,
 			"",
+			false,
+			false,
+			nil,
 		); diagnosticsHaveErrors(diagnostics) {
 			b.Fatal(diagnostics)
 		}
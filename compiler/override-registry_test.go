@@ -935,6 +935,7 @@ func TestOverrideParityVerifierReportsDeferredAtPhaseClose(t *testing.T) {
 		"runtime",
 		"runtime/pprof",
 		"runtime/trace",
+		"testing/synctest",
 	)
 	if diagnosticsHaveErrors(diagnostics) {
 		t.Fatalf("override ledgers still contain deferred entries: %#v", diagnostics)
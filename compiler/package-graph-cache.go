@@ -0,0 +1,348 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// maxWarmPackageGraphs bounds how many distinct package graph loads a warm
+// owner keeps. The least recently used graph is dropped first.
+const maxWarmPackageGraphs = 8
+
+// PackageGraphChanges lists sources that changed on disk since the warm
+// package graphs that read them were loaded.
+type PackageGraphChanges struct {
+	// Files are the changed, added, or removed source and module files.
+	Files []string
+	// Packages are the package paths that own a changed file or import,
+	// directly or transitively, a package that does.
+	Packages []string
+}
+
+// packageGraphCache holds loaded package graphs keyed by load identity. An
+// entry is reused only while every file it was loaded from keeps its content
+// hash and every package directory keeps its Go file listing.
+type packageGraphCache struct {
+	entries map[string]*packageGraphCacheEntry
+	tick    uint64
+}
+
+type packageGraphCacheEntry struct {
+	graph       *PackageGraph
+	diagnostics []Diagnostic
+	fingerprint *packageGraphFingerprint
+	used        uint64
+}
+
+// packageGraphFingerprint records the on-disk state a graph was loaded from.
+type packageGraphFingerprint struct {
+	// loadStart is when the graph load began.
+	loadStart time.Time
+	// files maps each file to its stamp. Absent module files have a zero stamp.
+	files map[string]packageGraphFileStamp
+	// dirs maps each package directory to its sorted .go file names.
+	dirs map[string][]string
+	// owners maps files and package directories to their package paths.
+	owners map[string][]string
+	// importers maps package paths to the graph packages that import them.
+	importers map[string][]string
+}
+
+// packageGraphFileStamp identifies a file's content by its sha256 hash. The
+// size and modification time are a fast path: the file is rehashed only when
+// they change or the modification time is not before the load start.
+// GOROOT and module cache files are read-only, so they are not hashed, and a
+// file modified while its graph was loading has no hash because the loaded
+// content is unknown.
+type packageGraphFileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+func newPackageGraphCache() *packageGraphCache {
+	return &packageGraphCache{entries: make(map[string]*packageGraphCacheEntry)}
+}
+
+// lookup returns a cached graph for key when its sources are unchanged.
+func (c *packageGraphCache) lookup(key string) (*PackageGraph, []Diagnostic, bool) {
+	entry := c.entries[key]
+	if entry == nil {
+		return nil, nil, false
+	}
+	if len(entry.fingerprint.changedFiles()) != 0 {
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+	c.tick++
+	entry.used = c.tick
+	return entry.graph, slices.Clone(entry.diagnostics), true
+}
+
+// store caches graph under key.
+func (c *packageGraphCache) store(key string, req *CompileRequest, graph *PackageGraph, diagnostics []Diagnostic, loadStart time.Time) {
+	fingerprint := newPackageGraphFingerprint(req, graph, loadStart)
+	if c.entries[key] == nil && len(c.entries) >= maxWarmPackageGraphs {
+		var oldestKey string
+		var oldest *packageGraphCacheEntry
+		for entryKey, entry := range c.entries {
+			if oldest == nil || entry.used < oldest.used {
+				oldestKey, oldest = entryKey, entry
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+	c.tick++
+	c.entries[key] = &packageGraphCacheEntry{
+		graph:       graph,
+		diagnostics: slices.Clone(diagnostics),
+		fingerprint: fingerprint,
+		used:        c.tick,
+	}
+}
+
+// changes reports the changed files and affected packages across entries.
+func (c *packageGraphCache) changes() *PackageGraphChanges {
+	files := make(map[string]bool)
+	packages := make(map[string]bool)
+	for _, entry := range c.entries {
+		changed := entry.fingerprint.changedFiles()
+		for _, file := range changed {
+			files[file] = true
+		}
+		for _, pkgPath := range entry.fingerprint.affectedPackages(changed) {
+			packages[pkgPath] = true
+		}
+	}
+	return &PackageGraphChanges{
+		Files:    sortedKeys(files),
+		Packages: sortedKeys(packages),
+	}
+}
+
+// packageGraphCacheKey identifies the package loader inputs of a request.
+// The package blocklist only filters diagnostics, so it is not part of it.
+func packageGraphCacheKey(req *CompileRequest, shape packageGraphLoadShape) string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(int(shape)))
+	b.WriteByte(0)
+	b.WriteString(req.Dir)
+	b.WriteByte(0)
+	b.WriteString(string(req.DependencyMode))
+	b.WriteByte(0)
+	b.WriteString(strconv.FormatBool(req.Tests))
+	for _, values := range [][]string{req.Patterns, req.BuildFlags} {
+		b.WriteByte(1)
+		for _, value := range values {
+			b.WriteString(value)
+			b.WriteByte(0)
+		}
+	}
+	return b.String()
+}
+
+func newPackageGraphFingerprint(req *CompileRequest, graph *PackageGraph, loadStart time.Time) *packageGraphFingerprint {
+	fingerprint := &packageGraphFingerprint{
+		loadStart: loadStart,
+		files:     make(map[string]packageGraphFileStamp),
+		dirs:      make(map[string][]string),
+		owners:    make(map[string][]string),
+		importers: make(map[string][]string),
+	}
+	moduleDirs := make(map[string]bool)
+	readOnly := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		files := slices.Concat(node.GoFiles, node.CompiledGoFiles)
+		pkg := graph.packagesByPath[node.PkgPath]
+		if pkg != nil {
+			files = slices.Concat(files, pkg.OtherFiles, pkg.IgnoredFiles)
+		}
+		for _, file := range files {
+			fingerprint.files[file] = packageGraphFileStamp{}
+			if readOnlyPackageGraphFile(pkg, file) {
+				readOnly[file] = true
+			}
+			fingerprint.addOwner(file, node.PkgPath)
+			fingerprint.addOwner(filepath.Dir(file), node.PkgPath)
+		}
+		for _, file := range node.GoFiles {
+			dir := filepath.Dir(file)
+			if _, ok := fingerprint.dirs[dir]; !ok {
+				fingerprint.dirs[dir] = goFileNames(dir)
+			}
+		}
+		for _, importPath := range node.Imports {
+			fingerprint.importers[importPath] = append(fingerprint.importers[importPath], node.PkgPath)
+		}
+		if node.ModuleDir != "" {
+			moduleDirs[node.ModuleDir] = true
+		}
+	}
+	for dir := range moduleDirs {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			fingerprint.files[filepath.Join(dir, name)] = packageGraphFileStamp{}
+		}
+	}
+	if dir, err := filepath.Abs(req.Dir); err == nil {
+		for {
+			for _, name := range []string{"go.work", "go.work.sum"} {
+				fingerprint.files[filepath.Join(dir, name)] = packageGraphFileStamp{}
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	for file := range fingerprint.files {
+		stamp := statPackageGraphFile(file)
+		if stamp.exists && !readOnly[file] && stamp.modTime.Before(loadStart) {
+			stamp.hash = hashPackageGraphFile(file)
+		}
+		fingerprint.files[file] = stamp
+	}
+	return fingerprint
+}
+
+// readOnlyPackageGraphFile reports whether file belongs to GOROOT or to a
+// versioned module in the module cache, whose content never changes.
+func readOnlyPackageGraphFile(pkg *packages.Package, file string) bool {
+	if pkg == nil || pkg.Module == nil {
+		root := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+		return build.Default.GOROOT != "" && strings.HasPrefix(file, root)
+	}
+	module := pkg.Module
+	if module.Replace != nil {
+		module = module.Replace
+	}
+	return !module.Main && module.Version != ""
+}
+
+func (f *packageGraphFingerprint) addOwner(path, pkgPath string) {
+	if !slices.Contains(f.owners[path], pkgPath) {
+		f.owners[path] = append(f.owners[path], pkgPath)
+	}
+}
+
+// changedFiles returns the files whose content, existence, or directory
+// membership differs from the fingerprint. Stamps whose size or mtime changed
+// but whose hash did not are refreshed in place.
+func (f *packageGraphFingerprint) changedFiles() []string {
+	var changed []string
+	for file, stamp := range f.files {
+		current := statPackageGraphFile(file)
+		if current.exists == stamp.exists && current.size == stamp.size && current.modTime.Equal(stamp.modTime) && current.modTime.Before(f.loadStart) {
+			continue
+		}
+		if current.exists && stamp.hash != "" && hashPackageGraphFile(file) == stamp.hash {
+			current.hash = stamp.hash
+			f.files[file] = current
+			continue
+		}
+		changed = append(changed, file)
+	}
+	for dir, names := range f.dirs {
+		current := goFileNames(dir)
+		for _, name := range names {
+			if !slices.Contains(current, name) {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+		for _, name := range current {
+			if !slices.Contains(names, name) {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+	}
+	slices.Sort(changed)
+	return slices.Compact(changed)
+}
+
+// affectedPackages returns the packages that own a changed file plus every
+// package in the graph that transitively imports one of them.
+func (f *packageGraphFingerprint) affectedPackages(changed []string) []string {
+	affected := make(map[string]bool)
+	var queue []string
+	for _, file := range changed {
+		owners := f.owners[file]
+		if len(owners) == 0 {
+			owners = f.owners[filepath.Dir(file)]
+		}
+		queue = append(queue, owners...)
+	}
+	for len(queue) != 0 {
+		pkgPath := queue[0]
+		queue = queue[1:]
+		if affected[pkgPath] {
+			continue
+		}
+		affected[pkgPath] = true
+		queue = append(queue, f.importers[pkgPath]...)
+	}
+	return sortedKeys(affected)
+}
+
+// statPackageGraphFile stamps file. A missing file or a directory has a zero
+// stamp.
+func statPackageGraphFile(file string) packageGraphFileStamp {
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		return packageGraphFileStamp{}
+	}
+	return packageGraphFileStamp{
+		exists:  true,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+	}
+}
+
+// hashPackageGraphFile returns the hex sha256 of file's content, or an empty
+// string when it cannot be read.
+func hashPackageGraphFile(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// goFileNames returns the sorted names of the .go files in dir.
+func goFileNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".go") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func sortedKeys(values map[string]bool) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
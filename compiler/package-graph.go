@@ -3,11 +3,16 @@ package compiler
 import (
 	"context"
 	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/pkg/errors"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -23,6 +28,9 @@ type PackageGraph struct {
 	NodesByPackagePath map[string]*PackageGraphNode
 
 	packagesByPath map[string]*packages.Package
+	// sources maps each parsed Go file to the content the loader parsed, so
+	// overlays and edits made after the load do not leak into the output.
+	sources map[string][]byte
 }
 
 // PackageGraphNode is one package in the loaded graph.
@@ -54,6 +62,8 @@ type PackageGraphNode struct {
 // PackageGraphOwner owns Go package loading and graph identity.
 type PackageGraphOwner struct {
 	overrideOwner *OverrideRegistryOwner
+	// warm caches loaded graphs across requests when KeepWarm was called.
+	warm *packageGraphCache
 }
 
 type packageGraphLoadShape int
@@ -82,6 +92,48 @@ func (o *PackageGraphOwner) LoadIdentity(ctx context.Context, req *CompileReques
 	return o.load(ctx, req, packageGraphLoadIdentity)
 }
 
+// LoadPackage loads the graph for a request whose patterns name one package
+// and returns that package. Load errors are returned as a CompileError.
+func (o *PackageGraphOwner) LoadPackage(ctx context.Context, req *CompileRequest) (*PackageGraphNode, []Diagnostic, error) {
+	graph, diagnostics := o.Load(ctx, req)
+	if diagnosticsHaveErrors(diagnostics) {
+		return nil, diagnostics, NewCompileError(diagnostics)
+	}
+	if graph == nil || len(graph.RequestedPackagePaths) != 1 {
+		count := 0
+		if graph != nil {
+			count = len(graph.RequestedPackagePaths)
+		}
+		return nil, diagnostics, errors.Errorf("pattern %s matched %d packages, want one", strings.Join(req.Patterns, " "), count)
+	}
+	pkg := graph.NodesByPackagePath[graph.RequestedPackagePaths[0]]
+	if pkg == nil {
+		return nil, diagnostics, errors.Errorf("package %s is missing from the package graph", graph.RequestedPackagePaths[0])
+	}
+	return pkg, diagnostics, nil
+}
+
+// KeepWarm makes the owner reuse loaded package graphs across requests with
+// the same loader inputs. A graph is reloaded once the content of a file it
+// was loaded from, the Go files in one of its package directories, or a
+// go.mod, go.sum, or go.work file changes. Files are rehashed only when their
+// size or modification time changed. KeepWarm is not safe for concurrent use
+// with Load.
+func (o *PackageGraphOwner) KeepWarm() {
+	if o.warm == nil {
+		o.warm = newPackageGraphCache()
+	}
+}
+
+// Changes reports the files that changed since the warm graphs were loaded
+// and the packages they affect. It is empty unless KeepWarm was called.
+func (o *PackageGraphOwner) Changes() *PackageGraphChanges {
+	if o.warm == nil {
+		return &PackageGraphChanges{}
+	}
+	return o.warm.changes()
+}
+
 func (o *PackageGraphOwner) load(ctx context.Context, req *CompileRequest, shape packageGraphLoadShape) (*PackageGraph, []Diagnostic) {
 	if err := ctx.Err(); err != nil {
 		return nil, []Diagnostic{{
@@ -90,7 +142,29 @@ func (o *PackageGraphOwner) load(ctx context.Context, req *CompileRequest, shape
 			Message:  err.Error(),
 		}}
 	}
+	if o.warm == nil {
+		graph, diagnostics := o.loadPackages(ctx, req, shape)
+		return graph, appendPackageBlocklistDiagnostics(graph, diagnostics, req.PackageBlocklist)
+	}
+
+	// A warm full graph also answers identity loads.
+	for _, keyShape := range []packageGraphLoadShape{shape, packageGraphLoadFull} {
+		if graph, diagnostics, ok := o.warm.lookup(packageGraphCacheKey(req, keyShape)); ok {
+			return graph, appendPackageBlocklistDiagnostics(graph, diagnostics, req.PackageBlocklist)
+		}
+	}
+	loadStart := time.Now()
+	graph, diagnostics := o.loadPackages(ctx, req, shape)
+	if graph != nil {
+		o.warm.store(packageGraphCacheKey(req, shape), req, graph, diagnostics, loadStart)
+	}
+	return graph, appendPackageBlocklistDiagnostics(graph, diagnostics, req.PackageBlocklist)
+}
 
+// loadPackages loads the graph and its package diagnostics. Blocklist
+// diagnostics are added by the caller so warm graphs can be shared across
+// blocklists.
+func (o *PackageGraphOwner) loadPackages(ctx context.Context, req *CompileRequest, shape packageGraphLoadShape) (*PackageGraph, []Diagnostic) {
 	cfg := &packages.Config{
 		Context:    ctx,
 		Dir:        req.Dir,
@@ -99,6 +173,14 @@ func (o *PackageGraphOwner) load(ctx context.Context, req *CompileRequest, shape
 		Tests:      req.Tests,
 		Mode:       packageGraphLoadMode(shape),
 	}
+	sources := make(map[string][]byte)
+	var sourcesMtx sync.Mutex
+	cfg.ParseFile = func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+		sourcesMtx.Lock()
+		sources[filename] = src
+		sourcesMtx.Unlock()
+		return parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+	}
 	pkgs, err := packages.Load(cfg, req.Patterns...)
 	if err != nil {
 		return nil, []Diagnostic{{
@@ -124,6 +206,7 @@ func (o *PackageGraphOwner) load(ctx context.Context, req *CompileRequest, shape
 		RequestedPatterns:     append([]string(nil), req.Patterns...),
 		NodesByPackagePath:    make(map[string]*PackageGraphNode),
 		packagesByPath:        make(map[string]*packages.Package),
+		sources:               sources,
 		RequestedPackagePaths: make([]string, 0, len(pkgs)),
 	}
 
@@ -169,12 +252,16 @@ func (o *PackageGraphOwner) load(ctx context.Context, req *CompileRequest, shape
 			Message:  "package graph did not contain any package nodes",
 		})
 	}
-	if len(req.PackageBlocklist) != 0 {
-		diagnostics = append(diagnostics, packageBlocklistDiagnostics(graph, req.PackageBlocklist)...)
-	}
 	return graph, diagnostics
 }
 
+func appendPackageBlocklistDiagnostics(graph *PackageGraph, diagnostics []Diagnostic, blocklist []string) []Diagnostic {
+	if graph == nil || len(blocklist) == 0 {
+		return diagnostics
+	}
+	return append(diagnostics, packageBlocklistDiagnostics(graph, blocklist)...)
+}
+
 func packageGraphLoadMode(shape packageGraphLoadShape) packages.LoadMode {
 	mode := packages.NeedName |
 		packages.NeedFiles |
@@ -425,6 +512,8 @@ func packageDiagnostics(pkg *packages.Package) []Diagnostic {
 			Code:     "goscript/package-graph:load-error",
 			Message:  "Go package contains load errors",
 			Detail:   pkgErr.Msg,
+			Stage:    DiagnosticStagePackageGraph,
+			Package:  pkg.PkgPath,
 		})
 	}
 	return diagnostics
@@ -108,6 +108,16 @@ func TestCompileRequestValidation(t *testing.T) {
 	}
 }
 
+func TestBuildTagsFlagsNormalizesTags(t *testing.T) {
+	flags := BuildTagsFlags([]string{"b,a", " c  a", ""})
+	if !slices.Equal(flags, []string{"-tags=a,b,c"}) {
+		t.Fatalf("unexpected build flags: %v", flags)
+	}
+	if flags := BuildTagsFlags([]string{" , "}); flags != nil {
+		t.Fatalf("expected no build flags, got %v", flags)
+	}
+}
+
 func TestPackageGraphLoadsRequestedPackage(t *testing.T) {
 	moduleDir := writePackageGraphFixture(t, map[string]string{
 		"go.mod":  "module example.test/graph\n\ngo 1.25.3\n",
@@ -133,6 +143,36 @@ func TestPackageGraphLoadsRequestedPackage(t *testing.T) {
 	}
 }
 
+func TestPackageGraphLoadPackageWantsOnePackage(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":      "module example.test/loadpkg\n\ngo 1.25.3\n",
+		"main.go":     "package main\nfunc main() {}\n",
+		"lib/lib.go":  "package lib\n",
+		"lib2/lib.go": "package lib2\n",
+	})
+	owner := NewPackageGraphOwner()
+	req := &CompileRequest{
+		Patterns:            []string{"./lib"},
+		Dir:                 moduleDir,
+		OutputPath:          filepath.Join(t.TempDir(), "out"),
+		DependencyMode:      DependencyModeRequested,
+		RuntimeEmissionMode: RuntimeEmissionModeEmit,
+	}
+	pkg, _, err := owner.LoadPackage(context.Background(), req)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if pkg.PkgPath != "example.test/loadpkg/lib" {
+		t.Fatalf("unexpected package: %s", pkg.PkgPath)
+	}
+
+	req.Patterns = []string{"./lib..."}
+	_, _, err = owner.LoadPackage(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "pattern ./lib... matched 2 packages, want one") {
+		t.Fatalf("expected a pattern match error, got %v", err)
+	}
+}
+
 func TestPackageGraphReportsLoadErrors(t *testing.T) {
 	moduleDir := writePackageGraphFixture(t, map[string]string{
 		"go.mod":  "module example.test/loaderr\n\ngo 1.25.3\n",
@@ -0,0 +1,15 @@
+package compiler
+
+// PackageTestExample describes one discovered Go example function that has an
+// output comment, so go test runs it.
+type PackageTestExample struct {
+	// Name is the Go ExampleXxx function name.
+	Name string
+	// PackagePath is the package variant that exports the function.
+	PackagePath string
+	// Output is the text of the example's output comment.
+	Output string
+	// Unordered reports an "Unordered output:" comment, whose lines may be
+	// printed in any order.
+	Unordered bool
+}
@@ -1,8 +1,10 @@
 package compiler
 
-// PackageTestFunction describes one discovered ordinary Go test function.
+// PackageTestFunction describes one discovered ordinary Go test function or
+// TestMain.
 type PackageTestFunction struct {
-	// Name is the Go TestXxx function name.
+	// Name is the Go TestXxx, BenchmarkXxx, FuzzXxx, or TestMain function
+	// name.
 	Name string
 	// PackagePath is the package variant that exports the function.
 	PackagePath string
@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"go/ast"
+	"go/doc"
 	"slices"
 	"strconv"
 	"strings"
@@ -30,6 +31,15 @@ type PackageTestGraphVariant struct {
 	Diagnostics []Diagnostic
 	// Tests are ordinary TestXxx functions discovered in this variant.
 	Tests []PackageTestFunction
+	// Benchmarks are BenchmarkXxx functions discovered in this variant.
+	Benchmarks []PackageTestFunction
+	// FuzzTargets are FuzzXxx functions discovered in this variant.
+	FuzzTargets []PackageTestFunction
+	// TestMain is the TestMain(m *testing.M) function of this variant, or nil.
+	TestMain *PackageTestFunction
+	// Examples are the ExampleXxx functions of this variant with an output
+	// comment.
+	Examples []PackageTestExample
 }
 
 func newPackageTestGraphVariant(pkg *packages.Package, diagnostics []Diagnostic) *PackageTestGraphVariant {
@@ -47,11 +57,60 @@ func newPackageTestGraphVariant(pkg *packages.Package, diagnostics []Diagnostic)
 		CompiledGoFiles: append([]string(nil), pkg.CompiledGoFiles...),
 		Imports:         imports,
 		Diagnostics:     append([]Diagnostic(nil), diagnostics...),
-		Tests:           discoverPackageTestFunctions(pkg),
+		Tests:           discoverPackageTestFunctions(pkg, "Test", "T"),
+		Benchmarks:      discoverPackageTestFunctions(pkg, "Benchmark", "B"),
+		FuzzTargets:     discoverPackageTestFunctions(pkg, "Fuzz", "F"),
+		TestMain:        discoverPackageTestMain(pkg),
+		Examples:        discoverPackageTestExamples(pkg),
 	}
 }
 
-func discoverPackageTestFunctions(pkg *packages.Package) []PackageTestFunction {
+// discoverPackageTestMain returns the TestMain(m *testing.M) function of pkg,
+// or nil when it has none.
+func discoverPackageTestMain(pkg *packages.Package) *PackageTestFunction {
+	if pkg == nil {
+		return nil
+	}
+	for _, file := range pkg.Syntax {
+		testingAliases := fileTestingAliases(file)
+		for _, decl := range file.Decls {
+			fn, _ := decl.(*ast.FuncDecl)
+			if fn == nil || fn.Name.Name != "TestMain" || !isTestFuncSignature(fn, testingAliases, "M") {
+				continue
+			}
+			return &PackageTestFunction{
+				Name:        fn.Name.Name,
+				PackagePath: packagePath(pkg),
+			}
+		}
+	}
+	return nil
+}
+
+// discoverPackageTestExamples returns the examples of pkg that go test runs:
+// those with an "Output:" or "Unordered output:" comment.
+func discoverPackageTestExamples(pkg *packages.Package) []PackageTestExample {
+	if pkg == nil {
+		return nil
+	}
+	var examples []PackageTestExample
+	for _, example := range doc.Examples(pkg.Syntax...) {
+		if example.Output == "" && !example.EmptyOutput {
+			continue
+		}
+		examples = append(examples, PackageTestExample{
+			Name:        "Example" + example.Name,
+			PackagePath: packagePath(pkg),
+			Output:      example.Output,
+			Unordered:   example.Unordered,
+		})
+	}
+	return examples
+}
+
+// discoverPackageTestFunctions returns the functions of pkg named prefixXxx
+// that take a *testing.<typeName> and return nothing.
+func discoverPackageTestFunctions(pkg *packages.Package, prefix string, typeName string) []PackageTestFunction {
 	if pkg == nil {
 		return nil
 	}
@@ -60,7 +119,7 @@ func discoverPackageTestFunctions(pkg *packages.Package) []PackageTestFunction {
 		testingAliases := fileTestingAliases(file)
 		for _, decl := range file.Decls {
 			fn, _ := decl.(*ast.FuncDecl)
-			if !isOrdinaryTestFuncDecl(fn, testingAliases) {
+			if !isOrdinaryTestFuncDecl(fn, testingAliases, prefix, typeName) {
 				continue
 			}
 			tests = append(tests, PackageTestFunction{
@@ -72,8 +131,14 @@ func discoverPackageTestFunctions(pkg *packages.Package) []PackageTestFunction {
 	return tests
 }
 
-func isOrdinaryTestFuncDecl(fn *ast.FuncDecl, testingAliases map[string]bool) bool {
-	if fn == nil || fn.Recv != nil || !isTestName(fn.Name.Name) || fn.Type == nil {
+func isOrdinaryTestFuncDecl(fn *ast.FuncDecl, testingAliases map[string]bool, prefix string, typeName string) bool {
+	return fn != nil && isTestName(fn.Name.Name, prefix) && isTestFuncSignature(fn, testingAliases, typeName)
+}
+
+// isTestFuncSignature reports whether fn is a plain function that takes a
+// *testing.<typeName> and returns nothing.
+func isTestFuncSignature(fn *ast.FuncDecl, testingAliases map[string]bool, typeName string) bool {
+	if fn.Recv != nil || fn.Type == nil {
 		return false
 	}
 	if fn.Type.Results != nil && len(fn.Type.Results.List) != 0 {
@@ -87,7 +152,7 @@ func isOrdinaryTestFuncDecl(fn *ast.FuncDecl, testingAliases map[string]bool) bo
 		return false
 	}
 	ptr, ok := param.Type.(*ast.StarExpr)
-	return ok && isTestingT(ptr.X, testingAliases)
+	return ok && isTestingType(ptr.X, testingAliases, typeName)
 }
 
 func fileTestingAliases(file *ast.File) map[string]bool {
@@ -106,23 +171,23 @@ func fileTestingAliases(file *ast.File) map[string]bool {
 	return aliases
 }
 
-func isTestingT(expr ast.Expr, aliases map[string]bool) bool {
+func isTestingType(expr ast.Expr, aliases map[string]bool, typeName string) bool {
 	switch typed := expr.(type) {
 	case *ast.SelectorExpr:
 		base, _ := typed.X.(*ast.Ident)
-		return base != nil && aliases[base.Name] && typed.Sel.Name == "T"
+		return base != nil && aliases[base.Name] && typed.Sel.Name == typeName
 	case *ast.Ident:
-		return aliases["."] && typed.Name == "T"
+		return aliases["."] && typed.Name == typeName
 	default:
 		return false
 	}
 }
 
-func isTestName(name string) bool {
-	if !strings.HasPrefix(name, "Test") || name == "Test" {
+func isTestName(name string, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) || name == prefix {
 		return false
 	}
-	for _, r := range strings.TrimPrefix(name, "Test") {
+	for _, r := range strings.TrimPrefix(name, prefix) {
 		return !unicode.IsLower(r)
 	}
 	return false
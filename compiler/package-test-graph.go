@@ -29,6 +29,11 @@ func (g *PackageTestGraph) PackageByPath(path string) *PackageTestGraphPackage {
 
 // LoadTestGraph builds package-scoped test graph facts for a validated request.
 func (o *PackageGraphOwner) LoadTestGraph(ctx context.Context, req *CompileRequest) (*PackageTestGraph, []Diagnostic) {
+	graph, diagnostics := o.loadTestGraph(ctx, req)
+	return graph, withDiagnosticStage(diagnostics, DiagnosticStagePackageGraph)
+}
+
+func (o *PackageGraphOwner) loadTestGraph(ctx context.Context, req *CompileRequest) (*PackageTestGraph, []Diagnostic) {
 	if err := ctx.Err(); err != nil {
 		return nil, []Diagnostic{{
 			Severity: DiagnosticSeverityError,
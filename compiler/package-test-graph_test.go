@@ -26,8 +26,30 @@ func TestPackageGraphOwnerLoadTestGraphFacts(t *testing.T) {
 			"",
 			"func TestAdd(t *testpkg.T) {}",
 			"func TestIgnoredBadSignature(t *badT) {}",
+			"func BenchmarkAdd(b *testpkg.B) {}",
+			"func Benchmarkadd(b *testpkg.B) {}",
+			"func FuzzAdd(f *testpkg.F) {}",
+			"func FuzzBadSignature(t *testpkg.T) {}",
+			"func TestMain(m *testpkg.M) {}",
 			"type badT struct{}",
 			"",
+			"func ExampleAdd() {",
+			"\tprintln(Add(1, 2))",
+			"\t// Output: 3",
+			"}",
+			"",
+			"func Example_unordered() {",
+			"\t// Unordered output:",
+			"\t// b",
+			"\t// a",
+			"}",
+			"",
+			"func Example_empty() {",
+			"\t// Output:",
+			"}",
+			"",
+			"func Example_compileOnly() {}",
+			"",
 		}, "\n"),
 		"external/value.go": strings.Join([]string{
 			"package external",
@@ -75,10 +97,30 @@ func TestPackageGraphOwnerLoadTestGraphFacts(t *testing.T) {
 	if len(same.SamePackageTests.Tests) != 1 || same.SamePackageTests.Tests[0].Name != "TestAdd" {
 		t.Fatalf("same-package test discovery should keep only ordinary tests: %#v", same.SamePackageTests.Tests)
 	}
+	if len(same.SamePackageTests.Benchmarks) != 1 || same.SamePackageTests.Benchmarks[0].Name != "BenchmarkAdd" {
+		t.Fatalf("same-package benchmark discovery should keep only BenchmarkXxx functions: %#v", same.SamePackageTests.Benchmarks)
+	}
+	if len(same.SamePackageTests.FuzzTargets) != 1 || same.SamePackageTests.FuzzTargets[0].Name != "FuzzAdd" {
+		t.Fatalf("same-package fuzz discovery should keep only FuzzXxx(*testing.F) functions: %#v", same.SamePackageTests.FuzzTargets)
+	}
+	if main := same.SamePackageTests.TestMain; main == nil || main.Name != "TestMain" || main.PackagePath != "example.test/testgraph/same" {
+		t.Fatalf("same-package TestMain discovery failed: %#v", main)
+	}
+	wantExamples := []PackageTestExample{
+		{Name: "ExampleAdd", PackagePath: "example.test/testgraph/same", Output: "3\n"},
+		{Name: "Example_empty", PackagePath: "example.test/testgraph/same"},
+		{Name: "Example_unordered", PackagePath: "example.test/testgraph/same", Output: "b\na\n", Unordered: true},
+	}
+	if !slices.Equal(same.SamePackageTests.Examples, wantExamples) {
+		t.Fatalf("same-package example discovery should keep only examples with output comments: %#v", same.SamePackageTests.Examples)
+	}
 	external := graph.PackageByPath("example.test/testgraph/external")
 	if external == nil || external.ExternalPackageTests == nil || external.SamePackageTests != nil || !external.HasTests() {
 		t.Fatalf("unexpected external-package facts: %#v", external)
 	}
+	if external.ExternalPackageTests.TestMain != nil {
+		t.Fatalf("unexpected external-package TestMain: %#v", external.ExternalPackageTests.TestMain)
+	}
 	notests := graph.PackageByPath("example.test/testgraph/notests")
 	if notests == nil || notests.HasTests() {
 		t.Fatalf("unexpected no-test package facts: %#v", notests)
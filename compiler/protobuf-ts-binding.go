@@ -288,7 +288,7 @@ func protobufTypeScriptBindingSafeIdentifier(name string) string {
 	}
 }
 
-func rewriteProtobufTypeScriptBindingFile(file *loweredFile, binding protobufTypeScriptBinding) {
+func rewriteProtobufTypeScriptBindingFile(file *loweredFile, binding protobufTypeScriptBinding, runtimeOwner *RuntimeContractOwner) {
 	if file == nil {
 		return
 	}
@@ -309,7 +309,7 @@ func rewriteProtobufTypeScriptBindingFile(file *loweredFile, binding protobufTyp
 			continue
 		}
 		if !binding.hasOneof {
-			rewriteProtobufTypeScriptBindingStruct(decl.structType, binding.sourcePath)
+			rewriteProtobufTypeScriptBindingStruct(decl.structType, binding.sourcePath, runtimeOwner)
 		}
 		messageName, ok := binding.messageNames[decl.structType.name]
 		if !ok {
@@ -465,7 +465,7 @@ func protobufSRPCHasGoScriptReplacement(sourcePath string) bool {
 	return err == nil
 }
 
-func rewriteProtobufTypeScriptBindingStruct(structType *loweredStruct, bindingSourcePath string) {
+func rewriteProtobufTypeScriptBindingStruct(structType *loweredStruct, bindingSourcePath string, runtimeOwner *RuntimeContractOwner) {
 	if structType == nil {
 		return
 	}
@@ -477,7 +477,7 @@ func rewriteProtobufTypeScriptBindingStruct(structType *loweredStruct, bindingSo
 		if structType.protobufPreserveJSON && protobufTypeScriptBindingJSONMethodName(method.name) {
 			continue
 		}
-		body := protobufTypeScriptBindingMethodBody(structType, method)
+		body := protobufTypeScriptBindingMethodBody(structType, method, runtimeOwner)
 		if body == "" {
 			continue
 		}
@@ -489,7 +489,7 @@ func rewriteProtobufTypeScriptBindingStruct(structType *loweredStruct, bindingSo
 	}
 }
 
-func protobufTypeScriptBindingMethodBody(structType *loweredStruct, method *loweredFunction) string {
+func protobufTypeScriptBindingMethodBody(structType *loweredStruct, method *loweredFunction, runtimeOwner *RuntimeContractOwner) string {
 	if structType == nil || method == nil {
 		return ""
 	}
@@ -499,7 +499,7 @@ func protobufTypeScriptBindingMethodBody(structType *loweredStruct, method *lowe
 	}
 	switch method.name {
 	case "CloneMessageVT":
-		return "return $.interfaceValue<protobuf_go_lite.CloneMessage | null>(protobuf_go_lite.CloneBoundMessage(" +
+		return "return " + runtimeOwner.QualifiedHelper(RuntimeHelperInterfaceValue) + "<protobuf_go_lite.CloneMessage | null>(protobuf_go_lite.CloneBoundMessage(" +
 			ctor + ", this) as any, " + strconvQuote("*"+structType.typeName) + ")"
 	case "CloneVT":
 		return "return protobuf_go_lite.CloneBoundMessage(" + ctor + ", this) as any"
@@ -518,7 +518,9 @@ func protobufTypeScriptBindingMethodBody(structType *loweredStruct, method *lowe
 	case "ProtoMessage":
 		return "return"
 	case "Reset":
-		return "$.assignStruct($.pointerValue<" + ctor + ">(this), $.markAsStructValue(new " + ctor + "()))"
+		return runtimeOwner.QualifiedHelper(RuntimeHelperAssignStruct) + "(" +
+			runtimeOwner.QualifiedHelper(RuntimeHelperPointerValue) + "<" + ctor + ">(this), " +
+			runtimeOwner.QualifiedHelper(RuntimeHelperMarkAsStructValue) + "(new " + ctor + "()))"
 	case "SizeVT":
 		return "return protobuf_go_lite.SizeBoundMessageVT(" + ctor + ", this)"
 	case "UnmarshalJSON":
@@ -1,5 +1,7 @@
 package compiler
 
+import "slices"
+
 // CompilationResult describes a compiler run after adapter normalization.
 type CompilationResult struct {
 	// CompiledPackages contains package paths compiled to TypeScript.
@@ -10,4 +12,17 @@ type CompilationResult struct {
 	OriginalPackages []string
 	// Diagnostics contains all diagnostics produced by the compile request.
 	Diagnostics []Diagnostic
+	// DeadCode reports declarations removed per package by dead-code
+	// elimination.
+	DeadCode []DeadCodePackage
+	// RuntimeHelpers are the runtime helpers the compiled packages use,
+	// sorted. Copied override packages are not lowered and are left out.
+	RuntimeHelpers []RuntimeHelper
+}
+
+// addRuntimeHelpers merges helpers into the sorted RuntimeHelpers set.
+func (r *CompilationResult) addRuntimeHelpers(helpers []RuntimeHelper) {
+	r.RuntimeHelpers = append(r.RuntimeHelpers, helpers...)
+	slices.Sort(r.RuntimeHelpers)
+	r.RuntimeHelpers = slices.Compact(r.RuntimeHelpers)
 }
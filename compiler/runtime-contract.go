@@ -13,16 +13,18 @@ import (
 type RuntimeHelperCategory string
 
 const (
-	RuntimeHelperCategoryBuiltin RuntimeHelperCategory = "builtin"
-	RuntimeHelperCategoryValue   RuntimeHelperCategory = "value"
-	RuntimeHelperCategoryVarRef  RuntimeHelperCategory = "varref"
-	RuntimeHelperCategorySlice   RuntimeHelperCategory = "slice"
-	RuntimeHelperCategoryMap     RuntimeHelperCategory = "map"
-	RuntimeHelperCategoryError   RuntimeHelperCategory = "error"
-	RuntimeHelperCategoryType    RuntimeHelperCategory = "type"
-	RuntimeHelperCategoryChannel RuntimeHelperCategory = "channel"
-	RuntimeHelperCategoryDefer   RuntimeHelperCategory = "defer"
-	RuntimeHelperCategoryHost    RuntimeHelperCategory = "host"
+	RuntimeHelperCategoryBuiltin  RuntimeHelperCategory = "builtin"
+	RuntimeHelperCategoryValue    RuntimeHelperCategory = "value"
+	RuntimeHelperCategoryVarRef   RuntimeHelperCategory = "varref"
+	RuntimeHelperCategorySlice    RuntimeHelperCategory = "slice"
+	RuntimeHelperCategoryMap      RuntimeHelperCategory = "map"
+	RuntimeHelperCategoryError    RuntimeHelperCategory = "error"
+	RuntimeHelperCategoryType     RuntimeHelperCategory = "type"
+	RuntimeHelperCategoryChannel  RuntimeHelperCategory = "channel"
+	RuntimeHelperCategoryDefer    RuntimeHelperCategory = "defer"
+	RuntimeHelperCategoryHost     RuntimeHelperCategory = "host"
+	RuntimeHelperCategorySchedule RuntimeHelperCategory = "schedule"
+	RuntimeHelperCategoryCoverage RuntimeHelperCategory = "coverage"
 )
 
 // RuntimeHelper identifies one compiler-visible helper exported by @goscript/builtin.
@@ -143,13 +145,15 @@ const (
 	RuntimeHelperNamedFunction            RuntimeHelper = "type.namedFunction"
 	RuntimeHelperGenericZero              RuntimeHelper = "type.genericZero"
 	RuntimeHelperCallGenericMethod        RuntimeHelper = "type.callGenericMethod"
+	RuntimeHelperGenericInterfaceValue    RuntimeHelper = "type.genericInterfaceValue"
 
-	RuntimeHelperMakeChannel     RuntimeHelper = "channel.makeChannel"
-	RuntimeHelperMakeChannelRef  RuntimeHelper = "channel.makeChannelRef"
-	RuntimeHelperChanSend        RuntimeHelper = "channel.chanSend"
-	RuntimeHelperChanRecv        RuntimeHelper = "channel.chanRecv"
-	RuntimeHelperChanRecvWithOk  RuntimeHelper = "channel.chanRecvWithOk"
-	RuntimeHelperSelectStatement RuntimeHelper = "channel.selectStatement"
+	RuntimeHelperMakeChannel      RuntimeHelper = "channel.makeChannel"
+	RuntimeHelperMakeChannelRef   RuntimeHelper = "channel.makeChannelRef"
+	RuntimeHelperChanSend         RuntimeHelper = "channel.chanSend"
+	RuntimeHelperChanRecv         RuntimeHelper = "channel.chanRecv"
+	RuntimeHelperChanRecvWithOk   RuntimeHelper = "channel.chanRecvWithOk"
+	RuntimeHelperSelectStatement  RuntimeHelper = "channel.selectStatement"
+	RuntimeHelperSelectVoidReturn RuntimeHelper = "channel.selectVoidReturn"
 
 	RuntimeHelperDisposableStack      RuntimeHelper = "defer.DisposableStack"
 	RuntimeHelperAsyncDisposableStack RuntimeHelper = "defer.AsyncDisposableStack"
@@ -158,6 +162,16 @@ const (
 	RuntimeHelperWriteHostStdoutText RuntimeHelper = "host.writeHostStdoutText"
 	RuntimeHelperWriteHostStderrText RuntimeHelper = "host.writeHostStderrText"
 	RuntimeHelperIsMainScript        RuntimeHelper = "host.isMainScript"
+
+	RuntimeHelperPreemptDue RuntimeHelper = "schedule.preemptDue"
+	RuntimeHelperPreempt    RuntimeHelper = "schedule.preempt"
+	RuntimeHelperGo         RuntimeHelper = "schedule.go"
+
+	RuntimeHelperMainGoroutineStarted RuntimeHelper = "schedule.mainGoroutineStarted"
+	RuntimeHelperStartMainGoroutine   RuntimeHelper = "schedule.startMainGoroutine"
+
+	RuntimeHelperCoverBlocks RuntimeHelper = "coverage.coverBlocks"
+	RuntimeHelperCoverHit    RuntimeHelper = "coverage.coverHit"
 )
 
 // RuntimeImport is a generated TypeScript import owned by the runtime contract.
@@ -176,6 +190,7 @@ type RuntimeHelperContract struct {
 // RuntimeContractOwner owns generated-code helper names and runtime capabilities.
 type RuntimeContractOwner struct {
 	helpers map[RuntimeHelper]RuntimeHelperContract
+	used    map[RuntimeHelper]bool
 }
 
 // NewRuntimeContractOwner creates the runtime contract owner.
@@ -189,6 +204,11 @@ func NewRuntimeContractOwner() *RuntimeContractOwner {
 	return owner
 }
 
+// recordingUsage returns an owner that adds every helper it qualifies to used.
+func (o *RuntimeContractOwner) recordingUsage(used map[RuntimeHelper]bool) *RuntimeContractOwner {
+	return &RuntimeContractOwner{helpers: o.helpers, used: used}
+}
+
 // BuiltinImport returns the runtime import used by generated package modules.
 func (o *RuntimeContractOwner) BuiltinImport() RuntimeImport {
 	return RuntimeImport{
@@ -240,6 +260,9 @@ func (o *RuntimeContractOwner) QualifiedHelper(helper RuntimeHelper) string {
 	if !ok {
 		panic("missing runtime helper contract: " + string(helper))
 	}
+	if o.used != nil {
+		o.used[helper] = true
+	}
 	return o.BuiltinImport().Alias + "." + name
 }
 
@@ -379,18 +402,27 @@ func runtimeHelperContracts() []RuntimeHelperContract {
 		runtimeHelper(RuntimeHelperNamedFunction, "namedFunction", RuntimeHelperCategoryType),
 		runtimeHelper(RuntimeHelperGenericZero, "genericZero", RuntimeHelperCategoryType),
 		runtimeHelper(RuntimeHelperCallGenericMethod, "callGenericMethod", RuntimeHelperCategoryType),
+		runtimeHelper(RuntimeHelperGenericInterfaceValue, "genericInterfaceValue", RuntimeHelperCategoryType),
 		runtimeHelper(RuntimeHelperMakeChannel, "makeChannel", RuntimeHelperCategoryChannel),
 		runtimeHelper(RuntimeHelperMakeChannelRef, "makeChannelRef", RuntimeHelperCategoryChannel),
 		runtimeHelper(RuntimeHelperChanSend, "chanSend", RuntimeHelperCategoryChannel),
 		runtimeHelper(RuntimeHelperChanRecv, "chanRecv", RuntimeHelperCategoryChannel),
 		runtimeHelper(RuntimeHelperChanRecvWithOk, "chanRecvWithOk", RuntimeHelperCategoryChannel),
 		runtimeHelper(RuntimeHelperSelectStatement, "selectStatement", RuntimeHelperCategoryChannel),
+		runtimeHelper(RuntimeHelperSelectVoidReturn, "selectVoidReturn", RuntimeHelperCategoryChannel),
 		runtimeHelper(RuntimeHelperDisposableStack, "DisposableStack", RuntimeHelperCategoryDefer),
 		runtimeHelper(RuntimeHelperAsyncDisposableStack, "AsyncDisposableStack", RuntimeHelperCategoryDefer),
 		runtimeHelper(RuntimeHelperGetHostRuntime, "getHostRuntime", RuntimeHelperCategoryHost),
 		runtimeHelper(RuntimeHelperWriteHostStdoutText, "writeHostStdoutText", RuntimeHelperCategoryHost),
 		runtimeHelper(RuntimeHelperWriteHostStderrText, "writeHostStderrText", RuntimeHelperCategoryHost),
 		runtimeHelper(RuntimeHelperIsMainScript, "isMainScript", RuntimeHelperCategoryHost),
+		runtimeHelper(RuntimeHelperPreemptDue, "preemptDue", RuntimeHelperCategorySchedule),
+		runtimeHelper(RuntimeHelperPreempt, "preempt", RuntimeHelperCategorySchedule),
+		runtimeHelper(RuntimeHelperGo, "go", RuntimeHelperCategorySchedule),
+		runtimeHelper(RuntimeHelperMainGoroutineStarted, "mainGoroutineStarted", RuntimeHelperCategorySchedule),
+		runtimeHelper(RuntimeHelperStartMainGoroutine, "startMainGoroutine", RuntimeHelperCategorySchedule),
+		runtimeHelper(RuntimeHelperCoverBlocks, "coverBlocks", RuntimeHelperCategoryCoverage),
+		runtimeHelper(RuntimeHelperCoverHit, "coverHit", RuntimeHelperCategoryCoverage),
 	}
 }
 
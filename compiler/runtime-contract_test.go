@@ -42,14 +42,23 @@ func TestRuntimeContractOwnsBuiltinImportAndHelpers(t *testing.T) {
 		RuntimeHelperNamedFunction:            RuntimeHelperCategoryType,
 		RuntimeHelperGenericZero:              RuntimeHelperCategoryType,
 		RuntimeHelperCallGenericMethod:        RuntimeHelperCategoryType,
+		RuntimeHelperGenericInterfaceValue:    RuntimeHelperCategoryType,
 		RuntimeHelperMakeChannel:              RuntimeHelperCategoryChannel,
 		RuntimeHelperSelectStatement:          RuntimeHelperCategoryChannel,
+		RuntimeHelperSelectVoidReturn:         RuntimeHelperCategoryChannel,
 		RuntimeHelperDisposableStack:          RuntimeHelperCategoryDefer,
 		RuntimeHelperAsyncDisposableStack:     RuntimeHelperCategoryDefer,
 		RuntimeHelperGetHostRuntime:           RuntimeHelperCategoryHost,
 		RuntimeHelperWriteHostStdoutText:      RuntimeHelperCategoryHost,
 		RuntimeHelperWriteHostStderrText:      RuntimeHelperCategoryHost,
 		RuntimeHelperIsMainScript:             RuntimeHelperCategoryHost,
+		RuntimeHelperPreemptDue:               RuntimeHelperCategorySchedule,
+		RuntimeHelperPreempt:                  RuntimeHelperCategorySchedule,
+		RuntimeHelperGo:                       RuntimeHelperCategorySchedule,
+		RuntimeHelperMainGoroutineStarted:     RuntimeHelperCategorySchedule,
+		RuntimeHelperStartMainGoroutine:       RuntimeHelperCategorySchedule,
+		RuntimeHelperCoverBlocks:              RuntimeHelperCategoryCoverage,
+		RuntimeHelperCoverHit:                 RuntimeHelperCategoryCoverage,
 	}
 	for helper, category := range wantHelpers {
 		contract, ok := owner.Helper(helper)
@@ -78,6 +87,8 @@ func TestRuntimeContractCoversPhaseFiveCategories(t *testing.T) {
 		RuntimeHelperCategoryChannel,
 		RuntimeHelperCategoryDefer,
 		RuntimeHelperCategoryHost,
+		RuntimeHelperCategorySchedule,
+		RuntimeHelperCategoryCoverage,
 	} {
 		if len(owner.HelpersByCategory(category)) == 0 {
 			t.Fatalf("runtime helper category %q has no helpers", category)
@@ -0,0 +1,116 @@
+package compiler
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	gs "github.com/s4wave/goscript"
+)
+
+// RuntimeUsage lists the @goscript/builtin exports a program uses.
+type RuntimeUsage struct {
+	// Helpers are the compiler-visible helpers the program calls.
+	Helpers []RuntimeHelperContract
+	// Exports are the runtime value exports the program references, the helpers
+	// included. Handwritten override packages reference exports that are not
+	// compiler-visible helpers, such as host I/O.
+	Exports []string
+}
+
+var builtinNamedImportPattern = regexp.MustCompile(`import\s+\{([^}]*)\}\s+from\s+['"]@goscript/builtin/index\.js['"]`)
+
+// RuntimeUsage returns the builtin exports a program uses. Helpers are the
+// helpers lowering recorded for the compiled packages, as reported by
+// CompilationResult.RuntimeHelpers. Override modules are handwritten rather
+// than lowered, so overrideSources, their TypeScript text, are scanned for
+// member accesses on the builtin import alias and named imports from the
+// builtin index. Type-only exports are left out, since they have no runtime
+// value to bundle.
+func (o *RuntimeContractOwner) RuntimeUsage(helpers []RuntimeHelper, overrideSources []string) (*RuntimeUsage, error) {
+	typeExports, err := scanBuiltinTypeExports()
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, helper := range helpers {
+		contract, ok := o.Helper(helper)
+		if !ok {
+			return nil, errors.Errorf("unknown runtime helper %s", helper)
+		}
+		used[contract.Export] = true
+	}
+	memberPattern := regexp.MustCompile(`(?:^|[^\w$.])` + regexp.QuoteMeta(o.BuiltinImport().Alias) + `\.([A-Za-z_$][\w$]*)`)
+	for _, source := range overrideSources {
+		for _, match := range memberPattern.FindAllStringSubmatch(source, -1) {
+			used[match[1]] = true
+		}
+		for _, match := range builtinNamedImportPattern.FindAllStringSubmatch(source, -1) {
+			for name := range strings.SplitSeq(match[1], ",") {
+				name = strings.TrimSpace(name)
+				if name == "" || strings.HasPrefix(name, "type ") {
+					continue
+				}
+				name, _, _ = strings.Cut(name, " as ")
+				used[strings.TrimSpace(name)] = true
+			}
+		}
+	}
+
+	usage := &RuntimeUsage{}
+	for name := range used {
+		if !typeExports[name] {
+			usage.Exports = append(usage.Exports, name)
+		}
+	}
+	slices.Sort(usage.Exports)
+	for _, helper := range o.Helpers() {
+		if slices.Contains(usage.Exports, helper.Export) {
+			usage.Helpers = append(usage.Helpers, helper)
+		}
+	}
+	return usage, nil
+}
+
+// scanBuiltinTypeExports returns the names the builtin runtime exports only as
+// types or interfaces.
+func scanBuiltinTypeExports() (map[string]bool, error) {
+	index, err := gs.GsOverrides.ReadFile("gs/builtin/index.ts")
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]bool)
+	values := make(map[string]bool)
+	for _, module := range builtinReexportModules(string(index)) {
+		data, err := gs.GsOverrides.ReadFile("gs/builtin/" + module + ".ts")
+		if err != nil {
+			return nil, errors.Wrapf(err, "read builtin export module %s", module)
+		}
+		for line := range strings.SplitSeq(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			for _, prefix := range []string{"export type ", "export interface "} {
+				if strings.HasPrefix(line, prefix) {
+					types[exportNameAfterPrefix(line, prefix)] = true
+				}
+			}
+			for _, prefix := range []string{
+				"export async function ",
+				"export function ",
+				"export const ",
+				"export let ",
+				"export class ",
+				"export enum ",
+			} {
+				if strings.HasPrefix(line, prefix) {
+					values[exportNameAfterPrefix(line, prefix)] = true
+				}
+			}
+		}
+	}
+	// A class or function may share its name with a type; keep the value.
+	for name := range values {
+		delete(types, name)
+	}
+	return types, nil
+}
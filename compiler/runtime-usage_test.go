@@ -0,0 +1,40 @@
+package compiler
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRuntimeUsageListsReferencedBuiltinExports(t *testing.T) {
+	owner := NewRuntimeContractOwner()
+	usage, err := owner.RuntimeUsage([]RuntimeHelper{RuntimeHelperMakeMap}, []string{
+		"import * as $ from \"@goscript/builtin/index.js\"\n" +
+			"export function Sum(xs: $.Slice<number>): number {\n" +
+			"\treturn $.len(xs) + other$.ignored\n" +
+			"}\n",
+		"import {\n\tgo,\n\ttype GoroutineInfo,\n\tpark as wait,\n} from '@goscript/builtin/index.js'\n",
+	})
+	if err != nil {
+		t.Fatalf("RuntimeUsage: %v", err)
+	}
+
+	if want := []string{"go", "len", "makeMap", "park"}; !slices.Equal(usage.Exports, want) {
+		t.Fatalf("Exports = %v, want %v", usage.Exports, want)
+	}
+	var helpers []RuntimeHelper
+	for _, helper := range usage.Helpers {
+		helpers = append(helpers, helper.Helper)
+	}
+	if !slices.Contains(helpers, RuntimeHelperGo) || !slices.Contains(helpers, RuntimeHelperMakeMap) {
+		t.Fatalf("Helpers = %v, want the go statement and recorded map helpers", helpers)
+	}
+	for _, helper := range usage.Helpers {
+		if !slices.Contains(usage.Exports, helper.Export) {
+			t.Fatalf("helper %s is not among the used exports", helper.Export)
+		}
+	}
+
+	if _, err := owner.RuntimeUsage([]RuntimeHelper{"missing.helper"}, nil); err == nil {
+		t.Fatal("RuntimeUsage accepted an unknown helper")
+	}
+}
@@ -0,0 +1,280 @@
+package compiler
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AsyncStep is one function in an async propagation chain.
+type AsyncStep struct {
+	// Function is the full Go name of the function, as printed by types.Func.FullName.
+	Function string
+	// Reason is the async reason that links this step to the next one.
+	Reason string
+	// Position is the function declaration, if known.
+	Position *DiagnosticPosition
+}
+
+// AsyncExplanation is the shortest chain from an async function back to the
+// fact that first made a function in the chain async.
+type AsyncExplanation struct {
+	// Function is the full Go name of the explained function.
+	Function string
+	// Package is the Go import path that declares the function.
+	Package string
+	// Chain starts at Function and ends at the function holding the root cause.
+	Chain []AsyncStep
+	// RootCause is the root async reason, such as channel-receive or
+	// override:sync.Mutex.Lock.
+	RootCause string
+}
+
+// AsyncCauseGroup lists the async functions that share one root cause.
+type AsyncCauseGroup struct {
+	// RootCause is the shared root async reason.
+	RootCause string
+	// Functions are the explanations in the group, sorted by function name.
+	Functions []*AsyncExplanation
+}
+
+// AsyncReportOptions configures async explanations.
+type AsyncReportOptions struct {
+	// Packages limits the package-wide report to these import paths.
+	// Empty reports every package in the semantic model.
+	Packages []string
+	// DisplayRoot makes explanation positions relative to this directory.
+	DisplayRoot string
+}
+
+// ExplainAsync returns the shortest propagation chain for one function.
+//
+// name is a full Go name such as example.com/pkg.Helper or
+// (*example.com/pkg.Server).Serve. A unique suffix such as pkg.Helper,
+// Server.Serve, or Helper is accepted too. It returns nil when the function
+// is sync.
+func (m *SemanticModel) ExplainAsync(name string, opts AsyncReportOptions) (*AsyncExplanation, error) {
+	semFn, err := m.lookupAsyncFunction(name, opts.Packages)
+	if err != nil {
+		return nil, err
+	}
+	if !semFn.async {
+		return nil, nil
+	}
+	return m.explainAsyncFunction(semFn, opts.DisplayRoot), nil
+}
+
+// AsyncReport explains every async function with a body in the selected
+// packages and groups the explanations by root cause, largest group first.
+func (m *SemanticModel) AsyncReport(opts AsyncReportOptions) []AsyncCauseGroup {
+	if m == nil {
+		return nil
+	}
+	groups := make(map[string]*AsyncCauseGroup)
+	for _, semPkg := range m.packages {
+		if len(opts.Packages) != 0 && !slices.Contains(opts.Packages, semPkg.pkgPath) {
+			continue
+		}
+		for _, semFn := range semPkg.functions {
+			if !semFn.async || !semFn.hasBody {
+				continue
+			}
+			explanation := m.explainAsyncFunction(semFn, opts.DisplayRoot)
+			group := groups[explanation.RootCause]
+			if group == nil {
+				group = &AsyncCauseGroup{RootCause: explanation.RootCause}
+				groups[explanation.RootCause] = group
+			}
+			group.Functions = append(group.Functions, explanation)
+		}
+	}
+	report := make([]AsyncCauseGroup, 0, len(groups))
+	for _, group := range groups {
+		slices.SortFunc(group.Functions, func(a, b *AsyncExplanation) int {
+			return cmp.Compare(a.Function, b.Function)
+		})
+		report = append(report, *group)
+	}
+	slices.SortFunc(report, func(a, b AsyncCauseGroup) int {
+		if c := cmp.Compare(len(b.Functions), len(a.Functions)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.RootCause, b.RootCause)
+	})
+	return report
+}
+
+// DescribeAsyncReason returns a short human-readable form of an async reason.
+func DescribeAsyncReason(reason string) string {
+	kind, subject, _ := strings.Cut(reason, ":")
+	switch kind {
+	case "channel-send":
+		return "sends on a channel"
+	case "channel-receive":
+		return "receives from a channel"
+	case "select":
+		return "uses a select statement"
+	case "range-function":
+		return "ranges over an async iterator function"
+	case "function-value-call":
+		return "calls a function value"
+	case "function-identifier-call":
+		return "calls a function variable"
+	case "interface-method-call":
+		return "calls an interface method"
+	case "async-function-literal-call":
+		return "calls a function literal that is async"
+	case "async-function-argument":
+		return "receives an async function argument"
+	case preemptLoopAsyncReason:
+		return "starts a goroutine whose loops yield (--preempt-loops)"
+	case "override":
+		return "calls " + subject + ", which the override meta.json lists as async"
+	case "call":
+		return "calls " + subject
+	case "interface-implementation":
+		return "is implemented by async " + subject
+	case "interface-method":
+		return "implements " + subject
+	default:
+		return reason
+	}
+}
+
+// asyncReasonLink returns the function a propagated reason points at.
+// Root reasons return "".
+func asyncReasonLink(reason string) string {
+	kind, subject, ok := strings.Cut(reason, ":")
+	if !ok {
+		return ""
+	}
+	switch kind {
+	case "call", "interface-implementation":
+		return subject
+	default:
+		return ""
+	}
+}
+
+// asyncReasonIsAnnotation reports whether a reason was recorded on a
+// function that was already async, so it never explains the coloring.
+func asyncReasonIsAnnotation(reason string) bool {
+	kind, _, _ := strings.Cut(reason, ":")
+	return kind == "interface-method"
+}
+
+// explainAsyncFunction walks async reasons breadth first so the returned
+// chain is the shortest one. Ties are broken by function name, then by the
+// order the reasons were recorded, so the output is stable.
+func (m *SemanticModel) explainAsyncFunction(start *semanticFunction, displayRoot string) *AsyncExplanation {
+	type visit struct {
+		fn     *semanticFunction
+		parent *visit
+		reason string
+	}
+	explanation := &AsyncExplanation{
+		Function: m.functionFullName(start.function),
+	}
+	if pkg := start.function.Pkg(); pkg != nil {
+		explanation.Package = pkg.Path()
+	}
+
+	seen := map[*semanticFunction]bool{start: true}
+	level := []*visit{{fn: start}}
+	var root *visit
+	for len(level) != 0 && root == nil {
+		slices.SortStableFunc(level, func(a, b *visit) int {
+			return cmp.Compare(m.functionFullName(a.fn.function), m.functionFullName(b.fn.function))
+		})
+		var next []*visit
+		for _, node := range level {
+			for _, reason := range node.fn.asyncReasons {
+				if asyncReasonIsAnnotation(reason) {
+					continue
+				}
+				link := asyncReasonLink(reason)
+				target := m.functionsByFullName[link]
+				if link == "" || target == nil || !target.async {
+					root = &visit{fn: node.fn, parent: node.parent, reason: reason}
+					break
+				}
+				if !seen[target] {
+					seen[target] = true
+					next = append(next, &visit{fn: target, parent: &visit{fn: node.fn, parent: node.parent, reason: reason}})
+				}
+			}
+			if root != nil {
+				break
+			}
+		}
+		level = next
+	}
+
+	var steps []AsyncStep
+	for node := root; node != nil; node = node.parent {
+		steps = append(steps, AsyncStep{
+			Function: m.functionFullName(node.fn.function),
+			Reason:   node.reason,
+			Position: diagnosticPositionFromSource(node.fn.position, displayRoot),
+		})
+	}
+	slices.Reverse(steps)
+	if root == nil {
+		// The function is async but recorded no reason, which only happens
+		// for interface methods colored through their method set.
+		steps = []AsyncStep{{
+			Function: explanation.Function,
+			Position: diagnosticPositionFromSource(start.position, displayRoot),
+		}}
+	} else {
+		explanation.RootCause = root.reason
+	}
+	explanation.Chain = steps
+	return explanation
+}
+
+func (m *SemanticModel) lookupAsyncFunction(name string, pkgPaths []string) (*semanticFunction, error) {
+	name = strings.TrimSpace(name)
+	if m == nil || name == "" {
+		return nil, errors.New("function name is required")
+	}
+	if semFn := m.functionsByFullName[name]; semFn != nil {
+		return semFn, nil
+	}
+	var matches []*semanticFunction
+	for _, semPkg := range m.packages {
+		if len(pkgPaths) != 0 && !slices.Contains(pkgPaths, semPkg.pkgPath) {
+			continue
+		}
+		for _, semFn := range semPkg.functions {
+			if asyncFunctionNameMatches(semPkg, semFn, name) {
+				matches = append(matches, semFn)
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, errors.Errorf("function %q not found", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, semFn := range matches {
+			names = append(names, m.functionFullName(semFn.function))
+		}
+		slices.Sort(names)
+		return nil, errors.Errorf("function %q is ambiguous: %s", name, strings.Join(names, ", "))
+	}
+}
+
+// asyncFunctionNameMatches accepts Name, Recv.Name, pkg.Name, and
+// pkg.Recv.Name, where pkg is the package name and Recv drops any pointer.
+func asyncFunctionNameMatches(semPkg *semanticPackage, semFn *semanticFunction, name string) bool {
+	short := semFn.name
+	if semFn.receiver != nil && semFn.receiver.Obj() != nil {
+		short = semFn.receiver.Obj().Name() + "." + short
+	}
+	return name == short || name == semFn.name || name == semPkg.name+"."+short
+}
@@ -0,0 +1,113 @@
+package compiler
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+var asyncExplainFixtureSource = strings.Join([]string{
+	"package explain",
+	"import \"sync\"",
+	"type Cache struct {",
+	"\tmu sync.Mutex",
+	"\tv  int",
+	"}",
+	"func (c *Cache) lock() { c.mu.Lock() }",
+	"func (c *Cache) Get() int {",
+	"\tc.lock()",
+	"\tdefer c.mu.Unlock()",
+	"\treturn c.v",
+	"}",
+	"func wait(ch chan int) int { return <-ch }",
+	"func Helper(ch chan int) int { return wait(ch) + 1 }",
+	"func Outer(ch chan int) int {",
+	"\tif len(ch) == 0 {",
+	"\t\treturn Helper(ch) * 2",
+	"\t}",
+	"\treturn <-ch",
+	"}",
+	"func Sync() int { return 1 }",
+	"",
+}, "\n")
+
+func buildAsyncExplainModel(t *testing.T) *SemanticModel {
+	t.Helper()
+
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":  "module example.test/explain\n\ngo 1.25.3\n",
+		"main.go": asyncExplainFixtureSource,
+	})
+	service := NewCompileService()
+	req := service.RequestOwner().NewRequest(Config{Dir: moduleDir}, []string{"."})
+	model, _, err := service.BuildSemanticModel(context.Background(), req)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return model
+}
+
+func TestExplainAsyncReturnsShortestChain(t *testing.T) {
+	model := buildAsyncExplainModel(t)
+
+	explanation, err := model.ExplainAsync("explain.Outer", AsyncReportOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if explanation == nil {
+		t.Fatal("expected Outer to be async")
+	}
+	// Outer receives from the channel itself, which is shorter than the
+	// chain through Helper and wait.
+	if len(explanation.Chain) != 1 || explanation.RootCause != "channel-receive" {
+		t.Fatalf("explanation = %#v, want direct channel-receive", explanation)
+	}
+
+	explanation, err = model.ExplainAsync("Cache.Get", AsyncReportOptions{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var chain []string
+	for _, step := range explanation.Chain {
+		chain = append(chain, step.Function+" "+step.Reason)
+	}
+	want := []string{
+		"(*example.test/explain.Cache).Get call:(*example.test/explain.Cache).lock",
+		"(*example.test/explain.Cache).lock override:sync.Mutex.Lock",
+	}
+	if strings.Join(chain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("chain = %q, want %q", chain, want)
+	}
+	if explanation.Package != "example.test/explain" {
+		t.Fatalf("Package = %q, want example.test/explain", explanation.Package)
+	}
+
+	explanation, err = model.ExplainAsync("example.test/explain.Sync", AsyncReportOptions{})
+	if err != nil || explanation != nil {
+		t.Fatalf("ExplainAsync(Sync) = %#v, %v; want nil explanation", explanation, err)
+	}
+	if _, err := model.ExplainAsync("Missing", AsyncReportOptions{}); err == nil {
+		t.Fatal("expected error for unknown function")
+	}
+}
+
+func TestAsyncReportGroupsByRootCause(t *testing.T) {
+	model := buildAsyncExplainModel(t)
+
+	groups := model.AsyncReport(AsyncReportOptions{Packages: []string{"example.test/explain"}})
+	got := make(map[string][]string)
+	for _, group := range groups {
+		for _, explanation := range group.Functions {
+			got[group.RootCause] = append(got[group.RootCause], explanation.Function)
+		}
+	}
+	if want := "example.test/explain.Helper,example.test/explain.Outer,example.test/explain.wait"; strings.Join(got["channel-receive"], ",") != want {
+		t.Fatalf("channel-receive group = %v, want %s", got["channel-receive"], want)
+	}
+	if want := "(*example.test/explain.Cache).Get,(*example.test/explain.Cache).lock"; strings.Join(got["override:sync.Mutex.Lock"], ",") != want {
+		t.Fatalf("override group = %v, want %s", got["override:sync.Mutex.Lock"], want)
+	}
+	if groups[0].RootCause != "channel-receive" {
+		t.Fatalf("largest group = %q, want channel-receive first", groups[0].RootCause)
+	}
+}
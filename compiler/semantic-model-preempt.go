@@ -0,0 +1,217 @@
+package compiler
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// preemptLoopAsyncReason is the async reason recorded on goroutine entry
+// functions colored async only so their loops can yield.
+const preemptLoopAsyncReason = "preempt-loop"
+
+// PreemptAsyncFunctions returns the functions that are async only because loop
+// preemption was enabled, sorted by name. Function literals started by go
+// statements use Go's closure naming, such as example.com/pkg.Run.func1.
+func (m *SemanticModel) PreemptAsyncFunctions() []string {
+	if m == nil {
+		return nil
+	}
+	return slices.Clone(m.preemptAsyncFunctions)
+}
+
+// preemptLoopLiteral reports whether lit is a goroutine body that lowering
+// must emit as async so its loops can yield.
+func (m *SemanticModel) preemptLoopLiteral(lit *ast.FuncLit) bool {
+	return m != nil && lit != nil && m.preemptLoopLiterals[lit]
+}
+
+// applyLoopPreemption colors the entry points of go statements async when
+// their bodies contain loops that would otherwise never yield.
+//
+// Only the function started by the go statement is colored. Sync helpers it
+// calls keep their sync shape, so loops inside them still run to completion.
+func (o *SemanticModelOwner) applyLoopPreemption(
+	ctx context.Context,
+	model *SemanticModel,
+) ([]semanticPreemptLiteral, []Diagnostic) {
+	bodies := make(map[*types.Func]*semanticPreemptBody)
+	var goStmts []semanticGoStmt
+	for _, semPkg := range model.packages {
+		if err := ctx.Err(); err != nil {
+			return nil, []Diagnostic{contextCanceledDiagnostic(err)}
+		}
+		pkg := semPkg.source
+		if pkg == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fnDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || fnDecl.Body == nil {
+					continue
+				}
+				if fn, _ := pkg.TypesInfo.Defs[fnDecl.Name].(*types.Func); fn != nil {
+					bodies[fn] = &semanticPreemptBody{pkg: pkg, body: fnDecl.Body}
+				}
+			}
+			goStmts = append(goStmts, collectSemanticGoStmts(model, pkg, file)...)
+		}
+	}
+
+	var literals []semanticPreemptLiteral
+	for _, goStmt := range goStmts {
+		if err := ctx.Err(); err != nil {
+			return nil, []Diagnostic{contextCanceledDiagnostic(err)}
+		}
+		if lit, ok := ast.Unparen(goStmt.stmt.Call.Fun).(*ast.FuncLit); ok {
+			if blockNeedsLoopPreemption(goStmt.pkg, lit.Body) {
+				model.preemptLoopLiterals[lit] = true
+				literals = append(literals, semanticPreemptLiteral{pkg: goStmt.pkg, lit: lit, name: goStmt.literalName})
+			}
+			continue
+		}
+		called := functionOriginOrSelf(calledFunction(goStmt.pkg, goStmt.stmt.Call.Fun))
+		body := bodies[called]
+		if body == nil || !blockNeedsLoopPreemption(body.pkg, body.body) {
+			continue
+		}
+		markFunctionAsync(semanticFunctionFor(model, called), preemptLoopAsyncReason)
+	}
+	return literals, nil
+}
+
+// recordPreemptAsyncFunctions stores the functions that were sync before loop
+// preemption ran and are async after propagation.
+func recordPreemptAsyncFunctions(
+	model *SemanticModel,
+	syncFunctions []*semanticFunction,
+	literals []semanticPreemptLiteral,
+) {
+	var names []string
+	for _, fn := range syncFunctions {
+		if fn.async {
+			names = append(names, model.functionFullName(fn.function))
+		}
+	}
+	for _, lit := range literals {
+		// Literals that already await something are async without preemption.
+		if !exprMayNeedAwait(model, lit.pkg, lit.lit) {
+			names = append(names, lit.name)
+		}
+	}
+	slices.Sort(names)
+	model.preemptAsyncFunctions = slices.Compact(names)
+}
+
+func semanticSyncFunctions(model *SemanticModel) []*semanticFunction {
+	var syncFunctions []*semanticFunction
+	seen := make(map[*semanticFunction]bool, len(model.functions))
+	for _, fn := range model.functions {
+		if fn == nil || fn.async || !fn.hasBody || seen[fn] {
+			continue
+		}
+		seen[fn] = true
+		syncFunctions = append(syncFunctions, fn)
+	}
+	return syncFunctions
+}
+
+type semanticPreemptBody struct {
+	pkg  *packages.Package
+	body *ast.BlockStmt
+}
+
+type semanticGoStmt struct {
+	pkg         *packages.Package
+	stmt        *ast.GoStmt
+	literalName string
+}
+
+type semanticPreemptLiteral struct {
+	pkg  *packages.Package
+	lit  *ast.FuncLit
+	name string
+}
+
+// collectSemanticGoStmts returns the go statements in file. Function literal
+// targets are named the way the Go toolchain names closures: funcN for the Nth
+// literal in a declaration and funcN.M for literals nested inside it.
+func collectSemanticGoStmts(model *SemanticModel, pkg *packages.Package, file *ast.File) []semanticGoStmt {
+	var goStmts []semanticGoStmt
+	globals := 0
+	for _, decl := range file.Decls {
+		prefix := pkg.PkgPath + ".glob."
+		counter := &globals
+		if fnDecl, ok := decl.(*ast.FuncDecl); ok {
+			fn, _ := pkg.TypesInfo.Defs[fnDecl.Name].(*types.Func)
+			if fn == nil {
+				continue
+			}
+			prefix = model.functionFullName(fn) + ".func"
+			counter = new(int)
+		}
+		var walk func(node ast.Node, prefix string, counter *int)
+		walk = func(node ast.Node, prefix string, counter *int) {
+			ast.Inspect(node, func(child ast.Node) bool {
+				switch typed := child.(type) {
+				case *ast.FuncLit:
+					if typed == node {
+						return true
+					}
+					*counter++
+					name := prefix + strconv.Itoa(*counter)
+					if goStmt, ok := semanticGoStmtForLiteral(goStmts, typed); ok {
+						goStmts[goStmt].literalName = name
+					}
+					walk(typed, name+".", new(int))
+					return false
+				case *ast.GoStmt:
+					goStmts = append(goStmts, semanticGoStmt{pkg: pkg, stmt: typed})
+				}
+				return true
+			})
+		}
+		walk(decl, prefix, counter)
+	}
+	return goStmts
+}
+
+func semanticGoStmtForLiteral(goStmts []semanticGoStmt, lit *ast.FuncLit) (int, bool) {
+	for idx := len(goStmts) - 1; idx >= 0; idx-- {
+		if ast.Unparen(goStmts[idx].stmt.Call.Fun) == lit {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// blockNeedsLoopPreemption reports whether body has a loop that lowering
+// would emit without any await. Channel ranges already await every receive
+// and range-over-func bodies are separate callbacks.
+func blockNeedsLoopPreemption(pkg *packages.Package, body *ast.BlockStmt) bool {
+	if pkg == nil || body == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		switch typed := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ForStmt:
+			found = true
+		case *ast.RangeStmt:
+			typ := pkg.TypesInfo.TypeOf(typed.X)
+			found = !isChannelType(typ) && !isFunctionType(typ)
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,143 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var preemptFixtureSource = strings.Join([]string{
+	"package spin",
+	"func work(n int) int {",
+	"\ttotal := 0",
+	"\tfor i := 0; i < n; i++ {",
+	"\t\ttotal += i",
+	"\t}",
+	"\treturn total",
+	"}",
+	"func Spin(n int) {",
+	"\tfor i := range n {",
+	"\t\t_ = i",
+	"\t}",
+	"}",
+	"func Helper(xs []int) int {",
+	"\ts := 0",
+	"\tfor _, x := range xs {",
+	"\t\ts += x",
+	"\t}",
+	"\treturn s",
+	"}",
+	"func Start(n int) {",
+	"\tgo Spin(n)",
+	"\tgo func() {",
+	"\t\tfor {",
+	"\t\t\t_ = Helper(nil)",
+	"\t\t}",
+	"\t}()",
+	"\t_ = work(n)",
+	"}",
+	"func Drain(ch chan int) {",
+	"\tgo func() {",
+	"\t\tfor {",
+	"\t\t\t<-ch",
+	"\t\t}",
+	"\t}()",
+	"}",
+	"",
+}, "\n")
+
+func TestSemanticModelReportsPreemptAsyncFunctions(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":  "module example.test/spin\n\ngo 1.25.3\n",
+		"main.go": preemptFixtureSource,
+	})
+	graph := loadPackageGraph(t, &CompileRequest{
+		Patterns:            []string{"."},
+		Dir:                 moduleDir,
+		OutputPath:          filepath.Join(t.TempDir(), "out"),
+		DependencyMode:      DependencyModeRequested,
+		RuntimeEmissionMode: RuntimeEmissionModeEmit,
+	})
+
+	model := buildSemanticModel(t, graph)
+	if got := model.PreemptAsyncFunctions(); len(got) != 0 {
+		t.Fatalf("expected no preemption facts without PreemptLoops, got %v", got)
+	}
+	spin := requireDefinedFunc(t, graph, "example.test/spin", "Spin")
+	if model.functions[spin].async {
+		t.Fatalf("did not expect Spin to be async without PreemptLoops")
+	}
+
+	model, diagnostics := NewSemanticModelOwner().Build(context.Background(), graph, SemanticModelOptions{PreemptLoops: true})
+	if diagnosticsHaveErrors(diagnostics) {
+		t.Fatalf("semantic model build failed: %#v", diagnostics)
+	}
+	want := []string{
+		"example.test/spin.Spin",
+		"example.test/spin.Start.func1",
+	}
+	if got := model.PreemptAsyncFunctions(); !slices.Equal(got, want) {
+		t.Fatalf("PreemptAsyncFunctions() = %v, want %v", got, want)
+	}
+	if reasons := model.functions[spin].asyncReasons; !slices.Contains(reasons, preemptLoopAsyncReason) {
+		t.Fatalf("expected Spin async reason %q, got %v", preemptLoopAsyncReason, reasons)
+	}
+	for _, name := range []string{"work", "Helper", "Start"} {
+		fn := requireDefinedFunc(t, graph, "example.test/spin", name)
+		if model.functions[fn].async {
+			t.Fatalf("did not expect sync-only %s to become async: %#v", name, model.functions[fn])
+		}
+	}
+}
+
+func TestCompilePackagesPreemptsLoopsInAsyncFunctions(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":  "module example.test/spin\n\ngo 1.25.3\n",
+		"main.go": preemptFixtureSource,
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir, PreemptLoops: true}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "@goscript", "example.test", "spin", "main.gs.ts"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	text := string(data)
+
+	const check = "if ($.preemptDue()) {\n"
+	spin := functionText(t, text, "export async function Spin(")
+	if !strings.Contains(spin, check) || !strings.Contains(spin, "await $.preempt()") {
+		t.Fatalf("expected preemption check in Spin:\n%s", spin)
+	}
+	start := functionText(t, text, "export function Start(")
+	if !strings.Contains(start, "async (): globalThis.Promise<void> => {") || !strings.Contains(start, check) {
+		t.Fatalf("expected async goroutine literal with preemption check in Start:\n%s", start)
+	}
+	for _, name := range []string{"function work(", "export function Helper("} {
+		if body := functionText(t, text, name); strings.Contains(body, "preemptDue") {
+			t.Fatalf("did not expect preemption check in sync-only function:\n%s", body)
+		}
+	}
+}
+
+// functionText returns the top-level function in text that starts with prefix.
+func functionText(t *testing.T, text string, prefix string) string {
+	t.Helper()
+	start := strings.Index(text, prefix)
+	if start < 0 {
+		t.Fatalf("missing %q in generated output:\n%s", prefix, text)
+	}
+	end := strings.Index(text[start:], "\n}\n")
+	if end < 0 {
+		return text[start:]
+	}
+	return text[start : start+end+3]
+}
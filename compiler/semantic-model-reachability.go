@@ -0,0 +1,456 @@
+package compiler
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+)
+
+// DeadCodePackage reports what the reachability pass removed from one
+// compiled package.
+type DeadCodePackage struct {
+	// Package is the Go package path.
+	Package string
+	// Declarations counts the package-level functions, types, vars, and
+	// consts before elimination.
+	Declarations int
+	// RemovedFunctions counts removed package-level functions.
+	RemovedFunctions int
+	// RemovedTypes counts removed types. Their methods are removed with them.
+	RemovedTypes int
+	// RemovedValues counts removed package-level vars and consts.
+	RemovedValues int
+}
+
+// Removed returns the number of removed declarations.
+func (p DeadCodePackage) Removed() int {
+	return p.RemovedFunctions + p.RemovedTypes + p.RemovedValues
+}
+
+// DeadCode returns the per-package dead-code elimination report, sorted by
+// package path. It is empty unless the model was built with
+// EliminateDeadCode.
+func (m *SemanticModel) DeadCode() []DeadCodePackage {
+	if m == nil {
+		return nil
+	}
+	return slices.Clone(m.deadCode)
+}
+
+// reachableFile returns file without its unreachable declarations. It returns
+// file itself when nothing was eliminated from it.
+func (m *SemanticModel) reachableFile(file *ast.File) *ast.File {
+	if m == nil || m.unreachableDecls == nil || file == nil {
+		return file
+	}
+	decls := make([]ast.Decl, 0, len(file.Decls))
+	changed := false
+	for _, decl := range file.Decls {
+		switch typed := decl.(type) {
+		case *ast.FuncDecl:
+			if m.unreachableDecls[typed] {
+				changed = true
+				continue
+			}
+		case *ast.GenDecl:
+			specs := make([]ast.Spec, 0, len(typed.Specs))
+			for _, spec := range typed.Specs {
+				if !m.unreachableDecls[spec] {
+					specs = append(specs, spec)
+				}
+			}
+			if len(specs) != len(typed.Specs) {
+				changed = true
+				if len(specs) == 0 {
+					continue
+				}
+				pruned := *typed
+				pruned.Specs = specs
+				decl = &pruned
+			}
+		}
+		decls = append(decls, decl)
+	}
+	if !changed {
+		return file
+	}
+	pruned := *file
+	pruned.Decls = decls
+	return &pruned
+}
+
+// eliminateDeadCode marks the package-level declarations that cannot be
+// reached from the program roots so lowering can skip them.
+//
+// The roots are main (or the exported API of requested library packages),
+// every init function, every package var whose initializer calls a function,
+// and the exported API of packages that override packages depend on, since
+// handwritten TypeScript cannot be analyzed. A reachable named type keeps all
+// of its methods, which preserves interface method sets and reflect access.
+func (o *SemanticModelOwner) eliminateDeadCode(
+	ctx context.Context,
+	model *SemanticModel,
+	graph *PackageGraph,
+) []Diagnostic {
+	facts, diagnostics := o.overrideOwner.Facts(ctx)
+	if diagnosticsHaveErrors(diagnostics) {
+		return diagnostics
+	}
+
+	r := newSemanticReachability(model)
+	for _, pkgPath := range graph.RequestedPackagePaths {
+		semPkg := model.packages[pkgPath]
+		if semPkg == nil || semPkg.source == nil || semPkg.source.Types == nil {
+			continue
+		}
+		if semPkg.name == "main" {
+			r.markObject(semPkg.source.Types.Scope().Lookup("main"))
+			continue
+		}
+		r.markExportedAPI(semPkg)
+	}
+	for _, node := range graph.Nodes {
+		if !node.OverrideCandidate {
+			continue
+		}
+		deps := slices.Clone(node.Imports)
+		if facts != nil {
+			deps = append(deps, facts.Metadata(node.PkgPath).Dependencies...)
+		}
+		for _, dep := range deps {
+			if semPkg := model.packages[dep]; semPkg != nil {
+				r.markExportedAPI(semPkg)
+			}
+		}
+	}
+	for _, root := range r.initRoots {
+		r.markDecl(root)
+	}
+	for len(r.queue) != 0 {
+		if err := ctx.Err(); err != nil {
+			return []Diagnostic{contextCanceledDiagnostic(err)}
+		}
+		decl := r.queue[len(r.queue)-1]
+		r.queue = r.queue[:len(r.queue)-1]
+		r.walkDecl(decl)
+	}
+	model.unreachableDecls, model.deadCode = r.report()
+	return diagnostics
+}
+
+// semanticReachableDecl is one declaration that lowering emits as a unit: a
+// function or method, a type spec, or a value spec.
+type semanticReachableDecl struct {
+	node  ast.Node
+	pkg   *semanticPackage
+	kind  string
+	names int
+}
+
+type semanticReachability struct {
+	model     *SemanticModel
+	decls     []*semanticReachableDecl
+	byObject  map[types.Object]*semanticReachableDecl
+	initRoots []*semanticReachableDecl
+	reachable map[*semanticReachableDecl]bool
+	seenTypes map[types.Type]bool
+	queue     []*semanticReachableDecl
+}
+
+func newSemanticReachability(model *SemanticModel) *semanticReachability {
+	r := &semanticReachability{
+		model:     model,
+		byObject:  make(map[types.Object]*semanticReachableDecl),
+		reachable: make(map[*semanticReachableDecl]bool),
+		seenTypes: make(map[types.Type]bool),
+	}
+	pkgPaths := make([]string, 0, len(model.packages))
+	for pkgPath := range model.packages {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	slices.Sort(pkgPaths)
+	for _, pkgPath := range pkgPaths {
+		semPkg := model.packages[pkgPath]
+		if semPkg.source == nil || semPkg.source.TypesInfo == nil {
+			continue
+		}
+		for _, file := range semPkg.source.Syntax {
+			for _, decl := range file.Decls {
+				r.indexDecl(semPkg, decl)
+			}
+		}
+	}
+	return r
+}
+
+func (r *semanticReachability) indexDecl(semPkg *semanticPackage, decl ast.Decl) {
+	info := semPkg.source.TypesInfo
+	switch typed := decl.(type) {
+	case *ast.FuncDecl:
+		entry := &semanticReachableDecl{node: typed, pkg: semPkg, kind: "function", names: 1}
+		if typed.Recv != nil {
+			entry.kind = "method"
+			entry.names = 0
+		}
+		r.decls = append(r.decls, entry)
+		if typed.Recv == nil && typed.Name.Name == "init" {
+			r.initRoots = append(r.initRoots, entry)
+			return
+		}
+		if obj := info.Defs[typed.Name]; obj != nil {
+			r.byObject[obj] = entry
+		}
+	case *ast.GenDecl:
+		if typed.Tok == token.IMPORT {
+			return
+		}
+		for _, spec := range typed.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				entry := &semanticReachableDecl{node: spec, pkg: semPkg, kind: "type", names: 1}
+				r.decls = append(r.decls, entry)
+				if obj := info.Defs[spec.Name]; obj != nil {
+					r.byObject[obj] = entry
+				}
+			case *ast.ValueSpec:
+				entry := &semanticReachableDecl{node: spec, pkg: semPkg, kind: "value"}
+				r.decls = append(r.decls, entry)
+				for _, name := range spec.Names {
+					if name.Name != "_" {
+						entry.names++
+					}
+					if obj := info.Defs[name]; obj != nil {
+						r.byObject[obj] = entry
+					}
+				}
+				if typed.Tok == token.VAR && valueSpecCallsFunction(info, spec) {
+					r.initRoots = append(r.initRoots, entry)
+				}
+			}
+		}
+	}
+}
+
+// valueSpecCallsFunction reports whether a package var initializer calls a
+// function or receives from a channel, so package init may observe it.
+func valueSpecCallsFunction(info *types.Info, spec *ast.ValueSpec) bool {
+	calls := false
+	for _, value := range spec.Values {
+		ast.Inspect(value, func(node ast.Node) bool {
+			if calls {
+				return false
+			}
+			switch typed := node.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				tv, ok := info.Types[typed.Fun]
+				if ok && tv.IsType() {
+					return true
+				}
+				if ok && tv.IsBuiltin() && !isEffectfulBuiltinCall(typed.Fun) {
+					return true
+				}
+				calls = true
+				return false
+			case *ast.UnaryExpr:
+				if typed.Op == token.ARROW {
+					calls = true
+					return false
+				}
+			}
+			return true
+		})
+		if calls {
+			return true
+		}
+	}
+	return false
+}
+
+func isEffectfulBuiltinCall(fun ast.Expr) bool {
+	ident, ok := ast.Unparen(fun).(*ast.Ident)
+	if !ok {
+		return true
+	}
+	switch ident.Name {
+	case "panic", "print", "println", "close", "delete", "clear", "copy", "recover":
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *semanticReachability) markExportedAPI(semPkg *semanticPackage) {
+	if semPkg == nil || semPkg.source == nil || semPkg.source.Types == nil {
+		return
+	}
+	scope := semPkg.source.Types.Scope()
+	for _, name := range scope.Names() {
+		if token.IsExported(name) {
+			r.markObject(scope.Lookup(name))
+		}
+	}
+}
+
+func (r *semanticReachability) markObject(obj types.Object) {
+	switch typed := obj.(type) {
+	case nil:
+		return
+	case *types.Func:
+		obj = typed.Origin()
+	case *types.Var:
+		obj = typed.Origin()
+	}
+	if entry := r.byObject[obj]; entry != nil {
+		r.markDecl(entry)
+	}
+}
+
+func (r *semanticReachability) markDecl(entry *semanticReachableDecl) {
+	if entry == nil || r.reachable[entry] {
+		return
+	}
+	r.reachable[entry] = true
+	r.queue = append(r.queue, entry)
+}
+
+// walkDecl marks everything a reachable declaration refers to, including the
+// types it mentions only through inferred types and type arguments.
+func (r *semanticReachability) walkDecl(entry *semanticReachableDecl) {
+	info := entry.pkg.source.TypesInfo
+	if spec, ok := entry.node.(*ast.TypeSpec); ok {
+		if typeName, _ := info.Defs[spec.Name].(*types.TypeName); typeName != nil {
+			r.markType(typeName.Type())
+		}
+	}
+	ast.Inspect(entry.node, func(node ast.Node) bool {
+		switch typed := node.(type) {
+		case *ast.Ident:
+			r.markObject(info.Uses[typed])
+			if obj := info.Defs[typed]; obj != nil {
+				r.markType(obj.Type())
+			}
+			if instance, ok := info.Instances[typed]; ok {
+				r.markTypeList(instance.TypeArgs)
+				r.markType(instance.Type)
+			}
+		case *ast.SelectorExpr:
+			if selection := info.Selections[typed]; selection != nil {
+				r.markObject(selection.Obj())
+				r.markType(selection.Recv())
+			}
+		}
+		if expr, ok := node.(ast.Expr); ok {
+			r.markType(info.TypeOf(expr))
+		}
+		return true
+	})
+}
+
+func (r *semanticReachability) markTypeList(list *types.TypeList) {
+	if list == nil {
+		return
+	}
+	for typ := range list.Types() {
+		r.markType(typ)
+	}
+}
+
+// markType marks the named types reachable through typ. Marking a named type
+// also marks all of its methods.
+func (r *semanticReachability) markType(typ types.Type) {
+	if typ == nil || r.seenTypes[typ] {
+		return
+	}
+	r.seenTypes[typ] = true
+	switch typed := typ.(type) {
+	case *types.Alias:
+		r.markObject(typed.Obj())
+		r.markTypeList(typed.TypeArgs())
+		r.markType(typed.Rhs())
+	case *types.Named:
+		origin := typed.Origin()
+		r.markObject(origin.Obj())
+		for method := range origin.Methods() {
+			r.markObject(method)
+		}
+		r.markTypeList(typed.TypeArgs())
+		r.markType(origin.Underlying())
+	case *types.TypeParam:
+		r.markType(typed.Constraint())
+	case *types.Pointer:
+		r.markType(typed.Elem())
+	case *types.Slice:
+		r.markType(typed.Elem())
+	case *types.Array:
+		r.markType(typed.Elem())
+	case *types.Map:
+		r.markType(typed.Key())
+		r.markType(typed.Elem())
+	case *types.Chan:
+		r.markType(typed.Elem())
+	case *types.Tuple:
+		for v := range typed.Variables() {
+			r.markType(v.Type())
+		}
+	case *types.Signature:
+		if recv := typed.Recv(); recv != nil {
+			r.markType(recv.Type())
+		}
+		r.markType(typed.Params())
+		r.markType(typed.Results())
+	case *types.Struct:
+		for field := range typed.Fields() {
+			r.markType(field.Type())
+		}
+	case *types.Interface:
+		for method := range typed.ExplicitMethods() {
+			r.markType(method.Type())
+		}
+		for embedded := range typed.EmbeddedTypes() {
+			r.markType(embedded)
+		}
+	case *types.Union:
+		for term := range typed.Terms() {
+			r.markType(term.Type())
+		}
+	}
+}
+
+// report collects the unreachable declarations and counts them per package.
+func (r *semanticReachability) report() (map[ast.Node]bool, []DeadCodePackage) {
+	unreachable := make(map[ast.Node]bool)
+	byPkg := make(map[string]*DeadCodePackage)
+	var pkgPaths []string
+	for _, entry := range r.decls {
+		pkgPath := entry.pkg.pkgPath
+		report := byPkg[pkgPath]
+		if report == nil {
+			report = &DeadCodePackage{Package: pkgPath}
+			byPkg[pkgPath] = report
+			pkgPaths = append(pkgPaths, pkgPath)
+		}
+		report.Declarations += entry.names
+		if r.reachable[entry] {
+			continue
+		}
+		unreachable[entry.node] = true
+		switch entry.kind {
+		case "function":
+			report.RemovedFunctions++
+		case "type":
+			report.RemovedTypes++
+		case "value":
+			report.RemovedValues += entry.names
+		}
+	}
+	slices.Sort(pkgPaths)
+	deadCode := make([]DeadCodePackage, 0, len(pkgPaths))
+	for _, pkgPath := range pkgPaths {
+		deadCode = append(deadCode, *byPkg[pkgPath])
+	}
+	return unreachable, deadCode
+}
@@ -0,0 +1,163 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var reachabilityFixtureFiles = map[string]string{
+	"go.mod": "module example.test/reach\n\ngo 1.25.3\n",
+	"main.go": strings.Join([]string{
+		"package main",
+		"import \"example.test/reach/lib\"",
+		"type shape interface{ Area() int }",
+		"type square struct{ n int }",
+		"func (s square) Area() int { return s.n * s.n }",
+		"type unusedType struct{}",
+		"func (unusedType) Method() {}",
+		"func unusedFunc() int { return 1 }",
+		"var table = map[string]int{\"a\": 1}",
+		"func main() {",
+		"  var sh shape = square{3}",
+		"  println(sh.Area(), lib.Named(\"x\").String(), lib.NewBox(lib.KindB).Get(), lib.Registry())",
+		"}",
+		"",
+	}, "\n"),
+	"lib/lib.go": strings.Join([]string{
+		"package lib",
+		"type Kind int",
+		"const (",
+		"  KindA Kind = iota",
+		"  KindB",
+		"  KindC",
+		")",
+		"type Stringer interface{ String() string }",
+		"type Box[T any] struct{ v T }",
+		"func (b Box[T]) Get() T { return b.v }",
+		"func NewBox[T any](v T) Box[T] { return Box[T]{v: v} }",
+		"type named struct{ label string }",
+		"func (n *named) String() string { return helper(n.label) }",
+		"func Named(s string) Stringer { return &named{label: s} }",
+		"type Unused struct{ x int }",
+		"func (u Unused) Value() int { return u.x + unusedHelper() }",
+		"var registry = map[string]int{}",
+		"var initialized = setup()",
+		"func setup() int { registry[\"setup\"] = 1; return len(registry) }",
+		"func init() { registry[\"init\"] = 2 }",
+		"func Registry() int { return len(registry) + initialized }",
+		"var _ Stringer = (*named)(nil)",
+		"",
+	}, "\n"),
+	"lib/helpers.go": strings.Join([]string{
+		"package lib",
+		"func helper(s string) string { return \"<\" + s + \">\" }",
+		"func unusedHelper() int { return 3 }",
+		"",
+	}, "\n"),
+}
+
+func TestSemanticModelEliminatesUnreachableDeclarations(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, reachabilityFixtureFiles)
+	graph := loadPackageGraph(t, &CompileRequest{
+		Patterns:            []string{"."},
+		Dir:                 moduleDir,
+		OutputPath:          filepath.Join(t.TempDir(), "out"),
+		DependencyMode:      DependencyModeAll,
+		RuntimeEmissionMode: RuntimeEmissionModeEmit,
+	})
+
+	model := buildSemanticModel(t, graph)
+	if got := model.DeadCode(); len(got) != 0 {
+		t.Fatalf("expected no dead-code report without EliminateDeadCode, got %#v", got)
+	}
+
+	model, diagnostics := NewSemanticModelOwner().Build(context.Background(), graph, SemanticModelOptions{EliminateDeadCode: true})
+	if diagnosticsHaveErrors(diagnostics) {
+		t.Fatalf("semantic model build failed: %#v", diagnostics)
+	}
+	want := []DeadCodePackage{{
+		Package:          "example.test/reach",
+		Declarations:     6,
+		RemovedFunctions: 1,
+		RemovedTypes:     1,
+		RemovedValues:    1,
+	}, {
+		Package:          "example.test/reach/lib",
+		Declarations:     17,
+		RemovedFunctions: 1,
+		RemovedTypes:     1,
+		RemovedValues:    2,
+	}}
+	got := model.DeadCode()
+	if len(got) != len(want) {
+		t.Fatalf("unexpected dead-code report: %#v", got)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Fatalf("unexpected dead-code report for %s:\nwant %#v\ngot  %#v", want[idx].Package, want[idx], got[idx])
+		}
+	}
+	if got[1].Removed() != 4 {
+		t.Fatalf("expected 4 removed lib declarations, got %d", got[1].Removed())
+	}
+}
+
+func TestCompilePackagesEliminatesDeadCode(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, reachabilityFixtureFiles)
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{
+		Dir:               moduleDir,
+		OutputPath:        outputDir,
+		AllDependencies:   true,
+		EliminateDeadCode: true,
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	result, err := comp.CompilePackages(context.Background(), ".")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(result.DeadCode) != 2 {
+		t.Fatalf("expected a dead-code report for both packages, got %#v", result.DeadCode)
+	}
+	libDir := filepath.Join(outputDir, "@goscript", "example.test", "reach", "lib")
+	readOutput := func(name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(libDir, name))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return string(content)
+	}
+	lib := readOutput("lib.gs.ts")
+	for _, want := range []string{
+		"class named",
+		"public String(): string",
+		"class Box",
+		"export const KindB",
+		"function setup()",
+		"__goscriptInit0()",
+	} {
+		if !strings.Contains(lib, want) {
+			t.Fatalf("missing %q in generated output:\n%s", want, lib)
+		}
+	}
+	for _, unwanted := range []string{"class Unused", "KindA", "KindC"} {
+		if strings.Contains(lib, unwanted) {
+			t.Fatalf("unexpected %q in generated output:\n%s", unwanted, lib)
+		}
+	}
+	helpers := readOutput("helpers.gs.ts")
+	if !strings.Contains(helpers, "function helper(") || strings.Contains(helpers, "unusedHelper") {
+		t.Fatalf("unexpected helpers output:\n%s", helpers)
+	}
+	index := readOutput("index.ts")
+	if strings.Contains(index, "Unused") || strings.Contains(index, "KindA") {
+		t.Fatalf("unexpected eliminated export in index:\n%s", index)
+	}
+}
@@ -1,6 +1,7 @@
 package compiler
 
 import (
+	"go/ast"
 	"go/types"
 
 	"golang.org/x/tools/go/packages"
@@ -23,6 +24,12 @@ type SemanticModel struct {
 	interfaceImplementations []semanticInterfaceImplementation
 	asyncInterfaceMethods    map[string]bool
 	asyncInterfaceMethodObjs map[*types.Func]bool
+	preemptLoopLiterals      map[*ast.FuncLit]bool
+	preemptAsyncFunctions    []string
+	unreachableDecls         map[ast.Node]bool
+	deadCode                 []DeadCodePackage
+	// sources maps each parsed Go file to the content the loader parsed.
+	sources map[string][]byte
 }
 
 type semanticPackage struct {
@@ -95,6 +102,7 @@ type semanticFunction struct {
 	async           bool
 	asyncReasons    []string
 	calls           map[*types.Func]bool
+	goCalls         map[*types.Func]bool
 }
 
 type semanticInterfaceImplementation struct {
@@ -27,8 +27,26 @@ func NewSemanticModelOwner(overrideOwners ...*OverrideRegistryOwner) *SemanticMo
 	return &SemanticModelOwner{overrideOwner: overrideOwner}
 }
 
+// SemanticModelOptions configures optional semantic facts.
+type SemanticModelOptions struct {
+	// PreemptLoops colors goroutine entry points that contain loops async so
+	// lowering can add cooperative preemption checks to their loop bodies.
+	PreemptLoops bool
+	// EliminateDeadCode marks declarations unreachable from the program roots
+	// so lowering skips them.
+	EliminateDeadCode bool
+}
+
 // Build constructs semantic facts for a package graph.
-func (o *SemanticModelOwner) Build(ctx context.Context, graph *PackageGraph) (*SemanticModel, []Diagnostic) {
+func (o *SemanticModelOwner) Build(
+	ctx context.Context,
+	graph *PackageGraph,
+	opts ...SemanticModelOptions,
+) (*SemanticModel, []Diagnostic) {
+	var options SemanticModelOptions
+	if len(opts) != 0 {
+		options = opts[0]
+	}
 	if err := ctx.Err(); err != nil {
 		return nil, []Diagnostic{{
 			Severity: DiagnosticSeverityError,
@@ -45,6 +63,7 @@ func (o *SemanticModelOwner) Build(ctx context.Context, graph *PackageGraph) (*S
 	}
 
 	model := newSemanticModel()
+	model.sources = graph.sources
 	var diagnostics []Diagnostic
 	for _, node := range graph.Nodes {
 		if err := ctx.Err(); err != nil {
@@ -65,16 +84,24 @@ func (o *SemanticModelOwner) Build(ctx context.Context, graph *PackageGraph) (*S
 				Code:     "goscript/semantic:missing-package",
 				Message:  "package graph node is missing loaded package data",
 				Detail:   node.PkgPath,
+				Package:  node.PkgPath,
 			})
 			continue
 		}
-		diagnostics = append(diagnostics, o.buildPackage(ctx, model, node, pkg)...)
+		diagnostics = append(diagnostics, withDiagnosticPackage(o.buildPackage(ctx, model, node, pkg), node.PkgPath)...)
 	}
 	if diagnosticsHaveErrors(diagnostics) {
 		return model, diagnostics
 	}
 
-	model.functionCallers = semanticFunctionCallers(model)
+	if options.EliminateDeadCode {
+		diagnostics = append(diagnostics, o.eliminateDeadCode(ctx, model, graph)...)
+		if diagnosticsHaveErrors(diagnostics) {
+			return model, diagnostics
+		}
+	}
+
+	model.functionCallers = semanticFunctionCallers(model, true)
 	diagnostics = append(diagnostics, o.propagateFunctionAsync(ctx, model)...)
 	if diagnosticsHaveErrors(diagnostics) {
 		return model, diagnostics
@@ -98,25 +125,63 @@ func (o *SemanticModelOwner) Build(ctx context.Context, graph *PackageGraph) (*S
 	if diagnosticsHaveErrors(diagnostics) {
 		return model, diagnostics
 	}
+	diagnostics = append(diagnostics, o.propagateInterfaceAsync(ctx, model, interfaceGraph, anonymousInterfaceGraph)...)
+	if diagnosticsHaveErrors(diagnostics) || !options.PreemptLoops {
+		return model, diagnostics
+	}
+
+	// Functions colored by preemption only need to be async inside their own
+	// goroutines, so go statements starting them keep their callers sync.
+	model.functionCallers = semanticFunctionCallers(model, false)
+	syncFunctions := semanticSyncFunctions(model)
+	preemptLiterals, preemptDiagnostics := o.applyLoopPreemption(ctx, model)
+	diagnostics = append(diagnostics, preemptDiagnostics...)
+	if diagnosticsHaveErrors(diagnostics) {
+		return model, diagnostics
+	}
+	diagnostics = append(diagnostics, o.propagateFunctionAsync(ctx, model)...)
+	if diagnosticsHaveErrors(diagnostics) {
+		return model, diagnostics
+	}
+	diagnostics = append(diagnostics, o.propagateAsyncFunctionArguments(ctx, model)...)
+	if diagnosticsHaveErrors(diagnostics) {
+		return model, diagnostics
+	}
+	diagnostics = append(diagnostics, o.propagateInterfaceAsync(ctx, model, interfaceGraph, anonymousInterfaceGraph)...)
+	if diagnosticsHaveErrors(diagnostics) {
+		return model, diagnostics
+	}
+	recordPreemptAsyncFunctions(model, syncFunctions, preemptLiterals)
+	return model, diagnostics
+}
+
+// propagateInterfaceAsync colors interface methods and their callers until the
+// async function set stops growing.
+func (o *SemanticModelOwner) propagateInterfaceAsync(
+	ctx context.Context,
+	model *SemanticModel,
+	interfaceGraph []semanticInterfaceImplementationGraphEntry,
+	anonymousInterfaceGraph []semanticAnonymousInterfaceImplementation,
+) []Diagnostic {
+	var diagnostics []Diagnostic
 	for {
 		asyncCount := semanticAsyncFunctionCount(model)
 		diagnostics = append(diagnostics, o.applyInterfaceAsyncMethods(ctx, model, interfaceGraph)...)
 		if diagnosticsHaveErrors(diagnostics) {
-			return model, diagnostics
+			return diagnostics
 		}
 		diagnostics = append(diagnostics, o.applyAnonymousInterfaceAsyncMethods(ctx, model, anonymousInterfaceGraph)...)
 		if diagnosticsHaveErrors(diagnostics) {
-			return model, diagnostics
+			return diagnostics
 		}
 		diagnostics = append(diagnostics, o.propagateFunctionAsync(ctx, model)...)
 		if diagnosticsHaveErrors(diagnostics) {
-			return model, diagnostics
+			return diagnostics
 		}
 		if semanticAsyncFunctionCount(model) == asyncCount {
-			break
+			return diagnostics
 		}
 	}
-	return model, diagnostics
 }
 
 func newSemanticModel() *SemanticModel {
@@ -135,6 +200,7 @@ func newSemanticModel() *SemanticModel {
 		generatedImportTypes:     make(map[string]map[types.Type]bool),
 		asyncInterfaceMethods:    make(map[string]bool),
 		asyncInterfaceMethodObjs: make(map[*types.Func]bool),
+		preemptLoopLiterals:      make(map[*ast.FuncLit]bool),
 	}
 }
 
@@ -592,6 +658,7 @@ func (o *SemanticModelOwner) addFunction(
 		signature: signature,
 		position:  position,
 		calls:     make(map[*types.Func]bool),
+		goCalls:   make(map[*types.Func]bool),
 	}
 	if signature != nil && signature.Recv() != nil {
 		recv := signature.Recv().Type()
@@ -744,10 +811,13 @@ func (o *SemanticModelOwner) collectFunctionFacts(
 		if semFn == nil {
 			continue
 		}
+		var goCall *ast.CallExpr
 		ast.Inspect(fnDecl.Body, func(node ast.Node) bool {
 			switch typed := node.(type) {
 			case *ast.FuncLit:
 				return false
+			case *ast.GoStmt:
+				goCall = typed.Call
 			case *ast.SendStmt:
 				markFunctionAsync(semFn, "channel-send")
 			case *ast.SelectStmt:
@@ -766,11 +836,15 @@ func (o *SemanticModelOwner) collectFunctionFacts(
 					}
 				}
 			case *ast.CallExpr:
+				calls := semFn.calls
+				if typed == goCall {
+					calls = semFn.goCalls
+				}
 				if called := calledFunction(pkg, typed.Fun); called != nil {
-					semFn.calls[functionOriginOrSelf(called)] = true
+					calls[functionOriginOrSelf(called)] = true
 				}
 				if fun, ok := ast.Unparen(typed.Fun).(*ast.FuncLit); ok {
-					recordImmediateFuncLitAsyncFacts(model, pkg, overrideFacts, semFn, fun)
+					recordImmediateFuncLitAsyncFacts(model, pkg, overrideFacts, semFn, calls, fun)
 				}
 				if callUsesFunctionValue(pkg, typed.Fun) {
 					markFunctionAsync(semFn, "function-value-call")
@@ -781,11 +855,11 @@ func (o *SemanticModelOwner) collectFunctionFacts(
 				if callUsesInterfaceMethod(pkg, typed.Fun) {
 					markFunctionAsync(semFn, "interface-method-call")
 				}
-				if overrideFacts.IsMethodAsync(overrideCallPackage(pkg, typed.Fun), overrideCallMethod(pkg, typed.Fun)) {
-					markFunctionAsync(semFn, "override")
+				if pkgPath, method := overrideCallPackage(pkg, typed.Fun), overrideCallMethod(pkg, typed.Fun); overrideFacts.IsMethodAsync(pkgPath, method) {
+					markFunctionAsync(semFn, "override:"+pkgPath+"."+method)
 				}
-				if overrideFacts.IsFunctionAsync(overrideFunctionCallPackage(pkg, typed.Fun), overrideFunctionCallName(pkg, typed.Fun)) {
-					markFunctionAsync(semFn, "override")
+				if pkgPath, name := overrideFunctionCallPackage(pkg, typed.Fun), overrideFunctionCallName(pkg, typed.Fun); overrideFacts.IsFunctionAsync(pkgPath, name) {
+					markFunctionAsync(semFn, "override:"+pkgPath+"."+name)
 				}
 			}
 			return true
@@ -822,6 +896,7 @@ func recordImmediateFuncLitAsyncFacts(
 	pkg *packages.Package,
 	overrideFacts *OverrideFacts,
 	semFn *semanticFunction,
+	calls map[*types.Func]bool,
 	lit *ast.FuncLit,
 ) {
 	if lit == nil || lit.Body == nil {
@@ -842,7 +917,7 @@ func recordImmediateFuncLitAsyncFacts(
 		case *ast.CallExpr:
 			called := calledFunction(pkg, typed.Fun)
 			if called != nil {
-				semFn.calls[functionOriginOrSelf(called)] = true
+				calls[functionOriginOrSelf(called)] = true
 			}
 			if callUsesFunctionValue(pkg, typed.Fun) {
 				markFunctionAsync(semFn, "async-function-literal-call")
@@ -1260,7 +1335,10 @@ func (o *SemanticModelOwner) propagateFunctionAsync(ctx context.Context, model *
 	return nil
 }
 
-func semanticFunctionCallers(model *SemanticModel) map[*types.Func][]*semanticFunction {
+// semanticFunctionCallers indexes callers by called function. Calls made by go
+// statements, including those inside goroutine literals, are only included
+// when includeGoCalls is set.
+func semanticFunctionCallers(model *SemanticModel, includeGoCalls bool) map[*types.Func][]*semanticFunction {
 	callers := make(map[*types.Func][]*semanticFunction)
 	for _, semFn := range model.functions {
 		for called := range semFn.calls {
@@ -1270,6 +1348,16 @@ func semanticFunctionCallers(model *SemanticModel) map[*types.Func][]*semanticFu
 			}
 			callers[called] = append(callers[called], semFn)
 		}
+		if !includeGoCalls {
+			continue
+		}
+		for called := range semFn.goCalls {
+			called = functionOriginOrSelf(called)
+			if called == nil || semFn.calls[called] {
+				continue
+			}
+			callers[called] = append(callers[called], semFn)
+		}
 	}
 	return callers
 }
@@ -1721,9 +1809,9 @@ func (o *SemanticModelOwner) applyInterfaceAsyncMethods(
 			if implFn != nil && implFn.async {
 				model.markInterfaceMethodAsync(ifaceMethod)
 				if ifaceFn := semanticFunctionFor(model, ifaceMethod); ifaceFn != nil {
-					markFunctionAsync(ifaceFn, "interface-implementation")
+					markFunctionAsync(ifaceFn, "interface-implementation:"+model.functionFullName(implMethod))
 				}
-				markFunctionAsync(implFn, "interface-method")
+				markFunctionAsync(implFn, "interface-method:"+model.functionFullName(ifaceMethod))
 			}
 		}
 		model.interfaceImplementations = append(model.interfaceImplementations, implementation)
@@ -3,6 +3,7 @@ package compiler
 import (
 	"context"
 	"slices"
+	"strings"
 )
 
 // CompileService owns the v2 compiler pipeline.
@@ -86,7 +87,7 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 		result.OriginalPackages = append([]string(nil), req.Patterns...)
 	}
 
-	diagnostics := s.requestOwner.Validate(req)
+	diagnostics := withDiagnosticStage(s.requestOwner.Validate(req), DiagnosticStageRequest)
 	if diagnosticsHaveErrors(diagnostics) {
 		result.Diagnostics = diagnostics
 		return result, NewCompileError(diagnostics)
@@ -99,7 +100,7 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 	var cacheReplayTried bool
 	if s.cacheOwner.Enabled(req) {
 		graph, graphDiagnostics := s.graphOwner.LoadIdentity(ctx, req)
-		diagnostics = append(diagnostics, graphDiagnostics...)
+		diagnostics = append(diagnostics, withDiagnosticStage(graphDiagnostics, DiagnosticStagePackageGraph)...)
 		if graph != nil {
 			result.OriginalPackages = append([]string(nil), graph.RequestedPackagePaths...)
 		}
@@ -110,7 +111,7 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 
 		var factsDiagnostics []Diagnostic
 		overrideFacts, factsDiagnostics = s.overrideOwner.Facts(ctx)
-		diagnostics = append(diagnostics, factsDiagnostics...)
+		diagnostics = append(diagnostics, withDiagnosticStage(factsDiagnostics, DiagnosticStageOverrideRegistry)...)
 		if diagnosticsHaveErrors(diagnostics) {
 			result.Diagnostics = diagnostics
 			return result, NewCompileError(diagnostics)
@@ -118,7 +119,7 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 
 		if compilerCacheFastReplayAllowed(req, graph, overrideFacts) {
 			overridePlan, overrideDiagnostics := s.overrideOwner.CopyPlan(ctx, req, graph)
-			diagnostics = append(diagnostics, overrideDiagnostics...)
+			diagnostics = append(diagnostics, withDiagnosticStage(overrideDiagnostics, DiagnosticStageOverrideRegistry)...)
 			if diagnosticsHaveErrors(diagnostics) {
 				result.Diagnostics = diagnostics
 				return result, NewCompileError(diagnostics)
@@ -134,7 +135,7 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 	}
 
 	graph, graphDiagnostics := s.graphOwner.Load(ctx, req)
-	diagnostics = append(diagnostics, graphDiagnostics...)
+	diagnostics = append(diagnostics, withDiagnosticStage(graphDiagnostics, DiagnosticStagePackageGraph)...)
 	if graph != nil {
 		result.OriginalPackages = append([]string(nil), graph.RequestedPackagePaths...)
 	}
@@ -146,14 +147,14 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 	if overrideFacts == nil {
 		var factsDiagnostics []Diagnostic
 		overrideFacts, factsDiagnostics = s.overrideOwner.Facts(ctx)
-		diagnostics = append(diagnostics, factsDiagnostics...)
+		diagnostics = append(diagnostics, withDiagnosticStage(factsDiagnostics, DiagnosticStageOverrideRegistry)...)
 		if diagnosticsHaveErrors(diagnostics) {
 			result.Diagnostics = diagnostics
 			return result, NewCompileError(diagnostics)
 		}
 	}
 	parityDiagnostics := s.parityOwner.Verify(ctx, graph, overrideFacts)
-	diagnostics = append(diagnostics, parityDiagnostics...)
+	diagnostics = append(diagnostics, withDiagnosticStage(parityDiagnostics, DiagnosticStageOverrideRegistry)...)
 	if diagnosticsHaveErrors(diagnostics) {
 		result.Diagnostics = diagnostics
 		return result, NewCompileError(diagnostics)
@@ -164,7 +165,7 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 	if s.cacheOwner.Enabled(req) {
 		var overrideDiagnostics []Diagnostic
 		overridePlan, overrideDiagnostics = s.overrideOwner.CopyPlan(ctx, req, graph)
-		diagnostics = append(diagnostics, overrideDiagnostics...)
+		diagnostics = append(diagnostics, withDiagnosticStage(overrideDiagnostics, DiagnosticStageOverrideRegistry)...)
 		if diagnosticsHaveErrors(diagnostics) {
 			result.Diagnostics = diagnostics
 			return result, NewCompileError(diagnostics)
@@ -179,8 +180,11 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 		}
 	}
 
-	semanticModel, semanticDiagnostics := s.semanticOwner.Build(ctx, graph)
-	diagnostics = append(diagnostics, semanticDiagnostics...)
+	semanticModel, semanticDiagnostics := s.semanticOwner.Build(ctx, graph, SemanticModelOptions{
+		PreemptLoops:      req.PreemptLoops,
+		EliminateDeadCode: req.EliminateDeadCode,
+	})
+	diagnostics = append(diagnostics, withDiagnosticStage(semanticDiagnostics, DiagnosticStageSemanticModel)...)
 	if diagnosticsHaveErrors(diagnostics) {
 		result.Diagnostics = diagnostics
 		return result, NewCompileError(diagnostics)
@@ -189,7 +193,7 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 	if overridePlan == nil {
 		var overrideDiagnostics []Diagnostic
 		overridePlan, overrideDiagnostics = s.overrideOwner.CopyPlan(ctx, req, graph)
-		diagnostics = append(diagnostics, overrideDiagnostics...)
+		diagnostics = append(diagnostics, withDiagnosticStage(overrideDiagnostics, DiagnosticStageOverrideRegistry)...)
 		if diagnosticsHaveErrors(diagnostics) {
 			result.Diagnostics = diagnostics
 			return result, NewCompileError(diagnostics)
@@ -202,30 +206,37 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 		OutputPath:                req.OutputPath,
 		ProtobufTypeScriptBinding: req.ProtobufTypeScriptBinding,
 		TrimTypeInfo:              !packageGraphContainsPackage(graph, "reflect"),
+		SourceMaps:                req.SourceMaps,
+		PreemptLoops:              req.PreemptLoops,
+		CoverPackages:             req.CoverPackages,
 	})
-	diagnostics = append(diagnostics, loweringDiagnostics...)
+	diagnostics = append(diagnostics, withDiagnosticStage(loweringDiagnostics, DiagnosticStageLowering)...)
 	if diagnosticsHaveErrors(diagnostics) {
 		result.Diagnostics = diagnostics
 		return result, NewCompileError(diagnostics)
 	}
 
 	files, emitDiagnostics := s.emitterOwner.EmitToMemory(ctx, loweredProgram)
-	diagnostics = append(diagnostics, emitDiagnostics...)
+	diagnostics = append(diagnostics, withDiagnosticStage(emitDiagnostics, DiagnosticStageEmit)...)
 	if diagnosticsHaveErrors(diagnostics) {
 		result.Diagnostics = diagnostics
 		return result, NewCompileError(diagnostics)
 	}
 	compiledPackages, writeDiagnostics := s.emitterOwner.WriteFiles(ctx, req, loweredProgram, files)
-	diagnostics = append(diagnostics, writeDiagnostics...)
+	diagnostics = append(diagnostics, withDiagnosticStage(writeDiagnostics, DiagnosticStageEmit)...)
 	if diagnosticsHaveErrors(diagnostics) {
 		result.Diagnostics = diagnostics
 		return result, NewCompileError(diagnostics)
 	}
 	result.CompiledPackages = append(result.CompiledPackages, compiledPackages...)
+	for _, pkg := range loweredProgram.packages {
+		result.addRuntimeHelpers(pkg.sortedRuntimeHelpers())
+	}
+	result.DeadCode = semanticModel.DeadCode()
 	s.cacheOwner.StoreGenerated(req, cacheEntries, loweredProgram, files)
 
 	copiedPackages, copyDiagnostics := s.overrideOwner.CopyPackages(ctx, req, overridePlan)
-	diagnostics = append(diagnostics, copyDiagnostics...)
+	diagnostics = append(diagnostics, withDiagnosticStage(copyDiagnostics, DiagnosticStageOverrideRegistry)...)
 	if diagnosticsHaveErrors(diagnostics) {
 		result.CopiedPackages = append(result.CopiedPackages, copiedPackages...)
 		result.Diagnostics = diagnostics
@@ -238,6 +249,42 @@ func (s *CompileService) Compile(ctx context.Context, req *CompileRequest) (*Com
 	return result, nil
 }
 
+// BuildSemanticModel loads the package graph for a request and builds its
+// semantic model without lowering or writing output.
+func (s *CompileService) BuildSemanticModel(ctx context.Context, req *CompileRequest) (*SemanticModel, []Diagnostic, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if req != nil && strings.TrimSpace(req.OutputPath) == "" {
+		// Analysis never writes output, so it does not require an output path.
+		analysisReq := *req
+		analysisReq.OutputPath = "."
+		req = &analysisReq
+	}
+
+	diagnostics := withDiagnosticStage(s.requestOwner.Validate(req), DiagnosticStageRequest)
+	if diagnosticsHaveErrors(diagnostics) {
+		return nil, diagnostics, NewCompileError(diagnostics)
+	}
+	if !slices.Equal(s.overrideOwner.overrideDirs, req.OverrideDirs) {
+		return NewCompileService(req.OverrideDirs...).BuildSemanticModel(ctx, req)
+	}
+
+	graph, graphDiagnostics := s.graphOwner.Load(ctx, req)
+	diagnostics = append(diagnostics, withDiagnosticStage(graphDiagnostics, DiagnosticStagePackageGraph)...)
+	if diagnosticsHaveErrors(diagnostics) {
+		return nil, diagnostics, NewCompileError(diagnostics)
+	}
+	model, semanticDiagnostics := s.semanticOwner.Build(ctx, graph, SemanticModelOptions{
+		PreemptLoops: req.PreemptLoops,
+	})
+	diagnostics = append(diagnostics, withDiagnosticStage(semanticDiagnostics, DiagnosticStageSemanticModel)...)
+	if diagnosticsHaveErrors(diagnostics) {
+		return nil, diagnostics, NewCompileError(diagnostics)
+	}
+	return model, diagnostics, nil
+}
+
 func compilerCacheFastReplayAllowed(req *CompileRequest, graph *PackageGraph, facts *OverrideFacts) bool {
 	if req == nil || req.DependencyMode != DependencyModeAll {
 		return false
@@ -926,6 +926,45 @@ func TestCompilePackagesWrapsChannelSendInterfaceValues(t *testing.T) {
 	}
 }
 
+func TestCompilePackagesLowersCompoundAssignFromChannelReceive(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod": "module example.test/chanrecvassign\n\ngo 1.25.3\n",
+		"main.go": strings.Join([]string{
+			"package main",
+			"func sum(ch chan int) (r int) {",
+			"  r += <-ch",
+			"  r -= <-ch",
+			"  return",
+			"}",
+			"func main() {}",
+			"",
+		}, "\n"),
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+	outputFile := filepath.Join(outputDir, "@goscript", "example.test", "chanrecvassign", "main.gs.ts")
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	text := string(content)
+	for _, want := range []string{
+		"r = r + (await $.chanRecv(ch))",
+		"r = r - (await $.chanRecv(ch))",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in generated output:\n%s", want, text)
+		}
+	}
+}
+
 func TestCompilePackagesBindsFuncLiteralVarRefParams(t *testing.T) {
 	moduleDir := writePackageGraphFixture(t, map[string]string{
 		"go.mod": "module example.test/funclitvarref\n\ngo 1.25.3\n",
@@ -2967,6 +3006,68 @@ func TestCompilePackagesBoxesTypedNilInterfaceValues(t *testing.T) {
 	}
 }
 
+func TestCompilePackagesSeparatesNilCaseFromTypedNilInterfaces(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod": "module example.test/typed-nil-switch\n\ngo 1.25.3\n",
+		"main.go": strings.Join([]string{
+			"package main",
+			"type MyErr struct{}",
+			"func (e *MyErr) Error() string { return \"my err\" }",
+			"func classify(err error) string {",
+			"  switch v := err.(type) {",
+			"  case nil:",
+			"    _ = v",
+			"    return \"nil\"",
+			"  case *MyErr:",
+			"    return \"MyErr\"",
+			"  }",
+			"  return \"other\"",
+			"}",
+			"func either(x any) bool {",
+			"  switch x.(type) {",
+			"  case nil, int:",
+			"    return true",
+			"  }",
+			"  return false",
+			"}",
+			"func same(err error, e *MyErr) bool { return err == e }",
+			"func main() {",
+			"  var e *MyErr",
+			"  println(classify(e), either(nil), same(e, e))",
+			"}",
+			"",
+		}, "\n"),
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+	outputFile := filepath.Join(outputDir, "@goscript", "example.test", "typed-nil-switch", "main.gs.ts")
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	text := string(content)
+	for _, want := range []string{
+		"case __goscriptTypeSwitchValue == null:",
+		"let v: $.GoError = null",
+		"case __goscriptTypeSwitchValue == null || $.is(__goscriptTypeSwitchValue, { kind: $.TypeKind.Basic, name: \"int\" }):",
+		"return $.comparableEqual(err, $.interfaceValue<$.GoError>(e, \"*main.MyErr\"))",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in generated output:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "name: \"unknown\"") {
+		t.Fatalf("did not expect untyped nil type info in generated output:\n%s", text)
+	}
+}
+
 func TestCompilePackagesBoxesAliasPointerInterfacesWithTargetRuntimeType(t *testing.T) {
 	moduleDir := writePackageGraphFixture(t, map[string]string{
 		"go.mod": "module example.test/alias-interface-box\n\ngo 1.25.3\n",
@@ -3083,6 +3184,120 @@ func TestCompilePackagesEmitsGenericMethodsAliasesAndDictionaries(t *testing.T)
 	}
 }
 
+func TestCompilePackagesThreadsDictionariesThroughGenericStructsAndMethodValues(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod": "module example.test/genericdict\n\ngo 1.25.3\n",
+		"main.go": strings.Join([]string{
+			"package main",
+			"type Stringer interface { String() string }",
+			"type IntVal int",
+			"func (i IntVal) String() string { return \"int\" }",
+			"type Counter struct { n int }",
+			"func (c *Counter) Set(n int) { c.n = n }",
+			"type Setter[T any] interface { *T; Set(int) }",
+			"type Box[T Stringer] struct { v T }",
+			"func (b Box[T]) Zero() string { var zero T; return zero.String() }",
+			"func (b *Box[U]) Reset() { var zero U; b.v = zero }",
+			"func MethodValue[T Stringer](v T) string { f := v.String; return f() }",
+			"func AsInterface[T Stringer](v T) Stringer { return v }",
+			"func Make[T any, PT Setter[T]](n int) T { var v T; PT(&v).Set(n); return v }",
+			"func main() {",
+			"  b := Box[IntVal]{v: 7}",
+			"  println(b.Zero(), MethodValue(IntVal(1)), AsInterface(IntVal(2)).String())",
+			"  b.Reset()",
+			"  println(Make[Counter](5).n)",
+			"}",
+			"",
+		}, "\n"),
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+	outputFile := filepath.Join(outputDir, "@goscript", "example.test", "genericdict", "main.gs.ts")
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	text := string(content)
+	for _, want := range []string{
+		"declare public __typeArgs: $.GenericTypeArgs | undefined",
+		"Object.defineProperty(this, \"__typeArgs\", { value: __typeArgs, writable: true })",
+		"v: $.varRef(init?.v ?? ($.genericZero(__typeArgs, \"T\", null) as any))",
+		"const cloned = new Box(undefined, this.__typeArgs)",
+		"const __typeArgs = this.__typeArgs",
+		"const __typeArgs = { U: ($.isVarRef(this) ? (this as any).value : this)?.__typeArgs?.[\"T\"] } as $.GenericTypeArgs",
+		"new Box({v: 7}, {T: { type: { kind: $.TypeKind.Basic, name: \"int\", typeName: \"main.IntVal\" }",
+		"((__receiver) => () => $.callGenericMethod(__typeArgs, \"T\", \"String\", __receiver))(v)",
+		"return $.genericInterfaceValue<Stringer | null>(__typeArgs, \"T\", v)",
+		"await $.callGenericMethod(__typeArgs, \"PT\", \"Set\", v, n)",
+		"PT: { type: { kind: $.TypeKind.Pointer, elemType: \"main.Counter\" }",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in generated output:\n%s", want, text)
+		}
+	}
+}
+
+func TestCompilePackagesThreadsDictionariesThroughGenericNonStructMethodsAndLiterals(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod": "module example.test/genericnamedmethods\n\ngo 1.25.3\n",
+		"main.go": strings.Join([]string{
+			"package main",
+			"type Stringer interface { String() string }",
+			"type IntVal int",
+			"func (i IntVal) String() string { return \"int\" }",
+			"type List[T Stringer] []T",
+			"func (l List[T]) First() T { var zero T; if len(l) == 0 { return zero }; return l[0] }",
+			"func (l List[U]) Last() U { var zero U; return zero }",
+			"type Pair[T any] [2]T",
+			"type Point struct { X int }",
+			"func Make[T ~[2]int](a, b int) T { return T{a, b} }",
+			"func MakePoint[T ~struct{ X int }](x int) T { return T{X: x} }",
+			"func main() {",
+			"  var l List[IntVal]",
+			"  f := List[IntVal].First",
+			"  println(l.First().String(), l.Last().String(), f(l).String())",
+			"  println(Make[Pair[int]](1, 2)[0], MakePoint[Point](3).X)",
+			"}",
+			"",
+		}, "\n"),
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+	outputFile := filepath.Join(outputDir, "@goscript", "example.test", "genericnamedmethods", "main.gs.ts")
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	text := string(content)
+	for _, want := range []string{
+		"export function List_First(l: List, __typeArgs: $.GenericTypeArgs | undefined): any {",
+		"export function List_Last(l: List, __receiverTypeArgs: $.GenericTypeArgs | undefined): any {",
+		"const __typeArgs = { U: __receiverTypeArgs?.[\"T\"] } as $.GenericTypeArgs",
+		"IntVal_String(List_First(l, {T: { type: { kind: $.TypeKind.Basic, name: \"int\", typeName: \"main.IntVal\" }",
+		"((receiver: any, ...args: any[]) => (List_First as any)(receiver, {T: ",
+		"return [a, b]",
+		"return Object.assign($.genericZero(__typeArgs, \"T\", null), {X: x})",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in generated output:\n%s", want, text)
+		}
+	}
+}
+
 func TestCompilePackagesInfersGenericTypeArgsFromNamedArgument(t *testing.T) {
 	moduleDir := writePackageGraphFixture(t, map[string]string{
 		"go.mod": "module example.test/genericnamedarg\n\ngo 1.25.3\n",
@@ -3819,7 +4034,8 @@ func TestCompilePackagesEmitsAsyncChannelsSelectAndDefer(t *testing.T) {
 		"await $.chanSend($.pointerValue<Worker>(w).ch, v)",
 		"return await $.chanRecv($.pointerValue<Worker>(w).ch)",
 		"await using __defer = new $.AsyncDisposableStack()",
-		"queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {",
+		"$.go(async () => { await (async (): globalThis.Promise<void> => {",
+		"}, \"main.main\", \"example.test/async/main.go:21\")",
 		"$.selectStatement<any, void>([",
 		"let v = __goscriptSelect1Result.value",
 		"return $.selectVoidReturn()",
@@ -5110,6 +5326,80 @@ func TestCompilePackagesLowersSwitchesAndFunctionValueCalls(t *testing.T) {
 	}
 }
 
+func TestCompilePackagesLowersUnsupportedGotoAsStateMachine(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod": "module example.test/gotostate\n\ngo 1.25.3\n",
+		"main.go": strings.Join([]string{
+			"package main",
+			"func weave(x int, ch chan int) (r int) {",
+			"  defer func() { r %= 1000 }()",
+			"  steps := 0",
+			"top:",
+			"  r += x",
+			"  for j := 0; j < 3; j++ {",
+			"    if (r+j)%3 == 0 {",
+			"      goto middle",
+			"    }",
+			"  }",
+			"middle:",
+			"  r++",
+			"  steps++",
+			"  if r%2 == 0 && steps < 3 {",
+			"    goto top",
+			"  }",
+			"tail:",
+			"  r += <-ch",
+			"  steps++",
+			"  if steps < 4 {",
+			"    goto top",
+			"  }",
+			"  if steps < 6 {",
+			"    goto middle",
+			"  }",
+			"  if steps < 8 {",
+			"    goto tail",
+			"  }",
+			"  return",
+			"}",
+			"func main() {",
+			"  ch := make(chan int, 8)",
+			"  for i := 0; i < 8; i++ {",
+			"    ch <- i",
+			"  }",
+			"  println(weave(3, ch))",
+			"}",
+			"",
+		}, "\n"),
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "@goscript", "example.test", "gotostate", "main.gs.ts"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	text := string(content)
+	for _, want := range []string{
+		"__goscriptGotoLoop",
+		"case \"top\":",
+		"case \"middle\":",
+		"case \"tail\":",
+		"= \"middle\"",
+		"r = r + (await $.chanRecv(ch))",
+		"__defer.defer(",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in generated output:\n%s", want, text)
+		}
+	}
+}
+
 func TestCompilePackagesLowersMethodValuesWithFixedParameters(t *testing.T) {
 	moduleDir := writePackageGraphFixture(t, map[string]string{
 		"go.mod": "module example.test/methodvalue\n\ngo 1.25.3\n",
@@ -0,0 +1,288 @@
+package compiler
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	jsoniter "github.com/aperturerobotics/json-iterator-lite"
+)
+
+// tsBuilder is the TypeScript text buffer used by the emitter. When sourceMap
+// is set, statement renderers record the Go position of each generated line.
+// Renderers qualify runtime helpers through runtime so their use is recorded.
+type tsBuilder struct {
+	strings.Builder
+	sourceMap *sourceMapBuilder
+	runtime   *RuntimeContractOwner
+}
+
+// markSource maps the next generated text to pos until the next mark or clear.
+func (b *tsBuilder) markSource(pos sourcePosition) {
+	if b.sourceMap == nil || pos.line <= 0 || pos.file == "" {
+		return
+	}
+	b.sourceMap.mark(b.String(), pos)
+}
+
+// clearSource stops carrying the last mapped Go position onto later lines.
+func (b *tsBuilder) clearSource() {
+	if b.sourceMap == nil {
+		return
+	}
+	b.sourceMap.clear(b.String())
+}
+
+// beginFunction starts the generated line range of the Go function name.
+func (b *tsBuilder) beginFunction(name string) {
+	if b.sourceMap == nil || name == "" {
+		return
+	}
+	b.sourceMap.beginFunction(b.String(), name)
+}
+
+// endFunction closes the range opened by the matching beginFunction.
+func (b *tsBuilder) endFunction(name string) {
+	if b.sourceMap == nil || name == "" {
+		return
+	}
+	b.sourceMap.endFunction(b.String())
+}
+
+// sourceMapBuilder accumulates line mappings for one generated file.
+//
+// Mappings are recorded per Go statement. Generated lines that belong to a
+// statement but have no statement of their own, such as the body of a
+// function literal lowered to expression text, carry the last statement's
+// position so stack traces still land on the enclosing Go line.
+type sourceMapBuilder struct {
+	sources     []string
+	sourceIndex map[string]int
+	lines       [][]sourceMapSegment
+
+	scanned int
+	line    int
+	lineOff int
+
+	active    bool
+	activePos sourcePosition
+
+	functions []sourceMapFunction
+	open      []int
+}
+
+// sourceMapFunction is the generated line range of one Go function. The
+// runtime uses it to name stack frames the way Go tracebacks do.
+type sourceMapFunction struct {
+	name      string
+	startLine int
+	endLine   int
+}
+
+type sourceMapSegment struct {
+	column int
+	source int
+	line   int
+	col    int
+}
+
+func newSourceMapBuilder() *sourceMapBuilder {
+	return &sourceMapBuilder{sourceIndex: make(map[string]int)}
+}
+
+// advance moves the generated cursor to the end of text, carrying the active
+// mapping onto every generated line that starts along the way.
+func (m *sourceMapBuilder) advance(text string) {
+	for m.scanned < len(text) {
+		next := strings.IndexByte(text[m.scanned:], '\n')
+		if next < 0 {
+			m.scanned = len(text)
+			break
+		}
+		m.scanned += next + 1
+		m.line++
+		m.lineOff = m.scanned
+		if m.active {
+			m.add(0, m.activePos)
+		}
+	}
+}
+
+func (m *sourceMapBuilder) mark(text string, pos sourcePosition) {
+	m.advance(text)
+	m.activePos = pos
+	m.active = true
+	m.add(len(text)-m.lineOff, pos)
+}
+
+func (m *sourceMapBuilder) clear(text string) {
+	m.advance(text)
+	m.active = false
+}
+
+func (m *sourceMapBuilder) add(column int, pos sourcePosition) {
+	for len(m.lines) <= m.line {
+		m.lines = append(m.lines, nil)
+	}
+	segments := m.lines[m.line]
+	if n := len(segments); n != 0 && segments[n-1].column == column {
+		// A nested statement starting at the same generated point wins.
+		segments = segments[:n-1]
+	}
+	source, ok := m.sourceIndex[pos.file]
+	if !ok {
+		source = len(m.sources)
+		m.sources = append(m.sources, pos.file)
+		m.sourceIndex[pos.file] = source
+	}
+	col := pos.column - 1
+	if col < 0 {
+		col = 0
+	}
+	m.lines[m.line] = append(segments, sourceMapSegment{
+		column: column,
+		source: source,
+		line:   pos.line - 1,
+		col:    col,
+	})
+}
+
+func (m *sourceMapBuilder) beginFunction(text string, name string) {
+	m.advance(text)
+	m.open = append(m.open, len(m.functions))
+	m.functions = append(m.functions, sourceMapFunction{name: name, startLine: m.line, endLine: m.line})
+}
+
+func (m *sourceMapBuilder) endFunction(text string) {
+	if len(m.open) == 0 {
+		return
+	}
+	m.advance(text)
+	idx := m.open[len(m.open)-1]
+	m.open = m.open[:len(m.open)-1]
+	m.functions[idx].endLine = m.line
+}
+
+// mappings encodes the recorded segments in the v3 base64 VLQ format.
+func (m *sourceMapBuilder) mappings() string {
+	var b strings.Builder
+	var prevSource, prevLine, prevCol int
+	for lineIdx, segments := range m.lines {
+		if lineIdx != 0 {
+			b.WriteByte(';')
+		}
+		prevColumn := 0
+		for segIdx, segment := range segments {
+			if segIdx != 0 {
+				b.WriteByte(',')
+			}
+			writeSourceMapVLQ(&b, segment.column-prevColumn)
+			writeSourceMapVLQ(&b, segment.source-prevSource)
+			writeSourceMapVLQ(&b, segment.line-prevLine)
+			writeSourceMapVLQ(&b, segment.col-prevCol)
+			prevColumn = segment.column
+			prevSource = segment.source
+			prevLine = segment.line
+			prevCol = segment.col
+		}
+	}
+	return b.String()
+}
+
+const sourceMapBase64 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+func writeSourceMapVLQ(b *strings.Builder, value int) {
+	vlq := value << 1
+	if value < 0 {
+		vlq = (-value << 1) | 1
+	}
+	for {
+		digit := vlq & 0x1f
+		vlq >>= 5
+		if vlq != 0 {
+			digit |= 0x20
+		}
+		b.WriteByte(sourceMapBase64[digit])
+		if vlq == 0 {
+			return
+		}
+	}
+}
+
+// render returns the v3 source map JSON for the generated file. Sources are
+// relative to outputDir when possible so the map survives moving the tree
+// together with the Go module, and sourcesContent embeds the Go source the
+// loader parsed so browser debuggers do not need to fetch it. The
+// x_goscript_functions extension lists the generated line range of each Go
+// function.
+func (m *sourceMapBuilder) render(file string, outputDir string, parsed map[string][]byte) string {
+	sources := make([]string, 0, len(m.sources))
+	contents := make([]string, 0, len(m.sources))
+	for _, source := range m.sources {
+		sources = append(sources, sourceMapSourcePath(source, outputDir))
+		contents = append(contents, string(parsed[source]))
+	}
+
+	var buf bytes.Buffer
+	stream := jsoniter.NewStream(&buf, 4096, 2)
+	stream.WriteObjectStart()
+	stream.WriteObjectField("version")
+	stream.WriteInt(3)
+	stream.WriteMore()
+	stream.WriteObjectField("file")
+	stream.WriteString(file)
+	stream.WriteMore()
+	writeStringArray(stream, "sources", sources)
+	stream.WriteMore()
+	writeStringArray(stream, "sourcesContent", contents)
+	stream.WriteMore()
+	writeStringArray(stream, "names", nil)
+	stream.WriteMore()
+	stream.WriteObjectField("mappings")
+	stream.WriteString(m.mappings())
+	if len(m.functions) != 0 {
+		// Each entry is [startLine, endLine, name] with zero-based
+		// generated lines and an exclusive end.
+		stream.WriteMore()
+		stream.WriteObjectField("x_goscript_functions")
+		stream.WriteArrayStart()
+		for idx, fn := range m.functions {
+			if idx != 0 {
+				stream.WriteMore()
+			}
+			stream.WriteArrayStart()
+			stream.WriteInt(fn.startLine)
+			stream.WriteMore()
+			stream.WriteInt(fn.endLine)
+			stream.WriteMore()
+			stream.WriteString(fn.name)
+			stream.WriteArrayEnd()
+		}
+		stream.WriteArrayEnd()
+	}
+	stream.WriteObjectEnd()
+	if stream.Error != nil {
+		return ""
+	}
+	return string(stream.Buffer()) + "\n"
+}
+
+func sourceMapSourcePath(source string, outputDir string) string {
+	if outputDir == "" || !filepath.IsAbs(source) {
+		return filepath.ToSlash(source)
+	}
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	rel, err := filepath.Rel(absOutput, source)
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// sourceMapURLComment is the trailer that points a generated file at its map.
+func sourceMapURLComment(mapName string) string {
+	return "//# sourceMappingURL=" + mapName + "\n"
+}
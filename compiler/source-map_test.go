@@ -0,0 +1,226 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceMapVLQ(t *testing.T) {
+	for _, tc := range []struct {
+		value int
+		want  string
+	}{
+		{0, "A"},
+		{1, "C"},
+		{-1, "D"},
+		{15, "e"},
+		{16, "gB"},
+		{-17, "jB"},
+		{1000, "w+B"},
+	} {
+		var b strings.Builder
+		writeSourceMapVLQ(&b, tc.value)
+		if got := b.String(); got != tc.want {
+			t.Fatalf("vlq(%d) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestSourceMapRenderEmbedsParsedSource(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(source, []byte("package edited\n"), 0o644); err != nil {
+		t.Fatal(err.Error())
+	}
+	m := newSourceMapBuilder()
+	m.add(0, sourcePosition{file: source, line: 1, column: 1})
+	var sourceMap struct {
+		SourcesContent []string `json:"sourcesContent"`
+	}
+	rendered := m.render("main.gs.ts", "", map[string][]byte{source: []byte("package parsed\n")})
+	if err := json.Unmarshal([]byte(rendered), &sourceMap); err != nil {
+		t.Fatalf("parse source map: %v\n%s", err, rendered)
+	}
+	if len(sourceMap.SourcesContent) != 1 || sourceMap.SourcesContent[0] != "package parsed\n" {
+		t.Fatalf("expected the parsed source, got %#v", sourceMap.SourcesContent)
+	}
+}
+
+func TestCompilePackagesEmitsSourceMaps(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod": "module example.test/sourcemap\n\ngo 1.25.3\n",
+		"main.go": strings.Join([]string{
+			"package sourcemap",
+			"",
+			"func Add(a int, b int) int {",
+			"\ttotal := a + b",
+			"\tif total > 10 {",
+			"\t\tpanic(\"too big\")",
+			"\t}",
+			"\treturn total",
+			"}",
+			"",
+			"type Counter struct{ n int }",
+			"",
+			"func (c *Counter) Inc() { c.n++ }",
+			"",
+		}, "\n"),
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir, SourceMaps: true}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	pkgDir := filepath.Join(outputDir, "@goscript", "example.test", "sourcemap")
+	generated, err := os.ReadFile(filepath.Join(pkgDir, "main.gs.ts"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.HasSuffix(string(generated), "//# sourceMappingURL=main.gs.ts.map\n") {
+		t.Fatalf("missing sourceMappingURL trailer:\n%s", generated)
+	}
+	data, err := os.ReadFile(filepath.Join(pkgDir, "main.gs.ts.map"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var sourceMap struct {
+		Version        int      `json:"version"`
+		File           string   `json:"file"`
+		Sources        []string `json:"sources"`
+		SourcesContent []string `json:"sourcesContent"`
+		Mappings       string   `json:"mappings"`
+		Functions      [][]any  `json:"x_goscript_functions"`
+	}
+	if err := json.Unmarshal(data, &sourceMap); err != nil {
+		t.Fatalf("parse source map: %v\n%s", err, data)
+	}
+	if sourceMap.Version != 3 || sourceMap.File != "main.gs.ts" {
+		t.Fatalf("unexpected source map header: %#v", sourceMap)
+	}
+	if len(sourceMap.Sources) != 1 || !strings.HasSuffix(sourceMap.Sources[0], "main.go") || filepath.IsAbs(sourceMap.Sources[0]) {
+		t.Fatalf("expected one relative main.go source, got %#v", sourceMap.Sources)
+	}
+	if resolved := filepath.Join(pkgDir, filepath.FromSlash(sourceMap.Sources[0])); resolved != filepath.Join(moduleDir, "main.go") {
+		t.Fatalf("source %q resolves to %q", sourceMap.Sources[0], resolved)
+	}
+	if len(sourceMap.SourcesContent) != 1 || !strings.Contains(sourceMap.SourcesContent[0], "panic(\"too big\")") {
+		t.Fatalf("expected embedded Go source, got %#v", sourceMap.SourcesContent)
+	}
+
+	goLines := decodeSourceMapLines(t, sourceMap.Mappings)
+	lines := strings.Split(string(generated), "\n")
+	for want, goLine := range map[string]int{
+		"export function Add(": 3,
+		"let total = a + b":    4,
+		"if (total > 10)":      5,
+		"$.panic(\"too big\")": 6,
+		"return total":         8,
+	} {
+		found := false
+		for idx, line := range lines {
+			if !strings.Contains(line, want) {
+				continue
+			}
+			found = true
+			if got := goLines[idx]; got != goLine {
+				t.Fatalf("generated line %d %q maps to Go line %d, want %d", idx+1, line, got, goLine)
+			}
+		}
+		if !found {
+			t.Fatalf("missing %q in generated output:\n%s", want, generated)
+		}
+	}
+
+	functions := make(map[string]string, len(sourceMap.Functions))
+	for _, fn := range sourceMap.Functions {
+		start, end := int(fn[0].(float64)), int(fn[1].(float64))
+		functions[fn[2].(string)] = strings.TrimSpace(lines[start]) + " ... " + strings.TrimSpace(lines[end-1])
+	}
+	if got := functions["example.test/sourcemap.Add"]; !strings.HasPrefix(got, "export function Add(") || !strings.HasSuffix(got, "}") {
+		t.Fatalf("unexpected range for Add: %q in %#v", got, sourceMap.Functions)
+	}
+	if got := functions["example.test/sourcemap.(*Counter).Inc"]; !strings.HasPrefix(got, "public Inc(") || !strings.HasSuffix(got, "}") {
+		t.Fatalf("unexpected range for (*Counter).Inc: %q in %#v", got, sourceMap.Functions)
+	}
+}
+
+func TestCompilePackagesOmitsSourceMapsByDefault(t *testing.T) {
+	moduleDir := writePackageGraphFixture(t, map[string]string{
+		"go.mod":  "module example.test/nosourcemap\n\ngo 1.25.3\n",
+		"main.go": "package nosourcemap\n\nfunc Value() int { return 1 }\n",
+	})
+	outputDir := filepath.Join(t.TempDir(), "output")
+	comp, err := NewCompiler(&Config{Dir: moduleDir, OutputPath: outputDir}, nil, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := comp.CompilePackages(context.Background(), "."); err != nil {
+		t.Fatal(err.Error())
+	}
+	pkgDir := filepath.Join(outputDir, "@goscript", "example.test", "nosourcemap")
+	generated, err := os.ReadFile(filepath.Join(pkgDir, "main.gs.ts"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if strings.Contains(string(generated), "sourceMappingURL") {
+		t.Fatalf("unexpected sourceMappingURL without source maps:\n%s", generated)
+	}
+	if _, err := os.Stat(filepath.Join(pkgDir, "main.gs.ts.map")); !os.IsNotExist(err) {
+		t.Fatalf("expected no source map file, stat err: %v", err)
+	}
+}
+
+// decodeSourceMapLines returns the 1-based Go line of the first segment on each
+// generated line, indexed by 0-based generated line.
+func decodeSourceMapLines(t *testing.T, mappings string) map[int]int {
+	t.Helper()
+	lines := make(map[int]int)
+	var sourceLine int
+	for genLine, group := range strings.Split(mappings, ";") {
+		if group == "" {
+			continue
+		}
+		for segIdx, segment := range strings.Split(group, ",") {
+			values := decodeSourceMapVLQs(t, segment)
+			if len(values) < 4 {
+				t.Fatalf("short source map segment %q", segment)
+			}
+			sourceLine += values[2]
+			if segIdx == 0 {
+				lines[genLine] = sourceLine + 1
+			}
+		}
+	}
+	return lines
+}
+
+func decodeSourceMapVLQs(t *testing.T, segment string) []int {
+	t.Helper()
+	var values []int
+	var value, shift int
+	for _, ch := range segment {
+		digit := strings.IndexRune(sourceMapBase64, ch)
+		if digit < 0 {
+			t.Fatalf("invalid VLQ digit %q", ch)
+		}
+		value += (digit & 0x1f) << shift
+		if digit&0x20 != 0 {
+			shift += 5
+			continue
+		}
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+	return values
+}
@@ -0,0 +1,92 @@
+package tsworkspace
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WriteModuleShims writes node_modules/@goscript/<path>.js re-exports for
+// every generated TypeScript file under each output root's @goscript tree.
+// Bun resolves bare @goscript/... imports through node_modules, so the shims
+// go into the workspace and into each output root, where generated packages
+// import each other.
+func (o *Owner) WriteModuleShims(outputRoots []string) Result {
+	seen := make(map[string]bool)
+	shimRoots := moduleShimRoots(o.workDir, outputRoots)
+	for _, outputRoot := range outputRoots {
+		if outputRoot == "" {
+			continue
+		}
+		root := filepath.Join(outputRoot, "@goscript")
+		if _, err := os.Stat(root); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return Result{Phase: PhaseWorkspace, Error: errors.Wrap(err, "stat GoScript runtime output root").Error()}
+		}
+		err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || filepath.Ext(path) != ".ts" {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			shimRel := filepath.Join("node_modules", "@goscript", strings.TrimSuffix(rel, ".ts")+".js")
+			for _, shimRoot := range shimRoots {
+				shimPath := filepath.Join(shimRoot, shimRel)
+				if seen[shimPath] {
+					continue
+				}
+				seen[shimPath] = true
+				importPath, err := filepath.Rel(filepath.Dir(shimPath), path)
+				if err != nil {
+					return err
+				}
+				importPath = filepath.ToSlash(importPath)
+				if !strings.HasPrefix(importPath, ".") {
+					importPath = "./" + importPath
+				}
+				if err := writeModuleShim(shimPath, "export * from "+strconv.Quote(importPath)+"\n"); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return Result{Phase: PhaseWorkspace, Error: errors.Wrap(err, "materialize GoScript runtime module shims").Error()}
+		}
+	}
+	return Result{Phase: PhaseWorkspace}
+}
+
+func moduleShimRoots(workDir string, outputRoots []string) []string {
+	seen := make(map[string]bool)
+	var roots []string
+	for _, root := range append([]string{workDir}, outputRoots...) {
+		if root == "" {
+			continue
+		}
+		clean := filepath.Clean(root)
+		if seen[clean] {
+			continue
+		}
+		seen[clean] = true
+		roots = append(roots, clean)
+	}
+	return roots
+}
+
+func writeModuleShim(path string, data string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(data), 0o644)
+}
@@ -3,6 +3,7 @@ package tsworkspace
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -44,6 +45,20 @@ func NewOwner(workDir, toolDir string) *Owner {
 	}
 }
 
+// NewTempWorkDir creates a workspace directory named prefix followed by a
+// random suffix in the .tmp directory of dir. The caller removes it.
+func NewTempWorkDir(dir string, prefix string) (string, error) {
+	workRoot := filepath.Join(dir, ".tmp")
+	if err := os.MkdirAll(workRoot, 0o755); err != nil {
+		return "", errors.Wrap(err, "create goscript work root")
+	}
+	workDir, err := os.MkdirTemp(workRoot, prefix)
+	if err != nil {
+		return "", errors.Wrap(err, "create goscript work directory")
+	}
+	return workDir, nil
+}
+
 // WorkDir returns the workspace directory.
 func (o *Owner) WorkDir() string {
 	if o == nil {
@@ -97,9 +112,69 @@ func (o *Owner) WriteJSON(phase Phase, name string, value any) Result {
 
 // RunTool finds and executes a TypeScript workspace tool.
 func (o *Owner) RunTool(ctx context.Context, phase Phase, dir string, name string, args ...string) Result {
+	var output bytes.Buffer
+	result := o.RunToolIO(ctx, phase, dir, ToolIO{Stdout: &output, Stderr: &output}, name, args...)
+	result.Output = output.String()
+	return result
+}
+
+// RunToolLines runs a TypeScript workspace tool like RunTool and also passes
+// each line of its combined output to onLine as the tool writes it. A final
+// line without a newline is passed once the tool exits.
+func (o *Owner) RunToolLines(ctx context.Context, phase Phase, dir string, onLine func(line string), name string, args ...string) Result {
+	var output bytes.Buffer
+	lines := &lineWriter{onLine: onLine}
+	stream := io.MultiWriter(&output, lines)
+	result := o.RunToolIO(ctx, phase, dir, ToolIO{Stdout: stream, Stderr: stream}, name, args...)
+	lines.flush()
+	result.Output = output.String()
+	return result
+}
+
+// lineWriter splits written bytes into lines for onLine.
+type lineWriter struct {
+	onLine  func(line string)
+	partial []byte
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	w.partial = append(w.partial, data...)
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		w.onLine(strings.TrimSuffix(string(w.partial[:idx]), "\r"))
+		w.partial = w.partial[idx+1:]
+	}
+	return len(data), nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.partial) != 0 {
+		w.onLine(strings.TrimSuffix(string(w.partial), "\r"))
+		w.partial = nil
+	}
+}
+
+// ToolIO connects a tool process to standard streams and extra environment.
+type ToolIO struct {
+	// Stdin is the tool's standard input. Nil reads from the null device.
+	Stdin io.Reader
+	// Stdout receives the tool's standard output.
+	Stdout io.Writer
+	// Stderr receives the tool's standard error.
+	Stderr io.Writer
+	// Env holds KEY=value entries added to the inherited environment.
+	Env []string
+}
+
+// RunToolIO finds and executes a TypeScript workspace tool attached to stdio
+// instead of capturing its output.
+func (o *Owner) RunToolIO(ctx context.Context, phase Phase, dir string, stdio ToolIO, name string, args ...string) Result {
 	tool, err := o.FindTool(name)
 	if err != nil {
-		return Result{Phase: phase, Error: err.Error()}
+		return Result{Phase: phase, Error: err.Error(), ExitCode: -1}
 	}
 	if dir == "" {
 		dir = o.workDir
@@ -113,15 +188,18 @@ func (o *Owner) RunTool(ctx context.Context, phase Phase, dir string, name strin
 	}
 	cmd.WaitDelay = 5 * time.Second
 	cmd.Dir = dir
-	var output bytes.Buffer
-	cmd.Stdout = &output
-	cmd.Stderr = &output
+	cmd.Stdin = stdio.Stdin
+	cmd.Stdout = stdio.Stdout
+	cmd.Stderr = stdio.Stderr
+	if len(stdio.Env) != 0 {
+		cmd.Env = append(os.Environ(), stdio.Env...)
+	}
 	err = cmd.Run()
 	result := Result{
-		Phase:   phase,
-		Command: append([]string{tool}, args...),
-		Output:  output.String(),
-		Elapsed: time.Since(start),
+		Phase:    phase,
+		Command:  append([]string{tool}, args...),
+		Elapsed:  time.Since(start),
+		ExitCode: cmd.ProcessState.ExitCode(),
 	}
 	if err != nil {
 		result.Error = err.Error()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -57,6 +58,47 @@ func TestOwnerRunsToolsWithCapturedOutput(t *testing.T) {
 	}
 }
 
+func TestOwnerRunsToolsWithStreamedLines(t *testing.T) {
+	dir := t.TempDir()
+	owner := NewOwner(dir, dir)
+
+	var lines []string
+	result := owner.RunToolLines(context.Background(), PhaseRuntime, dir, func(line string) {
+		lines = append(lines, line)
+	}, "go", "env", "GOOS", "GOARCH")
+	if result.Failed() {
+		t.Fatalf("run tool: %s\n%s", result.Error, result.Output)
+	}
+	if want := strings.Split(strings.TrimSpace(result.Output), "\n"); !slices.Equal(lines, want) {
+		t.Fatalf("streamed lines = %q, want the captured output lines %q", lines, want)
+	}
+}
+
+func TestOwnerRunsToolsAttachedToStdio(t *testing.T) {
+	dir := t.TempDir()
+	owner := NewOwner(dir, dir)
+
+	var stdout strings.Builder
+	result := owner.RunToolIO(context.Background(), PhaseRuntime, dir, ToolIO{
+		Stdout: &stdout,
+		Env:    []string{"GOFLAGS=-tags=goscriptrun"},
+	}, "go", "env", "GOFLAGS")
+	if result.Failed() {
+		t.Fatalf("run tool: %s", result.Error)
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "-tags=goscriptrun" {
+		t.Fatalf("expected tool output on stdout with extra env, got %q", got)
+	}
+	if result.Output != "" || result.ExitCode != 0 {
+		t.Fatalf("expected uncaptured output and exit code 0, got %q and %d", result.Output, result.ExitCode)
+	}
+
+	result = owner.RunToolIO(context.Background(), PhaseRuntime, dir, ToolIO{}, "go", "goscript-unknown-command")
+	if !result.Failed() || result.ExitCode != 2 {
+		t.Fatalf("expected exit code 2 from an unknown go command, got %d (%s)", result.ExitCode, result.Error)
+	}
+}
+
 func TestOwnerWritesNodeAmbientTypes(t *testing.T) {
 	dir := t.TempDir()
 	owner := NewOwner(dir, dir)
@@ -9,6 +9,10 @@ type Result struct {
 	Output  string
 	Error   string
 	Elapsed time.Duration
+
+	// ExitCode is the process exit code, or -1 when the process did not
+	// start or was killed by a signal.
+	ExitCode int
 }
 
 // Failed returns true when the operation failed.
@@ -106,6 +106,11 @@ func (o *TypeScriptEmitOwner) WriteFiles(
 			if err := writeFileString(path, files[filePath], 0o644); err != nil {
 				diagnostics = append(diagnostics, emitError("write TypeScript file", path, err))
 			}
+			if sourceMap, ok := files[filePath+".map"]; ok {
+				if err := writeFileString(path+".map", sourceMap, 0o644); err != nil {
+					diagnostics = append(diagnostics, emitError("write source map", path+".map", err))
+				}
+			}
 		}
 		indexPath := "@goscript/" + pkg.pkgPath + "/index.ts"
 		if err := writeFileString(filepath.Join(pkgDir, "index.ts"), files[indexPath], 0o644); err != nil {
@@ -154,15 +159,32 @@ func (o *TypeScriptEmitOwner) EmitToMemory(
 			return files, []Diagnostic{contextCanceledDiagnostic(err)}
 		}
 		for _, file := range pkg.files {
-			files["@goscript/"+pkg.pkgPath+"/"+file.outputName] = o.renderLoweredFile(pkg, file, program.trimTypeInfo)
+			filePath := "@goscript/" + pkg.pkgPath + "/" + file.outputName
+			if !program.sourceMaps {
+				files[filePath] = o.renderLoweredFile(pkg, file, program.trimTypeInfo, nil)
+				continue
+			}
+			sourceMap := newSourceMapBuilder()
+			files[filePath] = o.renderLoweredFile(pkg, file, program.trimTypeInfo, sourceMap) +
+				sourceMapURLComment(file.outputName+".map")
+			outputDir := ""
+			if program.outputPath != "" {
+				outputDir = filepath.Join(program.outputPath, "@goscript", filepath.FromSlash(pkg.pkgPath))
+			}
+			files[filePath+".map"] = sourceMap.render(file.outputName, outputDir, program.sources)
 		}
 		files["@goscript/"+pkg.pkgPath+"/index.ts"] = renderIndex(pkg)
 	}
 	return files, nil
 }
 
-func (o *TypeScriptEmitOwner) renderLoweredFile(pkg *loweredPackage, file *loweredFile, trimTypeInfo bool) string {
-	var b strings.Builder
+func (o *TypeScriptEmitOwner) renderLoweredFile(
+	pkg *loweredPackage,
+	file *loweredFile,
+	trimTypeInfo bool,
+	sourceMap *sourceMapBuilder,
+) string {
+	b := tsBuilder{sourceMap: sourceMap, runtime: o.runtimeOwner.recordingUsage(pkg.runtimeHelpers)}
 	b.Grow(estimateLoweredFileSize(file))
 	if file.sourcePath != "" {
 		b.WriteString("// Generated file based on ")
@@ -207,7 +229,7 @@ func (o *TypeScriptEmitOwner) renderLoweredFile(pkg *loweredPackage, file *lower
 	writeDecl := func(decl loweredDecl) {
 		if decl.structType != nil {
 			writeSeparator()
-			renderStruct(&b, decl.structType, o.runtimeOwner, trimTypeInfo)
+			renderStruct(&b, decl.structType, trimTypeInfo)
 			return
 		}
 		if decl.function != nil {
@@ -219,8 +241,10 @@ func (o *TypeScriptEmitOwner) renderLoweredFile(pkg *loweredPackage, file *lower
 			return
 		}
 		writeSeparator()
+		b.markSource(decl.source)
 		b.WriteString(decl.code)
 		b.WriteString("\n")
+		b.clearSource()
 	}
 	for _, decl := range file.decls {
 		if decl.typeIndexExport != "" && decl.code != "" {
@@ -241,7 +265,7 @@ func (o *TypeScriptEmitOwner) renderLoweredFile(pkg *loweredPackage, file *lower
 	if pkg.name == "main" && hasMain {
 		writeSeparator()
 		b.WriteString("if (")
-		b.WriteString(o.runtimeOwner.QualifiedHelper(RuntimeHelperIsMainScript))
+		b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperIsMainScript))
 		b.WriteString("(import.meta)) {\n")
 		b.WriteString("\tawait main()\n")
 		b.WriteString("}\n")
@@ -407,12 +431,9 @@ func structZeroValueDeps(structType *loweredStruct, names map[string]bool) []str
 	return deps
 }
 
-func renderStruct(b *strings.Builder, structType *loweredStruct, runtimeOwner *RuntimeContractOwner, trimTypeInfo bool) {
-	varRef := runtimeOwner.QualifiedHelper(RuntimeHelperVarRef)
-	markStructValue := runtimeOwner.QualifiedHelper(RuntimeHelperMarkAsStructValue)
-	cloneStructValue := runtimeOwner.QualifiedHelper(RuntimeHelperCloneStructValue)
-	cloneArrayValue := runtimeOwner.QualifiedHelper(RuntimeHelperCloneArrayValue)
-	registerStructType := runtimeOwner.QualifiedHelper(RuntimeHelperRegisterStructType)
+func renderStruct(b *tsBuilder, structType *loweredStruct, trimTypeInfo bool) {
+	markStructValue := b.runtime.QualifiedHelper(RuntimeHelperMarkAsStructValue)
+	registerStructType := b.runtime.QualifiedHelper(RuntimeHelperRegisterStructType)
 	if structType.exported {
 		b.WriteString("export ")
 	}
@@ -445,6 +466,9 @@ func renderStruct(b *strings.Builder, structType *loweredStruct, runtimeOwner *R
 		b.WriteString(">\n")
 	}
 	b.WriteString("\t}\n\n")
+	if structType.typeArgs {
+		b.WriteString("\tdeclare public __typeArgs: $.GenericTypeArgs | undefined\n\n")
+	}
 	b.WriteString("\tconstructor(init?: Partial<{")
 	for idx, field := range structType.fields {
 		if idx != 0 {
@@ -454,12 +478,20 @@ func renderStruct(b *strings.Builder, structType *loweredStruct, runtimeOwner *R
 		b.WriteString("?: ")
 		b.WriteString(field.typ)
 	}
-	b.WriteString("}>) {\n\t\tthis._fields = {\n")
+	b.WriteString("}>")
+	if structType.typeArgs {
+		b.WriteString(", __typeArgs?: $.GenericTypeArgs")
+	}
+	b.WriteString(") {\n")
+	if structType.typeArgs {
+		b.WriteString("\t\tObject.defineProperty(this, \"__typeArgs\", { value: __typeArgs, writable: true })\n")
+	}
+	b.WriteString("\t\tthis._fields = {\n")
 	for idx, field := range structType.fields {
 		b.WriteString("\t\t\t")
 		b.WriteString(field.name)
 		b.WriteString(": ")
-		b.WriteString(varRef)
+		b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperVarRef))
 		b.WriteString("(")
 		if field.structValue {
 			b.WriteString("init?.")
@@ -467,7 +499,7 @@ func renderStruct(b *strings.Builder, structType *loweredStruct, runtimeOwner *R
 			b.WriteString(" ? ")
 			b.WriteString(markStructValue)
 			b.WriteString("(")
-			b.WriteString(cloneStructValue)
+			b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperCloneStructValue))
 			b.WriteString("(init.")
 			b.WriteString(field.name)
 			b.WriteString(")) : ")
@@ -476,7 +508,7 @@ func renderStruct(b *strings.Builder, structType *loweredStruct, runtimeOwner *R
 			b.WriteString("init?.")
 			b.WriteString(field.name)
 			b.WriteString(" !== undefined ? ")
-			b.WriteString(cloneArrayValue)
+			b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperCloneArrayValue))
 			b.WriteString("(init.")
 			b.WriteString(field.name)
 			b.WriteString(") : ")
@@ -504,22 +536,26 @@ func renderStruct(b *strings.Builder, structType *loweredStruct, runtimeOwner *R
 	b.WriteString(structType.name)
 	b.WriteString(" {\n\t\tconst cloned = new ")
 	b.WriteString(structType.name)
-	b.WriteString("()\n\t\tcloned._fields = {\n")
+	if structType.typeArgs {
+		b.WriteString("(undefined, this.__typeArgs)\n\t\tcloned._fields = {\n")
+	} else {
+		b.WriteString("()\n\t\tcloned._fields = {\n")
+	}
 	for idx, field := range structType.fields {
 		b.WriteString("\t\t\t")
 		b.WriteString(field.name)
 		b.WriteString(": ")
-		b.WriteString(varRef)
+		b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperVarRef))
 		b.WriteString("(")
 		if field.structValue {
 			b.WriteString(markStructValue)
 			b.WriteString("(")
-			b.WriteString(cloneStructValue)
+			b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperCloneStructValue))
 			b.WriteString("(this._fields.")
 			b.WriteString(field.name)
 			b.WriteString(".value))")
 		} else if field.arrayValue {
-			b.WriteString(cloneArrayValue)
+			b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperCloneArrayValue))
 			b.WriteString("(this._fields.")
 			b.WriteString(field.name)
 			b.WriteString(".value)")
@@ -621,7 +657,7 @@ func trimmedRuntimeStructFieldInfoExpr(
 	return "{ " + strings.Join(fields, ", ") + " }"
 }
 
-func writeLineComment(b *strings.Builder, indent string, comment string) {
+func writeLineComment(b *tsBuilder, indent string, comment string) {
 	comment = strings.TrimSpace(comment)
 	if comment == "" {
 		return
@@ -640,7 +676,9 @@ func writeLineComment(b *strings.Builder, indent string, comment string) {
 	}
 }
 
-func renderFunction(b *strings.Builder, fn *loweredFunction) {
+func renderFunction(b *tsBuilder, fn *loweredFunction) {
+	b.beginFunction(fn.tracebackName)
+	b.markSource(fn.source)
 	if fn.exported {
 		b.WriteString("export ")
 	}
@@ -685,9 +723,13 @@ func renderFunction(b *strings.Builder, fn *loweredFunction) {
 		renderUnreachableReturn(b, fn, 1)
 	}
 	b.WriteString("}\n")
+	b.clearSource()
+	b.endFunction(fn.tracebackName)
 }
 
-func renderMethod(b *strings.Builder, fn *loweredFunction) {
+func renderMethod(b *tsBuilder, fn *loweredFunction) {
+	b.beginFunction(fn.tracebackName)
+	b.markSource(fn.source)
 	writeIndent(b, 1)
 	b.WriteString("public ")
 	if fn.async {
@@ -731,9 +773,11 @@ func renderMethod(b *strings.Builder, fn *loweredFunction) {
 	}
 	writeIndent(b, 1)
 	b.WriteString("}\n")
+	b.clearSource()
+	b.endFunction(fn.tracebackName)
 }
 
-func renderUnreachableReturn(b *strings.Builder, fn *loweredFunction, indent int) {
+func renderUnreachableReturn(b *tsBuilder, fn *loweredFunction, indent int) {
 	if fn.result == "void" || fn.result == "globalThis.Promise<void>" {
 		return
 	}
@@ -753,7 +797,7 @@ func loweredStmtsEndWithTerminal(stmts []loweredStmt) bool {
 	return strings.HasPrefix(text, "return") || strings.HasPrefix(text, "throw ")
 }
 
-func renderFunctionTypeParams(b *strings.Builder, fn *loweredFunction) {
+func renderFunctionTypeParams(b *tsBuilder, fn *loweredFunction) {
 	if len(fn.typeParams) == 0 {
 		return
 	}
@@ -774,7 +818,7 @@ func receiverValue(fn *loweredFunction) string {
 	return "this"
 }
 
-func renderNamedResults(b *strings.Builder, results []loweredNamedResult, indent int) {
+func renderNamedResults(b *tsBuilder, results []loweredNamedResult, indent int) {
 	for _, result := range results {
 		writeIndent(b, indent)
 		b.WriteString("let ")
@@ -787,24 +831,24 @@ func renderNamedResults(b *strings.Builder, results []loweredNamedResult, indent
 	}
 }
 
-func renderDeferStack(b *strings.Builder, state *loweredDeferState, indent int) {
+func renderDeferStack(b *tsBuilder, state *loweredDeferState, indent int) {
 	if state == nil || !state.used {
 		return
 	}
-	writeIndent(b, indent)
-	if state.recover {
-		if state.async {
-			b.WriteString("const __defer = new $.AsyncDisposableStack()\n")
-			return
-		}
-		b.WriteString("const __defer = new $.DisposableStack()\n")
-		return
-	}
+	stackHelper := RuntimeHelperDisposableStack
 	if state.async {
-		b.WriteString("await using __defer = new $.AsyncDisposableStack()\n")
-		return
+		stackHelper = RuntimeHelperAsyncDisposableStack
+	}
+	stack := b.runtime.QualifiedHelper(stackHelper)
+	writeIndent(b, indent)
+	switch {
+	case state.recover:
+		b.WriteString("const __defer = new " + stack + "()\n")
+	case state.async:
+		b.WriteString("await using __defer = new " + stack + "()\n")
+	default:
+		b.WriteString("using __defer = new " + stack + "()\n")
 	}
-	b.WriteString("using __defer = new $.DisposableStack()\n")
 }
 
 // renderBodyWithDefer emits the defer stack, body, and trailing return for a
@@ -812,7 +856,7 @@ func renderDeferStack(b *strings.Builder, state *loweredDeferState, indent int)
 // returns call dispose(), panic unwinding calls disposePanic(), and recover()
 // cannot observe unrelated async panics. Other defer stacks keep the plain
 // using-declaration shape.
-func renderBodyWithDefer(b *strings.Builder, fn *loweredFunction, indent int) {
+func renderBodyWithDefer(b *tsBuilder, fn *loweredFunction, indent int) {
 	if fn.deferState == nil || !fn.deferState.used || !fn.deferState.recover {
 		renderDeferStack(b, fn.deferState, indent)
 		renderStmts(b, fn.body, indent)
@@ -837,7 +881,7 @@ func renderBodyWithDefer(b *strings.Builder, fn *loweredFunction, indent int) {
 		b.WriteString("__defer.disposePanic(e)\n")
 	}
 	writeIndent(b, indent+1)
-	b.WriteString("if (!$.recovered(e)) {\n")
+	b.WriteString("if (!" + b.runtime.QualifiedHelper(RuntimeHelperRecovered) + "(e)) {\n")
 	writeIndent(b, indent+2)
 	b.WriteString("throw e\n")
 	writeIndent(b, indent+1)
@@ -851,9 +895,10 @@ func renderBodyWithDefer(b *strings.Builder, fn *loweredFunction, indent int) {
 	}
 }
 
-func renderStmts(b *strings.Builder, stmts []loweredStmt, indent int) {
+func renderStmts(b *tsBuilder, stmts []loweredStmt, indent int) {
 	for idx, stmt := range stmts {
 		renderLeadingLines(b, stmt.leading, indent)
+		b.markSource(stmt.source)
 		if stmt.rangeFunc != nil {
 			renderRangeFunc(b, stmt.rangeFunc, indent)
 			continue
@@ -918,7 +963,7 @@ func needsASIBarrier(current loweredStmt, next loweredStmt) bool {
 	return strings.HasPrefix(nextText, "(") || strings.HasPrefix(nextText, "[")
 }
 
-func renderLeadingLines(b *strings.Builder, lines []string, indent int) {
+func renderLeadingLines(b *tsBuilder, lines []string, indent int) {
 	for _, line := range lines {
 		if strings.TrimSpace(line) == "" {
 			b.WriteString("\n")
@@ -930,7 +975,7 @@ func renderLeadingLines(b *strings.Builder, lines []string, indent int) {
 	}
 }
 
-func writeIndentedText(b *strings.Builder, text string, indent int) {
+func writeIndentedText(b *tsBuilder, text string, indent int) {
 	lines := strings.Split(text, "\n")
 	for idx, line := range lines {
 		if idx != 0 {
@@ -943,7 +988,7 @@ func writeIndentedText(b *strings.Builder, text string, indent int) {
 	}
 }
 
-func renderSwitch(b *strings.Builder, stmt *loweredSwitch, indent int) {
+func renderSwitch(b *tsBuilder, stmt *loweredSwitch, indent int) {
 	writeIndent(b, indent)
 	b.WriteString("switch (")
 	b.WriteString(stmt.value)
@@ -966,7 +1011,7 @@ func renderSwitch(b *strings.Builder, stmt *loweredSwitch, indent int) {
 	b.WriteString("}\n")
 }
 
-func renderSwitchBody(b *strings.Builder, body []loweredStmt, fallsThrough bool, indent int) {
+func renderSwitchBody(b *tsBuilder, body []loweredStmt, fallsThrough bool, indent int) {
 	writeIndent(b, indent)
 	b.WriteString("{\n")
 	renderStmts(b, body, indent+1)
@@ -978,7 +1023,7 @@ func renderSwitchBody(b *strings.Builder, body []loweredStmt, fallsThrough bool,
 	b.WriteString("}\n")
 }
 
-func renderRangeFunc(b *strings.Builder, stmt *loweredRangeFunc, indent int) {
+func renderRangeFunc(b *tsBuilder, stmt *loweredRangeFunc, indent int) {
 	if stmt.returnBranch != nil {
 		writeIndent(b, indent)
 		b.WriteString("let ")
@@ -1061,12 +1106,12 @@ func renderRangeFunc(b *strings.Builder, stmt *loweredRangeFunc, indent int) {
 	b.WriteString("}\n")
 }
 
-func renderNamedStructConversion(expr *loweredNamedStructConversionExpr) string {
+func renderNamedStructConversion(runtimeOwner *RuntimeContractOwner, expr *loweredNamedStructConversionExpr) string {
 	if expr == nil {
 		return "undefined"
 	}
 	if expr.castOnly {
-		return "$.namedStructConversion<" + expr.castTarget + ">(" + expr.value.text + ")"
+		return runtimeOwner.QualifiedHelper(RuntimeHelperNamedStructConversion) + "<" + expr.castTarget + ">(" + expr.value.text + ")"
 	}
 	fields := make([]string, 0, len(expr.fields))
 	for _, field := range expr.fields {
@@ -1080,13 +1125,13 @@ func renderNamedStructConversion(expr *loweredNamedStructConversionExpr) string
 	return "(() => { " + body + " })()"
 }
 
-func renderSelect(b *strings.Builder, stmt *loweredSelect, indent int) {
+func renderSelect(b *tsBuilder, stmt *loweredSelect, indent int) {
 	writeIndent(b, indent)
 	b.WriteString("const [")
 	b.WriteString(stmt.hasReturn)
 	b.WriteString(", ")
 	b.WriteString(stmt.value)
-	b.WriteString("] = await $.selectStatement<any, ")
+	b.WriteString("] = await " + b.runtime.QualifiedHelper(RuntimeHelperSelectStatement) + "<any, ")
 	if stmt.external {
 		b.WriteString("any")
 	} else {
@@ -1128,7 +1173,7 @@ func renderSelect(b *strings.Builder, stmt *loweredSelect, indent int) {
 	}
 }
 
-func renderSelectExternalBodies(b *strings.Builder, stmt *loweredSelect, indent int) {
+func renderSelectExternalBodies(b *tsBuilder, stmt *loweredSelect, indent int) {
 	writeIndent(b, indent)
 	b.WriteString("switch (")
 	b.WriteString(stmt.value)
@@ -1161,7 +1206,7 @@ func renderSelectExternalBodies(b *strings.Builder, stmt *loweredSelect, indent
 	}
 }
 
-func renderSelectCase(b *strings.Builder, switchCase loweredSelectCase, external bool, resultName string, indent int) {
+func renderSelectCase(b *tsBuilder, switchCase loweredSelectCase, external bool, resultName string, indent int) {
 	writeIndent(b, indent)
 	b.WriteString("{\n")
 	writeIndent(b, indent+1)
@@ -1209,9 +1254,10 @@ func renderSelectCase(b *strings.Builder, switchCase loweredSelectCase, external
 	b.WriteString("}")
 }
 
-func renderSelectCaseStmts(b *strings.Builder, stmts []loweredStmt, indent int) {
+func renderSelectCaseStmts(b *tsBuilder, stmts []loweredStmt, indent int) {
 	for idx, stmt := range stmts {
 		renderLeadingLines(b, stmt.leading, indent)
+		b.markSource(stmt.source)
 		if stmt.rangeFunc != nil {
 			renderRangeFunc(b, stmt.rangeFunc, indent)
 			continue
@@ -1237,7 +1283,7 @@ func renderSelectCaseStmts(b *strings.Builder, stmts []loweredStmt, indent int)
 			continue
 		}
 		if strings.TrimSpace(stmt.text) == "return" {
-			b.WriteString("return $.selectVoidReturn()\n")
+			b.WriteString("return " + b.runtime.QualifiedHelper(RuntimeHelperSelectVoidReturn) + "()\n")
 			continue
 		}
 		writeIndentedText(b, stmt.text, indent)
@@ -1263,7 +1309,7 @@ func renderSelectCaseStmts(b *strings.Builder, stmts []loweredStmt, indent int)
 	}
 }
 
-func renderTypeSwitch(b *strings.Builder, stmt *loweredTypeSwitch, indent int) {
+func renderTypeSwitch(b *tsBuilder, stmt *loweredTypeSwitch, indent int) {
 	writeIndent(b, indent)
 	b.WriteString("{\n")
 	writeIndent(b, indent+1)
@@ -1288,14 +1334,16 @@ func renderTypeSwitch(b *strings.Builder, stmt *loweredTypeSwitch, indent int) {
 	b.WriteString("}\n")
 }
 
-func renderTypeSwitchCase(b *strings.Builder, varName string, varRef bool, switchCase loweredTypeSwitchCase, indent int) {
+func renderTypeSwitchCase(b *tsBuilder, varName string, varRef bool, switchCase loweredTypeSwitchCase, indent int) {
 	if len(switchCase.types) == 0 {
 		return
 	}
 	writeIndent(b, indent)
 	b.WriteString("case ")
-	if len(switchCase.types) == 1 {
-		b.WriteString("$.typeAssert<")
+	if len(switchCase.types) == 1 && switchCase.types[0] == "null" {
+		b.WriteString("__goscriptTypeSwitchValue == null")
+	} else if len(switchCase.types) == 1 {
+		b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperTypeAssert) + "<")
 		b.WriteString(typeSwitchAssertType(switchCase, 0))
 		b.WriteString(">(__goscriptTypeSwitchValue, ")
 		b.WriteString(switchCase.types[0])
@@ -1305,15 +1353,21 @@ func renderTypeSwitchCase(b *strings.Builder, varName string, varRef bool, switc
 			if idx != 0 {
 				b.WriteString(" || ")
 			}
-			b.WriteString("$.is(__goscriptTypeSwitchValue, ")
+			if typ == "null" {
+				b.WriteString("__goscriptTypeSwitchValue == null")
+				continue
+			}
+			b.WriteString(b.runtime.QualifiedHelper(RuntimeHelperIs) + "(__goscriptTypeSwitchValue, ")
 			b.WriteString(typ)
 			b.WriteString(")")
 		}
 	}
 	b.WriteString(":\n")
 	value := "__goscriptTypeSwitchValue"
-	if len(switchCase.types) == 1 {
-		value = "$.typeAssert<" + typeSwitchAssertType(switchCase, 0) +
+	if len(switchCase.types) == 1 && switchCase.types[0] == "null" {
+		value = "null"
+	} else if len(switchCase.types) == 1 {
+		value = b.runtime.QualifiedHelper(RuntimeHelperTypeAssert) + "<" + typeSwitchAssertType(switchCase, 0) +
 			">(__goscriptTypeSwitchValue, " + switchCase.types[0] + ").value"
 	}
 	renderTypeSwitchInlineBody(b, varName, varRef || switchCase.varRef, typeSwitchCaseVariableType(switchCase), value, switchCase.body, indent+1)
@@ -1336,7 +1390,7 @@ func typeSwitchCaseVariableType(switchCase loweredTypeSwitchCase) string {
 }
 
 func renderTypeSwitchInlineBody(
-	b *strings.Builder,
+	b *tsBuilder,
 	varName string,
 	varRef bool,
 	varType string,
@@ -1363,7 +1417,7 @@ func renderTypeSwitchInlineBody(
 		}
 		b.WriteString(": $.VarRef<")
 		b.WriteString(varType)
-		b.WriteString("> = $.varRef(")
+		b.WriteString("> = " + b.runtime.QualifiedHelper(RuntimeHelperVarRef) + "(")
 		b.WriteString(value)
 		b.WriteString(")\n")
 		renderStmts(b, body, indent+1)
@@ -1410,7 +1464,7 @@ func renderIndex(pkg *loweredPackage) string {
 	return strings.Join(lines, "\n") + "\n"
 }
 
-func writeIndent(b *strings.Builder, indent int) {
+func writeIndent(b *tsBuilder, indent int) {
 	for range indent {
 		b.WriteString("\t")
 	}
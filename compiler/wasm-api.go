@@ -166,6 +166,9 @@ func browserSourceGraph(source string, packageName string) (*PackageGraph, []Dia
 		packagesByPath: map[string]*packages.Package{
 			pkgPath: pkg,
 		},
+		sources: map[string][]byte{
+			browserSourceFileName: []byte(source),
+		},
 	}, nil
 }
 
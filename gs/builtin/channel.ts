@@ -1,3 +1,5 @@
+import { beginHostWork, park, parkForever } from './goroutine.js'
+
 /**
  * Represents the result of a channel receive operation with 'ok' value
  */
@@ -19,8 +21,13 @@ function scheduleClosedChannelWake(wake: () => void): void {
   // Closed-channel wakeups represent goroutine scheduling, not an immediate
   // Promise continuation. Keep them on a task boundary so goroutine work queued
   // around the close can observe state published before the blocked receiver
-  // resumes.
-  setTimeout(wake, 0)
+  // resumes. The pending wake counts as host work so the deadlock check does
+  // not see the woken goroutine as blocked.
+  const done = beginHostWork()
+  setTimeout(() => {
+    done()
+    wake()
+  }, 0)
 }
 
 function completeUnbufferedReceive<T>(
@@ -184,8 +191,7 @@ export async function selectStatement<T, V = void>(
 ): Promise<[boolean, V]> {
   if (cases.length === 0 && !hasDefault) {
     // Go spec: If there are no cases, the select statement blocks forever.
-    // Emulate blocking forever with a promise that never resolves.
-    return new Promise<[boolean, V]>(() => {}) // Promise never resolves
+    return parkForever<[boolean, V]>('select (no cases)')
   }
 
   // 1. Check for ready (non-blocking) operations
@@ -286,10 +292,10 @@ export async function selectStatement<T, V = void>(
   // If all non-default cases have nil channels, we effectively block forever
   if (blockingPromises.length === 0) {
     // No valid channels to operate on, block forever (unless there's a default)
-    return new Promise<[boolean, V]>(() => {}) // Promise never resolves
+    return parkForever<[boolean, V]>('select')
   }
 
-  const result = await Promise.race(blockingPromises)
+  const result = await park('select', Promise.race(blockingPromises))
   abort.abort()
   // Execute onSelected handler for the selected case
   const selectedCase = cases.find((c) => c.id === result.id)
@@ -315,7 +321,7 @@ export async function chanSend<T>(
 ): Promise<void> {
   if (channel === null) {
     // In Go, sending to a nil channel blocks forever
-    return new Promise<void>(() => {}) // Promise that never resolves
+    return parkForever<void>('chan send (nil chan)')
   }
   return channel.send(value)
 }
@@ -331,7 +337,7 @@ export async function chanRecv<T>(
 ): Promise<T> {
   if (channel === null) {
     // In Go, receiving from a nil channel blocks forever
-    return new Promise<T>(() => {}) // Promise that never resolves
+    return parkForever<T>('chan receive (nil chan)')
   }
   return channel.receive()
 }
@@ -347,7 +353,7 @@ export async function chanRecvWithOk<T>(
 ): Promise<ChannelReceiveResult<T>> {
   if (channel === null) {
     // In Go, receiving from a nil channel blocks forever
-    return new Promise<ChannelReceiveResult<T>>(() => {}) // Promise that never resolves
+    return parkForever<ChannelReceiveResult<T>>('chan receive (nil chan)')
   }
   return channel.receiveWithOk()
 }
@@ -448,9 +454,12 @@ class BufferedChannel<T> implements Channel<T> {
     }
 
     // Buffer is full (or capacity is 0 and no receivers are waiting). Sender must block.
-    return new Promise<void>((resolve, reject) => {
-      this.senders.push({ value, resolveSend: resolve, rejectSend: reject })
-    })
+    return park(
+      'chan send',
+      new Promise<void>((resolve, reject) => {
+        this.senders.push({ value, resolveSend: resolve, rejectSend: reject })
+      }),
+    )
   }
 
   async receive(): Promise<T> {
@@ -481,9 +490,12 @@ class BufferedChannel<T> implements Channel<T> {
     }
 
     // Buffer is empty, channel is open, no waiting senders. Receiver must block.
-    return new Promise<T>((resolve, reject) => {
-      this.receivers.push({ resolveReceive: resolve, rejectReceive: reject })
-    })
+    return park(
+      'chan receive',
+      new Promise<T>((resolve, reject) => {
+        this.receivers.push({ resolveReceive: resolve, rejectReceive: reject })
+      }),
+    )
   }
 
   async receiveWithOk(): Promise<ChannelReceiveResult<T>> {
@@ -513,9 +525,12 @@ class BufferedChannel<T> implements Channel<T> {
     }
 
     // Buffer is empty, channel is open, no waiting senders. Receiver must block.
-    return new Promise<ChannelReceiveResult<T>>((resolve) => {
-      this.receiversWithOk.push({ resolveReceive: resolve })
-    })
+    return park(
+      'chan receive',
+      new Promise<ChannelReceiveResult<T>>((resolve) => {
+        this.receiversWithOk.push({ resolveReceive: resolve })
+      }),
+    )
   }
 
   trySelectReceive(id: number): SelectResult<T> | undefined {
@@ -0,0 +1,65 @@
+import { describe, expect, it } from 'vitest'
+
+import { VirtualClock } from './clock.js'
+
+describe('VirtualClock', () => {
+  it('starts at the synctest epoch and only moves when advanced', () => {
+    const clock = new VirtualClock()
+    expect(new Date(clock.now()).toISOString()).toBe(
+      '2000-01-01T00:00:00.000Z',
+    )
+    expect(clock.monotonic()).toBe(0)
+    expect(clock.advance()).toBe(false)
+
+    clock.advanceBy(1500)
+    expect(clock.now()).toBe(Date.UTC(2000, 0, 1) + 1500)
+    expect(clock.monotonic()).toBe(1500)
+  })
+
+  it('runs timers in deadline order at their own time', () => {
+    const clock = new VirtualClock(0)
+    const fired: [string, number][] = []
+    const record = (name: string) => () => fired.push([name, clock.now()])
+
+    clock.schedule(record('b'), 20, false)
+    clock.schedule(record('a'), 10, false)
+    clock.schedule(record('c'), 20, false)
+    const cancel = clock.schedule(record('canceled'), 15, false)
+    cancel()
+    expect(clock.pending()).toBe(3)
+
+    expect(clock.advance()).toBe(true)
+    expect(fired).toEqual([['a', 10]])
+    clock.advanceBy(100)
+    expect(fired).toEqual([
+      ['a', 10],
+      ['b', 20],
+      ['c', 20],
+    ])
+    expect(clock.now()).toBe(110)
+    expect(clock.pending()).toBe(0)
+  })
+
+  it('repeats intervals until they are canceled', () => {
+    const clock = new VirtualClock(0)
+    const ticks: number[] = []
+    const cancel = clock.schedule(() => ticks.push(clock.now()), 25, true)
+
+    clock.advanceBy(80)
+    expect(ticks).toEqual([25, 50, 75])
+    cancel()
+    clock.advanceBy(80)
+    expect(ticks).toEqual([25, 50, 75])
+  })
+
+  it('runs timers that are already due on the next host turn', async () => {
+    const clock = new VirtualClock(0)
+    let fired = false
+    clock.schedule(() => (fired = true), 0, false)
+    expect(fired).toBe(false)
+
+    await new Promise((resolve) => setTimeout(resolve, 0))
+    expect(fired).toBe(true)
+    expect(clock.now()).toBe(0)
+  })
+})
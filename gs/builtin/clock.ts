@@ -0,0 +1,154 @@
+// Clocks behind time.Now, time.Sleep, timers, tickers and context deadlines.
+//
+// The runtime reads the time and schedules timer wakeups through the host
+// runtime's clock. By default that is the host's own clock. A test runner can
+// install a VirtualClock instead, whose time only moves when it is advanced.
+// Inside a root goroutine the runtime advances it by itself once every
+// goroutine is blocked, jumping straight to the next timer the way Go's
+// testing/synctest does, so code that sleeps for an hour runs instantly.
+
+// HostClock is a source of time and timers.
+export interface HostClock {
+  // now returns the wall clock time in milliseconds since the Unix epoch.
+  now(): number
+  // monotonic returns a monotonic reading in milliseconds since an arbitrary
+  // start.
+  monotonic(): number
+  // schedule calls fn after ms milliseconds, and every ms milliseconds after
+  // that when repeat is set, until the returned function is called.
+  schedule(fn: () => void, ms: number, repeat: boolean): () => void
+  // advance moves a virtual clock to its next timer and runs the timers due
+  // then, reporting false when none is pending. Host clocks leave it out:
+  // their timers fire on their own and count as pending host work.
+  advance?(): boolean
+}
+
+// hostClock is the host's clock: Date.now, performance.now and setTimeout.
+export const hostClock: HostClock = {
+  now: () => Date.now(),
+  monotonic: () =>
+    typeof performance !== 'undefined' && performance.now ?
+      performance.now()
+    : Date.now(),
+  schedule: (fn: () => void, ms: number, repeat: boolean) => {
+    if (repeat) {
+      const id = setInterval(fn, ms)
+      return () => clearInterval(id)
+    }
+    const id = setTimeout(fn, ms)
+    return () => clearTimeout(id)
+  },
+}
+
+interface virtualTimer {
+  when: number
+  seq: number
+  period: number
+  fn: () => void
+}
+
+// virtualClockStart is where a VirtualClock starts by default: midnight UTC
+// on January 1, 2000, the time a Go synctest bubble starts at.
+const virtualClockStart = Date.UTC(2000, 0, 1)
+
+// VirtualClock is a clock whose time only moves when it is advanced. Timers
+// due at the same time run in the order they were scheduled.
+export class VirtualClock implements HostClock {
+  private elapsed = 0
+  private nextSeq = 0
+  // timers is ordered by when, then seq.
+  private timers: virtualTimer[] = []
+  private flushScheduled = false
+
+  constructor(private readonly start = virtualClockStart) {}
+
+  now(): number {
+    return this.start + this.elapsed
+  }
+
+  monotonic(): number {
+    return this.elapsed
+  }
+
+  schedule(fn: () => void, ms: number, repeat: boolean): () => void {
+    const delay = Math.max(ms, 0)
+    const timer: virtualTimer = {
+      when: this.elapsed + delay,
+      seq: this.nextSeq++,
+      // A zero period would never let time move past the timer.
+      period: repeat ? Math.max(delay, 1) : 0,
+      fn,
+    }
+    this.insert(timer)
+    if (delay === 0) {
+      this.scheduleFlush()
+    }
+    return () => {
+      const idx = this.timers.indexOf(timer)
+      if (idx !== -1) {
+        this.timers.splice(idx, 1)
+      }
+      timer.period = 0
+    }
+  }
+
+  advance(): boolean {
+    if (this.timers.length === 0) {
+      return false
+    }
+    this.advanceTo(this.timers[0].when)
+    return true
+  }
+
+  // advanceBy moves the clock ms milliseconds forward, running each timer
+  // due on the way at its own time.
+  advanceBy(ms: number): void {
+    this.advanceTo(this.elapsed + Math.max(ms, 0))
+  }
+
+  // pending returns the number of timers waiting to run.
+  pending(): number {
+    return this.timers.length
+  }
+
+  private advanceTo(target: number): void {
+    while (this.timers.length !== 0 && this.timers[0].when <= target) {
+      const timer = this.timers.shift()!
+      this.elapsed = Math.max(this.elapsed, timer.when)
+      if (timer.period !== 0) {
+        timer.when += timer.period
+        timer.seq = this.nextSeq++
+        this.insert(timer)
+      }
+      timer.fn()
+    }
+    this.elapsed = Math.max(this.elapsed, target)
+  }
+
+  private insert(timer: virtualTimer): void {
+    let idx = this.timers.length
+    while (
+      idx > 0 &&
+      (this.timers[idx - 1].when > timer.when ||
+        (this.timers[idx - 1].when === timer.when &&
+          this.timers[idx - 1].seq > timer.seq))
+    ) {
+      idx--
+    }
+    this.timers.splice(idx, 0, timer)
+  }
+
+  // scheduleFlush runs timers that are already due on the host's next turn,
+  // so they fire even while goroutines keep running, as expired timers do in
+  // Go.
+  private scheduleFlush(): void {
+    if (this.flushScheduled) {
+      return
+    }
+    this.flushScheduled = true
+    setTimeout(() => {
+      this.flushScheduled = false
+      this.advanceTo(this.elapsed)
+    }, 0)
+  }
+}
@@ -0,0 +1,26 @@
+import { afterEach, describe, expect, it } from 'vitest'
+
+import {
+  coverBlocks,
+  coverHit,
+  coverProfile,
+  resetCoverage,
+} from './coverage.js'
+
+afterEach(() => {
+  resetCoverage()
+})
+
+describe('coverProfile', () => {
+  it('lists every registered block with its count', () => {
+    coverHit('example.test/cover/a.go', 1)
+    coverBlocks('example.test/cover/a.go', [3, 14, 5, 2, 2, 5, 2, 7, 3, 1])
+    coverHit('example.test/cover/a.go', 1)
+    coverBlocks('example.test/other/b.go', [1, 1, 1, 9, 1])
+
+    expect(coverProfile(['example.test/cover'])).toBe(
+      'example.test/cover/a.go:3.14,5.2 2 0\nexample.test/cover/a.go:5.2,7.3 1 2',
+    )
+    expect(coverProfile()).toContain('example.test/other/b.go:1.1,1.9 1 0')
+  })
+})
@@ -0,0 +1,67 @@
+// Statement coverage for packages compiled with coverage instrumentation.
+//
+// Each instrumented Go file registers its basic blocks with coverBlocks when
+// its module loads, and generated code calls coverHit at the start of every
+// block. A file is named the way a Go coverprofile names it, by package path
+// and base name, and a block by its index in the file. Counts may arrive
+// before the blocks are registered, since a method can be lowered into the
+// module of another file.
+
+interface CoverFile {
+  // blocks holds the start line, start column, end line, end column, and
+  // statement count of each block, flattened.
+  blocks: number[]
+  counts: number[]
+}
+
+const coverFiles = new Map<string, CoverFile>()
+
+function coverFile(name: string): CoverFile {
+  let file = coverFiles.get(name)
+  if (!file) {
+    file = { blocks: [], counts: [] }
+    coverFiles.set(name, file)
+  }
+  return file
+}
+
+// coverBlocks registers the basic blocks of the Go file name.
+export function coverBlocks(name: string, blocks: number[]): void {
+  coverFile(name).blocks = blocks
+}
+
+// coverHit counts one run of block id of the Go file name.
+export function coverHit(name: string, id: number): void {
+  const counts = coverFile(name).counts
+  counts[id] = (counts[id] ?? 0) + 1
+}
+
+// resetCoverage zeroes every block count, so one process can report the
+// tests of several packages separately.
+export function resetCoverage(): void {
+  for (const file of coverFiles.values()) {
+    file.counts = []
+  }
+}
+
+// coverProfile returns the coverprofile block lines, without the mode line,
+// for the registered files of packages, or of every package when packages is
+// empty. Counts are execution counts; set mode is applied by the reader.
+export function coverProfile(packages: string[] = []): string {
+  const lines: string[] = []
+  for (const name of [...coverFiles.keys()].sort()) {
+    const pkg = name.slice(0, name.lastIndexOf('/'))
+    if (packages.length !== 0 && !packages.includes(pkg)) {
+      continue
+    }
+    const file = coverFiles.get(name)!
+    for (let idx = 0; idx * 5 + 4 < file.blocks.length; idx++) {
+      const at = idx * 5
+      const b = file.blocks
+      lines.push(
+        `${name}:${b[at]}.${b[at + 1]},${b[at + 2]}.${b[at + 3]} ${b[at + 4]} ${file.counts[idx] ?? 0}`,
+      )
+    }
+  }
+  return lines.join('\n')
+}
@@ -0,0 +1,224 @@
+import { afterEach, describe, expect, it } from 'vitest'
+
+import { makeChannel } from './channel.js'
+import { VirtualClock } from './clock.js'
+import {
+  BubbleDeadlockError,
+  bubbleWait,
+  currentClock,
+  DeadlockError,
+  go,
+  goroutineDump,
+  goroutines,
+  hostTimeout,
+  numGoroutine,
+  park,
+  runBubble,
+  runRootGoroutine,
+} from './goroutine.js'
+import { installHostClock } from './hostio.js'
+
+describe('deadlock detection', () => {
+  it('reports a root goroutine blocked on a channel nobody sends to', async () => {
+    const ch = makeChannel<number>(0, 0, 'both')
+
+    const result = runRootGoroutine(async () => {
+      await ch.receive()
+    }, 'TestDeadlock')
+
+    await expect(result).rejects.toBeInstanceOf(DeadlockError)
+    await expect(result).rejects.toThrow(
+      /^fatal error: all goroutines are asleep - deadlock!\n\ngoroutine \d+ \[chan receive\]:\nTestDeadlock\(\.\.\.\)$/,
+    )
+  })
+
+  it('lets goroutines wake each other', async () => {
+    const ch = makeChannel<number>(0, 0, 'both')
+
+    const got = await runRootGoroutine(async () => {
+      go(async () => {
+        await ch.send(7)
+      })
+      return ch.receive()
+    })
+
+    expect(got).toBe(7)
+  })
+
+  it('waits for pending host timers', async () => {
+    const ch = makeChannel<number>(1, 0, 'both')
+
+    const got = await runRootGoroutine(async () => {
+      hostTimeout(() => {
+        void ch.send(3)
+      }, 5)
+      return ch.receive()
+    })
+
+    expect(got).toBe(3)
+  })
+})
+
+describe('virtual clock', () => {
+  let restore: (() => void) | null = null
+
+  afterEach(() => {
+    restore?.()
+    restore = null
+  })
+
+  it('advances to the next timer once every goroutine is blocked', async () => {
+    const clock = new VirtualClock(0)
+    restore = installHostClock(clock)
+    const sleep = (ms: number) =>
+      park('sleep', new Promise<void>((resolve) => hostTimeout(resolve, ms)))
+
+    const woke = await runRootGoroutine(async () => {
+      const ch = makeChannel<number>(0, 0, 'both')
+      go(async () => {
+        await sleep(60 * 60 * 1000)
+        await ch.send(clock.now())
+      })
+      await sleep(1000)
+      const first = clock.now()
+      return [first, await ch.receive()]
+    })
+
+    expect(woke).toEqual([1000, 60 * 60 * 1000])
+  })
+
+  it('reports a deadlock once no timer is left', async () => {
+    restore = installHostClock(new VirtualClock(0))
+    const ch = makeChannel<number>(0, 0, 'both')
+
+    const result = runRootGoroutine(async () => {
+      hostTimeout(() => {}, 5000)
+      await ch.receive()
+    })
+
+    await expect(result).rejects.toBeInstanceOf(DeadlockError)
+  })
+})
+
+describe('synctest bubbles', () => {
+  const sleep = (ms: number) =>
+    park('sleep', new Promise<void>((resolve) => hostTimeout(resolve, ms)))
+
+  it('runs goroutines on a clock of their own', async () => {
+    const times: number[] = []
+
+    await runBubble(async () => {
+      const start = currentClock().now()
+      expect(start).toBe(Date.UTC(2000, 0, 1))
+      go(async () => {
+        await sleep(2000)
+        times.push(currentClock().now() - start)
+      })
+      await sleep(500)
+      times.push(currentClock().now() - start)
+      await bubbleWait()
+      times.push(currentClock().now() - start)
+    })
+
+    expect(times).toEqual([500, 500, 2000])
+    expect(currentClock().now()).toBeGreaterThan(Date.UTC(2020, 0, 1))
+  })
+
+  it('waits until the other goroutines are durably blocked', async () => {
+    const events: string[] = []
+
+    await runBubble(async () => {
+      const ch = makeChannel<number>(0, 0, 'both')
+      go(async () => {
+        events.push('received ' + (await ch.receive()))
+        await ch.receive()
+      })
+      await bubbleWait()
+      events.push('blocked')
+      await ch.send(1)
+      await bubbleWait()
+      events.push('blocked again')
+      ch.close()
+    })
+
+    expect(events).toEqual(['blocked', 'received 1', 'blocked again'])
+  })
+
+  it('returns once every goroutine of the bubble has exited', async () => {
+    let done = false
+
+    await runBubble(async () => {
+      go(async () => {
+        await sleep(60 * 60 * 1000)
+        done = true
+      })
+    })
+
+    expect(done).toBe(true)
+  })
+
+  it('reports goroutines blocked with no timer left', async () => {
+    const ch = makeChannel<number>(0, 0, 'both')
+
+    const result = runBubble(async () => {
+      await ch.receive()
+    }, 'testing/synctest.Test')
+
+    await expect(result).rejects.toBeInstanceOf(BubbleDeadlockError)
+    await expect(result).rejects.toThrow(
+      /^deadlock: all goroutines in bubble are blocked\n\ngoroutine \d+ \[chan receive\]:/,
+    )
+
+    const leaked = runBubble(async () => {
+      const ch = makeChannel<number>(0, 0, 'both')
+      go(async () => {
+        await ch.send(1)
+      })
+    })
+    await expect(leaked).rejects.toThrow(
+      'deadlock: main bubble goroutine has exited but blocked goroutines remain',
+    )
+  })
+
+  it('panics outside a bubble and in nested bubbles', async () => {
+    expect(() => bubbleWait()).toThrow('goroutine is not in a bubble')
+
+    await runBubble(async () => {
+      expect(() => runBubble(() => {})).toThrow(
+        'synctest.Run called from within a synctest bubble',
+      )
+    })
+  })
+})
+
+describe('goroutine accounting', () => {
+  it('tracks goroutines from their go statement until they return', async () => {
+    const ch = makeChannel<number>(0, 0, 'both')
+    const before = numGoroutine()
+
+    await runRootGoroutine(async () => {
+      go(
+        async () => {
+          await ch.send(1)
+        },
+        'example.test/pkg.Serve',
+        'example.test/pkg/serve.go:12',
+      )
+      await Promise.resolve()
+
+      const worker = goroutines().find(
+        (g) => g.createdAt === 'example.test/pkg/serve.go:12',
+      )
+      expect(worker?.waitPoint).toBe('chan send')
+      expect(numGoroutine()).toBe(before + 2)
+      expect(goroutineDump()).toContain(
+        `goroutine ${worker?.id} [chan send]:\ncreated by example.test/pkg.Serve in goroutine ${worker?.creatorId}\n\texample.test/pkg/serve.go:12`,
+      )
+
+      await ch.receive()
+    })
+
+    await new Promise((resolve) => setTimeout(resolve, 0))
+    expect(numGoroutine()).toBe(before)
+  })
+})
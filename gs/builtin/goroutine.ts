@@ -0,0 +1,655 @@
+import { type HostClock, VirtualClock } from './clock.js'
+import {
+  getHostRuntime,
+  type NodeAsyncLocalStorage,
+  writeHostStderrText,
+} from './hostio.js'
+import { panic } from './panic.js'
+import { startPreemptSlice } from './schedule.js'
+import { formatGoFrames, goFrames } from './traceback.js'
+
+// Goroutine accounting and deadlock detection.
+//
+// JavaScript has no scheduler GoScript can ask whether a goroutine can still
+// run, so the runtime keeps the books itself. go() registers each goroutine
+// with an ID and the go statement that created it, park() records goroutines
+// blocked in a channel operation, select, or sync primitive, and host timers
+// count as pending work that can wake a parked goroutine. Once every live
+// goroutine is parked and no host work is pending, nothing can make progress
+// again and the runtime reports Go's fatal error:
+//
+//   fatal error: all goroutines are asleep - deadlock!
+//
+// The one exception is a virtual clock, whose timers wait for the runtime to
+// advance it: the runtime moves it to its next timer instead, as Go's
+// testing/synctest does, and only reports a deadlock once no timer is left.
+//
+// Detection only runs while a root goroutine is registered: main when the
+// package runs as the entry script, or the running test under goscript test.
+// Go code embedded in a larger JavaScript program can be woken by host
+// callbacks the runtime cannot see, so it never reports a deadlock.
+//
+// A testing/synctest bubble gets the same treatment on its own: the goroutines
+// started inside it share a virtual clock of the bubble's, and once all of
+// them are durably blocked the runtime ends a pending synctest.Wait, or else
+// moves the bubble's clock to its next timer, or else fails the bubble with
+// Go's bubble deadlock panic.
+
+// GoroutineInfo describes a goroutine known to the runtime. id follows Go's
+// numbering, where goroutine 1 is main. createdBy names the function whose go
+// statement started the goroutine and createdAt is the Go file:line of that
+// statement; both are empty for root goroutines. waitPoint is what a parked
+// goroutine waits on, such as "chan receive", or null while it can run.
+// bubble is the synctest bubble the goroutine runs in, or null.
+export interface GoroutineInfo {
+  readonly id: number
+  readonly entry: string
+  readonly createdBy: string
+  readonly createdAt: string
+  readonly creatorId: number
+  readonly bubble: Bubble | null
+  waitPoint: string | null
+}
+
+// Bubble is a testing/synctest bubble: the goroutines started by the function
+// passed to synctest.Test, with a clock of their own that starts at midnight
+// UTC on January 1, 2000.
+export class Bubble {
+  readonly clock = new VirtualClock()
+  readonly goroutines = new Set<GoroutineInfo>()
+  // rootDone is set once the bubble's root goroutine returns. The bubble
+  // ends when its last goroutine exits.
+  rootDone = false
+  rootError: { err: unknown } | null = null
+  // waiter is the goroutine blocked in synctest.Wait, and wake resumes it.
+  waiter: GoroutineInfo | null = null
+  wake: (() => void) | null = null
+
+  constructor(readonly settle: (err: unknown) => void) {}
+}
+
+// BubbleDeadlockError reports that every goroutine of a synctest bubble is
+// durably blocked with no timer left to wake one. Error returns Go's panic
+// reason; the message adds the blocked goroutines.
+export class BubbleDeadlockError extends Error {
+  constructor(
+    private readonly reason: string,
+    dump: string,
+  ) {
+    super(reason + '\n\n' + dump)
+    this.name = 'BubbleDeadlockError'
+  }
+
+  Error(): string {
+    return this.reason
+  }
+}
+
+// durableWaitPoints are the wait points that durably block a goroutine in a
+// bubble: only another goroutine of the bubble can end them. Go also requires
+// the channel of a channel operation to belong to the bubble; GoScript does
+// not track where a channel was made, so every channel operation counts.
+const durableWaitPoints = new Set([
+  'chan receive',
+  'chan receive (nil chan)',
+  'chan send',
+  'chan send (nil chan)',
+  'select',
+  'select (no cases)',
+  'sleep',
+  'sync.Cond.Wait',
+  'sync.WaitGroup.Wait',
+])
+
+let nextGoroutineId = 2
+let rootGoroutines = 0
+let mainStarted = false
+let pendingHostWork = 0
+const goroutineTable = new Map<number, GoroutineInfo>()
+const parkedWaits = new Set<{ waitPoint: string }>()
+// rootDeadlockHandlers reject the innermost runRootGoroutine call.
+const rootDeadlockHandlers: Array<(err: DeadlockError) => void> = []
+let deadlockCheckScheduled = false
+const bubbles = new Set<Bubble>()
+// timerScope holds the bubble of the timer whose callback is running, which
+// is where goroutines it starts belong and whose clock it reads.
+let timerScope: { bubble: Bubble | null } | null = null
+// currentGoroutine is the goroutine that last started or resumed from park,
+// and null once it parks again. JavaScript has no portable way to tell which
+// async call chain is running, so this is exact for code between two parks
+// and a best guess elsewhere.
+let currentGoroutine: GoroutineInfo | null = null
+// bubbleContext follows the goroutines of synctest bubbles through their
+// async calls on hosts with AsyncLocalStorage, since a bubble must know its
+// goroutines even where currentGoroutine is stale. It is made by the first
+// bubble, and stays null on hosts without AsyncLocalStorage.
+let bubbleContext: NodeAsyncLocalStorage<GoroutineInfo> | null = null
+// panicGoroutines records which goroutine an uncaught panic escaped from.
+const panicGoroutines = new WeakMap<object, number>()
+
+// DeadlockError reports that every goroutine is blocked forever. waitPoints
+// lists what each blocked goroutine is waiting on, in the order they parked.
+export class DeadlockError extends Error {
+  constructor(
+    public readonly waitPoints: string[],
+    dump = waitPoints.map((waitPoint) => `goroutine [${waitPoint}]:`).join('\n'),
+  ) {
+    super('fatal error: all goroutines are asleep - deadlock!\n\n' + dump)
+    this.name = 'DeadlockError'
+  }
+}
+
+function registerGoroutine(
+  id: number,
+  entry: string,
+  createdBy: string,
+  createdAt: string,
+  bubble: Bubble | null,
+): GoroutineInfo {
+  const g: GoroutineInfo = {
+    id,
+    entry,
+    createdBy,
+    createdAt,
+    creatorId: runningGoroutine()?.id ?? 0,
+    bubble,
+    waitPoint: null,
+  }
+  goroutineTable.set(id, g)
+  bubble?.goroutines.add(g)
+  return g
+}
+
+function unregisterGoroutine(g: GoroutineInfo): void {
+  goroutineTable.delete(g.id)
+  if (currentGoroutine === g) {
+    currentGoroutine = null
+  }
+  const bubble = g.bubble
+  if (bubble?.goroutines.delete(g) && bubble.goroutines.size === 0) {
+    bubbles.delete(bubble)
+    bubble.settle(bubble.rootError?.err)
+  }
+}
+
+// go starts fn as a new goroutine. The compiler lowers every go statement to a
+// call of go, passing the enclosing function and the statement's file:line.
+export function go(fn: () => unknown, createdBy = '', createdAt = ''): void {
+  const g = registerGoroutine(
+    nextGoroutineId++,
+    '',
+    createdBy,
+    createdAt,
+    currentBubble(),
+  )
+  queueMicrotask(async () => {
+    currentGoroutine = g
+    startPreemptSlice()
+    try {
+      await inGoroutine(g, fn)
+    } catch (err) {
+      if (err !== null && typeof err === 'object') {
+        panicGoroutines.set(err, g.id)
+      }
+      throw err
+    } finally {
+      unregisterGoroutine(g)
+      scheduleDeadlockCheck()
+    }
+  })
+}
+
+// park marks the calling goroutine as blocked at waitPoint, such as "chan
+// receive" or "sync.WaitGroup.Wait", until wait settles.
+export async function park<T>(waitPoint: string, wait: Promise<T>): Promise<T> {
+  const g = runningGoroutine()
+  const parked = { waitPoint }
+  parkedWaits.add(parked)
+  if (g) {
+    g.waitPoint = waitPoint
+  }
+  currentGoroutine = null
+  scheduleDeadlockCheck()
+  try {
+    return await wait
+  } finally {
+    parkedWaits.delete(parked)
+    if (g) {
+      g.waitPoint = null
+    }
+    currentGoroutine = g
+  }
+}
+
+// parkForever blocks the calling goroutine at waitPoint for good, as a nil
+// channel operation or an empty select does.
+export function parkForever<T>(waitPoint: string): Promise<T> {
+  return park(waitPoint, new Promise<T>(() => {}))
+}
+
+// beginHostWork records pending host work, such as a timer, that can wake a
+// parked goroutine. The returned function ends it and may be called more than
+// once.
+export function beginHostWork(): () => void {
+  pendingHostWork++
+  let done = false
+  return () => {
+    if (done) {
+      return
+    }
+    done = true
+    pendingHostWork--
+    scheduleDeadlockCheck()
+  }
+}
+
+// HostTimer is a host timeout or interval counted as pending host work.
+export interface HostTimer {
+  cancel(): void
+}
+
+// hostTimeout calls fn after ms milliseconds on the current clock, like
+// setTimeout, and counts the pending timer as host work.
+export function hostTimeout(fn: () => void, ms: number): HostTimer {
+  const bubble = currentBubble()
+  const clock = bubble?.clock ?? getHostRuntime().clock
+  const done = clockWork(clock)
+  const cancel = clock.schedule(
+    () => {
+      done()
+      runTimer(bubble, fn)
+    },
+    ms,
+    false,
+  )
+  return {
+    cancel: () => {
+      cancel()
+      done()
+    },
+  }
+}
+
+// hostInterval calls fn every ms milliseconds on the current clock, like
+// setInterval, and counts the interval as host work until it is canceled.
+export function hostInterval(fn: () => void, ms: number): HostTimer {
+  const bubble = currentBubble()
+  const clock = bubble?.clock ?? getHostRuntime().clock
+  const done = clockWork(clock)
+  const cancel = clock.schedule(() => runTimer(bubble, fn), ms, true)
+  return {
+    cancel: () => {
+      cancel()
+      done()
+    },
+  }
+}
+
+// clockWork begins host work for a timer on clock. A virtual clock's timers
+// only fire when the deadlock check advances it, so they are not host work.
+function clockWork(clock: HostClock): () => void {
+  return clock.advance ? () => {} : beginHostWork()
+}
+
+// runTimer runs the callback fn of a timer started in bubble.
+function runTimer(bubble: Bubble | null, fn: () => void): void {
+  const previous = timerScope
+  timerScope = { bubble }
+  try {
+    fn()
+  } finally {
+    timerScope = previous
+  }
+}
+
+// runningGoroutine returns the goroutine running the calling code, or null
+// when that is not known.
+function runningGoroutine(): GoroutineInfo | null {
+  if (bubbleContext === null) {
+    return currentGoroutine
+  }
+  const g = bubbleContext.getStore()
+  if (g !== undefined) {
+    return g
+  }
+  // The caller runs outside every bubble, so a bubble goroutine left in
+  // currentGoroutine is stale.
+  return currentGoroutine?.bubble ? null : currentGoroutine
+}
+
+// inGoroutine calls fn as the body of g.
+function inGoroutine(g: GoroutineInfo, fn: () => unknown): unknown {
+  return g.bubble && bubbleContext ? bubbleContext.run(g, fn) : fn()
+}
+
+function currentBubble(): Bubble | null {
+  return timerScope ? timerScope.bubble : (runningGoroutine()?.bubble ?? null)
+}
+
+// currentClock returns the clock of the running goroutine's synctest bubble,
+// or the host runtime's clock outside bubbles.
+export function currentClock(): HostClock {
+  return currentBubble()?.clock ?? getHostRuntime().clock
+}
+
+// runBubble runs fn as the root goroutine of a new synctest bubble, as
+// synctest.Test does, and settles once every goroutine of the bubble has
+// exited. It rejects with the error fn threw, or with a BubbleDeadlockError
+// when the bubble's goroutines block with nothing left to wake them.
+export function runBubble(fn: () => unknown, createdBy = ''): Promise<void> {
+  if (currentBubble() !== null) {
+    panic('synctest.Run called from within a synctest bubble')
+  }
+  return new Promise<void>((resolve, reject) => {
+    const bubble: Bubble = new Bubble((err: unknown) =>
+      err === undefined ? resolve() : reject(err),
+    )
+    if (bubbleContext === null) {
+      const hooks = getHostRuntime().nodeAsyncHooks
+      bubbleContext =
+        hooks ? new hooks.AsyncLocalStorage<GoroutineInfo>() : null
+    }
+    bubbles.add(bubble)
+    const g = registerGoroutine(nextGoroutineId++, '', createdBy, '', bubble)
+    queueMicrotask(async () => {
+      currentGoroutine = g
+      try {
+        await inGoroutine(g, fn)
+      } catch (err) {
+        bubble.rootError = { err }
+      } finally {
+        bubble.rootDone = true
+        unregisterGoroutine(g)
+        scheduleDeadlockCheck()
+      }
+    })
+  })
+}
+
+// bubbleWait blocks the calling goroutine until every other goroutine in its
+// bubble is durably blocked, as synctest.Wait does.
+export function bubbleWait(): Promise<void> {
+  const g = runningGoroutine()
+  const bubble = g?.bubble ?? null
+  if (bubble === null) {
+    panic('goroutine is not in a bubble')
+  }
+  if (bubble.waiter !== null) {
+    panic('wait already in progress')
+  }
+  bubble.waiter = g
+  return park(
+    'synctest.Wait',
+    new Promise<void>((resolve) => {
+      bubble.wake = resolve
+    }),
+  )
+}
+
+// stepBubbles moves on each bubble whose goroutines are all durably blocked:
+// it ends a pending synctest.Wait, or else advances the bubble's clock to its
+// next timer, or else fails the bubble. It reports whether it stepped any
+// bubble.
+function stepBubbles(): boolean {
+  let stepped = false
+  for (const bubble of bubbles) {
+    if (!bubbleBlocked(bubble)) {
+      continue
+    }
+    stepped = true
+    if (bubble.wake !== null) {
+      const wake = bubble.wake
+      bubble.waiter = null
+      bubble.wake = null
+      wake()
+      continue
+    }
+    if (bubble.clock.advance()) {
+      continue
+    }
+    bubbles.delete(bubble)
+    if (bubble.rootError !== null) {
+      // The failure that ended the root goroutine explains more than the
+      // goroutines it left behind.
+      bubble.settle(bubble.rootError.err)
+      continue
+    }
+    bubble.settle(
+      new BubbleDeadlockError(
+        bubble.rootDone ?
+          'deadlock: main bubble goroutine has exited but blocked goroutines remain'
+        : 'deadlock: all goroutines in bubble are blocked',
+        goroutineDump(null, bubble),
+      ),
+    )
+  }
+  return stepped
+}
+
+function bubbleBlocked(bubble: Bubble): boolean {
+  for (const g of bubble.goroutines) {
+    if (g === bubble.waiter) {
+      continue
+    }
+    if (g.waitPoint === null || !durableWaitPoints.has(g.waitPoint)) {
+      return false
+    }
+  }
+  return true
+}
+
+// startMainGoroutine registers the entry script's main goroutine as a root
+// goroutine. Main is never unregistered: Go exits when main returns, so once
+// it has returned nothing left running can deadlock the program.
+export function startMainGoroutine(): void {
+  if (mainStarted) {
+    return
+  }
+  mainStarted = true
+  rootGoroutines++
+  currentGoroutine = registerGoroutine(1, 'main.main', '', '', null)
+
+  const processObj = getHostRuntime().processObj
+  if (
+    typeof processObj?.on === 'function' &&
+    typeof processObj?.exit === 'function'
+  ) {
+    // Node reports a rejected top-level await as an uncaught exception;
+    // other hosts, such as Bun, may report it as an unhandled rejection.
+    const exitOnPanic = (err: unknown) => {
+      writeHostStderrText(formatUncaughtPanic(err) + '\n')
+      processObj.exit(2)
+    }
+    processObj.on('uncaughtException', exitOnPanic)
+    processObj.on('unhandledRejection', exitOnPanic)
+  }
+}
+
+// formatUncaughtPanic formats a panic that escaped main or a goroutine the way
+// Go prints it before exiting with status 2: the panic value, then the
+// panicking goroutine's frames. Without Go frames the JavaScript stack is
+// printed instead.
+export function formatUncaughtPanic(err: unknown): string {
+  const message =
+    err instanceof Error ?
+      err.message.startsWith('panic: ') ?
+        err.message
+      : `panic: ${err.message}`
+    : `panic: ${String(err)}`
+  const id =
+    (err !== null && typeof err === 'object' ?
+      panicGoroutines.get(err)
+    : undefined) ?? 1
+  const stack = err instanceof Error ? err.stack : undefined
+  const frames = goFrames(stack)
+  const trace =
+    frames.length !== 0 ?
+      formatGoFrames(frames)
+    : (stack ?? '').split('\n').slice(1).join('\n')
+  return `${message}\n\ngoroutine ${id} [running]:\n${trace}`
+}
+
+// mainGoroutineStarted reports whether startMainGoroutine has run, so a
+// bundle entry can start main itself when the host did not recognize the
+// package as its entry script.
+export function mainGoroutineStarted(): boolean {
+  return mainStarted
+}
+
+// runRootGoroutine runs fn as a root goroutine with deadlock detection, as
+// goscript test does for each test. entry names the function fn runs in
+// goroutine dumps. It rejects with a DeadlockError when every goroutine blocks
+// forever before fn returns. A deadlocked fn is abandoned and stays parked.
+export function runRootGoroutine<T>(
+  fn: () => T | Promise<T>,
+  entry = '',
+): Promise<T> {
+  return new Promise<T>((resolve, reject) => {
+    let settled = false
+    const onDeadlock = (err: DeadlockError) => {
+      settled = true
+      rootGoroutines--
+      reject(err)
+    }
+    const g = registerGoroutine(nextGoroutineId++, entry, '', '', null)
+    rootGoroutines++
+    rootDeadlockHandlers.push(onDeadlock)
+    const finish = () => {
+      const idx = rootDeadlockHandlers.indexOf(onDeadlock)
+      if (idx !== -1) {
+        rootDeadlockHandlers.splice(idx, 1)
+      }
+      unregisterGoroutine(g)
+      rootGoroutines--
+      scheduleDeadlockCheck()
+    }
+    Promise.resolve()
+      .then(() => {
+        currentGoroutine = g
+        return fn()
+      })
+      .then(
+        (value) => {
+          if (!settled) {
+            settled = true
+            finish()
+            resolve(value)
+          }
+        },
+        (err: unknown) => {
+          if (!settled) {
+            settled = true
+            finish()
+            reject(err)
+          }
+        },
+      )
+  })
+}
+
+function scheduleDeadlockCheck(): void {
+  if (
+    deadlockCheckScheduled ||
+    (rootGoroutines === 0 && bubbles.size === 0)
+  ) {
+    return
+  }
+  deadlockCheckScheduled = true
+  // Run after pending microtasks so goroutines woken by a send, close, or
+  // unlock have resumed and left their park before the counts are read.
+  setTimeout(checkDeadlock, 0)
+}
+
+function checkDeadlock(): void {
+  deadlockCheckScheduled = false
+  if (pendingHostWork === 0 && stepBubbles()) {
+    // The bubbles that moved on may have woken goroutines; look again once
+    // they have run.
+    scheduleDeadlockCheck()
+    return
+  }
+  if (
+    rootGoroutines === 0 ||
+    pendingHostWork !== 0 ||
+    goroutineTable.size === 0 ||
+    parkedWaits.size !== goroutineTable.size
+  ) {
+    return
+  }
+  if (getHostRuntime().clock.advance?.()) {
+    // The timers that ran may have woken a goroutine; look again once it
+    // has run.
+    scheduleDeadlockCheck()
+    return
+  }
+  const err = new DeadlockError(
+    Array.from(parkedWaits, (parked) => parked.waitPoint),
+    goroutineDump(),
+  )
+  const onDeadlock = rootDeadlockHandlers.pop()
+  if (onDeadlock) {
+    onDeadlock(err)
+    // Other root goroutines, such as parallel tests, may be stuck as well.
+    scheduleDeadlockCheck()
+    return
+  }
+  const processObj = getHostRuntime().processObj
+  if (typeof processObj?.exit === 'function') {
+    writeHostStderrText(err.message + '\n')
+    processObj.exit(2)
+    return
+  }
+  throw err
+}
+
+// numGoroutine returns the number of goroutines that exist, counting main even
+// when the package is not running as the entry script.
+export function numGoroutine(): number {
+  return goroutineTable.size + (mainStarted ? 0 : 1)
+}
+
+// goroutines returns the goroutines that exist, ordered by id.
+export function goroutines(): GoroutineInfo[] {
+  return Array.from(goroutineTable.values()).sort((a, b) => a.id - b.id)
+}
+
+// currentGoroutineInfo returns the running goroutine, or null when it is not
+// known, such as in host callbacks.
+export function currentGoroutineInfo(): GoroutineInfo | null {
+  return runningGoroutine()
+}
+
+// resumeGoroutine marks g as running again after it yielded to the host
+// without parking, as preempt does.
+export function resumeGoroutine(g: GoroutineInfo | null): void {
+  currentGoroutine = g
+}
+
+// goroutineDump formats the goroutines other than skip, or only those of
+// bubble, the way Go prints them in a traceback: each goroutine's id and
+// state, the function a root goroutine runs, and the go statement that
+// created the others.
+export function goroutineDump(
+  skip: GoroutineInfo | null = null,
+  bubble: Bubble | null = null,
+): string {
+  return goroutines()
+    .filter((g) => g !== skip && (bubble === null || g.bubble === bubble))
+    .map((g) => {
+      let text = `goroutine ${g.id} [${g.waitPoint ?? 'runnable'}]:`
+      if (g.entry) {
+        text += `\n${g.entry}(...)`
+      }
+      if (g.createdBy) {
+        text += `\ncreated by ${g.createdBy}`
+        if (g.creatorId) {
+          text += ` in goroutine ${g.creatorId}`
+        }
+      }
+      if (g.createdAt) {
+        text += `\n\t${g.createdAt}`
+      }
+      return text
+    })
+    .join('\n\n')
+}
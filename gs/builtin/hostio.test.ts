@@ -1,14 +1,17 @@
 import { afterEach, describe, expect, it, vi } from 'vitest'
 
+import { hostClock, VirtualClock } from './clock.js'
 import {
   getHostRuntime,
   HostRuntimeOwner,
   type HostRuntime,
   isMainScript,
+  mountHostFS,
   resetHostRuntimeForTests,
   writeHostStderrText,
   writeHostStdoutText,
 } from './hostio.js'
+import { MemFS } from './memfs.js'
 
 const originalDeno = (globalThis as any).Deno
 const originalProcess = (globalThis as any).process
@@ -33,14 +36,18 @@ afterEach(() => {
 
 function runtimeFixture(platform: string): HostRuntime {
   return {
+    args: [],
+    clock: hostClock,
     deno: null,
     getEnv: () => '',
     getStdioHandle: () => null,
+    nodeAsyncHooks: null,
     nodeCrypto: null,
     nodeFS: null,
     platform,
     processObj: null,
     readFD: () => null,
+    virtualFS: null,
     writeFD: () => 0,
     writeStderrText: () => {},
     writeStdoutText: () => {},
@@ -64,6 +71,92 @@ describe('hostio runtime owner', () => {
     expect(owner.current().platform).toBe('host-2')
     expect(detects).toBe(2)
   })
+
+  it('mounts a virtual filesystem until it is unmounted', () => {
+    const written: number[] = []
+    const owner = new HostRuntimeOwner(() => ({
+      ...runtimeFixture('linux'),
+      writeFD: (_fd: number, buffer: Uint8Array) => {
+        written.push(...buffer)
+        return buffer.length
+      },
+    }))
+    const fsys = new MemFS()
+    const unmount = owner.mountFS(fsys)
+
+    const runtime = owner.current()
+    expect(runtime.virtualFS).toBe(fsys)
+    expect(runtime.nodeFS).toBe(fsys)
+    const fd = fsys.openSync('/tmp/out', 'w+')
+    expect(runtime.writeFD(fd, new Uint8Array([1, 2, 3]))).toBe(3)
+    expect(runtime.writeFD(1, new Uint8Array([4]))).toBe(1)
+    fsys.seekSync(fd, 0, 0)
+    const buf = new Uint8Array(8)
+    expect(runtime.readFD(fd, buf)).toBe(3)
+    expect(Array.from(buf.subarray(0, 3))).toEqual([1, 2, 3])
+    expect(written).toEqual([4])
+
+    unmount()
+    expect(owner.current().virtualFS).toBeNull()
+  })
+
+  it('uses a virtual clock until it is restored', () => {
+    const owner = new HostRuntimeOwner(() => runtimeFixture('linux'))
+    const clock = new VirtualClock(1000)
+    const restore = owner.useClock(clock)
+
+    expect(owner.current().clock).toBe(clock)
+    expect(owner.current().clock.now()).toBe(1000)
+
+    restore()
+    expect(owner.current().clock).toBe(hostClock)
+  })
+
+  it('gives browser-like hosts a scratch filesystem', () => {
+    delete (globalThis as any).Deno
+    delete (globalThis as any).process
+    resetHostRuntimeForTests()
+
+    const runtime = getHostRuntime()
+    expect(runtime.deno).toBeNull()
+    const data = new Uint8Array([1, 2, 3, 4])
+    runtime.nodeFS!.writeFileSync!('/tmp/scratch', data)
+    expect(runtime.virtualFS!.statSync!('/tmp/scratch').size).toBe(4)
+
+    const fsys = new MemFS()
+    const unmount = mountHostFS(fsys)
+    expect(getHostRuntime().virtualFS).toBe(fsys)
+    unmount()
+    expect(getHostRuntime()).toBe(runtime)
+  })
+})
+
+describe('hostio program arguments', () => {
+  it('reports the script and its arguments as os.Args', () => {
+    delete (globalThis as any).Deno
+    ;(globalThis as any).process = {
+      argv: ['/usr/bin/bun', '/work/main.gs.ts', '-v', 'input.txt'],
+      env: {},
+    }
+    resetHostRuntimeForTests()
+
+    expect(getHostRuntime().args).toEqual([
+      '/work/main.gs.ts',
+      '-v',
+      'input.txt',
+    ])
+  })
+
+  it('names the program from GOSCRIPT_ARGV0', () => {
+    delete (globalThis as any).Deno
+    ;(globalThis as any).process = {
+      argv: ['/usr/bin/bun', '/work/main.gs.ts', '-v'],
+      env: { GOSCRIPT_ARGV0: 'tool' },
+    }
+    resetHostRuntimeForTests()
+
+    expect(getHostRuntime().args).toEqual(['tool', '-v'])
+  })
 })
 
 describe('hostio text writes', () => {
@@ -194,7 +287,7 @@ describe('hostio text writes', () => {
     writeHostStderrText('stderr\n')
 
     expect(getHostRuntime().platform).toBe('unknown')
-    expect(getHostRuntime().nodeFS).toBeNull()
+    expect(getHostRuntime().virtualFS).toBeInstanceOf(MemFS)
     expect(consoleLog).toHaveBeenCalledWith('browser')
     expect(consoleLog).toHaveBeenCalledWith('stderr')
     expect(consoleError).not.toHaveBeenCalled()
@@ -1,3 +1,7 @@
+import { type HostClock, hostClock } from './clock.js'
+import { startMainGoroutine } from './goroutine.js'
+import { MemFS } from './memfs.js'
+
 export class HostUnsupportedError extends Error {
   constructor() {
     super('operation not implemented in JavaScript environment')
@@ -54,6 +58,15 @@ export type NodeFSModule = {
   ): void
 }
 
+// HostFS is a filesystem that stands in for the host's, such as a MemFS. It
+// has the synchronous node:fs API plus the working directory and seeking,
+// which node:fs leaves to the process.
+export type HostFS = NodeFSModule & {
+  cwd(): string
+  chdir(dir: string): void
+  seekSync(fd: number, offset: number, whence: number): number
+}
+
 export type NodeCryptoHash = {
   copy?(): NodeCryptoHash
   update(data: Uint8Array): NodeCryptoHash
@@ -64,6 +77,17 @@ export type NodeCryptoModule = {
   createHash(algorithm: string): NodeCryptoHash
 }
 
+// NodeAsyncLocalStorage carries a value through an async call chain, across
+// awaits and the callbacks it schedules.
+export type NodeAsyncLocalStorage<T> = {
+  getStore(): T | undefined
+  run<R>(store: T, fn: () => R): R
+}
+
+export type NodeAsyncHooksModule = {
+  AsyncLocalStorage: new <T>() => NodeAsyncLocalStorage<T>
+}
+
 export type DenoStream = {
   readSync?(buffer: Uint8Array): number | null
   writeSync?(buffer: Uint8Array): number
@@ -83,9 +107,17 @@ type HostWriteFD = (fd: number, buffer: Uint8Array) => number
 type HostTextWrite = (data: string) => void
 
 export type HostRuntime = {
+  // args is os.Args: the program name followed by its arguments.
+  args: string[]
+  // clock is what time.Now and the runtime's timers read and schedule on.
+  clock: HostClock
   deno: any | null
+  nodeAsyncHooks: NodeAsyncHooksModule | null
   nodeCrypto: NodeCryptoModule | null
   nodeFS: NodeFSModule | null
+  // virtualFS is the filesystem that replaces the host's, or null. nodeFS is
+  // virtualFS then and deno is null, so file operations need no other check.
+  virtualFS: HostFS | null
   platform: string
   processObj: any | null
   getEnv(name: string): string
@@ -203,6 +235,33 @@ function detectNodeCrypto(processObj: any | null): NodeCryptoModule | null {
   return null
 }
 
+function detectNodeAsyncHooks(
+  processObj: any | null,
+): NodeAsyncHooksModule | null {
+  if (processObj && typeof processObj.getBuiltinModule === 'function') {
+    const module = processObj.getBuiltinModule('async_hooks')
+    if (module && typeof module.AsyncLocalStorage === 'function') {
+      return module as NodeAsyncHooksModule
+    }
+  }
+
+  const requireFn = getDynamicRequire()
+  if (requireFn) {
+    for (const specifier of ['node:async_hooks', 'async_hooks']) {
+      try {
+        const module = requireFn(specifier) as NodeAsyncHooksModule | null
+        if (module && typeof module.AsyncLocalStorage === 'function') {
+          return module
+        }
+      } catch {
+        // Try the next fallback.
+      }
+    }
+  }
+
+  return null
+}
+
 function hasURLScheme(path: string): boolean {
   return /^[a-zA-Z][a-zA-Z\d+\-.]*:/.test(path)
 }
@@ -226,6 +285,9 @@ function absolutePathToFileURL(path: string, isDirectory: boolean): string {
 
 export function getCurrentWorkingDirectory(): string | null {
   const runtime = getHostRuntime()
+  if (runtime.virtualFS) {
+    return runtime.virtualFS.cwd()
+  }
   try {
     if (typeof runtime.processObj?.cwd === 'function') {
       return runtime.processObj.cwd()
@@ -279,12 +341,35 @@ function fallbackConsoleLogWriter(): HostTextWrite {
   }
 }
 
+// detectArgs returns the host's program name and arguments. A launcher that
+// runs the program through a script, as goscript run does, names the program
+// in GOSCRIPT_ARGV0 so os.Args[0] matches the Go command.
+function detectArgs(
+  deno: any | null,
+  processObj: any | null,
+  getEnv: (name: string) => string,
+): string[] {
+  let program = ''
+  let rest: string[] = []
+  if (Array.isArray(deno?.args)) {
+    program = String(deno.mainModule ?? '')
+    rest = deno.args
+  } else if (Array.isArray(processObj?.argv)) {
+    program = String(processObj.argv[1] ?? processObj.argv[0] ?? '')
+    rest = processObj.argv.slice(2)
+  } else {
+    return []
+  }
+  return [getEnv('GOSCRIPT_ARGV0') || program, ...rest]
+}
+
 function detectHostRuntime(): HostRuntime {
   const globalObj = globalThis as any
   const deno = globalObj.Deno ?? null
   const processObj = globalObj.process ?? null
   const nodeFS = detectNodeFS(processObj)
   const nodeCrypto = detectNodeCrypto(processObj)
+  const nodeAsyncHooks = detectNodeAsyncHooks(processObj)
 
   const getStdioHandle = (fd: number): DenoFileLike | null => {
     if (!deno) {
@@ -315,6 +400,8 @@ function detectHostRuntime(): HostRuntime {
     return processObj?.env?.[name] ?? ''
   }
 
+  const args = detectArgs(deno, processObj, getEnv)
+
   const readFD: HostReadFD = (
     fd: number,
     buffer: Uint8Array,
@@ -382,19 +469,54 @@ function detectHostRuntime(): HostRuntime {
     fallbackConsoleLogWriter()(data)
   }
 
-  return {
+  const runtime: HostRuntime = {
+    args,
+    clock: hostClock,
     deno,
     getEnv,
     getStdioHandle,
+    nodeAsyncHooks,
     nodeCrypto,
     nodeFS,
     platform,
     processObj,
     readFD,
+    virtualFS: null,
     writeFD,
     writeStderrText,
     writeStdoutText,
   }
+  if (!deno && !nodeFS) {
+    // Browsers have no filesystem, so give programs a scratch one.
+    return withVirtualFS(runtime, new MemFS())
+  }
+  return runtime
+}
+
+// withVirtualFS returns runtime with its file operations sent to fsys. The
+// standard streams stay with the host.
+function withVirtualFS(runtime: HostRuntime, fsys: HostFS): HostRuntime {
+  return {
+    ...runtime,
+    deno: null,
+    nodeFS: fsys,
+    virtualFS: fsys,
+    readFD: (fd: number, buffer: Uint8Array): number | null => {
+      if (fd <= 2) {
+        return runtime.readFD(fd, buffer)
+      }
+      return fsys.readSync(fd, buffer, 0, buffer.length, null)
+    },
+    writeFD: (fd: number, buffer: Uint8Array): number => {
+      if (fd <= 2) {
+        return runtime.writeFD(fd, buffer)
+      }
+      return writeAllSync(
+        (chunk: Uint8Array) => fsys.writeSync(fd, chunk, 0, chunk.length, null),
+        buffer,
+      )
+    },
+  }
 }
 
 export class HostRuntimeOwner {
@@ -413,6 +535,54 @@ export class HostRuntimeOwner {
   reset(): void {
     this.runtime = this.detector()
   }
+
+  // captureStdout sends standard output to a buffer until the returned
+  // function is called, which restores the previous runtime and returns the
+  // captured text.
+  captureStdout(): () => string {
+    const previous = this.runtime
+    const decoder = new TextDecoder()
+    let text = ''
+    this.runtime = {
+      ...previous,
+      writeFD: (fd: number, buffer: Uint8Array): number => {
+        if (fd !== 1) {
+          return previous.writeFD(fd, buffer)
+        }
+        text += decoder.decode(buffer, { stream: true })
+        return buffer.length
+      },
+      writeStdoutText: (data: string) => {
+        text += data
+      },
+    }
+    return () => {
+      this.runtime = previous
+      return text + decoder.decode()
+    }
+  }
+
+  // mountFS sends file operations to fsys instead of the host filesystem
+  // until the returned function is called, which restores the previous
+  // runtime.
+  mountFS(fsys: HostFS): () => void {
+    const previous = this.runtime
+    this.runtime = withVirtualFS(previous, fsys)
+    return () => {
+      this.runtime = previous
+    }
+  }
+
+  // useClock sends time reads and timers to clock, such as a VirtualClock,
+  // until the returned function is called, which restores the previous
+  // runtime.
+  useClock(clock: HostClock): () => void {
+    const previous = this.runtime
+    this.runtime = { ...previous, clock }
+    return () => {
+      this.runtime = previous
+    }
+  }
 }
 
 export const hostRuntimeOwner = new HostRuntimeOwner()
@@ -425,6 +595,24 @@ export function resetHostRuntimeForTests(): void {
   hostRuntimeOwner.reset()
 }
 
+// captureHostStdout captures standard output until the returned function is
+// called. Examples use it to check what they print.
+export function captureHostStdout(): () => string {
+  return hostRuntimeOwner.captureStdout()
+}
+
+// mountHostFS sends file operations to fsys, such as a MemFS, until the
+// returned function is called.
+export function mountHostFS(fsys: HostFS): () => void {
+  return hostRuntimeOwner.mountFS(fsys)
+}
+
+// installHostClock sends time reads and timers to clock, such as a
+// VirtualClock, until the returned function is called.
+export function installHostClock(clock: HostClock): () => void {
+  return hostRuntimeOwner.useClock(clock)
+}
+
 export function writeHostStdoutText(data: string): void {
   getHostRuntime().writeStdoutText(data)
 }
@@ -433,7 +621,18 @@ export function writeHostStderrText(data: string): void {
   getHostRuntime().writeStderrText(data)
 }
 
+// isMainScript reports whether meta is the module the host started. The
+// generated entry code runs main only when it is, so a true result also
+// registers the main goroutine for deadlock detection.
 export function isMainScript(meta: MainScriptMeta): boolean {
+  if (!isEntryModule(meta)) {
+    return false
+  }
+  startMainGoroutine()
+  return true
+}
+
+function isEntryModule(meta: MainScriptMeta): boolean {
   if (meta.main === true) {
     return true
   }
@@ -8,3 +8,10 @@ export * from './varRef.js'
 export * from './defer.js'
 export * from './errors.js'
 export * from './hostio.js'
+export * from './clock.js'
+export * from './memfs.js'
+export * from './memfs-copy.js'
+export * from './schedule.js'
+export * from './goroutine.js'
+export * from './traceback.js'
+export * from './coverage.js'
@@ -0,0 +1,123 @@
+import { describe, expect, it } from 'vitest'
+
+import { type Bytes } from './builtin.js'
+import { type GoError } from './errors.js'
+import { memFSFromFS } from './memfs-copy.js'
+import { arrayToSlice, stringToBytes, type Slice } from './slice.js'
+
+const modeDir = 2147483648
+const modeSymlink = 1 << 27
+
+const errNotExist: GoError = { Error: () => 'file does not exist' }
+
+type treeNode = { mode: number; data?: string; link?: string }
+
+type treeEntry = {
+  Info(): Promise<[unknown, GoError]>
+  IsDir(): boolean
+  Name(): string
+  Type(): number
+}
+
+// treeFS is a file system with asynchronous methods, like a generated Go
+// implementation whose methods block.
+class treeFS {
+  constructor(private readonly nodes: Record<string, treeNode>) {}
+
+  async Open(_name: string): Promise<[null, GoError]> {
+    return [null, errNotExist]
+  }
+
+  async ReadDir(name: string): Promise<[Slice<treeEntry>, GoError]> {
+    const prefix = name === '.' ? '' : name + '/'
+    const entries = Object.keys(this.nodes)
+      .filter((path) => path.startsWith(prefix))
+      .filter((path) => !path.slice(prefix.length).includes('/'))
+      .sort()
+      .map((path) => this.entry(path))
+    return [arrayToSlice(entries), null]
+  }
+
+  async ReadFile(name: string): Promise<[Bytes, GoError]> {
+    return [stringToBytes(this.nodes[name]!.data ?? ''), null]
+  }
+
+  async ReadLink(name: string): Promise<[string, GoError]> {
+    return [this.nodes[name]!.link ?? '', null]
+  }
+
+  private entry(path: string): treeEntry {
+    const node = this.nodes[path]!
+    const info = {
+      IsDir: () => (node.mode & modeDir) !== 0,
+      ModTime: () => ({ UnixMilli: () => 5000n }),
+      Mode: () => node.mode,
+      Name: () => path.split('/').pop()!,
+    }
+    return {
+      Info: async () => [info, null],
+      IsDir: info.IsDir,
+      Name: info.Name,
+      Type: () => node.mode & (modeDir | modeSymlink),
+    }
+  }
+}
+
+describe('memFSFromFS', () => {
+  it('copies a file system tree', async () => {
+    const [mem, err] = await memFSFromFS(
+      new treeFS({
+        'bin': { mode: modeDir | 0o755 },
+        'bin/tool': { mode: 0o755, data: '#!' },
+        'etc': { mode: modeDir | 0o700 },
+        'etc/conf': { mode: 0o600, data: 'key=value' },
+        'etc/link': { mode: modeSymlink | 0o777, link: 'conf' },
+      }) as never,
+    )
+
+    expect(err).toBeNull()
+    expect(mem!.readdirSync('/')).toEqual(['bin', 'etc', 'tmp'])
+    expect(mem!.statSync('/etc').mode).toBe(0o40700)
+    expect(mem!.statSync('/bin/tool').mode).toBe(0o100755)
+    expect(mem!.statSync('/bin/tool').mtimeMs).toBe(5000)
+    expect(mem!.readlinkSync('/etc/link')).toBe('conf')
+    expect(new TextDecoder().decode(mem!.readFileSync('/etc/link'))).toBe(
+      'key=value',
+    )
+  })
+
+  it('merges a tmp directory with the one it creates', async () => {
+    const [mem, err] = await memFSFromFS(
+      new treeFS({
+        'tmp': { mode: modeDir | 0o755 },
+        'tmp/cache': { mode: 0o644, data: 'hit' },
+      }) as never,
+    )
+
+    expect(err).toBeNull()
+    expect(mem!.statSync('/tmp').mode).toBe(0o40755)
+    expect(new TextDecoder().decode(mem!.readFileSync('/tmp/cache'))).toBe(
+      'hit',
+    )
+  })
+
+  it('returns host errors as Go errors', async () => {
+    const [mem, err] = await memFSFromFS(
+      new treeFS({
+        'tmp': { mode: 0o644, data: 'not a directory' },
+      }) as never,
+    )
+
+    expect(mem).toBeNull()
+    expect(err!.Error()).toContain('/tmp')
+  })
+
+  it('returns the error of a failed read', async () => {
+    const [mem, err] = await memFSFromFS({
+      Open: (_name: string) => [null, errNotExist],
+    })
+
+    expect(mem).toBeNull()
+    expect(err).toBe(errNotExist)
+  })
+})
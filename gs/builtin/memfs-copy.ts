@@ -0,0 +1,197 @@
+import { bytesToUint8Array, type Bytes } from './builtin.js'
+import { type GoError, toGoError } from './errors.js'
+import { MemFS } from './memfs.js'
+import { asArray, type Slice } from './slice.js'
+
+// The runtime cannot import io/fs, which is built on it, so memFSFromFS reads
+// Go file systems through the methods of the io/fs interfaces. goFS, goFile,
+// goDirEntry and goFileInfo are the parts of fs.FS, fs.File, fs.DirEntry and
+// fs.FileInfo values it calls. Their methods may be asynchronous, as those of
+// generated Go types that block are.
+
+type maybeAsync<T> = T | Promise<T>
+
+type goFS = {
+  Open(name: string): maybeAsync<[goFile | null, GoError]>
+  ReadDir?(name: string): maybeAsync<[Slice<goDirEntry>, GoError]>
+  ReadFile?(name: string): maybeAsync<[Bytes, GoError]>
+  ReadLink?(name: string): maybeAsync<[string, GoError]>
+}
+
+type goFile = {
+  Read(p: Bytes): maybeAsync<[number, GoError]>
+  ReadDir?(n: number): maybeAsync<[Slice<goDirEntry>, GoError]>
+  Close(): maybeAsync<GoError>
+}
+
+type goDirEntry = {
+  Name(): string
+  IsDir(): boolean
+  Type(): number
+  Info(): maybeAsync<[goFileInfo | null, GoError]>
+}
+
+type goFileInfo = {
+  Mode(): number
+  ModTime(): { UnixMilli(): bigint } | null
+}
+
+// goModeSymlink and goModePerm are fs.ModeSymlink and fs.ModePerm.
+const goModeSymlink = 1 << 27
+const goModePerm = 0o777
+
+// memFSFromFS returns a MemFS holding a copy of the tree of the Go file
+// system fsys, such as an embed.FS or fstest.MapFS, at its root. Mounting it
+// with mountHostFS lets os code read the files. The copy is taken up front
+// because the methods of fsys may be asynchronous, while the host filesystem
+// API is not. Directories that already exist in a new MemFS, such as /tmp,
+// are merged with those of fsys.
+export async function memFSFromFS(
+  fsys: goFS,
+): Promise<[MemFS | null, GoError]> {
+  const mem = new MemFS()
+  const err = await copyDir(fsys, mem, '.')
+  if (err != null) {
+    return [null, err]
+  }
+  return [mem, null]
+}
+
+async function copyDir(fsys: goFS, mem: MemFS, dir: string): Promise<GoError> {
+  const [entries, err] = await readDir(fsys, dir)
+  if (err != null) {
+    return err
+  }
+  for (const entry of entries) {
+    const name = dir === '.' ? entry.Name() : dir + '/' + entry.Name()
+    const target = '/' + name
+    if ((entry.Type() & goModeSymlink) !== 0) {
+      if (typeof fsys.ReadLink !== 'function') {
+        continue
+      }
+      const [link, linkErr] = await fsys.ReadLink(name)
+      if (linkErr != null) {
+        return linkErr
+      }
+      const hostErr = hostCall(() => mem.symlinkSync(link, target))
+      if (hostErr != null) {
+        return hostErr
+      }
+      continue
+    }
+    const [info, infoErr] = await entry.Info()
+    if (infoErr != null) {
+      return infoErr
+    }
+    const perm = info!.Mode() & goModePerm
+    if (entry.IsDir()) {
+      const hostErr = hostCall(() => {
+        mem.mkdirSync(target, { recursive: true })
+        mem.chmodSync(target, perm)
+      })
+      if (hostErr != null) {
+        return hostErr
+      }
+      const dirErr = await copyDir(fsys, mem, name)
+      if (dirErr != null) {
+        return dirErr
+      }
+    } else {
+      const [data, readErr] = await readFile(fsys, name)
+      if (readErr != null) {
+        return readErr
+      }
+      const hostErr = hostCall(() =>
+        mem.writeFileSync(target, data, { mode: perm }),
+      )
+      if (hostErr != null) {
+        return hostErr
+      }
+    }
+    const modTime = info!.ModTime()
+    if (modTime != null) {
+      const seconds = Number(modTime.UnixMilli()) / 1000
+      const hostErr = hostCall(() => mem.utimesSync(target, seconds, seconds))
+      if (hostErr != null) {
+        return hostErr
+      }
+    }
+  }
+  return null
+}
+
+// hostCall runs fn and returns the error it throws as a Go error.
+function hostCall(fn: () => void): GoError {
+  try {
+    fn()
+  } catch (err) {
+    return toGoError(err as Error)
+  }
+  return null
+}
+
+// readDir is fs.ReadDir: it uses fs.ReadDirFS when fsys implements it, and
+// otherwise reads the directory through Open, sorted by name.
+async function readDir(
+  fsys: goFS,
+  name: string,
+): Promise<[goDirEntry[], GoError]> {
+  if (typeof fsys.ReadDir === 'function') {
+    const [entries, err] = await fsys.ReadDir(name)
+    return [asArray(entries), err]
+  }
+  const [file, err] = await fsys.Open(name)
+  if (err != null) {
+    return [[], err]
+  }
+  if (typeof file!.ReadDir !== 'function') {
+    await file!.Close()
+    return [[], toGoError(new Error(`readdir ${name}: not implemented`))]
+  }
+  const [entries, readErr] = await file!.ReadDir(-1)
+  await file!.Close()
+  const list = asArray(entries)
+  list.sort((a, b) => (a.Name() < b.Name() ? -1 : a.Name() > b.Name() ? 1 : 0))
+  return [list, readErr]
+}
+
+// readFile is fs.ReadFile: it uses fs.ReadFileFS when fsys implements it,
+// and otherwise reads the file through Open.
+async function readFile(
+  fsys: goFS,
+  name: string,
+): Promise<[Uint8Array, GoError]> {
+  if (typeof fsys.ReadFile === 'function') {
+    const [data, err] = await fsys.ReadFile(name)
+    return [bytesToUint8Array(data), err]
+  }
+  const [file, err] = await fsys.Open(name)
+  if (err != null) {
+    return [new Uint8Array(0), err]
+  }
+  const chunks: Uint8Array[] = []
+  let size = 0
+  let readErr: GoError = null
+  for (;;) {
+    const buf = new Uint8Array(32 * 1024)
+    const [n, err] = await file!.Read(buf)
+    chunks.push(buf.subarray(0, n))
+    size += n
+    // io.EOF, which the runtime cannot import, ends the file.
+    if (err != null && err.Error() === 'EOF') {
+      break
+    }
+    if (err != null) {
+      readErr = err
+      break
+    }
+  }
+  await file!.Close()
+  const data = new Uint8Array(size)
+  let offset = 0
+  for (const chunk of chunks) {
+    data.set(chunk, offset)
+    offset += chunk.length
+  }
+  return [data, readErr]
+}
@@ -0,0 +1,140 @@
+import { describe, expect, it } from 'vitest'
+
+import { MemFS, MemFSError } from './memfs.js'
+
+const encoder = new TextEncoder()
+const decoder = new TextDecoder()
+
+function thrown(fn: () => unknown): MemFSError {
+  try {
+    fn()
+  } catch (err) {
+    return err as MemFSError
+  }
+  throw new Error('expected an error')
+}
+
+describe('MemFS', () => {
+  it('reads, writes and seeks open files', () => {
+    const fsys = new MemFS()
+    const fd = fsys.openSync('/tmp/a.txt', 'w+', 0o600)
+    expect(fd).toBeGreaterThan(2)
+    expect(fsys.writeSync(fd, encoder.encode('hello world'))).toBe(11)
+    expect(fsys.seekSync(fd, 6, 0)).toBe(6)
+
+    const buf = new Uint8Array(16)
+    const n = fsys.readSync(fd, buf, 0, buf.length, null)
+    expect(decoder.decode(buf.subarray(0, n))).toBe('world')
+    expect(fsys.readSync(fd, buf, 0, buf.length, null)).toBe(0)
+
+    fsys.writeSync(fd, encoder.encode('W'), 0, 1, 6)
+    expect(fsys.seekSync(fd, 0, 1)).toBe(11)
+    fsys.ftruncateSync(fd, 7)
+    expect(fsys.fstatSync(fd).size).toBe(7)
+    fsys.closeSync(fd)
+
+    expect(decoder.decode(fsys.readFileSync('/tmp/a.txt'))).toBe('hello W')
+    const stat = fsys.statSync('/tmp/a.txt')
+    expect(stat.isFile()).toBe(true)
+    expect(stat.mode & 0o777).toBe(0o600)
+    expect(thrown(() => fsys.closeSync(fd)).code).toBe('EBADF')
+  })
+
+  it('appends and honors exclusive creation', () => {
+    const fsys = new MemFS()
+    fsys.writeFileSync('log', 'a')
+    const fd = fsys.openSync('log', 'a')
+    fsys.writeSync(fd, encoder.encode('b'), 0, 1, 0)
+    fsys.closeSync(fd)
+    expect(decoder.decode(fsys.readFileSync('/log'))).toBe('ab')
+
+    const err = thrown(() => fsys.openSync('/log', 'wx'))
+    expect(err.code).toBe('EEXIST')
+    expect(err.message).toBe("EEXIST: file already exists, open '/log'")
+    expect(thrown(() => fsys.openSync('/missing', 'r')).code).toBe('ENOENT')
+    expect(thrown(() => fsys.openSync('/tmp', 'w')).code).toBe('EISDIR')
+  })
+
+  it('manages directories relative to the working directory', () => {
+    const fsys = new MemFS()
+    expect(fsys.mkdirSync('/a/b/c', { recursive: true })).toBe('/a')
+    expect(fsys.mkdirSync('/a/b', { recursive: true })).toBeUndefined()
+    expect(thrown(() => fsys.mkdirSync('/a')).code).toBe('EEXIST')
+
+    fsys.chdir('/a/b')
+    expect(fsys.cwd()).toBe('/a/b')
+    fsys.writeFileSync('f.txt', 'x')
+    fsys.chdir('c/..')
+    expect(fsys.cwd()).toBe('/a/b')
+    expect(fsys.readdirSync('.')).toEqual(['c', 'f.txt'])
+    const entries = fsys.readdirSync('/a/b', { withFileTypes: true })
+    expect(entries.map((entry) => [entry.name, entry.isDirectory()])).toEqual([
+      ['c', true],
+      ['f.txt', false],
+    ])
+    expect(thrown(() => fsys.readdirSync('f.txt')).code).toBe('ENOTDIR')
+    expect(thrown(() => fsys.statSync('f.txt/')).code).toBe('ENOTDIR')
+
+    expect(thrown(() => fsys.rmdirSync('/a/b')).code).toBe('ENOTEMPTY')
+    expect(thrown(() => fsys.rmSync('/a/b')).code).toBe('EISDIR')
+    fsys.rmSync('/a', { recursive: true })
+    fsys.rmSync('/a', { force: true })
+    expect(thrown(() => fsys.statSync('/a')).code).toBe('ENOENT')
+  })
+
+  it('resolves symbolic links and hard links', () => {
+    const fsys = new MemFS()
+    fsys.mkdirSync('/data')
+    fsys.writeFileSync('/data/v1', 'one')
+    fsys.symlinkSync('v1', '/data/current')
+    fsys.symlinkSync('/data', '/link')
+
+    expect(decoder.decode(fsys.readFileSync('/link/current'))).toBe('one')
+    expect(fsys.readlinkSync('/data/current')).toBe('v1')
+    expect(fsys.lstatSync('/data/current').isSymbolicLink()).toBe(true)
+    expect(fsys.statSync('/data/current').isFile()).toBe(true)
+    expect(thrown(() => fsys.readlinkSync('/data/v1')).code).toBe('EINVAL')
+
+    fsys.symlinkSync('loop', '/loop')
+    expect(thrown(() => fsys.statSync('/loop')).code).toBe('ELOOP')
+
+    fsys.linkSync('/data/v1', '/hard')
+    fsys.unlinkSync('/data/v1')
+    expect(fsys.statSync('/hard').nlink).toBe(1)
+    expect(decoder.decode(fsys.readFileSync('/hard'))).toBe('one')
+    expect(thrown(() => fsys.statSync('/data/current')).code).toBe('ENOENT')
+  })
+
+  it('renames like rename(2)', () => {
+    const fsys = new MemFS()
+    fsys.mkdirSync('/d/sub', { recursive: true })
+    fsys.writeFileSync('/f', 'f')
+    fsys.writeFileSync('/g', 'g')
+
+    fsys.renameSync('/f', '/g')
+    expect(decoder.decode(fsys.readFileSync('/g'))).toBe('f')
+    expect(thrown(() => fsys.statSync('/f')).code).toBe('ENOENT')
+
+    expect(thrown(() => fsys.renameSync('/d', '/d/sub/x')).code).toBe('EINVAL')
+    expect(thrown(() => fsys.renameSync('/g', '/d')).code).toBe('EISDIR')
+    expect(thrown(() => fsys.renameSync('/d', '/g')).code).toBe('ENOTDIR')
+    fsys.renameSync('/d', '/e')
+    expect(fsys.statSync('/e/sub').isDirectory()).toBe(true)
+  })
+
+  it('sets modes, owners and times', () => {
+    const fsys = new MemFS()
+    fsys.writeFileSync('/f', 'x', { mode: 0o644 })
+    fsys.chmodSync('/f', 0o400)
+    fsys.chownSync('/f', 10, 20)
+    fsys.truncateSync('/f', 3)
+    fsys.utimesSync('/f', 1, new Date(2000))
+
+    const stat = fsys.statSync('/f')
+    expect(stat.mode).toBe(0o100400)
+    expect([stat.uid, stat.gid]).toEqual([10, 20])
+    expect(stat.atimeMs).toBe(1000)
+    expect(stat.mtime.getTime()).toBe(2000)
+    expect(Array.from(fsys.readFileSync('/f'))).toEqual([120, 0, 0])
+  })
+})
@@ -0,0 +1,776 @@
+const S_IFMT = 0o170000
+const S_IFREG = 0o100000
+const S_IFDIR = 0o040000
+const S_IFLNK = 0o120000
+
+const O_ACCMODE = 3
+const O_WRONLY = 1
+const O_RDWR = 2
+const O_CREAT = 64
+const O_EXCL = 128
+const O_TRUNC = 512
+const O_APPEND = 1024
+const O_DIRECTORY = 65536
+const O_NOFOLLOW = 131072
+
+// maxSymlinks bounds the symbolic links one lookup follows, as Linux does.
+const maxSymlinks = 40
+
+const errnoDescriptions: Record<string, [number, string]> = {
+  EPERM: [1, 'operation not permitted'],
+  ENOENT: [2, 'no such file or directory'],
+  EBADF: [9, 'bad file descriptor'],
+  EBUSY: [16, 'resource busy or locked'],
+  EEXIST: [17, 'file already exists'],
+  ENOTDIR: [20, 'not a directory'],
+  EISDIR: [21, 'illegal operation on a directory'],
+  EINVAL: [22, 'invalid argument'],
+  ENOTEMPTY: [39, 'directory not empty'],
+  ELOOP: [40, 'too many symbolic links encountered'],
+}
+
+// MemFSError is an error of a MemFS operation. It has the code, errno,
+// syscall and path properties of node:fs errors.
+export class MemFSError extends Error {
+  public readonly errno: number
+
+  constructor(
+    public readonly code: string,
+    public readonly syscall: string,
+    public readonly path?: string,
+    public readonly dest?: string,
+  ) {
+    const [errno, description] = errnoDescriptions[code] ?? [0, code]
+    let message = `${code}: ${description}, ${syscall}`
+    if (path !== undefined) {
+      message += ` '${path}'`
+    }
+    if (dest !== undefined) {
+      message += ` -> '${dest}'`
+    }
+    super(message)
+    this.errno = -errno
+  }
+}
+
+let nextIno = 1
+
+class memNode {
+  public readonly ino = nextIno++
+  public nlink = 1
+  public uid = 0
+  public gid = 0
+  public atimeMs: number
+  public mtimeMs: number
+  public ctimeMs: number
+  public readonly birthtimeMs: number
+  // data holds the bytes of a file, of which the first size are in use.
+  public data = new Uint8Array(0)
+  public size = 0
+  // entries holds the children of a directory.
+  public readonly entries = new Map<string, memNode>()
+  // target is the target of a symbolic link.
+  public target = ''
+
+  constructor(public mode: number) {
+    const now = Date.now()
+    this.atimeMs = now
+    this.mtimeMs = now
+    this.ctimeMs = now
+    this.birthtimeMs = now
+  }
+
+  public get type(): number {
+    return this.mode & S_IFMT
+  }
+
+  public touch(): void {
+    this.mtimeMs = Date.now()
+    this.ctimeMs = this.mtimeMs
+  }
+
+  public resize(size: number): void {
+    if (size > this.data.length) {
+      const data = new Uint8Array(Math.max(size, this.data.length * 2))
+      data.set(this.data.subarray(0, this.size))
+      this.data = data
+    } else if (size < this.size) {
+      this.data.fill(0, size, this.size)
+    }
+    this.size = size
+  }
+}
+
+// memStats is the node:fs Stats of a MemFS node.
+class memStats {
+  public readonly dev = 1
+  public readonly rdev = 0
+  public readonly blksize = 4096
+  public readonly ino: number
+  public readonly mode: number
+  public readonly nlink: number
+  public readonly uid: number
+  public readonly gid: number
+  public readonly size: number
+  public readonly blocks: number
+  public readonly atimeMs: number
+  public readonly mtimeMs: number
+  public readonly ctimeMs: number
+  public readonly birthtimeMs: number
+
+  constructor(node: memNode) {
+    this.ino = node.ino
+    this.mode = node.mode
+    this.nlink = node.nlink
+    this.uid = node.uid
+    this.gid = node.gid
+    this.size = node.type === S_IFLNK ? node.target.length : node.size
+    this.blocks = Math.ceil(this.size / 512)
+    this.atimeMs = node.atimeMs
+    this.mtimeMs = node.mtimeMs
+    this.ctimeMs = node.ctimeMs
+    this.birthtimeMs = node.birthtimeMs
+  }
+
+  public get atime(): Date {
+    return new Date(this.atimeMs)
+  }
+
+  public get mtime(): Date {
+    return new Date(this.mtimeMs)
+  }
+
+  public get ctime(): Date {
+    return new Date(this.ctimeMs)
+  }
+
+  public isFile(): boolean {
+    return (this.mode & S_IFMT) === S_IFREG
+  }
+
+  public isDirectory(): boolean {
+    return (this.mode & S_IFMT) === S_IFDIR
+  }
+
+  public isSymbolicLink(): boolean {
+    return (this.mode & S_IFMT) === S_IFLNK
+  }
+}
+
+// memDirent is the node:fs Dirent of a directory entry.
+class memDirent {
+  constructor(
+    public readonly name: string,
+    private readonly mode: number,
+  ) {}
+
+  public isFile(): boolean {
+    return (this.mode & S_IFMT) === S_IFREG
+  }
+
+  public isDirectory(): boolean {
+    return (this.mode & S_IFMT) === S_IFDIR
+  }
+
+  public isSymbolicLink(): boolean {
+    return (this.mode & S_IFMT) === S_IFLNK
+  }
+}
+
+type memFile = {
+  node: memNode
+  readable: boolean
+  writable: boolean
+  append: boolean
+  position: number
+}
+
+// memEntry is the result of a lookup. node is undefined when the last
+// element of the path does not exist, and name is empty when the path names
+// the root or ends in "..".
+type memEntry = {
+  parent: memNode
+  name: string
+  node: memNode | undefined
+  // dirs are the directories from the root to parent.
+  dirs: memNode[]
+  // path is the absolute path of the entry with the symbolic links resolved.
+  path: string
+}
+
+function splitPath(path: string): string[] {
+  return path.split('/').filter((name) => name !== '' && name !== '.')
+}
+
+function joinNames(names: string[]): string {
+  return '/' + names.join('/')
+}
+
+function parseOpenFlags(flags: number | string): number {
+  if (typeof flags === 'number') {
+    return flags
+  }
+  switch (flags.replace('s', '')) {
+    case 'r':
+      return 0
+    case 'r+':
+      return O_RDWR
+    case 'w':
+      return O_TRUNC | O_CREAT | O_WRONLY
+    case 'wx':
+    case 'xw':
+      return O_TRUNC | O_CREAT | O_WRONLY | O_EXCL
+    case 'w+':
+      return O_TRUNC | O_CREAT | O_RDWR
+    case 'wx+':
+    case 'xw+':
+      return O_TRUNC | O_CREAT | O_RDWR | O_EXCL
+    case 'a':
+      return O_APPEND | O_CREAT | O_WRONLY
+    case 'ax':
+    case 'xa':
+      return O_APPEND | O_CREAT | O_WRONLY | O_EXCL
+    case 'a+':
+      return O_APPEND | O_CREAT | O_RDWR
+    case 'ax+':
+    case 'xa+':
+      return O_APPEND | O_CREAT | O_RDWR | O_EXCL
+    default:
+      throw new MemFSError('EINVAL', 'open')
+  }
+}
+
+function timeMs(time: Date | number | string): number {
+  if (time instanceof Date) {
+    return time.getTime()
+  }
+  if (typeof time === 'string') {
+    return Number(time) * 1000
+  }
+  return time * 1000
+}
+
+// MemFS is an in-memory filesystem with the synchronous node:fs API that os,
+// path/filepath and syscall use. Hosts without a filesystem, such as
+// browsers, start with an empty one holding /tmp, and
+// HostRuntimeOwner.mountFS swaps one in for hermetic tests.
+export class MemFS {
+  private readonly root = new memNode(S_IFDIR | 0o755)
+  private readonly files = new Map<number, memFile>()
+  // Descriptors 0 to 2 stay with the host standard streams.
+  private nextFD = 3
+  private workDir = '/'
+
+  constructor() {
+    this.mkdirSync('/tmp', 0o1777)
+  }
+
+  public cwd(): string {
+    return this.workDir
+  }
+
+  public chdir(dir: string): void {
+    const entry = this.lookup(dir, 'chdir', true)
+    if (entry.node === undefined) {
+      throw new MemFSError('ENOENT', 'chdir', this.workDir, dir)
+    }
+    if (entry.node.type !== S_IFDIR) {
+      throw new MemFSError('ENOTDIR', 'chdir', this.workDir, dir)
+    }
+    this.workDir = entry.path
+  }
+
+  public openSync(
+    path: string,
+    flags: number | string = 'r',
+    mode = 0o666,
+  ): number {
+    const flag = parseOpenFlags(flags)
+    const create = (flag & O_CREAT) !== 0
+    const exclusive = create && (flag & O_EXCL) !== 0
+    const entry = this.lookup(
+      path,
+      'open',
+      !exclusive && (flag & O_NOFOLLOW) === 0,
+    )
+    const access = flag & O_ACCMODE
+    const writable = access === O_WRONLY || access === O_RDWR
+    let node = entry.node
+    if (node === undefined) {
+      if (!create) {
+        throw new MemFSError('ENOENT', 'open', path)
+      }
+      node = new memNode(S_IFREG | (mode & 0o7777))
+      this.link(entry, node)
+    } else {
+      if (exclusive) {
+        throw new MemFSError('EEXIST', 'open', path)
+      }
+      if (node.type === S_IFLNK) {
+        throw new MemFSError('ELOOP', 'open', path)
+      }
+      if (node.type === S_IFDIR && writable) {
+        throw new MemFSError('EISDIR', 'open', path)
+      }
+      if ((flag & O_TRUNC) !== 0 && writable && node.type === S_IFREG) {
+        node.resize(0)
+        node.touch()
+      }
+    }
+    if ((flag & O_DIRECTORY) !== 0 && node.type !== S_IFDIR) {
+      throw new MemFSError('ENOTDIR', 'open', path)
+    }
+    const fd = this.nextFD++
+    this.files.set(fd, {
+      node,
+      readable: access !== O_WRONLY,
+      writable,
+      append: (flag & O_APPEND) !== 0,
+      position: 0,
+    })
+    return fd
+  }
+
+  public closeSync(fd: number): void {
+    this.file(fd, 'close')
+    this.files.delete(fd)
+  }
+
+  public readSync(
+    fd: number,
+    buffer: Uint8Array,
+    offset = 0,
+    length = buffer.length - offset,
+    position: number | null = null,
+  ): number {
+    const file = this.file(fd, 'read')
+    if (file.node.type === S_IFDIR) {
+      throw new MemFSError('EISDIR', 'read')
+    }
+    if (!file.readable) {
+      throw new MemFSError('EBADF', 'read')
+    }
+    const start = position ?? file.position
+    const n = Math.max(0, Math.min(length, file.node.size - start))
+    buffer.set(file.node.data.subarray(start, start + n), offset)
+    if (position === null) {
+      file.position += n
+    }
+    file.node.atimeMs = Date.now()
+    return n
+  }
+
+  public writeSync(
+    fd: number,
+    buffer: Uint8Array,
+    offset = 0,
+    length = buffer.length - offset,
+    position: number | null = null,
+  ): number {
+    const file = this.file(fd, 'write')
+    if (!file.writable) {
+      throw new MemFSError('EBADF', 'write')
+    }
+    const node = file.node
+    const start = file.append ? node.size : (position ?? file.position)
+    const end = start + length
+    if (end > node.size) {
+      node.resize(end)
+    }
+    node.data.set(buffer.subarray(offset, offset + length), start)
+    node.touch()
+    if (file.append || position === null) {
+      file.position = end
+    }
+    return length
+  }
+
+  // seekSync sets the offset of fd for the next read or write like lseek,
+  // which node:fs has no counterpart for.
+  public seekSync(fd: number, offset: number, whence: number): number {
+    const file = this.file(fd, 'lseek')
+    let base: number
+    switch (whence) {
+      case 0:
+        base = 0
+        break
+      case 1:
+        base = file.position
+        break
+      case 2:
+        base = file.node.size
+        break
+      default:
+        throw new MemFSError('EINVAL', 'lseek')
+    }
+    if (base + offset < 0) {
+      throw new MemFSError('EINVAL', 'lseek')
+    }
+    file.position = base + offset
+    return file.position
+  }
+
+  public fstatSync(fd: number): memStats {
+    return new memStats(this.file(fd, 'fstat').node)
+  }
+
+  public fsyncSync(fd: number): void {
+    this.file(fd, 'fsync')
+  }
+
+  public ftruncateSync(fd: number, len = 0): void {
+    const file = this.file(fd, 'ftruncate')
+    if (!file.writable || file.node.type !== S_IFREG) {
+      throw new MemFSError('EINVAL', 'ftruncate')
+    }
+    file.node.resize(len)
+    file.node.touch()
+  }
+
+  public statSync(path: string): memStats {
+    return new memStats(this.existing(path, 'stat', true))
+  }
+
+  public lstatSync(path: string): memStats {
+    return new memStats(this.existing(path, 'lstat', false))
+  }
+
+  public chmodSync(path: string, mode: number): void {
+    const node = this.existing(path, 'chmod', true)
+    node.mode = node.type | (mode & 0o7777)
+    node.ctimeMs = Date.now()
+  }
+
+  public chownSync(path: string, uid: number, gid: number): void {
+    this.chown(this.existing(path, 'chown', true), uid, gid)
+  }
+
+  public lchownSync(path: string, uid: number, gid: number): void {
+    this.chown(this.existing(path, 'lchown', false), uid, gid)
+  }
+
+  public utimesSync(
+    path: string,
+    atime: Date | number | string,
+    mtime: Date | number | string,
+  ): void {
+    const node = this.existing(path, 'utime', true)
+    node.atimeMs = timeMs(atime)
+    node.mtimeMs = timeMs(mtime)
+    node.ctimeMs = Date.now()
+  }
+
+  public mkdirSync(
+    path: string,
+    options?: number | { mode?: number; recursive?: boolean },
+  ): string | undefined {
+    const mode =
+      (typeof options === 'number' ? options : options?.mode) ?? 0o777
+    if (typeof options === 'object' && options.recursive) {
+      return this.mkdirAll(path, mode)
+    }
+    const entry = this.lookup(path, 'mkdir', false)
+    if (entry.node !== undefined) {
+      throw new MemFSError('EEXIST', 'mkdir', path)
+    }
+    this.link(entry, new memNode(S_IFDIR | (mode & 0o7777)))
+    return undefined
+  }
+
+  public readdirSync(
+    path: string,
+    options?: { withFileTypes?: boolean },
+  ): any[] {
+    const node = this.existing(path, 'scandir', true)
+    if (node.type !== S_IFDIR) {
+      throw new MemFSError('ENOTDIR', 'scandir', path)
+    }
+    const names = Array.from(node.entries.keys()).sort()
+    if (!options?.withFileTypes) {
+      return names
+    }
+    return names.map(
+      (name) => new memDirent(name, node.entries.get(name)!.mode),
+    )
+  }
+
+  public readFileSync(path: string): Uint8Array {
+    const node = this.existing(path, 'open', true)
+    if (node.type === S_IFDIR) {
+      throw new MemFSError('EISDIR', 'read')
+    }
+    node.atimeMs = Date.now()
+    return node.data.slice(0, node.size)
+  }
+
+  public writeFileSync(
+    path: string,
+    data: Uint8Array | string,
+    options?: { mode?: number } | string,
+  ): void {
+    const bytes =
+      typeof data === 'string' ? new TextEncoder().encode(data) : data
+    const fd = this.openSync(
+      path,
+      'w',
+      typeof options === 'object' ? options.mode : undefined,
+    )
+    try {
+      this.writeSync(fd, bytes)
+    } finally {
+      this.closeSync(fd)
+    }
+  }
+
+  public truncateSync(path: string, len = 0): void {
+    const node = this.existing(path, 'open', true)
+    if (node.type === S_IFDIR) {
+      throw new MemFSError('EISDIR', 'open', path)
+    }
+    node.resize(len)
+    node.touch()
+  }
+
+  public readlinkSync(path: string): string {
+    const node = this.existing(path, 'readlink', false)
+    if (node.type !== S_IFLNK) {
+      throw new MemFSError('EINVAL', 'readlink', path)
+    }
+    return node.target
+  }
+
+  public symlinkSync(target: string, path: string): void {
+    const entry = this.lookup(path, 'symlink', false)
+    if (entry.node !== undefined) {
+      throw new MemFSError('EEXIST', 'symlink', target, path)
+    }
+    const node = new memNode(S_IFLNK | 0o777)
+    node.target = target
+    this.link(entry, node)
+  }
+
+  public linkSync(existingPath: string, newPath: string): void {
+    const node = this.lookup(existingPath, 'link', false).node
+    if (node === undefined) {
+      throw new MemFSError('ENOENT', 'link', existingPath, newPath)
+    }
+    if (node.type === S_IFDIR) {
+      throw new MemFSError('EPERM', 'link', existingPath, newPath)
+    }
+    const entry = this.lookup(newPath, 'link', false)
+    if (entry.node !== undefined) {
+      throw new MemFSError('EEXIST', 'link', existingPath, newPath)
+    }
+    node.nlink++
+    node.ctimeMs = Date.now()
+    this.link(entry, node)
+  }
+
+  public renameSync(oldPath: string, newPath: string): void {
+    const from = this.lookup(oldPath, 'rename', false)
+    const node = from.node
+    if (node === undefined) {
+      throw new MemFSError('ENOENT', 'rename', oldPath, newPath)
+    }
+    const to = this.lookup(newPath, 'rename', false)
+    if (from.name === '' || to.name === '') {
+      throw new MemFSError('EBUSY', 'rename', oldPath, newPath)
+    }
+    if (to.node === node) {
+      return
+    }
+    if (node.type === S_IFDIR) {
+      if (to.dirs.includes(node)) {
+        throw new MemFSError('EINVAL', 'rename', oldPath, newPath)
+      }
+      if (to.node !== undefined && to.node.type !== S_IFDIR) {
+        throw new MemFSError('ENOTDIR', 'rename', oldPath, newPath)
+      }
+      if (to.node !== undefined && to.node.entries.size !== 0) {
+        throw new MemFSError('ENOTEMPTY', 'rename', oldPath, newPath)
+      }
+    } else if (to.node?.type === S_IFDIR) {
+      throw new MemFSError('EISDIR', 'rename', oldPath, newPath)
+    }
+    if (to.node !== undefined) {
+      to.node.nlink--
+    }
+    from.parent.entries.delete(from.name)
+    from.parent.touch()
+    this.link(to, node)
+  }
+
+  public unlinkSync(path: string): void {
+    const entry = this.lookup(path, 'unlink', false)
+    if (entry.node === undefined) {
+      throw new MemFSError('ENOENT', 'unlink', path)
+    }
+    if (entry.node.type === S_IFDIR) {
+      throw new MemFSError('EISDIR', 'unlink', path)
+    }
+    this.unlink(entry)
+  }
+
+  public rmdirSync(path: string): void {
+    const entry = this.lookup(path, 'rmdir', false)
+    if (entry.node === undefined) {
+      throw new MemFSError('ENOENT', 'rmdir', path)
+    }
+    if (entry.node.type !== S_IFDIR) {
+      throw new MemFSError('ENOTDIR', 'rmdir', path)
+    }
+    if (entry.name === '') {
+      throw new MemFSError('EBUSY', 'rmdir', path)
+    }
+    if (entry.node.entries.size !== 0) {
+      throw new MemFSError('ENOTEMPTY', 'rmdir', path)
+    }
+    this.unlink(entry)
+  }
+
+  public rmSync(
+    path: string,
+    options?: { force?: boolean; recursive?: boolean },
+  ): void {
+    const entry = this.lookup(path, 'rm', false)
+    if (entry.node === undefined) {
+      if (options?.force) {
+        return
+      }
+      throw new MemFSError('ENOENT', 'rm', path)
+    }
+    if (entry.node.type === S_IFDIR && !options?.recursive) {
+      throw new MemFSError('EISDIR', 'rm', path)
+    }
+    if (entry.name === '') {
+      throw new MemFSError('EBUSY', 'rm', path)
+    }
+    this.unlink(entry)
+  }
+
+  private file(fd: number, syscall: string): memFile {
+    const file = this.files.get(fd)
+    if (file === undefined) {
+      throw new MemFSError('EBADF', syscall)
+    }
+    return file
+  }
+
+  private existing(path: string, syscall: string, follow: boolean): memNode {
+    const node = this.lookup(path, syscall, follow).node
+    if (node === undefined) {
+      throw new MemFSError('ENOENT', syscall, path)
+    }
+    return node
+  }
+
+  private chown(node: memNode, uid: number, gid: number): void {
+    node.uid = uid
+    node.gid = gid
+    node.ctimeMs = Date.now()
+  }
+
+  private link(entry: memEntry, node: memNode): void {
+    entry.parent.entries.set(entry.name, node)
+    entry.parent.touch()
+  }
+
+  private unlink(entry: memEntry): void {
+    entry.parent.entries.delete(entry.name)
+    entry.parent.touch()
+    entry.node!.nlink--
+  }
+
+  private mkdirAll(path: string, mode: number): string | undefined {
+    const names = splitPath(path)
+    let prefix = path.startsWith('/') ? '/' : ''
+    let first: string | undefined
+    for (let i = 0; i < names.length; i++) {
+      prefix += (prefix === '' || prefix.endsWith('/') ? '' : '/') + names[i]
+      const entry = this.lookup(prefix, 'mkdir', true)
+      if (entry.node === undefined) {
+        this.link(entry, new memNode(S_IFDIR | (mode & 0o7777)))
+        first ??= entry.path
+      } else if (entry.node.type !== S_IFDIR) {
+        throw new MemFSError(
+          i === names.length - 1 ? 'EEXIST' : 'ENOTDIR',
+          'mkdir',
+          path,
+        )
+      }
+    }
+    return first
+  }
+
+  private absolute(path: string): string {
+    if (path.startsWith('/')) {
+      return path
+    }
+    return this.workDir === '/' ? '/' + path : this.workDir + '/' + path
+  }
+
+  // lookup resolves path, following the symbolic links on the way. follow
+  // also follows a symbolic link in the last element.
+  private lookup(path: string, syscall: string, follow: boolean): memEntry {
+    if (path === '') {
+      throw new MemFSError('ENOENT', syscall, path)
+    }
+    const queue = splitPath(this.absolute(path))
+    const dirs: memNode[] = [this.root]
+    const names: string[] = []
+    let links = 0
+    const mustBeDir = path.endsWith('/') || /(^|\/)\.\.?$/.test(path)
+    while (queue.length > 0) {
+      const name = queue.shift()!
+      const dir = dirs[dirs.length - 1]
+      if (name === '..') {
+        if (dirs.length > 1) {
+          dirs.pop()
+          names.pop()
+        }
+        continue
+      }
+      const node = dir.entries.get(name)
+      const last = queue.length === 0
+      if (node?.type === S_IFLNK && (!last || follow || mustBeDir)) {
+        if (++links > maxSymlinks) {
+          throw new MemFSError('ELOOP', syscall, path)
+        }
+        if (node.target.startsWith('/')) {
+          dirs.splice(1)
+          names.splice(0)
+        }
+        queue.unshift(...splitPath(node.target))
+        continue
+      }
+      if (last) {
+        if (node !== undefined && mustBeDir && node.type !== S_IFDIR) {
+          throw new MemFSError('ENOTDIR', syscall, path)
+        }
+        return {
+          parent: dir,
+          name,
+          node,
+          dirs,
+          path: joinNames([...names, name]),
+        }
+      }
+      if (node === undefined) {
+        throw new MemFSError('ENOENT', syscall, path)
+      }
+      if (node.type !== S_IFDIR) {
+        throw new MemFSError('ENOTDIR', syscall, path)
+      }
+      dirs.push(node)
+      names.push(name)
+    }
+    const node = dirs.pop()!
+    return {
+      parent: dirs[dirs.length - 1] ?? node,
+      name: '',
+      node,
+      dirs,
+      path: joinNames(names),
+    }
+  }
+}
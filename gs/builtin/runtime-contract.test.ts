@@ -14,6 +14,7 @@ import {
   chanRecvWithOk,
   fieldRef,
   functionValue,
+  genericInterfaceValue,
   genericZero,
   goSlice,
   int,
@@ -859,6 +860,35 @@ describe('builtin runtime contract helpers', () => {
     }
     expect(genericZero(genericArgs, 'T', null)).toBe(0)
     expect(callGenericMethod(genericArgs, 'T', 'String', 12)).toBe('12')
+
+    const namedArgs = {
+      T: {
+        type: { kind: TypeKind.Basic, name: 'int', typeName: 'main.IntVal' },
+        zero: () => 0,
+        methods: {
+          String: (value: number) => `#${value}`,
+        },
+        methodSignatures: [{ name: 'String', args: [], returns: [] }],
+      },
+    }
+    const boxed = genericInterfaceValue<any>(namedArgs, 'T', 7)
+    expect(boxed.__goType).toBe('main.IntVal')
+    expect(boxed.String()).toBe('#7')
+    const plain = genericInterfaceValue<any>(
+      { T: { type: { kind: TypeKind.Basic, name: 'int' } } },
+      'T',
+      3,
+    )
+    expect(plain.__goType).toBe('int')
+    expect(plain.valueOf()).toBe(3)
+    const iface = { String: () => 'x' }
+    expect(
+      genericInterfaceValue(
+        { T: { type: { kind: TypeKind.Interface, methods: [] } } },
+        'T',
+        iface,
+      ),
+    ).toBe(iface)
   })
 
   it('compares anonymous descriptors inside function assertions', () => {
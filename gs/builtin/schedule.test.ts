@@ -0,0 +1,52 @@
+import { afterEach, describe, expect, it } from 'vitest'
+
+import { go } from './goroutine.js'
+import { preempt, preemptDue, setPreemptBudget } from './schedule.js'
+
+describe('loop preemption', () => {
+  afterEach(() => {
+    setPreemptBudget(10)
+  })
+
+  it('stays quiet while the time slice lasts', () => {
+    setPreemptBudget(Number.POSITIVE_INFINITY)
+    for (let i = 0; i < 10_000; i++) {
+      expect(preemptDue()).toBe(false)
+    }
+  })
+
+  it('lets a spinning goroutine yield to other goroutines', async () => {
+    setPreemptBudget(0)
+    await preempt()
+    let ran = false
+    queueMicrotask(() => {
+      ran = true
+    })
+    for (;;) {
+      if (preemptDue()) {
+        await preempt()
+        break
+      }
+    }
+    expect(ran).toBe(true)
+  })
+
+  it('starts each goroutine with a fresh time slice', async () => {
+    setPreemptBudget(50)
+    const spinUntil = performance.now() + 60
+    while (performance.now() < spinUntil) {
+      // Use up the creator's time slice without yielding.
+    }
+    let due = true
+    await new Promise<void>((resolve) => {
+      go(() => {
+        due = false
+        for (let i = 0; i < 2048; i++) {
+          due ||= preemptDue()
+        }
+        resolve()
+      })
+    })
+    expect(due).toBe(false)
+  })
+})
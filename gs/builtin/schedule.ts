@@ -0,0 +1,61 @@
+import { currentGoroutineInfo, resumeGoroutine } from './goroutine.js'
+
+// Cooperative loop preemption for code compiled with --preempt-loops.
+//
+// JavaScript only switches goroutines at await points, so a goroutine spinning
+// in a loop without channel operations starves every other goroutine. Under
+// --preempt-loops the compiler starts each loop body in an async function with
+//
+//   if ($.preemptDue()) await $.preempt()
+//
+// preemptDue is the cheap half: it bumps a counter and only reads the clock
+// every preemptCheckInterval calls. Once the current time slice has run longer
+// than the budget, preempt yields through a macrotask so timers, I/O, and other
+// goroutines get a turn before the loop continues.
+
+const preemptCheckInterval = 1024
+
+let preemptBudgetMs = 10
+let preemptCounter = 0
+let preemptSliceStart = preemptNow()
+
+function preemptNow(): number {
+  return typeof performance !== 'undefined' ? performance.now() : Date.now()
+}
+
+// preemptDue reports whether the running goroutine has used up its time slice.
+export function preemptDue(): boolean {
+  if (++preemptCounter < preemptCheckInterval) {
+    return false
+  }
+  preemptCounter = 0
+  return preemptNow() - preemptSliceStart >= preemptBudgetMs
+}
+
+// startPreemptSlice starts a new time slice for the goroutine about to run. go
+// calls it as each goroutine starts so a new goroutine does not inherit the
+// time its creator already used.
+export function startPreemptSlice(): void {
+  preemptCounter = 0
+  preemptSliceStart = preemptNow()
+}
+
+// preempt yields to the host event loop and starts a new time slice.
+export function preempt(): Promise<void> {
+  const g = currentGoroutineInfo()
+  return new Promise((resolve) => {
+    setTimeout(() => {
+      startPreemptSlice()
+      resumeGoroutine(g)
+      resolve()
+    }, 0)
+  })
+}
+
+// setPreemptBudget sets the time slice in milliseconds a loop may run before it
+// yields. It returns the previous budget.
+export function setPreemptBudget(ms: number): number {
+  const previous = preemptBudgetMs
+  preemptBudgetMs = ms
+  return previous
+}
@@ -0,0 +1,70 @@
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs'
+import { tmpdir } from 'node:os'
+import { join } from 'node:path'
+import { afterEach, describe, expect, it } from 'vitest'
+
+import { formatUncaughtPanic } from './goroutine.js'
+import { GoPanic } from './panic.js'
+import { formatGoFrames, goFrames } from './traceback.js'
+
+const tempRoots: string[] = []
+
+afterEach(() => {
+  for (const root of tempRoots.splice(0)) {
+    rmSync(root, { force: true, recursive: true })
+  }
+})
+
+// writeGeneratedPackage writes run.gs.ts with a source map whose generated
+// lines 1-3 map to run.go lines 1-3, and whose lines 2-3 belong to Run.
+function writeGeneratedPackage(): string {
+  const root = mkdtempSync(join(tmpdir(), 'goscript-traceback-'))
+  tempRoots.push(root)
+  const dir = join(root, 'pkg')
+  mkdirSync(dir)
+  writeFileSync(join(dir, 'run.gs.ts'), '')
+  writeFileSync(
+    join(dir, 'run.gs.ts.map'),
+    JSON.stringify({
+      version: 3,
+      file: 'run.gs.ts',
+      sources: ['../src/run.go'],
+      names: [],
+      mappings: 'AAAA;AACA;AACA',
+      x_goscript_functions: [[1, 3, 'example.test/pkg.Run']],
+    }),
+  )
+  return root
+}
+
+describe('goFrames', () => {
+  it('maps generated frames to Go functions and lines', () => {
+    const root = writeGeneratedPackage()
+    const stack = [
+      'Error: boom',
+      `    at Run (file://${root}/pkg/run.gs.ts:3:5)`,
+      `    at helper (${root}/node_modules/lib/index.js:10:1)`,
+      `    at main (${root}/main/main.gs.ts:7:3)`,
+    ].join('\n')
+
+    const frames = goFrames(stack)
+
+    expect(frames).toEqual([
+      { function: 'example.test/pkg.Run', file: `${root}/src/run.go`, line: 3 },
+      { function: 'main', file: `${root}/main/main.gs.ts`, line: 7 },
+    ])
+    expect(formatGoFrames(frames)).toBe(
+      `example.test/pkg.Run(...)\n\t${root}/src/run.go:3\nmain(...)\n\t${root}/main/main.gs.ts:7`,
+    )
+  })
+
+  it('formats uncaught panics as a goroutine traceback', () => {
+    const root = writeGeneratedPackage()
+    const err = new GoPanic('boom')
+    err.stack = `Error: panic: boom\n    at Run (${root}/pkg/run.gs.ts:2:1)`
+
+    expect(formatUncaughtPanic(err)).toBe(
+      `panic: boom\n\ngoroutine 1 [running]:\nexample.test/pkg.Run(...)\n\t${root}/src/run.go:2`,
+    )
+  })
+})
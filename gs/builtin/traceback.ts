@@ -0,0 +1,226 @@
+import { getHostRuntime } from './hostio.js'
+
+// Go stack frames from JavaScript stacks.
+//
+// Under --source-maps every generated .gs.ts file has a .gs.ts.map beside it.
+// Its mappings lead each generated line back to a Go file and line, and its
+// x_goscript_functions extension names the Go function that produced each
+// generated line range, such as main.main or example.com/pkg.(*T).Serve.
+// goFrames parses a JavaScript stack, loads the map of every generated file
+// it names, and keeps only frames of generated code. Frames whose file has no
+// map, or hosts that cannot read files synchronously, keep the JavaScript
+// function name and the generated file and line.
+
+// GoFrame is one stack frame of compiled Go code.
+export interface GoFrame {
+  function: string
+  file: string
+  line: number
+}
+
+interface GeneratedSourceMap {
+  // lines holds [generatedColumn, sourceIndex, goLine] segments for each
+  // zero-based generated line.
+  lines: Array<Array<[number, number, number]>>
+  sources: string[]
+  functions: Array<[number, number, string]>
+}
+
+const sourceMapCache = new Map<string, GeneratedSourceMap | null>()
+
+const v8FramePattern = /^\s*at (?:async )?(?:(.*?) \()?(.+?):(\d+):(\d+)\)?$/
+const geckoFramePattern = /^\s*(.*?)@(.+?):(\d+):(\d+)$/
+const generatedFilePattern = /\.gs\.[jt]s$/
+
+// goFrames returns the frames of generated Go code in a JavaScript stack,
+// innermost first.
+export function goFrames(stack: string | undefined): GoFrame[] {
+  const frames: GoFrame[] = []
+  for (const text of (stack ?? '').split('\n')) {
+    const match = v8FramePattern.exec(text) ?? geckoFramePattern.exec(text)
+    if (!match) {
+      continue
+    }
+    const file = filePathFromStackLocation(match[2])
+    if (!generatedFilePattern.test(file)) {
+      continue
+    }
+    frames.push(
+      mapGeneratedFrame(
+        match[1] ?? '',
+        file,
+        Number(match[3]),
+        Number(match[4]),
+      ),
+    )
+  }
+  return frames
+}
+
+// callerFrames returns the Go frames of the current JavaScript stack. Runtime
+// frames are not generated code, so the first frame is the Go function that
+// called into the runtime.
+export function callerFrames(): GoFrame[] {
+  const errorCtor = Error as { stackTraceLimit?: number }
+  const limit = errorCtor.stackTraceLimit
+  if (typeof limit === 'number' && limit < 100) {
+    errorCtor.stackTraceLimit = 100
+  }
+  try {
+    return goFrames(new Error().stack)
+  } finally {
+    if (typeof limit === 'number') {
+      errorCtor.stackTraceLimit = limit
+    }
+  }
+}
+
+// formatGoFrames formats frames the way a Go traceback lists them under a
+// goroutine header.
+export function formatGoFrames(frames: GoFrame[]): string {
+  return frames
+    .map((frame) => `${frame.function}(...)\n\t${frame.file}:${frame.line}`)
+    .join('\n')
+}
+
+function mapGeneratedFrame(
+  jsName: string,
+  file: string,
+  line: number,
+  column: number,
+): GoFrame {
+  const fallback = { function: jsName || '?', file, line }
+  const sourceMap = loadGeneratedSourceMap(file)
+  if (!sourceMap) {
+    return fallback
+  }
+  const generatedLine = line - 1
+  let fn = ''
+  let span = Number.POSITIVE_INFINITY
+  for (const [start, end, name] of sourceMap.functions) {
+    if (start <= generatedLine && generatedLine < end && end - start < span) {
+      fn = name
+      span = end - start
+    }
+  }
+  const segments = sourceMap.lines[generatedLine]
+  if (!segments || segments.length === 0) {
+    return { ...fallback, function: fn || fallback.function }
+  }
+  let segment = segments[0]
+  for (const candidate of segments) {
+    if (candidate[0] <= column - 1) {
+      segment = candidate
+    }
+  }
+  return {
+    function: fn || fallback.function,
+    file: resolveSourcePath(file, sourceMap.sources[segment[1]] ?? ''),
+    line: segment[2] + 1,
+  }
+}
+
+function loadGeneratedSourceMap(file: string): GeneratedSourceMap | null {
+  const cached = sourceMapCache.get(file)
+  if (cached !== undefined) {
+    return cached
+  }
+  let loaded: GeneratedSourceMap | null = null
+  const nodeFS = getHostRuntime().nodeFS
+  if (nodeFS?.readFileSync) {
+    try {
+      const raw = JSON.parse(
+        new TextDecoder().decode(nodeFS.readFileSync(file + '.map')),
+      ) as {
+        sources?: string[]
+        mappings?: string
+        x_goscript_functions?: Array<[number, number, string]>
+      }
+      loaded = {
+        lines: decodeMappings(raw.mappings ?? ''),
+        sources: raw.sources ?? [],
+        functions: raw.x_goscript_functions ?? [],
+      }
+    } catch {
+      loaded = null
+    }
+  }
+  sourceMapCache.set(file, loaded)
+  return loaded
+}
+
+const base64Digits =
+  'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/'
+
+function decodeMappings(
+  mappings: string,
+): Array<Array<[number, number, number]>> {
+  const lines: Array<Array<[number, number, number]>> = []
+  let source = 0
+  let goLine = 0
+  for (const lineText of mappings.split(';')) {
+    const segments: Array<[number, number, number]> = []
+    let column = 0
+    for (const segmentText of lineText.split(',')) {
+      if (segmentText === '') {
+        continue
+      }
+      const fields = decodeVLQ(segmentText)
+      column += fields[0]
+      if (fields.length >= 4) {
+        source += fields[1]
+        goLine += fields[2]
+        segments.push([column, source, goLine])
+      }
+    }
+    lines.push(segments)
+  }
+  return lines
+}
+
+function decodeVLQ(text: string): number[] {
+  const values: number[] = []
+  let value = 0
+  let shift = 0
+  for (const char of text) {
+    const digit = base64Digits.indexOf(char)
+    value += (digit & 0x1f) << shift
+    if (digit & 0x20) {
+      shift += 5
+      continue
+    }
+    values.push(value & 1 ? -(value >>> 1) : value >>> 1)
+    value = 0
+    shift = 0
+  }
+  return values
+}
+
+function filePathFromStackLocation(location: string): string {
+  if (!location.startsWith('file://')) {
+    return location
+  }
+  try {
+    return decodeURIComponent(new URL(location).pathname)
+  } catch {
+    return location.slice('file://'.length)
+  }
+}
+
+// resolveSourcePath resolves a map source, relative to the generated file's
+// directory, to the Go file path.
+function resolveSourcePath(generatedFile: string, source: string): string {
+  if (source.startsWith('/')) {
+    return source
+  }
+  const parts = generatedFile.split('/')
+  parts.pop()
+  for (const part of source.split('/')) {
+    if (part === '..') {
+      parts.pop()
+    } else if (part !== '.' && part !== '') {
+      parts.push(part)
+    }
+  }
+  return parts.join('/')
+}
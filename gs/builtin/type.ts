@@ -1416,6 +1416,10 @@ export function typeAssert<T>(
       }
       return { value: null as T, ok: false }
     }
+    // Nil maps, slices, channels and funcs keep their dynamic type too.
+    if (goTypeMatchesTypeInfo(value.__goType, normalizedType)) {
+      return { value: null as T, ok: true }
+    }
     return { value: null as T, ok: false }
   }
 
@@ -1566,7 +1570,7 @@ export function is(value: any, typeInfo: string | TypeInfo): boolean {
  * Each case matches against one or more types and contains a body function to execute when matched.
  */
 export interface TypeSwitchCase {
-  types: (string | TypeInfo)[] // Array of types for this case (e.g., case int, string:)
+  types: (string | TypeInfo | null)[] // Array of types for this case (e.g., case int, string:); null is case nil
   body: (value?: any) => any // Function representing the case body. 'value' is the asserted value if applicable.
 }
 
@@ -1586,7 +1590,11 @@ export function typeSwitch(
   for (const caseObj of cases) {
     // For cases with multiple types (case T1, T2:), use $.is
     if (caseObj.types.length > 1) {
-      const matchesAny = caseObj.types.some((typeInfo) => is(value, typeInfo))
+      const matchesAny = caseObj.types.some((typeInfo) =>
+        typeInfo === null ? value === null || value === undefined : (
+          is(value, typeInfo)
+        ),
+      )
       if (matchesAny) {
         // For multi-type cases, the case variable (if any) gets the original value
         return caseObj.body(value)
@@ -1594,6 +1602,13 @@ export function typeSwitch(
     } else if (caseObj.types.length === 1) {
       // For single-type cases (case T:), use $.typeAssert to get the typed value and ok status
       const typeInfo = caseObj.types[0]
+      if (typeInfo === null) {
+        // case nil matches only a nil interface, never a typed nil
+        if (value === null || value === undefined) {
+          return caseObj.body(null)
+        }
+        continue
+      }
       const { value: assertedValue, ok } = typeAssert(value, typeInfo)
       if (ok) {
         // Pass the asserted value to the case body function
@@ -1614,36 +1629,25 @@ export function typeSwitch(
  * This is used for type conversions like (*Interface)(nil) where we need
  * to preserve the pointer type information even though the value is null.
  *
+ * For pointers to registered struct types the typed nil also carries the
+ * type's methods bound to a nil receiver, so calls through an interface
+ * holding a nil *T dispatch to T's methods like they do in Go.
+ *
  * @param typeName The full Go type name (e.g., "*main.Stringer")
  * @returns An object that represents a typed nil with reflection metadata
  */
 export function typedNil(typeName: string): any {
-  return Object.assign(Object.create(null), {
+  const nilValue = Object.assign(Object.create(null), {
     __goType: typeName,
     __isTypedNil: true,
   })
-}
-
-export function interfaceValue<T>(value: unknown, typeName: string): T {
-  if (value !== null && value !== undefined) {
-    if (typeof value === 'object') {
-      Object.defineProperty(value, '__goType', {
-        value: typeName,
-        writable: true,
-        configurable: true,
-      })
-    }
-    return value as T
-  }
-
-  const nilValue = typedNil(typeName)
   if (!typeName.startsWith('*')) {
-    return nilValue as T
+    return nilValue
   }
 
   const dynamicType = typeRegistry.get(typeName.slice(1))
   if (!dynamicType || !isStructTypeInfo(dynamicType) || !dynamicType.ctor) {
-    return nilValue as T
+    return nilValue
   }
 
   const prototype = dynamicType.ctor.prototype as Record<string, unknown>
@@ -1657,7 +1661,21 @@ export function interfaceValue<T>(value: unknown, typeName: string): T {
       enumerable: true,
     })
   }
-  return nilValue as T
+  return nilValue
+}
+
+export function interfaceValue<T>(value: unknown, typeName: string): T {
+  if (value !== null && value !== undefined) {
+    if (typeof value === 'object') {
+      Object.defineProperty(value, '__goType', {
+        value: typeName,
+        writable: true,
+        configurable: true,
+      })
+    }
+    return value as T
+  }
+  return typedNil(typeName) as T
 }
 
 export function namedValueInterfaceValue<T>(
@@ -1757,6 +1775,46 @@ export function genericZero<T>(
   return fallback
 }
 
+/**
+ * Converts a type-parameter value to an interface value using the dynamic
+ * type recorded in its type-argument dictionary. Named types with methods are
+ * boxed with their method table, struct and pointer values are tagged with
+ * their type name, and values that already are interfaces pass through.
+ */
+export function genericInterfaceValue<T>(
+  typeArgs: GenericTypeArgs | undefined,
+  name: string,
+  value: unknown,
+): T {
+  const descriptor = typeArgs?.[name]
+  if (!descriptor?.type) {
+    return value as T
+  }
+  const info = normalizeTypeInfo(descriptor.type)
+  if (isInterfaceTypeInfo(info)) {
+    return value as T
+  }
+  const typeName =
+    typeof descriptor.type === 'string' ?
+      descriptor.type
+    : typeInfoRuntimeName(descriptor.type)
+  if (descriptor.methodSignatures || (isBasicTypeInfo(info) && !info.typeName)) {
+    return namedValueInterfaceValue<T>(
+      value,
+      typeName ?? info.name ?? '',
+      descriptor.methods ?? {},
+      descriptor.type,
+    )
+  }
+  if (isStructTypeInfo(info) && typeName) {
+    return interfaceValue<T>(value, typeName)
+  }
+  if (isPointerTypeInfo(info) && typeof info.elemType === 'string') {
+    return interfaceValue<T>(value, '*' + info.elemType)
+  }
+  return value as T
+}
+
 export function callGenericMethod<T>(
   typeArgs: GenericTypeArgs | undefined,
   name: string,
@@ -197,7 +197,7 @@ class cancelContext extends baseContext {
 // Timer context with deadline
 class timerContext extends cancelContext {
   private deadline: time.Time
-  private timer: any
+  private timer: $.HostTimer | null = null
 
   constructor(parent: ContextNonNil, deadline: time.Time) {
     super(parent)
@@ -217,7 +217,7 @@ class timerContext extends cancelContext {
       return
     }
 
-    this.timer = setTimeout(() => {
+    this.timer = $.hostTimeout(() => {
       this.cancel(true, DeadlineExceeded, null)
     }, duration)
   }
@@ -225,7 +225,7 @@ class timerContext extends cancelContext {
   cancel(removeFromParent: boolean, err: $.GoError, cause: $.GoError): void {
     super.cancel(removeFromParent, err, cause)
     if (this.timer) {
-      clearTimeout(this.timer)
+      this.timer.cancel()
       this.timer = null
     }
   }
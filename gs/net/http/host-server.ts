@@ -0,0 +1,247 @@
+import { getHostRuntime } from '@goscript/builtin/index.js'
+
+// HostRequest is a request the host HTTP server received.
+export type HostRequest = {
+  method: string
+  // target is the request target of the request line, such as /a?b=c.
+  target: string
+  protoMajor: number
+  protoMinor: number
+  headers: [string, string][]
+  // body is null when the request has none.
+  body: ReadableStream<Uint8Array> | null
+  remoteAddr: string
+  // signal aborts once the client goes away.
+  signal: AbortSignal
+}
+
+// HostResponse is the response to a HostRequest. A stream body is sent as it
+// is written.
+export type HostResponse = {
+  status: number
+  headers: [string, string][]
+  body: Uint8Array | ReadableStream<Uint8Array> | null
+}
+
+export type HostHandler = (req: HostRequest) => Promise<HostResponse>
+
+export type HostListenOptions = {
+  // hostname is empty to listen on every interface.
+  hostname: string
+  port: number
+  tls: { cert: string; key: string } | null
+  // idleTimeout closes idle keep-alive connections after that many
+  // milliseconds, or never when it is zero.
+  idleTimeout: number
+}
+
+// HostServer is a listening host HTTP server.
+export interface HostServer {
+  // addr is the host and port the server listens on.
+  readonly addr: string
+  // stop stops accepting connections and closes the idle ones. force closes
+  // the active connections as well.
+  stop(force: boolean): void
+}
+
+// HostServerUnsupportedError reports a host without an HTTP server API, such
+// as a browser.
+export class HostServerUnsupportedError extends Error {}
+
+// listenHost starts a host HTTP server that hands each request to handler.
+// It uses Bun.serve on Bun and node:http or node:https on Node.
+export async function listenHost(
+  options: HostListenOptions,
+  handler: HostHandler,
+): Promise<HostServer> {
+  const bun = (globalThis as { Bun?: { serve?: unknown } }).Bun
+  if (typeof bun?.serve === 'function') {
+    return listenBun(bun, options, handler)
+  }
+  const processObj = getHostRuntime().processObj
+  if (typeof processObj?.getBuiltinModule === 'function') {
+    return listenNode(
+      processObj.getBuiltinModule(options.tls ? 'node:https' : 'node:http'),
+      options,
+      handler,
+    )
+  }
+  throw new HostServerUnsupportedError(
+    'net/http: serving HTTP is not supported on this host',
+  )
+}
+
+function listenBun(
+  bun: any,
+  options: HostListenOptions,
+  handler: HostHandler,
+): HostServer {
+  const server = bun.serve({
+    // Without a hostname Bun listens on every interface, IPv6 included.
+    hostname: options.hostname === '' ? undefined : options.hostname,
+    port: options.port,
+    // Bun counts seconds, up to 255, and closes idle connections after 10
+    // by default, which would cut long streaming responses.
+    idleTimeout: Math.min(Math.ceil(options.idleTimeout / 1000), 255),
+    tls: options.tls ?? undefined,
+    async fetch(req: Request, srv: any): Promise<Response> {
+      const url = new URL(req.url)
+      const ip = srv.requestIP(req)
+      const headers: [string, string][] = []
+      req.headers.forEach((value, key) => headers.push([key, value]))
+      const resp = await handler({
+        method: req.method,
+        target: url.pathname + url.search,
+        protoMajor: 1,
+        protoMinor: 1,
+        headers,
+        body: req.body,
+        remoteAddr: ip == null ? '' : joinHostPort(ip.address, ip.port),
+        signal: req.signal,
+      })
+      const respHeaders = new Headers()
+      for (const [key, value] of resp.headers) {
+        respHeaders.append(key, value)
+      }
+      return new Response(resp.body as BodyInit | null, {
+        status: resp.status,
+        headers: respHeaders,
+      })
+    },
+  })
+  return {
+    addr: joinHostPort(server.hostname, server.port),
+    stop: (force) => {
+      void server.stop(force)
+    },
+  }
+}
+
+function listenNode(
+  http: any,
+  options: HostListenOptions,
+  handler: HostHandler,
+): Promise<HostServer> {
+  const server = http.createServer(
+    options.tls ?? {},
+    (req: any, res: any) => void serveNode(req, res, handler),
+  )
+  server.keepAliveTimeout = options.idleTimeout
+  return new Promise((resolve, reject) => {
+    server.once('error', reject)
+    const onListening = () => {
+      server.off('error', reject)
+      const addr = server.address()
+      resolve({
+        addr: joinHostPort(addr.address, addr.port),
+        stop: (force) => {
+          server.close()
+          server.closeIdleConnections?.()
+          if (force) {
+            server.closeAllConnections?.()
+          }
+        },
+      })
+    }
+    if (options.hostname === '') {
+      server.listen(options.port, onListening)
+    } else {
+      server.listen(options.port, options.hostname, onListening)
+    }
+  })
+}
+
+async function serveNode(
+  req: any,
+  res: any,
+  handler: HostHandler,
+): Promise<void> {
+  const controller = new AbortController()
+  res.on('close', () => controller.abort())
+  const headers: [string, string][] = []
+  const raw: string[] = req.rawHeaders
+  for (let i = 0; i + 1 < raw.length; i += 2) {
+    headers.push([raw[i], raw[i + 1]])
+  }
+  const hasBody =
+    req.headers['content-length'] !== undefined ||
+    req.headers['transfer-encoding'] !== undefined
+  let resp: HostResponse
+  try {
+    resp = await handler({
+      method: req.method,
+      target: req.url,
+      protoMajor: req.httpVersionMajor,
+      protoMinor: req.httpVersionMinor,
+      headers,
+      body: hasBody ? nodeReadableStream(req) : null,
+      remoteAddr: joinHostPort(
+        req.socket.remoteAddress ?? '',
+        req.socket.remotePort ?? 0,
+      ),
+      signal: controller.signal,
+    })
+  } catch {
+    // Like a Go handler that panics, drop the connection without a response.
+    res.destroy()
+    return
+  }
+  const grouped = new Map<string, string[]>()
+  for (const [key, value] of resp.headers) {
+    grouped.set(key, [...(grouped.get(key) ?? []), value])
+  }
+  for (const [key, values] of grouped) {
+    res.setHeader(key, values.length === 1 ? values[0] : values)
+  }
+  res.statusCode = resp.status
+  if (!(resp.body instanceof ReadableStream)) {
+    res.end(resp.body ?? undefined)
+    return
+  }
+  res.flushHeaders()
+  const reader = resp.body.getReader()
+  try {
+    for (;;) {
+      const { value, done } = await reader.read()
+      if (done) {
+        break
+      }
+      if (!res.write(value)) {
+        await new Promise((resolve) => res.once('drain', resolve))
+      }
+    }
+    res.end()
+  } catch {
+    res.destroy()
+  }
+}
+
+// nodeReadableStream reads the body of the node:http request req.
+function nodeReadableStream(req: any): ReadableStream<Uint8Array> {
+  const chunks: AsyncIterator<Uint8Array> = req[Symbol.asyncIterator]()
+  return new ReadableStream<Uint8Array>({
+    async pull(controller) {
+      try {
+        const { value, done } = await chunks.next()
+        if (done) {
+          controller.close()
+          return
+        }
+        controller.enqueue(new Uint8Array(value))
+      } catch (err) {
+        controller.error(err)
+      }
+    },
+    cancel() {
+      req.resume()
+    },
+  })
+}
+
+// joinHostPort formats host and port like net.JoinHostPort.
+export function joinHostPort(host: string, port: number | string): string {
+  if (host.includes(':')) {
+    return `[${host}]:${port}`
+  }
+  return `${host}:${port}`
+}
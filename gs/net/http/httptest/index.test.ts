@@ -27,23 +27,58 @@ import {
 } from './index.js'
 
 describe('net/http/httptest override', () => {
-  it('exports server helpers for typechecked server tests', () => {
+  it('exports server helpers for typechecked server tests', async () => {
     const handler: Handler = {
       ServeHTTP: () => undefined,
     }
 
-    const srv = NewServer(handler)
+    const srv = await NewServer(handler)
     const tlsSrv = NewTLSServer(handler)
-    expect(srv.URL).toMatch(/^http:\/\/goscript-httptest-\d+\.invalid$/)
+    expect(srv.URL).toMatch(/^http:\/\/127\.0\.0\.1:\d+$/)
+    expect(srv.Listener.Addr().String()).toBe(srv.URL.slice('http://'.length))
     expect(tlsSrv.URL).toMatch(/^https:\/\/goscript-httptest-\d+\.invalid$/)
     expect(srv.Client()).toBeTruthy()
     expect(srv.Config().Handler).toBe(handler)
     expect(tlsSrv.Client()).toBeTruthy()
-    expect(Server_Start(NewUnstartedServer(handler))?.Error()).toBe(
-      'net/http/httptest: Server.Start is not implemented in GoScript',
-    )
+    const unstarted = NewUnstartedServer(handler)
+    expect(unstarted.URL).toBe('')
+    expect(await Server_Start(unstarted)).toBeNull()
+    expect(unstarted.URL).toMatch(/^http:\/\/127\.0\.0\.1:\d+$/)
+    expect(() => unstarted.StartTLS()).toThrow('Server already started')
     srv.Close()
     tlsSrv.Close()
+    unstarted.Close()
+  })
+
+  it('serves NewServer handlers on a local port', async () => {
+    const srv = await NewServer({
+      async ServeHTTP(w, r) {
+        const req = $.pointerValue(r)!
+        expect(req.RequestURI).toBe('/hello?x=1')
+        expect(req.Host).toBe(srv.URL.slice('http://'.length))
+        const [data, err] = await io.ReadAll(req.Body!)
+        expect(err).toBeNull()
+        Header_Set(w!.Header(), 'X-Seen', req.Method)
+        w!.WriteHeader(StatusPartialContent)
+        w!.Write($.stringToBytes('hello ' + $.bytesToString(data)))
+      },
+    })
+    const [req, reqErr] = NewRequest(
+      MethodPost,
+      srv.URL + '/hello?x=1',
+      bytes.NewReader($.stringToBytes('world')),
+    )
+    expect(reqErr).toBeNull()
+
+    const [resp, err] = await srv.Client().Do(req)
+
+    expect(err).toBeNull()
+    expect(resp?.StatusCode).toBe(StatusPartialContent)
+    expect(Header_Get(resp!.Header, 'X-Seen')).toBe(MethodPost)
+    const [data, readErr] = await io.ReadAll(resp!.Body!)
+    expect(readErr).toBeNull()
+    expect($.bytesToString(data)).toBe('hello world')
+    srv.Close()
   })
 
   it('exports request defaults and recorder compatibility helpers', () => {
@@ -91,8 +126,8 @@ describe('net/http/httptest override', () => {
     expect(Buffer.from(recorder.Body.Bytes()).toString('utf8')).toBe('ok')
   })
 
-  it('routes Client.Do through the in-memory server handler', async () => {
-    const srv = NewServer({
+  it('routes Client.Do through the in-memory TLS server handler', async () => {
+    const srv = NewTLSServer({
       ServeHTTP(w, r) {
         const req = $.pointerValue(r)!
         expect(req.RequestURI).toBe('/pack.kvf')
@@ -128,7 +163,7 @@ describe('net/http/httptest override', () => {
   })
 
   it('awaits async handlers through Server.Client transport', async () => {
-    const srv = NewServer({
+    const srv = NewTLSServer({
       async ServeHTTP(w) {
         await Promise.resolve()
         w!.WriteHeader(StatusPartialContent)
@@ -151,7 +186,7 @@ describe('net/http/httptest override', () => {
   })
 
   it('closes request bodies through Server.Client transport', async () => {
-    const srv = NewServer({
+    const srv = NewTLSServer({
       ServeHTTP(w) {
         w!.WriteHeader(StatusPartialContent)
       },
@@ -176,7 +211,7 @@ describe('net/http/httptest override', () => {
   })
 
   it('suppresses bodies for Server.Client HEAD requests', async () => {
-    const srv = NewServer({
+    const srv = NewTLSServer({
       ServeHTTP(w) {
         w!.WriteHeader(StatusPartialContent)
         w!.Write($.stringToBytes('hidden'))
@@ -127,6 +127,9 @@ function requestBodyContentLength(body: io.Reader | null): bigint {
   return -1n
 }
 
+// Server serves its handler on a local port of the host, or in process on
+// hosts without an HTTP server API and for TLS, which needs certificates the
+// client does not have.
 export class Server {
   public ConfigValue: http.Server
   public Listener: any = null
@@ -134,29 +137,65 @@ export class Server {
   public TLS: any = null
   public URL: string
   private handler: http.Handler | null
+  private inProcess = false
 
   constructor(init?: Partial<Server> & { Handler?: http.Handler | null }) {
     this.handler = init?.Handler ?? null
     this.ConfigValue =
       init?.ConfigValue ?? new http.Server({ Handler: this.handler })
-    this.URL = init?.URL ?? http.RegisterInProcessServer(this.handler)
+    this.URL = init?.URL ?? ''
   }
 
   public Client(): http.Client {
-    return new http.Client({ Transport: new serverTransport(this) })
+    if (this.inProcess) {
+      return new http.Client({ Transport: new serverTransport(this) })
+    }
+    return new http.Client({ Transport: new http.Transport() })
   }
 
   public Close(): void {
-    http.UnregisterInProcessServer(this.URL)
+    if (this.inProcess) {
+      http.UnregisterInProcessServer(this.URL)
+      return
+    }
+    this.ConfigValue.Close()
   }
 
   public Config(): http.Server {
     return this.ConfigValue
   }
 
-  public Start(): void {}
+  // Start serves the handler on 127.0.0.1 with a port the host picks.
+  public async Start(): Promise<void> {
+    if (this.URL !== '') {
+      $.panic('Server already started')
+    }
+    const [l, err] = await this.ConfigValue.listen('127.0.0.1:0', null)
+    if (err === http.ErrNotSupported) {
+      this.startInProcess('http')
+      return
+    }
+    if (err != null || l == null) {
+      $.panic(`httptest: failed to listen on a port: ${err?.Error()}`)
+    }
+    this.URL = `http://${l.Addr().String()}`
+    this.Listener = l
+  }
 
-  public StartTLS(): void {}
+  public StartTLS(): void {
+    if (this.URL !== '') {
+      $.panic('Server already started')
+    }
+    this.startInProcess('https')
+    this.TLS = {}
+  }
+
+  private startInProcess(scheme: string): void {
+    this.inProcess = true
+    this.URL = http
+      .RegisterInProcessServer(this.ConfigValue)
+      .replace(/^http:/, `${scheme}:`)
+  }
 
   public ServeHTTP(
     w: http.ResponseWriter | null,
@@ -214,13 +253,15 @@ function serverRequest(request: http.Request): http.Request {
   return req
 }
 
-export function NewServer(handler: http.Handler | null): Server {
-  return new Server({ Handler: handler })
+export async function NewServer(handler: http.Handler | null): Promise<Server> {
+  const server = new Server({ Handler: handler })
+  await server.Start()
+  return server
 }
 
 export function NewTLSServer(handler: http.Handler | null): Server {
   const server = new Server({ Handler: handler })
-  server.URL = server.URL.replace(/^http:\/\//, 'https://')
+  server.StartTLS()
   return server
 }
 
@@ -228,8 +269,9 @@ export function NewUnstartedServer(handler: http.Handler | null): Server {
   return new Server({ Handler: handler })
 }
 
-export function Server_Start(_s: Server | $.VarRef<Server> | null): $.GoError {
-  return errors.New(
-    'net/http/httptest: Server.Start is not implemented in GoScript',
-  )
+export async function Server_Start(
+  s: Server | $.VarRef<Server> | null,
+): Promise<$.GoError> {
+  await $.pointerValue<Server | null>(s)?.Start()
+  return null
 }
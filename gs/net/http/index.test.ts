@@ -724,12 +724,16 @@ describe('net/http override', () => {
     const controller = NewResponseController(null)
     expect(controller.Hijack()[2]).toBe(ErrNotSupported)
     expect(controller.SetReadDeadline({} as any)).toBe(ErrNotSupported)
-    expect(ListenAndServe(':0', null)).toBe(ErrNotSupported)
-    expect(ListenAndServeTLS(':0', 'cert.pem', 'key.pem', null)).toBe(
+    expect((await ListenAndServe('bad', null))?.Error()).toBe(
+      'listen tcp bad: address bad: missing port in address',
+    )
+    expect(
+      (await ListenAndServeTLS('bad', 'cert.pem', 'key.pem', null))?.Error(),
+    ).toMatch('no such file')
+    expect(await Serve(null, null)).toBe(ErrNotSupported)
+    expect(await ServeTLS(null, null, 'cert.pem', 'key.pem')).toBe(
       ErrNotSupported,
     )
-    expect(Serve(null, null)).toBe(ErrNotSupported)
-    expect(ServeTLS(null, null, 'cert.pem', 'key.pem')).toBe(ErrNotSupported)
     expect(new Transport().Clone()).toBeInstanceOf(Transport)
   })
 
@@ -1280,7 +1284,7 @@ describe('net/http override', () => {
     expect(Header_Get(header, 'X-Pack-ID')).toBe('')
   })
 
-  it('accepts server context and shutdown surfaces', () => {
+  it('accepts server context and shutdown surfaces', async () => {
     const srv = new Server({
       Addr: ':0',
       BaseContext: () => ({}) as any,
@@ -1290,10 +1294,11 @@ describe('net/http override', () => {
     expect(srv.Addr).toBe(':0')
     expect(srv.BaseContext?.(null)).toEqual({})
     expect(srv.ReadHeaderTimeout).toBe(10)
-    expect(srv.Shutdown({} as any)).toBeNull()
-    expect(srv.ListenAndServeTLS('cert.pem', 'key.pem')?.Error()).toBe(
-      'net/http: Server.ListenAndServeTLS is not implemented in GoScript',
+    expect(await srv.Shutdown(context.Background())).toBeNull()
+    expect(await srv.ListenAndServeTLS('cert.pem', 'key.pem')).toBe(
+      ErrServerClosed,
     )
+    expect(await srv.ListenAndServe()).toBe(ErrServerClosed)
   })
 
   it('supports handler functions and not-found responses for typechecked server tests', () => {
@@ -2369,3 +2374,159 @@ describe('net/http override', () => {
     expect(writes[0]).toBe('status:404')
   })
 })
+
+describe('net/http host server', () => {
+  it('streams request and response bodies through the host server', async () => {
+    let release: () => void = () => {}
+    const released = new Promise<void>((resolve) => {
+      release = resolve
+    })
+    const srv = new Server({
+      Handler: {
+        async ServeHTTP(w, r) {
+          const req = $.pointerValue<Request>(r)
+          expect(req.Host).toMatch('127.0.0.1:')
+          expect(req.RemoteAddr).toMatch('127.0.0.1:')
+          expect(req.RequestURI).toBe('/echo?x=1')
+          expect(req.URL?.Query().Get('x')).toBe('1')
+          const [data, err] = await io.ReadAll(req.Body!)
+          expect(err).toBeNull()
+          Header_Set(w!.Header(), 'X-Method', req.Method)
+          w!.Write($.stringToBytes('got ' + $.bytesToString(data)))
+          ;(w as any).Flush()
+          await released
+          w!.Write($.stringToBytes(' done'))
+        },
+      },
+    })
+    const [l, err] = await srv.listen('127.0.0.1:0', null)
+    expect(err).toBeNull()
+
+    const body = new ReadableStream<Uint8Array>({
+      start(controller) {
+        controller.enqueue($.stringToBytes('part1 '))
+        controller.enqueue($.stringToBytes('part2'))
+        controller.close()
+      },
+    })
+    const resp = await originalFetch(`http://${l!.Addr().String()}/echo?x=1`, {
+      method: 'POST',
+      body,
+      duplex: 'half',
+    } as RequestInit)
+    expect(resp.status).toBe(200)
+    expect(resp.headers.get('X-Method')).toBe('POST')
+    expect(resp.headers.get('Content-Length')).toBeNull()
+
+    const reader = resp.body!.getReader()
+    const first = await reader.read()
+    expect(Buffer.from(first.value!).toString('utf8')).toBe('got part1 part2')
+    release()
+    let rest = ''
+    for (;;) {
+      const { value, done } = await reader.read()
+      if (done) {
+        break
+      }
+      rest += Buffer.from(value).toString('utf8')
+    }
+    expect(rest).toBe(' done')
+
+    expect(await srv.Shutdown(context.Background())).toBeNull()
+  })
+
+  it('buffers small responses with a content length and type', async () => {
+    const srv = new Server({
+      Handler: {
+        ServeHTTP(w) {
+          w!.WriteHeader(StatusCreated)
+          w!.Write($.stringToBytes('<html>hi</html>'))
+        },
+      },
+    })
+    const [l, err] = await srv.listen('127.0.0.1:0', null)
+    expect(err).toBeNull()
+
+    const resp = await originalFetch(`http://${l!.Addr().String()}/`)
+    expect(resp.status).toBe(StatusCreated)
+    expect(resp.headers.get('Content-Length')).toBe('15')
+    expect(resp.headers.get('Content-Type')).toBe('text/html; charset=utf-8')
+    expect(await resp.text()).toBe('<html>hi</html>')
+
+    expect(srv.Close()).toBeNull()
+  })
+
+  it('serves a host listener with the server that serves it', async () => {
+    let late: Promise<[number, $.GoError]> | null = null
+    const [l, err] = await new Server().listen('127.0.0.1:0', null)
+    expect(err).toBeNull()
+    const served = Serve(l, {
+      ServeHTTP(w) {
+        w!.Write($.stringToBytes('served'))
+        late = new Promise((resolve) =>
+          setTimeout(() => resolve(w!.Write($.stringToBytes('late'))), 0),
+        )
+      },
+    })
+
+    const resp = await originalFetch(`http://${l!.Addr().String()}/`)
+    expect(await resp.text()).toBe('served')
+    const [n, writeErr] = await late!
+    expect(n).toBe(0)
+    expect(writeErr?.Error()).toBe('http: write on closed response writer')
+
+    expect(l!.Close()).toBeNull()
+    expect((await served)?.Error()).toMatch(
+      /^accept tcp 127\.0\.0\.1:\d+: use of closed network connection$/,
+    )
+    expect(l!.Close()).not.toBeNull()
+    expect(await ServeTLS(l, null, 'cert.pem', 'key.pem')).toBe(
+      ErrNotSupported,
+    )
+  })
+
+  it('returns ErrServerClosed from ListenAndServe after Shutdown', async () => {
+    const srv = new Server({ Addr: '127.0.0.1:0' })
+    let shutdownCalled = false
+    srv.RegisterOnShutdown(() => {
+      shutdownCalled = true
+    })
+    const served = srv.ListenAndServe()
+    await new Promise((resolve) => setTimeout(resolve, 10))
+
+    expect(await srv.Shutdown(context.Background())).toBeNull()
+    expect(await served).toBe(ErrServerClosed)
+    await new Promise((resolve) => setTimeout(resolve, 0))
+    expect(shutdownCalled).toBe(true)
+  })
+
+  it('stops waiting for handlers once the Shutdown context is done', async () => {
+    let release: () => void = () => {}
+    const released = new Promise<void>((resolve) => {
+      release = resolve
+    })
+    let started: () => void = () => {}
+    const handlerStarted = new Promise<void>((resolve) => {
+      started = resolve
+    })
+    const srv = new Server({
+      Handler: {
+        async ServeHTTP(w) {
+          started()
+          await released
+          w!.Write($.stringToBytes('late'))
+        },
+      },
+    })
+    const [l] = await srv.listen('127.0.0.1:0', null)
+    const resp = originalFetch(`http://${l!.Addr().String()}/`)
+    await handlerStarted
+
+    const [ctx, cancel] = context.WithCancel(context.Background())
+    cancel()
+    expect(await srv.Shutdown(ctx)).toBe(context.Canceled)
+
+    release()
+    expect(await (await resp).text()).toBe('late')
+  })
+})
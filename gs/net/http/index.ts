@@ -9,6 +9,14 @@ import * as path from '@goscript/path/index.js'
 import * as strings from '@goscript/strings/index.js'
 import * as time from '@goscript/time/index.js'
 
+import {
+  HostServerUnsupportedError,
+  listenHost,
+  type HostRequest,
+  type HostResponse,
+  type HostServer,
+} from './host-server.js'
+
 export const StatusContinue = 100
 export const StatusSwitchingProtocols = 101
 export const StatusProcessing = 102
@@ -164,6 +172,11 @@ const errCrossOriginRequest = errors.New(
 const errCrossOriginRequestFromOldBrowser = errors.New(
   'cross-origin request detected, and/or browser is out of date: Sec-Fetch-Site is missing, and Origin does not match Host',
 )
+// errWriteAfterHandler is returned by a ResponseWriter used after its handler
+// returned, when the response is already closed.
+const errWriteAfterHandler = errors.New(
+  'http: write on closed response writer',
+)
 export const ServerContextKey = Symbol('net/http ServerContextKey')
 export const LocalAddrContextKey = Symbol('net/http LocalAddrContextKey')
 
@@ -1429,6 +1442,9 @@ function newFetchContext(requestContext: context.Context): {
   wait: <T>(promise: Promise<T>) => Promise<[T | null, $.GoError]>
 } {
   let stopped = false
+  // The fetch is host I/O that can still wake the goroutine until it stops,
+  // and the context watch below parks without one.
+  const endWork = $.beginHostWork()
   const watchController =
     typeof AbortController === 'undefined' ? null : new AbortController()
   const controller =
@@ -1459,6 +1475,7 @@ function newFetchContext(requestContext: context.Context): {
     stop: () => {
       stopped = true
       watchController?.abort()
+      endWork()
     },
     wait: async <T>(promise: Promise<T>): Promise<[T | null, $.GoError]> => {
       const settle = promise.then(
@@ -1892,6 +1909,17 @@ export class Server {
   public ErrorLog: any
   public HTTP2: HTTP2Config | null
   public Protocols: Protocols | null
+  // listeners are the host listeners the server listens on or serves.
+  private listeners = new Set<HostListener>()
+  private shuttingDown = false
+  // closed releases the ListenAndServe calls once the server shuts down.
+  private releaseClosed: () => void = () => {}
+  private readonly closed = new Promise<void>((resolve) => {
+    this.releaseClosed = resolve
+  })
+  // active are the handlers that are still running.
+  private active = new Set<Promise<void>>()
+  private onShutdown: (() => void)[] = []
 
   constructor(init?: Partial<Server>) {
     this.Addr = init?.Addr ?? ''
@@ -1914,36 +1942,225 @@ export class Server {
     this.Protocols = init?.Protocols ?? null
   }
 
-  public ListenAndServe(): $.GoError {
-    return errors.New(
-      'net/http: Server.ListenAndServe is not implemented in GoScript',
-    )
+  // ListenAndServe serves HTTP on srv.Addr with the HTTP server of the host
+  // until the server shuts down, and then returns ErrServerClosed.
+  public async ListenAndServe(): Promise<$.GoError> {
+    if (this.shuttingDown) {
+      return ErrServerClosed
+    }
+    const [l, err] = await this.listen(this.Addr || ':http', null)
+    if (err != null) {
+      return err
+    }
+    return this.Serve(l)
+  }
+
+  public async ListenAndServeTLS(
+    certFile: string,
+    keyFile: string,
+  ): Promise<$.GoError> {
+    if (this.shuttingDown) {
+      return ErrServerClosed
+    }
+    const nodeFS = $.getHostRuntime().nodeFS
+    if (nodeFS?.readFileSync === undefined) {
+      return errors.New(
+        'net/http: reading TLS certificates is not supported on this host',
+      )
+    }
+    let tls: { cert: string; key: string }
+    try {
+      const decoder = new TextDecoder()
+      tls = {
+        cert: decoder.decode(nodeFS.readFileSync(certFile)),
+        key: decoder.decode(nodeFS.readFileSync(keyFile)),
+      }
+    } catch (err) {
+      return errorFromUnknown(err)
+    }
+    const [l, err] = await this.listen(this.Addr || ':https', tls)
+    if (err != null) {
+      return err
+    }
+    return this.Serve(l)
+  }
+
+  // listen starts a host server on addr whose requests srv serves until
+  // another Server serves the returned listener. It returns ErrNotSupported
+  // on hosts without an HTTP server API.
+  public async listen(
+    addr: string,
+    tls: { cert: string; key: string } | null,
+  ): Promise<[HostListener | null, $.GoError]> {
+    const [hostname, port, addrErr] = splitListenAddr(addr)
+    if (addrErr != null) {
+      return [null, addrErr]
+    }
+    const l = new HostListener(this, tls !== null)
+    const endWork = $.beginHostWork()
+    try {
+      l.host = await $.park(
+        'IO wait',
+        listenHost(
+          {
+            hostname,
+            port,
+            tls,
+            idleTimeout: Number(
+              BigInt(this.IdleTimeout || this.ReadTimeout) / 1_000_000n,
+            ),
+          },
+          (req) => l.serveRequest(req),
+        ),
+      )
+      if (this.shuttingDown) {
+        l.stop(true)
+        return [null, ErrServerClosed]
+      }
+      this.listeners.add(l)
+      return [l, null]
+    } catch (err) {
+      if (err instanceof HostServerUnsupportedError) {
+        return [null, ErrNotSupported]
+      }
+      return [
+        null,
+        errors.New(`listen tcp ${addr}: ${errorFromUnknown(err)?.Error()}`),
+      ]
+    } finally {
+      endWork()
+    }
   }
 
-  public ListenAndServeTLS(_certFile: string, _keyFile: string): $.GoError {
+  // Serve serves the requests of the listener l until the server shuts down
+  // or l is closed. l must be a HostListener, such as the Listener of an
+  // httptest.Server: GoScript has no net package whose connections Serve
+  // could read requests from, so other listeners get ErrNotSupported.
+  public async Serve(l: any): Promise<$.GoError> {
+    if (!(l instanceof HostListener)) {
+      return ErrNotSupported
+    }
+    if (this.shuttingDown) {
+      l.stop(true)
+      return ErrServerClosed
+    }
+    l.server = this
+    this.listeners.add(l)
+    // The host server counts as pending host work while it serves, since
+    // requests can arrive at any time.
+    const endWork = $.beginHostWork()
+    try {
+      await $.park('IO wait', Promise.race([this.closed, l.closed]))
+    } finally {
+      endWork()
+    }
+    if (this.shuttingDown) {
+      return ErrServerClosed
+    }
+    this.listeners.delete(l)
     return errors.New(
-      'net/http: Server.ListenAndServeTLS is not implemented in GoScript',
+      `accept tcp ${l.Addr().String()}: use of closed network connection`,
     )
   }
 
-  public Close(): $.GoError {
-    return null
+  // ServeTLS is Serve for a listener the host started with TLS. GoScript
+  // cannot add TLS to a listener after the fact.
+  public async ServeTLS(
+    l: any,
+    _certFile: string,
+    _keyFile: string,
+  ): Promise<$.GoError> {
+    if (!(l instanceof HostListener) || !l.tls) {
+      return ErrNotSupported
+    }
+    return this.Serve(l)
+  }
+
+  // serveHostRequest runs the handler for req on a goroutine of its own and
+  // resolves once the response is committed.
+  public serveHostRequest(
+    req: HostRequest,
+    tls: boolean,
+  ): Promise<HostResponse> {
+    const ctx = context.WithValue(
+      this.BaseContext?.(null) ?? context.Background(),
+      ServerContextKey,
+      this,
+    )
+    const [reqCtx, cancel] = context.WithCancel(ctx)
+    if (req.signal.aborted) {
+      cancel()
+    } else {
+      req.signal.addEventListener('abort', () => cancel(), { once: true })
+    }
+    const request = newHostRequest(req, reqCtx, tls)
+    const w = new hostResponseWriter(request.Method)
+    const served = new Promise<void>((resolve) => {
+      $.go(async () => {
+        try {
+          await this.ServeHTTP(w, request)
+          w.finish()
+        } catch (err) {
+          w.abort(err)
+          if (err !== ErrAbortHandler) {
+            $.writeHostStderrText(
+              `http: panic serving ${req.remoteAddr}: ${$.formatUncaughtPanic(err).replace(/^panic: /, '')}\n`,
+            )
+          }
+        } finally {
+          request.Body?.Close()
+          cancel()
+          resolve()
+        }
+      }, 'net/http.(*conn).serve')
+    })
+    this.active.add(served)
+    void served.then(() => this.active.delete(served))
+    return w.response
   }
 
-  public Shutdown(_ctx: context.Context): $.GoError {
+  // Close stops the server right away and closes its active connections.
+  public Close(): $.GoError {
+    this.shuttingDown = true
+    for (const l of this.listeners) {
+      l.stop(true)
+    }
+    this.listeners.clear()
+    this.releaseClosed()
     return null
   }
 
-  public Serve(_listener: any): $.GoError {
-    return ErrNotSupported
-  }
-
-  public ServeTLS(
-    _listener: any,
-    _certFile: string,
-    _keyFile: string,
-  ): $.GoError {
-    return ErrNotSupported
+  // Shutdown stops the server from accepting connections, then waits for
+  // the active handlers to return or ctx to be done.
+  public async Shutdown(ctx: context.Context): Promise<$.GoError> {
+    this.shuttingDown = true
+    for (const l of this.listeners) {
+      l.stop(false)
+    }
+    this.listeners.clear()
+    this.releaseClosed()
+    for (const f of this.onShutdown) {
+      $.go(f, 'net/http.(*Server).Shutdown')
+    }
+    this.onShutdown = []
+    if (this.active.size === 0) {
+      return null
+    }
+    const handlersDone = Promise.all(this.active).then((): $.GoError => null)
+    const done = ctx?.Done()
+    if (done == null) {
+      return $.park('sync.WaitGroup.Wait', handlersDone)
+    }
+    const watch = new AbortController()
+    const ctxDone = done.selectReceive(0, watch.signal).then(
+      () => ctx.Err(),
+      () => ctx.Err(),
+    )
+    try {
+      return await $.park('select', Promise.race([handlersDone, ctxDone]))
+    } finally {
+      watch.abort()
+    }
   }
 
   public ServeHTTP(
@@ -1953,38 +2170,379 @@ export class Server {
     return (this.Handler ?? DefaultServeMux).ServeHTTP(w, r)
   }
 
-  public RegisterOnShutdown(_f: () => void): void {}
+  public RegisterOnShutdown(f: () => void): void {
+    this.onShutdown.push(f)
+  }
 
   public SetKeepAlivesEnabled(_v: boolean): void {}
 }
 
+// HostListener is the net.Listener of a host HTTP server. The host accepts
+// connections and reads requests itself, so Accept is not supported; the
+// Server serving the listener handles its requests instead.
+export class HostListener {
+  public host: HostServer | null = null
+  private isClosed = false
+  private releaseClosed: () => void = () => {}
+  public readonly closed = new Promise<void>((resolve) => {
+    this.releaseClosed = resolve
+  })
+
+  constructor(
+    public server: Server,
+    public readonly tls: boolean,
+  ) {}
+
+  public Accept(): [null, $.GoError] {
+    return [null, ErrNotSupported]
+  }
+
+  public Addr(): { Network(): string; String(): string } {
+    const addr = this.host?.addr ?? ''
+    return { Network: () => 'tcp', String: () => addr }
+  }
+
+  public Close(): $.GoError {
+    if (this.isClosed) {
+      return errors.New(
+        `close tcp ${this.Addr().String()}: use of closed network connection`,
+      )
+    }
+    this.stop(true)
+    return null
+  }
+
+  // stop stops the host server. force closes its active connections too.
+  public stop(force: boolean): void {
+    this.isClosed = true
+    this.host?.stop(force)
+    this.releaseClosed()
+  }
+
+  // serveRequest hands req to the server serving the listener.
+  public serveRequest(req: HostRequest): Promise<HostResponse> {
+    return this.server.serveHostRequest(req, this.tls)
+  }
+}
+
 export function ListenAndServe(
-  _addr: string,
-  _handler: Handler | null,
-): $.GoError {
-  return ErrNotSupported
+  addr: string,
+  handler: Handler | null,
+): Promise<$.GoError> {
+  return new Server({ Addr: addr, Handler: handler }).ListenAndServe()
 }
 
 export function ListenAndServeTLS(
-  _addr: string,
-  _certFile: string,
-  _keyFile: string,
-  _handler: Handler | null,
-): $.GoError {
-  return ErrNotSupported
+  addr: string,
+  certFile: string,
+  keyFile: string,
+  handler: Handler | null,
+): Promise<$.GoError> {
+  return new Server({ Addr: addr, Handler: handler }).ListenAndServeTLS(
+    certFile,
+    keyFile,
+  )
 }
 
-export function Serve(_listener: any, _handler: Handler | null): $.GoError {
-  return ErrNotSupported
+// splitListenAddr splits a listen address such as ":8080" or
+// "localhost:http" into the host and port to listen on.
+function splitListenAddr(addr: string): [string, number, $.GoError] {
+  const idx = addr.lastIndexOf(':')
+  if (idx < 0) {
+    return [
+      '',
+      0,
+      errors.New(`listen tcp ${addr}: address ${addr}: missing port in address`),
+    ]
+  }
+  let host = addr.slice(0, idx)
+  if (host.startsWith('[') && host.endsWith(']')) {
+    host = host.slice(1, -1)
+  }
+  const service = addr.slice(idx + 1)
+  const port =
+    service === 'http' ? 80
+    : service === 'https' ? 443
+    : /^\d+$/.test(service) ? Number(service)
+    : NaN
+  if (!(port >= 0 && port <= 65535)) {
+    return ['', 0, errors.New(`listen tcp ${addr}: unknown port`)]
+  }
+  return [host, port, null]
+}
+
+// hostRequestBody streams the body of a request the host server received.
+class hostRequestBody implements io.ReadCloser {
+  private reader: ReadableStreamDefaultReader<Uint8Array>
+  private chunk: bytes.Reader | null = null
+  private closed = false
+
+  constructor(body: ReadableStream<Uint8Array>) {
+    this.reader = body.getReader()
+  }
+
+  public Read(p: $.Bytes): [number, $.GoError]
+  public Read(p: $.Bytes): Promise<[number, $.GoError]>
+  public Read(p: $.Bytes): [number, $.GoError] | Promise<[number, $.GoError]> {
+    return this.readAsync(p)
+  }
+
+  private async readAsync(p: $.Bytes): Promise<[number, $.GoError]> {
+    if (this.closed) {
+      return [0, ErrBodyReadAfterClose]
+    }
+    while (this.chunk === null || this.chunk.Len() === 0) {
+      let result: ReadableStreamReadResult<Uint8Array>
+      try {
+        result = await this.reader.read()
+      } catch (err) {
+        return [0, errorFromUnknown(err)]
+      }
+      if (result.done) {
+        return [0, io.EOF]
+      }
+      this.chunk = bytes.NewReader(result.value)
+    }
+    return this.chunk.Read(p)
+  }
+
+  public Close(): $.GoError {
+    if (!this.closed) {
+      this.closed = true
+      void this.reader.cancel().catch(() => {})
+    }
+    return null
+  }
+}
+
+function newHostRequest(
+  req: HostRequest,
+  ctx: context.Context,
+  tls: boolean,
+): Request {
+  const header = new Header()
+  let host = ''
+  for (const [key, value] of req.headers) {
+    if (key.toLowerCase() === 'host') {
+      host = value
+      continue
+    }
+    Header_Add(header, key, value)
+  }
+  const [parsed] = parseRequestURL(req.target)
+  let contentLength = 0n
+  let transferEncoding: $.Slice<string> = null
+  const declared = Header_Get(header, 'Content-Length')
+  if (/^\d+$/.test(declared)) {
+    contentLength = BigInt(declared)
+  } else if (req.body !== null) {
+    contentLength = -1n
+    transferEncoding = $.arrayToSlice(['chunked'])
+  }
+  return new Request({
+    Method: req.method,
+    URL: parsed ?? new RequestURL(req.target, ''),
+    Proto: `HTTP/${req.protoMajor}.${req.protoMinor}`,
+    ProtoMajor: req.protoMajor,
+    ProtoMinor: req.protoMinor,
+    Header: header,
+    Body: req.body === null ? NoBody : new hostRequestBody(req.body),
+    ContentLength: contentLength,
+    TransferEncoding: transferEncoding,
+    Close: Header_Get(header, 'Connection').toLowerCase() === 'close',
+    Host: host,
+    RequestURI: req.target,
+    RemoteAddr: req.remoteAddr,
+    TLS: tls ? {} : null,
+    ctx,
+  })
+}
+
+// hostBufferSize is how much a handler writes before the response is
+// committed and streamed, like the buffer of a Go server connection.
+const hostBufferSize = 4096
+
+// hostResponseWriter is the ResponseWriter of a request the host server
+// received. Writes are buffered until hostBufferSize bytes, a Flush, or the
+// handler returning commit the response. A response committed by the
+// handler returning gets a Content-Length; otherwise the body streams.
+class hostResponseWriter implements ResponseWriter, Flusher {
+  public readonly response: Promise<HostResponse>
+  private respond: (resp: HostResponse) => void = () => {}
+  private fail: (err: unknown) => void = () => {}
+  private headerMap = new Header()
+  private status = 0
+  private snapshot: [string, string][] = []
+  private buffered: Uint8Array[] = []
+  private bufferedLen = 0
+  private stream: ReadableStreamDefaultController<Uint8Array> | null = null
+  private committed = false
+  private done = false
+
+  constructor(private readonly method: string) {
+    this.response = new Promise((resolve, reject) => {
+      this.respond = resolve
+      this.fail = reject
+    })
+  }
+
+  public Header(): Header {
+    return this.headerMap
+  }
+
+  public WriteHeader(statusCode: number): void {
+    if (this.status !== 0) {
+      $.writeHostStderrText('http: superfluous response.WriteHeader call\n')
+      return
+    }
+    if (statusCode < 100 || statusCode > 999) {
+      $.panic(`invalid WriteHeader code ${statusCode}`)
+    }
+    if (statusCode < 200) {
+      // Informational responses are not passed on by the host servers.
+      return
+    }
+    this.status = statusCode
+    this.snapshot = []
+    for (const [key, values] of this.headerMap) {
+      for (const value of Array.from(values ?? [])) {
+        this.snapshot.push([key, value])
+      }
+    }
+  }
+
+  public Write(p: $.Slice<number>): [number, $.GoError] {
+    if (this.done) {
+      return [0, errWriteAfterHandler]
+    }
+    if (this.status === 0) {
+      this.WriteHeader(StatusOK)
+    }
+    const n = $.len(p)
+    if (n === 0) {
+      return [0, null]
+    }
+    if (!bodyAllowedForStatus(this.status)) {
+      return [0, ErrBodyNotAllowed]
+    }
+    const data = Uint8Array.from(p ?? [])
+    if (this.stream !== null) {
+      this.stream.enqueue(data)
+      return [n, null]
+    }
+    this.buffered.push(data)
+    this.bufferedLen += n
+    if (this.bufferedLen > hostBufferSize) {
+      this.commit(true)
+    }
+    return [n, null]
+  }
+
+  public WriteString(s: string): [number, $.GoError] {
+    return this.Write($.stringToBytes(s))
+  }
+
+  public Flush(): void {
+    if (this.status === 0) {
+      this.WriteHeader(StatusOK)
+    }
+    if (!this.committed) {
+      this.commit(true)
+    }
+  }
+
+  // finish commits or ends the response once the handler returns.
+  public finish(): void {
+    if (this.status === 0) {
+      this.WriteHeader(StatusOK)
+    }
+    if (!this.committed) {
+      this.commit(false)
+    }
+    this.done = true
+    this.stream?.close()
+  }
+
+  // abort drops the response of a handler that panicked.
+  public abort(err: unknown): void {
+    this.done = true
+    if (!this.committed) {
+      this.committed = true
+      this.fail(err)
+      return
+    }
+    this.stream?.error(err)
+  }
+
+  private commit(streaming: boolean): void {
+    this.committed = true
+    const headers = this.snapshot
+    const has = (name: string) =>
+      headers.some(([key]) => key.toLowerCase() === name)
+    const body = concatChunks(this.buffered, this.bufferedLen)
+    this.buffered = []
+    if (
+      !has('content-type') &&
+      body.length !== 0 &&
+      !has('transfer-encoding')
+    ) {
+      headers.push(['Content-Type', DetectContentType(body)])
+    }
+    if (!streaming) {
+      if (!has('content-length') && bodyAllowedForStatus(this.status)) {
+        headers.push(['Content-Length', String(body.length)])
+      }
+      this.respond({
+        status: this.status,
+        headers,
+        body: this.method === MethodHead ? null : body,
+      })
+      return
+    }
+    this.respond({
+      status: this.status,
+      headers,
+      body: new ReadableStream<Uint8Array>({
+        start: (controller) => {
+          this.stream = controller
+          if (body.length !== 0) {
+            controller.enqueue(body)
+          }
+        },
+      }),
+    })
+  }
+}
+
+function bodyAllowedForStatus(status: number): boolean {
+  return !(
+    (status >= 100 && status <= 199) ||
+    status === StatusNoContent ||
+    status === StatusNotModified
+  )
+}
+
+function concatChunks(chunks: Uint8Array[], length: number): Uint8Array {
+  const out = new Uint8Array(length)
+  let off = 0
+  for (const chunk of chunks) {
+    out.set(chunk, off)
+    off += chunk.length
+  }
+  return out
+}
+
+export function Serve(l: any, handler: Handler | null): Promise<$.GoError> {
+  return new Server({ Handler: handler }).Serve(l)
 }
 
 export function ServeTLS(
-  _listener: any,
-  _handler: Handler | null,
-  _certFile: string,
-  _keyFile: string,
-): $.GoError {
-  return ErrNotSupported
+  l: any,
+  handler: Handler | null,
+  certFile: string,
+  keyFile: string,
+): Promise<$.GoError> {
+  return new Server({ Handler: handler }).ServeTLS(l, certFile, keyFile)
 }
 
 export class PushOptions {
@@ -0,0 +1,102 @@
+import { afterEach, describe, expect, it } from 'vitest'
+
+import * as $ from '@goscript/builtin/index.js'
+import * as errors from '../errors/errors.js'
+
+import {
+  Chdir,
+  ErrNotExist,
+  Getwd,
+  MkdirAll,
+  O_CREATE,
+  O_RDWR,
+  OpenFile,
+  ReadDir,
+  ReadFile,
+  Remove,
+  RemoveAll,
+  SEEK_SET,
+  Stat,
+  WriteFile,
+} from './index.js'
+
+let unmount: (() => void) | null = null
+
+afterEach(() => {
+  unmount?.()
+  unmount = null
+})
+
+function mountMemFS(): $.MemFS {
+  const fsys = new $.MemFS()
+  unmount = $.mountHostFS(fsys)
+  return fsys
+}
+
+describe('os on a virtual filesystem', () => {
+  it('reads and writes files and directories', () => {
+    const fsys = mountMemFS()
+
+    expect(MkdirAll('/srv/app/data', 0o755)).toBeNull()
+    const a = $.stringToBytes('a')
+    expect(WriteFile('/srv/app/data/a.txt', a, 0o644)).toBeNull()
+    expect(WriteFile('/srv/app/b.txt', $.stringToBytes('b'), 0o600)).toBeNull()
+    expect(fsys.readdirSync('/srv/app')).toEqual(['b.txt', 'data'])
+
+    const [data, readErr] = ReadFile('/srv/app/data/a.txt')
+    expect(readErr).toBeNull()
+    expect($.bytesToString(data)).toBe('a')
+
+    const [entries, dirErr] = ReadDir('/srv/app')
+    expect(dirErr).toBeNull()
+    expect(entries!.map((entry) => [entry!.Name(), entry!.IsDir()])).toEqual([
+      ['b.txt', false],
+      ['data', true],
+    ])
+
+    const [info, statErr] = Stat('/srv/app/b.txt')
+    expect(statErr).toBeNull()
+    expect(info!.Size()).toBe(1n)
+    expect(info!.Mode()).toBe(0o600)
+
+    const [, missingErr] = ReadFile('/srv/missing')
+    expect(errors.Is(missingErr, ErrNotExist)).toBe(true)
+  })
+
+  it('resolves relative paths against the virtual working directory', () => {
+    mountMemFS()
+
+    expect(MkdirAll('/home/gopher', 0o755)).toBeNull()
+    expect(Chdir('/home/gopher')).toBeNull()
+    expect(Getwd()).toEqual(['/home/gopher', null])
+    expect(WriteFile('notes', $.stringToBytes('hi'), 0o644)).toBeNull()
+    expect($.bytesToString(ReadFile('/home/gopher/notes')[0])).toBe('hi')
+    expect(errors.Is(Chdir('/nowhere'), ErrNotExist)).toBe(true)
+  })
+
+  it('seeks open files', () => {
+    mountMemFS()
+
+    const [f, openErr] = OpenFile('/tmp/f', O_CREATE | O_RDWR, 0o644)
+    expect(openErr).toBeNull()
+    expect(f!.WriteString('hello')).toEqual([5, null])
+    expect(f!.Seek(1n, SEEK_SET)).toEqual([1n, null])
+    const buf = new Uint8Array(4)
+    expect(f!.Read(buf)).toEqual([4, null])
+    expect($.bytesToString(buf)).toBe('ello')
+    expect(f!.Close()).toBeNull()
+  })
+
+  it('removes empty directories and trees', () => {
+    const fsys = mountMemFS()
+
+    expect(MkdirAll('/a/b', 0o755)).toBeNull()
+    expect(WriteFile('/a/b/f', $.stringToBytes('f'), 0o644)).toBeNull()
+    expect(Remove('/a')).not.toBeNull()
+    expect(Remove('/a/b/f')).toBeNull()
+    expect(Remove('/a/b')).toBeNull()
+    expect(MkdirAll('/a/c/d', 0o755)).toBeNull()
+    expect(RemoveAll('/a')).toBeNull()
+    expect(fsys.readdirSync('/')).toEqual(['tmp'])
+  })
+})
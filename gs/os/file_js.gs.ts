@@ -1,7 +1,7 @@
 import * as $ from "@goscript/builtin/index.js";
 import { ErrInvalid, ErrUnimplemented } from "./error.gs.js";
 import { File } from "./types_js.gs.js";
-import { getDeno, getNodeFS, newHostError } from "./types_js.gs.js";
+import { getDeno, getNodeFS, getVirtualFS, newHostError } from "./types_js.gs.js";
 import { O_CREATE, O_RDONLY, O_RDWR, O_TRUNC } from "./file_constants_js.gs.js";
 import { Link as linkPath, openFileNolog, readlink, Remove as removePath, rename as renamePath, Symlink as symlinkPath, Truncate as truncatePath } from "./file_unix_js.gs.js";
 import { Lstat as lstatPath, Stat as statPath } from "./stat_js.gs.js";
@@ -135,6 +135,15 @@ export function RemoveAll(path: string): $.GoError {
 }
 
 export function Chdir(dir: string): $.GoError {
+  const virtualFS = getVirtualFS()
+  if (virtualFS) {
+    try {
+      virtualFS.chdir(dir)
+      return null
+    } catch (err) {
+      return newHostError(err)
+    }
+  }
   const denoObj = getDeno()
   if (denoObj?.chdir) {
     try {
@@ -78,7 +78,19 @@ export function Remove(name: string): $.GoError {
 			nodeFS.rmSync(name)
 			return null
 		} catch (err) {
-			return newHostError(err)
+			// Like Go, remove an empty directory too, which rm refuses.
+			if (!nodeFS.rmdirSync || !nodeFS.lstatSync) {
+				return newHostError(err)
+			}
+			try {
+				if (!nodeFS.lstatSync(name).isDirectory()) {
+					return newHostError(err)
+				}
+				nodeFS.rmdirSync(name)
+				return null
+			} catch (dirErr) {
+				return newHostError(dirErr)
+			}
 		}
 	}
 	if (nodeFS?.unlinkSync) {
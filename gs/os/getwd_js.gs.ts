@@ -1,11 +1,15 @@
 import * as $ from "@goscript/builtin/index.js";
-import { getDeno } from "./types_js.gs.js";
+import { getDeno, getVirtualFS } from "./types_js.gs.js";
 
 import * as fs from "@goscript/io/fs/index.js"
 
 let currentWorkingDir: string = "/"
 
 export function Getwd(): [string, $.GoError] {
+	const virtualFS = getVirtualFS()
+	if (virtualFS) {
+		return [virtualFS.cwd(), null]
+	}
 	const denoObj = getDeno()
 	if (denoObj?.cwd) {
 		try {
@@ -1,16 +1,8 @@
 import * as $ from "@goscript/builtin/index.js";
-import { getDeno } from "./types_js.gs.js";
+import { getHostRuntime } from "@goscript/builtin/hostio.js";
 
 export function runtime_args(): $.Slice<string> {
-	const denoObj = getDeno()
-	if (Array.isArray(denoObj?.args)) {
-		return $.arrayToSlice<string>(denoObj.args)
-	}
-	const processObj = (globalThis as any).process
-	if (Array.isArray(processObj?.argv)) {
-		return $.arrayToSlice<string>(processObj.argv.slice(2))
-	}
-	return $.arrayToSlice<string>([])
+	return $.arrayToSlice<string>(getHostRuntime().args)
 }
 
 export function runtime_beforeExit(exitCode: number): void {
@@ -10,6 +10,7 @@ import {
 	DenoFileLike,
 	DenoStream,
 	getHostRuntime,
+	HostFS,
 	HostUnsupportedError,
 	NodeFSModule,
 	resetHostRuntimeForTests,
@@ -67,6 +68,11 @@ export function getNodeFS(): NodeFSModule | null {
 	return getHostRuntime().nodeFS
 }
 
+// getVirtualFS returns the filesystem that replaces the host's, or null.
+export function getVirtualFS(): HostFS | null {
+	return getHostRuntime().virtualFS
+}
+
 export function getDeno(): any | null {
 	return getHostRuntime().deno
 }
@@ -593,14 +599,22 @@ export class File {
 
 	public Seek(offset: bigint, whence: number): [bigint, $.GoError] {
 		const handle = this.file?.handle
-		if (!handle || typeof handle.seekSync !== "function") {
-			return [0n, ErrUnimplemented]
+		if (handle && typeof handle.seekSync === "function") {
+			try {
+				return [BigInt(handle.seekSync(Number(offset), whence)), null]
+			} catch (err) {
+				return [0n, newHostError(err)]
+			}
 		}
-		try {
-			return [BigInt(handle.seekSync(Number(offset), whence)), null]
-		} catch (err) {
-			return [0n, newHostError(err)]
+		const virtualFS = getVirtualFS()
+		if (virtualFS && this.fd > 2) {
+			try {
+				return [BigInt(virtualFS.seekSync(this.fd, Number(offset), whence)), null]
+			} catch (err) {
+				return [0n, newHostError(err)]
+			}
 		}
+		return [0n, ErrUnimplemented]
 	}
 
 	public WriteString(s: string): [number, $.GoError] {
@@ -85,9 +85,19 @@ export class BuildInfo {
   }
 }
 
+// Stack returns a formatted stack trace of the calling goroutine. It lists Go
+// frames when compiled Go code is on the stack and the JavaScript stack
+// otherwise.
 export function Stack(): Uint8Array {
-  const stack = new Error().stack ?? 'stack trace unavailable'
-  return new TextEncoder().encode(stack)
+  const frames = $.callerFrames()
+  if (frames.length === 0) {
+    const stack = new Error().stack ?? 'stack trace unavailable'
+    return new TextEncoder().encode(stack)
+  }
+  const id = $.currentGoroutineInfo()?.id ?? 1
+  return new TextEncoder().encode(
+    `goroutine ${id} [running]:\n${$.formatGoFrames(frames)}\n`,
+  )
 }
 
 export function PrintStack(): void {
@@ -6,9 +6,11 @@ import {
   Compiler,
   FuncForPC,
   MemStats,
+  NumGoroutine,
   ReadMemStats,
   ReadTrace,
   SetFinalizer,
+  Stack,
   StartTrace,
   StopTrace,
 } from './runtime.js'
@@ -24,6 +26,32 @@ describe('runtime override', () => {
     expect(() => StopTrace()).not.toThrow()
   })
 
+  it('counts goroutines and dumps them from Stack', async () => {
+    const ch = $.makeChannel<number>(0, 0, 'both')
+    const before = NumGoroutine()
+    $.go(
+      async () => {
+        await ch.receive()
+      },
+      'example.test/pkg.Watch',
+      'example.test/pkg/watch.go:7',
+    )
+    await Promise.resolve()
+
+    expect(NumGoroutine()).toBe(before + 1)
+    const buf = new Uint8Array(4096)
+    const n = Stack(buf, true)
+    const trace = new TextDecoder().decode(buf.subarray(0, n))
+    expect(trace).toMatch(/^goroutine \d+ \[running\]:\n/)
+    expect(trace).toContain(
+      '[chan receive]:\ncreated by example.test/pkg.Watch\n\texample.test/pkg/watch.go:7',
+    )
+
+    await ch.send(1)
+    await new Promise((resolve) => setTimeout(resolve, 0))
+    expect(NumGoroutine()).toBe(before)
+  })
+
   it('ignores finalizer registration and clearing', () => {
     const obj = {}
     expect(() => SetFinalizer(obj, () => {})).not.toThrow()
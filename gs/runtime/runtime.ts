@@ -52,55 +52,64 @@ export function Gosched(): Promise<void> {
 }
 
 // NumGoroutine returns the number of goroutines that currently exist.
-// In goscript, this is informational only
-let goroutineCount = 1 // Start with main goroutine
-
 export function NumGoroutine(): number {
-  return goroutineCount
+  return $.numGoroutine()
 }
 
-// Internal function to track goroutine creation (called by goscript runtime)
-export function _incrementGoroutineCount(): void {
-  goroutineCount++
+// Caller returns the function, file, and line of a frame on the calling
+// goroutine's stack. skip 0 is the caller of Caller. Frames come from
+// compiled Go code; with --source-maps they name Go functions and Go files,
+// otherwise they fall back to JavaScript names and generated files.
+export function Caller(skip: number): [number, string, number, boolean] {
+  const frame = $.callerFrames()[skip]
+  if (!frame) {
+    return [0, '', 0, false]
+  }
+  return [framePC(frame), frame.file, frame.line, true]
 }
 
-// Internal function to track goroutine completion (called by goscript runtime)
-export function _decrementGoroutineCount(): void {
-  if (goroutineCount > 0) {
-    goroutineCount--
+// framePCs assigns stable synthetic program counters to Go frames. JavaScript
+// has no program counters, so a PC is an index into this table plus one, and
+// PC 0 stays invalid.
+const framePCs = new Map<string, number>()
+const pcFrames: $.GoFrame[] = []
+
+function framePC(frame: $.GoFrame): number {
+  const key = `${frame.function}\n${frame.file}\n${frame.line}`
+  let pc = framePCs.get(key)
+  if (pc === undefined) {
+    pcFrames.push(frame)
+    pc = pcFrames.length
+    framePCs.set(key, pc)
   }
+  return pc
 }
 
-// Caller returns details about the calling goroutine's stack.
-// This is a simplified version for goscript
-export function Caller(_skip: number): [number, string, number, boolean] {
-  // In JavaScript, we can use Error stack trace, but it's limited
-  // Return dummy values for goscript compatibility
-  const pc = 0 // program counter (not meaningful in JS)
-  const file = 'unknown'
-  const line = 0
-  const ok = false // indicate we don't have real stack info
-  return [pc, file, line, ok]
+function frameForPC(pc: number): $.GoFrame | undefined {
+  return pcFrames[pc - 1]
 }
 
 // Func represents metadata for a function in a stack frame.
 export class Func {
+  constructor(private readonly frame: $.GoFrame = { function: '', file: '', line: 0 }) {}
+
   public Entry(): number {
     return 0
   }
 
   public FileLine(_pc: number): [string, number] {
-    return ['', 0]
+    return [this.frame.file, this.frame.line]
   }
 
   public Name(): string {
-    return ''
+    return this.frame.function
   }
 }
 
 // FuncForPC returns function metadata for a program counter.
-export function FuncForPC(_pc: number): Func | null {
-  return null
+export function FuncForPC(pc: number): Func | null {
+  const frame = frameForPC(pc)
+  return frame ? new Func(frame) : null
 }
 
 // StartTrace enables execution tracing.
@@ -146,22 +155,56 @@ export class Frames {
   }
 }
 
-// Callers fills pc with return program counters from the current stack.
-export function Callers(_skip: number, _pc: $.Slice<number>): number {
-  return 0
+// Callers fills pc with program counters of the calling goroutine's stack.
+// skip 0 is the frame of Callers itself and skip 1 its caller; Callers has
+// no frame of compiled Go code, so both start at the caller.
+export function Callers(skip: number, pc: $.Slice<number>): number {
+  const pcs = $.callerFrames()
+    .slice(Math.max(skip - 1, 0))
+    .map(framePC)
+  return $.copy(pc, $.arrayToSlice(pcs))
 }
 
 // CallersFrames returns an iterator over call frames for pcs.
-export function CallersFrames(_callers: $.Slice<number>): Frames {
-  return new Frames()
+export function CallersFrames(callers: $.Slice<number>): Frames {
+  const frames: Frame[] = []
+  for (let i = 0; i < $.len(callers); i++) {
+    const pc = callers![i]
+    const goFrame = frameForPC(pc)
+    if (!goFrame) {
+      continue
+    }
+    const frame = new Frame()
+    frame.PC = pc
+    frame.Func = new Func(goFrame)
+    frame.Function = goFrame.function
+    frame.File = goFrame.file
+    frame.Line = goFrame.line
+    frames.push(frame)
+  }
+  return new Frames(frames)
 }
 
-// Stack returns a formatted stack trace of the calling goroutine.
-// In JavaScript, we use Error.stack
-export function Stack(): Uint8Array {
-  const stack = new Error().stack || 'stack trace unavailable'
-  const encoder = new TextEncoder()
-  return encoder.encode(stack)
+// Stack formats a stack trace of the calling goroutine into buf and returns
+// the number of bytes written. If all is true, Stack appends the other
+// goroutines with their state and the go statement that created them. The
+// calling goroutine's frames are its Go frames, or the JavaScript stack when
+// no compiled Go code is on it.
+export function Stack(buf: $.Bytes, all: boolean): number {
+  const running = $.currentGoroutineInfo()
+  const goFrames = $.callerFrames()
+  const frames =
+    goFrames.length !== 0 ?
+      $.formatGoFrames(goFrames)
+    : (new Error().stack ?? '').split('\n').slice(2).join('\n')
+  let trace = `goroutine ${running?.id ?? 1} [running]:\n${frames}`
+  if (all) {
+    const others = $.goroutineDump(running)
+    if (others !== '') {
+      trace += '\n\n' + others
+    }
+  }
+  return $.copy(buf, trace)
 }
 
 // MemStats represents memory allocation statistics
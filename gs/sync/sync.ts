@@ -3,7 +3,7 @@
 // low-level library routines. Higher-level synchronization is better done via
 // channels and communication.
 
-import { comparableEqual } from '@goscript/builtin/index.js'
+import { comparableEqual, go, park } from '@goscript/builtin/index.js'
 
 // Locker represents an object that can be locked and unlocked
 export interface Locker {
@@ -29,9 +29,12 @@ export class Mutex implements Locker {
     }
 
     // GoScript lowers goroutine blocking to async suspension.
-    return new Promise<void>((resolve) => {
-      this._waitQueue.push(resolve)
-    })
+    return park(
+      'sync.Mutex.Lock',
+      new Promise<void>((resolve) => {
+        this._waitQueue.push(resolve)
+      }),
+    )
   }
 
   // TryLock tries to lock m and reports whether it succeeded
@@ -83,9 +86,12 @@ export class RWMutex {
       return
     }
 
-    return new Promise<void>((resolve) => {
-      this._writerWaitQueue.push(resolve)
-    })
+    return park(
+      'sync.RWMutex.Lock',
+      new Promise<void>((resolve) => {
+        this._writerWaitQueue.push(resolve)
+      }),
+    )
   }
 
   // TryLock tries to lock rw for writing and reports whether it succeeded
@@ -114,12 +120,15 @@ export class RWMutex {
       return
     }
 
-    return new Promise<void>((resolve) => {
-      this._readerWaitQueue.push(() => {
-        this._readers++
-        resolve()
-      })
-    })
+    return park(
+      'sync.RWMutex.RLock',
+      new Promise<void>((resolve) => {
+        this._readerWaitQueue.push(() => {
+          this._readers++
+          resolve()
+        })
+      }),
+    )
   }
 
   // TryRLock tries to lock rw for reading and reports whether it succeeded
@@ -205,13 +214,13 @@ export class WaitGroup {
   // Go calls f in a new goroutine and adds that task to the WaitGroup.
   public Go(f: () => void | Promise<void>): void {
     this.Add(1)
-    queueMicrotask(async () => {
+    go(async () => {
       try {
         await f()
       } finally {
         this.Done()
       }
-    })
+    }, 'sync.(*WaitGroup).Go')
   }
 
   // Wait blocks until the WaitGroup counter is zero
@@ -220,9 +229,12 @@ export class WaitGroup {
       return
     }
 
-    return new Promise<void>((resolve) => {
-      this._waiters.push(resolve)
-    })
+    return park(
+      'sync.WaitGroup.Wait',
+      new Promise<void>((resolve) => {
+        this._waiters.push(resolve)
+      }),
+    )
   }
 
   // clone returns a copy of this WaitGroup instance
@@ -295,12 +307,14 @@ export class Cond {
   public async Wait(): Promise<void> {
     this.L.Unlock()
 
-    return new Promise<void>((resolve) => {
-      this._waiters.push(async () => {
-        await this.L.Lock()
-        resolve()
-      })
-    })
+    // Park only until signaled; relocking parks on the Locker itself.
+    await park(
+      'sync.Cond.Wait',
+      new Promise<void>((resolve) => {
+        this._waiters.push(resolve)
+      }),
+    )
+    await this.L.Lock()
   }
 
   // clone returns a copy of this Cond instance
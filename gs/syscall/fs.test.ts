@@ -0,0 +1,59 @@
+import { afterEach, describe, expect, test } from 'vitest'
+
+import * as $ from '@goscript/builtin/index.js'
+
+import {
+  Close,
+  EBADF,
+  ENOENT,
+  Fstat,
+  Ftruncate,
+  O_CREATE,
+  O_RDWR,
+  Open,
+  Pread,
+  Pwrite,
+  Read,
+  Seek,
+  Stat_t,
+  Unlink,
+  Write,
+} from './index.js'
+
+let unmount: (() => void) | null = null
+
+afterEach(() => {
+  unmount?.()
+  unmount = null
+})
+
+describe('syscall file operations on a virtual filesystem', () => {
+  test('reads, writes and seeks descriptors', () => {
+    const fsys = new $.MemFS()
+    unmount = $.mountHostFS(fsys)
+
+    const [fd, err] = Open('/tmp/data', O_CREATE | O_RDWR, 0o640)
+    expect(err).toBeNull()
+    expect(Write(fd, new TextEncoder().encode('hello'))).toEqual([5, null])
+    expect(Pwrite(fd, new TextEncoder().encode('J'), 0n)).toEqual([1, null])
+    expect(Seek(fd, 1n, 0)).toEqual([1n, null])
+
+    const buf = new Uint8Array(8)
+    expect(Read(fd, buf)).toEqual([4, null])
+    expect(new TextDecoder().decode(buf.subarray(0, 4))).toBe('ello')
+    expect(Pread(fd, buf, 0n)).toEqual([5, null])
+    expect(buf[0]).toBe(74)
+
+    expect(Ftruncate(fd, 2n)).toBeNull()
+    const stat = new Stat_t()
+    expect(Fstat(fd, stat)).toBeNull()
+    expect(stat.Size).toBe(2)
+    expect(stat.Mode).toBe(0o100640)
+
+    expect(Close(fd)).toBeNull()
+    expect(Close(fd)).toBe(EBADF)
+    expect(Unlink('/tmp/data')).toBeNull()
+    expect(Unlink('/tmp/data')).toBe(ENOENT)
+    expect(Open('/tmp/data', O_RDWR, 0)).toEqual([-1, ENOENT])
+  })
+})
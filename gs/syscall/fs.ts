@@ -1,7 +1,112 @@
 import * as $ from '@goscript/builtin/index.js'
 import { RWMutex } from '@goscript/sync/index.js'
-import { ENOSYS } from './errors.js'
-import type { Iovec, Sockaddr } from './types.js'
+import {
+  EACCES,
+  EBADF,
+  EBUSY,
+  EEXIST,
+  EINVAL,
+  EIO,
+  EISDIR,
+  ELOOP,
+  EMFILE,
+  ENAMETOOLONG,
+  ENOENT,
+  ENOSPC,
+  ENOSYS,
+  ENOTDIR,
+  ENOTEMPTY,
+  EPERM,
+  EROFS,
+  EXDEV,
+} from './errors.js'
+import type { Errno, Iovec, Sockaddr } from './types.js'
+
+const hostErrnos: Record<string, Errno> = {
+  EACCES,
+  EBADF,
+  EBUSY,
+  EEXIST,
+  EINVAL,
+  EISDIR,
+  ELOOP,
+  EMFILE,
+  ENAMETOOLONG,
+  ENOENT,
+  ENOSPC,
+  ENOTDIR,
+  ENOTEMPTY,
+  EPERM,
+  EROFS,
+  EXDEV,
+}
+
+// hostErrno maps an error of the host filesystem API to its Errno.
+function hostErrno(err: unknown): Errno {
+  if (err instanceof $.HostUnsupportedError) {
+    return ENOSYS
+  }
+  const code = (err as { code?: unknown } | null)?.code
+  return (typeof code === 'string' && hostErrnos[code]) || EIO
+}
+
+// hostFile runs op on the host filesystem, which is a virtual one in hosts
+// without their own. It returns EBADF for a negative fd, and ENOSYS when the
+// host has no filesystem API.
+function hostFile<T>(
+  fd: number,
+  op: (nodeFS: $.NodeFSModule) => T,
+): [T | null, $.GoError] {
+  if (fd < 0) {
+    return [null, EBADF]
+  }
+  return hostPath(op)
+}
+
+// hostPath runs op on the host filesystem like hostFile, for path calls.
+function hostPath<T>(
+  op: (nodeFS: $.NodeFSModule) => T,
+): [T | null, $.GoError] {
+  const nodeFS = $.getHostRuntime().nodeFS
+  if (!nodeFS) {
+    return [null, ENOSYS]
+  }
+  try {
+    return [op(nodeFS), null]
+  } catch (err) {
+    return [null, hostErrno(err)]
+  }
+}
+
+// statFromHost fills stat from node:fs Stats.
+function statFromHost(stat: Stat_t, info: any): void {
+  const timespec = (ms: number | undefined): [number, number] => {
+    const value = ms ?? 0
+    const sec = Math.floor(value / 1000)
+    return [sec, Math.round((value - sec * 1000) * 1e6)]
+  }
+  const [atime, atimeNsec] = timespec(info.atimeMs)
+  const [mtime, mtimeNsec] = timespec(info.mtimeMs)
+  const [ctime, ctimeNsec] = timespec(info.ctimeMs)
+  Object.assign(stat, {
+    Dev: Number(info.dev ?? 0),
+    Ino: Number(info.ino ?? 0),
+    Mode: Number(info.mode ?? 0),
+    Nlink: Number(info.nlink ?? 0),
+    Uid: Number(info.uid ?? 0),
+    Gid: Number(info.gid ?? 0),
+    Rdev: Number(info.rdev ?? 0),
+    Size: Number(info.size ?? 0),
+    Blksize: Number(info.blksize ?? 0),
+    Blocks: Number(info.blocks ?? 0),
+    Atime: atime,
+    AtimeNsec: atimeNsec,
+    Mtime: mtime,
+    MtimeNsec: mtimeNsec,
+    Ctime: ctime,
+    CtimeNsec: ctimeNsec,
+  })
+}
 
 // Dirent structure with Reclen field
 export class Dirent {
@@ -43,27 +148,43 @@ export class Stat_t {
   }
 }
 
-// Additional missing syscall functions
+// Open opens path on the host filesystem and returns its descriptor.
 export function Open(
-  _path: string,
-  _flag: number,
-  _perm: number,
+  path: string,
+  flag: number,
+  perm: number,
 ): [number, $.GoError] {
-  return [-1, ENOSYS]
+  const [fd, err] = hostPath((nodeFS) => {
+    if (!nodeFS.openSync) {
+      throw new $.HostUnsupportedError()
+    }
+    return nodeFS.openSync(path, flag, perm)
+  })
+  return err != null ? [-1, err] : [fd!, null]
 }
 
 export function Sysctl(_name: string): [string, $.GoError] {
   return ['', ENOSYS]
 }
 
-export function Unlink(_path: string): $.GoError {
-  return ENOSYS
+export function Unlink(path: string): $.GoError {
+  return hostPath((nodeFS) => {
+    if (!nodeFS.unlinkSync) {
+      throw new $.HostUnsupportedError()
+    }
+    nodeFS.unlinkSync(path)
+  })[1]
 }
 
 export const ForkLock = new RWMutex()
 
-export function Close(_fd: number): $.GoError {
-  return null
+// Close closes fd. The standard streams stay open, since the host shares
+// them with the rest of the JavaScript program.
+export function Close(fd: number): $.GoError {
+  if (fd >= 0 && fd <= 2) {
+    return null
+  }
+  return hostFile(fd, (nodeFS) => nodeFS.closeSync?.(fd))[1]
 }
 
 export function CloseOnExec(_fd: number): void {}
@@ -85,22 +206,42 @@ export function Fchown(_fd: number, _uid: number, _gid: number): $.GoError {
 }
 
 export function Fstat(
-  _fd: number,
-  _stat: Stat_t | $.VarRef<Stat_t> | null,
+  fd: number,
+  stat: Stat_t | $.VarRef<Stat_t> | null,
 ): $.GoError {
-  return ENOSYS
+  return hostFile(fd, (nodeFS) => {
+    if (!nodeFS.fstatSync) {
+      throw new $.HostUnsupportedError()
+    }
+    statFromHost($.pointerValue<Stat_t>(stat), nodeFS.fstatSync(fd))
+  })[1]
 }
 
-export function Fsync(_fd: number): $.GoError {
-  return ENOSYS
+export function Fsync(fd: number): $.GoError {
+  return hostFile(fd, (nodeFS) => {
+    if (!nodeFS.fsyncSync) {
+      throw new $.HostUnsupportedError()
+    }
+    nodeFS.fsyncSync(fd)
+  })[1]
 }
 
-export function Ftruncate(_fd: number, _length: bigint): $.GoError {
-  return ENOSYS
+export function Ftruncate(fd: number, length: bigint): $.GoError {
+  return hostFile(fd, (nodeFS) => {
+    if (!nodeFS.ftruncateSync) {
+      throw new $.HostUnsupportedError()
+    }
+    nodeFS.ftruncateSync(fd, Number(length))
+  })[1]
 }
 
-export function Read(_fd: number, _b: $.Bytes | null): [number, $.GoError] {
-  return [0, ENOSYS]
+export function Read(fd: number, b: $.Bytes | null): [number, $.GoError] {
+  const buf = $.bytesToUint8Array(b)
+  const [n, err] = hostFile(fd, () => $.getHostRuntime().readFD(fd, buf) ?? 0)
+  if (b !== null && !(b instanceof Uint8Array) && n) {
+    $.copy(b, buf.subarray(0, n))
+  }
+  return [n ?? 0, err]
 }
 
 export function ReadDirent(
@@ -111,31 +252,52 @@ export function ReadDirent(
 }
 
 export function Pread(
-  _fd: number,
-  _b: $.Bytes | null,
-  _offset: bigint,
+  fd: number,
+  b: $.Bytes | null,
+  offset: bigint,
 ): [number, $.GoError] {
-  return [0, ENOSYS]
+  const buf = $.bytesToUint8Array(b)
+  const [n, err] = hostFile(fd, (nodeFS) =>
+    nodeFS.readSync(fd, buf, 0, buf.length, Number(offset)),
+  )
+  if (b !== null && !(b instanceof Uint8Array) && n) {
+    $.copy(b, buf.subarray(0, n))
+  }
+  return [n ?? 0, err]
 }
 
 export function Pwrite(
-  _fd: number,
-  _b: $.Bytes | null,
-  _offset: bigint,
+  fd: number,
+  b: $.Bytes | null,
+  offset: bigint,
 ): [number, $.GoError] {
-  return [0, ENOSYS]
+  const buf = $.bytesToUint8Array(b)
+  const [n, err] = hostFile(fd, (nodeFS) =>
+    nodeFS.writeSync(fd, buf, 0, buf.length, Number(offset)),
+  )
+  return [n ?? 0, err]
 }
 
+// Seek needs a virtual filesystem, since node:fs has no lseek.
 export function Seek(
-  _fd: number,
-  _offset: bigint,
-  _whence: number,
+  fd: number,
+  offset: bigint,
+  whence: number,
 ): [bigint, $.GoError] {
-  return [0n, ENOSYS]
+  const [pos, err] = hostFile(fd, () => {
+    const virtualFS = $.getHostRuntime().virtualFS
+    if (!virtualFS) {
+      throw new $.HostUnsupportedError()
+    }
+    return virtualFS.seekSync(fd, Number(offset), whence)
+  })
+  return [BigInt(pos ?? 0), err]
 }
 
-export function Write(_fd: number, _b: $.Bytes | null): [number, $.GoError] {
-  return [0, ENOSYS]
+export function Write(fd: number, b: $.Bytes | null): [number, $.GoError] {
+  const buf = $.bytesToUint8Array(b)
+  const [n, err] = hostFile(fd, () => $.getHostRuntime().writeFD(fd, buf))
+  return [n ?? 0, err]
 }
 
 export function Dup(_fd: number): [number, $.GoError] {
@@ -10,6 +10,7 @@ import {
   Dup,
   EADDRNOTAVAIL,
   EAGAIN,
+  EBADF,
   EINTR,
   EMFILE,
   ENOSYS,
@@ -97,13 +98,16 @@ describe('syscall network stubs', () => {
     expect(SetNonblock(1, false)).toBeNull()
   })
 
+  test('rejects invalid descriptors', () => {
+    expect(Read(-1, null)).toEqual([0, EBADF])
+    expect(Pread(-1, null, 0n)).toEqual([0, EBADF])
+    expect(Pwrite(-1, null, 0n)).toEqual([0, EBADF])
+    expect(Seek(-1, 0n, 0)).toEqual([0n, EBADF])
+    expect(Write(-1, null)).toEqual([0, EBADF])
+  })
+
   test('exports unsupported descriptor operations', () => {
-    expect(Read(-1, null)).toEqual([0, ENOSYS])
     expect(ReadDirent(-1, null)).toEqual([0, ENOSYS])
-    expect(Pread(-1, null, 0)).toEqual([0, ENOSYS])
-    expect(Pwrite(-1, null, 0)).toEqual([0, ENOSYS])
-    expect(Seek(-1, 0n, 0)).toEqual([0n, ENOSYS])
-    expect(Write(-1, null)).toEqual([0, ENOSYS])
     expect(Dup(-1)).toEqual([0, ENOSYS])
     expect(readv(-1, [new Iovec()])).toEqual([0, ENOSYS])
     expect(writev(-1, [new Iovec()])).toEqual([0, ENOSYS])
@@ -0,0 +1,148 @@
+import { describe, expect, it } from 'vitest'
+
+import * as $ from '@goscript/builtin/index.js'
+
+import {
+  corpusValueOf,
+  fuzzArgument,
+  marshalCorpusFile,
+  Mutator,
+  unmarshalCorpusFile,
+  type CorpusValue,
+} from './fuzz.js'
+
+describe('fuzz corpus encoding', () => {
+  it('marshals values like go test fuzz v1', () => {
+    const data = marshalCorpusFile([
+      { type: '[]byte', value: new Uint8Array([0x61, 0x80, 0xc3, 0xbf]) },
+      { type: 'string', value: new TextEncoder().encode('a"\n​') },
+      { type: 'int', value: -5n },
+      { type: 'int32', value: 0x72n },
+      { type: 'int32', value: -1n },
+      { type: 'uint8', value: 0x80n },
+      { type: 'uint64', value: 0xffffffffffffffffn },
+      { type: 'bool', value: true },
+      { type: 'float64', value: 1234567 },
+      { type: 'float64', value: 0.00001 },
+      { type: 'float64', value: -0 },
+      { type: 'float64', value: -Infinity },
+      { type: 'float32', value: Math.fround(0.1) },
+      { type: 'float32', value: Math.fround(-1333539.25) },
+    ])
+
+    expect(data).toBe(
+      [
+        'go test fuzz v1',
+        '[]byte("a\\x80ÿ")',
+        'string("a\\"\\n\\u200b")',
+        'int(-5)',
+        "rune('r')",
+        'int32(-1)',
+        "byte('\\u0080')",
+        'uint64(18446744073709551615)',
+        'bool(true)',
+        'float64(1.234567e+06)',
+        'float64(1e-05)',
+        'float64(-0)',
+        'float64(-Inf)',
+        'float32(0.1)',
+        'float32(-1.3335392e+06)',
+        '',
+      ].join('\n'),
+    )
+    expect(marshalCorpusFile(unmarshalCorpusFile(data))).toBe(data)
+  })
+
+  it('parses the literal forms Go accepts', () => {
+    const values = unmarshalCorpusFile(
+      [
+        'go test fuzz v1',
+        '[]byte(`raw\\x`)',
+        'string("\\377\\u00ff")',
+        'int8(-0x80)',
+        'uint16(0o17)',
+        'int(0b1_01)',
+        'rune(65)',
+        "byte('\\'')",
+        'float32(2)',
+        'float64(NaN)',
+        'float64(+Inf)',
+        'math.Float64frombits(0x3ff0000000000000)',
+        '',
+      ].join('\r\n'),
+    )
+
+    expect(values).toEqual([
+      { type: '[]byte', value: new TextEncoder().encode('raw\\x') },
+      { type: 'string', value: new Uint8Array([0xff, 0xc3, 0xbf]) },
+      { type: 'int8', value: -128n },
+      { type: 'uint16', value: 15n },
+      { type: 'int', value: 5n },
+      { type: 'int32', value: 65n },
+      { type: 'uint8', value: 39n },
+      { type: 'float32', value: 2 },
+      { type: 'float64', value: NaN },
+      { type: 'float64', value: Infinity },
+      { type: 'float64', value: 1 },
+    ])
+  })
+
+  it('rejects malformed corpus files', () => {
+    expect(() => unmarshalCorpusFile('go test fuzz v2\nint(1)\n')).toThrow(
+      'unknown encoding version: go test fuzz v2',
+    )
+    expect(() => unmarshalCorpusFile('go test fuzz v1\nint8(200)\n')).toThrow(
+      'malformed line "int8(200)": strconv.ParseInt: parsing "200": value out of range',
+    )
+    expect(() => unmarshalCorpusFile('go test fuzz v1\nint(1.5)\n')).toThrow(
+      'integer literal required for int types',
+    )
+    expect(() => unmarshalCorpusFile("go test fuzz v1\nbyte('ā')\n")).toThrow(
+      'can only encode single byte to a byte type',
+    )
+  })
+})
+
+describe('fuzz arguments', () => {
+  it('converts f.Add values and fuzz inputs between Go and engine forms', () => {
+    expect(corpusValueOf('hi')).toEqual({
+      type: 'string',
+      value: new Uint8Array([104, 105]),
+    })
+    expect(
+      corpusValueOf(
+        $.namedValueInterfaceValue(114, 'rune', {}, {
+          kind: $.TypeKind.Basic,
+          name: 'int32',
+        }),
+      ),
+    ).toEqual({ type: 'int32', value: 114n })
+    expect(corpusValueOf({ __goType: 'main.Celsius', __goValue: 1 })).toBe(
+      null,
+    )
+
+    expect(
+      fuzzArgument({ type: 'string', value: new Uint8Array([0xff]) }),
+    ).toBe($.bytesToString(new Uint8Array([0xff])))
+    expect(fuzzArgument({ type: 'int64', value: -1n })).toBe(-1n)
+    expect(fuzzArgument({ type: 'int16', value: -1n })).toBe(-1)
+  })
+
+  it('keeps mutated values in range for their types', () => {
+    const mutator = new Mutator(1)
+    const values: CorpusValue[] = [
+      { type: 'int8', value: 0n },
+      { type: 'uint16', value: 0n },
+      { type: 'float32', value: 0 },
+      { type: 'string', value: new Uint8Array(0) },
+    ]
+    for (let i = 0; i < 2000; i++) {
+      mutator.mutate(values, 16)
+      const [i8, u16, f32, str] = values.map((v) => v.value)
+      expect((i8 as bigint) >= -128n && (i8 as bigint) <= 127n).toBe(true)
+      expect((u16 as bigint) >= 0n && (u16 as bigint) <= 0xffffn).toBe(true)
+      expect(Object.is(Math.fround(f32 as number), f32)).toBe(true)
+      expect((str as Uint8Array).length <= 16).toBe(true)
+    }
+  })
+})
@@ -0,0 +1,820 @@
+import * as $ from '@goscript/builtin/index.js'
+
+// FuzzType names a Go type the fuzzing engine supports. byte and rune are
+// spelled uint8 and int32, the types they alias.
+export type FuzzType =
+  | '[]byte'
+  | 'string'
+  | 'bool'
+  | 'int'
+  | 'int8'
+  | 'int16'
+  | 'int32'
+  | 'int64'
+  | 'uint'
+  | 'uint8'
+  | 'uint16'
+  | 'uint32'
+  | 'uint64'
+  | 'float32'
+  | 'float64'
+
+// FuzzValue is one fuzz argument in engine form: strings and byte slices
+// are bytes, integers are bigint, floats are number.
+export type FuzzValue = Uint8Array | boolean | bigint | number
+
+export type CorpusValue = {
+  type: FuzzType
+  value: FuzzValue
+}
+
+const encVersion1 = 'go test fuzz v1'
+
+const intWidths: Partial<Record<FuzzType, [bits: number, signed: boolean]>> =
+  {
+    int: [64, true],
+    int8: [8, true],
+    int16: [16, true],
+    int32: [32, true],
+    int64: [64, true],
+    uint: [64, false],
+    uint8: [8, false],
+    uint16: [16, false],
+    uint32: [32, false],
+    uint64: [64, false],
+  }
+
+const typeAliases: Record<string, FuzzType> = {
+  byte: 'uint8',
+  rune: 'int32',
+  '[]uint8': '[]byte',
+}
+
+// fuzzTypeName returns the name reflect prints for t.
+export function fuzzTypeName(t: FuzzType): string {
+  return t === '[]byte' ? '[]uint8' : t
+}
+
+// fuzzTypeOfParam returns the fuzz type of a fuzz function parameter from its
+// type info, or null when fuzzing does not support the type.
+export function fuzzTypeOfParam(info: unknown): FuzzType | null {
+  if (typeof info === 'string') {
+    return supportedType(info)
+  }
+  if (info === null || typeof info !== 'object') {
+    return null
+  }
+  const typeInfo = info as {
+    kind?: string
+    name?: string
+    elemType?: unknown
+  }
+  if (typeInfo.kind === $.TypeKind.Slice) {
+    const elem = typeInfo.elemType
+    const elemName =
+      typeof elem === 'string' ? elem : (elem as { name?: string })?.name
+    return elemName === 'uint8' || elemName === 'byte' ? '[]byte' : null
+  }
+  if (typeInfo.kind === $.TypeKind.Basic && typeInfo.name !== undefined) {
+    return supportedType(typeInfo.name)
+  }
+  return null
+}
+
+// corpusValueOf converts an f.Add argument to engine form. It returns null
+// for values of unsupported types.
+export function corpusValueOf(arg: unknown): CorpusValue | null {
+  if (typeof arg === 'string' || arg instanceof String) {
+    return { type: 'string', value: $.stringToBytes(arg as string) }
+  }
+  if (typeof arg === 'boolean') {
+    return { type: 'bool', value: arg }
+  }
+  if (arg === null || typeof arg !== 'object') {
+    return null
+  }
+  const boxed = arg as { __goType?: unknown; __goValue?: unknown }
+  if (typeof boxed.__goType !== 'string') {
+    return null
+  }
+  const type = supportedType(boxed.__goType)
+  if (type === null) {
+    return null
+  }
+  if (type === '[]byte') {
+    return {
+      type,
+      value: $.bytesToUint8Array(arg as $.Bytes).slice(),
+    }
+  }
+  const value = boxed.__goValue
+  if (type === 'float32' || type === 'float64') {
+    return typeof value === 'number' ? { type, value } : null
+  }
+  if (type === 'bool' || type === 'string') {
+    return null
+  }
+  if (typeof value !== 'number' && typeof value !== 'bigint') {
+    return null
+  }
+  return { type, value: wrapInt(type, BigInt(value)) }
+}
+
+// zeroCorpusValue returns the zero value of t.
+export function zeroCorpusValue(type: FuzzType): CorpusValue {
+  switch (type) {
+    case '[]byte':
+    case 'string':
+      return { type, value: new Uint8Array(0) }
+    case 'bool':
+      return { type, value: false }
+    case 'float32':
+    case 'float64':
+      return { type, value: 0 }
+    default:
+      return { type, value: 0n }
+  }
+}
+
+// fuzzArgument converts v to the value generated code expects for a Go
+// parameter of its type.
+export function fuzzArgument(v: CorpusValue): unknown {
+  switch (v.type) {
+    case '[]byte':
+      return (v.value as Uint8Array).slice()
+    case 'string':
+      return $.bytesToString(v.value as Uint8Array)
+    case 'bool':
+    case 'float32':
+    case 'float64':
+      return v.value
+    case 'int64':
+    case 'uint64':
+      return v.value
+    case 'uint':
+      return $.uint(v.value as bigint, 64)
+    default:
+      return $.int(v.value as bigint)
+  }
+}
+
+// marshalCorpusFile encodes values in the go test fuzz v1 format.
+export function marshalCorpusFile(values: CorpusValue[]): string {
+  let out = encVersion1 + '\n'
+  for (const v of values) {
+    out += marshalCorpusValue(v) + '\n'
+  }
+  return out
+}
+
+function marshalCorpusValue(v: CorpusValue): string {
+  switch (v.type) {
+    case '[]byte':
+    case 'string':
+      return v.type + '(' + quoteBytes(v.value as Uint8Array) + ')'
+    case 'bool':
+      return 'bool(' + String(v.value) + ')'
+    case 'float32':
+    case 'float64':
+      return v.type + '(' + formatFloat(v.value as number, v.type) + ')'
+    case 'int32': {
+      const r = Number(v.value)
+      if (validRune(r)) {
+        return 'rune(' + quoteRune(r) + ')'
+      }
+      return 'int32(' + String(v.value) + ')'
+    }
+    case 'uint8':
+      return 'byte(' + quoteRune(Number(v.value)) + ')'
+    default:
+      return v.type + '(' + String(v.value) + ')'
+  }
+}
+
+// unmarshalCorpusFile decodes a go test fuzz v1 file.
+export function unmarshalCorpusFile(data: string): CorpusValue[] {
+  const lines = data.split('\n')
+  if (lines.length < 2) {
+    throw new Error('must include version and at least one value')
+  }
+  const version = lines[0].replace(/\r$/, '')
+  if (version !== encVersion1) {
+    throw new Error('unknown encoding version: ' + version)
+  }
+  const values: CorpusValue[] = []
+  for (let line of lines.slice(1)) {
+    line = line.trim()
+    if (line === '') {
+      continue
+    }
+    try {
+      values.push(parseCorpusValue(line))
+    } catch (err) {
+      throw new Error(
+        'malformed line ' +
+          JSON.stringify(line) +
+          ': ' +
+          (err instanceof Error ? err.message : String(err)),
+      )
+    }
+  }
+  return values
+}
+
+// checkCorpus verifies that values match the fuzz function parameter types.
+export function checkCorpus(values: CorpusValue[], types: FuzzType[]): void {
+  if (values.length !== types.length) {
+    throw new Error(
+      'wrong number of values in corpus entry: ' +
+        values.length +
+        ', want ' +
+        types.length,
+    )
+  }
+  for (let i = 0; i < types.length; i++) {
+    if (values[i].type !== types[i]) {
+      throw new Error(
+        'mismatched types in corpus entry: [' +
+          values.map((v) => fuzzTypeName(v.type)).join(' ') +
+          '], want [' +
+          types.map(fuzzTypeName).join(' ') +
+          ']',
+      )
+    }
+  }
+}
+
+function parseCorpusValue(line: string): CorpusValue {
+  const call = /^([^(]*)\((.*)\)$/s.exec(line)
+  if (call === null) {
+    throw new Error('expected call expression')
+  }
+  const fun = call[1].trim()
+  const arg = call[2].trim()
+  if (fun === '[]byte') {
+    if (!isStringLiteral(arg)) {
+      throw new Error('string literal required for type []byte')
+    }
+    return { type: '[]byte', value: unquote(arg) }
+  }
+  if (fun === 'math.Float64frombits' || fun === 'math.Float32frombits') {
+    const bits = /^(0[xX][0-9a-fA-F_]+|[0-9_]+|0[oObB][0-7_]+)$/.test(arg)
+    if (!bits) {
+      throw new Error('integer literal required for ' + fun + ' type')
+    }
+    const view = new DataView(new ArrayBuffer(8))
+    if (fun === 'math.Float64frombits') {
+      view.setBigUint64(0, parseInteger(arg, 'uint64'))
+      return { type: 'float64', value: view.getFloat64(0) }
+    }
+    view.setUint32(0, Number(parseInteger(arg, 'uint32')))
+    return { type: 'float32', value: view.getFloat32(0) }
+  }
+  if (fun === 'bool') {
+    if (arg !== 'true' && arg !== 'false') {
+      throw new Error('true or false required for type bool')
+    }
+    return { type: 'bool', value: arg === 'true' }
+  }
+  if (fun === 'string') {
+    if (!isStringLiteral(arg)) {
+      throw new Error('string literal value required for type string')
+    }
+    return { type: 'string', value: unquote(arg) }
+  }
+  const type = fun === 'byte' || fun === 'rune' ? typeAliases[fun] : fun
+  if (type === 'float32' || type === 'float64') {
+    const value = parseFloatLiteral(arg)
+    return { type, value: type === 'float32' ? Math.fround(value) : value }
+  }
+  if (intWidths[type as FuzzType] === undefined) {
+    throw new Error('expected []byte or primitive type')
+  }
+  if ((fun === 'byte' || fun === 'rune') && arg.startsWith("'")) {
+    const code = unquoteChar(arg)
+    if (fun === 'byte' && code >= 256) {
+      throw new Error('can only encode single byte to a byte type')
+    }
+    return { type: type as FuzzType, value: BigInt(code) }
+  }
+  if (!isIntLiteral(arg)) {
+    throw new Error('integer literal required for int types')
+  }
+  return { type: type as FuzzType, value: parseInteger(arg, type as FuzzType) }
+}
+
+function supportedType(name: string): FuzzType | null {
+  const type = typeAliases[name] ?? name
+  switch (type) {
+    case '[]byte':
+    case 'string':
+    case 'bool':
+    case 'float32':
+    case 'float64':
+      return type
+    default:
+      return intWidths[type as FuzzType] !== undefined ?
+          (type as FuzzType)
+        : null
+  }
+}
+
+function isStringLiteral(s: string): boolean {
+  return /^("(?:[^"\\]|\\.)*"|`[^`]*`)$/s.test(s)
+}
+
+function isIntLiteral(s: string): boolean {
+  return /^-?\s*(0[xX][0-9a-fA-F_]+|0[bB][01_]+|0[oO]?[0-7_]*|[1-9][0-9_]*)$/.test(
+    s,
+  )
+}
+
+function parseInteger(literal: string, type: FuzzType): bigint {
+  let text = literal.replace(/\s/g, '').replace(/_/g, '')
+  let negative = false
+  if (text.startsWith('-')) {
+    negative = true
+    text = text.slice(1)
+  }
+  if (/^0[0-7]+$/.test(text)) {
+    text = '0o' + text.slice(1)
+  }
+  let value = BigInt(text)
+  if (negative) {
+    value = -value
+  }
+  const [bits, signed] = intWidths[type]!
+  const min = signed ? -(1n << BigInt(bits - 1)) : 0n
+  const max = signed ? (1n << BigInt(bits - 1)) - 1n : (1n << BigInt(bits)) - 1n
+  if (value < min || value > max) {
+    throw new Error(
+      'strconv.Parse' +
+        (signed ? 'Int' : 'Uint') +
+        ': parsing ' +
+        JSON.stringify(literal) +
+        ': value out of range',
+    )
+  }
+  return value
+}
+
+function parseFloatLiteral(literal: string): number {
+  const text = literal.replace(/\s/g, '')
+  switch (text) {
+    case 'NaN':
+      return NaN
+    case '+Inf':
+    case 'Inf':
+      return Infinity
+    case '-Inf':
+      return -Infinity
+  }
+  if (!/^-?([0-9_]+\.?[0-9_]*|\.[0-9_]+)([eE][+-]?[0-9_]+)?$/.test(text)) {
+    throw new Error('float or integer literal required for float type')
+  }
+  return Number(text.replace(/_/g, ''))
+}
+
+// formatFloat formats v like fmt's %v for a float of type.
+function formatFloat(v: number, type: 'float32' | 'float64'): string {
+  if (Number.isNaN(v)) {
+    return 'NaN'
+  }
+  if (v === Infinity) {
+    return '+Inf'
+  }
+  if (v === -Infinity) {
+    return '-Inf'
+  }
+  if (v === 0) {
+    return Object.is(v, -0) ? '-0' : '0'
+  }
+  let digits = String(v)
+  if (type === 'float32') {
+    for (let precision = 1; precision <= 9; precision++) {
+      const candidate = Number(roundToEven(v, precision))
+      if (Math.fround(candidate) === v) {
+        digits = String(candidate)
+        break
+      }
+    }
+  }
+  // Like strconv's shortest 'g' format, %v switches to exponent form below
+  // 1e-4 and from 1e6.
+  const [mantissa, exponent] = Number(digits).toExponential().split('e')
+  const exp = Number(exponent)
+  if (exp < -4 || exp >= 6) {
+    const sign = exp < 0 ? '-' : '+'
+    return mantissa + 'e' + sign + String(Math.abs(exp)).padStart(2, '0')
+  }
+  return Number(digits).toFixed(Math.max(0, fractionDigits(mantissa) - exp))
+}
+
+// roundToEven is v.toPrecision(precision) with ties rounded to an even last
+// digit, as strconv rounds, instead of away from zero.
+function roundToEven(v: number, precision: number): string {
+  const rounded = v.toPrecision(precision)
+  const exact = v.toPrecision(precision + 1)
+  const [mantissa] = exact.split('e')
+  if (!mantissa.endsWith('5') || Number(exact) !== v) {
+    return rounded
+  }
+  const truncated = Number(
+    v.toPrecision(precision + 1).replace(/5(e|$)/, '0$1'),
+  ).toPrecision(precision)
+  const last = truncated.split('e')[0].replace(/[^0-9]/g, '').slice(-1)
+  return Number(last) % 2 === 0 ? truncated : rounded
+}
+
+function fractionDigits(mantissa: string): number {
+  const dot = mantissa.indexOf('.')
+  return dot < 0 ? 0 : mantissa.length - dot - 1
+}
+
+// quoteBytes quotes b like strconv.Quote, escaping invalid UTF-8 as \x.
+function quoteBytes(b: Uint8Array): string {
+  let out = '"'
+  for (let i = 0; i < b.length; ) {
+    const [r, size] = decodeRune(b, i)
+    if (r < 0) {
+      out += '\\x' + hex(b[i], 2)
+    } else {
+      out += escapeRune(r, '"')
+    }
+    i += size
+  }
+  return out + '"'
+}
+
+function quoteRune(r: number): string {
+  return "'" + escapeRune(validRune(r) ? r : 0xfffd, "'") + "'"
+}
+
+function escapeRune(r: number, quote: string): string {
+  const ch = String.fromCodePoint(r)
+  if (ch === quote || ch === '\\') {
+    return '\\' + ch
+  }
+  if (isPrint(ch)) {
+    return ch
+  }
+  switch (ch) {
+    case '\x07':
+      return '\\a'
+    case '\b':
+      return '\\b'
+    case '\f':
+      return '\\f'
+    case '\n':
+      return '\\n'
+    case '\r':
+      return '\\r'
+    case '\t':
+      return '\\t'
+    case '\v':
+      return '\\v'
+  }
+  if (r < 0x20 || r === 0x7f) {
+    return '\\x' + hex(r, 2)
+  }
+  if (r < 0x10000) {
+    return '\\u' + hex(r, 4)
+  }
+  return '\\U' + hex(r, 8)
+}
+
+// isPrint reports whether ch is printable by Go's definition: a letter,
+// mark, number, punctuation, symbol, or the ASCII space.
+function isPrint(ch: string): boolean {
+  return ch === ' ' || /^[\p{L}\p{M}\p{N}\p{P}\p{S}]$/u.test(ch)
+}
+
+function validRune(r: number): boolean {
+  return (
+    Number.isInteger(r) &&
+    r >= 0 &&
+    r <= 0x10ffff &&
+    !(r >= 0xd800 && r <= 0xdfff)
+  )
+}
+
+function hex(n: number, width: number): string {
+  return n.toString(16).padStart(width, '0')
+}
+
+// decodeRune decodes the UTF-8 sequence at b[i]. It returns -1 and size 1
+// for an invalid sequence.
+function decodeRune(b: Uint8Array, i: number): [number, number] {
+  const c = b[i]
+  if (c < 0x80) {
+    return [c, 1]
+  }
+  let size = 0
+  let r = 0
+  let min = 0
+  if (c >= 0xc2 && c <= 0xdf) {
+    size = 2
+    r = c & 0x1f
+    min = 0x80
+  } else if (c >= 0xe0 && c <= 0xef) {
+    size = 3
+    r = c & 0x0f
+    min = 0x800
+  } else if (c >= 0xf0 && c <= 0xf4) {
+    size = 4
+    r = c & 0x07
+    min = 0x10000
+  } else {
+    return [-1, 1]
+  }
+  if (i + size > b.length) {
+    return [-1, 1]
+  }
+  for (let k = 1; k < size; k++) {
+    const cc = b[i + k]
+    if ((cc & 0xc0) !== 0x80) {
+      return [-1, 1]
+    }
+    r = (r << 6) | (cc & 0x3f)
+  }
+  if (r < min || !validRune(r)) {
+    return [-1, 1]
+  }
+  return [r, size]
+}
+
+// unquote decodes a Go string literal to its bytes.
+function unquote(literal: string): Uint8Array {
+  const encoder = new TextEncoder()
+  if (literal.startsWith('`')) {
+    return encoder.encode(literal.slice(1, -1).replace(/\r/g, ''))
+  }
+  const out: number[] = []
+  const body = literal.slice(1, -1)
+  for (let i = 0; i < body.length; ) {
+    if (body[i] !== '\\') {
+      const cp = body.codePointAt(i)!
+      const ch = String.fromCodePoint(cp)
+      out.push(...encoder.encode(ch))
+      i += ch.length
+      continue
+    }
+    const [value, size, isByte] = unescape(body, i, '"')
+    if (isByte) {
+      out.push(value)
+    } else {
+      out.push(...encoder.encode(String.fromCodePoint(value)))
+    }
+    i += size
+  }
+  return Uint8Array.from(out)
+}
+
+// unquoteChar decodes a Go rune literal.
+function unquoteChar(literal: string): number {
+  if (literal.length < 3 || !literal.endsWith("'")) {
+    throw new Error('malformed character literal, missing single quotes')
+  }
+  const body = literal.slice(1, -1)
+  let value: number
+  let size: number
+  if (body[0] === '\\') {
+    ;[value, size] = unescape(body, 0, "'")
+  } else {
+    value = body.codePointAt(0)!
+    size = String.fromCodePoint(value).length
+  }
+  if (size !== body.length) {
+    throw new Error('invalid syntax')
+  }
+  return value
+}
+
+// unescape decodes the escape sequence at s[i]. isByte reports a \x or octal
+// escape, which stands for a byte rather than a rune.
+function unescape(
+  s: string,
+  i: number,
+  quote: string,
+): [value: number, size: number, isByte: boolean] {
+  const c = s[i + 1]
+  switch (c) {
+    case 'a':
+      return [0x07, 2, false]
+    case 'b':
+      return [0x08, 2, false]
+    case 'f':
+      return [0x0c, 2, false]
+    case 'n':
+      return [0x0a, 2, false]
+    case 'r':
+      return [0x0d, 2, false]
+    case 't':
+      return [0x09, 2, false]
+    case 'v':
+      return [0x0b, 2, false]
+    case '\\':
+      return [0x5c, 2, false]
+    case 'x':
+    case 'u':
+    case 'U': {
+      const width =
+        c === 'x' ? 2
+        : c === 'u' ? 4
+        : 8
+      const digits = s.slice(i + 2, i + 2 + width)
+      if (!new RegExp('^[0-9a-fA-F]{' + width + '}$').test(digits)) {
+        throw new Error('invalid syntax')
+      }
+      const value = parseInt(digits, 16)
+      if (c !== 'x' && !validRune(value)) {
+        throw new Error('invalid syntax')
+      }
+      return [value, 2 + width, c === 'x']
+    }
+  }
+  if (c === quote) {
+    return [c.charCodeAt(0), 2, false]
+  }
+  const octal = s.slice(i + 1, i + 4)
+  if (/^[0-7]{3}$/.test(octal) && parseInt(octal, 8) < 256) {
+    return [parseInt(octal, 8), 4, true]
+  }
+  throw new Error('invalid syntax')
+}
+
+// wrapInt truncates v to the width of the integer type, like a Go
+// conversion.
+function wrapInt(type: FuzzType, v: bigint): bigint {
+  const [bits, signed] = intWidths[type]!
+  return signed ? BigInt.asIntN(bits, v) : BigInt.asUintN(bits, v)
+}
+
+// Interesting values bias mutations toward the edges where JavaScript
+// numbers, strings, and byte slices tend to diverge from Go.
+const interestingInts = [
+  0n,
+  1n,
+  -1n,
+  0x7fn,
+  0x80n,
+  0xffn,
+  0x7fffn,
+  0x8000n,
+  0xffffn,
+  0x7fffffffn,
+  0x80000000n,
+  0xffffffffn,
+  (1n << 53n) - 1n,
+  1n << 53n,
+  (1n << 53n) + 1n,
+  0x7fffffffffffffffn,
+  -0x8000000000000000n,
+  0xffffffffffffffffn,
+]
+
+const interestingFloats = [
+  0,
+  -0,
+  1,
+  -1,
+  0.1,
+  Infinity,
+  -Infinity,
+  NaN,
+  Number.MAX_VALUE,
+  Number.MIN_VALUE,
+  Number.EPSILON,
+  2 ** 53,
+  2 ** 53 + 2,
+  2 ** 63,
+  2 ** 64,
+]
+
+const interestingBytes = [
+  [0x00],
+  [0xff],
+  [0x80],
+  [0xc0, 0x80],
+  [0xed, 0xa0, 0x80],
+  [0xef, 0xbf, 0xbd],
+  [0xf0, 0x9f, 0x98, 0x80],
+  [0xf4, 0x90, 0x80, 0x80],
+]
+
+// Mutator randomly changes fuzz inputs. Like Go's mutator it changes one
+// value of an entry per step.
+export class Mutator {
+  private state: number
+
+  constructor(seed = Date.now()) {
+    this.state = seed >>> 0 || 1
+  }
+
+  // mutate changes one randomly chosen value of values in place.
+  public mutate(values: CorpusValue[], maxBytes = 1 << 20): void {
+    const v = values[this.rand(values.length)]
+    switch (v.type) {
+      case '[]byte':
+      case 'string':
+        v.value = this.mutateBytes(v.value as Uint8Array, maxBytes)
+        return
+      case 'bool':
+        v.value = !v.value
+        return
+      case 'float32':
+      case 'float64': {
+        const f = this.mutateFloat(v.value as number)
+        v.value = v.type === 'float32' ? Math.fround(f) : f
+        return
+      }
+      default:
+        v.value = wrapInt(v.type, this.mutateInt(v.value as bigint))
+    }
+  }
+
+  private mutateInt(v: bigint): bigint {
+    switch (this.rand(4)) {
+      case 0:
+        return v + BigInt(1 + this.rand(100))
+      case 1:
+        return v - BigInt(1 + this.rand(100))
+      case 2:
+        return v ^ (1n << BigInt(this.rand(64)))
+      default:
+        return interestingInts[this.rand(interestingInts.length)]
+    }
+  }
+
+  private mutateFloat(v: number): number {
+    switch (this.rand(5)) {
+      case 0:
+        return v + (1 + this.rand(100))
+      case 1:
+        return v - (1 + this.rand(100))
+      case 2:
+        return v * (1 + this.rand(100))
+      case 3:
+        return v / (1 + this.rand(100))
+      default:
+        return interestingFloats[this.rand(interestingFloats.length)]
+    }
+  }
+
+  private mutateBytes(b: Uint8Array, maxBytes: number): Uint8Array {
+    const out = Array.from(b)
+    const op = out.length === 0 ? 0 : this.rand(7)
+    switch (op) {
+      case 0: {
+        // Insert random bytes.
+        const n = 1 + this.rand(Math.min(8, Math.max(1, maxBytes - out.length)))
+        const pos = this.rand(out.length + 1)
+        const inserted = Array.from({ length: n }, () => this.rand(256))
+        out.splice(pos, 0, ...inserted)
+        break
+      }
+      case 1: {
+        // Remove a range.
+        const pos = this.rand(out.length)
+        out.splice(pos, 1 + this.rand(out.length - pos))
+        break
+      }
+      case 2: {
+        // Duplicate a range.
+        const pos = this.rand(out.length)
+        const n = 1 + this.rand(Math.min(16, out.length - pos))
+        out.splice(this.rand(out.length + 1), 0, ...out.slice(pos, pos + n))
+        break
+      }
+      case 3:
+        out[this.rand(out.length)] ^= 1 << this.rand(8)
+        break
+      case 4:
+        out[this.rand(out.length)] = this.rand(256)
+        break
+      case 5: {
+        const i = this.rand(out.length)
+        const j = this.rand(out.length)
+        ;[out[i], out[j]] = [out[j], out[i]]
+        break
+      }
+      default: {
+        // Insert a byte sequence that is interesting as UTF-8.
+        const seq = interestingBytes[this.rand(interestingBytes.length)]
+        out.splice(this.rand(out.length + 1), 0, ...seq)
+      }
+    }
+    return Uint8Array.from(out.slice(0, maxBytes))
+  }
+
+  // rand returns a pseudo-random number in [0, n) from an xorshift32
+  // generator.
+  public rand(n: number): number {
+    let x = this.state
+    x ^= x << 13
+    x ^= x >>> 17
+    x ^= x << 5
+    this.state = x >>> 0
+    return this.state % n
+  }
+}
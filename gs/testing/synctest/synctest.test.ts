@@ -0,0 +1,111 @@
+import { describe, expect, it } from 'vitest'
+
+import * as $ from '@goscript/builtin/index.js'
+import * as time from '@goscript/time/index.js'
+
+import { T } from '../testing.js'
+import { Sleep, Test, Wait } from './synctest.js'
+
+describe('synctest.Test', () => {
+  it('runs f in a bubble with a clock of its own', async () => {
+    const t = new T('TestBubble')
+    let start = ''
+    let elapsed = 0n
+
+    await Test(t, async (t2) => {
+      expect(t2.Name()).toBe('TestBubble')
+      start = time.Now().UTC().Format(time.RFC3339)
+      const begin = time.Now()
+      await time.Sleep(time.Hour)
+      elapsed = time.Since(begin)
+    })
+
+    expect(start).toBe('2000-01-01T00:00:00Z')
+    expect(elapsed).toBe(time.Hour)
+    expect(t.Failed()).toBe(false)
+    expect(time.Now().Year()).toBeGreaterThan(2020)
+  })
+
+  it('waits for the goroutines of the bubble to block', async () => {
+    const t = new T('TestWait')
+    const events: string[] = []
+
+    await Test(t, async () => {
+      const ch = $.makeChannel<string>(0, '', 'both')
+      $.go(async () => {
+        events.push(await ch.receive())
+      })
+      await Wait()
+      events.push('blocked')
+      await ch.send('received')
+      await Wait()
+
+      let fired = false
+      time.AfterFunc(time.Second, () => {
+        fired = true
+      })
+      await Sleep(time.Second - 1n)
+      events.push('fired ' + fired)
+      await Sleep(1n)
+      events.push('fired ' + fired)
+    })
+
+    expect(events).toEqual([
+      'blocked',
+      'received',
+      'fired false',
+      'fired true',
+    ])
+  })
+
+  it('waits for goroutines that outlive f', async () => {
+    const t = new T('TestOutlive')
+    let done = false
+
+    await Test(t, async (t2) => {
+      t2.Cleanup(() => {
+        expect(done).toBe(false)
+      })
+      $.go(async () => {
+        await time.Sleep(time.Minute)
+        done = true
+      })
+    })
+
+    expect(done).toBe(true)
+  })
+
+  it('fails t when f fails', async () => {
+    const t = new T('TestFail')
+
+    await expect(
+      Test(t, (t2) => {
+        t2.Error('boom')
+      }),
+    ).rejects.toThrow('test failed')
+    expect(t.Failed()).toBe(true)
+    expect(t.hasLogs()).toBe(true)
+
+    const nested = new T('TestNested')
+    await expect(
+      Test(nested, async (t2) => {
+        await t2.Run('sub', () => {})
+      }),
+    ).rejects.toThrow('test failed')
+    expect(nested.Failed()).toBe(true)
+  })
+
+  it('panics when the bubble deadlocks', async () => {
+    const t = new T('TestDeadlock')
+
+    await expect(
+      Test(t, async () => {
+        await $.makeChannel<number>(0, 0, 'both').receive()
+      }),
+    ).rejects.toThrow('deadlock: all goroutines in bubble are blocked')
+  })
+
+  it('panics when Wait is called outside a bubble', async () => {
+    await expect(Wait()).rejects.toThrow('goroutine is not in a bubble')
+  })
+})
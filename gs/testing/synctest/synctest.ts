@@ -0,0 +1,47 @@
+import * as $ from '@goscript/builtin/index.js'
+import * as testing from '@goscript/testing/index.js'
+import * as time from '@goscript/time/index.js'
+
+// Test runs f in a new bubble, with a test of its own that shares the name
+// and log of t. Time in the bubble is virtual: it starts at midnight UTC on
+// January 1, 2000 and moves to the next timer once every goroutine in the
+// bubble is durably blocked. Test returns once every goroutine started in
+// the bubble has exited, and panics if they deadlock.
+export async function Test(
+  t: testing.T | null,
+  f: testing.TestFunc | null,
+): Promise<void> {
+  let ok = false
+  try {
+    await $.park(
+      'synctest.Run',
+      $.runBubble(async () => {
+        ok = await t!.runSynctest(f!)
+      }, 'testing/synctest.Test'),
+    )
+  } catch (err) {
+    if (err instanceof $.BubbleDeadlockError) {
+      $.panic(err)
+    }
+    throw err
+  }
+  if (!ok) {
+    // A failure inside the bubble fails t once the bubble has ended.
+    t!.FailNow()
+  }
+}
+
+// Wait blocks until every other goroutine in the current bubble is durably
+// blocked: on a channel, a select, a timer, sync.Cond.Wait or
+// sync.WaitGroup.Wait. It panics when called outside a bubble, or while
+// another goroutine of the bubble is already waiting.
+export async function Wait(): Promise<void> {
+  await $.bubbleWait()
+}
+
+// Sleep pauses the goroutine for d on the bubble's clock, then waits for the
+// other goroutines of the bubble to block, as time.Sleep followed by Wait.
+export async function Sleep(d: time.Duration): Promise<void> {
+  await time.Sleep(d)
+  await Wait()
+}
@@ -1,9 +1,21 @@
 import { describe, expect, it } from 'vitest'
-import { existsSync, writeFileSync } from 'node:fs'
+import {
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  readdirSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs'
+import { tmpdir } from 'node:os'
 import { join } from 'node:path'
 
-import { B, F, Short, T, type TB } from './testing.js'
+import * as $ from '@goscript/builtin/index.js'
+
+import { B, F, M, PB, Short, T, type TB } from './testing.js'
 import { runTests } from './testing.js'
+import { unmarshalCorpusFile } from './fuzz.js'
 
 describe('testing.T', () => {
   it('runs passing subtests', async () => {
@@ -45,7 +57,6 @@ describe('testing.T', () => {
 
   it('exports the benchmark and fuzz test surfaces used by compiled tests', async () => {
     const b = new B('bench')
-    b.N = 2
     b.ReportAllocs()
     b.ResetTimer()
     b.SetBytes(12)
@@ -59,7 +70,7 @@ describe('testing.T', () => {
 
     const f = new F('fuzz')
     f.Add('seed')
-    f.Fuzz(() => {})
+    await f.Fuzz(() => {})
 
     const tb: TB = b
     tb.Helper()
@@ -69,6 +80,332 @@ describe('testing.T', () => {
     expect(Short()).toBe(false)
   })
 
+  it('reports subtests below their test in verbose runs', async () => {
+    const messages = await captureLogs(() =>
+      runTests(
+        'example.test/verbose',
+        [
+          {
+            name: 'TestParent',
+            fn: async (t) => {
+              await t.Run('ok', async (child) => {
+                child.Log('hello')
+                await child.Run('deep', () => {})
+              })
+              await t.Run('skip', (child) => child.SkipNow())
+              await t.Run('bad', (child) => child.Error('boom'))
+            },
+          },
+        ],
+        { verbose: true },
+      ),
+    )
+
+    expect(
+      messages.map((line) => line.replace(/\(\d+\.\d\ds\)/, '(0.00s)')),
+    ).toEqual([
+      '=== RUN   TestParent',
+      '=== RUN   TestParent/ok',
+      '=== RUN   TestParent/ok/deep',
+      '    hello',
+      '=== RUN   TestParent/skip',
+      '=== RUN   TestParent/bad',
+      '    boom',
+      '--- FAIL: TestParent (0.00s)',
+      '    --- PASS: TestParent/ok (0.00s)',
+      '        --- PASS: TestParent/ok/deep (0.00s)',
+      '    --- SKIP: TestParent/skip (0.00s)',
+      '    --- FAIL: TestParent/bad (0.00s)',
+      'FAIL\texample.test/verbose',
+    ])
+  })
+
+  it('prints go test -bench result lines after the tests pass', async () => {
+    const ns: number[] = []
+    const messages = await captureLogs(() =>
+      runTests(
+        'example.test/bench',
+        [{ name: 'TestOK', fn: () => {} }],
+        {
+          bench: ['.'],
+          benchIters: 3,
+          benchmarks: [
+            {
+              name: 'BenchmarkAdd',
+              fn: (b) => {
+                ns.push(b.N)
+                b.ReportMetric(2, 'items/op')
+              },
+            },
+          ],
+        },
+      ),
+    )
+
+    // run1 probes with b.N = 1 before the measured -benchtime=3x run.
+    expect(ns).toEqual([1, 3])
+    expect(messages.slice(0, 3)).toEqual([
+      'goos: js',
+      'goarch: wasm',
+      'pkg: example.test/bench',
+    ])
+    expect(messages.at(-2)).toMatch(
+      /^BenchmarkAdd \t {7}3(\t.+ ns\/op)?\t {9}2\.000 items\/op$/,
+    )
+    expect(messages.at(-1)).toBe('PASS')
+  })
+
+  it('skips benchmarks when a test fails', async () => {
+    let ran = false
+    const messages = await captureLogs(() =>
+      runTests(
+        'example.test/bench-fail',
+        [{ name: 'TestFail', fn: (t) => t.Fail() }],
+        {
+          bench: ['.'],
+          benchmarks: [{ name: 'BenchmarkSkipped', fn: () => void (ran = true) }],
+        },
+      ),
+    )
+
+    expect(ran).toBe(false)
+    expect(messages.at(-1)).toBe('FAIL\texample.test/bench-fail')
+  })
+
+  it('scales b.Loop benchmarks to the iteration target', async () => {
+    const loops: number[] = []
+    const messages = await captureLogs(() =>
+      runTests('example.test/loop', [], {
+        bench: ['.'],
+        benchIters: 5,
+        benchmarks: [
+          {
+            name: 'BenchmarkLoop',
+            fn: (b) => {
+              let i = 0
+              while (b.Loop()) {
+                i++
+              }
+              loops.push(i)
+            },
+          },
+        ],
+      }),
+    )
+
+    // A b.Loop benchmark is measured by its first run.
+    expect(loops).toEqual([5])
+    expect(messages.at(-2)).toMatch(/^BenchmarkLoop \t {7}5(\t.+ ns\/op)?$/)
+  })
+
+  it('matches sub-benchmarks against the later -bench levels', async () => {
+    const ran: string[] = []
+    const messages = await captureLogs(() =>
+      runTests('example.test/sub', [], {
+        bench: ['.', 'fast'],
+        benchIters: 1,
+        benchmarks: [
+          {
+            name: 'BenchmarkSizes',
+            fn: async (b) => {
+              for (const size of ['fast', 'slow']) {
+                await b.Run(size, (sub) => {
+                  ran.push(sub.Name())
+                })
+              }
+            },
+          },
+        ],
+      }),
+    )
+
+    expect(ran).toEqual(['BenchmarkSizes/fast'])
+    expect(
+      messages.filter((message) => message.startsWith('Benchmark')),
+    ).toHaveLength(1)
+    expect(messages.at(-2)).toMatch(/^BenchmarkSizes\/fast +\t {7}1/)
+  })
+
+  it('shares b.N between RunParallel bodies', async () => {
+    let iterations = 0
+    const result = await captureLogs(() =>
+      runTests('example.test/parallel', [], {
+        bench: ['.'],
+        benchIters: 10,
+        benchmarks: [
+          {
+            name: 'BenchmarkParallel',
+            fn: async (b) => {
+              b.SetParallelism(4)
+              await b.RunParallel((pb) => {
+                while (pb.Next()) {
+                  iterations++
+                }
+              })
+            },
+          },
+        ],
+      }),
+    )
+
+    expect(iterations).toBe(11)
+    expect(result.at(-1)).toBe('PASS')
+  })
+
+  it('hands out PB iterations in grains', () => {
+    const counter = { n: 0 }
+    const first = new PB(counter, 2, 3)
+    const second = new PB(counter, 2, 3)
+
+    expect([first.Next(), first.Next()]).toEqual([true, true])
+    expect([second.Next(), second.Next()]).toEqual([true, false])
+    expect(first.Next()).toBe(false)
+  })
+
+  it('replays f.Add seeds and testdata corpus files as subtests', async () => {
+    const seen: [string, unknown, unknown][] = []
+    await inTempDir(async (dir) => {
+      mkdirSync(join(dir, 'testdata/fuzz/FuzzPair'), { recursive: true })
+      writeFileSync(
+        join(dir, 'testdata/fuzz/FuzzPair/corpus1'),
+        'go test fuzz v1\n[]byte("\\xff")\nint64(-7)\n',
+      )
+      const result = await captureLogs(() =>
+        runTests('example.test/fuzz', [], {
+          fuzzTargets: [{ name: 'FuzzPair', fn: fuzzPair(seen) }],
+        }),
+      )
+      expect(result).toEqual([])
+    })
+
+    expect(seen).toEqual([
+      ['FuzzPair/seed#0', new Uint8Array([1, 2]), 3n],
+      ['FuzzPair/corpus1', new Uint8Array([0xff]), -7n],
+    ])
+  })
+
+  it('selects corpus entries with the second -run level', async () => {
+    const seen: [string, unknown, unknown][] = []
+    await inTempDir(() =>
+      runTests('example.test/fuzz', [], {
+        fuzzTargets: [{ name: 'FuzzPair', fn: fuzzPair(seen, 2) }],
+        run: ['FuzzPair', 'seed#1'],
+      }),
+    )
+
+    expect(seen.map(([name]) => name)).toEqual(['FuzzPair/seed#1'])
+  })
+
+  it('fails a fuzz target whose corpus does not match its arguments', async () => {
+    let ran = false
+    const messages = await inTempDir(async (dir) => {
+      mkdirSync(join(dir, 'testdata/fuzz/FuzzPair'), { recursive: true })
+      writeFileSync(
+        join(dir, 'testdata/fuzz/FuzzPair/bad'),
+        'go test fuzz v1\nstring("x")\nint64(1)\n',
+      )
+      return captureLogs(() =>
+        runTests('example.test/fuzz', [], {
+          fuzzTargets: [
+            {
+              name: 'FuzzPair',
+              fn: async (f) => {
+                await f.Fuzz(pairFunc(() => (ran = true)))
+              },
+            },
+          ],
+        }),
+      )
+    })
+
+    expect(ran).toBe(false)
+    expect(messages[0]).toBe(
+      '    "testdata/fuzz/FuzzPair/bad": mismatched types in corpus entry: [string int64], want [[]uint8 int64]',
+    )
+    expect(messages.slice(1)).toEqual([
+      '--- FAIL: FuzzPair (0.00s)',
+      'FAIL\texample.test/fuzz',
+    ])
+  })
+
+  it('fuzzes until an input fails and writes it to the corpus', async () => {
+    let corpus: string[] = []
+    let failing = ''
+    const messages = await inTempDir(async (dir) => {
+      const messages = await captureLogs(() =>
+        runTests('example.test/fuzz', [], {
+          fuzzTargets: [
+            {
+              name: 'FuzzShort',
+              fn: async (f) => {
+                f.Add('ab')
+                await f.Fuzz(
+                  $.functionValue(
+                    (t: T, s: string) => {
+                      if ($.len(s) > 3) {
+                        t.Fatalf('too long: %q', s)
+                      }
+                    },
+                    {
+                      kind: $.TypeKind.Function,
+                      params: [
+                        { kind: $.TypeKind.Pointer, elemType: 'testing.T' },
+                        { kind: $.TypeKind.Basic, name: 'string' },
+                      ],
+                      results: [],
+                    },
+                  ),
+                )
+              },
+            },
+          ],
+          fuzz: 'Short',
+          fuzzIters: 100000,
+        }),
+      )
+      corpus = readdirSync(join(dir, 'testdata/fuzz/FuzzShort'))
+      failing = readFileSync(
+        join(dir, 'testdata/fuzz/FuzzShort', corpus[0]),
+        'utf8',
+      )
+      return messages
+    })
+
+    expect(corpus).toHaveLength(1)
+    expect(corpus[0]).toMatch(/^[0-9a-f]{16}$/)
+    const [value] = unmarshalCorpusFile(failing)
+    expect(value.type).toBe('string')
+    expect((value.value as Uint8Array).length > 3).toBe(true)
+    expect(messages[0]).toBe(
+      'fuzz: elapsed: 0s, testing seed corpus: 0/1 completed',
+    )
+    expect(messages[1]).toBe(
+      'fuzz: elapsed: 0s, testing seed corpus: 1/1 completed, now fuzzing with 1 workers',
+    )
+    expect(messages).toContain(
+      '    Failing input written to testdata/fuzz/FuzzShort/' + corpus[0],
+    )
+    expect(messages).toContain(
+      '    goscript test -run=FuzzShort/' + corpus[0],
+    )
+    expect(messages.at(-1)).toBe('FAIL\texample.test/fuzz')
+  })
+
+  it('will not fuzz when -fuzz matches several fuzz targets', async () => {
+    const target = { name: 'FuzzA', fn: async () => {} }
+    const messages = await captureLogs(() =>
+      runTests('example.test/fuzz', [], {
+        fuzzTargets: [target, { ...target, name: 'FuzzB' }],
+        fuzz: 'Fuzz',
+      }),
+    )
+
+    expect(messages).toEqual([
+      'testing: will not fuzz, -fuzz matches more than one fuzz test: [FuzzA FuzzB]',
+      'FAIL\texample.test/fuzz',
+    ])
+  })
+
   it('reports short mode while a short run is active', async () => {
     let observed = false
 
@@ -90,6 +427,51 @@ describe('testing.T', () => {
     expect(Short()).toBe(false)
   })
 
+  it('stops a hung test at the deadline with a goroutine dump', async () => {
+    const messages: string[] = []
+    const originalLog = console.log
+    console.log = (message?: unknown) => {
+      messages.push(String(message))
+    }
+    let timer: $.HostTimer | undefined
+    let ranNext = false
+    let result
+    try {
+      result = await runTests(
+        'example.test/hang',
+        [
+          {
+            name: 'TestHang',
+            fn: () =>
+              $.park(
+                'chan receive',
+                new Promise<void>((resolve) => {
+                  timer = $.hostTimeout(resolve, 60_000)
+                }),
+              ),
+          },
+          {
+            name: 'TestNext',
+            fn: () => {
+              ranNext = true
+            },
+          },
+        ],
+        { timeout: '20ms', deadline: Date.now() + 20 },
+      )
+    } finally {
+      console.log = originalLog
+      timer?.cancel()
+    }
+
+    expect(result.ok).toBe(false)
+    expect(result.timedOut).toBe(true)
+    expect(ranNext).toBe(false)
+    expect(messages[0]).toMatch(
+      /^panic: test timed out after 20ms\n\trunning tests:\n\t\tTestHang \(0s\)\n\ngoroutine \d+ \[chan receive\]:\nexample\.test\/hang\.TestHang\(\.\.\.\)/,
+    )
+  })
+
   it('accepts parallel tests in the sequential runner', () => {
     const t = new T('root')
 
@@ -175,6 +557,162 @@ describe('testing.T', () => {
     ).rejects.toBe(exit)
   })
 
+  it('runs parallel subtests together once their parent returns', async () => {
+    let release = () => {}
+    const released = new Promise<void>((resolve) => {
+      release = resolve
+    })
+    const order: string[] = []
+    const messages = await captureLogs(() =>
+      runTests(
+        'example.test/parallel',
+        [
+          {
+            name: 'TestParallel',
+            fn: async (t) => {
+              await t.Run('wait', async (child) => {
+                await child.Parallel()
+                await $.park('chan receive', released)
+                order.push('wait')
+              })
+              await t.Run('send', async (child) => {
+                await child.Parallel()
+                order.push('send')
+                release()
+              })
+              order.push('parent')
+            },
+          },
+        ],
+        { verbose: true },
+      ),
+    )
+
+    expect(order).toEqual(['parent', 'send', 'wait'])
+    expect(
+      messages.map((line) => line.replace(/\(\d+\.\d\ds\)/, '(0.00s)')),
+    ).toEqual([
+      '=== RUN   TestParallel',
+      '=== RUN   TestParallel/wait',
+      '=== PAUSE TestParallel/wait',
+      '=== RUN   TestParallel/send',
+      '=== PAUSE TestParallel/send',
+      '=== CONT  TestParallel/wait',
+      '=== CONT  TestParallel/send',
+      '--- PASS: TestParallel (0.00s)',
+      '    --- PASS: TestParallel/send (0.00s)',
+      '    --- PASS: TestParallel/wait (0.00s)',
+      'PASS',
+    ])
+  })
+
+  it('runs parallel top-level tests after the sequential ones', async () => {
+    const order: string[] = []
+    const result = await captureLogs(() =>
+      runTests('example.test/parallel-top', [
+        {
+          name: 'TestA',
+          fn: async (t) => {
+            await t.Parallel()
+            order.push('A')
+            t.Error('boom')
+          },
+        },
+        { name: 'TestB', fn: () => void order.push('B') },
+      ]),
+    )
+
+    expect(order).toEqual(['B', 'A'])
+    expect(result.map((line) => line.replace(/\(\d+\.\d\ds\)/, '(0.00s)'))).toEqual([
+      '    boom',
+      '--- FAIL: TestA (0.00s)',
+      'FAIL\texample.test/parallel-top',
+    ])
+  })
+
+  it('panics when t.Parallel is called twice or followed by t.Setenv', async () => {
+    const t = new T('root')
+    await t.Parallel()
+
+    await expect(t.Parallel()).rejects.toThrow('t.Parallel called multiple times')
+    expect(() => t.Setenv('GOSCRIPT_TEST', '1')).toThrow(
+      'cannot set environment variables in parallel tests',
+    )
+  })
+
+  it('checks the output of examples', async () => {
+    const messages = await captureLogs(() =>
+      runTests('example.test/examples', [], {
+        verbose: true,
+        examples: [
+          { name: 'ExampleOK', fn: () => $.println('3'), output: '3\n' },
+          {
+            name: 'ExampleUnordered',
+            fn: () => $.println('b\na'),
+            output: 'a\nb\n',
+            unordered: true,
+          },
+          { name: 'ExampleBad', fn: () => $.println('4'), output: '3' },
+        ],
+      }),
+    )
+
+    expect(
+      messages.map((line) => line.replace(/\(\d+\.\d\ds\)/, '(0.00s)')),
+    ).toEqual([
+      '=== RUN   ExampleOK',
+      '--- PASS: ExampleOK (0.00s)',
+      '=== RUN   ExampleUnordered',
+      '--- PASS: ExampleUnordered (0.00s)',
+      '=== RUN   ExampleBad',
+      '--- FAIL: ExampleBad (0.00s)\ngot:\n4\nwant:\n3',
+      'FAIL\texample.test/examples',
+    ])
+  })
+
+  it('runs the package from TestMain with m.Run', async () => {
+    const steps: string[] = []
+    let code = -1
+    const result = await captureLogs(() =>
+      runTests(
+        'example.test/main',
+        [{ name: 'TestFail', fn: (t) => t.Fail() }],
+        {
+          after: () => void steps.push('after'),
+          testMain: async (m: M) => {
+            steps.push('setup')
+            code = await m.Run()
+            steps.push('teardown')
+          },
+        },
+      ),
+    )
+
+    expect(steps).toEqual(['setup', 'after', 'teardown'])
+    expect(code).toBe(1)
+    expect(result).toEqual([
+      '--- FAIL: TestFail (0.00s)',
+      'FAIL\texample.test/main',
+    ])
+  })
+
+  it('passes the os.Exit code of TestMain on', async () => {
+    const exit = Object.assign(new Error('exit'), { __goscriptExitCode: 3 })
+    const result = await runTests(
+      'example.test/main-exit',
+      [{ name: 'TestOK', fn: () => {} }],
+      {
+        testMain: async (m: M) => {
+          if ((await m.Run()) === 0) {
+            throw exit
+          }
+        },
+      },
+    )
+
+    expect(result.ok).toBe(false)
+  })
+
   it('returns a non-nil context', () => {
     const t = new T('root')
 
@@ -240,3 +778,66 @@ describe('testing.T', () => {
     expect(messages).toEqual(['    err=host path missing'])
   })
 })
+
+// fuzzPair returns a fuzz target that adds seeds of []byte and int64
+// arguments and records the inputs its fuzz function sees.
+function fuzzPair(
+  seen: [string, unknown, unknown][],
+  seeds = 1,
+): (f: F) => Promise<void> {
+  return async (f) => {
+    for (let i = 0; i < seeds; i++) {
+      f.Add(
+        $.interfaceValue(new Uint8Array([1, 2 + i]), '[]byte'),
+        $.namedValueInterfaceValue(BigInt(3 + i), 'int64', {}, {
+          kind: $.TypeKind.Basic,
+          name: 'int64',
+        }),
+      )
+    }
+    await f.Fuzz(pairFunc((t, b, n) => seen.push([t.Name(), b, n])))
+  }
+}
+
+function pairFunc(fn: (t: T, b: Uint8Array, n: bigint) => unknown) {
+  return $.functionValue(fn, {
+    kind: $.TypeKind.Function,
+    params: [
+      { kind: $.TypeKind.Pointer, elemType: 'testing.T' },
+      {
+        kind: $.TypeKind.Slice,
+        elemType: { kind: $.TypeKind.Basic, name: 'uint8' },
+      },
+      { kind: $.TypeKind.Basic, name: 'int64' },
+    ],
+    results: [],
+  })
+}
+
+// inTempDir runs run with an empty temporary directory as the working
+// directory, where fuzz targets look for testdata.
+async function inTempDir<R>(run: (dir: string) => Promise<R>): Promise<R> {
+  const dir = mkdtempSync(join(tmpdir(), 'goscript-fuzz-'))
+  const previous = process.cwd()
+  process.chdir(dir)
+  try {
+    return await run(dir)
+  } finally {
+    process.chdir(previous)
+    rmSync(dir, { force: true, recursive: true })
+  }
+}
+
+async function captureLogs(run: () => Promise<unknown>): Promise<string[]> {
+  const messages: string[] = []
+  const originalLog = console.log
+  console.log = (message?: unknown) => {
+    messages.push(String(message))
+  }
+  try {
+    await run()
+  } finally {
+    console.log = originalLog
+  }
+  return messages
+}
@@ -1,4 +1,19 @@
+import * as $ from '@goscript/builtin/index.js'
 import * as context from '@goscript/context/index.js'
+import * as runtime from '@goscript/runtime/index.js'
+
+import {
+  checkCorpus,
+  corpusValueOf,
+  fuzzArgument,
+  fuzzTypeOfParam,
+  marshalCorpusFile,
+  Mutator,
+  unmarshalCorpusFile,
+  zeroCorpusValue,
+  type CorpusValue,
+  type FuzzType,
+} from './fuzz.js'
 
 export type TestFunc = (t: T) => void | Promise<void>
 export type TB = T | B | F
@@ -12,12 +27,55 @@ export type RunOptions = {
   verbose?: boolean
   count?: number
   short?: boolean
+  // timeout is the -timeout flag as Go formats it, such as "10m0s", and
+  // deadline is when it expires, in milliseconds since the epoch.
+  timeout?: string
+  deadline?: number
+  // benchmarks run after the tests pass. bench is the -bench pattern split
+  // into one regexp per slash-separated name level.
+  benchmarks?: BenchmarkCase[]
+  bench?: string[]
+  // benchTime is the -benchtime target in milliseconds, or benchIters the
+  // iteration count of a -benchtime=Nx flag.
+  benchTime?: number
+  benchIters?: number
+  benchmem?: boolean
+  // fuzzTargets run after the tests with their seed corpora. run is the -run
+  // pattern split like bench; its second level selects corpus entries.
+  fuzzTargets?: FuzzTargetCase[]
+  run?: string[]
+  // fuzz is the -fuzz pattern. The one fuzz target it matches is fuzzed
+  // after the benchmarks for fuzzTime milliseconds or fuzzIters inputs, or
+  // until an input fails. Without either it runs until -timeout.
+  fuzz?: string
+  fuzzTime?: number
+  fuzzIters?: number
+  // examples run after the tests and the fuzz seed corpora.
+  examples?: ExampleCase[]
+  // testMain is the TestMain of the package, which runs everything else
+  // with m.Run. after is called when the run ends, before m.Run returns.
+  testMain?: TestMainFunc
+  after?: () => void
+}
+
+export type ExampleCase = {
+  name: string
+  fn: () => void | Promise<void>
+  // output is the // Output: comment of the example. Unordered output comes
+  // from an // Unordered output: comment.
+  output: string
+  unordered?: boolean
 }
 
+export type TestMainFunc = (m: M) => void | Promise<void>
+
 export type RunResult = {
   ok: boolean
   failed: number
   skipped: number
+  // timedOut reports that a test was still running at the deadline. Its
+  // goroutines are abandoned, so the caller should exit the process.
+  timedOut?: boolean
 }
 
 interface HostProcess {
@@ -43,6 +101,16 @@ class TestControl extends Error {
 
 let shortMode = false
 
+// testPaused is what a test reports to its parent when it calls Parallel.
+const testPaused = Symbol('paused')
+
+// timeoutReported is set once a run reported the -timeout panic, which the
+// parallel tests that were still running share.
+let timeoutReported = false
+
+// chatty is set during a verbose run, which reports subtests like go test -v.
+let chatty = false
+
 export class T {
   private readonly testName: string
   private failed = false
@@ -50,6 +118,24 @@ export class T {
   private logs: string[] = []
   private cleanups: (() => void | Promise<void>)[] = []
   private tempDirs: string[] = []
+  // reports are the result lines of finished subtests, printed indented
+  // below the report of their top-level test.
+  private reports: string[] = []
+  // parent is the test whose body started this one. Top-level tests have the
+  // root of their run as parent.
+  private parent: T | null = null
+  private parallel = false
+  // pause hands control back to the parent once this test calls Parallel.
+  private pause: () => void = () => {}
+  // parallelBarrier holds the parallel subtests until the body of this test
+  // returns, and parallelSubtests report them once they finish.
+  private releaseParallel: () => void = () => {}
+  private readonly parallelBarrier = new Promise<void>((resolve) => {
+    this.releaseParallel = resolve
+  })
+  private parallelSubtests: Promise<boolean>[] = []
+  // synctest is set on the test that synctest.Test runs inside a bubble.
+  private synctest = false
 
   constructor(name: string) {
     this.testName = name
@@ -131,39 +217,146 @@ export class T {
   }
 
   public async Run(name: string, fn: TestFunc): Promise<boolean> {
-    const child = new T(this.testName + '/' + name)
+    if (this.synctest) {
+      $.panic('testing: t.Run called inside synctest bubble')
+    }
+    return this.runChild(new T(this.testName + '/' + name), fn)
+  }
+
+  // runChild runs fn as the subtest child on a goroutine of its own and
+  // reports whether it passed. A subtest that calls Parallel is reported
+  // once it finishes, after the body of this test returns.
+  protected async runChild(child: T, fn: TestFunc): Promise<boolean> {
+    if (chatty) {
+      console.log('=== RUN   ' + child.Name())
+    }
+    const start = Date.now()
+    const paused = new Promise<typeof testPaused>((resolve) =>
+      child.attach(this, () => resolve(testPaused)),
+    )
+    const done = new Promise<unknown>((resolve) => {
+      $.go(async () => resolve(await child.runBody(fn)), 'testing.(*T).Run')
+    })
+    const exit = await $.park('chan receive', Promise.race([done, paused]))
+    if (exit === testPaused) {
+      this.parallelSubtests.push(
+        done.then((exit) => this.reportChild(child, start, exit)),
+      )
+      return !child.Failed()
+    }
+    return this.reportChild(child, start, exit)
+  }
+
+  // reportChild records the result of the finished subtest child. exit is
+  // the error of an os.Exit call that ended it.
+  private reportChild(child: T, start: number, exit: unknown): boolean {
+    if (exit !== null) {
+      throw exit
+    }
+    if (chatty) {
+      const status =
+        child.Failed() ? 'FAIL'
+        : child.Skipped() ? 'SKIP'
+        : 'PASS'
+      this.reports.push(
+        '--- ' +
+          status +
+          ': ' +
+          child.Name() +
+          ' (' +
+          ((Date.now() - start) / 1000).toFixed(2) +
+          's)',
+        ...child.reports.map((line) => '    ' + line),
+      )
+    }
+    if (child.Failed()) {
+      this.Fail()
+      child.flushLogs()
+      return false
+    }
+    if (chatty) {
+      child.flushLogs()
+    }
+    return true
+  }
+
+  // attach makes this test a subtest of parent. pause is called when the test
+  // calls Parallel.
+  public attach(parent: T, pause: () => void): void {
+    this.parent = parent
+    this.pause = pause
+  }
+
+  // runBody runs fn as the body of this test, then resumes its parallel
+  // subtests and waits for them before running the cleanups. It returns the
+  // error of an os.Exit call, which ends the run, or null.
+  public async runBody(fn: TestFunc): Promise<unknown> {
     try {
-      await fn(child)
+      await fn(this)
     } catch (err) {
       if (isProcessExitError(err)) {
-        throw err
+        return err
       }
-      if (err instanceof TestControl && err.kind === 'skip') {
-        // A skipped subtest is still a successful Run result.
-      } else {
-        child.Fail()
-        if (!(err instanceof TestControl)) {
-          child.Log(formatValue(err))
-        }
+      this.recover(err)
+    }
+    this.resumeParallel()
+    if (this.parallelSubtests.length !== 0) {
+      try {
+        await $.park('chan receive', Promise.all(this.parallelSubtests))
+      } catch (err) {
+        return err
       }
     }
     try {
-      await child.runCleanups()
+      await this.runCleanups()
     } catch (err) {
       if (isProcessExitError(err)) {
-        throw err
-      }
-      child.Fail()
-      if (!(err instanceof TestControl)) {
-        child.Log(formatValue(err))
+        return err
       }
+      this.recover(err)
     }
-    if (child.Failed()) {
-      this.Fail()
-      child.flushLogs()
-      return false
+    return null
+  }
+
+  // runSynctest runs fn with a test of its own, as synctest.Test does inside
+  // its bubble. That test shares the name and log of this one, runs its
+  // cleanups in the bubble, and cannot start subtests or run in parallel.
+  // runSynctest reports whether fn passed and throws the error of an os.Exit
+  // call.
+  public async runSynctest(fn: TestFunc): Promise<boolean> {
+    const t = new T(this.testName)
+    t.synctest = true
+    t.logs = this.logs
+    const exit = await t.runBody(fn)
+    if (exit !== null) {
+      throw exit
     }
-    return true
+    return !t.Failed()
+  }
+
+  // resumeParallel lets the subtests that called Parallel continue.
+  public resumeParallel(): void {
+    this.releaseParallel()
+  }
+
+  // recover records err, which ended the body or a cleanup of this test.
+  private recover(err: unknown): void {
+    if (err instanceof TestControl && err.kind === 'skip') {
+      // A skipped test still succeeds.
+      return
+    }
+    this.Fail()
+    if (!(err instanceof TestControl)) {
+      this.Log(formatValue(err))
+    }
+  }
+
+  // flushReports prints the result lines of the finished subtests.
+  public flushReports(): void {
+    for (const line of this.reports) {
+      console.log('    ' + line)
+    }
+    this.reports = []
   }
 
   public TempDir(): string {
@@ -195,9 +388,35 @@ export class T {
     return path
   }
 
-  public Parallel(): void {}
+  // Parallel pauses the test until the body of its parent returns, then
+  // runs it alongside the other parallel subtests of the parent.
+  public async Parallel(): Promise<void> {
+    if (this.parallel) {
+      $.panic('testing: t.Parallel called multiple times')
+    }
+    if (this.synctest) {
+      $.panic('testing: t.Parallel called inside synctest bubble')
+    }
+    this.parallel = true
+    if (this.parent === null) {
+      return
+    }
+    if (chatty) {
+      console.log('=== PAUSE ' + this.testName)
+    }
+    this.pause()
+    await $.park('chan receive', this.parent.parallelBarrier)
+    if (chatty) {
+      console.log('=== CONT  ' + this.testName)
+    }
+  }
 
   public Setenv(key: string, value: string): void {
+    if (this.parallel) {
+      $.panic(
+        'testing: t.Setenv called after t.Parallel; cannot set environment variables in parallel tests',
+      )
+    }
     const env = (globalThis as HostGlobal).process?.env
     if (env === undefined) {
       throw new Error('testing.Setenv is not supported without a host process')
@@ -214,6 +433,11 @@ export class T {
   }
 
   public Chdir(dir: string): void {
+    if (this.parallel) {
+      $.panic(
+        'testing: t.Chdir called after t.Parallel; cannot change working directory in parallel tests',
+      )
+    }
     const proc = (globalThis as HostGlobal).process
     if (proc?.cwd === undefined || proc.chdir === undefined) {
       throw new Error('testing.Chdir is not supported without a host process')
@@ -242,11 +466,15 @@ export class T {
   public private(): void {}
 
   public async runCleanups(): Promise<void> {
-    for (let i = this.cleanups.length - 1; i >= 0; i--) {
-      await this.cleanups[i]()
+    while (this.cleanups.length !== 0) {
+      await this.cleanups.pop()!()
     }
   }
 
+  public hasLogs(): boolean {
+    return this.logs.length !== 0
+  }
+
   public flushLogs(): void {
     for (const line of this.logs) {
       console.log('    ' + line)
@@ -254,76 +482,793 @@ export class T {
   }
 }
 
+export type BenchmarkFunc = (b: B) => void | Promise<void>
+
+export type BenchmarkCase = {
+  name: string
+  fn: BenchmarkFunc
+}
+
+// BenchTime is a -benchtime target: n iterations when n is positive,
+// otherwise d nanoseconds.
+type BenchTime = {
+  n: number
+  d: number
+}
+
+type BenchmarkResult = {
+  n: number
+  // ns is the measured time in nanoseconds.
+  ns: number
+  bytes: number
+  extra: Map<string, number>
+}
+
 export class B extends T {
-  public N = 1
+  public N = 0
+  private readonly benchFunc: BenchmarkFunc
+  private readonly bstate: BenchState | null
+  private readonly level: number
+  private readonly benchTime: BenchTime
+  private hasSub = false
+  private timerOn = false
+  // start and duration are in nanoseconds.
+  private start = 0
+  private duration = 0
+  private bytes = 0
+  private extra = new Map<string, number>()
+  private showAllocResult = false
+  private previousN = 0
+  private previousDuration = 0
+  private parallelism = 1
+  private loopN = 0
+  private loopI = 0
+  private loopDone = false
 
-  constructor(name = 'Benchmark') {
+  constructor(
+    name = 'Benchmark',
+    fn: BenchmarkFunc = () => {},
+    bstate: BenchState | null = null,
+    level = 0,
+  ) {
     super(name)
+    this.benchFunc = fn
+    this.bstate = bstate
+    this.level = level
+    this.benchTime = bstate?.benchTime ?? { n: 0, d: 1e9 }
+  }
+
+  // Run benchmarks fn as a sub-benchmark. Like in Go, a benchmark that calls
+  // Run is not measured itself.
+  public async Run(name: string, fn: BenchmarkFunc): Promise<boolean> {
+    this.hasSub = true
+    let benchName = this.Name() + '/' + rewriteBenchmarkName(name)
+    let partial = false
+    if (this.bstate !== null) {
+      const match = this.bstate.fullName(this.level, this.Name(), name)
+      if (!match.ok) {
+        return true
+      }
+      benchName = match.name
+      partial = match.partial
+    }
+    const sub = new B(benchName, fn, this.bstate, this.level + 1)
+    // A partial match, like -bench=X/Y matching BenchmarkX, only runs the
+    // sub-benchmarks.
+    sub.hasSub = partial
+    if (this.bstate?.verbose) {
+      this.bstate.printLabels()
+      console.log(benchName)
+    }
+    if (await sub.run1()) {
+      await sub.run()
+    }
+    if (sub.Failed()) {
+      this.Fail()
+      return false
+    }
+    return true
+  }
+
+  public StartTimer(): void {
+    if (!this.timerOn) {
+      this.start = nowNanoseconds()
+      this.timerOn = true
+    }
+  }
+
+  public StopTimer(): void {
+    if (this.timerOn) {
+      this.duration += nowNanoseconds() - this.start
+      this.timerOn = false
+    }
+  }
+
+  public ResetTimer(): void {
+    this.extra.clear()
+    if (this.timerOn) {
+      this.start = nowNanoseconds()
+    }
+    this.duration = 0
+  }
+
+  // Elapsed returns the measured time of the benchmark as a time.Duration.
+  public Elapsed(): bigint {
+    let ns = this.duration
+    if (this.timerOn) {
+      ns += nowNanoseconds() - this.start
+    }
+    return BigInt(Math.round(ns))
+  }
+
+  public ReportAllocs(): void {
+    this.showAllocResult = true
+  }
+
+  public SetBytes(bytes: number | bigint): void {
+    this.bytes = Number(bytes)
+  }
+
+  public SetParallelism(p: number): void {
+    if (p >= 1) {
+      this.parallelism = p
+    }
+  }
+
+  public ReportMetric(n: number, unit: string): void {
+    if (unit === '') {
+      $.panic('metric unit must not be empty')
+    }
+    if (/\s/.test(unit)) {
+      $.panic('metric unit must not contain whitespace')
+    }
+    this.extra.set(unit, n)
+  }
+
+  // RunParallel runs body on SetParallelism goroutines that share b.N
+  // iterations through pb.Next.
+  public async RunParallel(
+    body: (pb: PB) => void | Promise<void>,
+  ): Promise<void> {
+    if (this.N === 0) {
+      // Nothing to do when probing.
+      return
+    }
+    // Hand out iterations in grains of about 100µs.
+    let grain = 0
+    if (this.previousN > 0 && this.previousDuration > 0) {
+      grain = Math.floor((1e5 * this.previousN) / this.previousDuration)
+    }
+    grain = Math.min(Math.max(grain, 1), 1e4)
+
+    const counter = { n: 0 }
+    const procs = this.parallelism * runtime.GOMAXPROCS(0)
+    const bodies: Promise<void>[] = []
+    for (let p = 0; p < procs; p++) {
+      bodies.push(
+        (async () => {
+          await body(new PB(counter, grain, this.N))
+        })(),
+      )
+    }
+    await Promise.all(bodies)
+    if (counter.n <= this.N && !this.Failed()) {
+      this.Fatal('RunParallel: body exited without pb.Next() == false')
+    }
+  }
+
+  public Loop(): boolean {
+    if (this.timerOn && this.loopI < this.loopN) {
+      this.loopI++
+      return true
+    }
+    return this.loopSlowPath()
+  }
+
+  private loopSlowPath(): boolean {
+    if (!this.timerOn) {
+      this.Fatal('B.Loop called with timer stopped')
+    }
+    if (this.loopN === 0) {
+      // The first call to Loop scales the iteration count itself, so b.N is
+      // not used inside the loop.
+      this.loopN = this.benchTime.n > 0 ? this.benchTime.n : 1
+      this.N = 0
+      this.ResetTimer()
+      this.loopI++
+      return true
+    }
+    let more = false
+    if (this.benchTime.n === 0) {
+      const elapsed = this.duration + nowNanoseconds() - this.start
+      if (elapsed < this.benchTime.d) {
+        const prevIters = this.loopN
+        this.loopN = predictN(this.benchTime.d, prevIters, elapsed, prevIters)
+        more = prevIters < this.loopN
+      }
+    }
+    if (!more) {
+      this.StopTimer()
+      this.N = this.loopN
+      this.loopDone = true
+      return false
+    }
+    this.loopI++
+    return true
   }
 
-  public async Run(
-    name: string,
-    fn: (b: B) => void | Promise<void>,
-  ): Promise<boolean> {
-    const child = new B(this.Name() + '/' + name)
-    child.N = this.N
+  // runN runs the benchmark function once with b.N set to n.
+  private async runN(n: number): Promise<void> {
+    collectGarbage()
+    this.N = n
+    this.loopN = 0
+    this.loopI = 0
+    this.loopDone = false
+    this.parallelism = 1
+    this.ResetTimer()
+    this.StartTimer()
     try {
-      await fn(child)
+      await this.benchFunc(this)
     } catch (err) {
       if (isProcessExitError(err)) {
         throw err
       }
-      child.Error(err)
+      if (!(err instanceof TestControl)) {
+        this.Fail()
+        this.Log(formatValue(err))
+      }
     }
-    if (child.Failed()) {
+    this.StopTimer()
+    this.previousN = n
+    this.previousDuration = this.duration
+    try {
+      await this.runCleanups()
+    } catch (err) {
+      if (isProcessExitError(err)) {
+        throw err
+      }
       this.Fail()
+      if (!(err instanceof TestControl)) {
+        this.Log(formatValue(err))
+      }
+    }
+    if (this.loopN > 0 && !this.loopDone && !this.Failed()) {
+      this.Error(
+        'benchmark function returned without B.Loop() == false (break or return in loop?)',
+      )
+    }
+  }
+
+  // run1 runs the benchmark once with b.N = 1 and reports whether it should
+  // be measured.
+  private async run1(): Promise<boolean> {
+    if (this.bstate !== null) {
+      const n = this.Name().length + 1
+      if (n > this.bstate.maxLen) {
+        // Add slack to avoid too many jumps in width.
+        this.bstate.maxLen = n + 8
+      }
+    }
+    await this.runN(1)
+    if (this.Failed()) {
+      console.log('--- FAIL: ' + this.Name())
+      this.flushLogs()
+      return false
+    }
+    if (this.hasSub || this.Skipped()) {
+      if (this.bstate?.verbose && (this.hasLogs() || this.Skipped())) {
+        console.log(
+          '--- ' + (this.Skipped() ? 'SKIP' : 'BENCH') + ': ' + this.Name(),
+        )
+        this.flushLogs()
+      }
       return false
     }
     return true
   }
 
-  public StartTimer(): void {}
+  // run measures the benchmark and prints its result lines.
+  private async run(): Promise<void> {
+    const state = this.bstate
+    if (state === null) {
+      await this.doBench()
+      return
+    }
+    state.printLabels()
+    let b: B = this
+    for (let i = 0; i < state.count; i++) {
+      const name = this.Name()
+      // Recompute the running time for all but the first run.
+      if (i > 0) {
+        b = new B(name, this.benchFunc, state, this.level)
+        await b.run1()
+      }
+      const result = await b.doBench()
+      if (b.Failed()) {
+        this.Fail()
+        console.log(
+          (state.verbose ? '' : name.padEnd(state.maxLen) + '\t') +
+            '--- FAIL: ' +
+            name,
+        )
+        b.flushLogs()
+        continue
+      }
+      let line = formatBenchmarkResult(result)
+      if (state.benchmem || b.showAllocResult) {
+        line += formatBenchmarkMemory(result)
+      }
+      console.log(name.padEnd(state.maxLen) + '\t' + line)
+      if (b.hasLogs()) {
+        console.log('--- BENCH: ' + name)
+        b.flushLogs()
+      }
+    }
+  }
 
-  public StopTimer(): void {}
+  private async doBench(): Promise<BenchmarkResult> {
+    // Loop scales the iteration count itself, so a benchmark that used it in
+    // run1 is already measured.
+    if (this.loopN === 0) {
+      if (this.benchTime.n > 0) {
+        // run1 already ran a single iteration, which -benchtime=1x uses.
+        if (this.benchTime.n > 1) {
+          await this.runN(this.benchTime.n)
+        }
+      } else {
+        const d = this.benchTime.d
+        for (let n = 1; !this.Failed() && this.duration < d && n < 1e9; ) {
+          const last = n
+          n = predictN(d, this.N, this.duration, last)
+          await this.runN(n)
+        }
+      }
+    }
+    return {
+      n: this.N,
+      ns: this.duration,
+      bytes: this.bytes,
+      extra: new Map(this.extra),
+    }
+  }
+}
 
-  public ResetTimer(): void {}
+// BenchState is the -bench configuration shared by the benchmarks of one
+// package run.
+class BenchState {
+  public maxLen = 0
+  public readonly benchTime: BenchTime
+  public readonly count: number
+  public readonly verbose: boolean
+  public readonly benchmem: boolean
+  private readonly pattern: (RegExp | null)[]
+  private readonly seen = new Map<string, number>()
+  private labelsPrinted = false
 
-  public ReportAllocs(): void {}
+  constructor(
+    private readonly packagePath: string,
+    options: RunOptions,
+  ) {
+    this.benchTime =
+      options.benchIters !== undefined && options.benchIters > 0 ?
+        { n: options.benchIters, d: 0 }
+      : { n: 0, d: (options.benchTime ?? 1000) * 1e6 }
+    this.count = options.count ?? 1
+    this.verbose = options.verbose ?? false
+    this.benchmem = options.benchmem ?? false
+    // The runner selects the top-level benchmarks with Go regexp semantics,
+    // so only the sub-benchmark levels are matched here.
+    this.pattern = (options.bench ?? []).map((elem, level) =>
+      level === 0 ? null : new RegExp(elem),
+    )
+  }
 
-  public SetBytes(_bytes: number): void {}
+  // fullName returns the name of the sub-benchmark subname of parent and
+  // whether it matches -bench. A partial match matches every level of the
+  // name but leaves pattern levels for deeper sub-benchmarks.
+  public fullName(
+    level: number,
+    parent: string,
+    subname: string,
+  ): { name: string; ok: boolean; partial: boolean } {
+    let name = rewriteBenchmarkName(subname)
+    if (level > 0) {
+      name = parent + '/' + name
+    }
+    const seen = this.seen.get(name)
+    if (seen !== undefined) {
+      this.seen.set(name, seen + 1)
+      name += '#' + String(seen).padStart(2, '0')
+    } else {
+      this.seen.set(name, 1)
+    }
+    const elems = name.split('/')
+    for (let i = 0; i < elems.length && i < this.pattern.length; i++) {
+      const re = this.pattern[i]
+      if (re !== null && !re.test(elems[i])) {
+        return { name, ok: false, partial: false }
+      }
+    }
+    return { name, ok: true, partial: elems.length < this.pattern.length }
+  }
 
-  public ReportMetric(_n: number, _unit: string): void {}
+  // printLabels prints the goos, goarch, pkg and cpu lines that go test
+  // prints before the first benchmark.
+  public printLabels(): void {
+    if (this.labelsPrinted) {
+      return
+    }
+    this.labelsPrinted = true
+    console.log('goos: ' + runtime.GOOS)
+    console.log('goarch: ' + runtime.GOARCH)
+    if (this.packagePath !== '') {
+      console.log('pkg: ' + this.packagePath)
+    }
+    const cpu = cpuName()
+    if (cpu !== '') {
+      console.log('cpu: ' + cpu)
+    }
+  }
 
-  public RunParallel(_fn: (pb: PB) => void): void {}
+  // ran reports whether a benchmark printed a result.
+  public ran(): boolean {
+    return this.labelsPrinted
+  }
+}
 
-  public Loop(): boolean {
-    if (this.N > 0) {
-      this.N--
-      return true
+// runBenchmarks runs the benchmarks matching options.bench and reports
+// whether they all passed.
+async function runBenchmarks(
+  state: BenchState,
+  benchmarks: BenchmarkCase[],
+): Promise<boolean> {
+  for (const benchmark of benchmarks) {
+    const n = benchmark.name.length + 1
+    if (n > state.maxLen) {
+      state.maxLen = n
     }
-    return false
   }
+  const main = new B('Main', () => {}, state)
+  for (const benchmark of benchmarks) {
+    await main.Run(benchmark.name, benchmark.fn)
+  }
+  return !main.Failed()
+}
+
+export type FuzzFunc = (f: F) => void | Promise<void>
+
+export type FuzzTargetCase = {
+  name: string
+  fn: FuzzFunc
+}
+
+// FuzzState is the fuzzing configuration of one fuzz target run.
+type FuzzState = {
+  // entries selects the corpus entries a seed run replays by name.
+  entries: RegExp | null
+  // fuzzing is set when the target mutates inputs instead of only
+  // replaying its seed corpus. iters is 0 when time bounds the run, and
+  // time is 0 when neither does.
+  fuzzing: { time: number; iters: number } | null
+}
+
+type CorpusEntry = {
+  name: string
+  values: CorpusValue[]
 }
 
+// corpusDir is where go test keeps the seed corpus of each fuzz target,
+// relative to the package directory.
+const corpusDir = 'testdata/fuzz'
+
+// fuzzStatusInterval is how often fuzzing prints its progress, like the go
+// test coordinator.
+const fuzzStatusInterval = 3000
+
 export class F extends T {
-  constructor(name = 'Fuzz') {
+  private readonly corpus: CorpusEntry[] = []
+  private readonly fstate: FuzzState
+  private fuzzCalled = false
+
+  constructor(
+    name = 'Fuzz',
+    fstate: FuzzState = { entries: null, fuzzing: null },
+  ) {
     super(name)
+    this.fstate = fstate
+  }
+
+  public Add(...args: unknown[]): void {
+    const values = args.map((arg) => {
+      const value = corpusValueOf(arg)
+      if (value === null) {
+        $.panic('testing: unsupported type to Add ' + describeType(arg))
+      }
+      return value
+    })
+    this.corpus.push({ name: 'seed#' + this.corpus.length, values })
   }
 
-  public Add(..._args: unknown[]): void {}
+  public async Fuzz(ff: unknown): Promise<void> {
+    if (this.fuzzCalled) {
+      $.panic('testing: F.Fuzz called more than once')
+    }
+    this.fuzzCalled = true
+    if (this.Failed()) {
+      return
+    }
+    if (typeof ff !== 'function') {
+      $.panic('testing: F.Fuzz must receive a function')
+    }
+    const types = this.fuzzTypes(ff)
 
-  public Fuzz(_fn: unknown): void {}
+    for (const entry of this.corpus) {
+      try {
+        checkCorpus(entry.values, types)
+      } catch (err) {
+        this.Fatal(err)
+      }
+    }
+    this.corpus.push(...this.readCorpus(types))
+
+    const call = (values: CorpusValue[]) => (t: T) =>
+      (ff as (t: T, ...args: unknown[]) => void | Promise<void>)(
+        t,
+        ...values.map(fuzzArgument),
+      )
+    if (this.fstate.fuzzing === null) {
+      for (const entry of this.corpus) {
+        if (this.fstate.entries?.test(entry.name) === false) {
+          continue
+        }
+        await this.runChild(
+          new T(this.Name() + '/' + entry.name),
+          call(entry.values),
+        )
+      }
+      return
+    }
+    await this.fuzz(types, call, this.fstate.fuzzing)
+  }
+
+  // fuzzTypes returns the argument types of the fuzz function ff after its
+  // *testing.T. Function literals carry their type info. Named functions do
+  // not, so their types come from the first f.Add seed.
+  private fuzzTypes(ff: unknown): FuzzType[] {
+    const info = (
+      ff as { __typeInfo?: { params?: unknown[]; results?: unknown[] } }
+    ).__typeInfo
+    if (info === undefined) {
+      if (this.corpus.length === 0) {
+        $.panic(
+          'testing: cannot determine the argument types of the fuzz function; call F.Fuzz with a function literal or add a seed with F.Add',
+        )
+      }
+      return this.corpus[0].values.map((value) => value.type)
+    }
+    const params = info.params ?? []
+    if (params.length < 2 || !isTestingTPointer(params[0])) {
+      $.panic(
+        'testing: fuzz target must receive at least two arguments, where the first argument is a *T',
+      )
+    }
+    if ((info.results ?? []).length !== 0) {
+      $.panic('testing: fuzz target must not return a value')
+    }
+    return params.slice(1).map((param) => {
+      const type = fuzzTypeOfParam(param)
+      if (type === null) {
+        $.panic('testing: unsupported type for fuzzing ' + describeType(param))
+      }
+      return type
+    })
+  }
+
+  // fuzz replays the seed corpus, then runs mutations of it until an input
+  // fails or the time or input budget runs out. A failing input is written
+  // to the corpus so later runs replay it.
+  private async fuzz(
+    types: FuzzType[],
+    call: (values: CorpusValue[]) => TestFunc,
+    budget: { time: number; iters: number },
+  ): Promise<void> {
+    const start = Date.now()
+    const elapsed = () => formatSeconds(Date.now() - start)
+    const seeds = this.corpus.length
+    console.log(
+      'fuzz: elapsed: ' +
+        elapsed() +
+        ', testing seed corpus: 0/' +
+        seeds +
+        ' completed',
+    )
+    for (const entry of this.corpus) {
+      const name = this.Name() + '/' + entry.name
+      if (!(await this.runChild(new T(name), call(entry.values)))) {
+        this.Log('failure while testing seed corpus entry: ' + name)
+        return
+      }
+    }
+    console.log(
+      'fuzz: elapsed: ' +
+        elapsed() +
+        ', testing seed corpus: ' +
+        seeds +
+        '/' +
+        seeds +
+        ' completed, now fuzzing with 1 workers',
+    )
+
+    const corpus =
+      seeds !== 0 ?
+        this.corpus.map((entry) => entry.values)
+      : [types.map(zeroCorpusValue)]
+    const mutator = new Mutator()
+    let execs = 0
+    let lastLog = Date.now()
+    let lastExecs = 0
+    let lastYield = Date.now()
+    const logStats = () => {
+      const now = Date.now()
+      const rate = ((execs - lastExecs) * 1000) / Math.max(now - lastLog, 1)
+      console.log(
+        'fuzz: elapsed: ' +
+          elapsed() +
+          ', execs: ' +
+          execs +
+          ' (' +
+          Math.round(rate) +
+          '/sec)',
+      )
+      lastLog = now
+      lastExecs = execs
+    }
+    const more = () =>
+      budget.iters > 0 ? execs < budget.iters
+      : budget.time > 0 ? Date.now() - start < budget.time
+      : true
+    while (more()) {
+      const values = corpus[mutator.rand(corpus.length)].map((v) => ({
+        type: v.type,
+        value: v.value instanceof Uint8Array ? v.value.slice() : v.value,
+      }))
+      for (let n = 1 + mutator.rand(4); n > 0; n--) {
+        mutator.mutate(values)
+      }
+      execs++
+      if (!(await this.runChild(new T(this.Name()), call(values)))) {
+        logStats()
+        this.reportFailingInput(values)
+        return
+      }
+      const now = Date.now()
+      if (now - lastLog >= fuzzStatusInterval) {
+        logStats()
+      }
+      if (now - lastYield >= 50) {
+        // Let timers, such as the -timeout alarm, and other goroutines run.
+        await new Promise((resolve) => setTimeout(resolve, 0))
+        lastYield = Date.now()
+      }
+    }
+    logStats()
+  }
+
+  // readCorpus loads the testdata seed corpus of this fuzz target.
+  private readCorpus(types: FuzzType[]): CorpusEntry[] {
+    let fs: {
+      readdirSync(
+        path: string,
+        opts: { withFileTypes: true },
+      ): { name: string; isDirectory(): boolean }[]
+      readFileSync(path: string, encoding: 'utf8'): string
+    }
+    try {
+      fs = requireHostModule('node:fs', 'testing.F')
+    } catch {
+      // Without a host file system there is no testdata to read.
+      return []
+    }
+    const dir = corpusDir + '/' + this.Name()
+    let files: { name: string; isDirectory(): boolean }[]
+    try {
+      files = fs.readdirSync(dir, { withFileTypes: true })
+    } catch (err) {
+      if ((err as { code?: unknown }).code === 'ENOENT') {
+        return []
+      }
+      this.Fatal('reading seed corpus from testdata: ' + formatValue(err))
+    }
+    const entries: CorpusEntry[] = []
+    const errs: string[] = []
+    files.sort((a, b) =>
+      a.name < b.name ? -1
+      : a.name > b.name ? 1
+      : 0,
+    )
+    for (const file of files) {
+      if (file.isDirectory()) {
+        continue
+      }
+      const path = dir + '/' + file.name
+      const data = fs.readFileSync(path, 'utf8')
+      try {
+        let values: CorpusValue[]
+        try {
+          values = unmarshalCorpusFile(data)
+        } catch (err) {
+          throw new Error('unmarshal: ' + formatValue(err))
+        }
+        checkCorpus(values, types)
+        entries.push({ name: file.name, values })
+      } catch (err) {
+        errs.push(JSON.stringify(path) + ': ' + formatValue(err))
+      }
+    }
+    if (errs.length !== 0) {
+      this.Fatal(errs.join('\n'))
+    }
+    return entries
+  }
+
+  // reportFailingInput writes values to the seed corpus and logs how to
+  // replay them.
+  private reportFailingInput(values: CorpusValue[]): void {
+    const data = marshalCorpusFile(values)
+    try {
+      const fs = requireHostModule<{
+        mkdirSync(path: string, opts: { recursive: boolean }): void
+        writeFileSync(path: string, data: Uint8Array): void
+      }>('node:fs', 'testing.F')
+      const crypto = requireHostModule<{
+        createHash(algorithm: string): {
+          update(data: Uint8Array): { digest(encoding: 'hex'): string }
+        }
+      }>('node:crypto', 'testing.F')
+      const bytes = new TextEncoder().encode(data)
+      const sum = crypto
+        .createHash('sha256')
+        .update(bytes)
+        .digest('hex')
+        .slice(0, 16)
+      const dir = corpusDir + '/' + this.Name()
+      fs.mkdirSync(dir, { recursive: true })
+      fs.writeFileSync(dir + '/' + sum, bytes)
+      this.Log('Failing input written to ' + dir + '/' + sum)
+      this.Log('To re-run:')
+      this.Log('goscript test -run=' + this.Name() + '/' + sum)
+    } catch (err) {
+      this.Log('failed to write failing input: ' + formatValue(err))
+      this.Log(data)
+    }
+  }
 }
 
+// PB hands out the iterations of a RunParallel benchmark. The bodies of one
+// RunParallel call share globalN and each reserves grain iterations at a time.
 export class PB {
-  private remaining = 1
+  private cache = 0
+
+  constructor(
+    private readonly globalN: { n: number } = { n: 0 },
+    private readonly grain = 1,
+    private readonly bN = 1,
+  ) {}
 
   public Next(): boolean {
-    if (this.remaining > 0) {
-      this.remaining--
-      return true
+    if (this.cache === 0) {
+      this.globalN.n += this.grain
+      const n = this.globalN.n
+      if (n <= this.bN) {
+        this.cache = this.grain
+      } else if (n < this.bN + this.grain) {
+        this.cache = this.bN + this.grain - n
+      } else {
+        return false
+      }
     }
-    return false
+    this.cache--
+    return true
   }
 }
 
@@ -337,65 +1282,519 @@ export async function runTests(
   options: RunOptions = {},
 ): Promise<RunResult> {
   const previousShortMode = shortMode
+  const previousChatty = chatty
   shortMode = options.short ?? false
+  chatty = options.verbose ?? false
+  timeoutReported = false
+  try {
+    if (options.testMain !== undefined) {
+      return await runTestMain(packagePath, tests, options.testMain, options)
+    }
+    return await runPackage(packagePath, tests, options)
+  } finally {
+    shortMode = previousShortMode
+    chatty = previousChatty
+  }
+}
+
+// M is the argument of TestMain, which runs the tests with m.Run.
+export class M {
+  // running is the run of an m.Run call in progress.
+  private running: Promise<RunResult> | null = null
+  private result: RunResult | null = null
+
+  constructor(private readonly run: () => Promise<RunResult>) {}
+
+  // Run runs the tests, examples, and benchmarks and returns the exit code
+  // to pass to os.Exit.
+  public async Run(): Promise<number> {
+    this.running = this.run()
+    try {
+      this.result = await $.park('chan receive', this.running)
+    } finally {
+      this.running = null
+    }
+    return this.result.ok ? 0 : 1
+  }
+
+  // pendingRun returns the run of an m.Run call in progress, or null.
+  public pendingRun(): Promise<RunResult> | null {
+    return this.running
+  }
+
+  // lastResult returns the result of the last m.Run call, or null.
+  public lastResult(): RunResult | null {
+    return this.result
+  }
+}
+
+// runTestMain runs testMain, which runs the package with m.Run. Like go
+// test, a TestMain that returns without calling os.Exit passes the result
+// of m.Run on.
+async function runTestMain(
+  packagePath: string,
+  tests: TestCase[],
+  testMain: TestMainFunc,
+  options: RunOptions,
+): Promise<RunResult> {
+  const m = new M(() => runPackage(packagePath, tests, options))
+  const start = Date.now()
+  const failed = (): RunResult => {
+    console.log('FAIL\t' + packagePath)
+    return { ok: false, failed: 1, skipped: 0 }
+  }
+  try {
+    await runUntilDeadline(
+      $.runRootGoroutine(() => testMain(m), packagePath + '.TestMain'),
+      options.deadline,
+    )
+  } catch (err) {
+    if (isProcessExitError(err)) {
+      if (m.pendingRun() !== null) {
+        // A test called os.Exit.
+        throw err
+      }
+      const code = (err as { __goscriptExitCode: number }).__goscriptExitCode
+      const result = m.lastResult()
+      return {
+        ok: code === 0,
+        failed: result?.failed ?? (code === 0 ? 0 : 1),
+        skipped: result?.skipped ?? 0,
+        timedOut: result?.timedOut,
+      }
+    }
+    if (err instanceof TestTimeoutError) {
+      const pending = m.pendingRun()
+      if (pending !== null) {
+        // The test that hung reports the timeout.
+        return await pending
+      }
+      console.log(
+        'panic: test timed out after ' +
+          (options.timeout ?? '') +
+          '\n\trunning tests:\n\t\tTestMain (' +
+          formatSeconds(Date.now() - start) +
+          ')\n\n' +
+          $.goroutineDump(),
+      )
+      return { ok: false, failed: 1, skipped: 0, timedOut: true }
+    }
+    if (err instanceof $.DeadlockError) {
+      console.log(err.message)
+      return failed()
+    }
+    console.log('panic: ' + formatValue(err))
+    return failed()
+  }
+  return m.lastResult() ?? { ok: true, failed: 0, skipped: 0 }
+}
+
+// runPackage runs the tests, fuzz seed corpora, examples, and benchmarks of
+// a package, and fuzzes it when options.fuzz is set.
+async function runPackage(
+  packagePath: string,
+  tests: TestCase[],
+  options: RunOptions,
+): Promise<RunResult> {
   const count = options.count ?? 1
+  const entries =
+    options.run !== undefined && options.run.length > 1 ?
+      new RegExp(options.run[1])
+    : null
+  const cases: { name: string; newT: () => T; fn: TestFunc }[] = [
+    ...tests.map((test) => ({
+      name: test.name,
+      newT: () => new T(test.name),
+      fn: test.fn,
+    })),
+    ...(options.fuzzTargets ?? []).map((target) => ({
+      name: target.name,
+      newT: () => new F(target.name, { entries, fuzzing: null }),
+      fn: (t: T) => target.fn(t as F),
+    })),
+  ]
   let failed = 0
   let skipped = 0
-  try {
-    for (let run = 0; run < count; run++) {
-      for (const test of tests) {
-        if (options.verbose) {
-          console.log('=== RUN   ' + test.name)
-        }
-        const t = new T(test.name)
-        const start = Date.now()
-        try {
-          await test.fn(t)
-        } catch (err) {
-          if (isProcessExitError(err)) {
-            throw err
-          }
-          if (err instanceof TestControl && err.kind === 'skip') {
-            skipped++
-          } else {
-            t.Fail()
-            if (!(err instanceof TestControl)) {
-              t.Log(formatValue(err))
-            }
-          }
-        }
-        await t.runCleanups()
-        const elapsed = ((Date.now() - start) / 1000).toFixed(2)
-        if (t.Skipped()) {
-          if (options.verbose) {
-            t.flushLogs()
-          }
-          console.log('--- SKIP: ' + test.name + ' (' + elapsed + 's)')
-          continue
-        }
-        if (t.Failed()) {
-          failed++
-          t.flushLogs()
-          console.log('--- FAIL: ' + test.name + ' (' + elapsed + 's)')
-          continue
-        }
-        if (options.verbose) {
-          t.flushLogs()
-          console.log('--- PASS: ' + test.name + ' (' + elapsed + 's)')
+  let timedOut = false
+  let halted = false
+  let streamed = false
+  // tally counts status and reports whether the run has to stop.
+  const tally = (status: TestStatus): boolean => {
+    if (status === 'skip') {
+      skipped++
+    } else if (status !== 'pass') {
+      failed++
+    }
+    if (status === 'timeout' || status === 'deadlock') {
+      timedOut ||= status === 'timeout'
+      halted = true
+    }
+    return halted
+  }
+  runs: for (let run = 0; run < count; run++) {
+    // Like go test, the top-level tests are subtests of a root test, which
+    // resumes the parallel ones once the others finish.
+    const root = new T('')
+    const parallel: Promise<TestStatus>[] = []
+    for (const test of cases) {
+      const started = await runTest(
+        packagePath,
+        test.newT(),
+        test.fn,
+        options,
+        '=== RUN   ',
+        root,
+      )
+      if (started.parallel) {
+        parallel.push(started.status)
+        continue
+      }
+      if (tally(await started.status)) {
+        break runs
+      }
+    }
+    root.resumeParallel()
+    for (const status of await Promise.all(parallel)) {
+      tally(status)
+    }
+    if (halted) {
+      break
+    }
+  }
+  if (!halted) {
+    examples: for (let run = 0; run < count; run++) {
+      for (const example of options.examples ?? []) {
+        if (tally(await runExample(packagePath, example, options))) {
+          break examples
         }
       }
     }
-    if (failed === 0) {
-      if (options.verbose) {
-        console.log('PASS')
+  }
+  if (failed === 0 && !halted && options.bench !== undefined) {
+    const state = new BenchState(packagePath, options)
+    let benchOK = false
+    try {
+      benchOK = await runUntilDeadline(
+        $.runRootGoroutine(
+          () => runBenchmarks(state, options.benchmarks ?? []),
+          packagePath + '.Main',
+        ),
+        options.deadline,
+      )
+    } catch (err) {
+      if (isProcessExitError(err)) {
+        throw err
+      }
+      if (err instanceof TestTimeoutError) {
+        timedOut = true
+        console.log(
+          'panic: test timed out after ' +
+            (options.timeout ?? '') +
+            '\n\n' +
+            $.goroutineDump(),
+        )
+      } else {
+        console.log(formatValue(err))
       }
-      return { ok: true, failed, skipped }
     }
-    console.log('FAIL\t' + packagePath)
-    return { ok: false, failed, skipped }
+    if (!benchOK) {
+      failed++
+    }
+    streamed = state.ran()
+  }
+  if (failed === 0 && !timedOut && options.fuzz !== undefined) {
+    const status = await runFuzzing(packagePath, options)
+    if (status !== 'pass') {
+      failed++
+    }
+    timedOut = status === 'timeout'
+    streamed = true
+  }
+  options.after?.()
+  if (failed === 0) {
+    if (options.verbose || streamed) {
+      console.log('PASS')
+    }
+    return { ok: true, failed, skipped }
+  }
+  console.log('FAIL\t' + packagePath)
+  return { ok: false, failed, skipped, timedOut }
+}
+
+type TestStatus = 'pass' | 'fail' | 'skip' | 'timeout' | 'deadlock'
+
+// TestRun is a started top-level test. A test that called Parallel waits
+// for its parent before status settles.
+type TestRun = {
+  parallel: boolean
+  status: Promise<TestStatus>
+}
+
+// runTest starts the top-level test fn with t and prints its result. header
+// starts the line a verbose run prints before the test, and root is the
+// parent of the top-level tests that call Parallel.
+async function runTest(
+  packagePath: string,
+  t: T,
+  fn: TestFunc,
+  options: RunOptions,
+  header: string,
+  root: T | null = null,
+): Promise<TestRun> {
+  const name = t.Name()
+  if (options.verbose) {
+    console.log(header + name)
+  }
+  const start = Date.now()
+  let paused = new Promise<typeof testPaused>(() => {})
+  if (root !== null) {
+    paused = new Promise((resolve) => t.attach(root, () => resolve(testPaused)))
+  }
+  const status = finishTest(
+    t,
+    runUntilDeadline(
+      $.runRootGoroutine(() => t.runBody(fn), packagePath + '.' + name),
+      options.deadline,
+    ),
+    start,
+    options,
+  )
+  const first = await Promise.race([status, paused])
+  return { parallel: first === testPaused, status }
+}
+
+// finishTest waits for the body of the top-level test t and prints its
+// result.
+async function finishTest(
+  t: T,
+  run: Promise<unknown>,
+  start: number,
+  options: RunOptions,
+): Promise<TestStatus> {
+  const name = t.Name()
+  try {
+    const exit = await run
+    if (exit !== null) {
+      throw exit
+    }
+  } catch (err) {
+    if (isProcessExitError(err)) {
+      throw err
+    }
+    if (err instanceof TestTimeoutError) {
+      // Like the go test alarm, report the hung test with every goroutine
+      // that is still around and stop.
+      if (timeoutReported) {
+        return 'timeout'
+      }
+      timeoutReported = true
+      t.flushLogs()
+      console.log(
+        'panic: test timed out after ' +
+          (options.timeout ?? '') +
+          '\n\trunning tests:\n\t\t' +
+          name +
+          ' (' +
+          formatSeconds(Date.now() - start) +
+          ')\n\n' +
+          $.goroutineDump(),
+      )
+      return 'timeout'
+    }
+    if (err instanceof $.DeadlockError) {
+      // Go aborts the test binary on a deadlock. The deadlocked test stays
+      // parked, so report it and skip the remaining tests.
+      t.Fail()
+      t.Log(err.message)
+      t.flushLogs()
+      const elapsed = ((Date.now() - start) / 1000).toFixed(2)
+      console.log('--- FAIL: ' + name + ' (' + elapsed + 's)')
+      t.flushReports()
+      return 'deadlock'
+    }
+    throw err
+  }
+  const elapsed = ((Date.now() - start) / 1000).toFixed(2)
+  if (t.Skipped()) {
+    if (options.verbose) {
+      t.flushLogs()
+    }
+    console.log('--- SKIP: ' + name + ' (' + elapsed + 's)')
+    t.flushReports()
+    return 'skip'
+  }
+  if (t.Failed()) {
+    t.flushLogs()
+    console.log('--- FAIL: ' + name + ' (' + elapsed + 's)')
+    t.flushReports()
+    return 'fail'
+  }
+  if (options.verbose) {
+    t.flushLogs()
+    console.log('--- PASS: ' + name + ' (' + elapsed + 's)')
+    t.flushReports()
+  }
+  return 'pass'
+}
+
+// runExample runs example with standard output captured and compares what
+// it printed with its // Output: comment, ignoring leading and trailing
+// space like go test. Unordered output is compared line by line in any
+// order.
+async function runExample(
+  packagePath: string,
+  example: ExampleCase,
+  options: RunOptions,
+): Promise<TestStatus> {
+  if (options.verbose) {
+    console.log('=== RUN   ' + example.name)
+  }
+  const start = Date.now()
+  const finishCapture = $.captureHostStdout()
+  let stdout = ''
+  let caught = false
+  let panicked: unknown = null
+  try {
+    await runUntilDeadline(
+      $.runRootGoroutine(example.fn, packagePath + '.' + example.name),
+      options.deadline,
+    )
+  } catch (err) {
+    caught = true
+    panicked = err
   } finally {
-    shortMode = previousShortMode
+    stdout = finishCapture()
+  }
+  if (caught) {
+    const err = panicked
+    if (isProcessExitError(err)) {
+      throw err
+    }
+    if (err instanceof TestTimeoutError) {
+      console.log(
+        'panic: test timed out after ' +
+          (options.timeout ?? '') +
+          '\n\trunning tests:\n\t\t' +
+          example.name +
+          ' (' +
+          formatSeconds(Date.now() - start) +
+          ')\n\n' +
+          $.goroutineDump(),
+      )
+      return 'timeout'
+    }
+    if (err instanceof $.DeadlockError) {
+      console.log(
+        '--- FAIL: ' +
+          example.name +
+          ' (' +
+          ((Date.now() - start) / 1000).toFixed(2) +
+          's)\n' +
+          err.message,
+      )
+      return 'deadlock'
+    }
+  }
+  const elapsed = ((Date.now() - start) / 1000).toFixed(2)
+  const got = stdout.trim()
+  const want = example.output.trim()
+  let fail = ''
+  if (caught) {
+    fail = 'panic: ' + formatValue(panicked)
+  } else if (example.unordered) {
+    if (sortLines(got) !== sortLines(want)) {
+      fail = 'got:\n' + got + '\nwant (unordered):\n' + want
+    }
+  } else if (got !== want) {
+    fail = 'got:\n' + got + '\nwant:\n' + want
+  }
+  if (fail !== '') {
+    console.log('--- FAIL: ' + example.name + ' (' + elapsed + 's)\n' + fail)
+    return 'fail'
+  }
+  if (options.verbose) {
+    console.log('--- PASS: ' + example.name + ' (' + elapsed + 's)')
+  }
+  return 'pass'
+}
+
+function sortLines(output: string): string {
+  return output.split('\n').sort().join('\n')
+}
+
+// runFuzzing fuzzes the fuzz target matching options.fuzz, like go test
+// does after the tests and benchmarks pass.
+async function runFuzzing(
+  packagePath: string,
+  options: RunOptions,
+): Promise<TestStatus> {
+  const pattern = new RegExp(options.fuzz ?? '')
+  const matched = (options.fuzzTargets ?? []).filter((target) =>
+    pattern.test(target.name),
+  )
+  if (matched.length === 0) {
+    console.log('testing: warning: no fuzz tests to fuzz')
+    return 'pass'
+  }
+  if (matched.length > 1) {
+    console.log(
+      'testing: will not fuzz, -fuzz matches more than one fuzz test: [' +
+        matched.map((target) => target.name).join(' ') +
+        ']',
+    )
+    return 'fail'
+  }
+  const target = matched[0]
+  const f = new F(target.name, {
+    entries: null,
+    fuzzing: {
+      time: options.fuzzTime ?? 0,
+      iters: options.fuzzIters ?? 0,
+    },
+  })
+  const started = await runTest(
+    packagePath,
+    f,
+    (t) => target.fn(t as F),
+    options,
+    '=== FUZZ  ',
+  )
+  return started.status
+}
+
+class TestTimeoutError extends Error {}
+
+// runUntilDeadline waits for run, or rejects with a TestTimeoutError once the
+// deadline passes. The deadline timer is not host work: a test that deadlocks
+// before the deadline still fails as a deadlock.
+function runUntilDeadline<T>(run: Promise<T>, deadline?: number): Promise<T> {
+  if (deadline === undefined) {
+    return run
+  }
+  let timer: ReturnType<typeof setTimeout> | undefined
+  const expired = new Promise<never>((_, reject) => {
+    timer = setTimeout(
+      () => reject(new TestTimeoutError()),
+      Math.max(0, deadline - Date.now()),
+    )
+  })
+  return Promise.race([run, expired]).finally(() => clearTimeout(timer))
+}
+
+// formatSeconds formats ms as a time.Duration rounded to the second.
+function formatSeconds(ms: number): string {
+  let seconds = Math.round(ms / 1000)
+  const hours = Math.floor(seconds / 3600)
+  seconds -= hours * 3600
+  const minutes = Math.floor(seconds / 60)
+  seconds -= minutes * 60
+  if (hours > 0) {
+    return `${hours}h${minutes}m${seconds}s`
+  }
+  if (minutes > 0) {
+    return `${minutes}m${seconds}s`
   }
+  return `${seconds}s`
 }
 
 function formatMessage(format: string, args: unknown[]): string {
@@ -443,6 +1842,41 @@ function hostRequire(): ((name: string) => unknown) | undefined {
   return undefined
 }
 
+// isTestingTPointer reports whether info is the type info of *testing.T.
+function isTestingTPointer(info: unknown): boolean {
+  if (info === null || typeof info !== 'object') {
+    return false
+  }
+  const pointer = info as { kind?: unknown; elemType?: unknown }
+  const elem = pointer.elemType as { name?: unknown } | string | undefined
+  return (
+    pointer.kind === $.TypeKind.Pointer &&
+    (elem === 'testing.T' ||
+      (typeof elem === 'object' && elem?.name === 'testing.T'))
+  )
+}
+
+// describeType names the Go type of a value, or of a type info, in a panic
+// message.
+function describeType(value: unknown): string {
+  if (typeof value === 'string') {
+    return value
+  }
+  if (value !== null && typeof value === 'object') {
+    const info = value as { __goType?: unknown; name?: unknown; kind?: unknown }
+    if (typeof info.__goType === 'string') {
+      return info.__goType
+    }
+    if (typeof info.name === 'string') {
+      return info.name
+    }
+    if (typeof info.kind === 'string') {
+      return info.kind
+    }
+  }
+  return typeof value
+}
+
 function isProcessExitError(err: unknown): boolean {
   if (err === null || typeof err !== 'object') {
     return false
@@ -468,3 +1902,132 @@ function formatValue(value: unknown): string {
   }
   return String(value)
 }
+
+// predictN returns the iteration count that should take goalns, given that
+// prevIters iterations took prevns, growing by at most 100x over last.
+function predictN(
+  goalns: number,
+  prevIters: number,
+  prevns: number,
+  last: number,
+): number {
+  if (prevns <= 0) {
+    prevns = 1
+  }
+  // Run 20% more than the estimate, so the target is reached on this run.
+  let n = Math.floor((goalns * prevIters) / prevns)
+  n += Math.floor(n / 5)
+  n = Math.min(n, 100 * last)
+  n = Math.max(n, last + 1)
+  return Math.min(n, 1e9)
+}
+
+// rewriteBenchmarkName replaces the spaces of a sub-benchmark name like Go.
+function rewriteBenchmarkName(name: string): string {
+  return name.replace(/\s/g, '_')
+}
+
+// formatBenchmarkResult formats r like BenchmarkResult.String in Go.
+function formatBenchmarkResult(r: BenchmarkResult): string {
+  let line = String(r.n).padStart(8)
+  const ns = r.extra.get('ns/op') ?? (r.n > 0 ? r.ns / r.n : 0)
+  if (ns !== 0) {
+    line += '\t' + prettyPrint(ns, 'ns/op')
+  }
+  if (r.bytes > 0 && r.ns > 0 && r.n > 0) {
+    const mbs = (r.bytes * r.n) / 1e6 / (r.ns / 1e9)
+    line += '\t' + mbs.toFixed(2).padStart(7) + ' MB/s'
+  }
+  const keys = [...r.extra.keys()]
+    .filter(
+      (key) =>
+        key !== 'ns/op' &&
+        key !== 'MB/s' &&
+        key !== 'B/op' &&
+        key !== 'allocs/op',
+    )
+    .sort()
+  for (const key of keys) {
+    line += '\t' + prettyPrint(r.extra.get(key)!, key)
+  }
+  return line
+}
+
+// formatBenchmarkMemory formats the -benchmem columns of r. JavaScript hosts
+// do not report allocations, so B/op and allocs/op are only printed when the
+// benchmark reports them.
+function formatBenchmarkMemory(r: BenchmarkResult): string {
+  let line = ''
+  const bytes = r.extra.get('B/op')
+  if (bytes !== undefined) {
+    line += '\t' + String(Math.floor(bytes)).padStart(8) + ' B/op'
+  }
+  const allocs = r.extra.get('allocs/op')
+  if (allocs !== undefined) {
+    line += '\t' + String(Math.floor(allocs)).padStart(8) + ' allocs/op'
+  }
+  return line
+}
+
+// prettyPrint formats x like the benchmark metrics of go test: ten places
+// before the decimal point and four significant figures for small numbers.
+function prettyPrint(x: number, unit: string): string {
+  const y = Math.abs(x)
+  let width = 18
+  let digits = 7
+  if (y === 0 || y >= 999.95) {
+    width = 10
+    digits = 0
+  } else if (y >= 99.995) {
+    width = 12
+    digits = 1
+  } else if (y >= 9.9995) {
+    width = 13
+    digits = 2
+  } else if (y >= 0.99995) {
+    width = 14
+    digits = 3
+  } else if (y >= 0.099995) {
+    width = 15
+    digits = 4
+  } else if (y >= 0.0099995) {
+    width = 16
+    digits = 5
+  } else if (y >= 0.00099995) {
+    width = 17
+    digits = 6
+  }
+  return x.toFixed(digits).padStart(width) + ' ' + unit
+}
+
+function nowNanoseconds(): number {
+  return performance.now() * 1e6
+}
+
+// collectGarbage asks the host for a full collection before a benchmark run,
+// like runtime.GC in go test, when the host exposes one.
+function collectGarbage(): void {
+  const host = globalThis as {
+    Bun?: { gc?: (force: boolean) => void }
+    gc?: () => void
+  }
+  if (typeof host.Bun?.gc === 'function') {
+    host.Bun.gc(true)
+  } else if (typeof host.gc === 'function') {
+    host.gc()
+  }
+}
+
+// cpuName returns the CPU model for the cpu: benchmark label, or an empty
+// string when the host does not report it.
+function cpuName(): string {
+  try {
+    const os = requireHostModule<{ cpus(): { model: string }[] }>(
+      'node:os',
+      'testing',
+    )
+    return os.cpus()[0]?.model.trim() ?? ''
+  } catch {
+    return ''
+  }
+}
@@ -1,8 +1,9 @@
-import { describe, expect, it } from 'vitest'
+import { afterEach, describe, expect, it } from 'vitest'
 
 import * as $ from '@goscript/builtin/index.js'
 
 import {
+  After,
   ANSIC,
   Date,
   Duration_Abs,
@@ -35,6 +36,7 @@ import {
   RFC3339Nano,
   Saturday,
   Second,
+  Sleep,
   StampMicro,
   Sunday,
   May,
@@ -126,6 +128,43 @@ describe('time constants and timers', () => {
   })
 })
 
+describe('time on a virtual clock', () => {
+  let restore: (() => void) | null = null
+
+  afterEach(() => {
+    restore?.()
+    restore = null
+  })
+
+  it('reads Now from the installed clock', () => {
+    restore = $.installHostClock(new $.VirtualClock())
+
+    const now = Now()
+    expect(now.UTC().Format(RFC3339)).toBe('2000-01-01T00:00:00Z')
+    expect(Since(now)).toBe(0n)
+  })
+
+  it('sleeps and fires timers without waiting in real time', async () => {
+    const clock = new $.VirtualClock()
+    restore = $.installHostClock(clock)
+
+    const elapsed = await $.runRootGoroutine(async () => {
+      const start = Now()
+      const ticker = NewTicker(Minute)
+      const timer = NewTimer(90n * Second)
+      await Sleep(24n * 3600n * Second)
+      await timer.C.receive()
+      await ticker.C.receive()
+      ticker.Stop()
+      await After(Millisecond).receive()
+      return Since(start)
+    })
+
+    expect(elapsed).toBe(24n * 3600n * Second + Millisecond)
+    expect(clock.pending()).toBe(0)
+  })
+})
+
 describe('time.Time.In', () => {
   it('returns the same instant in another fixed location', () => {
     const utc = Date(2025, May, 15, 1, 10, 42, 0, UTC)
@@ -218,14 +257,17 @@ describe('time location loading', () => {
 
     const [missing, err] = LoadLocation('America/NotReal')
     expect(missing).toBeNull()
-    expect(err?.Error()).toBe('time: unknown time zone America/NotReal')
+    expect(err?.Error()).toBe('unknown time zone America/NotReal')
+
+    const [, invalid] = LoadLocation('../etc/passwd')
+    expect(invalid?.Error()).toBe('time: invalid location name')
   })
 
-  it('returns tuple shape for timezone data loaders', () => {
+  it('rejects malformed timezone data', () => {
     const [loc, err] = LoadLocationFromTZData('Custom/Zone', new Uint8Array())
 
-    expect(err).toBeNull()
-    expect(loc?.String()).toBe('Custom/Zone')
+    expect(loc).toBeNull()
+    expect(err?.Error()).toBe('malformed time zone information')
   })
 })
 
@@ -1,5 +1,14 @@
 import * as $ from '../builtin/index.js'
 import { makeChannel, ChannelRef, makeChannelRef } from '../builtin/channel.js'
+import {
+  errBadData,
+  fixedZoneData,
+  loadZoneData,
+  localZoneData,
+  lookupZone,
+  parseTZData,
+  zoneData,
+} from './zoneinfo.js'
 
 // Time represents a time instant with nanosecond precision
 export class Time {
@@ -60,72 +69,37 @@ export class Time {
 
   // Weekday returns the day of the week specified by t
   public Weekday(): Weekday {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-      return adjustedTime.getUTCDay() as Weekday
-    }
-    return this._date.getDay() as Weekday
+    return this.wall().getUTCDay() as Weekday
   }
 
   // Day returns the day of the month specified by t
   public Day(): number {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-      return adjustedTime.getUTCDate()
-    }
-    return this._date.getDate()
+    return this.wall().getUTCDate()
   }
 
   // Month returns the month of the year specified by t
   public Month(): Month {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-      return (adjustedTime.getUTCMonth() + 1) as Month
-    }
-    return (this._date.getMonth() + 1) as Month
+    return (this.wall().getUTCMonth() + 1) as Month
   }
 
   // Year returns the year in which t occurs
   public Year(): number {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-      return adjustedTime.getUTCFullYear()
-    }
-    return this._date.getFullYear()
+    return this.wall().getUTCFullYear()
   }
 
   // Hour returns the hour within the day specified by t, in the range [0, 23]
   public Hour(): number {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-      return adjustedTime.getUTCHours()
-    }
-    return this._date.getHours()
+    return this.wall().getUTCHours()
   }
 
   // Minute returns the minute offset within the hour specified by t, in the range [0, 59]
   public Minute(): number {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-      return adjustedTime.getUTCMinutes()
-    }
-    return this._date.getMinutes()
+    return this.wall().getUTCMinutes()
   }
 
   // Second returns the second offset within the minute specified by t, in the range [0, 59]
   public Second(): number {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-      return adjustedTime.getUTCSeconds()
-    }
-    return this._date.getSeconds()
+    return this.wall().getUTCSeconds()
   }
 
   // Nanosecond returns the nanosecond offset within the second specified by t, in the range [0, 999999999]
@@ -141,12 +115,10 @@ export class Time {
     return [this.Hour(), this.Minute(), this.Second()]
   }
 
+  // Zone returns the abbreviated name of the zone in effect at t, such as
+  // "CET", and its offset in seconds east of UTC.
   public Zone(): [string, number] {
-    if (this._location.offsetSeconds !== undefined) {
-      return [this._location.name, this._location.offsetSeconds]
-    }
-    const offset = -this._date.getTimezoneOffset() * 60
-    return [this._location.name, offset]
+    return this._location.lookup(Math.floor(this._date.getTime() / 1000))
   }
 
   // Location returns the time zone information associated with t
@@ -177,31 +149,17 @@ export class Time {
     // "Mon Jan 2 15:04:05 MST 2006" (Unix time 1136239445)
 
     // Calculate the time in the timezone of this Time object
-    let year: number, month0: number, dayOfMonth: number, dayOfWeek: number
-    let hour24: number, minute: number, second: number
-
-    if (this._location.offsetSeconds !== undefined) {
-      // For fixed timezone locations, adjust the UTC time by the offset
-      const offsetMs = this._location.offsetSeconds * 1000
-      const adjustedTime = new globalThis.Date(this._date.getTime() + offsetMs)
-
-      year = adjustedTime.getUTCFullYear()
-      month0 = adjustedTime.getUTCMonth() // 0-11 for array indexing
-      dayOfMonth = adjustedTime.getUTCDate() // 1-31
-      dayOfWeek = adjustedTime.getUTCDay() // 0 (Sun) - 6 (Sat)
-      hour24 = adjustedTime.getUTCHours() // 0-23
-      minute = adjustedTime.getUTCMinutes() // 0-59
-      second = adjustedTime.getUTCSeconds() // 0-59
-    } else {
-      // For local time, use the local timezone methods
-      year = this._date.getFullYear()
-      month0 = this._date.getMonth() // 0-11 for array indexing
-      dayOfMonth = this._date.getDate() // 1-31
-      dayOfWeek = this._date.getDay() // 0 (Sun) - 6 (Sat)
-      hour24 = this._date.getHours() // 0-23
-      minute = this._date.getMinutes() // 0-59
-      second = this._date.getSeconds() // 0-59
-    }
+    const [tzName, tzOffsetSeconds] = this.Zone()
+    const wall = new globalThis.Date(
+      this._date.getTime() + tzOffsetSeconds * 1000,
+    )
+    const year = wall.getUTCFullYear()
+    const month0 = wall.getUTCMonth() // 0-11 for array indexing
+    const dayOfMonth = wall.getUTCDate() // 1-31
+    const dayOfWeek = wall.getUTCDay() // 0 (Sun) - 6 (Sat)
+    const hour24 = wall.getUTCHours() // 0-23
+    const minute = wall.getUTCMinutes() // 0-59
+    const second = wall.getUTCSeconds() // 0-59
 
     const nsec = this._nsec // Nanoseconds (0-999,999,999)
 
@@ -248,22 +206,8 @@ export class Time {
     const ampmUpper = hour24 < 12 ? 'AM' : 'PM'
     const ampmLower = ampmUpper.toLowerCase()
 
-    // Timezone offset calculation - use the location's offset if available
-    let tzOffsetSeconds: number
-    let tzName = this._location.name
-    let isUTC: boolean
-
-    if (this._location.offsetSeconds !== undefined) {
-      // Use the fixed offset from the location
-      tzOffsetSeconds = this._location.offsetSeconds
-      isUTC = tzOffsetSeconds === 0 && this._location.name === 'UTC'
-    } else {
-      // Fall back to JavaScript's timezone offset (for local time)
-      const tzOffsetMinutesJS = this._date.getTimezoneOffset()
-      tzOffsetSeconds = -tzOffsetMinutesJS * 60 // Convert to seconds, negate because JS offset is opposite
-      isUTC = tzOffsetSeconds === 0
-    }
-
+    // Like Go, the Z formats print Z for any zone at UTC's offset.
+    const isUTC = tzOffsetSeconds === 0
     let tzSign = '+'
     if (tzOffsetSeconds < 0) {
       tzSign = '-'
@@ -271,6 +215,7 @@ export class Time {
     const absTzOffsetSeconds = Math.abs(tzOffsetSeconds)
     const tzOffsetHours = Math.floor(absTzOffsetSeconds / 3600)
     const tzOffsetMins = Math.floor((absTzOffsetSeconds % 3600) / 60)
+    const tzOffsetSecs = absTzOffsetSeconds % 60
 
     // Helper function to format fractional seconds
     const formatFracSeconds = (n: number, trimZeros: boolean): string => {
@@ -353,7 +298,7 @@ export class Time {
         if (isUTC) {
           result += 'Z'
         } else {
-          result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}${tzOffsetMins.toString().padStart(2, '0')}00`
+          result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}${tzOffsetMins.toString().padStart(2, '0')}${tzOffsetSecs.toString().padStart(2, '0')}`
         }
         i += 7
         matched = true
@@ -361,7 +306,7 @@ export class Time {
         if (isUTC) {
           result += 'Z'
         } else {
-          result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}:${tzOffsetMins.toString().padStart(2, '0')}:00`
+          result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}:${tzOffsetMins.toString().padStart(2, '0')}:${tzOffsetSecs.toString().padStart(2, '0')}`
         }
         i += 9
         matched = true
@@ -390,11 +335,11 @@ export class Time {
         i += 3
         matched = true
       } else if (remaining.startsWith('-070000')) {
-        result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}${tzOffsetMins.toString().padStart(2, '0')}00`
+        result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}${tzOffsetMins.toString().padStart(2, '0')}${tzOffsetSecs.toString().padStart(2, '0')}`
         i += 7
         matched = true
       } else if (remaining.startsWith('-07:00:00')) {
-        result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}:${tzOffsetMins.toString().padStart(2, '0')}:00`
+        result += `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}:${tzOffsetMins.toString().padStart(2, '0')}:${tzOffsetSecs.toString().padStart(2, '0')}`
         i += 9
         matched = true
       } else if (remaining.startsWith('-0700')) {
@@ -428,8 +373,11 @@ export class Time {
         i += 3
         matched = true
       } else if (remaining.startsWith('MST')) {
-        // Use the actual timezone name instead of literal "MST"
-        result += tzName
+        // Use the zone's abbreviation, or its offset when it has none
+        result +=
+          tzName !== '' ? tzName : (
+            `${tzSign}${tzOffsetHours.toString().padStart(2, '0')}${tzOffsetMins.toString().padStart(2, '0')}`
+          )
         i += 3
         matched = true
       }
@@ -605,31 +553,25 @@ export class Time {
     return Time.create(newDate, newNsec, newMonotonic, this._location)
   }
 
+  // AddDate returns the time on the wall clock of t's location years, months
+  // and days after t, normalized like Date.
   public AddDate(years: number, months: number, days: number): Time {
-    if (this._location.offsetSeconds !== undefined) {
-      const offsetMs = this._location.offsetSeconds * 1000
-      const local = new globalThis.Date(this._date.getTime() + offsetMs)
-      const shifted = globalThis.Date.UTC(
-        local.getUTCFullYear() + years,
-        local.getUTCMonth() + months,
-        local.getUTCDate() + days,
-        local.getUTCHours(),
-        local.getUTCMinutes(),
-        local.getUTCSeconds(),
-        local.getUTCMilliseconds(),
-      )
-      return Time.create(
-        new globalThis.Date(shifted - offsetMs),
-        this._nsec,
-        undefined,
-        this._location,
-      )
-    }
-    const shifted = new globalThis.Date(this._date.getTime())
-    shifted.setFullYear(shifted.getFullYear() + years)
-    shifted.setMonth(shifted.getMonth() + months)
-    shifted.setDate(shifted.getDate() + days)
-    return Time.create(shifted, this._nsec, undefined, this._location)
+    const wall = this.wall()
+    const shifted = wallMillis(
+      wall.getUTCFullYear() + years,
+      wall.getUTCMonth() + months,
+      wall.getUTCDate() + days,
+      wall.getUTCHours(),
+      wall.getUTCMinutes(),
+      wall.getUTCSeconds(),
+      wall.getUTCMilliseconds(),
+    )
+    return Time.create(
+      new globalThis.Date(wallToUnixMillis(shifted, this._location)),
+      this._nsec,
+      undefined,
+      this._location,
+    )
   }
 
   // Equal reports whether t and u represent the same time instant
@@ -685,15 +627,7 @@ export class Time {
 
   // String returns the time formatted as a string
   public String(): string {
-    // Format as "YYYY-MM-DD HH:MM:SS +0000 UTC" to match Go's format
-    const year = this._date.getUTCFullYear()
-    const month = String(this._date.getUTCMonth() + 1).padStart(2, '0')
-    const day = String(this._date.getUTCDate()).padStart(2, '0')
-    const hour = String(this._date.getUTCHours()).padStart(2, '0')
-    const minute = String(this._date.getUTCMinutes()).padStart(2, '0')
-    const second = String(this._date.getUTCSeconds()).padStart(2, '0')
-
-    let result = `${year}-${month}-${day} ${hour}:${minute}:${second} +0000 UTC`
+    let result = this.Format('2006-01-02 15:04:05.999999999 -0700 MST')
 
     // Include monotonic reading in debug output as per Go specification
     if (this._monotonic !== undefined) {
@@ -704,24 +638,47 @@ export class Time {
   }
 
   private wallDateParts(): [number, number, number] {
-    if (this._location.offsetSeconds !== undefined) {
-      const adjustedTime = new globalThis.Date(
-        this._date.getTime() + this._location.offsetSeconds * 1000,
-      )
-      return [
-        adjustedTime.getUTCFullYear(),
-        adjustedTime.getUTCMonth(),
-        adjustedTime.getUTCDate(),
-      ]
-    }
-    return [
-      this._date.getFullYear(),
-      this._date.getMonth(),
-      this._date.getDate(),
-    ]
+    const wall = this.wall()
+    return [wall.getUTCFullYear(), wall.getUTCMonth(), wall.getUTCDate()]
+  }
+
+  // wall returns t's wall clock time in its location as a Date whose UTC
+  // fields hold it.
+  private wall(): globalThis.Date {
+    const [, offset] = this.Zone()
+    return new globalThis.Date(this._date.getTime() + offset * 1000)
   }
 }
 
+// wallMillis returns the wall clock time in milliseconds for the given
+// fields, normalizing out-of-range values like Date. Unlike Date.UTC it
+// does not read years 0 through 99 as 1900 through 1999.
+function wallMillis(
+  year: number,
+  month0: number,
+  day: number,
+  hour: number,
+  min: number,
+  sec: number,
+  ms: number,
+): number {
+  const date = new globalThis.Date(0)
+  date.setUTCFullYear(year, month0, day)
+  date.setUTCHours(hour, min, sec, ms)
+  return date.getTime()
+}
+
+// wallToUnixMillis returns the instant at which the wall clock of loc reads
+// wall. Like Go, it looks up the zone at wall read as UTC, then again at the
+// instant that offset gives, so a time in a daylight saving gap or overlap
+// resolves the way Go's Date does.
+function wallToUnixMillis(wall: number, loc: Location): number {
+  const unix = Math.floor(wall / 1000)
+  const [, guess] = loc.lookup(unix)
+  const [, offset] = loc.lookup(unix - guess)
+  return wall - offset * 1000
+}
+
 // Duration represents a span of time (nanoseconds)
 export type Duration = bigint
 
@@ -922,27 +879,60 @@ function formatUnit(nanos: number, unit: number, suffix: string): string {
   return `${whole}.${fraction}${suffix}`
 }
 
-// Location represents a time zone
+// Location maps time instants to the zone in use at that time: UTC, a fixed
+// offset, or the rules of an IANA time zone such as America/New_York.
 export class Location {
   private _name: string
   private _offsetSeconds?: number
+  private _zone: zoneData | null = null
+  private _load?: () => [string, zoneData]
 
-  constructor(name: string, offsetSeconds?: number) {
+  // zone holds the rules of the location, or a loader that returns its name
+  // and rules on first use, as Local has. Without either, the location has
+  // offsetSeconds, or is UTC.
+  constructor(
+    name: string = '',
+    offsetSeconds?: number,
+    zone?: zoneData | (() => [string, zoneData]),
+  ) {
     this._name = name
     this._offsetSeconds = offsetSeconds
+    if (typeof zone === 'function') {
+      this._load = zone
+    } else {
+      this._zone = zone ?? fixedZoneData(name, offsetSeconds ?? 0)
+    }
   }
 
   public get name(): string {
+    this.rules()
     return this._name
   }
 
+  // offsetSeconds is the offset of a fixed zone, or undefined for a zone
+  // whose offset changes.
   public get offsetSeconds(): number | undefined {
     return this._offsetSeconds
   }
 
+  // lookup returns the abbreviated name and offset in seconds east of UTC of
+  // the zone in use at sec, in Unix seconds.
+  public lookup(sec: number): [string, number] {
+    return lookupZone(this.rules(), sec)
+  }
+
   // String returns a descriptive name for the time zone information
   public String(): string {
-    return this._name
+    return this.name
+  }
+
+  private rules(): zoneData {
+    if (this._zone === null) {
+      const [name, zone] = this._load!()
+      this._name = name
+      this._zone = zone
+    }
+    return this._zone
   }
 }
 
@@ -1025,7 +1015,7 @@ export class ParseError extends Error {
 
 // Timer represents a single event timer
 export class Timer {
-  private _timeout: ReturnType<typeof setTimeout>
+  private _timeout: $.HostTimer
   private _duration: Duration
   private _callback?: () => void
   private _channel = makeChannel(1, new Time(), 'both')
@@ -1039,11 +1029,7 @@ export class Timer {
 
   // Stop prevents the Timer from firing
   public Stop(): boolean {
-    if (typeof this._timeout === 'number') {
-      clearTimeout(this._timeout)
-    } else {
-      clearTimeout(this._timeout)
-    }
+    this._timeout.cancel()
     return true
   }
 
@@ -1055,20 +1041,24 @@ export class Timer {
     return true
   }
 
-  private start(d: Duration): ReturnType<typeof setTimeout> {
+  private start(d: Duration): $.HostTimer {
     const ms = timeoutMilliseconds(d)
-    if (this._callback) {
-      return setTimeout(this._callback, ms)
+    const callback = this._callback
+    if (callback) {
+      // AfterFunc runs f in its own goroutine.
+      return $.hostTimeout(() => $.go(callback, 'time.goFunc'), ms)
     }
-    return setTimeout(() => {
-      this._channel.send(Now()).catch(() => {})
+    // Timer channels have capacity 1; like Go, drop a value nobody received
+    // instead of blocking the host callback.
+    return $.hostTimeout(() => {
+      this._channel.trySelectSend(Now(), 0)
     }, ms)
   }
 }
 
 // Ticker holds a channel that delivers ticks at intervals
 export class Ticker {
-  private _interval: ReturnType<typeof setInterval>
+  private _interval: $.HostTimer
   private _duration: Duration
   private _stopped: boolean = false
   private _channel = makeChannel(1, new Time(), 'both')
@@ -1082,11 +1072,7 @@ export class Ticker {
   // Stop turns off a ticker
   public Stop(): void {
     this._stopped = true
-    if (typeof this._interval === 'number') {
-      clearInterval(this._interval)
-    } else {
-      clearInterval(this._interval)
-    }
+    this._interval.cancel()
   }
 
   // Reset stops a ticker and resets its period to the specified duration
@@ -1104,27 +1090,27 @@ export class Ticker {
     }
   }
 
-  private start(d: Duration): ReturnType<typeof setInterval> {
+  private start(d: Duration): $.HostTimer {
     const ms = timeoutMilliseconds(d)
-    return setInterval(() => {
-      this._channel.send(Now()).catch(() => {})
+    // Like Go, drop ticks for a slow receiver instead of queueing them.
+    return $.hostInterval(() => {
+      this._channel.trySelectSend(Now(), 0)
     }, ms)
   }
 }
 
-// Now returns the current local time with monotonic clock reading
+// Now returns the current local time with monotonic clock reading, both read
+// from the clock of the running goroutine's synctest bubble, or the host
+// runtime's clock outside bubbles
 export function Now(): Time {
-  const date = new globalThis.Date()
-  let monotonic: number | undefined
+  const clock = $.currentClock()
+  const date = new globalThis.Date(clock.now())
 
-  // Use performance.now() for high-resolution monotonic timing if available
-  if (typeof performance !== 'undefined' && performance.now) {
-    // performance.now() returns milliseconds with sub-millisecond precision.
-    // Convert to nanoseconds and floor: the monotonic field is integer
-    // nanoseconds, matching Go's int64 monotonic clock. A fractional value here
-    // reaches Sub/BigInt and throws a RangeError ("not an integer").
-    monotonic = Math.floor(performance.now() * 1000000)
-  }
+  // The clock's monotonic reading is in milliseconds with sub-millisecond
+  // precision. Convert to nanoseconds and floor: the monotonic field is integer
+  // nanoseconds, matching Go's int64 monotonic clock. A fractional value here
+  // reaches Sub/BigInt and throws a RangeError ("not an integer").
+  const monotonic = Math.floor(clock.monotonic() * 1000000)
 
   return Time.create(date, 0, monotonic)
 }
@@ -1147,40 +1133,22 @@ export function Date(
     throw new Error('time: missing Location in call to Date')
   }
   loc = $.pointerValue(loc)
-  let date: globalThis.Date
-
-  if (loc.offsetSeconds !== undefined) {
-    // For fixed timezone locations, create the date in the local timezone and then convert to UTC
-    const localTime = globalThis.Date.UTC(
-      year,
-      month - 1,
-      day,
-      hour,
-      min,
-      sec,
-      Math.floor(nsec / 1000000),
-    )
-    // Subtract the offset to convert local time to UTC
-    // (if offset is -7*3600 for PDT, local time - (-7*3600) = local time + 7*3600 = UTC)
-    date = new globalThis.Date(localTime - loc.offsetSeconds * 1000)
-  } else {
-    // For local time or other timezones, use regular Date constructor
-    date = new globalThis.Date(
-      year,
-      month - 1,
-      day,
-      hour,
-      min,
-      sec,
-      Math.floor(nsec / 1000000),
-    )
-  }
+  const wall = wallMillis(
+    year,
+    month - 1,
+    day,
+    hour,
+    min,
+    sec,
+    Math.floor(nsec / 1000000),
+  )
+  const date = new globalThis.Date(wallToUnixMillis(wall, loc))
   return Time.create(date, nsec % 1000000000, undefined, loc) // No monotonic reading
 }
 
 // Common locations
 export const UTC = new Location('UTC', 0)
-export const Local = new Location('Local')
+export const Local = new Location('Local', undefined, localZoneData)
 
 // FixedZone returns a Location that always uses the given zone name and offset (seconds east of UTC)
 export function FixedZone(name: string, offset: number): Location {
@@ -1210,7 +1178,12 @@ export function Until(t: Time): Duration {
 // Sleep pauses the current execution for at least the duration d
 export async function Sleep(d: Duration): Promise<void> {
   const ms = timeoutMilliseconds(d)
-  return new Promise((resolve) => setTimeout(resolve, ms))
+  await $.park(
+    'sleep',
+    new Promise<void>((resolve) => {
+      $.hostTimeout(resolve, ms)
+    }),
+  )
 }
 
 // Export month constants
@@ -1414,8 +1387,8 @@ export function After(d: Duration): ChannelRef<Time> {
   const channel = makeChannel(1, new Time(), 'both')
 
   // Start a timer that will send the current time after the duration
-  setTimeout(async () => {
-    channel.send(Now()).catch(() => {})
+  $.hostTimeout(() => {
+    channel.trySelectSend(Now(), 0)
   }, ms)
 
   return makeChannelRef(channel, 'receive')
@@ -1441,26 +1414,39 @@ export function Tick(d: Duration): ChannelRef<Time> {
   return new Ticker(d).C
 }
 
-// LoadLocation returns the Location with the given name
-// This is a simplified implementation that only supports UTC and Local
+// LoadLocation returns the Location with the given name: UTC for "" or
+// "UTC", Local for "Local", and otherwise the IANA time zone of that name,
+// such as "America/New_York", read from the host's zone database. Hosts
+// without one, such as browsers, use the zone rules of their Intl API.
 export function LoadLocation(name: string): [Location | null, $.GoError] {
-  switch (name) {
-    case '':
-    case 'UTC':
-      return [UTC, null]
-    case 'Local':
-      return [Local, null]
-    default:
-      return [null, $.newError(`time: unknown time zone ${name}`)]
+  if (name === '' || name === 'UTC') {
+    return [UTC, null]
+  }
+  if (name === 'Local') {
+    return [Local, null]
   }
+  if (name.includes('..') || name[0] === '/' || name[0] === '\\') {
+    // No valid IANA Time Zone name contains a single dot, much less dot
+    // dot. Likewise, none begin with a slash.
+    return [null, $.newError('time: invalid location name')]
+  }
+  const [zone, err] = loadZoneData(name)
+  if (zone === null) {
+    return [null, $.newError(err)]
+  }
+  return [new Location(name, undefined, zone), null]
 }
 
 // LoadLocationFromTZData returns a Location with the given name
-// This is a simplified implementation
+// initialized from data in the TZif format of the IANA Time Zone database,
+// such as the content of /etc/localtime.
 export function LoadLocationFromTZData(
   name: string,
-  _data: Uint8Array,
+  data: $.Bytes,
 ): [Location | null, $.GoError] {
-  // TODO: parse the timezone data
-  return [new Location(name), null]
+  const zone = parseTZData($.bytesToUint8Array(data))
+  if (zone === null) {
+    return [null, $.newError(errBadData)]
+  }
+  return [new Location(name, undefined, zone), null]
 }
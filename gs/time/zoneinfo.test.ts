@@ -0,0 +1,162 @@
+import { afterEach, describe, expect, it } from 'vitest'
+
+import * as $ from '@goscript/builtin/index.js'
+
+import { Date, LoadLocation, LoadLocationFromTZData, Unix } from './time.js'
+import { lookupZone, parseTZData } from './zoneinfo.js'
+
+const layout = '2006-01-02 15:04:05 MST -0700'
+
+// tzif encodes a version 2 TZif file with an empty version 1 block, the
+// given zones as [name, offset, isDST], transitions as [when, zone], and
+// the POSIX TZ string extend.
+function tzif(
+  zones: [string, number, boolean][],
+  transitions: [number, number][],
+  extend: string,
+): Uint8Array {
+  let chars = ''
+  const abbrs = zones.map(([name]) => {
+    const index = chars.length
+    chars += name + '\0'
+    return index
+  })
+  const bytes: number[] = []
+  const u32 = (n: number) => {
+    bytes.push(
+      (n >>> 24) & 0xff,
+      (n >>> 16) & 0xff,
+      (n >>> 8) & 0xff,
+      n & 0xff,
+    )
+  }
+  const header = (counts: number[]) => {
+    bytes.push(...Array.from('TZif2', (c) => c.charCodeAt(0)))
+    bytes.push(...new Array(15).fill(0))
+    counts.forEach(u32)
+  }
+  header([0, 0, 0, 0, 0, 0])
+  header([0, 0, 0, transitions.length, zones.length, chars.length])
+  for (const [when] of transitions) {
+    u32(Math.floor(when / 2 ** 32))
+    u32(when >>> 0)
+  }
+  bytes.push(...transitions.map(([, zone]) => zone))
+  zones.forEach(([, offset, isDST], i) => {
+    u32(offset)
+    bytes.push(isDST ? 1 : 0, abbrs[i])
+  })
+  bytes.push(...Array.from(chars, (c) => c.charCodeAt(0)))
+  bytes.push(...Array.from('\n' + extend + '\n', (c) => c.charCodeAt(0)))
+  return new Uint8Array(bytes)
+}
+
+// eastern has New York's rules since 2007: a transition to EST at the start
+// of 2007, then the US daylight saving rules.
+const eastern = tzif(
+  [
+    ['LMT', -17762, false],
+    ['EST', -18000, false],
+  ],
+  [[1167627600, 1]],
+  'EST5EDT,M3.2.0,M11.1.0',
+)
+
+let unmount: (() => void) | null = null
+
+afterEach(() => {
+  unmount?.()
+  unmount = null
+})
+
+describe('time zone data', () => {
+  it('parses TZif data and extends it with its TZ string', () => {
+    const zone = parseTZData(eastern)!
+    expect(zone.zones.map((z) => z.name)).toEqual(['LMT', 'EST'])
+    expect(lookupZone(zone, 0)).toEqual(['LMT', -17762])
+    expect(lookupZone(zone, 1167627600)).toEqual(['EST', -18000])
+    expect(lookupZone(zone, 1710054000)).toEqual(['EDT', -14400])
+    expect(lookupZone(zone, 1710053999)).toEqual(['EST', -18000])
+    expect(lookupZone(zone, 1730613600)).toEqual(['EST', -18000])
+    expect(lookupZone(zone, 4121132400)).toEqual(['EDT', -14400])
+  })
+
+  it('rejects malformed data', () => {
+    expect(parseTZData(new Uint8Array([1, 2, 3]))).toBeNull()
+    expect(parseTZData(eastern.subarray(0, 60))).toBeNull()
+  })
+
+  it('formats times across daylight saving transitions', () => {
+    const [loc, err] = LoadLocationFromTZData('America/New_York', eastern)
+    expect(err).toBeNull()
+    expect(loc!.String()).toBe('America/New_York')
+
+    expect(Unix(1710053999, 0).In(loc).Format(layout)).toBe(
+      '2024-03-10 01:59:59 EST -0500',
+    )
+    expect(Unix(1710054000, 0).In(loc).Format(layout)).toBe(
+      '2024-03-10 03:00:00 EDT -0400',
+    )
+    expect(Unix(1730613600, 0).In(loc).Zone()).toEqual(['EST', -18000])
+    expect(Unix(1730613600, 0).In(loc).String()).toBe(
+      '2024-11-03 01:00:00 -0500 EST',
+    )
+  })
+
+  it('resolves wall times in gaps and overlaps like Go', () => {
+    const [loc] = LoadLocationFromTZData('America/New_York', eastern)
+
+    // 02:30 does not exist on 2024-03-10; Go picks 01:30 EST.
+    expect(Date(2024, 3, 10, 2, 30, 0, 0, loc).Unix()).toBe(1710052200n)
+    // 01:30 happens twice on 2024-11-03; Go picks the first, in EDT.
+    expect(Date(2024, 11, 3, 1, 30, 0, 0, loc).Format(layout)).toBe(
+      '2024-11-03 01:30:00 EDT -0400',
+    )
+    expect(
+      Date(2024, 3, 9, 12, 0, 0, 0, loc).AddDate(0, 0, 1).Format(layout),
+    ).toBe('2024-03-10 12:00:00 EDT -0400')
+  })
+})
+
+describe('time.LoadLocation', () => {
+  it('reads zones from the host zone database', () => {
+    const fsys = new $.MemFS()
+    fsys.mkdirSync('/usr/share/zoneinfo/America', { recursive: true })
+    fsys.writeFileSync('/usr/share/zoneinfo/America/New_York', eastern)
+    unmount = $.mountHostFS(fsys)
+
+    const [loc, err] = LoadLocation('America/New_York')
+    expect(err).toBeNull()
+    expect(loc!.String()).toBe('America/New_York')
+    expect(Unix(1720000000, 0).In(loc).Format(layout)).toBe(
+      '2024-07-03 05:46:40 EDT -0400',
+    )
+
+    fsys.mkdirSync('/usr/share/zoneinfo/Bad')
+    fsys.writeFileSync('/usr/share/zoneinfo/Bad/Zone', 'not tzif')
+    const [, bad] = LoadLocation('Bad/Zone')
+    expect(bad?.Error()).toBe('malformed time zone information')
+  })
+
+  it('falls back to Intl without a zone database', () => {
+    unmount = $.mountHostFS(new $.MemFS())
+
+    const [ny, err] = LoadLocation('America/New_York')
+    expect(err).toBeNull()
+    expect(Unix(1720000000, 0).In(ny).Format(layout)).toBe(
+      '2024-07-03 05:46:40 EDT -0400',
+    )
+    expect(Unix(1710054000, 0).In(ny).Zone()).toEqual(['EDT', -14400])
+    expect(Date(2024, 1, 15, 9, 0, 0, 0, ny).Unix()).toBe(1705327200n)
+
+    // Intl names Berlin's zones by offset, as tzdata does for zones
+    // without an abbreviation.
+    const [berlin] = LoadLocation('Europe/Berlin')
+    expect(Unix(1720000000, 0).In(berlin).Format(layout)).toBe(
+      '2024-07-03 11:46:40 +02 +0200',
+    )
+
+    const [, missing] = LoadLocation('America/NotReal')
+    expect(missing?.Error()).toBe('unknown time zone America/NotReal')
+  })
+})
@@ -0,0 +1,740 @@
+import * as $ from '../builtin/index.js'
+
+// Time zone rules, ported from Go's zoneinfo.go and zoneinfo_read.go.
+//
+// A named Location is loaded from the IANA Time Zone database the way Go does
+// on Unix: from the directory named by $ZONEINFO, then from the system
+// directories, read through the host filesystem. Hosts without the database,
+// such as browsers, fall back to the zone rules of the host's Intl API.
+
+// zone is a time zone such as CET, with its offset in seconds east of UTC.
+export interface zone {
+  name: string
+  offset: number
+  isDST: boolean
+}
+
+// zoneTrans is a transition to zones[index] at when, in Unix seconds.
+interface zoneTrans {
+  when: number
+  index: number
+}
+
+// zoneData holds the rules of a Location. Zones loaded from TZif data use
+// zones and tx, with the POSIX TZ string extend for times after the last
+// transition. Zones the host knows but whose data could not be read use
+// host instead.
+export interface zoneData {
+  zones: zone[]
+  tx: zoneTrans[]
+  extend: string
+  host?: (sec: number) => [string, number]
+}
+
+const alpha = -Infinity
+const secondsPerMinute = 60
+const secondsPerHour = 60 * secondsPerMinute
+const secondsPerDay = 24 * secondsPerHour
+
+// maxFileSize is the largest TZif file Go reads.
+const maxFileSize = 10 << 20
+
+// platformZoneSources lists the directories Go searches on Unix.
+const platformZoneSources = [
+  '/usr/share/zoneinfo/',
+  '/usr/share/lib/zoneinfo/',
+  '/usr/lib/locale/TZ/',
+  '/etc/zoneinfo',
+]
+
+export const errBadData = 'malformed time zone information'
+
+// fixedZoneData returns the rules of a zone that always has offset.
+export function fixedZoneData(name: string, offset: number): zoneData {
+  return {
+    zones: [{ name, offset, isDST: false }],
+    tx: [{ when: alpha, index: 0 }],
+    extend: '',
+  }
+}
+
+// lookupZone returns the abbreviated name and offset of the zone in effect
+// at sec, in Unix seconds.
+export function lookupZone(data: zoneData, sec: number): [string, number] {
+  if (data.host) {
+    return data.host(sec)
+  }
+  const { zones, tx } = data
+  if (zones.length === 0) {
+    return ['UTC', 0]
+  }
+  if (tx.length === 0 || sec < tx[0]!.when) {
+    const first = zones[lookupFirstZone(data)]!
+    return [first.name, first.offset]
+  }
+
+  // Binary search for the entry with the largest time <= sec.
+  let lo = 0
+  let hi = tx.length
+  while (hi - lo > 1) {
+    const m = (lo + hi) >>> 1
+    if (sec < tx[m]!.when) {
+      hi = m
+    } else {
+      lo = m
+    }
+  }
+  const found = zones[tx[lo]!.index]!
+
+  // At the end of the known transitions, try the extend string.
+  if (lo === tx.length - 1 && data.extend !== '') {
+    const extended = tzset(data.extend, sec)
+    if (extended) {
+      return extended
+    }
+  }
+  return [found.name, found.offset]
+}
+
+// lookupFirstZone returns the index of the zone used before the first
+// transition, following localtime.c from tzcode.
+function lookupFirstZone(data: zoneData): number {
+  const { zones, tx } = data
+  if (!tx.some((t) => t.index === 0)) {
+    return 0
+  }
+  if (tx.length > 0 && zones[tx[0]!.index]!.isDST) {
+    for (let zi = tx[0]!.index - 1; zi >= 0; zi--) {
+      if (!zones[zi]!.isDST) {
+        return zi
+      }
+    }
+  }
+  const std = zones.findIndex((z) => !z.isDST)
+  return std === -1 ? 0 : std
+}
+
+// tzset returns the zone in effect at sec under the POSIX TZ string s, such
+// as "EST5EDT,M3.2.0,M11.1.0". It returns null when s does not parse.
+function tzset(s: string, sec: number): [string, number] | null {
+  const std = tzsetName(s)
+  const stdOff = std && tzsetOffset(std[1])
+  if (!std || !stdOff) {
+    return null
+  }
+  // TZ offsets are added to local time to get UTC; ours are the reverse.
+  let stdName = std[0]
+  let stdOffset = -stdOff[0]
+  s = stdOff[1]
+  if (s.length === 0 || s[0] === ',') {
+    // No daylight saving time.
+    return [stdName, stdOffset]
+  }
+
+  const dst = tzsetName(s)
+  if (!dst) {
+    return null
+  }
+  let dstName = dst[0]
+  let dstOffset = stdOffset + secondsPerHour
+  s = dst[1]
+  if (s.length !== 0 && s[0] !== ',') {
+    const dstOff = tzsetOffset(s)
+    if (!dstOff) {
+      return null
+    }
+    dstOffset = -dstOff[0]
+    s = dstOff[1]
+  }
+
+  if (s.length === 0) {
+    // Default DST rules per tzcode.
+    s = ',M3.2.0,M11.1.0'
+  }
+  // The TZ definition does not mention ';' here but tzcode accepts it.
+  if (s[0] !== ',' && s[0] !== ';') {
+    return null
+  }
+  const startRule = tzsetRule(s.slice(1))
+  if (!startRule || startRule[1][0] !== ',') {
+    return null
+  }
+  const endRule = tzsetRule(startRule[1].slice(1))
+  if (!endRule || endRule[1].length !== 0) {
+    return null
+  }
+
+  const year = new globalThis.Date(sec * 1000).getUTCFullYear()
+  const ystart = globalThis.Date.UTC(year, 0, 1) / 1000
+  const ysec = sec - ystart
+
+  let startSec = tzruleTime(year, startRule[0], stdOffset)
+  let endSec = tzruleTime(year, endRule[0], dstOffset)
+  // In the southern hemisphere daylight saving time spans the new year.
+  if (endSec < startSec) {
+    ;[startSec, endSec] = [endSec, startSec]
+    ;[stdName, dstName] = [dstName, stdName]
+    ;[stdOffset, dstOffset] = [dstOffset, stdOffset]
+  }
+  if (ysec < startSec || ysec >= endSec) {
+    return [stdName, stdOffset]
+  }
+  return [dstName, dstOffset]
+}
+
+// tzsetName returns the zone name at the start of s and the rest of s.
+function tzsetName(s: string): [string, string] | null {
+  if (s.length === 0) {
+    return null
+  }
+  if (s[0] === '<') {
+    const end = s.indexOf('>')
+    return end === -1 ? null : [s.slice(1, end), s.slice(end + 1)]
+  }
+  const end = s.search(/[0-9,+-]/)
+  if (end === -1) {
+    return s.length < 3 ? null : [s, '']
+  }
+  return end < 3 ? null : [s.slice(0, end), s.slice(end)]
+}
+
+// tzsetOffset returns the offset in seconds at the start of s, such as
+// "-5:30", and the rest of s.
+function tzsetOffset(s: string): [number, string] | null {
+  if (s.length === 0) {
+    return null
+  }
+  let neg = false
+  if (s[0] === '+') {
+    s = s.slice(1)
+  } else if (s[0] === '-') {
+    s = s.slice(1)
+    neg = true
+  }
+
+  // The tzdata code permits values up to 24 * 7 here, although POSIX does
+  // not.
+  const hours = tzsetNum(s, 0, 24 * 7)
+  if (!hours) {
+    return null
+  }
+  let off = hours[0] * secondsPerHour
+  s = hours[1]
+  if (s[0] === ':') {
+    const mins = tzsetNum(s.slice(1), 0, 59)
+    if (!mins) {
+      return null
+    }
+    off += mins[0] * secondsPerMinute
+    s = mins[1]
+    if (s[0] === ':') {
+      const secs = tzsetNum(s.slice(1), 0, 59)
+      if (!secs) {
+        return null
+      }
+      off += secs[0]
+      s = secs[1]
+    }
+  }
+  return [neg ? -off : off, s]
+}
+
+// rule is a transition rule of a TZ string: Jn, n or Mm.w.d, at time
+// seconds after local midnight.
+interface rule {
+  kind: 'julian' | 'doy' | 'monthWeekDay'
+  day: number
+  week: number
+  mon: number
+  time: number
+}
+
+// tzsetRule returns the rule at the start of s and the rest of s.
+function tzsetRule(s: string): [rule, string] | null {
+  const r: rule = { kind: 'doy', day: 0, week: 0, mon: 0, time: 0 }
+  if (s.length === 0) {
+    return null
+  }
+  if (s[0] === 'J') {
+    const jday = tzsetNum(s.slice(1), 1, 365)
+    if (!jday) {
+      return null
+    }
+    r.kind = 'julian'
+    r.day = jday[0]
+    s = jday[1]
+  } else if (s[0] === 'M') {
+    const mon = tzsetNum(s.slice(1), 1, 12)
+    if (!mon || mon[1][0] !== '.') {
+      return null
+    }
+    const week = tzsetNum(mon[1].slice(1), 1, 5)
+    if (!week || week[1][0] !== '.') {
+      return null
+    }
+    const day = tzsetNum(week[1].slice(1), 0, 6)
+    if (!day) {
+      return null
+    }
+    r.kind = 'monthWeekDay'
+    r.mon = mon[0]
+    r.week = week[0]
+    r.day = day[0]
+    s = day[1]
+  } else {
+    const day = tzsetNum(s, 0, 365)
+    if (!day) {
+      return null
+    }
+    r.day = day[0]
+    s = day[1]
+  }
+
+  if (s[0] !== '/') {
+    r.time = 2 * secondsPerHour // 2am is the default
+    return [r, s]
+  }
+  const offset = tzsetOffset(s.slice(1))
+  if (!offset) {
+    return null
+  }
+  r.time = offset[0]
+  return [r, offset[1]]
+}
+
+// tzsetNum returns the number between min and max at the start of s and the
+// rest of s.
+function tzsetNum(
+  s: string,
+  min: number,
+  max: number,
+): [number, string] | null {
+  const digits = /^[0-9]+/.exec(s)?.[0]
+  if (!digits) {
+    return null
+  }
+  const num = Number(digits)
+  if (num < min || num > max) {
+    return null
+  }
+  return [num, s.slice(digits.length)]
+}
+
+// tzruleTime returns the number of seconds after the start of year at which
+// r takes effect in a zone with offset off.
+function tzruleTime(year: number, r: rule, off: number): number {
+  let s = 0
+  switch (r.kind) {
+    case 'julian':
+      s = (r.day - 1) * secondsPerDay
+      if (isLeap(year) && r.day >= 60) {
+        s += secondsPerDay
+      }
+      break
+    case 'doy':
+      s = r.day * secondsPerDay
+      break
+    case 'monthWeekDay': {
+      // Zeller's Congruence.
+      const m1 = ((r.mon + 9) % 12) + 1
+      let yy0 = year
+      if (r.mon <= 2) {
+        yy0--
+      }
+      const yy1 = Math.trunc(yy0 / 100)
+      const yy2 = yy0 % 100
+      let dow =
+        (Math.trunc((26 * m1 - 2) / 10) +
+          1 +
+          yy2 +
+          Math.trunc(yy2 / 4) +
+          Math.trunc(yy1 / 4) -
+          2 * yy1) %
+        7
+      if (dow < 0) {
+        dow += 7
+      }
+      // dow is the day of the week of the first day of r.mon. Find the
+      // day of the month of the first r.day.
+      let d = r.day - dow
+      if (d < 0) {
+        d += 7
+      }
+      const daysInMonth = new globalThis.Date(
+        globalThis.Date.UTC(year, r.mon, 0),
+      ).getUTCDate()
+      for (let i = 1; i < r.week; i++) {
+        if (d + 7 >= daysInMonth) {
+          break
+        }
+        d += 7
+      }
+      const daysBefore =
+        (globalThis.Date.UTC(year, r.mon - 1, 1) -
+          globalThis.Date.UTC(year, 0, 1)) /
+        (secondsPerDay * 1000)
+      s = (d + daysBefore) * secondsPerDay
+      break
+    }
+  }
+  return s + r.time - off
+}
+
+function isLeap(year: number): boolean {
+  return year % 4 === 0 && (year % 100 !== 0 || year % 400 === 0)
+}
+
+// parseTZData parses data in the TZif format of the IANA Time Zone database,
+// such as the content of /etc/localtime. It returns null for malformed data.
+export function parseTZData(data: Uint8Array): zoneData | null {
+  const view = new DataView(data.buffer, data.byteOffset, data.byteLength)
+  let p = 0
+  const read = (n: number): Uint8Array | null => {
+    if (n < 0 || p + n > data.length) {
+      return null
+    }
+    const out = data.subarray(p, p + n)
+    p += n
+    return out
+  }
+  const counts = (): number[] | null => {
+    if (p + 24 > data.length) {
+      return null
+    }
+    const n: number[] = []
+    for (let i = 0; i < 6; i++) {
+      n.push(view.getUint32(p))
+      p += 4
+    }
+    return n
+  }
+
+  // 4-byte magic "TZif", a 1-byte version, then 15 bytes of padding.
+  const magic = read(4)
+  if (!magic || String.fromCharCode(...magic) !== 'TZif') {
+    return null
+  }
+  const header = read(16)
+  if (!header) {
+    return null
+  }
+  const versionByte = header[0]!
+  if (versionByte !== 0 && versionByte !== 0x32 && versionByte !== 0x33) {
+    return null
+  }
+
+  // Counts of UTC/local indicators, standard/wall indicators, leap
+  // seconds, transition times, zones and abbreviation characters.
+  const NUTCLocal = 0
+  const NStdWall = 1
+  const NLeap = 2
+  const NTime = 3
+  const NZone = 4
+  const NChar = 5
+  let n = counts()
+  if (!n) {
+    return null
+  }
+
+  // Version 2 and 3 data repeat the tables with 64-bit times after the
+  // 32-bit ones. Skip to the 64-bit tables, which cover more dates.
+  let is64 = false
+  if (versionByte !== 0) {
+    const skip =
+      n[NTime]! * 5 +
+      n[NZone]! * 6 +
+      n[NChar]! +
+      n[NLeap]! * 8 +
+      n[NStdWall]! +
+      n[NUTCLocal]!
+    if (!read(skip + 4 + 16)) {
+      return null
+    }
+    is64 = true
+    n = counts()
+    if (!n) {
+      return null
+    }
+  }
+  const size = is64 ? 8 : 4
+
+  const txTimesStart = p
+  const txZones = read(n[NTime]! * size) && read(n[NTime]!)
+  const zoneStart = p
+  const zoneBytes = read(n[NZone]! * 6)
+  const abbrev = read(n[NChar]!)
+  const leaps = read(n[NLeap]! * (size + 4))
+  const isstd = read(n[NStdWall]!)
+  const isutc = read(n[NUTCLocal]!)
+  if (!txZones || !zoneBytes || !abbrev || !leaps || !isstd || !isutc) {
+    return null
+  }
+
+  let extend = ''
+  const rest = data.subarray(p)
+  if (rest.length > 2 && rest[0] === 10 && rest[rest.length - 1] === 10) {
+    extend = new TextDecoder().decode(rest.subarray(1, rest.length - 1))
+  }
+
+  // Reject data with no zones; there is nothing useful in it.
+  if (n[NZone] === 0) {
+    return null
+  }
+  const zones: zone[] = []
+  for (let i = 0; i < n[NZone]!; i++) {
+    const at = zoneStart + i * 6
+    const nameIndex = data[at + 5]!
+    if (nameIndex >= abbrev.length) {
+      return null
+    }
+    let nameEnd = abbrev.indexOf(0, nameIndex)
+    if (nameEnd === -1) {
+      nameEnd = abbrev.length
+    }
+    zones.push({
+      name: String.fromCharCode(...abbrev.subarray(nameIndex, nameEnd)),
+      offset: view.getInt32(at),
+      isDST: data[at + 4] !== 0,
+    })
+  }
+
+  const tx: zoneTrans[] = []
+  for (let i = 0; i < n[NTime]!; i++) {
+    const at = txTimesStart + i * size
+    const when =
+      is64 ? Number(view.getBigInt64(at)) : view.getInt32(at)
+    const index = txZones[i]!
+    if (index >= zones.length) {
+      return null
+    }
+    tx.push({ when, index })
+  }
+  if (tx.length === 0) {
+    // Fixed zones such as Etc/GMT0 have no transitions.
+    tx.push({ when: alpha, index: 0 })
+  }
+  return { zones, tx, extend }
+}
+
+// loadZoneData returns the rules of the IANA time zone name, or the error
+// LoadLocation reports.
+export function loadZoneData(name: string): [zoneData | null, string] {
+  let firstErr = ''
+  const [zoneinfo] = hostEnv('ZONEINFO')
+  if (zoneinfo !== '') {
+    const [data, err] = readHostFile(zoneinfo + '/' + name)
+    if (data) {
+      const zone = parseTZData(data)
+      if (zone) {
+        return [zone, '']
+      }
+      firstErr = errBadData
+    } else if (err !== 'ENOENT') {
+      firstErr = err
+    }
+  }
+  const [zone, err] = loadZoneFromSources(name, platformZoneSources)
+  if (zone) {
+    return [zone, '']
+  }
+  const intl = intlZoneData(name)
+  if (intl) {
+    return [intl, '']
+  }
+  return [null, firstErr || err]
+}
+
+// loadZoneFromSources reads name from the first of sources that has it.
+function loadZoneFromSources(
+  name: string,
+  sources: string[],
+): [zoneData | null, string] {
+  let firstErr = ''
+  for (const source of sources) {
+    const path = source === '' ? name : source.replace(/\/?$/, '/') + name
+    const [data, err] = readHostFile(path)
+    if (data) {
+      const zone = parseTZData(data)
+      if (zone) {
+        return [zone, '']
+      }
+      firstErr ||= errBadData
+    } else if (err !== 'ENOENT') {
+      firstErr ||= err
+    }
+  }
+  return [null, firstErr || 'unknown time zone ' + name]
+}
+
+// localZoneData returns the name and rules of the Local location, following
+// Go's initLocal on Unix: $TZ names the zone, no $TZ means /etc/localtime,
+// and an empty or unknown $TZ means UTC. Hosts without a zone database use
+// the zone their Intl API reports.
+export function localZoneData(): [string, zoneData] {
+  let [tz, set] = hostEnv('TZ')
+  if (!set) {
+    const [zone] = loadZoneFromSources('localtime', ['/etc'])
+    if (zone) {
+      return ['Local', zone]
+    }
+    const host = hostLocalZoneData()
+    if (host) {
+      return ['Local', host]
+    }
+  } else if (tz !== '') {
+    if (tz[0] === ':') {
+      tz = tz.slice(1)
+    }
+    if (tz !== '' && tz[0] === '/') {
+      const [zone] = loadZoneFromSources(tz, [''])
+      if (zone) {
+        return [tz === '/etc/localtime' ? 'Local' : tz, zone]
+      }
+    } else if (tz !== '' && tz !== 'UTC') {
+      const [zone] = loadZoneFromSources(tz, platformZoneSources)
+      const found = zone ?? intlZoneData(tz)
+      if (found) {
+        return [tz, found]
+      }
+    }
+  }
+  return ['UTC', { zones: [], tx: [], extend: '' }]
+}
+
+// hostLocalZoneData returns the rules of the host's own time zone from its
+// Intl API or, failing that, its Date offsets. Like Go's js/wasm port, the
+// latter names zones from their offset, such as "UTC-5".
+function hostLocalZoneData(): zoneData | null {
+  try {
+    const name = new Intl.DateTimeFormat().resolvedOptions().timeZone
+    const zone = name ? intlZoneData(name) : null
+    if (zone) {
+      return zone
+    }
+  } catch {
+    // Fall back to Date offsets below.
+  }
+  return {
+    zones: [],
+    tx: [],
+    extend: '',
+    host: (sec: number): [string, number] => {
+      const offset = -new globalThis.Date(sec * 1000).getTimezoneOffset()
+      const abs = Math.abs(offset)
+      let name = (offset < 0 ? 'UTC-' : 'UTC+') + String(Math.floor(abs / 60))
+      if (abs % 60 !== 0) {
+        name += ':' + String(abs % 60)
+      }
+      return [name, offset * 60]
+    },
+  }
+}
+
+// intlZoneData returns rules for the IANA time zone name from the host's
+// Intl API, or null when the host does not know it. Intl has no
+// abbreviation for some zones that tzdata names, such as CET; those zones
+// are named by their offset, as tzdata does for zones without one, such as
+// "+01".
+function intlZoneData(name: string): zoneData | null {
+  if (typeof Intl === 'undefined' || /^[+-]/.test(name)) {
+    return null
+  }
+  let format: Intl.DateTimeFormat
+  try {
+    format = new Intl.DateTimeFormat('en-US', {
+      timeZone: name,
+      hourCycle: 'h23',
+      year: 'numeric',
+      month: 'numeric',
+      day: 'numeric',
+      hour: 'numeric',
+      minute: 'numeric',
+      second: 'numeric',
+      timeZoneName: 'short',
+    })
+  } catch {
+    return null
+  }
+  return {
+    zones: [],
+    tx: [],
+    extend: '',
+    host: (sec: number): [string, number] => {
+      const fields: Record<string, string> = {}
+      for (const part of format.formatToParts(sec * 1000)) {
+        fields[part.type] = part.value
+      }
+      const wall = globalThis.Date.UTC(
+        Number(fields.year),
+        Number(fields.month) - 1,
+        Number(fields.day),
+        Number(fields.hour),
+        Number(fields.minute),
+        Number(fields.second),
+      )
+      const offset = Math.round((wall - sec * 1000) / 1000)
+      return [intlZoneName(fields.timeZoneName ?? '', offset), offset]
+    },
+  }
+}
+
+// intlZoneName turns an Intl short zone name such as "EST" or "GMT+1" into
+// the abbreviation tzdata would use.
+function intlZoneName(name: string, offset: number): string {
+  if (name !== '' && !/^(GMT|UTC)[+-]/.test(name)) {
+    return name
+  }
+  const abs = Math.abs(offset)
+  let text = (offset < 0 ? '-' : '+') + pad2(Math.floor(abs / 3600))
+  if (abs % 3600 !== 0) {
+    text += pad2(Math.floor((abs % 3600) / 60))
+  }
+  return text
+}
+
+function pad2(n: number): string {
+  return String(n).padStart(2, '0')
+}
+
+// hostEnv returns the environment variable name and whether it is set.
+function hostEnv(name: string): [string, boolean] {
+  const runtime = $.getHostRuntime()
+  try {
+    const value =
+      runtime.deno?.env?.get ?
+        runtime.deno.env.get(name)
+      : runtime.processObj?.env?.[name]
+    return typeof value === 'string' ? [value, true] : ['', false]
+  } catch {
+    return ['', false]
+  }
+}
+
+// readHostFile reads path through the host filesystem. It returns the error
+// code of a failed read, such as "ENOENT".
+function readHostFile(path: string): [Uint8Array | null, string] {
+  const runtime = $.getHostRuntime()
+  try {
+    let data: Uint8Array | null = null
+    if (runtime.nodeFS?.readFileSync) {
+      data = runtime.nodeFS.readFileSync(path)
+    } else if (runtime.deno?.readFileSync) {
+      data = runtime.deno.readFileSync(path)
+    } else {
+      return [null, 'ENOENT']
+    }
+    if (data!.length > maxFileSize) {
+      return [null, 'time: file ' + path + ' is too large']
+    }
+    return [data, '']
+  } catch (err) {
+    const code = (err as { code?: unknown; name?: unknown } | null)?.code
+    if (code === 'ENOENT' || code === 'ENOTDIR') {
+      return [null, 'ENOENT']
+    }
+    if ((err as { name?: unknown } | null)?.name === 'NotFound') {
+      return [null, 'ENOENT']
+    }
+    return [null, err instanceof Error ? err.message : String(err)]
+  }
+}
@@ -12,9 +12,9 @@ export async function wrap(fn: (() => void) | null): globalThis.Promise<(() => v
 
 export async function main(): globalThis.Promise<void> {
 	let ch: $.Channel<{}> | null = $.makeChannel<{}>(0, {}, "both")
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await $.chanSend(ch, {})
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/async_void_function_literal_call/async_void_function_literal_call.go:12")
 	let wrapped: (() => void) | null = await wrap($.functionValue(async (): globalThis.Promise<void> => {
 		await $.chanRecv(ch)
 		$.println("fn")
@@ -6,9 +6,9 @@ import * as $ from "@goscript/builtin/index.js"
 export async function main(): globalThis.Promise<void> {
 	let messages: $.Channel<string> | null = $.makeChannel<string>(0, "", "both")
 
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await $.chanSend(messages, "ping")
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/channel_basic/channel_basic.go:6")
 
 	let msg = await $.chanRecv(messages)
 	$.println(msg)
@@ -32,9 +32,9 @@ export class AsyncResource {
 	public async Release(): globalThis.Promise<void> {
 		const r: AsyncResource | $.VarRef<AsyncResource> | null = this
 		let ch: $.Channel<boolean> | null = $.makeChannel<boolean>(1, false, "both")
-		queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+		$.go(async () => { await (async (): globalThis.Promise<void> => {
 			await $.chanSend(ch, true)
-		})() })
+		})() }, "main.(*AsyncResource).Release", "github.com/s4wave/goscript/tests/tests/defer_async_method/defer_async_method.go:11")
 		await $.chanRecv(ch)
 		$.println("Released", $.pointerValue<AsyncResource>(r).name)
 	}
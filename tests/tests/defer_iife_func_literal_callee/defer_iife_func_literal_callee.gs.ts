@@ -6,9 +6,9 @@ import * as $ from "@goscript/builtin/index.js"
 export async function main(): globalThis.Promise<void> {
 	using __defer = new $.DisposableStack()
 	let messages: $.Channel<string> | null = $.makeChannel<string>(0, "", "both")
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await $.chanSend(messages, "go")
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/defer_iife_func_literal_callee/defer_iife_func_literal_callee.go:5")
 	$.println(await $.chanRecv(messages))
 
 	void ((): void => {
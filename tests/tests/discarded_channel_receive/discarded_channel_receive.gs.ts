@@ -5,10 +5,10 @@ import * as $ from "@goscript/builtin/index.js"
 
 export async function main(): globalThis.Promise<void> {
 	let ch: $.Channel<number> | null = $.makeChannel<number>(0, 0, "both")
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await $.chanSend(ch, 1)
 		ch!.close()
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/discarded_channel_receive/discarded_channel_receive.go:5")
 	await $.chanRecv(ch)
 	$.println("done")
 }
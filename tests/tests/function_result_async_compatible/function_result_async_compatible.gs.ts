@@ -65,12 +65,12 @@ export function unwrap(v: Value | null): Value | null {
 
 export async function wrapNew(__typeArgs: $.GenericTypeArgs | undefined, newValue: (() => any | globalThis.Promise<any>) | null): globalThis.Promise<(() => Value | null | globalThis.Promise<Value | null>) | null> {
 	return $.functionValue(async (): globalThis.Promise<Value | null> => {
-		return unwrap((await newValue!() as Value | null))
+		return unwrap($.genericInterfaceValue<Value | null>(__typeArgs, "T", await newValue!()))
 	}, ({ kind: $.TypeKind.Function, params: [], results: ["main.Value"] } as $.FunctionTypeInfo))
 }
 
 export async function main(): globalThis.Promise<void> {
-	let fn: (() => Value | null | globalThis.Promise<Value | null>) | null = await wrapNew(undefined, asyncBox)
+	let fn: (() => Value | null | globalThis.Promise<Value | null>) | null = await wrapNew({T: { type: { kind: $.TypeKind.Pointer, elemType: "main.box" }, zero: () => null, methods: {Value: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).Value(...args)} }}, asyncBox)
 	$.println(await $.pointerValue<Exclude<Value, null>>((await fn!())).Value())
 }
 
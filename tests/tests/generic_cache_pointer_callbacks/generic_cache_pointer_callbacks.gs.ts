@@ -15,14 +15,17 @@ export class cache {
 		stored: $.VarRef<any>
 	}
 
-	constructor(init?: Partial<{stored?: any}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{stored?: any}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
 			stored: $.varRef(init?.stored ?? (null as any))
 		}
 	}
 
 	public clone(): cache {
-		const cloned = new cache()
+		const cloned = new cache(undefined, this.__typeArgs)
 		cloned._fields = {
 			stored: $.varRef(this._fields.stored.value)
 		}
@@ -121,7 +124,7 @@ export class privateKey {
 	)
 }
 
-export let privateKeyCache: $.VarRef<cache> = $.varRef($.markAsStructValue(new cache()))
+export let privateKeyCache: $.VarRef<cache> = $.varRef($.markAsStructValue(new cache(undefined, {K: { type: "main.key", zero: () => $.markAsStructValue(new key()) }, V: { type: "main.privateKey", zero: () => $.markAsStructValue(new privateKey()) }})))
 
 export function __goscript_set_privateKeyCache(__goscriptValue: cache): void {
 	privateKeyCache.value = __goscriptValue
@@ -57,7 +57,7 @@ $.registerInterfaceType(
 );
 
 export async function cloneSlice<T>(__typeArgs: $.GenericTypeArgs | undefined, items: $.Slice<T>): globalThis.Promise<$.Slice<T>> {
-	let cloned: $.Slice<T> = $.makeSlice<T>(0, $.len(items))
+	let cloned: $.Slice<T> = $.makeSlice<T>(0, $.len(items), undefined, () => $.genericZero(__typeArgs, "T", null))
 	for (let __goscriptRangeTarget0 = items, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget0); __rangeIndex++) {
 		let item = __goscriptRangeTarget0![__rangeIndex]
 		cloned = $.append(cloned, await $.callGenericMethod(__typeArgs, "T", "CloneVT", item))
@@ -67,7 +67,7 @@ export async function cloneSlice<T>(__typeArgs: $.GenericTypeArgs | undefined, i
 
 export async function main(): globalThis.Promise<void> {
 	let items: $.Slice<item | $.VarRef<item> | null> = $.arrayToSlice<item | $.VarRef<item> | null>([new item({value: "first"}), new item({value: "second"})])
-	let cloned: $.Slice<item | $.VarRef<item> | null> = (await cloneSlice({T: { type: { kind: $.TypeKind.Pointer, elemType: "main.item" }, zero: () => null }}, items) as $.Slice<item | $.VarRef<item> | null>)
+	let cloned: $.Slice<item | $.VarRef<item> | null> = (await cloneSlice({T: { type: { kind: $.TypeKind.Pointer, elemType: "main.item" }, zero: () => null, methods: {CloneVT: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).CloneVT(...args)} }}, items) as $.Slice<item | $.VarRef<item> | null>)
 	$.println($.len(cloned), $.pointerValue<item>($.arrayIndex(cloned!, 0)).value, $.pointerValue<item>($.arrayIndex(cloned!, 1)).value, $.arrayIndex(cloned!, 0) == $.arrayIndex(items!, 0))
 }
 
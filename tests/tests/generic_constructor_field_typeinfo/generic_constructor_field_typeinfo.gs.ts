@@ -34,7 +34,10 @@ export class blockType {
 		_constructor: $.VarRef<(() => any | globalThis.Promise<any>) | null>
 	}
 
-	constructor(init?: Partial<{typeID?: string, _constructor?: (() => any | globalThis.Promise<any>) | null}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{typeID?: string, _constructor?: (() => any | globalThis.Promise<any>) | null}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
 			typeID: $.varRef(init?.typeID ?? ("" as string)),
 			_constructor: $.varRef(init?._constructor ?? (null as (() => any | globalThis.Promise<any>) | null))
@@ -42,7 +45,7 @@ export class blockType {
 	}
 
 	public clone(): blockType {
-		const cloned = new blockType()
+		const cloned = new blockType(undefined, this.__typeArgs)
 		cloned._fields = {
 			typeID: $.varRef(this._fields.typeID.value),
 			_constructor: $.varRef(this._fields._constructor.value)
@@ -52,7 +55,8 @@ export class blockType {
 
 	public async Constructor(): globalThis.Promise<Block | null> {
 		const t: blockType | $.VarRef<blockType> | null = this
-		return (await $.pointerValue<blockType>(t)._constructor!() as Block | null)
+		const __typeArgs = ($.isVarRef(this) ? (this as any).value : this)?.__typeArgs
+		return $.genericInterfaceValue<Block | null>(__typeArgs, "T", await $.pointerValue<blockType>(t)._constructor!())
 	}
 
 	public GetBlockTypeID(): string {
@@ -103,11 +107,11 @@ export class sampleBlock {
 }
 
 export function NewBlockType(__typeArgs: $.GenericTypeArgs | undefined, typeID: string, _constructor: (() => any | globalThis.Promise<any>) | null): blockType | $.VarRef<blockType> | null {
-	return new blockType({typeID: typeID, _constructor: _constructor})
+	return new blockType({typeID: typeID, _constructor: _constructor}, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [{ name: "MarshalBlock", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Slice, elemType: { kind: $.TypeKind.Basic, name: "uint8" } } }, { name: "_r1", type: "error" }] }, { name: "UnmarshalBlock", args: [{ name: "_p0", type: { kind: $.TypeKind.Slice, elemType: { kind: $.TypeKind.Basic, name: "uint8" } } }], returns: [{ name: "_r0", type: "error" }] }] }, zero: () => null }})
 }
 
 export async function main(): globalThis.Promise<void> {
-	let bt: blockType | $.VarRef<blockType> | null = (NewBlockType(undefined, "sample", $.functionValue((): sampleBlock | $.VarRef<sampleBlock> | null => {
+	let bt: blockType | $.VarRef<blockType> | null = (NewBlockType({T: { type: { kind: $.TypeKind.Pointer, elemType: "main.sampleBlock" }, zero: () => null, methods: {MarshalBlock: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).MarshalBlock(...args), UnmarshalBlock: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).UnmarshalBlock(...args)} }}, "sample", $.functionValue((): sampleBlock | $.VarRef<sampleBlock> | null => {
 		return new sampleBlock()
 	}, ({ kind: $.TypeKind.Function, params: [], results: [{ kind: $.TypeKind.Pointer, elemType: "main.sampleBlock" }] } as $.FunctionTypeInfo))) as blockType | $.VarRef<blockType> | null)
 	let blk = await blockType.prototype.Constructor.call(bt)
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Type parameters instantiated with named array types keep array zero values
+// and dispatch to the array type's methods.
+
+type Stringer interface {
+	String() string
+}
+
+type Pair [2]int
+
+func (p Pair) String() string {
+	return strconv.Itoa(p[0]) + "/" + strconv.Itoa(p[1])
+}
+
+func (p *Pair) Swap() {
+	p[0], p[1] = p[1], p[0]
+}
+
+type PairLike interface {
+	~[2]int
+	String() string
+}
+
+func Zero[T Stringer]() string {
+	var zero T
+	return zero.String()
+}
+
+func First[T PairLike](v T) int {
+	return v[0]
+}
+
+func MethodValue[T Stringer](v T) string {
+	f := v.String
+	return f()
+}
+
+func AsInterface[T Stringer](v T) string {
+	var s Stringer = v
+	return s.String() + " " + fmt.Sprint(v)
+}
+
+type Swapper[T any] interface {
+	*T
+	Swap()
+	String() string
+}
+
+func Swapped[T any, PT Swapper[T]](v T) string {
+	PT(&v).Swap()
+	return PT(&v).String()
+}
+
+func main() {
+	println("zero:", Zero[Pair]())
+	println("first:", First(Pair{3, 4}))
+	println("method value:", MethodValue(Pair{1, 2}))
+	println("interface:", AsInterface(Pair{5, 6}))
+	println("swapped:", Swapped(Pair{1, 9}))
+}
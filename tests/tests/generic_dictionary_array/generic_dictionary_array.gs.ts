@@ -0,0 +1,91 @@
+// Generated file based on generic_dictionary_array.go
+// Updated when compliance tests are re-run, DO NOT EDIT!
+
+import * as $ from "@goscript/builtin/index.js"
+
+import * as fmt from "@goscript/fmt/index.js"
+
+import * as strconv from "@goscript/strconv/index.js"
+import "@goscript/fmt/index.js"
+import "@goscript/strconv/index.js"
+
+export type Stringer = {
+	String(): string
+}
+
+$.registerInterfaceType(
+	"main.Stringer",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }]
+);
+
+export type Pair = number[]
+
+export type PairLike = {
+	String(): string
+}
+
+$.registerInterfaceType(
+	"main.PairLike",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }]
+);
+
+export type Swapper = {
+	String(): string
+	Swap(): void
+}
+
+$.registerInterfaceType(
+	"main.Swapper",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }, { name: "Swap", args: [], returns: [] }]
+);
+
+export function Pair_String(p: Pair): string {
+	return (strconv.Itoa($.arrayIndex(p, 0)) + "/") + strconv.Itoa($.arrayIndex(p, 1))
+}
+
+export function Pair_Swap(p: $.VarRef<Pair> | null): void {
+	let __goscriptAssign0_0: number = $.arrayIndex($.pointerValue<number[]>(p), 1)
+	let __goscriptAssign0_1: number = $.arrayIndex($.pointerValue<number[]>(p), 0)
+	$.pointerValue<number[]>(p)[0] = __goscriptAssign0_0
+	$.pointerValue<number[]>(p)[1] = __goscriptAssign0_1
+}
+
+export async function Zero(__typeArgs: $.GenericTypeArgs | undefined): globalThis.Promise<string> {
+	let zero: any = $.genericZero(__typeArgs, "T", null)
+	return $.callGenericMethod(__typeArgs, "T", "String", zero)
+}
+
+export function First(__typeArgs: $.GenericTypeArgs | undefined, v: any): number {
+	return $.arrayIndex(v!, 0)
+}
+
+export async function MethodValue(__typeArgs: $.GenericTypeArgs | undefined, v: any): globalThis.Promise<string> {
+	let f: (() => string | globalThis.Promise<string>) | null = $.functionValue(((__receiver) => () => $.callGenericMethod(__typeArgs, "T", "String", __receiver))(v), ({ kind: $.TypeKind.Function, params: [], results: [{ kind: $.TypeKind.Basic, name: "string" }] } as $.FunctionTypeInfo))
+	return f!()
+}
+
+export async function AsInterface(__typeArgs: $.GenericTypeArgs | undefined, v: any): globalThis.Promise<string> {
+	let s: Stringer | null = $.genericInterfaceValue<Stringer | null>(__typeArgs, "T", v)
+	return (await $.pointerValue<Exclude<Stringer, null>>(s).String() + " ") + fmt.Sprint($.genericInterfaceValue<any>(__typeArgs, "T", v))
+}
+
+export async function Swapped(__typeArgs: $.GenericTypeArgs | undefined, __goscriptParam0: any): globalThis.Promise<string> {
+	let v: $.VarRef<any> = $.varRef(__goscriptParam0)
+	await $.callGenericMethod(__typeArgs, "PT", "Swap", v)
+	return $.callGenericMethod(__typeArgs, "PT", "String", v)
+}
+
+export async function main(): globalThis.Promise<void> {
+	$.println("zero:", await Zero({T: { type: "main.Pair", zero: () => Array.from({ length: 2 }, () => 0), methods: {String: (receiver: any, ...args: any[]) => (Pair_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}))
+	$.println("first:", First({T: { type: "main.Pair", zero: () => Array.from({ length: 2 }, () => 0), methods: {String: (receiver: any, ...args: any[]) => (Pair_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, [3, 4]))
+	$.println("method value:", await MethodValue({T: { type: "main.Pair", zero: () => Array.from({ length: 2 }, () => 0), methods: {String: (receiver: any, ...args: any[]) => (Pair_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, [1, 2]))
+	$.println("interface:", await AsInterface({T: { type: "main.Pair", zero: () => Array.from({ length: 2 }, () => 0), methods: {String: (receiver: any, ...args: any[]) => (Pair_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, [5, 6]))
+	$.println("swapped:", await Swapped({T: { type: "main.Pair", zero: () => Array.from({ length: 2 }, () => 0), methods: {String: (receiver: any, ...args: any[]) => (Pair_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }, PT: { type: { kind: $.TypeKind.Pointer, elemType: "main.Pair" }, zero: () => null, methods: {String: (receiver: any, ...args: any[]) => (Pair_String as any)($.pointerValue(receiver), ...args), Swap: (receiver: any, ...args: any[]) => (Pair_Swap as any)(receiver, ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }, { name: "Swap", args: [], returns: [] }] }}, [1, 9]))
+}
+
+if ($.isMainScript(import.meta)) {
+	await main()
+}
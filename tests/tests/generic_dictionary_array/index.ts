@@ -0,0 +1,3 @@
+export type { Pair, PairLike, Stringer, Swapper } from "./generic_dictionary_array.gs.ts"
+export { AsInterface, First, MethodValue, Pair_String, Pair_Swap, Swapped, Zero } from "./generic_dictionary_array.gs.ts"
+import "./generic_dictionary_array.gs.ts"
@@ -0,0 +1,56 @@
+package main
+
+// Composite literals of type parameters build values of the type argument,
+// using the core type of the constraint.
+
+type Vec [2]int
+
+type Names []string
+
+type Index map[string]int
+
+type Point struct{ X, Y int }
+
+func (p Point) Sum() int {
+	return p.X + p.Y
+}
+
+func Make[T ~[2]int](a, b int) T {
+	return T{a, b}
+}
+
+func MakeSlice[T ~[]E, E any](vals ...E) T {
+	return T{vals[0], vals[len(vals)-1]}
+}
+
+func MakeMap[M ~map[K]V, K comparable, V any](k K, v V) M {
+	return M{k: v}
+}
+
+func MakePoint[T ~struct{ X, Y int }](x int) T {
+	return T{X: x, Y: x * 2}
+}
+
+func Empty[T ~[]string]() T {
+	return T{}
+}
+
+func main() {
+	v := Make[Vec](1, 2)
+	println(v[0], v[1])
+
+	n := MakeSlice[Names]("x", "y", "z")
+	println(len(n), n[0], n[1])
+
+	m := MakeMap[Index]("k", 1)
+	println(len(m), m["k"])
+
+	p := MakePoint[Point](2)
+	println(p.X, p.Y, p.Sum())
+
+	a := MakePoint[struct{ X, Y int }](1)
+	println(a.X, a.Y)
+
+	e := Empty[Names]()
+	println(len(e), e == nil)
+}
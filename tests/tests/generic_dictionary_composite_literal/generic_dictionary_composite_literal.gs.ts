@@ -0,0 +1,104 @@
+// Generated file based on generic_dictionary_composite_literal.go
+// Updated when compliance tests are re-run, DO NOT EDIT!
+
+import * as $ from "@goscript/builtin/index.js"
+
+export type Vec = number[]
+
+export type Names = $.Slice<string>
+
+export type Index = globalThis.Map<string, number> | null
+
+export class Point {
+	public get X(): number {
+		return this._fields.X.value
+	}
+	public set X(value: number) {
+		this._fields.X.value = value
+	}
+
+	public get Y(): number {
+		return this._fields.Y.value
+	}
+	public set Y(value: number) {
+		this._fields.Y.value = value
+	}
+
+	public _fields: {
+		X: $.VarRef<number>
+		Y: $.VarRef<number>
+	}
+
+	constructor(init?: Partial<{X?: number, Y?: number}>) {
+		this._fields = {
+			X: $.varRef(init?.X ?? (0 as number)),
+			Y: $.varRef(init?.Y ?? (0 as number))
+		}
+	}
+
+	public clone(): Point {
+		const cloned = new Point()
+		cloned._fields = {
+			X: $.varRef(this._fields.X.value),
+			Y: $.varRef(this._fields.Y.value)
+		}
+		return $.markAsStructValue(cloned)
+	}
+
+	public Sum(): number {
+		const p = this
+		return p.X + p.Y
+	}
+
+	static __typeInfo = $.registerStructType(
+		"main.Point",
+		() => new Point(),
+		[{ name: "Sum", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "int" } }] }],
+		Point,
+		[{ name: "X", key: "X", type: { kind: $.TypeKind.Basic, name: "int" } }, { name: "Y", key: "Y", type: { kind: $.TypeKind.Basic, name: "int" } }]
+	)
+}
+
+export function Make(__typeArgs: $.GenericTypeArgs | undefined, a: number, b: number): any {
+	return [a, b]
+}
+
+export function MakeSlice<E>(__typeArgs: $.GenericTypeArgs | undefined, vals: $.Slice<E>): any {
+	return $.arrayToSlice<E>([$.arrayIndex(vals!, 0), $.arrayIndex(vals!, $.len(vals) - 1)])
+}
+
+export function MakeMap(__typeArgs: $.GenericTypeArgs | undefined, k: any, v: any): any {
+	return new globalThis.Map<any, any>([[k, v]])
+}
+
+export function MakePoint(__typeArgs: $.GenericTypeArgs | undefined, x: number): any {
+	return Object.assign($.genericZero(__typeArgs, "T", null), {X: x, Y: x * 2})
+}
+
+export function Empty(__typeArgs: $.GenericTypeArgs | undefined): any {
+	return $.arrayToSlice<string>([])
+}
+
+export async function main(): globalThis.Promise<void> {
+	let v = (Make({T: { type: "main.Vec", zero: () => Array.from({ length: 2 }, () => 0) }}, 1, 2) as Vec)
+	$.println($.arrayIndex(v, 0), $.arrayIndex(v, 1))
+
+	let n: Names = ((MakeSlice({T: { type: "main.Names", zero: () => null }, E: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, $.arrayToSlice<string>(["x", "y", "z"])) as Names) as Names)
+	$.println($.len((n as Names)), $.arrayIndex(n!, 0), $.arrayIndex(n!, 1))
+
+	let m: Index = (MakeMap({M: { type: "main.Index", zero: () => null }, K: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }, V: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, "k", 1) as Index)
+	$.println($.len(m), $.mapGet<string, number, number>(m, "k", 0)[0])
+
+	let p = ($.markAsStructValue($.cloneStructValue(MakePoint({T: { type: "main.Point", zero: () => $.markAsStructValue(new Point()), methods: {Sum: (receiver: any, ...args: any[]) => receiver.Sum(...args)} }}, 2))) as Point)
+	$.println(p.X, p.Y, $.markAsStructValue($.cloneStructValue(p)).Sum())
+
+	let a = (MakePoint({T: { type: { kind: $.TypeKind.Struct, methods: [], fields: [{ name: "X", key: "X", type: { kind: $.TypeKind.Basic, name: "int" }, index: [0], offset: 0, exported: true }, { name: "Y", key: "Y", type: { kind: $.TypeKind.Basic, name: "int" }, index: [1], offset: 8, exported: true }] }, zero: () => ({"X": 0, "Y": 0}) }}, 1) as {"X": number, "Y": number})
+	$.println(a.X, a.Y)
+
+	let e: Names = ((Empty({T: { type: "main.Names", zero: () => null }}) as Names) as Names)
+	$.println($.len((e as Names)), e == null)
+}
+
+if ($.isMainScript(import.meta)) {
+	await main()
+}
@@ -0,0 +1,2 @@
+export type { Index, Names, Vec } from "./generic_dictionary_composite_literal.gs.ts"
+export { Empty, Make, MakeMap, MakePoint, MakeSlice, Point } from "./generic_dictionary_composite_literal.gs.ts"
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Type parameters constrained by a method set and instantiated with named
+// basic types get their zero values and methods from the instantiation.
+
+type Stringer interface {
+	String() string
+}
+
+type IntVal int
+
+func (i IntVal) String() string {
+	return "IntVal(" + strconv.Itoa(int(i)) + ")"
+}
+
+type Label string
+
+func (l Label) String() string {
+	return "Label(" + string(l) + ")"
+}
+
+type Number interface {
+	~int
+	String() string
+}
+
+func Zero[T Stringer]() string {
+	var zero T
+	return zero.String()
+}
+
+func MethodValue[T Stringer](v T) string {
+	f := v.String
+	return f()
+}
+
+func MethodExpr[T Stringer](v T) string {
+	return T.String(v)
+}
+
+func AsInterface[T Stringer](v T) string {
+	var s Stringer = v
+	return s.String() + " " + fmt.Sprint(v)
+}
+
+func Zeros[T Stringer](n int) []string {
+	vals := make([]T, n)
+	var arr [2]T
+	out := make([]string, 0, n+len(arr))
+	for _, v := range vals {
+		out = append(out, v.String())
+	}
+	for _, v := range arr {
+		out = append(out, v.String())
+	}
+	return out
+}
+
+func Sum[T Number](vals ...T) T {
+	var sum T
+	for _, v := range vals {
+		sum += v
+	}
+	return sum
+}
+
+func Nested[T Stringer]() string {
+	return Zero[T]() + "/" + MethodValue(*new(T))
+}
+
+func main() {
+	println("zero:", Zero[IntVal](), Zero[Label]())
+	println("method value:", MethodValue(IntVal(4)), MethodValue(Label("x")))
+	println("method expr:", MethodExpr(IntVal(5)), MethodExpr(Label("y")))
+	println("interface:", AsInterface(IntVal(6)))
+	println("interface:", AsInterface(Label("z")))
+	println("zeros:", fmt.Sprint(Zeros[IntVal](2)))
+	println("sum:", Sum[IntVal](1, 2, 3).String(), Sum[IntVal]().String())
+	println("nested:", Nested[IntVal](), Nested[Label]())
+}
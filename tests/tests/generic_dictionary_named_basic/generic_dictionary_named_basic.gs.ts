@@ -0,0 +1,104 @@
+// Generated file based on generic_dictionary_named_basic.go
+// Updated when compliance tests are re-run, DO NOT EDIT!
+
+import * as $ from "@goscript/builtin/index.js"
+
+import * as fmt from "@goscript/fmt/index.js"
+
+import * as strconv from "@goscript/strconv/index.js"
+import "@goscript/fmt/index.js"
+import "@goscript/strconv/index.js"
+
+export type Stringer = {
+	String(): string
+}
+
+$.registerInterfaceType(
+	"main.Stringer",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }]
+);
+
+export type IntVal = number
+
+export type Label = string
+
+export type Number = {
+	String(): string
+}
+
+$.registerInterfaceType(
+	"main.Number",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }]
+);
+
+export function IntVal_String(i: IntVal): string {
+	return ("IntVal(" + strconv.Itoa($.int(i))) + ")"
+}
+
+export function Label_String(l: Label): string {
+	return ("Label(" + l) + ")"
+}
+
+export async function Zero(__typeArgs: $.GenericTypeArgs | undefined): globalThis.Promise<string> {
+	let zero: any = $.genericZero(__typeArgs, "T", null)
+	return $.callGenericMethod(__typeArgs, "T", "String", zero)
+}
+
+export async function MethodValue(__typeArgs: $.GenericTypeArgs | undefined, v: any): globalThis.Promise<string> {
+	let f: (() => string | globalThis.Promise<string>) | null = $.functionValue(((__receiver) => () => $.callGenericMethod(__typeArgs, "T", "String", __receiver))(v), ({ kind: $.TypeKind.Function, params: [], results: [{ kind: $.TypeKind.Basic, name: "string" }] } as $.FunctionTypeInfo))
+	return f!()
+}
+
+export function MethodExpr(__typeArgs: $.GenericTypeArgs | undefined, v: any): string {
+	return $.functionValue((_p0: any): string => $.callGenericMethod(__typeArgs, "T", "String", _p0), ({ kind: $.TypeKind.Function, params: [{ kind: $.TypeKind.Interface, methods: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }], results: [{ kind: $.TypeKind.Basic, name: "string" }] } as $.FunctionTypeInfo))(v)
+}
+
+export async function AsInterface(__typeArgs: $.GenericTypeArgs | undefined, v: any): globalThis.Promise<string> {
+	let s: Stringer | null = $.genericInterfaceValue<Stringer | null>(__typeArgs, "T", v)
+	return (await $.pointerValue<Exclude<Stringer, null>>(s).String() + " ") + fmt.Sprint($.genericInterfaceValue<any>(__typeArgs, "T", v))
+}
+
+export async function Zeros(__typeArgs: $.GenericTypeArgs | undefined, n: number): globalThis.Promise<$.Slice<string>> {
+	let vals: $.Slice<any> = $.makeSlice<any>(n, undefined, undefined, () => $.genericZero(__typeArgs, "T", null))
+	let arr: any[] = Array.from({ length: 2 }, () => $.genericZero(__typeArgs, "T", null))
+	let out: $.Slice<string> = $.makeSlice<string>(0, n + $.len(arr), "string")
+	for (let __goscriptRangeTarget0 = vals, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget0); __rangeIndex++) {
+		let v = __goscriptRangeTarget0![__rangeIndex]
+		out = $.append(out, await $.callGenericMethod(__typeArgs, "T", "String", v))
+	}
+	for (let __goscriptRangeTarget1 = arr, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget1); __rangeIndex++) {
+		let v = __goscriptRangeTarget1[__rangeIndex]
+		out = $.append(out, await $.callGenericMethod(__typeArgs, "T", "String", v))
+	}
+	return out
+}
+
+export function Sum<T>(__typeArgs: $.GenericTypeArgs | undefined, vals: $.Slice<T>): any {
+	let sum: any = $.genericZero(__typeArgs, "T", null)
+	for (let __goscriptRangeTarget2 = vals, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget2); __rangeIndex++) {
+		let v = __goscriptRangeTarget2![__rangeIndex]
+		sum = sum + (v)
+	}
+	return sum
+}
+
+export async function Nested(__typeArgs: $.GenericTypeArgs | undefined): globalThis.Promise<string> {
+	return (await Zero({T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }, zero: () => null }}) + "/") + await MethodValue({T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }, zero: () => null }}, $.pointerValue<any>($.varRef<any>($.genericZero(__typeArgs, "T", null))))
+}
+
+export async function main(): globalThis.Promise<void> {
+	$.println("zero:", await Zero({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}), await Zero({T: { type: { kind: $.TypeKind.Basic, name: "string", typeName: "main.Label" }, zero: () => "", methods: {String: (receiver: any, ...args: any[]) => (Label_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}))
+	$.println("method value:", await MethodValue({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, 4), await MethodValue({T: { type: { kind: $.TypeKind.Basic, name: "string", typeName: "main.Label" }, zero: () => "", methods: {String: (receiver: any, ...args: any[]) => (Label_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, "x"))
+	$.println("method expr:", MethodExpr({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, 5), MethodExpr({T: { type: { kind: $.TypeKind.Basic, name: "string", typeName: "main.Label" }, zero: () => "", methods: {String: (receiver: any, ...args: any[]) => (Label_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, "y"))
+	$.println("interface:", await AsInterface({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, 6))
+	$.println("interface:", await AsInterface({T: { type: { kind: $.TypeKind.Basic, name: "string", typeName: "main.Label" }, zero: () => "", methods: {String: (receiver: any, ...args: any[]) => (Label_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, "z"))
+	$.println("zeros:", fmt.Sprint($.interfaceValue<any>(await Zeros({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, 2), "[]string")))
+	$.println("sum:", IntVal_String(Sum({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, $.arrayToSlice<IntVal>([1, 2, 3]))), IntVal_String(Sum({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, null)))
+	$.println("nested:", await Nested({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}), await Nested({T: { type: { kind: $.TypeKind.Basic, name: "string", typeName: "main.Label" }, zero: () => "", methods: {String: (receiver: any, ...args: any[]) => (Label_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}))
+}
+
+if ($.isMainScript(import.meta)) {
+	await main()
+}
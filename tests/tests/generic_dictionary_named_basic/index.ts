@@ -0,0 +1,3 @@
+export type { IntVal, Label, Number, Stringer } from "./generic_dictionary_named_basic.gs.ts"
+export { AsInterface, IntVal_String, Label_String, MethodExpr, MethodValue, Nested, Sum, Zero, Zeros } from "./generic_dictionary_named_basic.gs.ts"
+import "./generic_dictionary_named_basic.gs.ts"
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strconv"
+)
+
+// Methods declared on generic named types that are not structs receive the
+// receiver's type arguments, so their bodies can build type-parameter zero
+// values and call type-parameter methods.
+
+type Stringer interface {
+	String() string
+}
+
+type IntVal int
+
+func (i IntVal) String() string {
+	return "<" + strconv.Itoa(int(i)) + ">"
+}
+
+type List[T Stringer] []T
+
+func (l List[T]) First() T {
+	var zero T
+	if len(l) == 0 {
+		return zero
+	}
+	return l[0]
+}
+
+func (l List[U]) Last() U {
+	var zero U
+	if len(l) == 0 {
+		return zero
+	}
+	return l[len(l)-1]
+}
+
+func (l List[T]) Join() string {
+	s := ""
+	for _, v := range l {
+		s += v.String()
+	}
+	return s
+}
+
+func (l *List[T]) Push(v T) {
+	*l = append(*l, v)
+}
+
+func (l List[T]) String() string {
+	return "[" + l.Join() + "]"
+}
+
+type Code[T any] int
+
+func (c Code[T]) Error() string {
+	var zero T
+	if v, ok := any(zero).(IntVal); ok {
+		return "code " + strconv.Itoa(int(c)) + " zero " + strconv.Itoa(int(v))
+	}
+	return "code " + strconv.Itoa(int(c))
+}
+
+func Describe[S Stringer](s S) string {
+	return s.String()
+}
+
+func main() {
+	var l List[IntVal]
+	println(l.First().String(), l.Last().String())
+
+	l.Push(3)
+	l = append(l, 4)
+	println(l.Join(), l.First().String(), l.Last().String())
+
+	join := l.Join
+	first := List[IntVal].First
+	println(join(), first(l).String())
+
+	var s Stringer = l
+	println(s.String(), Describe(l))
+
+	var err error = Code[IntVal](7)
+	println(err.Error())
+}
@@ -0,0 +1,99 @@
+// Generated file based on generic_dictionary_named_methods.go
+// Updated when compliance tests are re-run, DO NOT EDIT!
+
+import * as $ from "@goscript/builtin/index.js"
+
+import * as strconv from "@goscript/strconv/index.js"
+import "@goscript/strconv/index.js"
+
+export type Stringer = {
+	String(): string | globalThis.Promise<string>
+}
+
+$.registerInterfaceType(
+	"main.Stringer",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }]
+);
+
+export type IntVal = number
+
+export type List = $.Slice<any>
+
+export type Code = number
+
+export function IntVal_String(i: IntVal): string {
+	return ("<" + strconv.Itoa($.int(i))) + ">"
+}
+
+export function List_First(l: List, __typeArgs: $.GenericTypeArgs | undefined): any {
+	let zero: any = $.genericZero(__typeArgs, "T", null)
+	if ($.len((l as List)) == 0) {
+		return zero
+	}
+	return $.arrayIndex(l!, 0)
+}
+
+export function List_Last(l: List, __receiverTypeArgs: $.GenericTypeArgs | undefined): any {
+	const __typeArgs = { U: __receiverTypeArgs?.["T"] } as $.GenericTypeArgs
+	let zero: any = $.genericZero(__typeArgs, "U", null)
+	if ($.len((l as List)) == 0) {
+		return zero
+	}
+	return $.arrayIndex(l!, $.len((l as List)) - 1)
+}
+
+export async function List_Join(l: List, __typeArgs: $.GenericTypeArgs | undefined): globalThis.Promise<string> {
+	let s = ""
+	for (let __goscriptRangeTarget0 = l, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget0); __rangeIndex++) {
+		let v = __goscriptRangeTarget0![__rangeIndex]
+		s = s + (await $.callGenericMethod(__typeArgs, "T", "String", v))
+	}
+	return s
+}
+
+export function List_Push(l: $.VarRef<List> | null, __typeArgs: $.GenericTypeArgs | undefined, v: any): void {
+	l!.value = ($.append(($.pointerValue<List>(l) as List), v) as List)
+}
+
+export async function List_String(l: List, __typeArgs: $.GenericTypeArgs | undefined): globalThis.Promise<string> {
+	return ("[" + await List_Join(l, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }, zero: () => null }})) + "]"
+}
+
+export function Code_Error(c: Code, __typeArgs: $.GenericTypeArgs | undefined): string {
+	let zero: any = $.genericZero(__typeArgs, "T", null)
+	{
+		let [v, ok] = $.typeAssertTuple<IntVal>($.genericInterfaceValue<any>(__typeArgs, "T", zero), { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" })
+		if (ok) {
+			return (("code " + strconv.Itoa($.int(c))) + " zero ") + strconv.Itoa($.int(v))
+		}
+	}
+	return "code " + strconv.Itoa($.int(c))
+}
+
+export async function Describe(__typeArgs: $.GenericTypeArgs | undefined, s: any): globalThis.Promise<string> {
+	return $.callGenericMethod(__typeArgs, "S", "String", s)
+}
+
+export async function main(): globalThis.Promise<void> {
+	let l: $.VarRef<List> = $.varRef(null as List)
+	$.println(IntVal_String(List_First(l.value, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }})), IntVal_String(List_Last(l.value, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }})))
+
+	List_Push(l, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, 3)
+	l.value = ($.append((l.value as List), 4) as List)
+	$.println(await List_Join(l.value, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}), IntVal_String(List_First(l.value, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }})), IntVal_String(List_Last(l.value, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }})))
+
+	let join: (() => string | globalThis.Promise<string>) | null = $.functionValue(((__receiver) => () => List_Join(__receiver, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}))(l.value), ({ kind: $.TypeKind.Function, params: [], results: [{ kind: $.TypeKind.Basic, name: "string" }] } as $.FunctionTypeInfo))
+	let first: ((_p0: List) => IntVal | globalThis.Promise<IntVal>) | null = ((receiver: any, ...args: any[]) => (List_First as any)(receiver, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args))
+	$.println(await join!(), IntVal_String((await first!((l.value as List)))))
+
+	let s: Stringer | null = $.namedValueInterfaceValue<Stringer | null>(l.value, "main.List[main.IntVal]", {First: (receiver: any, ...args: any[]) => (List_First as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args), Join: (receiver: any, ...args: any[]) => (List_Join as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args), Last: (receiver: any, ...args: any[]) => (List_Last as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args), String: (receiver: any, ...args: any[]) => (List_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args)}, "main.List")
+	$.println(await $.pointerValue<Exclude<Stringer, null>>(s).String(), await Describe({S: { type: "main.List", zero: () => null, methods: {First: (receiver: any, ...args: any[]) => (List_First as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args), Join: (receiver: any, ...args: any[]) => (List_Join as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args), Last: (receiver: any, ...args: any[]) => (List_Last as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args), String: (receiver: any, ...args: any[]) => (List_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args)}, methodSignatures: [{ name: "First", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" } }] }, { name: "Join", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }, { name: "Last", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" } }] }, { name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, (l.value as List)))
+
+	let err: $.GoError = $.namedValueInterfaceValue<$.GoError>(7, "main.Code[main.IntVal]", {"Error": ((receiver: any, ...args: any[]) => (Code_Error as any)(receiver, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}, ...args))}, { kind: $.TypeKind.Basic, name: "int", typeName: "main.Code" })
+	$.println($.pointerValue<Exclude<$.GoError, null>>(err).Error())
+}
+
+if ($.isMainScript(import.meta)) {
+	await main()
+}
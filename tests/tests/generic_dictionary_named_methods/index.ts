@@ -0,0 +1,3 @@
+export type { Code, IntVal, List, Stringer } from "./generic_dictionary_named_methods.gs.ts"
+export { Code_Error, Describe, IntVal_String, List_First, List_Join, List_Last, List_Push, List_String } from "./generic_dictionary_named_methods.gs.ts"
+import "./generic_dictionary_named_methods.gs.ts"
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Type parameters instantiated with struct types get struct zero values, and
+// pointer-method constraints dispatch through the instantiated pointer type.
+
+type Stringer interface {
+	String() string
+}
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return "(" + strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y) + ")"
+}
+
+type Counter struct {
+	n int
+}
+
+func (c *Counter) Set(n int) {
+	c.n = n
+}
+
+func (c *Counter) Get() int {
+	return c.n
+}
+
+type Setter[T any] interface {
+	*T
+	Set(int)
+	Get() int
+}
+
+func Zero[T Stringer]() string {
+	var zero T
+	return zero.String()
+}
+
+func MethodValue[T Stringer](v T) string {
+	f := v.String
+	return f()
+}
+
+func AsInterface[T Stringer](v T) string {
+	var s Stringer = v
+	return s.String() + " " + fmt.Sprint(v)
+}
+
+func Make[T any, PT Setter[T]](n int) T {
+	var v T
+	PT(&v).Set(n)
+	return v
+}
+
+func Read[T any, PT Setter[T]](p PT) int {
+	return p.Get()
+}
+
+func main() {
+	println("zero:", Zero[Point]())
+	println("method value:", MethodValue(Point{1, 2}))
+	println("interface:", AsInterface(Point{3, 4}))
+	c := Make[Counter](5)
+	println("make:", c.n)
+	println("read:", Read(&c))
+}
@@ -0,0 +1,164 @@
+// Generated file based on generic_dictionary_struct.go
+// Updated when compliance tests are re-run, DO NOT EDIT!
+
+import * as $ from "@goscript/builtin/index.js"
+
+import * as fmt from "@goscript/fmt/index.js"
+
+import * as strconv from "@goscript/strconv/index.js"
+import "@goscript/fmt/index.js"
+import "@goscript/strconv/index.js"
+
+export type Stringer = {
+	String(): string
+}
+
+$.registerInterfaceType(
+	"main.Stringer",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }]
+);
+
+export type Setter = {
+	Get(): number
+	Set(_p0: number): void
+}
+
+$.registerInterfaceType(
+	"main.Setter",
+	null,
+	[{ name: "Get", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "int" } }] }, { name: "Set", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [] }]
+);
+
+export class Point {
+	public get X(): number {
+		return this._fields.X.value
+	}
+	public set X(value: number) {
+		this._fields.X.value = value
+	}
+
+	public get Y(): number {
+		return this._fields.Y.value
+	}
+	public set Y(value: number) {
+		this._fields.Y.value = value
+	}
+
+	public _fields: {
+		X: $.VarRef<number>
+		Y: $.VarRef<number>
+	}
+
+	constructor(init?: Partial<{X?: number, Y?: number}>) {
+		this._fields = {
+			X: $.varRef(init?.X ?? (0 as number)),
+			Y: $.varRef(init?.Y ?? (0 as number))
+		}
+	}
+
+	public clone(): Point {
+		const cloned = new Point()
+		cloned._fields = {
+			X: $.varRef(this._fields.X.value),
+			Y: $.varRef(this._fields.Y.value)
+		}
+		return $.markAsStructValue(cloned)
+	}
+
+	public String(): string {
+		const p = this
+		return ((("(" + strconv.Itoa(p.X)) + ",") + strconv.Itoa(p.Y)) + ")"
+	}
+
+	static __typeInfo = $.registerStructType(
+		"main.Point",
+		() => new Point(),
+		[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }],
+		Point,
+		[{ name: "X", key: "X", type: { kind: $.TypeKind.Basic, name: "int" } }, { name: "Y", key: "Y", type: { kind: $.TypeKind.Basic, name: "int" } }]
+	)
+}
+
+export class Counter {
+	public get n(): number {
+		return this._fields.n.value
+	}
+	public set n(value: number) {
+		this._fields.n.value = value
+	}
+
+	public _fields: {
+		n: $.VarRef<number>
+	}
+
+	constructor(init?: Partial<{n?: number}>) {
+		this._fields = {
+			n: $.varRef(init?.n ?? (0 as number))
+		}
+	}
+
+	public clone(): Counter {
+		const cloned = new Counter()
+		cloned._fields = {
+			n: $.varRef(this._fields.n.value)
+		}
+		return $.markAsStructValue(cloned)
+	}
+
+	public Get(): number {
+		const c: Counter | $.VarRef<Counter> | null = this
+		return $.pointerValue<Counter>(c).n
+	}
+
+	public Set(n: number): void {
+		let c: Counter | $.VarRef<Counter> | null = this
+		$.pointerValue<Counter>(c).n = n
+	}
+
+	static __typeInfo = $.registerStructType(
+		"main.Counter",
+		() => new Counter(),
+		[{ name: "Get", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "int" } }] }, { name: "Set", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [] }],
+		Counter,
+		[{ name: "n", key: "n", type: { kind: $.TypeKind.Basic, name: "int" } }]
+	)
+}
+
+export async function Zero(__typeArgs: $.GenericTypeArgs | undefined): globalThis.Promise<string> {
+	let zero: any = $.genericZero(__typeArgs, "T", null)
+	return $.callGenericMethod(__typeArgs, "T", "String", zero)
+}
+
+export async function MethodValue(__typeArgs: $.GenericTypeArgs | undefined, v: any): globalThis.Promise<string> {
+	let f: (() => string | globalThis.Promise<string>) | null = $.functionValue(((__receiver) => () => $.callGenericMethod(__typeArgs, "T", "String", __receiver))(v), ({ kind: $.TypeKind.Function, params: [], results: [{ kind: $.TypeKind.Basic, name: "string" }] } as $.FunctionTypeInfo))
+	return f!()
+}
+
+export async function AsInterface(__typeArgs: $.GenericTypeArgs | undefined, v: any): globalThis.Promise<string> {
+	let s: Stringer | null = $.genericInterfaceValue<Stringer | null>(__typeArgs, "T", v)
+	return (await $.pointerValue<Exclude<Stringer, null>>(s).String() + " ") + fmt.Sprint($.genericInterfaceValue<any>(__typeArgs, "T", v))
+}
+
+export async function Make(__typeArgs: $.GenericTypeArgs | undefined, n: number): globalThis.Promise<any> {
+	let v: $.VarRef<any> = $.varRef($.genericZero(__typeArgs, "T", null))
+	await $.callGenericMethod(__typeArgs, "PT", "Set", v, n)
+	return v.value
+}
+
+export async function Read(__typeArgs: $.GenericTypeArgs | undefined, p: any): globalThis.Promise<number> {
+	return $.callGenericMethod(__typeArgs, "PT", "Get", p)
+}
+
+export async function main(): globalThis.Promise<void> {
+	$.println("zero:", await Zero({T: { type: "main.Point", zero: () => $.markAsStructValue(new Point()), methods: {String: (receiver: any, ...args: any[]) => receiver.String(...args)} }}))
+	$.println("method value:", await MethodValue({T: { type: "main.Point", zero: () => $.markAsStructValue(new Point()), methods: {String: (receiver: any, ...args: any[]) => receiver.String(...args)} }}, $.markAsStructValue(new Point({X: 1, Y: 2}))))
+	$.println("interface:", await AsInterface({T: { type: "main.Point", zero: () => $.markAsStructValue(new Point()), methods: {String: (receiver: any, ...args: any[]) => receiver.String(...args)} }}, $.markAsStructValue(new Point({X: 3, Y: 4}))))
+	let c = $.varRef(($.markAsStructValue($.cloneStructValue(await Make({T: { type: "main.Counter", zero: () => $.markAsStructValue(new Counter()) }, PT: { type: { kind: $.TypeKind.Pointer, elemType: "main.Counter" }, zero: () => null, methods: {Get: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).Get(...args), Set: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).Set(...args)} }}, 5))) as Counter))
+	$.println("make:", c.value.n)
+	$.println("read:", await Read({T: { type: "main.Counter", zero: () => $.markAsStructValue(new Counter()) }, PT: { type: { kind: $.TypeKind.Pointer, elemType: "main.Counter" }, zero: () => null, methods: {Get: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).Get(...args), Set: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).Set(...args)} }}, c))
+}
+
+if ($.isMainScript(import.meta)) {
+	await main()
+}
@@ -0,0 +1,3 @@
+export type { Setter, Stringer } from "./generic_dictionary_struct.gs.ts"
+export { AsInterface, Counter, Make, MethodValue, Point, Read, Zero } from "./generic_dictionary_struct.gs.ts"
+import "./generic_dictionary_struct.gs.ts"
@@ -0,0 +1,56 @@
+package main
+
+import "strconv"
+
+// Methods of generic struct types see the type arguments their instance was
+// created with, including zero-valued fields and renamed receiver parameters.
+
+type Stringer interface {
+	String() string
+}
+
+type IntVal int
+
+func (i IntVal) String() string {
+	return "IntVal(" + strconv.Itoa(int(i)) + ")"
+}
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return "(" + strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y) + ")"
+}
+
+type Box[T Stringer] struct {
+	value T
+	pair  [2]T
+}
+
+func (b Box[T]) Describe() string {
+	var zero T
+	return b.value.String() + " " + b.pair[1].String() + " " + zero.String()
+}
+
+func (b *Box[U]) Reset() {
+	var zero U
+	b.value = zero
+}
+
+func NewBox[T Stringer](v T) *Box[T] {
+	return &Box[T]{value: v}
+}
+
+func main() {
+	b := Box[IntVal]{value: 7}
+	println("literal:", b.Describe())
+	var z Box[Point]
+	println("zero:", z.Describe())
+	p := NewBox(Point{1, 2})
+	println("constructor:", p.Describe())
+	p.Reset()
+	println("reset:", p.Describe())
+	c := *p
+	println("copy:", c.Describe())
+}
@@ -0,0 +1,156 @@
+// Generated file based on generic_dictionary_struct_type.go
+// Updated when compliance tests are re-run, DO NOT EDIT!
+
+import * as $ from "@goscript/builtin/index.js"
+
+import * as strconv from "@goscript/strconv/index.js"
+import "@goscript/strconv/index.js"
+
+export type Stringer = {
+	String(): string
+}
+
+$.registerInterfaceType(
+	"main.Stringer",
+	null,
+	[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }]
+);
+
+export type IntVal = number
+
+export class Point {
+	public get X(): number {
+		return this._fields.X.value
+	}
+	public set X(value: number) {
+		this._fields.X.value = value
+	}
+
+	public get Y(): number {
+		return this._fields.Y.value
+	}
+	public set Y(value: number) {
+		this._fields.Y.value = value
+	}
+
+	public _fields: {
+		X: $.VarRef<number>
+		Y: $.VarRef<number>
+	}
+
+	constructor(init?: Partial<{X?: number, Y?: number}>) {
+		this._fields = {
+			X: $.varRef(init?.X ?? (0 as number)),
+			Y: $.varRef(init?.Y ?? (0 as number))
+		}
+	}
+
+	public clone(): Point {
+		const cloned = new Point()
+		cloned._fields = {
+			X: $.varRef(this._fields.X.value),
+			Y: $.varRef(this._fields.Y.value)
+		}
+		return $.markAsStructValue(cloned)
+	}
+
+	public String(): string {
+		const p = this
+		return ((("(" + strconv.Itoa(p.X)) + ",") + strconv.Itoa(p.Y)) + ")"
+	}
+
+	static __typeInfo = $.registerStructType(
+		"main.Point",
+		() => new Point(),
+		[{ name: "String", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }],
+		Point,
+		[{ name: "X", key: "X", type: { kind: $.TypeKind.Basic, name: "int" } }, { name: "Y", key: "Y", type: { kind: $.TypeKind.Basic, name: "int" } }]
+	)
+}
+
+export class Box {
+	public get value(): any {
+		return this._fields.value.value
+	}
+	public set value(value: any) {
+		this._fields.value.value = value
+	}
+
+	public get pair(): any[] {
+		return this._fields.pair.value
+	}
+	public set pair(value: any[]) {
+		this._fields.pair.value = value
+	}
+
+	public _fields: {
+		value: $.VarRef<any>
+		pair: $.VarRef<any[]>
+	}
+
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{value?: any, pair?: any[]}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
+		this._fields = {
+			value: $.varRef(init?.value ?? ($.genericZero(__typeArgs, "T", null) as any)),
+			pair: $.varRef(init?.pair !== undefined ? $.cloneArrayValue(init.pair) : Array.from({ length: 2 }, () => $.genericZero(__typeArgs, "T", null)))
+		}
+	}
+
+	public clone(): Box {
+		const cloned = new Box(undefined, this.__typeArgs)
+		cloned._fields = {
+			value: $.varRef(this._fields.value.value),
+			pair: $.varRef($.cloneArrayValue(this._fields.pair.value))
+		}
+		return $.markAsStructValue(cloned)
+	}
+
+	public async Describe(): globalThis.Promise<string> {
+		const b = this
+		const __typeArgs = this.__typeArgs
+		let zero: any = $.genericZero(__typeArgs, "T", null)
+		return (((await $.callGenericMethod(__typeArgs, "T", "String", b.value) + " ") + await $.callGenericMethod(__typeArgs, "T", "String", $.arrayIndex(b.pair, 1))) + " ") + await $.callGenericMethod(__typeArgs, "T", "String", zero)
+	}
+
+	public Reset(): void {
+		let b: Box | $.VarRef<Box> | null = this
+		const __typeArgs = { U: ($.isVarRef(this) ? (this as any).value : this)?.__typeArgs?.["T"] } as $.GenericTypeArgs
+		let zero: any = $.genericZero(__typeArgs, "U", null)
+		$.pointerValue<Box>(b).value = zero
+	}
+
+	static __typeInfo = $.registerStructType(
+		"main.Box",
+		() => new Box(),
+		[{ name: "Describe", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }, { name: "Reset", args: [], returns: [] }],
+		Box,
+		[{ name: "value", key: "value", type: { kind: $.TypeKind.Interface, methods: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] } }, { name: "pair", key: "pair", type: { kind: $.TypeKind.Array, elemType: { kind: $.TypeKind.Interface, methods: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }, length: 2 } }]
+	)
+}
+
+export function IntVal_String(i: IntVal): string {
+	return ("IntVal(" + strconv.Itoa($.int(i))) + ")"
+}
+
+export function NewBox(__typeArgs: $.GenericTypeArgs | undefined, v: any): Box | $.VarRef<Box> | null {
+	return new Box({value: v}, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }, zero: () => null }})
+}
+
+export async function main(): globalThis.Promise<void> {
+	let b = $.markAsStructValue(new Box({value: 7}, {T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.IntVal" }, zero: () => 0, methods: {String: (receiver: any, ...args: any[]) => (IntVal_String as any)(($.isVarRef(receiver) ? receiver.value : receiver), ...args)}, methodSignatures: [{ name: "String", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "string" } }] }] }}))
+	$.println("literal:", await $.markAsStructValue($.cloneStructValue(b)).Describe())
+	let z: Box = $.markAsStructValue(new Box(undefined, {T: { type: "main.Point", zero: () => $.markAsStructValue(new Point()), methods: {String: (receiver: any, ...args: any[]) => receiver.String(...args)} }}))
+	$.println("zero:", await $.markAsStructValue($.cloneStructValue(z)).Describe())
+	let p: Box | $.VarRef<Box> | null = (NewBox({T: { type: "main.Point", zero: () => $.markAsStructValue(new Point()), methods: {String: (receiver: any, ...args: any[]) => receiver.String(...args)} }}, $.markAsStructValue(new Point({X: 1, Y: 2}))) as Box | $.VarRef<Box> | null)
+	$.println("constructor:", await $.markAsStructValue($.cloneStructValue($.pointerValue<Box>(p))).Describe())
+	Box.prototype.Reset.call(p)
+	$.println("reset:", await $.markAsStructValue($.cloneStructValue($.pointerValue<Box>(p))).Describe())
+	let c = $.markAsStructValue($.cloneStructValue($.pointerValue<Box>(p)))
+	$.println("copy:", await $.markAsStructValue($.cloneStructValue(c)).Describe())
+}
+
+if ($.isMainScript(import.meta)) {
+	await main()
+}
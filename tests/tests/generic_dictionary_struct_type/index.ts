@@ -0,0 +1,3 @@
+export type { IntVal, Stringer } from "./generic_dictionary_struct_type.gs.ts"
+export { Box, IntVal_String, NewBox, Point } from "./generic_dictionary_struct_type.gs.ts"
+import "./generic_dictionary_struct_type.gs.ts"
@@ -15,14 +15,17 @@ export class queue {
 		buf: $.VarRef<$.Slice<any>>
 	}
 
-	constructor(init?: Partial<{buf?: $.Slice<any>}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{buf?: $.Slice<any>}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
 			buf: $.varRef(init?.buf ?? (null as $.Slice<any>))
 		}
 	}
 
 	public clone(): queue {
-		const cloned = new queue()
+		const cloned = new queue(undefined, this.__typeArgs)
 		cloned._fields = {
 			buf: $.varRef(this._fields.buf.value)
 		}
@@ -39,7 +42,7 @@ export class queue {
 }
 
 export function newQueue(__typeArgs: $.GenericTypeArgs | undefined, capacity: number): queue | $.VarRef<queue> | null {
-	return new queue({buf: $.makeSlice<any>(capacity)})
+	return new queue({buf: $.makeSlice<any>(capacity, undefined, undefined, () => $.genericZero(__typeArgs, "T", null))}, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }})
 }
 
 export async function main(): globalThis.Promise<void> {
@@ -7,13 +7,16 @@ export class nistCurve {
 	public _fields: {
 	}
 
-	constructor(init?: Partial<{}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
 		}
 	}
 
 	public clone(): nistCurve {
-		const cloned = new nistCurve()
+		const cloned = new nistCurve(undefined, this.__typeArgs)
 		cloned._fields = {
 		}
 		return $.markAsStructValue(cloned)
@@ -21,12 +24,14 @@ export class nistCurve {
 
 	public async Add(p1: any, p2: any): globalThis.Promise<any> {
 		const curve: nistCurve | $.VarRef<nistCurve> | null = this
-		return (await p1.Add(p1, p2) as any)
+		const __typeArgs = ($.isVarRef(this) ? (this as any).value : this)?.__typeArgs
+		return (await $.callGenericMethod(__typeArgs, "Point", "Add", p1, p1, p2) as any)
 	}
 
 	public Zero(): any {
 		const curve: nistCurve | $.VarRef<nistCurve> | null = this
-		let p: any = null
+		const __typeArgs = ($.isVarRef(this) ? (this as any).value : this)?.__typeArgs
+		let p: any = $.genericZero(__typeArgs, "Point", null)
 		return p
 	}
 
@@ -89,7 +94,7 @@ $.registerInterfaceType(
 	[{ name: "Add", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }, { type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [{ type: { kind: $.TypeKind.Interface, methods: [] } }] }]
 );
 
-export let curve: nistCurve | $.VarRef<nistCurve> | null = new nistCurve()
+export let curve: nistCurve | $.VarRef<nistCurve> | null = new nistCurve(undefined, {Point: { type: { kind: $.TypeKind.Pointer, elemType: "main.point" }, zero: () => null, methods: {Add: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).Add(...args)} }})
 
 export function __goscript_set_curve(__goscriptValue: nistCurve | $.VarRef<nistCurve> | null): void {
 	curve = __goscriptValue
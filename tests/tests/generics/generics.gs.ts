@@ -1,19 +1,7 @@
 // Generated file based on generics.go
 // Updated when compliance tests are re-run, DO NOT EDIT!
 
-import * as $ from "@goscript/builtin/index.ts"
-
-export function printVal(__typeArgs: $.GenericTypeArgs | undefined, val: any): void {
-	$.println(val)
-}
-
-export function equal(__typeArgs: $.GenericTypeArgs | undefined, a: any, b: any): boolean {
-	return a == b
-}
-
-export function getLength(__typeArgs: $.GenericTypeArgs | undefined, s: any): number {
-	return $.len(s)
-}
+import * as $ from "@goscript/builtin/index.js"
 
 export class Pair {
 	public get First(): any {
@@ -35,15 +23,18 @@ export class Pair {
 		Second: $.VarRef<any>
 	}
 
-	constructor(init?: Partial<{First?: any, Second?: any}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{First?: any, Second?: any}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
-			First: $.varRef(init?.First ?? null),
-			Second: $.varRef(init?.Second ?? null)
+			First: $.varRef(init?.First ?? ($.genericZero(__typeArgs, "T", null) as any)),
+			Second: $.varRef(init?.Second ?? ($.genericZero(__typeArgs, "T", null) as any))
 		}
 	}
 
 	public clone(): Pair {
-		const cloned = new Pair()
+		const cloned = new Pair(undefined, this.__typeArgs)
 		cloned._fields = {
 			First: $.varRef(this._fields.First.value),
 			Second: $.varRef(this._fields.Second.value)
@@ -58,65 +49,91 @@ export class Pair {
 
 	static __typeInfo = $.registerStructType(
 		"main.Pair",
-		new Pair(),
-		[{ name: "GetFirst", args: [], returns: [] }],
+		() => new Pair(),
+		[{ name: "GetFirst", args: [], returns: [{ type: { kind: $.TypeKind.Interface, methods: [] } }] }],
 		Pair,
-		{"First": { kind: $.TypeKind.Interface, methods: [] }, "Second": { kind: $.TypeKind.Interface, methods: [] }}
+		[{ name: "First", key: "First", type: { kind: $.TypeKind.Interface, methods: [] } }, { name: "Second", key: "Second", type: { kind: $.TypeKind.Interface, methods: [] } }]
 	)
 }
 
+export function printVal(__typeArgs: $.GenericTypeArgs | undefined, val: any): void {
+	$.println(val)
+}
+
+export function equal(__typeArgs: $.GenericTypeArgs | undefined, a: any, b: any): boolean {
+	return $.comparableEqual(a, b)
+}
+
+export function getLength(__typeArgs: $.GenericTypeArgs | undefined, s: any): number {
+	return $.len(s)
+}
+
 export function makePair(__typeArgs: $.GenericTypeArgs | undefined, a: any, b: any): Pair {
-	return $.markAsStructValue(new Pair({First: a, Second: b}))
+	return $.markAsStructValue(new Pair({First: a, Second: b}, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }}))
 }
 
-export function append2(__typeArgs: $.GenericTypeArgs | undefined, slice: $.Slice<any>, elem: any): $.Slice<any> {
+export function append2<T>(__typeArgs: $.GenericTypeArgs | undefined, slice: $.Slice<T>, elem: any): $.Slice<T> {
 	return $.append(slice, elem)
 }
 
-export async function main(): Promise<void> {
+export async function main(): globalThis.Promise<void> {
+	// Test basic generic function
 	$.println("=== Basic Generic Function ===")
-	printVal({T: { zero: () => 0 }}, 42)
-	printVal({T: { zero: () => "" }}, "hello")
-	printVal({T: { zero: () => false }}, true)
+	printVal({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, 42)
+	printVal({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "hello")
+	printVal({T: { type: { kind: $.TypeKind.Basic, name: "bool" }, zero: () => false }}, true)
+
+	// Test comparable constraint
 	$.println("=== Comparable Constraint ===")
-	$.println(equal({T: { zero: () => 0 }}, 1, 1))
-	$.println(equal({T: { zero: () => 0 }}, 1, 2))
-	$.println(equal({T: { zero: () => "" }}, "hello", "hello"))
-	$.println(equal({T: { zero: () => "" }}, "hello", "world"))
+	$.println(equal({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, 1, 1))
+	$.println(equal({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, 1, 2))
+	$.println(equal({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "hello", "hello"))
+	$.println(equal({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "hello", "world"))
+
+	// Test union constraint with string
 	$.println("=== Union Constraint ===")
 	let str = "hello"
-	$.println(getLength({S: { zero: () => "" }}, str))
-	let bytes = $.stringToBytes("world")
-	$.println(getLength({S: { zero: () => null }}, bytes))
+	$.println(getLength({S: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, str))
+
+	// Test union constraint with []byte
+	let bytes: $.Slice<number> = new Uint8Array([119, 111, 114, 108, 100])
+	$.println(getLength({S: { type: { kind: $.TypeKind.Slice, elemType: { kind: $.TypeKind.Basic, name: "uint8" } }, zero: () => null }}, bytes))
+
+	// Test generic struct
 	$.println("=== Generic Struct ===")
-	let intPair = $.markAsStructValue(makePair({T: { zero: () => 0 }}, 10, 20).clone())
-	$.println($.markAsStructValue(intPair.clone()).GetFirst())
+	let intPair = ($.markAsStructValue($.cloneStructValue(makePair({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, 10, 20))) as Pair)
+	$.println($.markAsStructValue($.cloneStructValue(intPair)).GetFirst())
 	$.println(intPair.First)
 	$.println(intPair.Second)
-	let stringPair = $.markAsStructValue(makePair({T: { zero: () => "" }}, "foo", "bar").clone())
-	$.println($.markAsStructValue(stringPair.clone()).GetFirst())
+
+	let stringPair = ($.markAsStructValue($.cloneStructValue(makePair({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "foo", "bar"))) as Pair)
+	$.println($.markAsStructValue($.cloneStructValue(stringPair)).GetFirst())
 	$.println(stringPair.First)
 	$.println(stringPair.Second)
+
+	// Test generic slice operations
 	$.println("=== Generic Slice Operations ===")
-	let nums = $.arrayToSlice<number>([1, 2, 3])
-	nums = append2({T: { zero: () => 0 }}, nums, 4)
-	for (let __rangeIndex = 0; __rangeIndex < $.len(nums); __rangeIndex++) {
-		let n = nums![__rangeIndex]
+	let nums: $.Slice<number> = $.arrayToSlice<number>([1, 2, 3])
+	nums = (append2({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, nums, 4) as $.Slice<number>)
+	for (let __goscriptRangeTarget0 = nums, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget0); __rangeIndex++) {
+		let n = __goscriptRangeTarget0![__rangeIndex]
 		$.println(n)
 	}
-	let words = $.arrayToSlice<string>(["a", "b"])
-	words = append2({T: { zero: () => "" }}, words, "c")
-	for (let __rangeIndex = 0; __rangeIndex < $.len(words); __rangeIndex++) {
-		let w = words![__rangeIndex]
+
+	let words: $.Slice<string> = $.arrayToSlice<string>(["a", "b"])
+	words = (append2({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, words, "c") as $.Slice<string>)
+	for (let __goscriptRangeTarget1 = words, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget1); __rangeIndex++) {
+		let w = __goscriptRangeTarget1![__rangeIndex]
 		$.println(w)
 	}
+
+	// Test type inference
 	$.println("=== Type Inference ===")
-	let result = $.markAsStructValue(makePair({T: { zero: () => 0 }}, 100, 200).clone())
+	let result = ($.markAsStructValue($.cloneStructValue(makePair({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, 100, 200))) as Pair)
 	$.println(result.First)
 	$.println(result.Second)
 }
 
-
 if ($.isMainScript(import.meta)) {
 	await main()
 }
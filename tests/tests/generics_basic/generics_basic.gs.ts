@@ -37,14 +37,17 @@ export class Stack {
 		items: $.VarRef<$.Slice<any>>
 	}
 
-	constructor(init?: Partial<{items?: $.Slice<any>}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{items?: $.Slice<any>}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
 			items: $.varRef(init?.items ?? (null as $.Slice<any>))
 		}
 	}
 
 	public clone(): Stack {
-		const cloned = new Stack()
+		const cloned = new Stack(undefined, this.__typeArgs)
 		cloned._fields = {
 			items: $.varRef(this._fields.items.value)
 		}
@@ -136,14 +139,17 @@ export class Mapper {
 		values: $.VarRef<globalThis.Map<any, any> | null>
 	}
 
-	constructor(init?: Partial<{values?: globalThis.Map<any, any> | null}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{values?: globalThis.Map<any, any> | null}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
 			values: $.varRef(init?.values ?? (null as globalThis.Map<any, any> | null))
 		}
 	}
 
 	public clone(): Mapper {
-		const cloned = new Mapper()
+		const cloned = new Mapper(undefined, this.__typeArgs)
 		cloned._fields = {
 			values: $.varRef(this._fields.values.value)
 		}
@@ -152,7 +158,8 @@ export class Mapper {
 
 	public Get(key: any): [any, boolean] {
 		const m: Mapper | $.VarRef<Mapper> | null = this
-		let [value, ok] = $.mapGet<any, any, any>($.pointerValue<Mapper>(m).values, key, null)
+		const __typeArgs = ($.isVarRef(this) ? (this as any).value : this)?.__typeArgs
+		let [value, ok] = $.mapGet<any, any, any>($.pointerValue<Mapper>(m).values, key, $.genericZero(__typeArgs, "V", null))
 		return [value, ok]
 	}
 
@@ -190,15 +197,18 @@ export class Pair {
 		Second: $.VarRef<any>
 	}
 
-	constructor(init?: Partial<{First?: any, Second?: any}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{First?: any, Second?: any}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
-			First: $.varRef(init?.First ?? (null as any)),
-			Second: $.varRef(init?.Second ?? (null as any))
+			First: $.varRef(init?.First ?? ($.genericZero(__typeArgs, "T", null) as any)),
+			Second: $.varRef(init?.Second ?? ($.genericZero(__typeArgs, "T", null) as any))
 		}
 	}
 
 	public clone(): Pair {
-		const cloned = new Pair()
+		const cloned = new Pair(undefined, this.__typeArgs)
 		cloned._fields = {
 			First: $.varRef(this._fields.First.value),
 			Second: $.varRef(this._fields.Second.value)
@@ -208,7 +218,8 @@ export class Pair {
 
 	public Swap(): Pair {
 		const p = this
-		return $.markAsStructValue(new Pair({First: p.Second, Second: p.First}))
+		const __typeArgs = this.__typeArgs
+		return $.markAsStructValue(new Pair({First: p.Second, Second: p.First}, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }}))
 	}
 
 	static __typeInfo = $.registerStructType(
@@ -236,17 +247,17 @@ export function NewSet<T>(__typeArgs: $.GenericTypeArgs | undefined, values: $.S
 	return _set
 }
 
-export function Set_Add(s: Set, value: any): void {
+export function Set_Add(s: Set, __typeArgs: $.GenericTypeArgs | undefined, value: any): void {
 	$.mapSet(s, value, {})
 }
 
-export function Set_Has(s: Set, value: any): boolean {
+export function Set_Has(s: Set, __typeArgs: $.GenericTypeArgs | undefined, value: any): boolean {
 	let [, ok] = $.mapGet<any, {}, {}>(s, value, {})
 	return ok
 }
 
 export async function CloneAll<T>(__typeArgs: $.GenericTypeArgs | undefined, items: $.Slice<T>): globalThis.Promise<$.Slice<T>> {
-	let clones: $.Slice<T> = $.makeSlice<T>(0, $.len(items))
+	let clones: $.Slice<T> = $.makeSlice<T>(0, $.len(items), undefined, () => $.genericZero(__typeArgs, "T", null))
 	for (let __goscriptRangeTarget1 = items, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget1); __rangeIndex++) {
 		let item = __goscriptRangeTarget1![__rangeIndex]
 		clones = $.append(clones, await $.callGenericMethod(__typeArgs, "T", "Clone", item))
@@ -255,7 +266,7 @@ export async function CloneAll<T>(__typeArgs: $.GenericTypeArgs | undefined, ite
 }
 
 export function NewMapper(__typeArgs: $.GenericTypeArgs | undefined): Mapper | $.VarRef<Mapper> | null {
-	return new Mapper({values: $.makeMap<any, any>()})
+	return new Mapper({values: $.makeMap<any, any>()}, {K: __typeArgs?.["K"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }, V: __typeArgs?.["V"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }})
 }
 
 export async function Apply(__typeArgs: $.GenericTypeArgs | undefined, value: any, fn: ((_p0: any) => any | globalThis.Promise<any>) | null): globalThis.Promise<any> {
@@ -267,7 +278,7 @@ export async function main(): globalThis.Promise<void> {
 	$.println("min:", min({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, 8, 3), min({T: { type: { kind: $.TypeKind.Basic, name: "int", typeName: "main.Score" }, zero: () => 0 }}, 9, 4), min({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "go", "ts"))
 
 	$.println("=== Generic stack ===")
-	let stack: $.VarRef<Stack> = $.varRef($.markAsStructValue(new Stack()))
+	let stack: $.VarRef<Stack> = $.varRef($.markAsStructValue(new Stack(undefined, {T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }})))
 	stack.value.Push(10)
 	stack.value.Push(20)
 	let __goscriptTuple0: any = stack.value.Pop()
@@ -280,13 +291,13 @@ export async function main(): globalThis.Promise<void> {
 	$.println("pop:", value, ok, stack.value.Len())
 
 	$.println("=== Generic map alias ===")
-	let seen: Set = (NewSet(undefined, $.arrayToSlice<string>(["go", "ts"])) as Set)
-	Set_Add(seen, "wasm")
-	$.println("set:", Set_Has(seen, "go"), Set_Has(seen, "rust"), $.len(seen))
+	let seen: Set = (NewSet({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, $.arrayToSlice<string>(["go", "ts"])) as Set)
+	Set_Add(seen, {T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "wasm")
+	$.println("set:", Set_Has(seen, {T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "go"), Set_Has(seen, {T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, "rust"), $.len(seen))
 
 	$.println("=== Interface constraint ===")
 	let items: $.Slice<Item | $.VarRef<Item> | null> = $.arrayToSlice<Item | $.VarRef<Item> | null>([new Item({Name: "alpha"}), new Item({Name: "beta"})])
-	let clones: $.Slice<Item | $.VarRef<Item> | null> = (await CloneAll({T: { type: { kind: $.TypeKind.Pointer, elemType: "main.Item" }, zero: () => null }}, items) as $.Slice<Item | $.VarRef<Item> | null>)
+	let clones: $.Slice<Item | $.VarRef<Item> | null> = (await CloneAll({T: { type: { kind: $.TypeKind.Pointer, elemType: "main.Item" }, zero: () => null, methods: {Clone: (receiver: any, ...args: any[]) => $.pointerValue<any>(receiver).Clone(...args)} }}, items) as $.Slice<Item | $.VarRef<Item> | null>)
 	$.println("clone:", $.pointerValue<Item>($.arrayIndex(clones!, 0)).Name, $.pointerValue<Item>($.arrayIndex(clones!, 1)).Name, $.arrayIndex(clones!, 0) == $.arrayIndex(items!, 0))
 
 	$.println("=== Generic struct with map field ===")
@@ -304,7 +315,7 @@ export async function main(): globalThis.Promise<void> {
 	}, ({ kind: $.TypeKind.Function, params: [{ kind: $.TypeKind.Basic, name: "int" }], results: [{ kind: $.TypeKind.Basic, name: "int" }] } as $.FunctionTypeInfo))))
 
 	$.println("=== Generic pair method ===")
-	let pair = $.markAsStructValue(new Pair({First: "left", Second: "right"}))
+	let pair = $.markAsStructValue(new Pair({First: "left", Second: "right"}, {T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}))
 	let swapped = ($.markAsStructValue($.cloneStructValue($.markAsStructValue($.cloneStructValue(pair)).Swap())) as Pair)
 	$.println("pair:", swapped.First, swapped.Second)
 }
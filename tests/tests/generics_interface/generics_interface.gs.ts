@@ -1,9 +1,9 @@
 // Generated file based on generics_interface.go
 // Updated when compliance tests are re-run, DO NOT EDIT!
 
-import * as $ from "@goscript/builtin/index.ts"
+import * as $ from "@goscript/builtin/index.js"
 
-export type Container = null | {
+export type Container = {
 	Get(): any
 	Set(_p0: any): void
 	Size(): number
@@ -12,10 +12,10 @@ export type Container = null | {
 $.registerInterfaceType(
 	"main.Container",
 	null,
-	[{ name: "Get", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Interface, methods: [] } }] }, { name: "Set", args: [{ name: "_p0", type: { kind: $.TypeKind.Interface, methods: [] } }], returns: [] }, { name: "Size", args: [], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "int" } }] }]
-)
+	[{ name: "Get", args: [], returns: [{ type: { kind: $.TypeKind.Interface, methods: [] } }] }, { name: "Set", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [] }, { name: "Size", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "int" } }] }]
+);
 
-export type Comparable = null | {
+export type Comparable = {
 	Compare(_p0: any): number
 	Equal(_p0: any): boolean
 }
@@ -23,8 +23,8 @@ export type Comparable = null | {
 $.registerInterfaceType(
 	"main.Comparable",
 	null,
-	[{ name: "Compare", args: [{ name: "_p0", type: { kind: $.TypeKind.Interface, methods: [] } }], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "int" } }] }, { name: "Equal", args: [{ name: "_p0", type: { kind: $.TypeKind.Interface, methods: [] } }], returns: [{ name: "_r0", type: { kind: $.TypeKind.Basic, name: "bool" } }] }]
-)
+	[{ name: "Compare", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [{ type: { kind: $.TypeKind.Basic, name: "int" } }] }, { name: "Equal", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [{ type: { kind: $.TypeKind.Basic, name: "bool" } }] }]
+);
 
 export class ValueContainer {
 	public get value(): any {
@@ -46,15 +46,18 @@ export class ValueContainer {
 		count: $.VarRef<number>
 	}
 
-	constructor(init?: Partial<{value?: any, count?: number}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{value?: any, count?: number}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
-			value: $.varRef(init?.value ?? null),
-			count: $.varRef(init?.count ?? 0)
+			value: $.varRef(init?.value ?? ($.genericZero(__typeArgs, "T", null) as any)),
+			count: $.varRef(init?.count ?? (0 as number))
 		}
 	}
 
 	public clone(): ValueContainer {
-		const cloned = new ValueContainer()
+		const cloned = new ValueContainer(undefined, this.__typeArgs)
 		cloned._fields = {
 			value: $.varRef(this._fields.value.value),
 			count: $.varRef(this._fields.count.value)
@@ -63,27 +66,27 @@ export class ValueContainer {
 	}
 
 	public Get(): any {
-		const b = this
-		return $.pointerValue(b).value
+		const b: ValueContainer | $.VarRef<ValueContainer> | null = this
+		return $.pointerValue<ValueContainer>(b).value
 	}
 
 	public Set(v: any): void {
-		const b = this
-		$.pointerValue(b).value = v
-		$.pointerValue(b).count++
+		let b: ValueContainer | $.VarRef<ValueContainer> | null = this
+		$.pointerValue<ValueContainer>(b).value = v
+		$.pointerValue<ValueContainer>(b).count++
 	}
 
 	public Size(): number {
-		const b = this
-		return $.pointerValue(b).count
+		const b: ValueContainer | $.VarRef<ValueContainer> | null = this
+		return $.pointerValue<ValueContainer>(b).count
 	}
 
 	static __typeInfo = $.registerStructType(
 		"main.ValueContainer",
-		new ValueContainer(),
-		[{ name: "Get", args: [], returns: [] }, { name: "Set", args: [], returns: [] }, { name: "Size", args: [], returns: [] }],
+		() => new ValueContainer(),
+		[{ name: "Get", args: [], returns: [{ type: { kind: $.TypeKind.Interface, methods: [] } }] }, { name: "Set", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [] }, { name: "Size", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "int" } }] }],
 		ValueContainer,
-		{"value": { kind: $.TypeKind.Interface, methods: [] }, "count": { kind: $.TypeKind.Basic, name: "int" }}
+		[{ name: "value", key: "value", type: { kind: $.TypeKind.Interface, methods: [] } }, { name: "count", key: "count", type: { kind: $.TypeKind.Basic, name: "int" } }]
 	)
 }
 
@@ -101,7 +104,7 @@ export class StringValueContainer {
 
 	constructor(init?: Partial<{value?: string}>) {
 		this._fields = {
-			value: $.varRef(init?.value ?? "")
+			value: $.varRef(init?.value ?? ("" as string))
 		}
 	}
 
@@ -114,11 +117,11 @@ export class StringValueContainer {
 	}
 
 	public Compare(other: string): number {
-		const s = this
-		if ($.pointerValue(s).value < other) {
+		const s: StringValueContainer | $.VarRef<StringValueContainer> | null = this
+		if ($.stringCompare($.pointerValue<StringValueContainer>(s).value, other) < 0) {
 			return -1
 		} else {
-			if ($.pointerValue(s).value > other) {
+			if ($.stringCompare($.pointerValue<StringValueContainer>(s).value, other) > 0) {
 				return 1
 			}
 		}
@@ -126,47 +129,51 @@ export class StringValueContainer {
 	}
 
 	public Equal(other: string): boolean {
-		const s = this
-		return $.pointerValue(s).value == other
+		const s: StringValueContainer | $.VarRef<StringValueContainer> | null = this
+		return $.stringEqual($.pointerValue<StringValueContainer>(s).value, other)
 	}
 
 	static __typeInfo = $.registerStructType(
 		"main.StringValueContainer",
-		new StringValueContainer(),
-		[{ name: "Compare", args: [], returns: [] }, { name: "Equal", args: [], returns: [] }],
+		() => new StringValueContainer(),
+		[{ name: "Compare", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [{ type: { kind: $.TypeKind.Basic, name: "int" } }] }, { name: "Equal", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [{ type: { kind: $.TypeKind.Basic, name: "bool" } }] }],
 		StringValueContainer,
-		{"value": { kind: $.TypeKind.Basic, name: "string" }}
+		[{ name: "value", key: "value", type: { kind: $.TypeKind.Basic, name: "string" } }]
 	)
 }
 
-export function useContainer(__typeArgs: $.GenericTypeArgs | undefined, c: Container, val: any): any {
-	c.Set(val)
-	return c.Get()
+export async function useContainer(__typeArgs: $.GenericTypeArgs | undefined, c: Container | null, val: any): globalThis.Promise<any> {
+	await $.pointerValue<Exclude<Container, null>>(c).Set(val)
+	return (await $.pointerValue<Exclude<Container, null>>(c).Get() as any)
 }
 
-export function checkEqual(__typeArgs: $.GenericTypeArgs | undefined, c: Comparable, val: any): boolean {
-	return c.Equal(val)
+export async function checkEqual(__typeArgs: $.GenericTypeArgs | undefined, c: Comparable | null, val: any): globalThis.Promise<boolean> {
+	return $.pointerValue<Exclude<Comparable, null>>(c).Equal(val)
 }
 
-export async function main(): Promise<void> {
+export async function main(): globalThis.Promise<void> {
 	$.println("=== Generic Interface Test ===")
-	let intValueContainer = new ValueContainer()
-	let result = useContainer({T: { zero: () => 0 }}, intValueContainer, 42)
+
+	// Test ValueContainer implementing Container
+	let intValueContainer: ValueContainer | $.VarRef<ValueContainer> | null = new ValueContainer(undefined, {T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }})
+	let result = (await useContainer({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, $.interfaceValue<Container | null>(intValueContainer, "*main.ValueContainer[int]"), 42) as number)
 	$.println("Int ValueContainer result:", result)
-	$.println("Int ValueContainer size:", $.pointerValue(intValueContainer).Size())
-	let stringValueContainer = new ValueContainer()
-	let strResult = useContainer({T: { zero: () => "" }}, stringValueContainer, "hello")
+	$.println("Int ValueContainer size:", ValueContainer.prototype.Size.call(intValueContainer))
+
+	let stringValueContainer: ValueContainer | $.VarRef<ValueContainer> | null = new ValueContainer(undefined, {T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }})
+	let strResult = (await useContainer({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, $.interfaceValue<Container | null>(stringValueContainer, "*main.ValueContainer[string]"), "hello") as string)
 	$.println("String ValueContainer result:", strResult)
-	$.println("String ValueContainer size:", $.pointerValue(stringValueContainer).Size())
-	let sb = new StringValueContainer({value: "test"})
-	$.println("String comparison equal:", checkEqual({T: { zero: () => "" }}, sb, "test"))
-	$.println("String comparison not equal:", checkEqual({T: { zero: () => "" }}, sb, "other"))
-	$.println("String comparison -1:", $.pointerValue(sb).Compare("zebra"))
-	$.println("String comparison 1:", $.pointerValue(sb).Compare("alpha"))
-	$.println("String comparison 0:", $.pointerValue(sb).Compare("test"))
+	$.println("String ValueContainer size:", ValueContainer.prototype.Size.call(stringValueContainer))
+
+	// Test StringValueContainer implementing Comparable
+	let sb: StringValueContainer | $.VarRef<StringValueContainer> | null = new StringValueContainer({value: "test"})
+	$.println("String comparison equal:", await checkEqual({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, $.interfaceValue<Comparable | null>(sb, "*main.StringValueContainer"), "test"))
+	$.println("String comparison not equal:", await checkEqual({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}, $.interfaceValue<Comparable | null>(sb, "*main.StringValueContainer"), "other"))
+	$.println("String comparison -1:", StringValueContainer.prototype.Compare.call(sb, "zebra"))
+	$.println("String comparison 1:", StringValueContainer.prototype.Compare.call(sb, "alpha"))
+	$.println("String comparison 0:", StringValueContainer.prototype.Compare.call(sb, "test"))
 }
 
-
 if ($.isMainScript(import.meta)) {
 	await main()
 }
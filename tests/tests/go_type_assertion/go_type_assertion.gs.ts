@@ -7,7 +7,7 @@ export async function main(): globalThis.Promise<void> {
 	let x: any = $.interfaceValue<any>($.functionValue((): void => {
 		$.println("goroutine executed")
 	}, ({ kind: $.TypeKind.Function, params: [], results: [] } as $.FunctionTypeInfo)), "func()")
-	queueMicrotask(async () => { await $.mustTypeAssert<(() => void) | null>(x, ({ kind: $.TypeKind.Function, params: [], results: [] } as $.FunctionTypeInfo))!() })
+	$.go(async () => { await $.mustTypeAssert<(() => void) | null>(x, ({ kind: $.TypeKind.Function, params: [], results: [] } as $.FunctionTypeInfo))!() }, "main.main", "github.com/s4wave/goscript/tests/tests/go_type_assertion/go_type_assertion.go:7")
 	$.println("main finished")
 }
 
@@ -16,7 +16,7 @@ export async function main(): globalThis.Promise<void> {
 	let completed = 0
 
 	for (let w = 0; w < 16; w++) {
-		queueMicrotask(async () => { await (async (id: number): globalThis.Promise<void> => {
+		$.go(async () => { await (async (id: number): globalThis.Promise<void> => {
 			// Await-free CPU span: a tight loop with no I/O or channel op, the
 			// starvation shape from issue_118 scaled across 16 goroutines.
 			let sum = 0
@@ -28,7 +28,7 @@ export async function main(): globalThis.Promise<void> {
 				completed++
 				await broadcastFn!()
 			}, ({ kind: $.TypeKind.Function, params: [({ kind: $.TypeKind.Function, params: [], results: [] } as $.FunctionTypeInfo), ({ kind: $.TypeKind.Function, params: [], results: [{ kind: $.TypeKind.Channel, direction: "receive", elemType: { kind: $.TypeKind.Struct, methods: [], fields: [] } }] } as $.FunctionTypeInfo)], results: [] } as $.FunctionTypeInfo)))
-		})(w) })
+		})(w) }, "main.main", "github.com/s4wave/goscript/tests/tests/goroutine_broadcast_fanout/main.go:25")
 	}
 
 	// Coordinator mirrors ConcurrentQueue.WaitIdle: read state and the wait
@@ -77,16 +77,16 @@ export async function main(): globalThis.Promise<void> {
 
 	// Start 3 worker goroutines
 	for (let i = 0; i < 3; i++) {
-		queueMicrotask(async () => { await worker(i) })
+		$.go(async () => { await worker(i) }, "main.main", "github.com/s4wave/goscript/tests/tests/goroutines/goroutines.go:51")
 	}
 
 	// Start another worker goroutine
-	queueMicrotask(async () => { await anotherWorker("test") })
+	$.go(async () => { await anotherWorker("test") }, "main.main", "github.com/s4wave/goscript/tests/tests/goroutines/goroutines.go:55")
 
 	// Start an anonymous function worker
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await $.chanSend(messages, $.markAsStructValue(new Message({priority: 50, text: "Anonymous function worker"})))
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/goroutines/goroutines.go:58")
 
 	// Add status message
 	allMessages = $.append(allMessages, $.markAsStructValue(new Message({priority: 1, text: "Main: Workers started"})))
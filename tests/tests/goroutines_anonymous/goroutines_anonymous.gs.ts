@@ -6,9 +6,9 @@ import * as $ from "@goscript/builtin/index.js"
 export async function main(): globalThis.Promise<void> {
 	// Start an anonymous function worker
 	let msgs: $.Channel<string> | null = $.makeChannel<string>(1, "", "both")
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await $.chanSend(msgs, "anonymous function worker")
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/goroutines_anonymous/goroutines_anonymous.go:6")
 	$.println(await $.chanRecv(msgs))
 }
 
@@ -50,7 +50,7 @@ export function NewFoo(): Foo | $.VarRef<Foo> | null {
 
 export async function main(): globalThis.Promise<void> {
 	let f: Foo | $.VarRef<Foo> | null = NewFoo()
-	queueMicrotask(async () => { await Foo.prototype.Bar.call(f) })
+	$.go(async () => { await Foo.prototype.Bar.call(f) }, "main.main", "github.com/s4wave/goscript/tests/tests/goroutines_selector/goroutines_selector.go:18")
 	await $.chanRecv($.pointerValue<Foo>(f).done)
 	$.println("main done")
 }
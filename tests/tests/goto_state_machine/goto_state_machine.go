@@ -0,0 +1,263 @@
+package main
+
+// Functions whose gotos do not fit the structured loop and block shapes are
+// lowered as a state machine. Defers, named results, awaits and unlabeled
+// break/continue must keep their Go meaning inside it.
+
+// lex jumps into the middle of the token loop from nested blocks, the way
+// goyacc-generated parsers do.
+func lex(input string) (tokens []string, err string) {
+	defer func() {
+		if err != "" {
+			tokens = append(tokens, "<error>")
+		}
+	}()
+	i := 0
+	var cur []byte
+	if len(input) == 0 {
+		goto done
+	}
+next:
+	if i >= len(input) {
+		goto flush
+	}
+	switch c := input[i]; {
+	case c == ' ':
+		i++
+		goto flush
+	case c >= '0' && c <= '9':
+		for j := 0; j < 2; j++ {
+			if j == 1 {
+				cur = append(cur, c)
+				i++
+				goto next
+			}
+		}
+	case c == '#':
+		err = "bad char"
+		return
+	}
+	cur = append(cur, '?')
+	i++
+	goto next
+flush:
+	if len(cur) > 0 {
+		tokens = append(tokens, string(cur))
+		cur = cur[:0]
+	}
+	if i < len(input) {
+		goto next
+	}
+done:
+	return tokens, ""
+}
+
+// scan mixes gotos with unlabeled break and continue in the enclosing loop.
+func scan(values []int) (sum int) {
+	for _, v := range values {
+		if v < 0 {
+			goto negative
+		}
+		if v == 0 {
+			break
+		}
+	positive:
+		sum += v
+		if v%2 == 0 {
+			continue
+		}
+		sum += 100
+		if v > 5 {
+			goto negative
+		}
+		continue
+	negative:
+		sum -= 1
+		if v == -7 {
+			v = 1
+			goto positive
+		}
+	}
+	return
+}
+
+// tokenize jumps between states in any order, including into the middle of
+// the function from a loop nested in a later state.
+func tokenize(s string) (kinds []string) {
+	i := 0
+start:
+	if i >= len(s) {
+		return
+	}
+	if s[i] >= '0' && s[i] <= '9' {
+		goto number
+	}
+	if s[i] == ' ' {
+		i++
+		goto start
+	}
+ident:
+	for i < len(s) && s[i] != ' ' {
+		if s[i] >= '0' && s[i] <= '9' {
+			kinds = append(kinds, "ident")
+			goto number
+		}
+		i++
+	}
+	kinds = append(kinds, "ident")
+	goto start
+number:
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	kinds = append(kinds, "number")
+	if i < len(s) && s[i] != ' ' {
+		goto ident
+	}
+	goto start
+}
+
+// collatz has overlapping backward jumps and a forward jump out of a nested
+// loop, which no structured shape covers.
+func collatz(n int) (steps int, peak int) {
+	peak = n
+start:
+	if n == 1 {
+		goto done
+	}
+	for k := 0; k < 2; k++ {
+		if n > 1000 {
+			goto overflow
+		}
+	}
+	if n%2 == 0 {
+		goto even
+	}
+odd:
+	n = 3*n + 1
+	steps++
+	if n > peak {
+		peak = n
+	}
+	if n%2 != 0 {
+		goto odd
+	}
+even:
+	n /= 2
+	steps++
+	if n%2 == 0 {
+		goto even
+	}
+	goto start
+overflow:
+	peak = -1
+done:
+	return
+}
+
+// weave re-enters an outer backward loop from inside an inner one and jumps
+// forward out of nested loops past both of them, awaiting channel operations
+// and rewriting its named result in a defer.
+func weave(x int, ch chan int) (r int) {
+	defer func() {
+		r %= 1000003
+	}()
+	steps := 0
+top:
+	r = r*3 + 1
+	steps++
+	if steps > 12 {
+		return
+	}
+	for j := 0; j < 3; j++ {
+		if (r+j+x)%5 == 0 {
+			goto tail
+		}
+	}
+	r = r*3 + 2
+	for j := 0; j < 3; j++ {
+		if (r+j+x)%3 == 0 {
+			goto middle
+		}
+	}
+middle:
+	r = r*3 + 3
+	steps++
+	if len(ch) < cap(ch) {
+		ch <- r % 7
+	}
+	if (r+x)%2 == 0 {
+		goto top
+	}
+	for j := 0; j < 3; j++ {
+		if (r+j+x)%4 == 0 {
+			goto middle
+		}
+	}
+tail:
+	r = r*3 + 4
+	if len(ch) > 0 {
+		r += <-ch
+	}
+	steps++
+	if steps < 6 {
+		goto top
+	}
+	if steps < 8 {
+		goto middle
+	}
+	if steps < 10 {
+		goto tail
+	}
+	return
+}
+
+// relay awaits channel operations between jumps.
+func relay(ch chan int, n int) (total int) {
+	defer func() {
+		total *= 10
+	}()
+	count := 0
+	if n == 0 {
+		goto done
+	}
+send:
+	ch <- count
+	count++
+	if count < n {
+		goto send
+	}
+recv:
+	total += <-ch
+	count--
+	if count > 0 {
+		goto recv
+	}
+	if total < 10 {
+		n++
+		goto send
+	}
+done:
+	return
+}
+
+func main() {
+	tokens, err := lex("12 a3 4")
+	println("lex:", len(tokens), tokens[0], tokens[1], tokens[2], err == "")
+	tokens, err = lex("1 #")
+	println("lex error:", len(tokens), tokens[0], tokens[1], err)
+	tokens, _ = lex("")
+	println("lex empty:", len(tokens))
+	println("scan:", scan([]int{1, 2, -3, 7, -7, 0, 5}))
+	kinds := tokenize("ab12 7 x9y")
+	for _, kind := range kinds {
+		println("token:", kind)
+	}
+	steps, peak := collatz(6)
+	println("collatz:", steps, peak)
+	steps, peak = collatz(27)
+	println("collatz:", steps, peak)
+	ch := make(chan int, 2)
+	println("weave:", weave(0, ch), weave(1, ch), weave(2, ch))
+	println("relay:", relay(make(chan int, 8), 3), relay(make(chan int, 1), 0))
+}
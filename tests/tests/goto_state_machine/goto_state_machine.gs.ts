@@ -0,0 +1,485 @@
+// Generated file based on goto_state_machine.go
+// Updated when compliance tests are re-run, DO NOT EDIT!
+
+import * as $ from "@goscript/builtin/index.js"
+
+export function lex(input: string): [$.Slice<string>, string] {
+	let tokens: $.Slice<string> = null as $.Slice<string>
+	let err: string = ""
+	using __defer = new $.DisposableStack()
+	__defer.defer(() => { ((): void => {
+		if (!$.stringEqual(err, "")) {
+			tokens = $.append(tokens, "<error>")
+		}
+	})() })
+	let i = 0
+	let cur: $.Slice<number> = null as $.Slice<number>
+	let __goscriptGotoState0 = "__entry"
+	__goscriptGotoLoop0: while (true) {
+		switch (__goscriptGotoState0) {
+			case "__entry":
+			{
+				if ($.len(input) == 0) {
+					__goscriptGotoState0 = "done"
+					continue __goscriptGotoLoop0
+				}
+				__goscriptGotoState0 = "next"
+				continue __goscriptGotoLoop0
+				break
+			}
+			case "next":
+			{
+				if (i >= $.len(input)) {
+					__goscriptGotoState0 = "flush"
+					continue __goscriptGotoLoop0
+				}
+				{
+					var c = $.uint($.indexStringOrBytes(input, i), 8)
+					switch (true) {
+						case $.uint(c, 8) == $.uint(32, 8):
+						{
+							i++
+							__goscriptGotoState0 = "flush"
+							continue __goscriptGotoLoop0
+							break
+						}
+						case ($.uint(c, 8) >= $.uint(48, 8)) && ($.uint(c, 8) <= $.uint(57, 8)):
+						{
+							for (let j = 0; j < 2; j++) {
+								if (j == 1) {
+									cur = $.append(cur, $.uint(c, 8))
+									i++
+									__goscriptGotoState0 = "next"
+									continue __goscriptGotoLoop0
+								}
+							}
+							break
+						}
+						case $.uint(c, 8) == $.uint(35, 8):
+						{
+							err = "bad char"
+							__defer.dispose()
+							return [tokens, err]
+							break
+						}
+					}
+				}
+				cur = $.append(cur, $.uint(63, 8))
+				i++
+				__goscriptGotoState0 = "next"
+				continue __goscriptGotoLoop0
+				__goscriptGotoState0 = "flush"
+				continue __goscriptGotoLoop0
+				break
+			}
+			case "flush":
+			{
+				if ($.len(cur) > 0) {
+					tokens = $.append(tokens, $.bytesToString(cur))
+					cur = $.goSlice(cur, undefined, 0)
+				}
+				if (i < $.len(input)) {
+					__goscriptGotoState0 = "next"
+					continue __goscriptGotoLoop0
+				}
+				__goscriptGotoState0 = "done"
+				continue __goscriptGotoLoop0
+				break
+			}
+			case "done":
+			{
+				const __goscriptReturn0: [$.Slice<string>, string] = [tokens, ""]
+				tokens = __goscriptReturn0[0]
+				err = __goscriptReturn0[1]
+				__defer.dispose()
+				return [tokens, err]
+				break __goscriptGotoLoop0
+				break
+			}
+		}
+		break
+	}
+	throw new globalThis.Error("goscript: unreachable return")
+}
+
+export function scan(values: $.Slice<number>): number {
+	let sum: number = 0
+	for (let __goscriptRangeTarget0 = values, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget0); __rangeIndex++) {
+		let v = __goscriptRangeTarget0![__rangeIndex]
+		let __goscriptGotoState1 = "__entry"
+		__goscriptGotoLoop1: while (true) {
+			switch (__goscriptGotoState1) {
+				case "__entry":
+				{
+					if (v < 0) {
+						__goscriptGotoState1 = "negative"
+						continue __goscriptGotoLoop1
+					}
+					if (v == 0) {
+						__goscriptGotoState1 = "__break"
+						break __goscriptGotoLoop1
+					}
+					__goscriptGotoState1 = "positive"
+					continue __goscriptGotoLoop1
+					break
+				}
+				case "positive":
+				{
+					sum = sum + (v)
+					if ((v % 2) == 0) {
+						__goscriptGotoState1 = "__continue"
+						break __goscriptGotoLoop1
+					}
+					sum = sum + (100)
+					if (v > 5) {
+						__goscriptGotoState1 = "negative"
+						continue __goscriptGotoLoop1
+					}
+					__goscriptGotoState1 = "__continue"
+					break __goscriptGotoLoop1
+					__goscriptGotoState1 = "negative"
+					continue __goscriptGotoLoop1
+					break
+				}
+				case "negative":
+				{
+					sum = sum - (1)
+					if (v == -7) {
+						v = 1
+						__goscriptGotoState1 = "positive"
+						continue __goscriptGotoLoop1
+					}
+					break __goscriptGotoLoop1
+					break
+				}
+			}
+			break
+		}
+		if (__goscriptGotoState1 === "__break") {
+			break
+		}
+		if (__goscriptGotoState1 === "__continue") {
+			continue
+		}
+	}
+	return sum
+}
+
+export function tokenize(s: string): $.Slice<string> {
+	let kinds: $.Slice<string> = null as $.Slice<string>
+	let i = 0
+	start: while (true) {
+		if (i >= $.len(s)) {
+			return kinds
+		}
+
+		let __goscriptGotoState2 = "__entry"
+		__goscriptGotoLoop2: while (true) {
+			switch (__goscriptGotoState2) {
+				case "__entry":
+				{
+					if (($.uint($.indexStringOrBytes(s, i), 8) >= $.uint(48, 8)) && ($.uint($.indexStringOrBytes(s, i), 8) <= $.uint(57, 8))) {
+						__goscriptGotoState2 = "_number"
+						continue __goscriptGotoLoop2
+					}
+					if ($.uint($.indexStringOrBytes(s, i), 8) == $.uint(32, 8)) {
+						i++
+						continue start
+					}
+					__goscriptGotoState2 = "ident"
+					continue __goscriptGotoLoop2
+					break
+				}
+				case "ident":
+				{
+					while ((i < $.len(s)) && ($.uint($.indexStringOrBytes(s, i), 8) != $.uint(32, 8))) {
+						if (($.uint($.indexStringOrBytes(s, i), 8) >= $.uint(48, 8)) && ($.uint($.indexStringOrBytes(s, i), 8) <= $.uint(57, 8))) {
+							kinds = $.append(kinds, "ident")
+							__goscriptGotoState2 = "_number"
+							continue __goscriptGotoLoop2
+						}
+						i++
+					}
+					kinds = $.append(kinds, "ident")
+					continue start
+					__goscriptGotoState2 = "_number"
+					continue __goscriptGotoLoop2
+					break
+				}
+				case "_number":
+				{
+					while (((i < $.len(s)) && ($.uint($.indexStringOrBytes(s, i), 8) >= $.uint(48, 8))) && ($.uint($.indexStringOrBytes(s, i), 8) <= $.uint(57, 8))) {
+						i++
+					}
+					kinds = $.append(kinds, "number")
+					if ((i < $.len(s)) && ($.uint($.indexStringOrBytes(s, i), 8) != $.uint(32, 8))) {
+						__goscriptGotoState2 = "ident"
+						continue __goscriptGotoLoop2
+					}
+					break __goscriptGotoLoop2
+					break
+				}
+			}
+			break
+		}
+		continue start
+		break
+	}
+	throw new globalThis.Error("goscript: unreachable return")
+}
+
+export function collatz(n: number): [number, number] {
+	let steps: number = 0
+	let peak: number = 0
+	peak = n
+	let __goscriptGotoState3 = "start"
+	__goscriptGotoLoop3: while (true) {
+		switch (__goscriptGotoState3) {
+			case "start":
+			{
+				if (n == 1) {
+					__goscriptGotoState3 = "done"
+					continue __goscriptGotoLoop3
+				}
+				for (var k = 0; k < 2; k++) {
+					if (n > 1000) {
+						__goscriptGotoState3 = "overflow"
+						continue __goscriptGotoLoop3
+					}
+				}
+				if ((n % 2) == 0) {
+					__goscriptGotoState3 = "even"
+					continue __goscriptGotoLoop3
+				}
+				__goscriptGotoState3 = "odd"
+				continue __goscriptGotoLoop3
+				break
+			}
+			case "odd":
+			{
+				n = (3 * n) + 1
+				steps++
+				if (n > peak) {
+					peak = n
+				}
+				if ((n % 2) != 0) {
+					__goscriptGotoState3 = "odd"
+					continue __goscriptGotoLoop3
+				}
+				__goscriptGotoState3 = "even"
+				continue __goscriptGotoLoop3
+				break
+			}
+			case "even":
+			{
+				n = Math.trunc(n / 2)
+				steps++
+				if ((n % 2) == 0) {
+					__goscriptGotoState3 = "even"
+					continue __goscriptGotoLoop3
+				}
+				__goscriptGotoState3 = "start"
+				continue __goscriptGotoLoop3
+				__goscriptGotoState3 = "overflow"
+				continue __goscriptGotoLoop3
+				break
+			}
+			case "overflow":
+			{
+				peak = -1
+				__goscriptGotoState3 = "done"
+				continue __goscriptGotoLoop3
+				break
+			}
+			case "done":
+			{
+				return [steps, peak]
+				break __goscriptGotoLoop3
+				break
+			}
+		}
+		break
+	}
+	throw new globalThis.Error("goscript: unreachable return")
+}
+
+export async function weave(x: number, ch: $.Channel<number> | null): globalThis.Promise<number> {
+	let r: number = 0
+	using __defer = new $.DisposableStack()
+	__defer.defer(() => { ((): void => {
+		r = r % (1000003)
+	})() })
+	let steps = 0
+	let __goscriptGotoState4 = "top"
+	__goscriptGotoLoop4: while (true) {
+		switch (__goscriptGotoState4) {
+			case "top":
+			{
+				r = (r * 3) + 1
+				steps++
+				if (steps > 12) {
+					__defer.dispose()
+					return r
+				}
+				for (var j = 0; j < 3; j++) {
+					if ((((r + j) + x) % 5) == 0) {
+						__goscriptGotoState4 = "tail"
+						continue __goscriptGotoLoop4
+					}
+				}
+				r = (r * 3) + 2
+				for (var j = 0; j < 3; j++) {
+					if ((((r + j) + x) % 3) == 0) {
+						__goscriptGotoState4 = "middle"
+						continue __goscriptGotoLoop4
+					}
+				}
+				__goscriptGotoState4 = "middle"
+				continue __goscriptGotoLoop4
+				break
+			}
+			case "middle":
+			{
+				r = (r * 3) + 3
+				steps++
+				if ($.len(ch) < $.cap(ch)) {
+					await $.chanSend(ch, r % 7)
+				}
+				if (((r + x) % 2) == 0) {
+					__goscriptGotoState4 = "top"
+					continue __goscriptGotoLoop4
+				}
+				for (var j = 0; j < 3; j++) {
+					if ((((r + j) + x) % 4) == 0) {
+						__goscriptGotoState4 = "middle"
+						continue __goscriptGotoLoop4
+					}
+				}
+				__goscriptGotoState4 = "tail"
+				continue __goscriptGotoLoop4
+				break
+			}
+			case "tail":
+			{
+				r = (r * 3) + 4
+				if ($.len(ch) > 0) {
+					r = r + (await $.chanRecv(ch))
+				}
+				steps++
+				if (steps < 6) {
+					__goscriptGotoState4 = "top"
+					continue __goscriptGotoLoop4
+				}
+				if (steps < 8) {
+					__goscriptGotoState4 = "middle"
+					continue __goscriptGotoLoop4
+				}
+				if (steps < 10) {
+					__goscriptGotoState4 = "tail"
+					continue __goscriptGotoLoop4
+				}
+				break __goscriptGotoLoop4
+				break
+			}
+		}
+		break
+	}
+	__defer.dispose()
+	return r
+	throw new globalThis.Error("goscript: unreachable return")
+}
+
+export async function relay(ch: $.Channel<number> | null, n: number): globalThis.Promise<number> {
+	let total: number = 0
+	using __defer = new $.DisposableStack()
+	__defer.defer(() => { ((): void => {
+		total = total * (10)
+	})() })
+	let count = 0
+	let __goscriptGotoState5 = "__entry"
+	__goscriptGotoLoop5: while (true) {
+		switch (__goscriptGotoState5) {
+			case "__entry":
+			{
+				if (n == 0) {
+					__goscriptGotoState5 = "done"
+					continue __goscriptGotoLoop5
+				}
+				__goscriptGotoState5 = "send"
+				continue __goscriptGotoLoop5
+				break
+			}
+			case "send":
+			{
+				await $.chanSend(ch, count)
+				count++
+				if (count < n) {
+					__goscriptGotoState5 = "send"
+					continue __goscriptGotoLoop5
+				}
+				__goscriptGotoState5 = "recv"
+				continue __goscriptGotoLoop5
+				break
+			}
+			case "recv":
+			{
+				total = total + (await $.chanRecv(ch))
+				count--
+				if (count > 0) {
+					__goscriptGotoState5 = "recv"
+					continue __goscriptGotoLoop5
+				}
+				if (total < 10) {
+					n++
+					__goscriptGotoState5 = "send"
+					continue __goscriptGotoLoop5
+				}
+				__goscriptGotoState5 = "done"
+				continue __goscriptGotoLoop5
+				break
+			}
+			case "done":
+			{
+				__defer.dispose()
+				return total
+				break __goscriptGotoLoop5
+				break
+			}
+		}
+		break
+	}
+	throw new globalThis.Error("goscript: unreachable return")
+}
+
+export async function main(): globalThis.Promise<void> {
+	let __goscriptTuple0: any = lex("12 a3 4")
+	let tokens: $.Slice<string> = __goscriptTuple0[0]
+	let err = __goscriptTuple0[1]
+	$.println("lex:", $.len(tokens), $.arrayIndex(tokens!, 0), $.arrayIndex(tokens!, 1), $.arrayIndex(tokens!, 2), $.stringEqual(err, ""))
+	let __goscriptTuple1: any = lex("1 #")
+	tokens = __goscriptTuple1[0]
+	err = __goscriptTuple1[1]
+	$.println("lex error:", $.len(tokens), $.arrayIndex(tokens!, 0), $.arrayIndex(tokens!, 1), err)
+	let __goscriptTuple2: any = lex("")
+	tokens = __goscriptTuple2[0]
+	$.println("lex empty:", $.len(tokens))
+	$.println("scan:", scan($.arrayToSlice<number>([1, 2, -3, 7, -7, 0, 5])))
+	let kinds: $.Slice<string> = tokenize("ab12 7 x9y")
+	for (let __goscriptRangeTarget1 = kinds, __rangeIndex = 0; __rangeIndex < $.len(__goscriptRangeTarget1); __rangeIndex++) {
+		let kind = __goscriptRangeTarget1![__rangeIndex]
+		$.println("token:", kind)
+	}
+	let [steps, peak] = collatz(6)
+	$.println("collatz:", steps, peak)
+	let __goscriptTuple3: any = collatz(27)
+	steps = __goscriptTuple3[0]
+	peak = __goscriptTuple3[1]
+	$.println("collatz:", steps, peak)
+	let ch: $.Channel<number> | null = $.makeChannel<number>(2, 0, "both")
+	$.println("weave:", await weave(0, ch), await weave(1, ch), await weave(2, ch))
+	$.println("relay:", await relay($.makeChannel<number>(8, 0, "both"), 3), await relay($.makeChannel<number>(1, 0, "both"), 0))
+}
+
+if ($.isMainScript(import.meta)) {
+	await main()
+}
@@ -139,15 +139,18 @@ export class GenericChannelStore {
 		value: $.VarRef<any>
 	}
 
-	constructor(init?: Partial<{ch?: $.Channel<any> | null, value?: any}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{ch?: $.Channel<any> | null, value?: any}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
 			ch: $.varRef(init?.ch ?? (null as $.Channel<any> | null)),
-			value: $.varRef(init?.value ?? (null as any))
+			value: $.varRef(init?.value ?? ($.genericZero(__typeArgs, "V", null) as any))
 		}
 	}
 
 	public clone(): GenericChannelStore {
-		const cloned = new GenericChannelStore()
+		const cloned = new GenericChannelStore(undefined, this.__typeArgs)
 		cloned._fields = {
 			ch: $.varRef(this._fields.ch.value),
 			value: $.varRef(this._fields.value.value)
@@ -181,7 +184,7 @@ export async function processViaInterface(processor: AsyncProcessor | null, inpu
 }
 
 export function newGenericStore(__typeArgs: $.GenericTypeArgs | undefined, value: any): GenericStore | null {
-	return $.interfaceValue<GenericStore | null>(new GenericChannelStore({ch: $.makeChannel<any>(1, null, "both"), value: value}), "*main.GenericChannelStore[V]")
+	return $.interfaceValue<GenericStore | null>(new GenericChannelStore({ch: $.makeChannel<any>(1, $.genericZero(__typeArgs, "V", null), "both"), value: value}, {V: __typeArgs?.["V"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }}), "*main.GenericChannelStore[V]")
 }
 
 export async function loadGenericStore(store: GenericStore | null): globalThis.Promise<number> {
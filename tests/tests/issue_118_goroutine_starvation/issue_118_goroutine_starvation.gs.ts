@@ -35,10 +35,10 @@ export async function main(): globalThis.Promise<void> {
 	}, ({ kind: $.TypeKind.Function, params: [], results: [] } as $.FunctionTypeInfo)))
 
 	// Wait for both workers with a timeout
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await wg.value.Wait()
 		done!.close()
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/issue_118_goroutine_starvation/issue_118_goroutine_starvation.go:45")
 
 	// Collect results
 	let results: $.Slice<number> = $.arrayToSlice<number>([])
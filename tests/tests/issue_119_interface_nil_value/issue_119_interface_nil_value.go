@@ -17,6 +17,30 @@ type Cat struct {
 	name string
 }
 
+type MyErr struct{}
+
+func (e *MyErr) Error() string {
+	if e == nil {
+		return "nil MyErr"
+	}
+	return "MyErr"
+}
+
+func mayFail() error {
+	var err *MyErr
+	return err // Returns a non-nil error holding a typed nil
+}
+
+func classify(err error) string {
+	switch err.(type) {
+	case nil:
+		return "nil error"
+	case *MyErr:
+		return "typed nil MyErr"
+	}
+	return "other error"
+}
+
 func (d *Dog) Name() string {
 	if d == nil {
 		return "unknown dog"
@@ -98,4 +122,15 @@ func main() {
 	} else {
 		println("b is not nil")
 	}
+
+	// Test 6: Equality converts the pointer to the interface type first
+	println("a == dog:", a == dog)
+	println("a == b:", a == b)
+
+	// Test 7: Type switches keep case nil apart from typed nils
+	err := mayFail()
+	println("err != nil:", err != nil)
+	println("err.Error():", err.Error())
+	println("classify(err):", classify(err))
+	println("classify(nil):", classify(nil))
 }
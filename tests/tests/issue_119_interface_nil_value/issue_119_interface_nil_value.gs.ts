@@ -99,6 +99,63 @@ export class Cat {
 	)
 }
 
+export class MyErr {
+	public _fields: {
+	}
+
+	constructor(init?: Partial<{}>) {
+		this._fields = {
+		}
+	}
+
+	public clone(): MyErr {
+		const cloned = new MyErr()
+		cloned._fields = {
+		}
+		return $.markAsStructValue(cloned)
+	}
+
+	public Error(): string {
+		const e: MyErr | $.VarRef<MyErr> | null = this
+		if (e == null) {
+			return "nil MyErr"
+		}
+		return "MyErr"
+	}
+
+	static __typeInfo = $.registerStructType(
+		"main.MyErr",
+		() => new MyErr(),
+		[{ name: "Error", args: [], returns: [{ type: { kind: $.TypeKind.Basic, name: "string" } }] }],
+		MyErr,
+		[]
+	)
+}
+
+export function mayFail(): $.GoError {
+	let err: MyErr | $.VarRef<MyErr> | null = null as MyErr | $.VarRef<MyErr> | null
+	return $.interfaceValue<$.GoError>(err, "*main.MyErr")
+}
+
+export function classify(err: $.GoError): string {
+	{
+		const __goscriptTypeSwitchValue = err
+		switch (true) {
+			case __goscriptTypeSwitchValue == null:
+				{
+					return "nil error"
+				}
+				break
+			case $.typeAssert<MyErr | $.VarRef<MyErr> | null>(__goscriptTypeSwitchValue, { kind: $.TypeKind.Pointer, elemType: "main.MyErr" }).ok:
+				{
+					return "typed nil MyErr"
+				}
+				break
+		}
+	}
+	return "other error"
+}
+
 export function FindDog(): Dog | $.VarRef<Dog> | null {
 	return null
 }
@@ -179,6 +236,17 @@ export async function main(): globalThis.Promise<void> {
 	} else {
 		$.println("b is not nil")
 	}
+
+	// Test 6: Equality converts the pointer to the interface type first
+	$.println("a == dog:", $.comparableEqual(a, $.interfaceValue<Animal | null>(dog, "*main.Dog")))
+	$.println("a == b:", $.comparableEqual(a, b))
+
+	// Test 7: Type switches keep case nil apart from typed nils
+	let err = mayFail()
+	$.println("err != nil:", err != null)
+	$.println("err.Error():", $.pointerValue<Exclude<$.GoError, null>>(err).Error())
+	$.println("classify(err):", classify(err))
+	$.println("classify(nil):", classify(null))
 }
 
 if ($.isMainScript(import.meta)) {
@@ -36,7 +36,7 @@ export function ZeroValue(__typeArgs: $.GenericTypeArgs | undefined): any {
 }
 
 export function ZeroArrayLiteral(__typeArgs: $.GenericTypeArgs | undefined): any {
-	return $.genericZero(__typeArgs, "T", null)
+	return [0, 0]
 }
 
 export async function CallString(__typeArgs: $.GenericTypeArgs | undefined, v: any): globalThis.Promise<string> {
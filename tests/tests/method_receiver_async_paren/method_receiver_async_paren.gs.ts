@@ -32,10 +32,10 @@ export class Thing {
 	public async callIt(x: number): Promise<void> {
 		const t = this
 		let done = $.makeChannel<Record<string, unknown>>(0, null, "both")
-		queueMicrotask(async () => { ((): void => {
+		$.go(async () => { ((): void => {
 	getFunc()(t, x)
 	done.close()
-})() })
+})() }, "main.(*Thing).callIt", "github.com/s4wave/goscript/tests/tests/method_receiver_async_paren/method_receiver_async_paren.go:16")
 		await $.chanRecv(done)
 	}
 
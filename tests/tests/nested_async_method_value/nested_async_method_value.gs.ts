@@ -41,9 +41,9 @@ export class Worker {
 
 	public async Spawn(): globalThis.Promise<$.GoError> {
 		const w: Worker | $.VarRef<Worker> | null = this
-		queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+		$.go(async () => { await (async (): globalThis.Promise<void> => {
 			await $.chanRecv($.pointerValue<Worker>(w).ch)
-		})() })
+		})() }, "main.(*Worker).Spawn", "github.com/s4wave/goscript/tests/tests/nested_async_method_value/nested_async_method_value.go:12")
 		return null
 	}
 
@@ -16,10 +16,10 @@ export async function run(ctx: context.Context | null): globalThis.Promise<void>
 
 	let myCh: $.Channel<{}> | null = $.makeChannel<{}>(0, {}, "both")
 
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await $.chanRecv(await $.pointerValue<Exclude<context.Context, null>>(sctx).Done())
 		await $.chanSend(myCh, {})
-	})() })
+	})() }, "main.run", "github.com/s4wave/goscript/tests/tests/package_import_context/package_import_context.go:14")
 
 	// Check that myCh is not readable yet
 	const [__goscriptSelect0HasReturn, __goscriptSelect0Value] = await $.selectStatement<any, void>([
@@ -50,15 +50,15 @@ export async function main(): globalThis.Promise<void> {
 
 	// Start worker goroutines
 	for (let i = 0; i < numWorkers; i++) {
-		queueMicrotask(async () => { await worker!(i) })
+		$.go(async () => { await worker!(i) }, "main.main", "github.com/s4wave/goscript/tests/tests/package_import_csync/package_import_csync.go:47")
 	}
 
 	// Wait for all workers to complete or context timeout
 	let done: $.Channel<{}> | null = $.makeChannel<{}>(0, {}, "both")
-	queueMicrotask(async () => { await (async (): globalThis.Promise<void> => {
+	$.go(async () => { await (async (): globalThis.Promise<void> => {
 		await wg.value.Wait()
 		done!.close()
-	})() })
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/package_import_csync/package_import_csync.go:52")
 
 	const [__goscriptSelect0HasReturn, __goscriptSelect0Value] = await $.selectStatement<any, void>([
 		{
@@ -17,7 +17,7 @@ import "@goscript/strings/index.js"
 
 export async function main(): globalThis.Promise<void> {
 	await using __defer = new $.AsyncDisposableStack()
-	let server: httptest.Server | $.VarRef<httptest.Server> | null = httptest.NewServer($.pointerValueOrNil($.namedValueInterfaceValue<http.Handler | null>($.namedFunction($.functionValue(async (w: http.ResponseWriter | null, r: http.Request | $.VarRef<http.Request> | null): globalThis.Promise<void> => {
+	let server: httptest.Server | $.VarRef<httptest.Server> | null = await httptest.NewServer($.pointerValueOrNil($.namedValueInterfaceValue<http.Handler | null>($.namedFunction($.functionValue(async (w: http.ResponseWriter | null, r: http.Request | $.VarRef<http.Request> | null): globalThis.Promise<void> => {
 		let data: $.Slice<number> = null as $.Slice<number>
 		if ($.pointerValue<http.Request>(r).Body != null) {
 			let readErr: $.GoError = null as $.GoError
@@ -1,9 +1,10 @@
 // Generated file based on util_promise.go
 // Updated when compliance tests are re-run, DO NOT EDIT!
 
-import * as $ from "@goscript/builtin/index.ts"
+import * as $ from "@goscript/builtin/index.js"
 
-import * as context from "@goscript/context/index.ts"
+import * as context from "@goscript/context/index.js"
+import "@goscript/context/index.js"
 
 export class Promise {
 	public get result(): any {
@@ -13,10 +14,10 @@ export class Promise {
 		this._fields.result.value = value
 	}
 
-	public get err(): error {
+	public get err(): $.GoError {
 		return this._fields.err.value
 	}
-	public set err(value: error) {
+	public set err(value: $.GoError) {
 		this._fields.err.value = value
 	}
 
@@ -27,31 +28,34 @@ export class Promise {
 		this._fields.isResolved.value = value
 	}
 
-	public get ch(): $.Channel<Record<string, unknown>> | null {
+	public get ch(): $.Channel<{}> | null {
 		return this._fields.ch.value
 	}
-	public set ch(value: $.Channel<Record<string, unknown>> | null) {
+	public set ch(value: $.Channel<{}> | null) {
 		this._fields.ch.value = value
 	}
 
 	public _fields: {
 		result: $.VarRef<any>
-		err: $.VarRef<error>
+		err: $.VarRef<$.GoError>
 		isResolved: $.VarRef<boolean>
-		ch: $.VarRef<$.Channel<Record<string, unknown>> | null>
+		ch: $.VarRef<$.Channel<{}> | null>
 	}
 
-	constructor(init?: Partial<{result?: any, err?: error, isResolved?: boolean, ch?: $.Channel<Record<string, unknown>> | null}>) {
+	declare public __typeArgs: $.GenericTypeArgs | undefined
+
+	constructor(init?: Partial<{result?: any, err?: $.GoError, isResolved?: boolean, ch?: $.Channel<{}> | null}>, __typeArgs?: $.GenericTypeArgs) {
+		Object.defineProperty(this, "__typeArgs", { value: __typeArgs, writable: true })
 		this._fields = {
-			result: $.varRef(init?.result ?? null),
-			err: $.varRef(init?.err ?? null),
-			isResolved: $.varRef(init?.isResolved ?? false),
-			ch: $.varRef(init?.ch ?? null)
+			result: $.varRef(init?.result ?? ($.genericZero(__typeArgs, "T", null) as any)),
+			err: $.varRef(init?.err ?? (null as $.GoError)),
+			isResolved: $.varRef(init?.isResolved ?? (false as boolean)),
+			ch: $.varRef(init?.ch ?? (null as $.Channel<{}> | null))
 		}
 	}
 
 	public clone(): Promise {
-		const cloned = new Promise()
+		const cloned = new Promise(undefined, this.__typeArgs)
 		cloned._fields = {
 			result: $.varRef(this._fields.result.value),
 			err: $.varRef(this._fields.err.value),
@@ -61,115 +65,141 @@ export class Promise {
 		return $.markAsStructValue(cloned)
 	}
 
-	public async Await(ctx: Context): Promise<void> {
-		const p = this
-		if ($.pointerValue(p).isResolved) {
-			return [$.pointerValue(p).result, $.pointerValue(p).err]
+	public async Await(ctx: context.Context | null): globalThis.Promise<[any, $.GoError]> {
+		const p: Promise | $.VarRef<Promise> | null = this
+		const __typeArgs = ($.isVarRef(this) ? (this as any).value : this)?.__typeArgs
+		let val: any = $.genericZero(__typeArgs, "T", null)
+		let err: $.GoError = null as $.GoError
+		if ($.pointerValue<Promise>(p).isResolved) {
+			return [$.pointerValue<Promise>(p).result, $.pointerValue<Promise>(p).err]
 		}
-		const [__goscriptSelectHasReturn3505372, __goscriptSelectValue3505372] = await $.selectStatement([
+
+		const [__goscriptSelect0HasReturn, __goscriptSelect0Value] = await $.selectStatement<any, [any, $.GoError]>([
 			{
 				id: 0,
 				isSend: false,
-				channel: $.pointerValue(p).ch,
-				onSelected: async (result) => {
-					return [$.pointerValue(p).result, $.pointerValue(p).err]
+				channel: $.pointerValue<Promise>(p).ch,
+				onSelected: async (__goscriptSelect0Result) => {
+					return [$.pointerValue<Promise>(p).result, $.pointerValue<Promise>(p).err]
 				}
 			},
 			{
 				id: 1,
 				isSend: false,
-				channel: ctx.Done(),
-				onSelected: async (result) => {
+				channel: await $.pointerValue<Exclude<context.Context, null>>(ctx).Done(),
+				onSelected: async (__goscriptSelect0Result) => {
 					let zero: any = $.genericZero(__typeArgs, "T", null)
-					return [zero, ctx.Err()]
+					return [zero, await $.pointerValue<Exclude<context.Context, null>>(ctx).Err()]
 				}
 			}
 		], false)
-		if (__goscriptSelectHasReturn3505372) {
-			return __goscriptSelectValue3505372
+		if (__goscriptSelect0HasReturn) {
+			return __goscriptSelect0Value
 		}
+		throw new Error("unreachable select")
+		throw new globalThis.Error("goscript: unreachable return")
 	}
 
-	public SetResult(val: any, err: error): boolean {
-		const p = this
-		if ($.pointerValue(p).isResolved) {
+	public SetResult(val: any, err: $.GoError): boolean {
+		let p: Promise | $.VarRef<Promise> | null = this
+		if ($.pointerValue<Promise>(p).isResolved) {
 			return false
 		}
-		$.pointerValue(p).result = val
-		$.pointerValue(p).err = err
-		$.pointerValue(p).isResolved = true
-		if ($.pointerValue(p).ch != null) {
-			$.pointerValue(p).ch.close()
+		$.pointerValue<Promise>(p).result = val
+		$.pointerValue<Promise>(p).err = err
+		$.pointerValue<Promise>(p).isResolved = true
+		if ($.pointerValue<Promise>(p).ch != null) {
+			$.pointerValue<Promise>(p).ch!.close()
 		}
 		return true
 	}
 
 	static __typeInfo = $.registerStructType(
 		"main.Promise",
-		new Promise(),
-		[{ name: "Await", args: [], returns: [] }, { name: "SetResult", args: [], returns: [] }],
+		() => new Promise(),
+		[{ name: "Await", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [{ type: { kind: $.TypeKind.Interface, methods: [] } }, { type: "error" }] }, { name: "SetResult", args: [{ type: { kind: $.TypeKind.Basic, name: "unknown" } }, { type: { kind: $.TypeKind.Basic, name: "unknown" } }], returns: [{ type: { kind: $.TypeKind.Basic, name: "bool" } }] }],
 		Promise,
-		{"result": { kind: $.TypeKind.Interface, methods: [] }, "err": "error", "isResolved": { kind: $.TypeKind.Basic, name: "bool" }, "ch": { kind: $.TypeKind.Channel, direction: "both", elemType: { kind: $.TypeKind.Struct, methods: [], fields: {} } }}
+		[{ name: "result", key: "result", type: { kind: $.TypeKind.Interface, methods: [] } }, { name: "err", key: "err", type: "error" }, { name: "isResolved", key: "isResolved", type: { kind: $.TypeKind.Basic, name: "bool" } }, { name: "ch", key: "ch", type: { kind: $.TypeKind.Channel, direction: "both", elemType: { kind: $.TypeKind.Struct, methods: [], fields: [] } } }]
 	)
 }
 
 export function NewPromise(__typeArgs: $.GenericTypeArgs | undefined): Promise | $.VarRef<Promise> | null {
-	return new Promise({ch: $.makeChannel<Record<string, unknown>>(0, null, "both")})
+	return new Promise({ch: $.makeChannel<{}>(0, {}, "both")}, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }})
 }
 
-export function NewPromiseWithResult(__typeArgs: $.GenericTypeArgs | undefined, val: any, err: error): Promise | $.VarRef<Promise> | null {
-	let p = new Promise({result: val, err: err, isResolved: true, ch: $.makeChannel<Record<string, unknown>>(0, null, "both")})
-	if ($.pointerValue(p).ch != null) {
-		$.pointerValue(p).ch.close()
+export function NewPromiseWithResult(__typeArgs: $.GenericTypeArgs | undefined, val: any, err: $.GoError): Promise | $.VarRef<Promise> | null {
+	let p: Promise | $.VarRef<Promise> | null = new Promise({result: val, err: err, isResolved: true, ch: $.makeChannel<{}>(0, {}, "both")}, {T: __typeArgs?.["T"] ?? { type: { kind: $.TypeKind.Interface, methods: [] }, zero: () => null }})
+	if ($.pointerValue<Promise>(p).ch != null) {
+		$.pointerValue<Promise>(p).ch!.close()
 	}
 	return p
 }
 
-export async function main(): Promise<void> {
+export async function main(): globalThis.Promise<void> {
 	let ctx = context.Background()
+
+	// Test 1: Basic Promise with string
 	$.println("Test 1: Basic Promise with string")
-	let p1 = NewPromise({T: { zero: () => "" }})
-	queueMicrotask(async () => { ((): void => {
-	$.pointerValue(p1).SetResult("hello world", null)
-})() })
-	let [result1, err1] = $.pointerValue(p1).Await(ctx)
+	let p1: Promise | $.VarRef<Promise> | null = (NewPromise({T: { type: { kind: $.TypeKind.Basic, name: "string" }, zero: () => "" }}) as Promise | $.VarRef<Promise> | null)
+
+	// Set result in goroutine
+	$.go(async () => { ((): void => {
+		Promise.prototype.SetResult.call(p1, "hello world", null)
+	})() }, "main.main", "github.com/s4wave/goscript/tests/tests/util_promise/util_promise.go:71")
+
+	let __goscriptTuple0: any = await Promise.prototype.Await.call(p1, ctx)
+	let result1 = (__goscriptTuple0[0] as string)
+	let err1 = __goscriptTuple0[1]
 	if (err1 != null) {
-		$.println("Error:", err1.Error())
+		$.println("Error:", $.pointerValue<Exclude<$.GoError, null>>(err1).Error())
 	} else {
 		$.println("Result:", result1)
 	}
+
+	// Test 2: Pre-resolved Promise with int
 	$.println("Test 2: Pre-resolved Promise with int")
-	let p2 = NewPromiseWithResult({T: { zero: () => 0 }}, 42, null)
-	let [result2, err2] = $.pointerValue(p2).Await(ctx)
+	let p2: Promise | $.VarRef<Promise> | null = (NewPromiseWithResult({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}, 42, null) as Promise | $.VarRef<Promise> | null)
+	let __goscriptTuple1: any = await Promise.prototype.Await.call(p2, ctx)
+	let result2 = (__goscriptTuple1[0] as number)
+	let err2 = __goscriptTuple1[1]
 	if (err2 != null) {
-		$.println("Error:", err2.Error())
+		$.println("Error:", $.pointerValue<Exclude<$.GoError, null>>(err2).Error())
 	} else {
 		$.println("Result:", result2)
 	}
+
+	// Test 3: Promise with error
 	$.println("Test 3: Promise with error")
-	let p3 = NewPromiseWithResult({T: { zero: () => false }}, false, context.DeadlineExceeded)
-	let [result3, err3] = $.pointerValue(p3).Await(ctx)
+	let p3: Promise | $.VarRef<Promise> | null = (NewPromiseWithResult({T: { type: { kind: $.TypeKind.Basic, name: "bool" }, zero: () => false }}, false, context.DeadlineExceeded) as Promise | $.VarRef<Promise> | null)
+	let __goscriptTuple2: any = await Promise.prototype.Await.call(p3, ctx)
+	let result3 = (__goscriptTuple2[0] as boolean)
+	let err3 = __goscriptTuple2[1]
 	if (err3 != null) {
-		$.println("Error:", err3.Error())
+		$.println("Error:", $.pointerValue<Exclude<$.GoError, null>>(err3).Error())
 	} else {
 		$.println("Result:", result3)
 	}
+
+	// Test 4: Cannot set result twice
 	$.println("Test 4: Cannot set result twice")
-	let p4 = NewPromise({T: { zero: () => 0 }})
-	let success1 = $.pointerValue(p4).SetResult(100, null)
-	let success2 = $.pointerValue(p4).SetResult(200, null)
+	let p4: Promise | $.VarRef<Promise> | null = (NewPromise({T: { type: { kind: $.TypeKind.Basic, name: "int" }, zero: () => 0 }}) as Promise | $.VarRef<Promise> | null)
+	let success1 = Promise.prototype.SetResult.call(p4, 100, null)
+	let success2 = Promise.prototype.SetResult.call(p4, 200, null)
 	$.println("First set success:", success1)
 	$.println("Second set success:", success2)
-	let [result4, err4] = $.pointerValue(p4).Await(ctx)
+
+	let __goscriptTuple3: any = await Promise.prototype.Await.call(p4, ctx)
+	let result4 = (__goscriptTuple3[0] as number)
+	let err4 = __goscriptTuple3[1]
 	if (err4 != null) {
-		$.println("Error:", err4.Error())
+		$.println("Error:", $.pointerValue<Exclude<$.GoError, null>>(err4).Error())
 	} else {
 		$.println("Final result:", result4)
 	}
+
 	$.println("All tests completed")
 }
 
-
 if ($.isMainScript(import.meta)) {
 	await main()
 }